@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// concurrencyManager sets the number of workers of the controllers added through it, as the
+// machine controller creates its controller with the default options and takes none. Adding a
+// runnable it cannot set the workers of fails, so that a change of the controller-runtime
+// controller does not silently fall back to a single worker.
+type concurrencyManager struct {
+	manager.Manager
+	maxConcurrentReconciles int
+	// configured counts the controllers whose workers were set.
+	configured int
+}
+
+// Add sets the workers of the controller before adding it to the manager.
+func (m *concurrencyManager) Add(runnable manager.Runnable) error {
+	if err := setMaxConcurrentReconciles(runnable, m.maxConcurrentReconciles); err != nil {
+		return err
+	}
+	m.configured++
+	return m.Manager.Add(runnable)
+}
+
+// setMaxConcurrentReconciles sets the MaxConcurrentReconciles field of the controller-runtime
+// controller, returning an error if the runnable has none.
+func setMaxConcurrentReconciles(runnable manager.Runnable, maxConcurrentReconciles int) error {
+	value := reflect.ValueOf(runnable)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("failed to set the workers of %T: not a controller", runnable)
+	}
+	field := value.Elem().FieldByName("MaxConcurrentReconciles")
+	if !field.IsValid() || !field.CanSet() || field.Kind() != reflect.Int {
+		return fmt.Errorf("failed to set the workers of %T: no settable MaxConcurrentReconciles field", runnable)
+	}
+	field.SetInt(int64(maxConcurrentReconciles))
+	return nil
+}
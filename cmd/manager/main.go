@@ -14,32 +14,123 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	"github.com/openshift/machine-api-operator/pkg/controller/machine"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog"
-	"k8s.io/klog/klogr"
-	machineactuator "sigs.k8s.io/cluster-api-provider-aws/pkg/actuators/machine"
-	machinesetcontroller "sigs.k8s.io/cluster-api-provider-aws/pkg/actuators/machineset"
-	awsclient "sigs.k8s.io/cluster-api-provider-aws/pkg/client"
-	"sigs.k8s.io/cluster-api-provider-aws/pkg/version"
+	machineactuator "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/actuators/machine"
+	machinesetcontroller "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/actuators/machineset"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/handoff"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/logging"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/version"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/webhooks"
 	ctrl "sigs.k8s.io/controller-runtime"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
+// stringSliceFlag is a flag that can be repeated.
+type stringSliceFlag []string
+
+func (f *stringSliceFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringSliceFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// leaderElectionID is the name of the leader lock shared by all versions of the provider.
+const leaderElectionID = "cluster-api-provider-kubevirt-leader"
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "metrics" {
+		os.Exit(runMetrics(os.Args[2:]))
+	}
+
 	var printVersion bool
 	flag.BoolVar(&printVersion, "version", false, "print version and exit")
 
 	klog.InitFlags(nil)
 	watchNamespace := flag.String("namespace", "", "Namespace that the controller watches to reconcile machine-api objects. If unspecified, the controller watches for machine-api objects across all namespaces.")
+	minResyncPeriod := flag.Duration("min-resync-period", time.Minute, "Resync period of machines while any machine of the fleet is provisioning, deleting or failing.")
+	maxResyncPeriod := flag.Duration("max-resync-period", 10*time.Minute, "Resync period of machines while all machines of the fleet are running.")
+	advancedTuningEnabled := flag.Bool("enable-advanced-tuning", false, "Allow provider specs to set the allowlisted KubeVirt tuning annotations of the advancedTuning section.")
+	provisioningFailureThreshold := flag.Int("provisioning-failure-threshold", 5, "Consecutive failures to create or bootstrap machines of a MachineSet after which no VM is created for it, until its provisioning-failures annotation is removed. Zero disables the failure budget.")
+	provisioningFailureBackoff := flag.Duration("provisioning-failure-backoff", 30*time.Second, "Delay of VM creations for a MachineSet after its first provisioning failure, doubled on each consecutive failure.")
+	bootstrapTimeout := flag.Duration("bootstrap-timeout", 20*time.Minute, "Time after which a machine whose node did not join counts as a provisioning failure of its MachineSet.")
+	nodeDrainTimeoutCheckInterval := flag.Duration("node-drain-timeout-check-interval", 30*time.Second, "Interval at which the drain of the tenant Node of deleted machines is checked against the nodeDrainTimeout of their provider spec.")
+	infraEvictionDrain := flag.Bool("infra-eviction-drain", false, "Cordon and drain the tenant Node of machines whose VMI the infra cluster is terminating, e.g. evicting it off an infra node in maintenance, before the VM goes away. The Node is uncordoned once the VM runs again.")
+	infraEvictionCheckInterval := flag.Duration("infra-eviction-check-interval", 10*time.Second, "Interval at which the VMIs of the machines are checked for termination by the infra cluster.")
+	bootImageCache := flag.Bool("boot-image-cache", false, "Import the boot images of the provider specs with a bootImageImport on a schedule with CDI DataImportCrons on the infra cluster, and delete the DataImportCrons no longer referenced.")
+	bootImageCacheSyncInterval := flag.Duration("boot-image-cache-sync-interval", 5*time.Minute, "Interval at which the DataImportCrons of the boot images are synced with the machines and MachineSets.")
+	var diagnosticsURLTemplates stringSliceFlag
+	flag.Var(&diagnosticsURLTemplates, "diagnostics-url-template", "Deep link into the infra cluster consoles set in the provider status of machines, as name=template. The Go template is executed with Namespace, VMName, MachineName and, once the VM runs, VMIUID and NodeName. Can be repeated.")
+	var overcommitProfileSpecs stringSliceFlag
+	flag.Var(&overcommitProfileSpecs, "overcommit-profile", "Overcommit profile provider specs refer to by name in overcommitProfile, as name=key:value,... with the keys cpuRatio (vCPUs per requested CPU), memoryRatio (guest memory per requested memory), guaranteed (limits set to the requests) and guestOverhead (launcher overhead counted within the requested memory). Can be repeated.")
+	var propagatedLabels, propagatedAnnotations stringSliceFlag
+	flag.Var(&propagatedLabels, "propagate-machine-label", "Key of the Machine label propagated onto the VirtualMachine, its VMI template and the running virt-launcher pod on every Update, e.g. machine.openshift.io/cluster-api-machineset. Can be repeated.")
+	flag.Var(&propagatedAnnotations, "propagate-machine-annotation", "Key of the Machine annotation propagated onto the VirtualMachine, its VMI template and the running virt-launcher pod on every Update. Can be repeated.")
+	dnsBackend := flag.String("dns-backend", "", "Backend the hostnames of the machines are registered into with their internal IPs, DNSEndpoint for an external-dns DNSEndpoint per machine or ZoneSecret for hosts file entries in a Secret, and removed on Delete. Empty disables the registration.")
+	dnsZone := flag.String("dns-zone", "", "Zone the hostnames of the machines are registered in, e.g. nodes.example.com.")
+	dnsZoneSecret := flag.String("dns-zone-secret", "", "Name of the Secret in the namespace of the machines the ZoneSecret backend keeps the hosts file entries in, one key per machine.")
+	dnsTTL := flag.Duration("dns-ttl", 0, "TTL of the records of the DNSEndpoint backend. Zero keeps the external-dns default.")
+	leaderElect := flag.Bool("leader-elect", false, "Run only while holding the leader lock, and hand reconciliation off to instances of another version without downtime on upgrades.")
+	leaderElectionNamespace := flag.String("leader-election-namespace", "", "Namespace of the leader lock. Defaults to the watched namespace.")
+	leaderElectionLeaseDuration := flag.Duration("leader-election-lease-duration", 15*time.Second, "Duration non-leader instances wait before taking over a leader lock which was not renewed.")
+	leaderElectionRenewDeadline := flag.Duration("leader-election-renew-deadline", 10*time.Second, "Duration the leader retries renewing the leader lock before stepping down. It must be shorter than the lease duration.")
+	leaderElectionRetryPeriod := flag.Duration("leader-election-retry-period", 2*time.Second, "Interval of the attempts to acquire or renew the leader lock.")
+	handoffDrainTimeout := flag.Duration("handoff-drain-timeout", time.Minute, "Time the leader waits for its machine operations in flight before handing off to an instance of another version.")
+	webhookPort := flag.Int("webhook-port", 0, "Port the admission webhooks defaulting and validating the provider spec of Machines and MachineSets are served at. Zero disables the webhooks.")
+	webhookCertDir := flag.String("webhook-cert-dir", "", "Directory holding the tls.crt and tls.key serving certificate of the admission webhooks. Defaults to <tmp>/k8s-webhook-server/serving-certs.")
+	impersonateUser := flag.String("infra-impersonate-user", "", "User the requests to the infra cluster impersonate, suffixed with the tenant cluster ID, so that the infra audit logs attribute VM operations to the tenant machine. The infra credentials need the impersonate permission. Empty disables impersonation.")
+	var impersonateGroups stringSliceFlag
+	flag.Var(&impersonateGroups, "infra-impersonate-group", "Group the requests to the infra cluster impersonate along with the impersonated user. Can be repeated.")
+	tenantClusterID := flag.String("tenant-cluster-id", "", "ID of the tenant cluster in the identity impersonated on the infra cluster. Defaults to the cluster ID label of each machine.")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "OTLP/HTTP endpoint of the OpenTelemetry collector the spans of the machine operations are exported to, e.g. http://otel-collector:4318. Empty disables tracing.")
+	otlpExportInterval := flag.Duration("otlp-export-interval", 5*time.Second, "Interval of the span exports to the OpenTelemetry collector.")
+	logFormat := flag.String("log-format", logging.FormatText, "Format of the machine controller logs, text for klog or json for a JSON object per line with the machine, namespace and operation fields. The verbosity is set with -v.")
+	eventAggregationWindow := flag.Duration("event-aggregation-window", 5*time.Minute, "Window within which the events of a machine with the same type, reason and message are collapsed into one, the next event reporting the count. Zero disables the aggregation.")
+	eventQPS := flag.Float64("event-qps", 1, "Rate of the machine events recorded, per second, events beyond it are dropped and reported with the next event of the machine. Zero disables the limit.")
+	eventBurst := flag.Int("event-burst", 25, "Burst of machine events recorded above the event rate.")
+	infraEventWindow := flag.Duration("infra-event-window", 10*time.Minute, "Window of the warning events of the VM, VMI, virt-launcher pod and boot volume of a machine summarized in its FailedCreate and FailedUpdate events. Zero disables the summary.")
+	createTimeout := flag.Duration("create-timeout", 5*time.Minute, "Time a machine Create may take, its infra requests being cancelled once it elapsed. Zero disables the timeout.")
+	existsTimeout := flag.Duration("exists-timeout", time.Minute, "Time a machine Exists may take, its infra requests being cancelled once it elapsed. Zero disables the timeout.")
+	updateTimeout := flag.Duration("update-timeout", 5*time.Minute, "Time a machine Update may take, its infra requests being cancelled once it elapsed. Zero disables the timeout.")
+	deleteTimeout := flag.Duration("delete-timeout", 5*time.Minute, "Time a machine Delete may take, its infra requests being cancelled once it elapsed. Zero disables the timeout.")
+	dryRun := flag.Bool("dry-run", false, "Render and validate the VirtualMachine, DataVolumes and UserData secret of the machines on Create, Update and Delete, and log them instead of applying them. Single machines are dry run with the kubevirtproviderconfig.openshift.io/dry-run annotation.")
+	infraCacheEnabled := flag.Bool("infra-cache", false, "Read the VirtualMachines, VirtualMachineInstances and DataVolumes of the infra cluster from informers instead of a request per reconcile. The infra credentials need to list and watch them. Impersonating clients are not cached.")
+	infraAccessNamespace := flag.String("infra-access-namespace", "", "Namespace of the infra cluster the infra credentials are scoped to, e.g. by a Role bound to a namespaced ServiceAccount. The permissions of the manager credentials in it are verified at startup, and provider specs placing VMs in another namespace or needing cluster-scoped infra access are rejected. Empty expects cluster-wide access.")
+	infraQPS := flag.Float64("infra-qps", kubevirtclient.DefaultQPS, "Rate of the requests to each infra cluster, per second, shared by all machines.")
+	infraBurst := flag.Int("infra-burst", kubevirtclient.DefaultBurst, "Burst of the requests to each infra cluster above the infra request rate.")
+	createQPS := flag.Float64("create-qps", 0, "Rate of the machine creations, per second, creations beyond it are requeued. Zero disables the limit.")
+	createBurst := flag.Int("create-burst", 10, "Burst of machine creations above the creation rate.")
+	updateQPS := flag.Float64("update-qps", 0, "Rate of the machine updates, per second, updates beyond it are requeued. Zero disables the limit.")
+	updateBurst := flag.Int("update-burst", 10, "Burst of machine updates above the update rate.")
+	deleteQPS := flag.Float64("delete-qps", 0, "Rate of the machine deletions, per second, deletions beyond it are requeued. Zero disables the limit.")
+	deleteBurst := flag.Int("delete-burst", 10, "Burst of machine deletions above the deletion rate.")
+	machineConcurrency := flag.Int("machine-concurrency", 1, "Number of machines reconciled in parallel.")
+	maxConcurrentClones := flag.Int("max-concurrent-clones", 0, "Maximum of boot volumes cloned concurrently out of the same source PVC, the creations of further VMs are queued until clones complete. Zero disables the limit.")
 	flag.Set("logtostderr", "true")
 	flag.Parse()
 
@@ -48,14 +139,22 @@ func main() {
 		os.Exit(0)
 	}
 
+	verbosity := flag.Lookup("v").Value.(flag.Getter).Get().(klog.Level)
+	logger, err := logging.New(*logFormat, os.Stderr, int(verbosity))
+	if err != nil {
+		klog.Fatalf("Error creating logger: %v", err)
+	}
+	ctrl.SetLogger(logger)
+
 	// Get a config to talk to the apiserver
 	cfg, err := config.GetConfig()
 	if err != nil {
 		klog.Fatalf("Error getting configuration: %v", err)
 	}
 
-	// Setup a Manager
-	syncPeriod := 10 * time.Minute
+	// Setup a Manager, the resync tracker stretches the sync period of healthy machines
+	// up to the max resync period
+	syncPeriod := *minResyncPeriod
 	opts := manager.Options{
 		SyncPeriod: &syncPeriod,
 		// Disable metrics serving
@@ -66,6 +165,22 @@ func main() {
 		klog.Infof("Watching machine-api objects only in namespace %q for reconciliation.", opts.Namespace)
 	}
 
+	// With leader election, preflight before campaigning so that this version never takes over
+	// in-flight operations whose state it cannot resume
+	if *leaderElect {
+		scheme := runtime.NewScheme()
+		if err := mapiv1beta1.AddToScheme(scheme); err != nil {
+			klog.Fatalf("Error setting up scheme: %v", err)
+		}
+		preflightClient, err := runtimeclient.New(cfg, runtimeclient.Options{Scheme: scheme})
+		if err != nil {
+			klog.Fatalf("Error creating preflight client: %v", err)
+		}
+		if err := machineactuator.CheckOperationStateCompatibility(context.Background(), preflightClient, *watchNamespace); err != nil {
+			klog.Fatalf("Refusing to start, in-flight operations are incompatible with this version: %v", err)
+		}
+	}
+
 	mgr, err := manager.New(cfg, opts)
 	if err != nil {
 		klog.Fatalf("Error creating manager: %v", err)
@@ -76,18 +191,149 @@ func main() {
 		klog.Fatalf("Error setting up scheme: %v", err)
 	}
 
+	resyncTracker := machineactuator.NewResyncTracker(mgr.GetClient(), *watchNamespace, *minResyncPeriod, *maxResyncPeriod)
+	if err := mgr.Add(resyncTracker); err != nil {
+		klog.Fatalf("Error adding resync tracker: %v", err)
+	}
+
+	kubevirtclient.SetRateLimits(float32(*infraQPS), *infraBurst)
+	kubevirtClientBuilder := kubevirtclient.NewClient
+	if *infraCacheEnabled {
+		infraCache := kubevirtclient.NewInfraCache(*maxResyncPeriod)
+		if err := mgr.Add(infraCache); err != nil {
+			klog.Fatalf("Error adding infra cache: %v", err)
+		}
+		if err := infraCache.WatchCredentials(context.Background(), mgr.GetCache()); err != nil {
+			klog.Fatalf("Error watching infra credentials: %v", err)
+		}
+		kubevirtClientBuilder = infraCache.ClientBuilder
+	}
+
+	if *infraAccessNamespace != "" {
+		infraClient, err := kubevirtclient.NewClient(mgr.GetClient(), "", *infraAccessNamespace)
+		if err != nil {
+			klog.Fatalf("Error creating infra client: %v", err)
+		}
+		if err := machineactuator.VerifyInfraAccess(context.Background(), infraClient, *infraAccessNamespace, *infraCacheEnabled); err != nil {
+			klog.Fatalf("Error verifying infra access: %v", err)
+		}
+	}
+
+	if err := mgr.Add(machineactuator.NewNodeDrainTimeout(mgr.GetClient(), *watchNamespace, *nodeDrainTimeoutCheckInterval)); err != nil {
+		klog.Fatalf("Error adding node drain timeout: %v", err)
+	}
+
+	if *infraEvictionDrain {
+		kubeClient, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			klog.Fatalf("Error setting up infra eviction drain: %v", err)
+		}
+		if err := mgr.Add(machineactuator.NewInfraEvictionDrain(mgr.GetClient(), kubeClient, kubevirtClientBuilder,
+			mgr.GetEventRecorderFor("kubevirtcontroller"), *watchNamespace, *infraEvictionCheckInterval)); err != nil {
+			klog.Fatalf("Error adding infra eviction drain: %v", err)
+		}
+	}
+
+	if *bootImageCache {
+		if err := mgr.Add(machineactuator.NewBootImageCache(mgr.GetClient(), kubevirtClientBuilder, *watchNamespace, *bootImageCacheSyncInterval)); err != nil {
+			klog.Fatalf("Error adding boot image cache: %v", err)
+		}
+	}
+
+	diagnosticsTemplates, err := machineactuator.ParseDiagnosticsURLTemplates(diagnosticsURLTemplates)
+	if err != nil {
+		klog.Fatalf("Error parsing diagnostics URL templates: %v", err)
+	}
+
+	overcommitProfiles, err := machineactuator.ParseOvercommitProfiles(overcommitProfileSpecs)
+	if err != nil {
+		klog.Fatalf("Error parsing overcommit profiles: %v", err)
+	}
+
+	var metadataPropagation *machineactuator.MetadataPropagation
+	if len(propagatedLabels) > 0 || len(propagatedAnnotations) > 0 {
+		if metadataPropagation, err = machineactuator.NewMetadataPropagation(propagatedLabels, propagatedAnnotations); err != nil {
+			klog.Fatalf("Error parsing propagated machine metadata: %v", err)
+		}
+	}
+
+	var dnsRegistration *machineactuator.DNSRegistration
+	if *dnsBackend != "" {
+		if dnsRegistration, err = machineactuator.NewDNSRegistration(machineactuator.DNSBackend(*dnsBackend), *dnsZone, *dnsZoneSecret, *dnsTTL); err != nil {
+			klog.Fatalf("Error setting up DNS registration: %v", err)
+		}
+	}
+
+	var impersonation *machineactuator.Impersonation
+	if *impersonateUser != "" {
+		impersonation = &machineactuator.Impersonation{
+			UserName:  *impersonateUser,
+			Groups:    impersonateGroups,
+			ClusterID: *tenantClusterID,
+		}
+	}
+
+	var cloneCoordinator *machineactuator.CloneCoordinator
+	if *maxConcurrentClones > 0 {
+		cloneCoordinator = machineactuator.NewCloneCoordinator(*maxConcurrentClones)
+	}
+
+	var tracer tracing.Tracer
+	if *otlpEndpoint != "" {
+		otlpTracer := tracing.NewOTLPTracer(*otlpEndpoint, "cluster-api-provider-kubevirt", *otlpExportInterval)
+		if err := mgr.Add(otlpTracer); err != nil {
+			klog.Fatalf("Error adding OTLP tracer: %v", err)
+		}
+		tracer = otlpTracer
+	}
+
+	// Machine operations are drained before handing off reconciliation
+	operationGate := machineactuator.NewOperationGate()
+
 	// Initialize machine actuator.
 	machineActuator := machineactuator.NewActuator(machineactuator.ActuatorParams{
-		Client:           mgr.GetClient(),
-		EventRecorder:    mgr.GetEventRecorderFor("awscontroller"),
-		AwsClientBuilder: awsclient.NewClient,
+		Client:                  mgr.GetClient(),
+		EventRecorder:           machineactuator.NewEventAggregator(mgr.GetEventRecorderFor("kubevirtcontroller"), *eventAggregationWindow, float32(*eventQPS), *eventBurst),
+		KubevirtClientBuilder:   kubevirtClientBuilder,
+		ResyncTracker:           resyncTracker,
+		AdvancedTuningEnabled:   *advancedTuningEnabled,
+		FailureBudget:           machineactuator.NewFailureBudget(mgr.GetClient(), *provisioningFailureThreshold, *provisioningFailureBackoff, 10*time.Minute, *bootstrapTimeout),
+		CloneCoordinator:        cloneCoordinator,
+		OperationGate:           operationGate,
+		DiagnosticsURLTemplates: diagnosticsTemplates,
+		Impersonation:           impersonation,
+		Tracer:                  tracer,
+		Logger:                  logger,
+		InfraEventWindow:        *infraEventWindow,
+		OvercommitProfiles:      overcommitProfiles,
+		MetadataPropagation:     metadataPropagation,
+		DNSRegistration:         dnsRegistration,
+		InfraAccessNamespace:    *infraAccessNamespace,
+		OperationTimeouts: machineactuator.OperationTimeouts{
+			Create: *createTimeout,
+			Exists: *existsTimeout,
+			Update: *updateTimeout,
+			Delete: *deleteTimeout,
+		},
+		DryRun: *dryRun,
+		OperationRateLimits: machineactuator.OperationRateLimits{
+			Create: machineactuator.RateLimit{QPS: float32(*createQPS), Burst: *createBurst},
+			Update: machineactuator.RateLimit{QPS: float32(*updateQPS), Burst: *updateBurst},
+			Delete: machineactuator.RateLimit{QPS: float32(*deleteQPS), Burst: *deleteBurst},
+		},
 	})
 
-	if err := machine.AddWithActuator(mgr, machineActuator); err != nil {
+	if *machineConcurrency < 1 {
+		klog.Fatalf("Error setting the machine concurrency: %d is not positive", *machineConcurrency)
+	}
+	machineManager := &concurrencyManager{Manager: mgr, maxConcurrentReconciles: *machineConcurrency}
+	if err := machine.AddWithActuator(machineManager, machineActuator); err != nil {
 		klog.Fatalf("Error adding actuator: %v", err)
 	}
+	if machineManager.configured != 1 {
+		klog.Fatalf("Error setting the machine concurrency: found %d machine controllers", machineManager.configured)
+	}
 
-	ctrl.SetLogger(klogr.New())
 	setupLog := ctrl.Log.WithName("setup")
 	if err = (&machinesetcontroller.Reconciler{
 		Client: mgr.GetClient(),
@@ -96,9 +342,85 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineSet")
 		os.Exit(1)
 	}
+	stop := ctrl.SetupSignalHandler()
+	if *webhookPort != 0 {
+		startWebhookServer(mgr, *webhookPort, *webhookCertDir, stop)
+	}
+	if *leaderElect {
+		runLeaderElected(cfg, mgr, operationGate, handoff.Options{
+			Namespace:     *leaderElectionNamespace,
+			LeaseDuration: *leaderElectionLeaseDuration,
+			RenewDeadline: *leaderElectionRenewDeadline,
+			RetryPeriod:   *leaderElectionRetryPeriod,
+			DrainTimeout:  *handoffDrainTimeout,
+		}, *watchNamespace, stop)
+		return
+	}
+
 	// Start the Cmd
-	err = mgr.Start(ctrl.SetupSignalHandler())
+	err = mgr.Start(stop)
 	if err != nil {
 		klog.Fatalf("Error starting manager: %v", err)
 	}
 }
+
+// startWebhookServer serves the admission webhooks. They are served apart from the manager,
+// which only runs while holding the leader lock, so that every instance serves them.
+func startWebhookServer(mgr manager.Manager, port int, certDir string, stop <-chan struct{}) {
+	server := &webhook.Server{Port: port, CertDir: certDir}
+	server.Register(webhooks.ProviderSpecDefaultingPath, &webhook.Admission{Handler: webhooks.NewProviderSpecDefaulter()})
+	server.Register(webhooks.ProviderSpecValidationPath, &webhook.Admission{Handler: webhooks.NewProviderSpecValidator()})
+	if err := server.InjectFunc(mgr.SetFields); err != nil {
+		klog.Fatalf("Error setting up webhook server: %v", err)
+	}
+	go func() {
+		if err := server.Start(stop); err != nil {
+			klog.Fatalf("Error serving webhooks: %v", err)
+		}
+	}()
+}
+
+// runLeaderElected runs the manager while this instance holds the leader lock, and returns
+// once it hands off or steps down.
+func runLeaderElected(cfg *rest.Config, mgr manager.Manager, gate *machineactuator.OperationGate, options handoff.Options, watchNamespace string, stop <-chan struct{}) {
+	if options.Namespace == "" {
+		options.Namespace = watchNamespace
+	}
+	if options.Namespace == "" {
+		klog.Fatalf("Error setting up leader election: --leader-election-namespace is required when watching all namespaces")
+	}
+
+	identity, err := handoff.NewIdentity(version.Raw)
+	if err != nil {
+		klog.Fatalf("Error setting up leader election: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Error setting up leader election: %v", err)
+	}
+	lock, err := resourcelock.New(resourcelock.ConfigMapsResourceLock, options.Namespace, leaderElectionID,
+		kubeClient.CoreV1(), kubeClient.CoordinationV1(), resourcelock.ResourceLockConfig{Identity: identity})
+	if err != nil {
+		klog.Fatalf("Error setting up leader election: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	options.Name = leaderElectionID
+	options.Version = version.Raw
+	// The cache of the manager only starts once leading, candidates read the handoff
+	// ConfigMap from the API server
+	elector := handoff.NewElector(options, lock, mgr.GetAPIReader(), mgr.GetClient(), gate)
+	err = elector.Run(ctx, func(leaderCtx context.Context) {
+		if err := mgr.Start(leaderCtx.Done()); err != nil {
+			klog.Fatalf("Error starting manager: %v", err)
+		}
+	})
+	if err != nil {
+		klog.Fatalf("Error running leader election: %v", err)
+	}
+}
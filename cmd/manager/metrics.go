@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	machineactuator "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/actuators/machine"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/monitoring"
+)
+
+//go:generate sh -c "go run . metrics manifest > ../../config/monitoring/monitoring.yaml"
+
+// monitoringName names the PrometheusRule and the dashboard ConfigMap of the provider.
+const monitoringName = "cluster-api-provider-kubevirt"
+
+// runMetrics implements the metrics subcommand. Its manifest command writes the PrometheusRule
+// alerting on the metrics of the provider and the ConfigMap of its Grafana dashboard.
+// It returns the exit code of the process.
+func runMetrics(args []string) int {
+	fs := flag.NewFlagSet("metrics", flag.ExitOnError)
+	namespace := fs.String("namespace", "openshift-machine-api", "Namespace of the generated PrometheusRule and dashboard ConfigMap.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s metrics manifest [--namespace <namespace>]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "manifest" {
+		fs.Usage()
+		return 2
+	}
+	fs.Parse(args[1:])
+
+	metrics := machineactuator.Metrics()
+	rule, err := monitoring.PrometheusRule(monitoringName, *namespace, metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating PrometheusRule: %v\n", err)
+		return 1
+	}
+	dashboard, err := monitoring.Dashboard("KubeVirt machines", metrics)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating dashboard: %v\n", err)
+		return 1
+	}
+	configMap, err := monitoring.DashboardConfigMap(monitoringName+"-dashboard", *namespace, dashboard)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating dashboard ConfigMap: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("%s---\n%s", rule, configMap)
+	return 0
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	mapiv1beta1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/clientcmd"
+	machineactuator "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/actuators/machine"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/codec"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+)
+
+// runValidate implements the validate subcommand. It defaults and validates the provider spec
+// of every Machine and MachineSet in the given manifests the way the actuator does, and
+// optionally probes the infra cluster for the source PVCs they reference.
+// It returns the exit code of the process.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	infraKubeconfig := fs.String("infra-kubeconfig", "", "Kubeconfig of the infra cluster to probe for the resources referenced by the provider specs. If unspecified, the infra cluster is not probed.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate [--infra-kubeconfig <path>] <manifest>...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var infraClient kubevirtclient.Client
+	if *infraKubeconfig != "" {
+		restConfig, err := clientcmd.BuildConfigFromFlags("", *infraKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading infra kubeconfig: %v\n", err)
+			return 2
+		}
+		if infraClient, err = kubevirtclient.NewClientFromRESTConfig(restConfig); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating infra cluster client: %v\n", err)
+			return 2
+		}
+	}
+
+	valid := true
+	for _, path := range fs.Args() {
+		errs, err := validateManifest(path, infraClient)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			valid = false
+			continue
+		}
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			valid = false
+		}
+	}
+
+	if !valid {
+		return 1
+	}
+	return 0
+}
+
+// validateManifest validates the Machines and MachineSets of a multi-document YAML or JSON manifest.
+func validateManifest(path string, infraClient kubevirtclient.Client) (field.ErrorList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	allErrs := field.ErrorList{}
+	decoder := yaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode manifest: %v", err)
+		}
+		if len(document) == 0 || string(document) == "null" {
+			continue
+		}
+
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal(document, &typeMeta); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest: %v", err)
+		}
+
+		switch typeMeta.Kind {
+		case "Machine":
+			machine := &mapiv1beta1.Machine{}
+			if err := json.Unmarshal(document, machine); err != nil {
+				return nil, fmt.Errorf("failed to decode Machine: %v", err)
+			}
+			errs := validateProviderSpec(machine.Spec.ProviderSpec, machine.Namespace, field.NewPath("spec", "providerSpec", "value"), infraClient)
+			allErrs = append(allErrs, prefixErrors(errs, "Machine", machine.ObjectMeta)...)
+		case "MachineSet":
+			machineSet := &mapiv1beta1.MachineSet{}
+			if err := json.Unmarshal(document, machineSet); err != nil {
+				return nil, fmt.Errorf("failed to decode MachineSet: %v", err)
+			}
+			errs := validateProviderSpec(machineSet.Spec.Template.Spec.ProviderSpec, machineSet.Namespace, field.NewPath("spec", "template", "spec", "providerSpec", "value"), infraClient)
+			allErrs = append(allErrs, prefixErrors(errs, "MachineSet", machineSet.ObjectMeta)...)
+		}
+	}
+
+	return allErrs, nil
+}
+
+func validateProviderSpec(providerSpec mapiv1beta1.ProviderSpec, namespace string, fldPath *field.Path, infraClient kubevirtclient.Client) field.ErrorList {
+	spec, err := codec.DecodeProviderSpec(providerSpec.Value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, "", err.Error())}
+	}
+
+	machineactuator.DefaultProviderSpec(spec)
+	allErrs := machineactuator.ValidateProviderSpec(spec, fldPath)
+
+	if infraClient != nil && spec.SourcePvcName != "" {
+		if _, err := infraClient.GetPersistentVolumeClaim(context.Background(), namespace, spec.SourcePvcName, &metav1.GetOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				allErrs = append(allErrs, field.NotFound(fldPath.Child("sourcePvcName"), spec.SourcePvcName))
+			} else {
+				allErrs = append(allErrs, field.InternalError(fldPath.Child("sourcePvcName"), err))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func prefixErrors(errs field.ErrorList, kind string, objectMeta metav1.ObjectMeta) field.ErrorList {
+	for _, err := range errs {
+		err.Field = fmt.Sprintf("%s %s/%s: %s", kind, objectMeta.Namespace, objectMeta.Name, err.Field)
+	}
+	return errs
+}
@@ -24,8 +24,8 @@ import (
 
 	"k8s.io/klog"
 	"k8s.io/klog/klogr"
-	"sigs.k8s.io/cluster-api-provider-aws/pkg/termination"
-	"sigs.k8s.io/cluster-api-provider-aws/pkg/version"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/termination"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
@@ -19,30 +19,54 @@ package machine
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/go-logr/logr"
 	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/klog"
+	"k8s.io/klog/klogr"
 
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
 	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	scopeFailFmt      = "%s: failed to create scope for machine: %w"
-	reconcilerFailFmt = "%s: reconciler failed to %s machine: %w"
-	createEventAction = "Create"
-	updateEventAction = "Update"
-	deleteEventAction = "Delete"
-	noEventAction     = ""
+	scopeFailFmt         = "%s: failed to create scope for machine: %w"
+	reconcilerFailFmt    = "%s: reconciler failed to %s machine: %w"
+	createEventAction    = "Create"
+	updateEventAction    = "Update"
+	deleteEventAction    = "Delete"
+	noEventAction        = ""
+	vmStateChangedReason = "VMStateChanged"
 )
 
 // Actuator is responsible for performing machine reconciliation.
 type Actuator struct {
-	client                runtimeclient.Client
-	eventRecorder         record.EventRecorder
-	kubevirtClientBuilder kubevirtclient.KubevirtClientBuilderFuncType
+	client                  runtimeclient.Client
+	eventRecorder           record.EventRecorder
+	kubevirtClientBuilder   kubevirtclient.KubevirtClientBuilderFuncType
+	resyncTracker           *ResyncTracker
+	advancedTuningEnabled   bool
+	failureBudget           *FailureBudget
+	cloneCoordinator        *CloneCoordinator
+	operationGate           *OperationGate
+	diagnosticsURLTemplates DiagnosticsURLTemplates
+	machineFilter           MachineFilter
+	impersonation           *Impersonation
+	tracer                  tracing.Tracer
+	logger                  logr.Logger
+	infraEventWindow        time.Duration
+	overcommitProfiles      OvercommitProfiles
+	metadataPropagation     *MetadataPropagation
+	dnsRegistration         *DNSRegistration
+	infraAccessNamespace    string
+	operationTimeouts       OperationTimeouts
+	dryRun                  bool
+	rateLimiters            operationRateLimiters
 }
 
 // ActuatorParams holds parameter information for Actuator.
@@ -50,46 +74,262 @@ type ActuatorParams struct {
 	Client                runtimeclient.Client
 	EventRecorder         record.EventRecorder
 	KubevirtClientBuilder kubevirtclient.KubevirtClientBuilderFuncType
+	// ResyncTracker is optional, if set periodic resyncs of healthy machines are skipped
+	// within the resync interval it computes out of the health of the fleet.
+	ResyncTracker *ResyncTracker
+	// AdvancedTuningEnabled allows provider specs to set the advanced tuning section.
+	AdvancedTuningEnabled bool
+	// FailureBudget is optional, if set VM creations of MachineSets whose machines keep
+	// failing to provision are backed off and eventually stopped.
+	FailureBudget *FailureBudget
+	// CloneCoordinator is optional, if set VM creations are queued while the maximum of boot
+	// volumes are being cloned out of their source PVC.
+	CloneCoordinator *CloneCoordinator
+	// OperationGate is optional, if set machine operations are refused while it is paused,
+	// during the handoff of reconciliation to another provider instance.
+	OperationGate *OperationGate
+	// DiagnosticsURLTemplates are optional, they render the deep links into the infra cluster
+	// consoles set in the provider status of machines.
+	DiagnosticsURLTemplates DiagnosticsURLTemplates
+	// MachineFilter is optional, it decides which machines the actuator leaves alone.
+	// Defaults to AnnotationMachineFilter.
+	MachineFilter MachineFilter
+	// Impersonation is optional, if set the requests to the infra cluster impersonate the
+	// identity of the tenant cluster and machine they are made for.
+	Impersonation *Impersonation
+	// Tracer is optional, if set it records spans of the machine operations and of the
+	// infra cluster requests they make.
+	Tracer tracing.Tracer
+	// Logger is optional, it logs the machine operations with the machine, namespace and
+	// operation fields. Defaults to klog.
+	Logger logr.Logger
+	// InfraEventWindow is optional, if set the FailedCreate and FailedUpdate events summarize
+	// the warning events of the infra objects of the machine which occurred within it.
+	InfraEventWindow time.Duration
+	// OvercommitProfiles are optional, they are the overcommit profiles provider specs can
+	// refer to by name.
+	OvercommitProfiles OvercommitProfiles
+	// MetadataPropagation is optional, if set the Machine labels and annotations it holds the
+	// keys of are propagated onto the VirtualMachine and its virt-launcher pod on every Update.
+	MetadataPropagation *MetadataPropagation
+	// DNSRegistration is optional, if set the hostnames of the machines are registered with
+	// their internal IPs into its DNS backend, and removed on Delete.
+	DNSRegistration *DNSRegistration
+	// InfraAccessNamespace is optional, if set the infra credentials are scoped to it and
+	// provider specs placing VMs in another namespace or needing cluster-scoped infra access
+	// are rejected.
+	InfraAccessNamespace string
+	// OperationTimeouts are optional, they bound the machine operations and cancel their
+	// infra requests once elapsed.
+	OperationTimeouts OperationTimeouts
+	// DryRun is optional, if set Create, Update and Delete render and validate the infra
+	// objects of all machines and log them instead of applying them.
+	DryRun bool
+	// OperationRateLimits are optional, operations exceeding their rate are requeued.
+	OperationRateLimits OperationRateLimits
 }
 
 // NewActuator returns an actuator.
 func NewActuator(params ActuatorParams) *Actuator {
+	machineFilter := params.MachineFilter
+	if machineFilter == nil {
+		machineFilter = AnnotationMachineFilter
+	}
+	tracer := params.Tracer
+	if tracer == nil {
+		tracer = tracing.NoopTracer()
+	}
+	logger := params.Logger
+	if logger == nil {
+		logger = klogr.New()
+	}
 	return &Actuator{
-		client:                params.Client,
-		eventRecorder:         params.EventRecorder,
-		kubevirtClientBuilder: params.KubevirtClientBuilder,
+		client:                  params.Client,
+		eventRecorder:           params.EventRecorder,
+		kubevirtClientBuilder:   params.KubevirtClientBuilder,
+		resyncTracker:           params.ResyncTracker,
+		advancedTuningEnabled:   params.AdvancedTuningEnabled,
+		failureBudget:           params.FailureBudget,
+		cloneCoordinator:        params.CloneCoordinator,
+		operationGate:           params.OperationGate,
+		diagnosticsURLTemplates: params.DiagnosticsURLTemplates,
+		machineFilter:           machineFilter,
+		impersonation:           params.Impersonation,
+		tracer:                  tracer,
+		logger:                  logger.WithName("actuator"),
+		infraEventWindow:        params.InfraEventWindow,
+		overcommitProfiles:      params.OvercommitProfiles,
+		metadataPropagation:     params.MetadataPropagation,
+		dnsRegistration:         params.DNSRegistration,
+		infraAccessNamespace:    params.InfraAccessNamespace,
+		operationTimeouts:       params.OperationTimeouts,
+		dryRun:                  params.DryRun,
+		rateLimiters:            newOperationRateLimiters(params.OperationRateLimits),
+	}
+}
+
+// excluded returns true if the machine filter excludes the machine from reconciliation.
+func (a *Actuator) excluded(machine *machinev1.Machine, logger logr.Logger) bool {
+	if a.machineFilter == nil || !a.machineFilter.Excluded(machine) {
+		return false
+	}
+	logger.Info("machine excluded from reconciliation, skipping")
+	return true
+}
+
+// operationLogger returns the logger of a machine operation.
+func (a *Actuator) operationLogger(operation string, machine *machinev1.Machine) logr.Logger {
+	return a.logger.WithValues("machine", machine.GetName(), "namespace", machine.GetNamespace(), "operation", operation)
+}
+
+// scopeParams returns the parameters of the machine scope of an operation.
+func (a *Actuator) scopeParams(ctx context.Context, machine *machinev1.Machine, logger logr.Logger) machineScopeParams {
+	return machineScopeParams{
+		Context:                 ctx,
+		client:                  a.client,
+		machine:                 machine,
+		kubevirtClientBuilder:   a.kubevirtClientBuilder,
+		advancedTuningEnabled:   a.advancedTuningEnabled,
+		failureBudget:           a.failureBudget,
+		cloneCoordinator:        a.cloneCoordinator,
+		diagnosticsURLTemplates: a.diagnosticsURLTemplates,
+		overcommitProfiles:      a.overcommitProfiles,
+		metadataPropagation:     a.metadataPropagation,
+		dnsRegistration:         a.dnsRegistration,
+		infraAccessNamespace:    a.infraAccessNamespace,
+		impersonation:           a.impersonation,
+		logger:                  logger,
+	}
+}
+
+// startSpan starts the span of a machine operation, ended with the error of the operation.
+func (a *Actuator) startSpan(ctx context.Context, operation string, machine *machinev1.Machine) (context.Context, func(error)) {
+	ctx, span := a.tracer.StartSpan(ctx, operation,
+		tracing.String("machine.name", machine.GetName()),
+		tracing.String("machine.namespace", machine.GetNamespace()))
+	return ctx, func(err error) {
+		span.RecordError(err)
+		span.End()
+	}
+}
+
+// enterOperation returns false if machine operations are paused for a handoff, else the
+// operation is tracked until leaveOperation is called.
+func (a *Actuator) enterOperation() bool {
+	return a.operationGate == nil || a.operationGate.enter()
+}
+
+func (a *Actuator) leaveOperation() {
+	if a.operationGate != nil {
+		a.operationGate.leave()
 	}
 }
 
+// resyncDue returns true if the machine has to be synced against its VM.
+func (a *Actuator) resyncDue(machine *machinev1.Machine) bool {
+	return a.resyncTracker == nil || a.resyncTracker.Due(machine, time.Now())
+}
+
 // Set corresponding event based on error. It also returns the original error
-// for convenience, so callers can do "return handleMachineError(...)".
-func (a *Actuator) handleMachineError(machine *machinev1.Machine, err error, eventAction string) error {
-	klog.Errorf("%v error: %v", machine.GetName(), err)
+// for convenience, so callers can do "return handleMachineError(...)", or a
+// RequeueAfterError if the error is transient. The details, if any, are appended
+// to the event message.
+func (a *Actuator) handleMachineError(machine *machinev1.Machine, err error, eventAction string, details ...string) error {
+	a.operationLogger(eventAction, machine).Error(err, "machine operation failed")
 	if eventAction != noEventAction {
-		a.eventRecorder.Eventf(machine, corev1.EventTypeWarning, "Failed"+eventAction, "%v", err)
+		message := err.Error()
+		for _, detail := range details {
+			if detail != "" {
+				message += "; " + detail
+			}
+		}
+		a.eventRecorder.Event(machine, corev1.EventTypeWarning, "Failed"+eventAction, message)
+	}
+	return requeueIfTransient(err)
+}
+
+// recordVMStateChange emits an event if the printable state of the VM, kept in the
+// instance-state annotation of the machine, changed from the previous one.
+func (a *Actuator) recordVMStateChange(machine *machinev1.Machine, previous string) {
+	current := machine.Annotations[machinecontroller.MachineInstanceStateAnnotationName]
+	if current == "" || current == previous {
+		return
+	}
+	eventType := corev1.EventTypeNormal
+	if current == string(kubevirtproviderv1.VMStateCrashLoopBackOff) {
+		eventType = corev1.EventTypeWarning
+	}
+	if previous == "" {
+		a.eventRecorder.Eventf(machine, eventType, vmStateChangedReason, "VM is %s", current)
+		return
+	}
+	a.eventRecorder.Eventf(machine, eventType, vmStateChangedReason, "VM changed from %s to %s", previous, current)
+}
+
+// recordUpdateDecision emits an event if the update policy took another decision about the
+// drift of the provider spec from the VM than the previous one, as reported by the VMUpToDate
+// condition. A VM which never drifted emits none.
+func (a *Actuator) recordUpdateDecision(machine *machinev1.Machine, previous kubevirtproviderv1.KubevirtMachineProviderConditionReason, providerStatus *kubevirtproviderv1.KubevirtMachineProviderStatus) {
+	condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.VMUpToDate)
+	if condition == nil || condition.Reason == previous {
+		return
+	}
+	if condition.Reason == kubevirtproviderv1.VMMatchesSpec && previous == "" {
+		return
+	}
+	eventType := corev1.EventTypeNormal
+	if condition.Reason == kubevirtproviderv1.ReplacementRequired {
+		eventType = corev1.EventTypeWarning
 	}
-	return err
+	a.eventRecorder.Event(machine, eventType, string(condition.Reason), condition.Message)
 }
 
 // Create creates a machine and is invoked by the machine controller.
-func (a *Actuator) Create(ctx context.Context, machine *machinev1.Machine) error {
-	klog.Infof("%s: actuator creating machine", machine.GetName())
-	scope, err := newMachineScope(machineScopeParams{
-		Context:               ctx,
-		client:                a.client,
-		machine:               machine,
-		kubevirtClientBuilder: a.kubevirtClientBuilder,
-	})
+func (a *Actuator) Create(ctx context.Context, machine *machinev1.Machine) (err error) {
+	logger := a.operationLogger("Create", machine)
+	logger.Info("actuator creating machine")
+	ctx, endSpan := a.startSpan(ctx, "Create", machine)
+	defer func() { endSpan(err) }()
+	ctx, cancel := a.withOperationTimeout(ctx, "Create")
+	defer cancel()
+	if a.excluded(machine, logger) {
+		return nil
+	}
+	if a.skipPaused(ctx, machine, "Create", logger) {
+		return &machinecontroller.RequeueAfterError{RequeueAfter: pausedRequeue}
+	}
+	if !a.enterOperation() {
+		logger.Info("machine operations paused for handoff, requeuing")
+		return &machinecontroller.RequeueAfterError{RequeueAfter: operationGatedRequeue}
+	}
+	defer a.leaveOperation()
+	if delay, limited := a.rateLimiters.rateLimited("Create"); limited {
+		logger.Info("machine operation rate limited, requeuing", "delay", delay.String())
+		return &machinecontroller.RequeueAfterError{RequeueAfter: delay}
+	}
+	scope, err := newMachineScope(a.scopeParams(ctx, machine, logger))
 	if err != nil {
 		fmtErr := fmt.Errorf(scopeFailFmt, machine.GetName(), err)
 		return a.handleMachineError(machine, fmtErr, createEventAction)
 	}
+	if a.inDryRun(machine) {
+		if err := newReconciler(scope).dryRunApply(); err != nil {
+			fmtErr := fmt.Errorf(reconcilerFailFmt, machine.GetName(), createEventAction, err)
+			return a.handleMachineError(machine, fmtErr, createEventAction)
+		}
+		return &machinecontroller.RequeueAfterError{RequeueAfter: dryRunRequeue}
+	}
 	if err := newReconciler(scope).create(); err != nil {
 		if err := scope.patchMachine(); err != nil {
 			return err
 		}
+		if scope.waitingForBootstrapData() {
+			// Bootstrap providers commonly create the user data secret after the machine, so
+			// waiting for it is not a creation failure and emits no FailedCreate event
+			return err
+		}
 		fmtErr := fmt.Errorf(reconcilerFailFmt, machine.GetName(), createEventAction, err)
-		return a.handleMachineError(machine, fmtErr, createEventAction)
+		return a.handleMachineError(machine, fmtErr, createEventAction, scope.infraEventSummary(a.infraEventWindow))
 	}
 	a.eventRecorder.Eventf(machine, corev1.EventTypeNormal, createEventAction, "Created Machine %v", machine.GetName())
 	return scope.patchMachine()
@@ -97,14 +337,21 @@ func (a *Actuator) Create(ctx context.Context, machine *machinev1.Machine) error
 
 // Exists determines if the given machine currently exists.
 // A machine which is not terminated is considered as existing.
-func (a *Actuator) Exists(ctx context.Context, machine *machinev1.Machine) (bool, error) {
-	klog.Infof("%s: actuator checking if machine exists", machine.GetName())
-	scope, err := newMachineScope(machineScopeParams{
-		Context:               ctx,
-		client:                a.client,
-		machine:               machine,
-		kubevirtClientBuilder: a.kubevirtClientBuilder,
-	})
+func (a *Actuator) Exists(ctx context.Context, machine *machinev1.Machine) (exists bool, err error) {
+	logger := a.operationLogger("Exists", machine)
+	logger.Info("actuator checking if machine exists")
+	ctx, endSpan := a.startSpan(ctx, "Exists", machine)
+	defer func() { endSpan(err) }()
+	ctx, cancel := a.withOperationTimeout(ctx, "Exists")
+	defer cancel()
+	if a.excluded(machine, logger) {
+		return machine.DeletionTimestamp == nil, nil
+	}
+	if !a.resyncDue(machine) {
+		logger.V(3).Info("machine synced within the resync interval, skipping VM lookup")
+		return true, nil
+	}
+	scope, err := newMachineScope(a.scopeParams(ctx, machine, logger))
 	if err != nil {
 		return false, fmt.Errorf(scopeFailFmt, machine.GetName(), err)
 	}
@@ -112,25 +359,61 @@ func (a *Actuator) Exists(ctx context.Context, machine *machinev1.Machine) (bool
 }
 
 // Update attempts to sync machine state with an existing instance.
-func (a *Actuator) Update(ctx context.Context, machine *machinev1.Machine) error {
-	klog.Infof("%s: actuator updating machine", machine.GetName())
-	scope, err := newMachineScope(machineScopeParams{
-		Context:               ctx,
-		client:                a.client,
-		machine:               machine,
-		kubevirtClientBuilder: a.kubevirtClientBuilder,
-	})
+func (a *Actuator) Update(ctx context.Context, machine *machinev1.Machine) (err error) {
+	logger := a.operationLogger("Update", machine)
+	logger.Info("actuator updating machine")
+	ctx, endSpan := a.startSpan(ctx, "Update", machine)
+	defer func() { endSpan(err) }()
+	ctx, cancel := a.withOperationTimeout(ctx, "Update")
+	defer cancel()
+	if a.excluded(machine, logger) {
+		return nil
+	}
+	if a.skipPaused(ctx, machine, "Update", logger) {
+		return nil
+	}
+	if !a.enterOperation() {
+		logger.Info("machine operations paused for handoff, requeuing")
+		return &machinecontroller.RequeueAfterError{RequeueAfter: operationGatedRequeue}
+	}
+	defer a.leaveOperation()
+	if !a.resyncDue(machine) {
+		logger.V(3).Info("machine synced within the resync interval, skipping update")
+		return nil
+	}
+	if delay, limited := a.rateLimiters.rateLimited("Update"); limited {
+		logger.Info("machine operation rate limited, requeuing", "delay", delay.String())
+		return &machinecontroller.RequeueAfterError{RequeueAfter: delay}
+	}
+	scope, err := newMachineScope(a.scopeParams(ctx, machine, logger))
 	if err != nil {
 		fmtErr := fmt.Errorf(scopeFailFmt, machine.GetName(), err)
 		return a.handleMachineError(machine, fmtErr, updateEventAction)
 	}
-	if err := newReconciler(scope).update(); err != nil {
+	if a.inDryRun(machine) {
+		if err := newReconciler(scope).dryRunApply(); err != nil {
+			fmtErr := fmt.Errorf(reconcilerFailFmt, machine.GetName(), updateEventAction, err)
+			return a.handleMachineError(machine, fmtErr, updateEventAction)
+		}
+		return nil
+	}
+	previousState := machine.Annotations[machinecontroller.MachineInstanceStateAnnotationName]
+	var previousDecision kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	if condition := findProviderCondition(scope.providerStatus.Conditions, kubevirtproviderv1.VMUpToDate); condition != nil {
+		previousDecision = condition.Reason
+	}
+	previousBootVolume := scope.providerStatus.BootVolume.DeepCopy()
+	err = newReconciler(scope).update()
+	a.recordVMStateChange(machine, previousState)
+	a.recordUpdateDecision(machine, previousDecision, scope.providerStatus)
+	a.recordBootVolumeProgress(machine, previousBootVolume, scope.providerStatus)
+	if err != nil {
 		// Update machine and machine status in case it was modified
 		if err := scope.patchMachine(); err != nil {
 			return err
 		}
 		fmtErr := fmt.Errorf(reconcilerFailFmt, machine.GetName(), updateEventAction, err)
-		return a.handleMachineError(machine, fmtErr, updateEventAction)
+		return a.handleMachineError(machine, fmtErr, updateEventAction, scope.infraEventSummary(a.infraEventWindow))
 	}
 
 	previousResourceVersion := scope.machine.ResourceVersion
@@ -146,22 +429,58 @@ func (a *Actuator) Update(ctx context.Context, machine *machinev1.Machine) error
 		a.eventRecorder.Eventf(machine, corev1.EventTypeNormal, updateEventAction, "Updated Machine %v", machine.GetName())
 	}
 
+	if a.resyncTracker != nil {
+		a.resyncTracker.Synced(scope.machine, time.Now())
+	}
+
 	return nil
 }
 
 // Delete deletes a machine and updates its finalizer
-func (a *Actuator) Delete(ctx context.Context, machine *machinev1.Machine) error {
-	klog.Infof("%s: actuator deleting machine", machine.GetName())
-	scope, err := newMachineScope(machineScopeParams{
-		Context:               ctx,
-		client:                a.client,
-		machine:               machine,
-		kubevirtClientBuilder: a.kubevirtClientBuilder,
-	})
+func (a *Actuator) Delete(ctx context.Context, machine *machinev1.Machine) (err error) {
+	logger := a.operationLogger("Delete", machine)
+	logger.Info("actuator deleting machine")
+	ctx, endSpan := a.startSpan(ctx, "Delete", machine)
+	defer func() { endSpan(err) }()
+	ctx, cancel := a.withOperationTimeout(ctx, "Delete")
+	defer cancel()
+	if a.excluded(machine, logger) {
+		return nil
+	}
+	if a.skipPaused(ctx, machine, "Delete", logger) {
+		return &machinecontroller.RequeueAfterError{RequeueAfter: pausedRequeue}
+	}
+	if !a.enterOperation() {
+		logger.Info("machine operations paused for handoff, requeuing")
+		return &machinecontroller.RequeueAfterError{RequeueAfter: operationGatedRequeue}
+	}
+	defer a.leaveOperation()
+	if delay, limited := a.rateLimiters.rateLimited("Delete"); limited {
+		logger.Info("machine operation rate limited, requeuing", "delay", delay.String())
+		return &machinecontroller.RequeueAfterError{RequeueAfter: delay}
+	}
+	if a.resyncTracker != nil {
+		a.resyncTracker.Forget(machine)
+	}
+	if err := CheckDeletionProtection(machine); err != nil {
+		return a.handleMachineError(machine, err, deleteEventAction)
+	}
+	if hooks := pendingLifecycleHooks(machine); len(hooks) > 0 {
+		logger.Info("waiting for lifecycle hooks to be removed before deleting VM, requeuing", "hooks", hooks)
+		return &machinecontroller.RequeueAfterError{RequeueAfter: lifecycleHookRequeue}
+	}
+	scope, err := newMachineScope(a.scopeParams(ctx, machine, logger))
 	if err != nil {
 		fmtErr := fmt.Errorf(scopeFailFmt, machine.GetName(), err)
 		return a.handleMachineError(machine, fmtErr, deleteEventAction)
 	}
+	if a.inDryRun(machine) {
+		if err := newReconciler(scope).dryRunDelete(); err != nil {
+			fmtErr := fmt.Errorf(reconcilerFailFmt, machine.GetName(), deleteEventAction, err)
+			return a.handleMachineError(machine, fmtErr, deleteEventAction)
+		}
+		return &machinecontroller.RequeueAfterError{RequeueAfter: dryRunRequeue}
+	}
 	if err := newReconciler(scope).delete(); err != nil {
 		if err := scope.patchMachine(); err != nil {
 			return err
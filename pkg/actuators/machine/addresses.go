@@ -0,0 +1,67 @@
+package machine
+
+import (
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+// machineAddresses returns the addresses of the machine out of the interfaces reported in the VMI
+// status, filled by the guest agent when it runs in the guest, and the hostname of the guest.
+// Link-local addresses are skipped.
+func machineAddresses(vmi *kubevirtapis.VirtualMachineInstance, machineName, domainSuffix string) []corev1.NodeAddress {
+	if vmi == nil {
+		return nil
+	}
+
+	var addresses []corev1.NodeAddress
+	seen := map[string]bool{}
+	for _, iface := range vmi.Status.Interfaces {
+		ips := iface.IPs
+		if len(ips) == 0 && iface.IP != "" {
+			ips = []string{iface.IP}
+		}
+		for _, address := range ips {
+			// The guest agent reports the addresses in CIDR notation
+			ip, _, err := net.ParseCIDR(address)
+			if err != nil {
+				ip = net.ParseIP(address)
+			}
+			if ip == nil || ip.IsLinkLocalUnicast() || ip.IsLoopback() || seen[ip.String()] {
+				continue
+			}
+			seen[ip.String()] = true
+			addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip.String()})
+		}
+	}
+
+	if hostname := guestHostname(machineName); hostname != "" {
+		internalDNS := hostname
+		if domainSuffix != "" {
+			if fqdn, err := guestFQDN(machineName, domainSuffix); err == nil {
+				internalDNS = fqdn
+			}
+		}
+		addresses = append(addresses,
+			corev1.NodeAddress{Type: corev1.NodeInternalDNS, Address: internalDNS},
+			corev1.NodeAddress{Type: corev1.NodeHostName, Address: hostname},
+		)
+	}
+	return addresses
+}
+
+// setAddresses sets the addresses of the machine once the VMI reports them, restricted to the
+// address families of the provider spec, the primary family first. When the provider spec
+// requires the guest agent, the addresses are only set once it is connected.
+func (s *machineScope) setAddresses(vmi *kubevirtapis.VirtualMachineInstance) {
+	if vmi == nil || vmi.Status.Phase != kubevirtapis.Running {
+		return
+	}
+	if s.providerSpec.RequireGuestAgent && !guestAgentConnected(vmi) {
+		return
+	}
+	addresses := machineAddresses(vmi, s.machine.Name, s.providerSpec.DomainSuffix)
+	addresses = filterAddressFamilies(addresses, s.providerSpec.AddressFamilyPolicy)
+	s.machine.Status.Addresses = orderAddressFamilies(addresses, s.providerSpec.AddressFamilyPolicy)
+}
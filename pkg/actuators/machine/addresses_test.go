@@ -0,0 +1,82 @@
+package machine
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+func TestMachineAddresses(t *testing.T) {
+	vmi := &kubevirtapis.VirtualMachineInstance{
+		Status: kubevirtapis.VirtualMachineInstanceStatus{
+			Interfaces: []kubevirtapis.VirtualMachineInstanceNetworkInterface{
+				{
+					Name: "default",
+					IP:   "10.128.0.10",
+					IPs:  []string{"10.128.0.10/23", "fe80::1/64", "fd00::10/64"},
+				},
+				{
+					Name: "data",
+					IP:   "192.168.10.5",
+				},
+				{
+					Name: "duplicate",
+					IPs:  []string{"10.128.0.10"},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		testcase          string
+		vmi               *kubevirtapis.VirtualMachineInstance
+		machineName       string
+		domainSuffix      string
+		expectedAddresses []corev1.NodeAddress
+	}{
+		{
+			testcase:          "no VMI",
+			machineName:       "worker-abcde",
+			expectedAddresses: nil,
+		},
+		{
+			testcase:    "guest addresses and hostname",
+			vmi:         vmi,
+			machineName: "worker-abcde",
+			expectedAddresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.128.0.10"},
+				{Type: corev1.NodeInternalIP, Address: "fd00::10"},
+				{Type: corev1.NodeInternalIP, Address: "192.168.10.5"},
+				{Type: corev1.NodeInternalDNS, Address: "worker-abcde"},
+				{Type: corev1.NodeHostName, Address: "worker-abcde"},
+			},
+		},
+		{
+			testcase:     "FQDN as internal DNS",
+			vmi:          &kubevirtapis.VirtualMachineInstance{},
+			machineName:  "worker-abcde",
+			domainSuffix: "cluster.example.com.",
+			expectedAddresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalDNS, Address: "worker-abcde.cluster.example.com"},
+				{Type: corev1.NodeHostName, Address: "worker-abcde"},
+			},
+		},
+		{
+			testcase:          "machine name not a valid hostname",
+			vmi:               &kubevirtapis.VirtualMachineInstance{},
+			machineName:       "Worker.abcde",
+			expectedAddresses: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			addresses := machineAddresses(tc.vmi, tc.machineName, tc.domainSuffix)
+			if !reflect.DeepEqual(addresses, tc.expectedAddresses) {
+				t.Errorf("Expected addresses %v, got %v", tc.expectedAddresses, addresses)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package machine
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// unspecifiedIPv4 and unspecifiedIPv6 let the kubelet pick the node IP of the family
+	unspecifiedIPv4 = "0.0.0.0"
+	unspecifiedIPv6 = "::"
+)
+
+// addressFamilies returns whether the policy allows IPv4 and IPv6 addresses.
+func addressFamilies(policy kubevirtproviderv1.AddressFamilyPolicy) (ipv4, ipv6 bool) {
+	switch policy {
+	case kubevirtproviderv1.AddressFamilyIPv4:
+		return true, false
+	case kubevirtproviderv1.AddressFamilyIPv6:
+		return false, true
+	default:
+		return true, true
+	}
+}
+
+// allowedAddress returns an error if the IP does not belong to the families of the policy.
+func allowedAddress(policy kubevirtproviderv1.AddressFamilyPolicy, ip net.IP) error {
+	ipv4, ipv6 := addressFamilies(policy)
+	if isIPv4 := ip.To4() != nil; (isIPv4 && !ipv4) || (!isIPv4 && !ipv6) {
+		return fmt.Errorf("address %s is not allowed by addressFamilyPolicy %s", ip, policy)
+	}
+	return nil
+}
+
+// validateAddressFamilyPolicy returns an error if the policy is unsupported or the static
+// addresses of the provider spec do not belong to its families. Dual requires the static IP
+// addresses, if any, to cover both families.
+func validateAddressFamilyPolicy(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	policy := providerSpec.AddressFamilyPolicy
+	switch policy {
+	case kubevirtproviderv1.AddressFamilyIPv4, kubevirtproviderv1.AddressFamilyIPv6, kubevirtproviderv1.AddressFamilyDual:
+	default:
+		return fmt.Errorf("unsupported addressFamilyPolicy %q, must be %s, %s or %s", policy,
+			kubevirtproviderv1.AddressFamilyIPv4, kubevirtproviderv1.AddressFamilyIPv6, kubevirtproviderv1.AddressFamilyDual)
+	}
+
+	var hasIPv4, hasIPv6 bool
+	for _, address := range providerSpec.StaticIPAddresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return fmt.Errorf("invalid static IP address %q", address)
+		}
+		if err := allowedAddress(policy, ip); err != nil {
+			return err
+		}
+		hasIPv4 = hasIPv4 || ip.To4() != nil
+		hasIPv6 = hasIPv6 || ip.To4() == nil
+	}
+	if policy == kubevirtproviderv1.AddressFamilyDual && len(providerSpec.StaticIPAddresses) > 0 && !(hasIPv4 && hasIPv6) {
+		return fmt.Errorf("addressFamilyPolicy %s requires staticIPAddresses of both IPv4 and IPv6", policy)
+	}
+
+	if providerSpec.NetworkData != nil {
+		for _, config := range interfaceConfigs(providerSpec.NetworkData) {
+			for _, address := range config.Addresses {
+				ip, _, err := net.ParseCIDR(address)
+				if err != nil {
+					return fmt.Errorf("invalid address %q, must be in CIDR notation", address)
+				}
+				if err := allowedAddress(policy, ip); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// interfaceConfigs returns the configurations of the interfaces of the network data.
+func interfaceConfigs(networkData *kubevirtproviderv1.NetworkData) []*kubevirtproviderv1.InterfaceConfig {
+	var configs []*kubevirtproviderv1.InterfaceConfig
+	for i := range networkData.Ethernets {
+		configs = append(configs, &networkData.Ethernets[i].InterfaceConfig)
+	}
+	for i := range networkData.VLANs {
+		configs = append(configs, &networkData.VLANs[i].InterfaceConfig)
+	}
+	return configs
+}
+
+// resolveNodeIPs returns the node IPs passed to the kubelet: the static IP addresses, the
+// first one of each family of the policy in the order of the families when the policy is set,
+// or the unspecified address of the family of single family policies without them.
+func resolveNodeIPs(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) ([]string, error) {
+	ips, err := parseStaticIPAddresses(providerSpec.StaticIPAddresses)
+	if err != nil {
+		return nil, err
+	}
+	policy := providerSpec.AddressFamilyPolicy
+	if policy == "" {
+		return ips, nil
+	}
+	if err := validateAddressFamilyPolicy(providerSpec); err != nil {
+		return nil, err
+	}
+
+	var ipv4, ipv6 string
+	for _, ip := range ips {
+		if net.ParseIP(ip).To4() != nil {
+			if ipv4 == "" {
+				ipv4 = ip
+			}
+		} else if ipv6 == "" {
+			ipv6 = ip
+		}
+	}
+	switch policy {
+	case kubevirtproviderv1.AddressFamilyIPv4:
+		if ipv4 == "" {
+			ipv4 = unspecifiedIPv4
+		}
+		return []string{ipv4}, nil
+	case kubevirtproviderv1.AddressFamilyIPv6:
+		if ipv6 == "" {
+			ipv6 = unspecifiedIPv6
+		}
+		return []string{ipv6}, nil
+	default:
+		if len(ips) == 0 {
+			// The kubelet picks the node IPs of both families
+			return nil, nil
+		}
+		return []string{ipv4, ipv6}, nil
+	}
+}
+
+// filterAddressFamilies drops the IP addresses outside of the families of the policy, the
+// DNS and hostname addresses are kept.
+func filterAddressFamilies(addresses []corev1.NodeAddress, policy kubevirtproviderv1.AddressFamilyPolicy) []corev1.NodeAddress {
+	if policy == "" {
+		return addresses
+	}
+	var filtered []corev1.NodeAddress
+	for _, address := range addresses {
+		if ip := net.ParseIP(address.Address); ip != nil && allowedAddress(policy, ip) != nil {
+			continue
+		}
+		filtered = append(filtered, address)
+	}
+	return filtered
+}
+
+// orderAddressFamilies moves the IPv4 addresses ahead of the IPv6 ones under the Dual policy,
+// IPv4 being its primary family, keeping the order of the addresses within each family.
+func orderAddressFamilies(addresses []corev1.NodeAddress, policy kubevirtproviderv1.AddressFamilyPolicy) []corev1.NodeAddress {
+	if policy != kubevirtproviderv1.AddressFamilyDual {
+		return addresses
+	}
+	var ipv4, others []corev1.NodeAddress
+	for _, address := range addresses {
+		if ip := net.ParseIP(address.Address); ip != nil && ip.To4() != nil {
+			ipv4 = append(ipv4, address)
+		} else {
+			others = append(others, address)
+		}
+	}
+	return append(ipv4, others...)
+}
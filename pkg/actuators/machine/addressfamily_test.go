@@ -0,0 +1,173 @@
+package machine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestResolveNodeIPs(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		policy      kubevirtproviderv1.AddressFamilyPolicy
+		addresses   []string
+		networkData *kubevirtproviderv1.NetworkData
+		expected    []string
+		expectError bool
+	}{
+		{
+			testcase:  "no policy keeps the static addresses",
+			addresses: []string{"fd00::10", "192.168.1.10"},
+			expected:  []string{"fd00::10", "192.168.1.10"},
+		},
+		{
+			testcase: "ipv4 without static addresses",
+			policy:   kubevirtproviderv1.AddressFamilyIPv4,
+			expected: []string{"0.0.0.0"},
+		},
+		{
+			testcase: "ipv6 without static addresses",
+			policy:   kubevirtproviderv1.AddressFamilyIPv6,
+			expected: []string{"::"},
+		},
+		{
+			testcase: "dual without static addresses",
+			policy:   kubevirtproviderv1.AddressFamilyDual,
+		},
+		{
+			testcase:  "dual orders ipv4 first",
+			policy:    kubevirtproviderv1.AddressFamilyDual,
+			addresses: []string{"fd00::10", "192.168.1.10"},
+			expected:  []string{"192.168.1.10", "fd00::10"},
+		},
+		{
+			testcase:    "dual requires both families",
+			policy:      kubevirtproviderv1.AddressFamilyDual,
+			addresses:   []string{"192.168.1.10"},
+			expectError: true,
+		},
+		{
+			testcase:    "ipv4 rejects ipv6 static addresses",
+			policy:      kubevirtproviderv1.AddressFamilyIPv4,
+			addresses:   []string{"192.168.1.10", "fd00::10"},
+			expectError: true,
+		},
+		{
+			testcase: "ipv6 rejects ipv4 network data addresses",
+			policy:   kubevirtproviderv1.AddressFamilyIPv6,
+			networkData: &kubevirtproviderv1.NetworkData{
+				Ethernets: []kubevirtproviderv1.EthernetInterface{{
+					Name:            "eth0",
+					InterfaceConfig: kubevirtproviderv1.InterfaceConfig{Addresses: []string{"192.168.1.10/24"}},
+				}},
+			},
+			expectError: true,
+		},
+		{
+			testcase:    "unsupported policy",
+			policy:      "IPv5",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				AddressFamilyPolicy: tc.policy,
+				StaticIPAddresses:   tc.addresses,
+				NetworkData:         tc.networkData,
+			}
+			ips, err := resolveNodeIPs(providerSpec)
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(ips, tc.expected) {
+				t.Errorf("Expected node IPs %v, got %v", tc.expected, ips)
+			}
+		})
+	}
+}
+
+func TestFilterAddressFamilies(t *testing.T) {
+	addresses := []corev1.NodeAddress{
+		{Type: corev1.NodeInternalIP, Address: "192.168.1.10"},
+		{Type: corev1.NodeInternalIP, Address: "fd00::10"},
+		{Type: corev1.NodeHostName, Address: "worker-abcde"},
+	}
+
+	filtered := filterAddressFamilies(addresses, kubevirtproviderv1.AddressFamilyIPv6)
+	expected := []corev1.NodeAddress{addresses[1], addresses[2]}
+	if !reflect.DeepEqual(filtered, expected) {
+		t.Errorf("Expected addresses %v, got %v", expected, filtered)
+	}
+
+	if filtered := filterAddressFamilies(addresses, kubevirtproviderv1.AddressFamilyDual); !reflect.DeepEqual(filtered, addresses) {
+		t.Errorf("Expected addresses %v, got %v", addresses, filtered)
+	}
+}
+
+func TestRenderNetworkDataAddressFamilies(t *testing.T) {
+	networkData := &kubevirtproviderv1.NetworkData{
+		Ethernets: []kubevirtproviderv1.EthernetInterface{{
+			Name:            "eth0",
+			InterfaceConfig: kubevirtproviderv1.InterfaceConfig{DHCP4: true},
+		}},
+	}
+
+	testCases := []struct {
+		policy   kubevirtproviderv1.AddressFamilyPolicy
+		expected []string
+	}{
+		{policy: "", expected: []string{"dhcp4: true"}},
+		{policy: kubevirtproviderv1.AddressFamilyIPv4, expected: []string{"dhcp4: true"}},
+		{policy: kubevirtproviderv1.AddressFamilyIPv6, expected: []string{"dhcp4: false", "dhcp6: true"}},
+		{policy: kubevirtproviderv1.AddressFamilyDual, expected: []string{"dhcp4: true", "dhcp6: true"}},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			rendered, err := renderNetworkData(networkData, tc.policy)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(string(rendered), expected) {
+					t.Errorf("Expected network data with %q, got %s", expected, rendered)
+				}
+			}
+			if tc.policy == "" || tc.policy == kubevirtproviderv1.AddressFamilyIPv4 {
+				if strings.Contains(string(rendered), "dhcp6") {
+					t.Errorf("Expected network data without dhcp6, got %s", rendered)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderAddressFamilies(t *testing.T) {
+	addresses := []corev1.NodeAddress{
+		{Type: corev1.NodeInternalIP, Address: "fd00::10"},
+		{Type: corev1.NodeInternalIP, Address: "192.168.1.10"},
+		{Type: corev1.NodeInternalIP, Address: "10.128.0.10"},
+		{Type: corev1.NodeHostName, Address: "worker-abcde"},
+	}
+
+	ordered := orderAddressFamilies(addresses, kubevirtproviderv1.AddressFamilyDual)
+	expected := []corev1.NodeAddress{addresses[1], addresses[2], addresses[0], addresses[3]}
+	if !reflect.DeepEqual(ordered, expected) {
+		t.Errorf("Expected addresses %v, got %v", expected, ordered)
+	}
+
+	if ordered := orderAddressFamilies(addresses, ""); !reflect.DeepEqual(ordered, addresses) {
+		t.Errorf("Expected addresses %v, got %v", addresses, ordered)
+	}
+}
@@ -0,0 +1,70 @@
+package machine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+var (
+	// advancedTuningAnnotations are the annotations allowed in the advanced tuning section.
+	advancedTuningAnnotations = []string{
+		"hooks.kubevirt.io/hookSidecars",
+	}
+	// advancedTuningAnnotationPrefixes are the annotation prefixes allowed in the advanced tuning section.
+	advancedTuningAnnotationPrefixes = []string{
+		"smbios.vm.kubevirt.io/",
+	}
+)
+
+func advancedTuningAnnotationAllowed(key string) bool {
+	for _, annotation := range advancedTuningAnnotations {
+		if key == annotation {
+			return true
+		}
+	}
+	for _, prefix := range advancedTuningAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// advancedTuningAnnotationKeys returns the sorted annotation keys of the advanced tuning section.
+func advancedTuningAnnotationKeys(tuning *kubevirtproviderv1.AdvancedTuning) []string {
+	keys := []string{}
+	for key := range tuning.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateAdvancedTuning rejects annotations outside of the allowlist.
+func validateAdvancedTuning(tuning *kubevirtproviderv1.AdvancedTuning) error {
+	disallowed := []string{}
+	for _, key := range advancedTuningAnnotationKeys(tuning) {
+		if !advancedTuningAnnotationAllowed(key) {
+			disallowed = append(disallowed, key)
+		}
+	}
+	if len(disallowed) > 0 {
+		return fmt.Errorf("advancedTuning annotations %s are not allowed", strings.Join(disallowed, ", "))
+	}
+	return nil
+}
+
+// advancedTuningCondition reports the advanced tuning annotations applied to the VM.
+func advancedTuningCondition(tuning *kubevirtproviderv1.AdvancedTuning) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.AdvancedTuningActive,
+		Status:  corev1.ConditionTrue,
+		Reason:  kubevirtproviderv1.AdvancedTuningApplied,
+		Message: fmt.Sprintf("VirtualMachine is tuned with annotations %s", strings.Join(advancedTuningAnnotationKeys(tuning), ", ")),
+	}
+}
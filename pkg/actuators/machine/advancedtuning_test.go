@@ -0,0 +1,73 @@
+package machine
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateAdvancedTuning(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		annotations map[string]string
+		expectError bool
+	}{
+		{
+			testcase: "hook sidecars",
+			annotations: map[string]string{
+				"hooks.kubevirt.io/hookSidecars": `[{"image": "registry.example.com/hook:latest"}]`,
+			},
+		},
+		{
+			testcase: "smbios",
+			annotations: map[string]string{
+				"smbios.vm.kubevirt.io/baseBoardManufacturer": "Example",
+			},
+		},
+		{
+			testcase: "disallowed annotation",
+			annotations: map[string]string{
+				"smbios.vm.kubevirt.io/baseBoardManufacturer": "Example",
+				"kubevirt.io/ignitiondata":                    "{}",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateAdvancedTuning(&kubevirtproviderv1.AdvancedTuning{Annotations: tc.annotations})
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("Expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), "kubevirt.io/ignitiondata") {
+					t.Errorf("Expected error to name the disallowed annotation, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAdvancedTuningCondition(t *testing.T) {
+	condition := advancedTuningCondition(&kubevirtproviderv1.AdvancedTuning{
+		Annotations: map[string]string{
+			"smbios.vm.kubevirt.io/baseBoardManufacturer": "Example",
+			"hooks.kubevirt.io/hookSidecars":              "[]",
+		},
+	})
+
+	if condition.Type != kubevirtproviderv1.AdvancedTuningActive || condition.Status != corev1.ConditionTrue || condition.Reason != kubevirtproviderv1.AdvancedTuningApplied {
+		t.Errorf("Unexpected condition %+v", condition)
+	}
+	if expected := "VirtualMachine is tuned with annotations hooks.kubevirt.io/hookSidecars, smbios.vm.kubevirt.io/baseBoardManufacturer"; condition.Message != expected {
+		t.Errorf("Expected message %q, got %q", expected, condition.Message)
+	}
+}
@@ -0,0 +1,80 @@
+package machine
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// imageArchitectureLabel is the label importers publish the architecture of a boot image with.
+	imageArchitectureLabel = "template.kubevirt.io/architecture"
+
+	// arm64MachineType is the QEMU machine type of arm64 guests, KubeVirt defaults to q35.
+	arm64MachineType = "virt"
+)
+
+// resolveArchitecture returns the architecture of the guest, validating it against the
+// settings that depend on it.
+func resolveArchitecture(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (kubevirtproviderv1.Architecture, error) {
+	architecture := providerSpec.Architecture
+	switch architecture {
+	case "":
+		if providerSpec.AllowEmulation {
+			return "", fmt.Errorf("allowEmulation is not supported on architecture %q", kubevirtproviderv1.ArchitectureAMD64)
+		}
+		return kubevirtproviderv1.ArchitectureAMD64, nil
+	case kubevirtproviderv1.ArchitectureAMD64:
+		if providerSpec.AllowEmulation {
+			return "", fmt.Errorf("allowEmulation is not supported on architecture %q", architecture)
+		}
+	case kubevirtproviderv1.ArchitectureARM64:
+		if providerSpec.Windows != nil {
+			return "", fmt.Errorf("windows guests require architecture %q", kubevirtproviderv1.ArchitectureAMD64)
+		}
+		if providerSpec.DiskBus == kubevirtproviderv1.DiskBusSATA {
+			return "", fmt.Errorf("diskBus %q is not supported on architecture %q", kubevirtproviderv1.DiskBusSATA, architecture)
+		}
+	default:
+		return "", fmt.Errorf("unsupported architecture %q, must be one of %q or %q", architecture, kubevirtproviderv1.ArchitectureAMD64, kubevirtproviderv1.ArchitectureARM64)
+	}
+
+	if nodeArchitecture, ok := providerSpec.NodeSelector[corev1.LabelArchStable]; ok && nodeArchitecture != string(architecture) {
+		return "", fmt.Errorf("nodeSelector %s=%s conflicts with architecture %q", corev1.LabelArchStable, nodeArchitecture, architecture)
+	}
+	return architecture, nil
+}
+
+// validateSourceArchitecture returns an error if the source PVC is labeled as a boot image of
+// another architecture than the guest. Unlabeled source PVCs are assumed to match.
+func validateSourceArchitecture(sourcePvc *corev1.PersistentVolumeClaim, architecture kubevirtproviderv1.Architecture) error {
+	if imageArchitecture, ok := sourcePvc.Labels[imageArchitectureLabel]; ok && imageArchitecture != string(architecture) {
+		return fmt.Errorf("source PVC %s is a boot image of architecture %q, not %q", sourcePvc.Name, imageArchitecture, architecture)
+	}
+	return nil
+}
+
+// applyArchitecture sets the machine type and firmware of arm64 guests and places the VM onto
+// the infra nodes of its architecture, declared in the provider spec. Emulated guests are
+// placed the same way, the emulation does not translate between architectures.
+func applyArchitecture(spec *kubevirtapis.VirtualMachineInstanceSpec, architecture kubevirtproviderv1.Architecture) {
+	if architecture == kubevirtproviderv1.ArchitectureARM64 {
+		// arm64 guests only boot with UEFI, which KubeVirt supports without secure boot there
+		secureBoot := false
+		spec.Domain.Machine.Type = arm64MachineType
+		if spec.Domain.Firmware == nil {
+			spec.Domain.Firmware = &kubevirtapis.Firmware{}
+		}
+		spec.Domain.Firmware.Bootloader = &kubevirtapis.Bootloader{
+			EFI: &kubevirtapis.EFI{SecureBoot: &secureBoot},
+		}
+	}
+
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = map[string]string{}
+	}
+	spec.NodeSelector[corev1.LabelArchStable] = string(architecture)
+}
@@ -0,0 +1,165 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestResolveArchitecture(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		expected     kubevirtproviderv1.Architecture
+		expectError  bool
+	}{
+		{
+			testcase: "default",
+			expected: kubevirtproviderv1.ArchitectureAMD64,
+		},
+		{
+			testcase:     "arm64",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Architecture: kubevirtproviderv1.ArchitectureARM64, AllowEmulation: true},
+			expected:     kubevirtproviderv1.ArchitectureARM64,
+		},
+		{
+			testcase:     "unsupported",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Architecture: "s390x"},
+			expectError:  true,
+		},
+		{
+			testcase:     "emulation without architecture",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{AllowEmulation: true},
+			expectError:  true,
+		},
+		{
+			testcase:     "amd64 emulation",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Architecture: kubevirtproviderv1.ArchitectureAMD64, AllowEmulation: true},
+			expectError:  true,
+		},
+		{
+			testcase:     "arm64 windows",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Architecture: kubevirtproviderv1.ArchitectureARM64, Windows: &kubevirtproviderv1.Windows{}},
+			expectError:  true,
+		},
+		{
+			testcase:     "arm64 sata",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Architecture: kubevirtproviderv1.ArchitectureARM64, DiskBus: kubevirtproviderv1.DiskBusSATA},
+			expectError:  true,
+		},
+		{
+			testcase: "conflicting node selector",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				Architecture: kubevirtproviderv1.ArchitectureARM64,
+				NodeSelector: map[string]string{corev1.LabelArchStable: "amd64"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			architecture, err := resolveArchitecture(&tc.providerSpec)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+			if architecture != tc.expected {
+				t.Errorf("Expected architecture %q, got %q", tc.expected, architecture)
+			}
+		})
+	}
+}
+
+func TestBuildVMArchitecture(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spec := vm.Spec.Template.Spec; spec.Domain.Machine.Type != "" || spec.Domain.Firmware != nil || spec.NodeSelector != nil {
+		t.Errorf("Expected the default VM to be left to the KubeVirt defaults, got %v, %v and %v", spec.Domain.Machine, spec.Domain.Firmware, spec.NodeSelector)
+	}
+
+	vm, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos-aarch64", Architecture: kubevirtproviderv1.ArchitectureARM64}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec := vm.Spec.Template.Spec
+	if spec.Domain.Machine.Type != arm64MachineType {
+		t.Errorf("Expected machine type %s, got %s", arm64MachineType, spec.Domain.Machine.Type)
+	}
+	if firmware := spec.Domain.Firmware; firmware == nil || firmware.Bootloader == nil || firmware.Bootloader.EFI == nil || *firmware.Bootloader.EFI.SecureBoot {
+		t.Errorf("Expected UEFI without secure boot, got %v", firmware)
+	}
+	if nodeArchitecture := spec.NodeSelector[corev1.LabelArchStable]; nodeArchitecture != "arm64" {
+		t.Errorf("Expected the VM to be scheduled onto arm64 nodes, got %v", spec.NodeSelector)
+	}
+
+	vm, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:  "rhcos-aarch64",
+		Architecture:   kubevirtproviderv1.ArchitectureARM64,
+		AllowEmulation: true,
+		Affinity:       &corev1.Affinity{PodAffinity: &corev1.PodAffinity{}},
+	}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec = vm.Spec.Template.Spec
+	if nodeArchitecture := spec.NodeSelector[corev1.LabelArchStable]; nodeArchitecture != "arm64" {
+		t.Errorf("Expected the emulated VM to be scheduled onto arm64 nodes, got %v", spec.NodeSelector)
+	}
+	if spec.Affinity.PodAffinity == nil || spec.Affinity.NodeAffinity != nil {
+		t.Errorf("Expected the provider spec affinity to be left alone, got %v", spec.Affinity)
+	}
+}
+
+func TestRenderVMSourceArchitecture(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		labels      map[string]string
+		expectError bool
+	}{
+		{
+			testcase: "unlabeled",
+		},
+		{
+			testcase: "matching",
+			labels:   map[string]string{imageArchitectureLabel: "arm64"},
+		},
+		{
+			testcase:    "other architecture",
+			labels:      map[string]string{imageArchitectureLabel: "amd64"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			sourcePvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "rhcos-aarch64", Namespace: "tenant-a", Labels: tc.labels}}
+			client.EXPECT().GetPersistentVolumeClaim(gomock.Any(), "tenant-a", "rhcos-aarch64", gomock.Any()).Return(sourcePvc, nil)
+
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+			providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos-aarch64", Architecture: kubevirtproviderv1.ArchitectureARM64}
+			vm, _, err := renderVM(context.Background(), machine, providerSpec, nil, []byte("#cloud-config\n"), client)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+			if vm != nil {
+				if _, ok := vm.Annotations[bootImageSourceUIDAnnotation]; ok {
+					t.Errorf("Expected the boot image not to be tracked, got %v", vm.Annotations)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package machine
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// bootImageSourceUIDAnnotation records on the VM the UID of the source PVC its root disk was cloned from.
+// Importers such as DataImportCron publish a new image by replacing the source PVC, which changes its UID.
+const bootImageSourceUIDAnnotation = "kubevirtproviderconfig.openshift.io/boot-image-source-uid"
+
+// setBootImageSource annotates the VM with the revision of the source PVC it is cloned from.
+func setBootImageSource(vm *kubevirtapis.VirtualMachine, sourcePvc *corev1.PersistentVolumeClaim) {
+	if vm.Annotations == nil {
+		vm.Annotations = make(map[string]string)
+	}
+	vm.Annotations[bootImageSourceUIDAnnotation] = string(sourcePvc.UID)
+}
+
+// bootImageCondition computes the OutdatedBootImage condition of the VM against the current source PVC.
+func bootImageCondition(vm *kubevirtapis.VirtualMachine, sourcePvc *corev1.PersistentVolumeClaim) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	sourceUID, ok := vm.Annotations[bootImageSourceUIDAnnotation]
+	if !ok {
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.OutdatedBootImage,
+			Status:  corev1.ConditionUnknown,
+			Reason:  kubevirtproviderv1.BootImageUnknown,
+			Message: fmt.Sprintf("VirtualMachine is missing the %s annotation", bootImageSourceUIDAnnotation),
+		}
+	}
+
+	if sourceUID != string(sourcePvc.UID) {
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.OutdatedBootImage,
+			Status:  corev1.ConditionTrue,
+			Reason:  kubevirtproviderv1.BootImageUpdated,
+			Message: fmt.Sprintf("Boot image source %s was updated after the machine was created", sourcePvc.Name),
+		}
+	}
+
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.OutdatedBootImage,
+		Status:  corev1.ConditionFalse,
+		Reason:  kubevirtproviderv1.BootImageUpToDate,
+		Message: fmt.Sprintf("Machine runs the current revision of boot image source %s", sourcePvc.Name),
+	}
+}
@@ -0,0 +1,79 @@
+package machine
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestBootImageCondition(t *testing.T) {
+	sourcePvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "rhcos",
+			UID:  "current-uid",
+		},
+	}
+
+	testCases := []struct {
+		testcase       string
+		annotations    map[string]string
+		expectedStatus corev1.ConditionStatus
+		expectedReason kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase:       "up to date",
+			annotations:    map[string]string{bootImageSourceUIDAnnotation: "current-uid"},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: kubevirtproviderv1.BootImageUpToDate,
+		},
+		{
+			testcase:       "outdated",
+			annotations:    map[string]string{bootImageSourceUIDAnnotation: "previous-uid"},
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: kubevirtproviderv1.BootImageUpdated,
+		},
+		{
+			testcase:       "not tracked",
+			expectedStatus: corev1.ConditionUnknown,
+			expectedReason: kubevirtproviderv1.BootImageUnknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+
+			condition := bootImageCondition(vm, sourcePvc)
+			if condition.Type != kubevirtproviderv1.OutdatedBootImage {
+				t.Errorf("Expected condition type %q, got %q", kubevirtproviderv1.OutdatedBootImage, condition.Type)
+			}
+			if condition.Status != tc.expectedStatus {
+				t.Errorf("Expected condition status %q, got %q", tc.expectedStatus, condition.Status)
+			}
+			if condition.Reason != tc.expectedReason {
+				t.Errorf("Expected condition reason %q, got %q", tc.expectedReason, condition.Reason)
+			}
+		})
+	}
+}
+
+func TestSetBootImageSource(t *testing.T) {
+	vm := &kubevirtapis.VirtualMachine{}
+	setBootImageSource(vm, &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "source-uid",
+		},
+	})
+
+	if got := vm.Annotations[bootImageSourceUIDAnnotation]; got != "source-uid" {
+		t.Errorf("Expected annotation %q to be %q, got %q", bootImageSourceUIDAnnotation, "source-uid", got)
+	}
+}
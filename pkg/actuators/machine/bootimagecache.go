@@ -0,0 +1,312 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/codec"
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+)
+
+const (
+	// bootImageCacheLabel is set on the DataImportCrons managed by the boot image cache to the
+	// cluster ID of the tenant cluster whose machines reference them.
+	bootImageCacheLabel = "kubevirtproviderconfig.openshift.io/boot-image-cache"
+	// defaultBootImageSchedule polls the registry for a new boot image every 12 hours
+	defaultBootImageSchedule = "0 */12 * * *"
+	// defaultBootImageImportsToKeep is the number of imported boot images kept by default
+	defaultBootImageImportsToKeep = 3
+)
+
+// validateBootImageImport returns an error if the registry URL, the schedule or the storage
+// of the boot image import are invalid.
+func validateBootImageImport(bootImageImport *kubevirtproviderv1.BootImageImport) error {
+	if !strings.HasPrefix(bootImageImport.URL, "docker://") && !strings.HasPrefix(bootImageImport.URL, "oci-archive://") {
+		return fmt.Errorf("bootImageImport url %q must be a docker:// or oci-archive:// registry URL", bootImageImport.URL)
+	}
+	if bootImageImport.Schedule != "" && len(strings.Fields(bootImageImport.Schedule)) != 5 {
+		return fmt.Errorf("bootImageImport schedule %q must be a cron schedule of 5 fields", bootImageImport.Schedule)
+	}
+	if bootImageImport.ImportsToKeep != nil && *bootImageImport.ImportsToKeep < 1 {
+		return fmt.Errorf("bootImageImport importsToKeep must be at least 1, got %d", *bootImageImport.ImportsToKeep)
+	}
+	if bootImageImport.Storage != "" {
+		if _, err := resource.ParseQuantity(bootImageImport.Storage); err != nil {
+			return fmt.Errorf("invalid bootImageImport storage %q: %v", bootImageImport.Storage, err)
+		}
+	}
+	return nil
+}
+
+// resolveBootImageSource returns the name of the source PVC root disks are cloned from: the
+// PVC the DataSource named by sourcePvcName points to for imported boot images, sourcePvcName
+// itself otherwise. It fails until the DataImportCron imported a first image.
+func resolveBootImageSource(ctx context.Context, client kubevirtclient.Client, namespace string, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (string, error) {
+	if providerSpec.BootImageImport == nil {
+		return providerSpec.SourcePvcName, nil
+	}
+
+	dataSource, err := client.GetDataSource(ctx, namespace, providerSpec.SourcePvcName, &metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get DataSource %s/%s: %w", namespace, providerSpec.SourcePvcName, err)
+	}
+	name, _, _ := unstructured.NestedString(dataSource.Object, "spec", "source", "pvc", "name")
+	if name == "" {
+		return "", fmt.Errorf("DataSource %s/%s does not reference an imported boot image yet", namespace, providerSpec.SourcePvcName)
+	}
+	if pvcNamespace, _, _ := unstructured.NestedString(dataSource.Object, "spec", "source", "pvc", "namespace"); pvcNamespace != "" && pvcNamespace != namespace {
+		return "", fmt.Errorf("DataSource %s/%s references PVC %s/%s outside of the namespace of the VMs", namespace, providerSpec.SourcePvcName, pvcNamespace, name)
+	}
+	return name, nil
+}
+
+// bootImageCron renders the DataImportCron importing the boot image of the provider spec into
+// the DataSource named by sourcePvcName.
+func bootImageCron(namespace, clusterID string, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) *unstructured.Unstructured {
+	bootImageImport := providerSpec.BootImageImport
+	schedule := bootImageImport.Schedule
+	if schedule == "" {
+		schedule = defaultBootImageSchedule
+	}
+	importsToKeep := int64(defaultBootImageImportsToKeep)
+	if bootImageImport.ImportsToKeep != nil {
+		importsToKeep = int64(*bootImageImport.ImportsToKeep)
+	}
+	storage := bootImageImport.Storage
+	if storage == "" {
+		storage = providerSpec.RequestedStorage
+	}
+	if storage == "" {
+		storage = defaultRequestedStorage
+	}
+
+	storageSpec := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"storage": storage},
+		},
+	}
+	if providerSpec.StorageClassName != "" {
+		storageSpec["storageClassName"] = providerSpec.StorageClassName
+	}
+
+	cron := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"managedDataSource": providerSpec.SourcePvcName,
+			"schedule":          schedule,
+			"importsToKeep":     importsToKeep,
+			"garbageCollect":    "Outdated",
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"source": map[string]interface{}{
+						"registry": map[string]interface{}{"url": bootImageImport.URL},
+					},
+					"storage": storageSpec,
+				},
+			},
+		},
+	}}
+	cron.SetAPIVersion(kubevirtclient.DataImportCronResource.GroupVersion().String())
+	cron.SetKind("DataImportCron")
+	cron.SetName(providerSpec.SourcePvcName)
+	cron.SetNamespace(namespace)
+	cron.SetLabels(map[string]string{bootImageCacheLabel: clusterID})
+	return cron
+}
+
+// bootImageCacheKey identifies the DataImportCrons of one tenant cluster in one infra namespace.
+type bootImageCacheKey struct {
+	credentialsSecretName string
+	tenantNamespace       string
+	infraNamespace        string
+	clusterID             string
+}
+
+// BootImageCache keeps the DataImportCrons importing the boot images of the provider specs
+// with a bootImageImport on the infra cluster, so that the images are imported once ahead of
+// machine creation and kept fresh, and root disks are cloned from the last import. The
+// DataImportCrons no longer referenced by the machines and MachineSets of a tenant cluster
+// are deleted, along with the images CDI imported for them, including in the infra namespaces
+// they were all removed from since the controller started.
+type BootImageCache struct {
+	client                runtimeclient.Client
+	kubevirtClientBuilder kubevirtclient.KubevirtClientBuilderFuncType
+	namespace             string
+	interval              time.Duration
+	// synced are the infra namespaces of tenant clusters with DataImportCrons after the last sync
+	synced map[bootImageCacheKey]bool
+}
+
+// NewBootImageCache returns a BootImageCache syncing the boot images of the machines and
+// MachineSets in namespace, or in all namespaces if empty, at the interval.
+func NewBootImageCache(client runtimeclient.Client, kubevirtClientBuilder kubevirtclient.KubevirtClientBuilderFuncType, namespace string, interval time.Duration) *BootImageCache {
+	return &BootImageCache{
+		client:                client,
+		kubevirtClientBuilder: kubevirtClientBuilder,
+		namespace:             namespace,
+		interval:              interval,
+	}
+}
+
+// Start periodically syncs the DataImportCrons of the boot images.
+// It implements manager.Runnable.
+func (c *BootImageCache) Start(stop <-chan struct{}) error {
+	wait.Until(c.sync, c.interval, stop)
+	return nil
+}
+
+func (c *BootImageCache) sync() {
+	desired, err := c.desiredCrons()
+	if err != nil {
+		klog.Errorf("Failed to list the boot images to import: %v", err)
+		return
+	}
+	for key := range c.synced {
+		if _, ok := desired[key]; !ok {
+			desired[key] = map[string]*unstructured.Unstructured{}
+		}
+	}
+
+	synced := map[bootImageCacheKey]bool{}
+	for key, crons := range desired {
+		if len(crons) > 0 {
+			synced[key] = true
+		}
+		if err := c.syncCrons(key, crons); err != nil {
+			klog.Errorf("Failed to sync the boot images of %s in infra namespace %s: %v", key.clusterID, key.infraNamespace, err)
+			synced[key] = true
+		}
+	}
+	c.synced = synced
+}
+
+// desiredCrons returns the DataImportCrons of the boot images referenced by the machines and
+// MachineSets, by infra namespace. When provider specs import different images into the same
+// DataSource, the first one in namespace and name order wins.
+func (c *BootImageCache) desiredCrons() (map[bootImageCacheKey]map[string]*unstructured.Unstructured, error) {
+	machineSets := &machinev1.MachineSetList{}
+	if err := c.client.List(context.Background(), machineSets, runtimeclient.InNamespace(c.namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list machine sets: %w", err)
+	}
+	machines := &machinev1.MachineList{}
+	if err := c.client.List(context.Background(), machines, runtimeclient.InNamespace(c.namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	// The machines of a MachineSet are stood in for by a machine of its template
+	var templates []*machinev1.Machine
+	for i := range machineSets.Items {
+		machineSet := &machineSets.Items[i]
+		templates = append(templates, &machinev1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: machineSet.Name, Namespace: machineSet.Namespace, Labels: machineSet.Spec.Template.Labels},
+			Spec:       machineSet.Spec.Template.Spec,
+		})
+	}
+	for i := range machines.Items {
+		if machines.Items[i].DeletionTimestamp == nil {
+			templates = append(templates, &machines.Items[i])
+		}
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].Namespace != templates[j].Namespace {
+			return templates[i].Namespace < templates[j].Namespace
+		}
+		return templates[i].Name < templates[j].Name
+	})
+
+	desired := map[bootImageCacheKey]map[string]*unstructured.Unstructured{}
+	for _, machine := range templates {
+		providerSpec, err := codec.DecodeProviderSpec(machine.Spec.ProviderSpec.Value)
+		if err != nil || providerSpec.BootImageImport == nil {
+			continue
+		}
+		clusterID, ok := getClusterID(machine)
+		if !ok || providerSpec.SourcePvcName == "" || validateBootImageImport(providerSpec.BootImageImport) != nil {
+			klog.Warningf("%s: skipping the invalid boot image import of %s", machine.Name, providerSpec.SourcePvcName)
+			continue
+		}
+
+		key := bootImageCacheKey{tenantNamespace: machine.Namespace, infraNamespace: infraNamespace(machine, providerSpec), clusterID: clusterID}
+		if providerSpec.CredentialsSecret != nil {
+			key.credentialsSecretName = providerSpec.CredentialsSecret.Name
+		}
+		if desired[key] == nil {
+			desired[key] = map[string]*unstructured.Unstructured{}
+		}
+		cron := bootImageCron(key.infraNamespace, clusterID, providerSpec)
+		if existing, ok := desired[key][cron.GetName()]; ok {
+			if !equality.Semantic.DeepEqual(existing.Object["spec"], cron.Object["spec"]) {
+				klog.Warningf("%s: boot image import of %s conflicts with that of another machine, ignoring it", machine.Name, cron.GetName())
+			}
+			continue
+		}
+		desired[key][cron.GetName()] = cron
+	}
+	return desired, nil
+}
+
+// syncCrons creates or updates the desired DataImportCrons of a tenant cluster in an infra
+// namespace, and deletes those it no longer references. DataImportCrons not created by the
+// boot image cache are left alone.
+func (c *BootImageCache) syncCrons(key bootImageCacheKey, desired map[string]*unstructured.Unstructured) error {
+	ctx := context.Background()
+	kubevirtClient, err := c.kubevirtClientBuilder(c.client, key.credentialsSecretName, key.tenantNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to create kubevirt client: %w", err)
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{bootImageCacheLabel: key.clusterID}).String()
+	existing, err := kubevirtClient.ListDataImportCrons(ctx, key.infraNamespace, &metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list DataImportCrons: %w", err)
+	}
+
+	for i := range existing.Items {
+		cron := &existing.Items[i]
+		want, ok := desired[cron.GetName()]
+		if !ok {
+			klog.Infof("Deleting DataImportCron %s/%s of a boot image no longer referenced", cron.GetNamespace(), cron.GetName())
+			if err := kubevirtClient.DeleteDataImportCron(ctx, cron.GetNamespace(), cron.GetName(), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete DataImportCron %s: %w", cron.GetName(), err)
+			}
+			continue
+		}
+		delete(desired, cron.GetName())
+
+		// Fields defaulted by CDI are not reverted
+		if equality.Semantic.DeepDerivative(want.Object["spec"], cron.Object["spec"]) {
+			continue
+		}
+		cron.Object["spec"] = want.Object["spec"]
+		klog.Infof("Updating DataImportCron %s/%s of a changed boot image import", cron.GetNamespace(), cron.GetName())
+		if _, err := kubevirtClient.UpdateDataImportCron(ctx, cron.GetNamespace(), cron); err != nil {
+			return fmt.Errorf("failed to update DataImportCron %s: %w", cron.GetName(), err)
+		}
+	}
+
+	for name, cron := range desired {
+		klog.Infof("Creating DataImportCron %s/%s", key.infraNamespace, name)
+		if _, err := kubevirtClient.CreateDataImportCron(ctx, key.infraNamespace, cron); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				klog.Warningf("DataImportCron %s/%s exists and is not managed by the boot image cache", key.infraNamespace, name)
+				continue
+			}
+			return fmt.Errorf("failed to create DataImportCron %s: %w", name, err)
+		}
+	}
+	return nil
+}
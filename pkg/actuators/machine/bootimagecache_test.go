@@ -0,0 +1,213 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestValidateBootImageImport(t *testing.T) {
+	testCases := []struct {
+		testcase        string
+		bootImageImport kubevirtproviderv1.BootImageImport
+		expectError     bool
+	}{
+		{
+			testcase:        "registry",
+			bootImageImport: kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:latest", Schedule: "0 */6 * * *", ImportsToKeep: pointer.Int32Ptr(2), Storage: "20Gi"},
+		},
+		{
+			testcase:        "http source",
+			bootImageImport: kubevirtproviderv1.BootImageImport{URL: "https://example.com/rhcos.qcow2"},
+			expectError:     true,
+		},
+		{
+			testcase:        "invalid schedule",
+			bootImageImport: kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:latest", Schedule: "@daily"},
+			expectError:     true,
+		},
+		{
+			testcase:        "no imports kept",
+			bootImageImport: kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:latest", ImportsToKeep: pointer.Int32Ptr(0)},
+			expectError:     true,
+		},
+		{
+			testcase:        "invalid storage",
+			bootImageImport: kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:latest", Storage: "20 gigs"},
+			expectError:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateBootImageImport(&tc.bootImageImport)
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestResolveBootImageSource(t *testing.T) {
+	dataSource := func(pvc map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"source": map[string]interface{}{"pvc": pvc}},
+		}}
+	}
+
+	testCases := []struct {
+		testcase    string
+		dataSource  *unstructured.Unstructured
+		getErr      error
+		expected    string
+		expectError bool
+	}{
+		{
+			testcase:   "imported",
+			dataSource: dataSource(map[string]interface{}{"name": "fedora-7c8b2d1e", "namespace": "tenant-a"}),
+			expected:   "fedora-7c8b2d1e",
+		},
+		{
+			testcase:    "not imported yet",
+			dataSource:  dataSource(map[string]interface{}{}),
+			expectError: true,
+		},
+		{
+			testcase:    "other namespace",
+			dataSource:  dataSource(map[string]interface{}{"name": "fedora-7c8b2d1e", "namespace": "golden-images"}),
+			expectError: true,
+		},
+		{
+			testcase:    "missing",
+			getErr:      apierrors.NewNotFound(schema.GroupResource{Group: "cdi.kubevirt.io", Resource: "datasources"}, "fedora"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			client.EXPECT().GetDataSource(gomock.Any(), "tenant-a", "fedora", gomock.Any()).Return(tc.dataSource, tc.getErr)
+
+			providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:   "fedora",
+				BootImageImport: &kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:latest"},
+			}
+			name, err := resolveBootImageSource(context.Background(), client, "tenant-a", providerSpec)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+			if name != tc.expected {
+				t.Errorf("Expected source PVC %q, got %q", tc.expected, name)
+			}
+		})
+	}
+}
+
+func TestRenderVMBootImageImport(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client := mockkubevirt.NewMockClient(mockCtrl)
+	dataSource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"source": map[string]interface{}{"pvc": map[string]interface{}{"name": "fedora-7c8b2d1e"}}},
+	}}
+	client.EXPECT().GetDataSource(gomock.Any(), "tenant-a", "fedora", gomock.Any()).Return(dataSource, nil)
+
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:   "fedora",
+		BootImageImport: &kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:latest"},
+	}
+	vm, _, err := renderVM(context.Background(), machine, providerSpec, nil, []byte("#cloud-config\n"), client)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if source := vm.Spec.DataVolumeTemplates[0].Spec.Source.PVC; source == nil || source.Name != "fedora-7c8b2d1e" {
+		t.Errorf("Expected the root disk to be cloned from the imported PVC, got %v", source)
+	}
+	if providerSpec.SourcePvcName != "fedora" {
+		t.Errorf("Expected the provider spec to be left unchanged, got %q", providerSpec.SourcePvcName)
+	}
+}
+
+func TestBootImageCacheSync(t *testing.T) {
+	providerSpecValue := func(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) *runtime.RawExtension {
+		value, err := kubevirtproviderv1.RawExtensionFromProviderSpec(providerSpec)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return value
+	}
+	bootImageImport := &kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:latest"}
+	machineSet := &machinev1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "tenant-a"},
+		Spec: machinev1.MachineSetSpec{Template: machinev1.MachineTemplateSpec{
+			ObjectMeta: machinev1.ObjectMeta{Labels: map[string]string{machinev1.MachineClusterIDLabel: "tenant-cluster"}},
+			Spec: machinev1.MachineSpec{ProviderSpec: machinev1.ProviderSpec{Value: providerSpecValue(&kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:    "fedora",
+				StorageClassName: "ocs-storagecluster-ceph-rbd",
+				BootImageImport:  bootImageImport,
+			})}},
+		}},
+	}
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers-abcde", Namespace: "tenant-a", Labels: map[string]string{machinev1.MachineClusterIDLabel: "tenant-cluster"}},
+		Spec: machinev1.MachineSpec{ProviderSpec: machinev1.ProviderSpec{Value: providerSpecValue(&kubevirtproviderv1.KubevirtMachineProviderSpec{
+			SourcePvcName:   "fedora",
+			BootImageImport: &kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:40"},
+		})}},
+	}
+
+	outdated := bootImageCron("tenant-a", "tenant-cluster", &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "fedora", BootImageImport: &kubevirtproviderv1.BootImageImport{URL: "docker://quay.io/containerdisks/fedora:39"}})
+	unreferenced := bootImageCron("tenant-a", "tenant-cluster", &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "centos", BootImageImport: bootImageImport})
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	kubevirtClient := mockkubevirt.NewMockClient(mockCtrl)
+	kubevirtClient.EXPECT().ListDataImportCrons(gomock.Any(), "tenant-a", &metav1.ListOptions{LabelSelector: bootImageCacheLabel + "=tenant-cluster"}).
+		Return(&unstructured.UnstructuredList{Items: []unstructured.Unstructured{*outdated, *unreferenced}}, nil)
+	kubevirtClient.EXPECT().DeleteDataImportCron(gomock.Any(), "tenant-a", "centos", gomock.Any()).Return(nil)
+	var updated *unstructured.Unstructured
+	kubevirtClient.EXPECT().UpdateDataImportCron(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			updated = cron
+			return cron, nil
+		})
+
+	scheme := runtime.NewScheme()
+	if err := machinev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cache := NewBootImageCache(fake.NewFakeClientWithScheme(scheme, machineSet, machine),
+		func(_ runtimeclient.Client, _, _ string) (kubevirtclient.Client, error) { return kubevirtClient, nil }, "tenant-a", 0)
+	cache.sync()
+
+	if updated == nil || updated.GetName() != "fedora" {
+		t.Fatalf("Expected the outdated DataImportCron to be updated, got %v", updated)
+	}
+	// The MachineSet sorts before its machine and wins the conflict
+	url, _, _ := unstructured.NestedString(updated.Object, "spec", "template", "spec", "source", "registry", "url")
+	storageClassName, _, _ := unstructured.NestedString(updated.Object, "spec", "template", "spec", "storage", "storageClassName")
+	if url != bootImageImport.URL || storageClassName != "ocs-storagecluster-ceph-rbd" {
+		t.Errorf("Expected the import of the MachineSet, got %s into %s", url, storageClassName)
+	}
+	if schedule, _, _ := unstructured.NestedString(updated.Object, "spec", "schedule"); schedule != defaultBootImageSchedule {
+		t.Errorf("Expected the default schedule, got %q", schedule)
+	}
+}
@@ -0,0 +1,71 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// minBootstrapDataBackoff is the delay before the user data secret is looked up again
+	// after it was first found missing.
+	minBootstrapDataBackoff = 5 * time.Second
+	// maxBootstrapDataBackoff bounds the delay between the lookups of a missing user data secret.
+	maxBootstrapDataBackoff = 2 * time.Minute
+)
+
+// bootstrapDataBackoff returns the delay before the next lookup of the user data secret,
+// doubling the time it has been waited for so far within bounds.
+func bootstrapDataBackoff(waited time.Duration) time.Duration {
+	switch {
+	case waited < minBootstrapDataBackoff:
+		return minBootstrapDataBackoff
+	case waited > maxBootstrapDataBackoff:
+		return maxBootstrapDataBackoff
+	default:
+		return waited
+	}
+}
+
+// waitingForBootstrapData returns true if the creation of the machine waits for its user data
+// secret to be created, e.g. by a bootstrap provider.
+func (s *machineScope) waitingForBootstrapData() bool {
+	condition := findProviderCondition(s.providerStatus.Conditions, kubevirtproviderv1.WaitingForBootstrapData)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// waitForBootstrapData sets the WaitingForBootstrapData condition and returns a
+// RequeueAfterError backing off the lookups of the missing user data secret.
+func (r *Reconciler) waitForBootstrapData() error {
+	secret := r.providerSpec.UserDataSecret.Name
+	r.log().Info("user data secret not found, waiting for bootstrap data", "secret", secret)
+
+	waited := time.Duration(0)
+	if condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.WaitingForBootstrapData); condition != nil && condition.Status == corev1.ConditionTrue {
+		waited = time.Since(condition.LastTransitionTime.Time)
+	}
+	r.machineScope.setProviderStatus(kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.WaitingForBootstrapData,
+		Status:  corev1.ConditionTrue,
+		Reason:  kubevirtproviderv1.UserDataSecretNotFound,
+		Message: fmt.Sprintf("User data secret %s does not exist yet", secret),
+	})
+	return &machinecontroller.RequeueAfterError{RequeueAfter: bootstrapDataBackoff(waited)}
+}
+
+// bootstrapDataFound clears the WaitingForBootstrapData condition of a machine which waited
+// for its user data secret.
+func (r *Reconciler) bootstrapDataFound() {
+	if findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.WaitingForBootstrapData) == nil {
+		return
+	}
+	r.machineScope.setProviderStatus(kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:   kubevirtproviderv1.WaitingForBootstrapData,
+		Status: corev1.ConditionFalse,
+		Reason: kubevirtproviderv1.BootstrapDataAvailable,
+	})
+}
@@ -0,0 +1,64 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestBootstrapDataBackoff(t *testing.T) {
+	testCases := []struct {
+		waited   time.Duration
+		expected time.Duration
+	}{
+		{waited: 0, expected: minBootstrapDataBackoff},
+		{waited: 20 * time.Second, expected: 20 * time.Second},
+		{waited: time.Hour, expected: maxBootstrapDataBackoff},
+	}
+	for _, tc := range testCases {
+		if backoff := bootstrapDataBackoff(tc.waited); backoff != tc.expected {
+			t.Errorf("Expected backoff %v after waiting %v, got %v", tc.expected, tc.waited, backoff)
+		}
+	}
+}
+
+func TestWaitForBootstrapData(t *testing.T) {
+	r := newReconciler(&machineScope{
+		Context: context.Background(),
+		machine: &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}},
+		providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+			UserDataSecret: &kubevirtproviderv1.UserDataSecretReference{Name: "worker-user-data"},
+		},
+		providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+	})
+
+	err := r.waitForBootstrapData()
+	requeue, ok := err.(*machinecontroller.RequeueAfterError)
+	if !ok || requeue.RequeueAfter != minBootstrapDataBackoff {
+		t.Fatalf("Expected a requeue after %v, got %v", minBootstrapDataBackoff, err)
+	}
+	if !r.waitingForBootstrapData() {
+		t.Fatal("Expected the machine to wait for bootstrap data")
+	}
+
+	condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.WaitingForBootstrapData)
+	condition.LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute))
+	err = r.waitForBootstrapData()
+	if requeue, ok := err.(*machinecontroller.RequeueAfterError); !ok || requeue.RequeueAfter < time.Minute {
+		t.Errorf("Expected the requeue to back off, got %v", err)
+	}
+
+	r.bootstrapDataFound()
+	if r.waitingForBootstrapData() {
+		t.Error("Expected the machine to no longer wait for bootstrap data")
+	}
+	if condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.WaitingForBootstrapData); condition.Reason != kubevirtproviderv1.BootstrapDataAvailable {
+		t.Errorf("Expected reason %v, got %v", kubevirtproviderv1.BootstrapDataAvailable, condition.Reason)
+	}
+}
@@ -0,0 +1,125 @@
+package machine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// bootVolumeProgressStep is the progress, in percent, a population advances by between two
+// events reporting it.
+const bootVolumeProgressStep = 25
+
+// bootVolumeReadyCondition returns whether the boot DataVolume is populated, with the phase
+// and progress of the population otherwise.
+func bootVolumeReadyCondition(status *kubevirtproviderv1.BootVolumeStatus) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	switch cdiv1.DataVolumePhase(status.Phase) {
+	case cdiv1.Succeeded:
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.BootVolumeReady,
+			Status:  corev1.ConditionTrue,
+			Reason:  kubevirtproviderv1.BootVolumePopulated,
+			Message: fmt.Sprintf("Boot DataVolume %s is populated", status.DataVolume),
+		}
+	case cdiv1.Failed:
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.BootVolumeReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.BootVolumeFailed,
+			Message: fmt.Sprintf("CDI failed to populate boot DataVolume %s", status.DataVolume),
+		}
+	}
+
+	message := fmt.Sprintf("Boot DataVolume %s is pending", status.DataVolume)
+	if status.Phase != "" {
+		message = fmt.Sprintf("Boot DataVolume %s is in phase %s", status.DataVolume, status.Phase)
+	}
+	if _, ok := parseBootVolumeProgress(status.Progress); ok {
+		message = fmt.Sprintf("%s at %s", message, status.Progress)
+	}
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.BootVolumeReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  kubevirtproviderv1.BootVolumePopulating,
+		Message: message,
+	}
+}
+
+// parseBootVolumeProgress returns the percentage of the progress CDI reports, e.g. 45.20%.
+// CDI reports N/A when the size of the source is unknown.
+func parseBootVolumeProgress(progress string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(progress, "%"), 64)
+	if err != nil || !strings.HasSuffix(progress, "%") {
+		return 0, false
+	}
+	return value, true
+}
+
+// bootVolumeProgressChanged returns true if the boot DataVolume changed phase, or its progress
+// advanced by a step, from the previous to the current status.
+func bootVolumeProgressChanged(previous, current *kubevirtproviderv1.BootVolumeStatus) bool {
+	if previous == nil || previous.DataVolume != current.DataVolume || previous.Phase != current.Phase {
+		return true
+	}
+	previousProgress, _ := parseBootVolumeProgress(previous.Progress)
+	currentProgress, _ := parseBootVolumeProgress(current.Progress)
+	return int(currentProgress)/bootVolumeProgressStep != int(previousProgress)/bootVolumeProgressStep
+}
+
+// reconcileBootVolumeProgress records the phase and progress of the import or clone populating
+// the boot DataVolume in the provider status until it is populated. KubeVirt only starts the
+// VM once its DataVolumes are populated, the DataVolume of a VM with a VMI is not read.
+func (r *Reconciler) reconcileBootVolumeProgress(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) error {
+	name := rootDataVolumeName(vm)
+	if name == "" || coldMigrationInProgress(r.providerStatus.ColdMigration) {
+		return nil
+	}
+	if status := r.providerStatus.BootVolume; status != nil && status.DataVolume == name && status.Phase == string(cdiv1.Succeeded) {
+		return nil
+	}
+
+	status := &kubevirtproviderv1.BootVolumeStatus{DataVolume: name, Phase: string(cdiv1.Succeeded)}
+	if vmi == nil {
+		dataVolume, err := r.kubevirtClient.GetDataVolume(r.Context, vm.Namespace, name, &metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// Not created by KubeVirt yet
+				return nil
+			}
+			return fmt.Errorf("failed to get boot DataVolume: %w", err)
+		}
+		status.Phase = string(dataVolume.Status.Phase)
+		status.Progress = string(dataVolume.Status.Progress)
+	}
+	r.providerStatus.BootVolume = status
+	r.machineScope.setProviderStatus(bootVolumeReadyCondition(status))
+	return nil
+}
+
+// recordBootVolumeProgress emits an event if the boot DataVolume changed phase or its progress
+// advanced by a step since the previous status. Machines whose boot DataVolume is populated
+// when first seen emit none.
+func (a *Actuator) recordBootVolumeProgress(machine *machinev1.Machine, previous *kubevirtproviderv1.BootVolumeStatus, providerStatus *kubevirtproviderv1.KubevirtMachineProviderStatus) {
+	current := providerStatus.BootVolume
+	if current == nil || !bootVolumeProgressChanged(previous, current) {
+		return
+	}
+	if previous == nil && current.Phase == string(cdiv1.Succeeded) {
+		return
+	}
+	condition := bootVolumeReadyCondition(current)
+	eventType := corev1.EventTypeNormal
+	if condition.Reason == kubevirtproviderv1.BootVolumeFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	a.eventRecorder.Event(machine, eventType, string(condition.Reason), condition.Message)
+}
@@ -0,0 +1,175 @@
+package machine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestBootVolumeReadyCondition(t *testing.T) {
+	testCases := []struct {
+		status          kubevirtproviderv1.BootVolumeStatus
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  kubevirtproviderv1.KubevirtMachineProviderConditionReason
+		expectedMessage string
+	}{
+		{
+			status:          kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume"},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  kubevirtproviderv1.BootVolumePopulating,
+			expectedMessage: "Boot DataVolume worker-abcde-bootvolume is pending",
+		},
+		{
+			status:          kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "CloneInProgress", Progress: "45.20%"},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  kubevirtproviderv1.BootVolumePopulating,
+			expectedMessage: "Boot DataVolume worker-abcde-bootvolume is in phase CloneInProgress at 45.20%",
+		},
+		{
+			status:          kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "ImportInProgress", Progress: "N/A"},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  kubevirtproviderv1.BootVolumePopulating,
+			expectedMessage: "Boot DataVolume worker-abcde-bootvolume is in phase ImportInProgress",
+		},
+		{
+			status:          kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "Failed"},
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  kubevirtproviderv1.BootVolumeFailed,
+			expectedMessage: "CDI failed to populate boot DataVolume worker-abcde-bootvolume",
+		},
+		{
+			status:          kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "Succeeded", Progress: "100.0%"},
+			expectedStatus:  corev1.ConditionTrue,
+			expectedReason:  kubevirtproviderv1.BootVolumePopulated,
+			expectedMessage: "Boot DataVolume worker-abcde-bootvolume is populated",
+		},
+	}
+
+	for _, tc := range testCases {
+		condition := bootVolumeReadyCondition(&tc.status)
+		if condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason || condition.Message != tc.expectedMessage {
+			t.Errorf("Expected %s %s %q, got %s %s %q", tc.expectedStatus, tc.expectedReason, tc.expectedMessage, condition.Status, condition.Reason, condition.Message)
+		}
+	}
+}
+
+func TestReconcileBootVolumeProgress(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "cdi.kubevirt.io", Resource: "datavolumes"}, "worker-abcde-bootvolume")
+
+	testCases := []struct {
+		testcase        string
+		status          *kubevirtproviderv1.BootVolumeStatus
+		vmi             *kubevirtapis.VirtualMachineInstance
+		dataVolume      *cdiv1.DataVolume
+		getErr          error
+		expectGet       bool
+		expectedStatus  *kubevirtproviderv1.BootVolumeStatus
+		expectCondition bool
+	}{
+		{
+			testcase:        "cloning",
+			dataVolume:      &cdiv1.DataVolume{Status: cdiv1.DataVolumeStatus{Phase: cdiv1.CloneInProgress, Progress: "45.20%"}},
+			expectGet:       true,
+			expectedStatus:  &kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "CloneInProgress", Progress: "45.20%"},
+			expectCondition: true,
+		},
+		{
+			testcase:  "not created yet",
+			getErr:    notFound,
+			expectGet: true,
+		},
+		{
+			testcase:        "running",
+			status:          &kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "CloneInProgress", Progress: "97.00%"},
+			vmi:             &kubevirtapis.VirtualMachineInstance{},
+			expectedStatus:  &kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "Succeeded"},
+			expectCondition: true,
+		},
+		{
+			testcase:       "populated",
+			status:         &kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "Succeeded", Progress: "100.0%"},
+			expectedStatus: &kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: "Succeeded", Progress: "100.0%"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			kubevirtClient := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.expectGet {
+				kubevirtClient.EXPECT().GetDataVolume(gomock.Any(), "tenant-a", "worker-abcde-bootvolume", gomock.Any()).Return(tc.dataVolume, tc.getErr)
+			}
+
+			providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{BootVolume: tc.status}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: kubevirtClient,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api"}},
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{},
+				providerStatus: providerStatus,
+			})
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec: kubevirtapis.VirtualMachineSpec{Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtapis.VirtualMachineInstanceSpec{Volumes: []kubevirtapis.Volume{
+						{Name: mainDiskName, VolumeSource: kubevirtapis.VolumeSource{DataVolume: &kubevirtapis.DataVolumeSource{Name: "worker-abcde-bootvolume"}}},
+					}},
+				}},
+			}
+
+			if err := r.reconcileBootVolumeProgress(vm, tc.vmi); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (tc.expectedStatus == nil) != (providerStatus.BootVolume == nil) || (tc.expectedStatus != nil && *tc.expectedStatus != *providerStatus.BootVolume) {
+				t.Errorf("Expected boot volume status %v, got %v", tc.expectedStatus, providerStatus.BootVolume)
+			}
+			condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.BootVolumeReady)
+			if tc.expectCondition != (condition != nil) {
+				t.Errorf("Expected the BootVolumeReady condition: %v, got %v", tc.expectCondition, condition)
+			}
+		})
+	}
+}
+
+func TestRecordBootVolumeProgress(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	status := func(phase, progress string) *kubevirtproviderv1.BootVolumeStatus {
+		return &kubevirtproviderv1.BootVolumeStatus{DataVolume: "worker-abcde-bootvolume", Phase: phase, Progress: progress}
+	}
+	providerStatus := func(bootVolume *kubevirtproviderv1.BootVolumeStatus) *kubevirtproviderv1.KubevirtMachineProviderStatus {
+		return &kubevirtproviderv1.KubevirtMachineProviderStatus{BootVolume: bootVolume}
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	a := NewActuator(ActuatorParams{EventRecorder: recorder})
+	a.recordBootVolumeProgress(machine, nil, providerStatus(nil))
+	a.recordBootVolumeProgress(machine, nil, providerStatus(status("Succeeded", "")))
+	a.recordBootVolumeProgress(machine, status("CloneInProgress", "26.00%"), providerStatus(status("CloneInProgress", "49.90%")))
+	if events := drainEvents(recorder); len(events) != 0 {
+		t.Errorf("Expected no event without progress, got %v", events)
+	}
+
+	a.recordBootVolumeProgress(machine, nil, providerStatus(status("CloneScheduled", "")))
+	a.recordBootVolumeProgress(machine, status("CloneInProgress", "49.90%"), providerStatus(status("CloneInProgress", "50.10%")))
+	a.recordBootVolumeProgress(machine, status("CloneInProgress", "99.00%"), providerStatus(status("Succeeded", "100.0%")))
+	a.recordBootVolumeProgress(machine, status("ImportInProgress", "10.00%"), providerStatus(status("Failed", "10.00%")))
+	events := drainEvents(recorder)
+	if len(events) != 4 || !strings.HasPrefix(events[0], "Normal BootVolumePopulating") || !strings.HasSuffix(events[1], "at 50.10%") ||
+		!strings.HasPrefix(events[2], "Normal BootVolumePopulated") || !strings.HasPrefix(events[3], "Warning BootVolumeFailed") {
+		t.Errorf("Expected the progress of the boot volume to be recorded, got %v", events)
+	}
+}
@@ -0,0 +1,195 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	defaultImportStuckTimeout = 15 * time.Minute
+	defaultImportMaxRetries   = 3
+	defaultImportBackoff      = time.Minute
+
+	// cdiAppLabel selects the importer, cloner and upload pods of CDI
+	cdiAppLabel      = "app"
+	cdiAppLabelValue = "containerized-data-importer"
+	// crashLoopBackOffReason is the waiting reason of crashlooping containers
+	crashLoopBackOffReason = "CrashLoopBackOff"
+)
+
+// validateBootVolumeRetryPolicy returns an error if a duration or the retry limit is negative.
+func validateBootVolumeRetryPolicy(policy *kubevirtproviderv1.BootVolumeRetryPolicy) error {
+	if policy.StuckTimeout != nil && policy.StuckTimeout.Duration <= 0 {
+		return fmt.Errorf("stuckTimeout must be positive, got %v", policy.StuckTimeout.Duration)
+	}
+	if policy.Backoff != nil && policy.Backoff.Duration < 0 {
+		return fmt.Errorf("backoff must not be negative, got %v", policy.Backoff.Duration)
+	}
+	if policy.MaxRetries != nil && *policy.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries must not be negative, got %d", *policy.MaxRetries)
+	}
+	return nil
+}
+
+// resolveBootVolumeRetryPolicy returns the stuck timeout, the retry limit and the backoff of
+// the policy, defaulting the unset ones.
+func resolveBootVolumeRetryPolicy(policy *kubevirtproviderv1.BootVolumeRetryPolicy) (time.Duration, int32, time.Duration) {
+	stuckTimeout, maxRetries, backoff := defaultImportStuckTimeout, int32(defaultImportMaxRetries), defaultImportBackoff
+	if policy.StuckTimeout != nil {
+		stuckTimeout = policy.StuckTimeout.Duration
+	}
+	if policy.MaxRetries != nil {
+		maxRetries = *policy.MaxRetries
+	}
+	if policy.Backoff != nil {
+		backoff = policy.Backoff.Duration
+	}
+	return stuckTimeout, maxRetries, backoff
+}
+
+// importPodCrashLooping returns the name and the last termination message of the crashlooping
+// CDI pod populating the DataVolume, if any.
+func importPodCrashLooping(pods []corev1.Pod, dataVolume string) (string, string, bool) {
+	for _, pod := range pods {
+		if pod.Name != "importer-"+dataVolume && pod.Name != "cdi-upload-"+dataVolume {
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Waiting == nil || container.State.Waiting.Reason != crashLoopBackOffReason {
+				continue
+			}
+			message := container.State.Waiting.Message
+			if terminated := container.LastTerminationState.Terminated; terminated != nil && terminated.Message != "" {
+				message = terminated.Message
+			}
+			return pod.Name, message, true
+		}
+	}
+	return "", "", false
+}
+
+// bootVolumeImportCondition returns the condition recording the retries of the boot volume import.
+func bootVolumeImportCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.BootVolumeImport,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// stuckImportReason returns why the import of the DataVolume is stuck, or an empty string if
+// it is not.
+func (r *Reconciler) stuckImportReason(dataVolume *cdiv1.DataVolume, status *kubevirtproviderv1.BootVolumeImportStatus, stuckTimeout time.Duration, now time.Time) (string, error) {
+	if dataVolume.Status.Phase == cdiv1.Failed {
+		return "the DataVolume failed", nil
+	}
+
+	pods, err := r.kubevirtClient.ListPods(r.Context, dataVolume.Namespace, &metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{cdiAppLabel: cdiAppLabelValue}).String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list CDI pods: %w", err)
+	}
+	if pod, message, ok := importPodCrashLooping(pods.Items, dataVolume.Name); ok {
+		return fmt.Sprintf("pod %s is crashlooping: %s", pod, message), nil
+	}
+
+	if status.LastProgressTime != nil && now.Sub(status.LastProgressTime.Time) > stuckTimeout {
+		return fmt.Sprintf("no progress since %s, in phase %s at %q", status.LastProgressTime.UTC().Format(time.RFC3339), dataVolume.Status.Phase, status.Progress), nil
+	}
+	return "", nil
+}
+
+// reconcileBootVolumeImport tracks the progress of the import of the boot DataVolume and
+// deletes the DataVolume, for KubeVirt to recreate it out of the DataVolume template of the VM,
+// when the import is stuck. Once the retries are exhausted the machine is marked failed.
+func (r *Reconciler) reconcileBootVolumeImport(vm *kubevirtapis.VirtualMachine) error {
+	policy := r.providerSpec.BootVolumeRetryPolicy
+	if policy == nil || coldMigrationInProgress(r.providerStatus.ColdMigration) {
+		return nil
+	}
+	name := rootDataVolumeName(vm)
+	if name == "" {
+		return nil
+	}
+
+	dataVolume, err := r.kubevirtClient.GetDataVolume(r.Context, vm.Namespace, name, &metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Being recreated by KubeVirt after a retry
+			return nil
+		}
+		return fmt.Errorf("failed to get boot DataVolume: %w", err)
+	}
+
+	status := r.providerStatus.BootVolumeImport
+	if dataVolume.Status.Phase == cdiv1.Succeeded {
+		if status != nil {
+			r.providerStatus.BootVolumeImport = nil
+			r.machineScope.setProviderStatus(bootVolumeImportCondition(corev1.ConditionTrue, kubevirtproviderv1.ImportSucceeded,
+				fmt.Sprintf("Import of boot DataVolume %s completed after %d retries", name, status.Retries)))
+		}
+		return nil
+	}
+
+	now := metav1.Now()
+	if status == nil {
+		status = &kubevirtproviderv1.BootVolumeImportStatus{}
+		r.providerStatus.BootVolumeImport = status
+	}
+	progress := string(dataVolume.Status.Progress)
+	if status.LastProgressTime == nil || progress != status.Progress {
+		status.Progress = progress
+		status.LastProgressTime = &now
+	}
+
+	stuckTimeout, maxRetries, backoff := resolveBootVolumeRetryPolicy(policy)
+	reason, err := r.stuckImportReason(dataVolume, status, stuckTimeout, now.Time)
+	if err != nil || reason == "" {
+		return err
+	}
+
+	if status.Retries >= maxRetries {
+		message := fmt.Sprintf("Import of boot DataVolume %s stuck after %d retries: %s", name, status.Retries, reason)
+		if r.machine.Status.ErrorReason == nil {
+			klog.Warningf("%s: %s, marking machine failed", r.machine.Name, message)
+			errorReason := machinev1.CreateMachineError
+			r.machine.Status.ErrorReason = &errorReason
+			r.machine.Status.ErrorMessage = &message
+		}
+		r.machineScope.setProviderStatus(bootVolumeImportCondition(corev1.ConditionFalse, kubevirtproviderv1.ImportRetriesExhausted, message))
+		return nil
+	}
+
+	if status.NextRetryTime != nil && now.Before(status.NextRetryTime) {
+		r.machineScope.setProviderStatus(bootVolumeImportCondition(corev1.ConditionFalse, kubevirtproviderv1.ImportStuck,
+			fmt.Sprintf("Import of boot DataVolume %s stuck: %s, retry %d of %d at %s", name, reason, status.Retries+1, maxRetries, status.NextRetryTime.UTC().Format(time.RFC3339))))
+		return &machinecontroller.RequeueAfterError{RequeueAfter: status.NextRetryTime.Sub(now.Time)}
+	}
+
+	klog.Infof("%s: import of boot DataVolume %s stuck (%s), deleting it for retry %d of %d", r.machine.Name, name, reason, status.Retries+1, maxRetries)
+	if err := r.kubevirtClient.DeleteDataVolume(r.Context, vm.Namespace, name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete stuck boot DataVolume: %w", err)
+	}
+	status.Retries++
+	nextRetryTime := metav1.NewTime(now.Add(backoff << uint(status.Retries-1)))
+	status.NextRetryTime = &nextRetryTime
+	status.Progress = ""
+	status.LastProgressTime = &now
+	r.machineScope.setProviderStatus(bootVolumeImportCondition(corev1.ConditionFalse, kubevirtproviderv1.ImportStuck,
+		fmt.Sprintf("Import of boot DataVolume %s stuck: %s, retried %d of %d", name, reason, status.Retries, maxRetries)))
+	return nil
+}
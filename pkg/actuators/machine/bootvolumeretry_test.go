@@ -0,0 +1,142 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestReconcileBootVolumeImport(t *testing.T) {
+	crashLoopingImporter := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "importer-worker-abcde-bootvolume"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOffReason}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "Unable to connect to http data source"}},
+		}}},
+	}
+	stalled := metav1.NewTime(time.Now().Add(-time.Hour))
+	future := metav1.NewTime(time.Now().Add(time.Hour))
+
+	testCases := []struct {
+		testcase        string
+		phase           cdiv1.DataVolumePhase
+		progress        cdiv1.DataVolumeProgress
+		status          *kubevirtproviderv1.BootVolumeImportStatus
+		pods            []corev1.Pod
+		expectDelete    bool
+		expectedReason  kubevirtproviderv1.KubevirtMachineProviderConditionReason
+		expectedRetries int32
+		expectedFailed  bool
+		expectRequeue   bool
+	}{
+		{
+			testcase:        "progressing import",
+			phase:           cdiv1.CloneInProgress,
+			progress:        "45.00%",
+			status:          &kubevirtproviderv1.BootVolumeImportStatus{Progress: "12.00%", LastProgressTime: &stalled},
+			expectedRetries: 0,
+		},
+		{
+			testcase:        "paused progress",
+			phase:           cdiv1.CloneInProgress,
+			progress:        "45.00%",
+			status:          &kubevirtproviderv1.BootVolumeImportStatus{Progress: "45.00%", LastProgressTime: &stalled},
+			expectDelete:    true,
+			expectedReason:  kubevirtproviderv1.ImportStuck,
+			expectedRetries: 1,
+		},
+		{
+			testcase:        "crashlooping importer pod",
+			phase:           cdiv1.ImportInProgress,
+			pods:            []corev1.Pod{crashLoopingImporter},
+			expectDelete:    true,
+			expectedReason:  kubevirtproviderv1.ImportStuck,
+			expectedRetries: 1,
+		},
+		{
+			testcase:        "backing off",
+			phase:           cdiv1.Failed,
+			status:          &kubevirtproviderv1.BootVolumeImportStatus{Retries: 1, NextRetryTime: &future},
+			expectedReason:  kubevirtproviderv1.ImportStuck,
+			expectedRetries: 1,
+			expectRequeue:   true,
+		},
+		{
+			testcase:        "retries exhausted",
+			phase:           cdiv1.Failed,
+			status:          &kubevirtproviderv1.BootVolumeImportStatus{Retries: 3},
+			expectedReason:  kubevirtproviderv1.ImportRetriesExhausted,
+			expectedRetries: 3,
+			expectedFailed:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec: kubevirtapis.VirtualMachineSpec{Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtapis.VirtualMachineInstanceSpec{Volumes: []kubevirtapis.Volume{{
+						Name:         mainDiskName,
+						VolumeSource: kubevirtapis.VolumeSource{DataVolume: &kubevirtapis.DataVolumeSource{Name: "worker-abcde-bootvolume"}},
+					}}},
+				}},
+			}
+			dataVolume := &cdiv1.DataVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde-bootvolume", Namespace: "tenant-a"},
+				Status:     cdiv1.DataVolumeStatus{Phase: tc.phase, Progress: tc.progress},
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			client.EXPECT().GetDataVolume(gomock.Any(), "tenant-a", "worker-abcde-bootvolume", gomock.Any()).Return(dataVolume, nil)
+			if tc.phase != cdiv1.Failed {
+				client.EXPECT().ListPods(gomock.Any(), "tenant-a", gomock.Any()).Return(&corev1.PodList{Items: tc.pods}, nil)
+			}
+			if tc.expectDelete {
+				client.EXPECT().DeleteDataVolume(gomock.Any(), "tenant-a", "worker-abcde-bootvolume", gomock.Any()).Return(nil)
+			}
+
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        machine,
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					BootVolumeRetryPolicy: &kubevirtproviderv1.BootVolumeRetryPolicy{},
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{BootVolumeImport: tc.status},
+			})
+			err := r.reconcileBootVolumeImport(vm)
+			if tc.expectRequeue != (err != nil) {
+				t.Fatalf("Expected requeue %v, got error %v", tc.expectRequeue, err)
+			}
+
+			if retries := r.providerStatus.BootVolumeImport.Retries; retries != tc.expectedRetries {
+				t.Errorf("Expected %d retries, got %d", tc.expectedRetries, retries)
+			}
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.BootVolumeImport)
+			if tc.expectedReason == "" {
+				if condition != nil {
+					t.Errorf("Expected no BootVolumeImport condition, got %v", condition)
+				}
+			} else if condition == nil || condition.Reason != tc.expectedReason {
+				t.Errorf("Expected BootVolumeImport condition with reason %s, got %v", tc.expectedReason, condition)
+			}
+			if failed := machine.Status.ErrorReason != nil; failed != tc.expectedFailed {
+				t.Errorf("Expected machine failed %v, got %v", tc.expectedFailed, failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package machine
+
+import (
+	"fmt"
+	"regexp"
+
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// timezonePattern matches the zone names of the TZ database, e.g. America/New_York or UTC. The
+// zone is resolved by libvirt on the infra node, the controller may not ship the database.
+var timezonePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+\-]*(/[A-Za-z0-9_+\-]+)*$`)
+
+// validateClock returns an error if the timezone is not a zone name or a timer state is
+// unsupported. The Hyper-V timer is only available to x86 guests.
+func validateClock(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	clock := providerSpec.Clock
+	if clock.Timezone != "" && !timezonePattern.MatchString(clock.Timezone) {
+		return fmt.Errorf("clock timezone %q is not a zone name, e.g. America/New_York", clock.Timezone)
+	}
+	if err := validateTimerState("hypervTimer", clock.HypervTimer); err != nil {
+		return err
+	}
+	if err := validateTimerState("kvmTimer", clock.KVMTimer); err != nil {
+		return err
+	}
+	if clock.HypervTimer == kubevirtproviderv1.TimerStateEnabled && providerSpec.Architecture == kubevirtproviderv1.ArchitectureARM64 {
+		return fmt.Errorf("clock hypervTimer is not supported on architecture %q", providerSpec.Architecture)
+	}
+	return nil
+}
+
+func validateTimerState(name string, state kubevirtproviderv1.TimerState) error {
+	switch state {
+	case "", kubevirtproviderv1.TimerStateEnabled, kubevirtproviderv1.TimerStateDisabled:
+		return nil
+	default:
+		return fmt.Errorf("unsupported clock %s %q, must be one of %q or %q", name, state,
+			kubevirtproviderv1.TimerStateEnabled, kubevirtproviderv1.TimerStateDisabled)
+	}
+}
+
+// applyClock sets the clock offset and the timers of the provider spec on the VM, overriding
+// those set for Windows guests.
+func applyClock(spec *kubevirtapis.VirtualMachineInstanceSpec, clock *kubevirtproviderv1.Clock) {
+	if spec.Domain.Clock == nil {
+		spec.Domain.Clock = &kubevirtapis.Clock{
+			ClockOffset: kubevirtapis.ClockOffset{UTC: &kubevirtapis.ClockOffsetUTC{}},
+		}
+	}
+	if clock.Timezone != "" {
+		timezone := kubevirtapis.ClockOffsetTimezone(clock.Timezone)
+		spec.Domain.Clock.ClockOffset = kubevirtapis.ClockOffset{Timezone: &timezone}
+	}
+
+	if clock.HypervTimer == "" && clock.KVMTimer == "" {
+		return
+	}
+	if spec.Domain.Clock.Timer == nil {
+		spec.Domain.Clock.Timer = &kubevirtapis.Timer{}
+	}
+	if clock.HypervTimer != "" {
+		enabled := clock.HypervTimer == kubevirtproviderv1.TimerStateEnabled
+		spec.Domain.Clock.Timer.Hyperv = &kubevirtapis.HypervTimer{Enabled: &enabled}
+	}
+	if clock.KVMTimer != "" {
+		enabled := clock.KVMTimer == kubevirtproviderv1.TimerStateEnabled
+		spec.Domain.Clock.Timer.KVM = &kubevirtapis.KVMTimer{Enabled: &enabled}
+	}
+}
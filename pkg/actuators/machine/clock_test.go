@@ -0,0 +1,81 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateClock(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		clock        kubevirtproviderv1.Clock
+		architecture kubevirtproviderv1.Architecture
+		expectError  bool
+	}{
+		{
+			testcase: "timezone and timers",
+			clock:    kubevirtproviderv1.Clock{Timezone: "America/Argentina/Buenos_Aires", HypervTimer: kubevirtproviderv1.TimerStateEnabled, KVMTimer: kubevirtproviderv1.TimerStateDisabled},
+		},
+		{
+			testcase:    "invalid timezone",
+			clock:       kubevirtproviderv1.Clock{Timezone: "../etc/passwd"},
+			expectError: true,
+		},
+		{
+			testcase:    "unsupported timer state",
+			clock:       kubevirtproviderv1.Clock{KVMTimer: "true"},
+			expectError: true,
+		},
+		{
+			testcase:     "arm64 hyperv timer",
+			clock:        kubevirtproviderv1.Clock{HypervTimer: kubevirtproviderv1.TimerStateEnabled},
+			architecture: kubevirtproviderv1.ArchitectureARM64,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateClock(&kubevirtproviderv1.KubevirtMachineProviderSpec{Clock: &tc.clock, Architecture: tc.architecture})
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMClock(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		Clock:         &kubevirtproviderv1.Clock{KVMTimer: kubevirtproviderv1.TimerStateEnabled},
+	}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clock := vm.Spec.Template.Spec.Domain.Clock
+	if clock == nil || clock.UTC == nil || clock.Timer == nil || clock.Timer.KVM == nil || !*clock.Timer.KVM.Enabled || clock.Timer.Hyperv != nil {
+		t.Errorf("Expected a UTC clock with kvmclock, got %+v", clock)
+	}
+
+	vm, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "windows-2019",
+		Windows:       &kubevirtproviderv1.Windows{},
+		Clock:         &kubevirtproviderv1.Clock{Timezone: "Europe/Berlin", HypervTimer: kubevirtproviderv1.TimerStateDisabled},
+	}, []byte("Write-Host bootstrap"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clock = vm.Spec.Template.Spec.Domain.Clock
+	if clock.UTC != nil || clock.Timezone == nil || *clock.Timezone != "Europe/Berlin" {
+		t.Errorf("Expected the clock in the local time of Europe/Berlin, got %+v", clock.ClockOffset)
+	}
+	if clock.Timer.Hyperv == nil || *clock.Timer.Hyperv.Enabled || clock.Timer.RTC == nil {
+		t.Errorf("Expected the Hyper-V timer disabled next to the Windows timers, got %+v", clock.Timer)
+	}
+}
@@ -0,0 +1,147 @@
+package machine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// cloneSlotRequeue is the delay before the creation of a VM waiting for a clone slot of its
+// source PVC is retried.
+const cloneSlotRequeue = 20 * time.Second
+
+// CloneCoordinator bounds the boot volumes cloned concurrently out of the same source PVC, so
+// that scaling up a MachineSet by many machines at once does not start a clone per machine
+// against its golden image. The machines are still created in parallel, up to the concurrency
+// of the machine controller, the creations beyond the limit are queued until clones complete.
+type CloneCoordinator struct {
+	maxConcurrentClones int
+
+	lock sync.Mutex
+	// clones are the machines whose boot volume is being cloned, by source PVC
+	clones map[types.NamespacedName]map[string]struct{}
+}
+
+// NewCloneCoordinator returns a CloneCoordinator allowing maxConcurrentClones clones per
+// source PVC.
+func NewCloneCoordinator(maxConcurrentClones int) *CloneCoordinator {
+	return &CloneCoordinator{
+		maxConcurrentClones: maxConcurrentClones,
+		clones:              map[types.NamespacedName]map[string]struct{}{},
+	}
+}
+
+// acquire takes a clone slot of the source for the machine, returning false along with the
+// clones in flight when none is free. A machine holding a slot already keeps it.
+func (c *CloneCoordinator) acquire(source types.NamespacedName, machine string) (bool, int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	clones := c.clones[source]
+	if _, ok := clones[machine]; ok {
+		return true, len(clones)
+	}
+	if len(clones) >= c.maxConcurrentClones {
+		return false, len(clones)
+	}
+	c.add(source, machine)
+	return true, len(clones) + 1
+}
+
+// track records the clone of the machine regardless of the limit, e.g. for the clones started
+// before the provider restarted.
+func (c *CloneCoordinator) track(source types.NamespacedName, machine string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.add(source, machine)
+}
+
+// add records the clone of the machine. It must be called with the lock held.
+func (c *CloneCoordinator) add(source types.NamespacedName, machine string) {
+	if c.clones[source] == nil {
+		c.clones[source] = map[string]struct{}{}
+	}
+	c.clones[source][machine] = struct{}{}
+}
+
+// release frees the clone slot of the source held by the machine, if any.
+func (c *CloneCoordinator) release(source types.NamespacedName, machine string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.clones[source], machine)
+	if len(c.clones[source]) == 0 {
+		delete(c.clones, source)
+	}
+}
+
+// cloneSource returns the source PVC the boot volume of the machine is cloned out of.
+func (r *Reconciler) cloneSource() types.NamespacedName {
+	return types.NamespacedName{Namespace: infraNamespace(r.machine, r.providerSpec), Name: r.providerSpec.SourcePvcName}
+}
+
+// cloneCondition reports whether the creation of the VM waits for a clone slot.
+func cloneCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.WaitingForCloneSlot,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// acquireCloneSlot takes a clone slot of the source PVC before the VM, and with it its boot
+// volume, is created. The creation is requeued while the slots of the source are exhausted.
+func (r *Reconciler) acquireCloneSlot() error {
+	source := r.cloneSource()
+	acquired, inFlight := r.cloneCoordinator.acquire(source, r.machine.Name)
+	if !acquired {
+		r.log().Info("waiting for a clone slot of the source PVC", "source", source.String(), "clonesInFlight", inFlight)
+		r.machineScope.setProviderStatus(cloneCondition(corev1.ConditionTrue, kubevirtproviderv1.CloneSlotsExhausted,
+			fmt.Sprintf("%d boot volumes are being cloned out of source PVC %s, waiting for one to complete", inFlight, source)))
+		return &machinecontroller.RequeueAfterError{RequeueAfter: cloneSlotRequeue}
+	}
+	r.machineScope.setProviderStatus(cloneCondition(corev1.ConditionFalse, kubevirtproviderv1.CloneSlotAcquired,
+		fmt.Sprintf("Cloning boot volume out of source PVC %s", source)))
+	return nil
+}
+
+// reconcileBootVolumeClone releases the clone slot of the machine once the clone of its boot
+// volume completed. Until then the clone is tracked, for the slot to survive provider restarts.
+func (r *Reconciler) reconcileBootVolumeClone(vm *kubevirtapis.VirtualMachine) error {
+	condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.WaitingForCloneSlot)
+	if condition == nil || condition.Reason != kubevirtproviderv1.CloneSlotAcquired {
+		return nil
+	}
+	name := rootDataVolumeName(vm)
+	if name == "" {
+		return nil
+	}
+
+	source := r.cloneSource()
+	dataVolume, err := r.kubevirtClient.GetDataVolume(r.Context, vm.Namespace, name, &metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get boot DataVolume: %w", err)
+	}
+	if err != nil || (dataVolume.Status.Phase != cdiv1.Succeeded && dataVolume.Status.Phase != cdiv1.Failed) {
+		// Not cloned yet, or being recreated by KubeVirt after a retry
+		r.cloneCoordinator.track(source, r.machine.Name)
+		return nil
+	}
+
+	r.cloneCoordinator.release(source, r.machine.Name)
+	r.machineScope.setProviderStatus(cloneCondition(corev1.ConditionFalse, kubevirtproviderv1.CloneCompleted,
+		fmt.Sprintf("Clone of boot DataVolume %s out of source PVC %s is %s", name, source, dataVolume.Status.Phase)))
+	return nil
+}
@@ -0,0 +1,181 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestCloneCoordinator(t *testing.T) {
+	golden := types.NamespacedName{Namespace: "tenant-a", Name: "golden"}
+	other := types.NamespacedName{Namespace: "tenant-a", Name: "other"}
+	coordinator := NewCloneCoordinator(2)
+
+	for _, machine := range []string{"worker-a", "worker-b"} {
+		if acquired, _ := coordinator.acquire(golden, machine); !acquired {
+			t.Errorf("Expected %s to acquire a clone slot", machine)
+		}
+	}
+	if acquired, inFlight := coordinator.acquire(golden, "worker-c"); acquired || inFlight != 2 {
+		t.Errorf("Expected worker-c to wait for 2 clones, got acquired %v with %d clones", acquired, inFlight)
+	}
+	if acquired, _ := coordinator.acquire(golden, "worker-a"); !acquired {
+		t.Error("Expected worker-a to keep its clone slot")
+	}
+	if acquired, _ := coordinator.acquire(other, "worker-c"); !acquired {
+		t.Error("Expected the slots of another source to be free")
+	}
+
+	coordinator.release(golden, "worker-b")
+	if acquired, _ := coordinator.acquire(golden, "worker-c"); !acquired {
+		t.Error("Expected worker-c to acquire the released clone slot")
+	}
+
+	coordinator.track(golden, "worker-d")
+	if inFlight := len(coordinator.clones[golden]); inFlight != 3 {
+		t.Errorf("Expected the tracked clone to exceed the limit, got %d clones", inFlight)
+	}
+}
+
+func TestAcquireCloneSlot(t *testing.T) {
+	coordinator := NewCloneCoordinator(1)
+	newCloneReconciler := func(machine string) *Reconciler {
+		return newReconciler(&machineScope{
+			Context:          context.Background(),
+			cloneCoordinator: coordinator,
+			machine:          &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: machine, Namespace: "tenant-a"}},
+			providerSpec:     &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "golden"},
+			providerStatus:   &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+		})
+	}
+
+	first := newCloneReconciler("worker-a")
+	if err := first.acquireCloneSlot(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if condition := findProviderCondition(first.providerStatus.Conditions, kubevirtproviderv1.WaitingForCloneSlot); condition == nil || condition.Reason != kubevirtproviderv1.CloneSlotAcquired {
+		t.Errorf("Expected reason %v, got condition %v", kubevirtproviderv1.CloneSlotAcquired, condition)
+	}
+
+	second := newCloneReconciler("worker-b")
+	err := second.acquireCloneSlot()
+	if requeue, ok := err.(*machinecontroller.RequeueAfterError); !ok || requeue.RequeueAfter != cloneSlotRequeue {
+		t.Fatalf("Expected a requeue after %v, got %v", cloneSlotRequeue, err)
+	}
+	if condition := findProviderCondition(second.providerStatus.Conditions, kubevirtproviderv1.WaitingForCloneSlot); condition == nil || condition.Reason != kubevirtproviderv1.CloneSlotsExhausted {
+		t.Errorf("Expected reason %v, got condition %v", kubevirtproviderv1.CloneSlotsExhausted, condition)
+	}
+}
+
+func TestReconcileBootVolumeClone(t *testing.T) {
+	source := types.NamespacedName{Namespace: "tenant-a", Name: "golden"}
+	dataVolumeNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "datavolumes"}, "worker-abcde-bootvolume")
+
+	testCases := []struct {
+		testcase       string
+		reason         kubevirtproviderv1.KubevirtMachineProviderConditionReason
+		phase          cdiv1.DataVolumePhase
+		getErr         error
+		expectGet      bool
+		expectTracked  bool
+		expectedReason kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase:       "clone in progress",
+			reason:         kubevirtproviderv1.CloneSlotAcquired,
+			phase:          cdiv1.CloneInProgress,
+			expectGet:      true,
+			expectTracked:  true,
+			expectedReason: kubevirtproviderv1.CloneSlotAcquired,
+		},
+		{
+			testcase:       "boot volume being recreated",
+			reason:         kubevirtproviderv1.CloneSlotAcquired,
+			getErr:         dataVolumeNotFound,
+			expectGet:      true,
+			expectTracked:  true,
+			expectedReason: kubevirtproviderv1.CloneSlotAcquired,
+		},
+		{
+			testcase:       "clone succeeded",
+			reason:         kubevirtproviderv1.CloneSlotAcquired,
+			phase:          cdiv1.Succeeded,
+			expectGet:      true,
+			expectedReason: kubevirtproviderv1.CloneCompleted,
+		},
+		{
+			testcase:       "clone failed",
+			reason:         kubevirtproviderv1.CloneSlotAcquired,
+			phase:          cdiv1.Failed,
+			expectGet:      true,
+			expectedReason: kubevirtproviderv1.CloneCompleted,
+		},
+		{
+			testcase:       "clone completed before",
+			reason:         kubevirtproviderv1.CloneCompleted,
+			expectedReason: kubevirtproviderv1.CloneCompleted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.expectGet {
+				client.EXPECT().GetDataVolume(gomock.Any(), "tenant-a", "worker-abcde-bootvolume", gomock.Any()).Return(&cdiv1.DataVolume{Status: cdiv1.DataVolumeStatus{Phase: tc.phase}}, tc.getErr)
+			}
+
+			coordinator := NewCloneCoordinator(1)
+			r := newReconciler(&machineScope{
+				Context:          context.Background(),
+				kubevirtClient:   client,
+				cloneCoordinator: coordinator,
+				machine:          &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}},
+				providerSpec:     &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "golden"},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{
+					Conditions: []kubevirtproviderv1.KubevirtMachineProviderCondition{cloneCondition(corev1.ConditionFalse, tc.reason, "")},
+				},
+			})
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec: kubevirtapis.VirtualMachineSpec{
+					Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{
+						Spec: kubevirtapis.VirtualMachineInstanceSpec{
+							Volumes: []kubevirtapis.Volume{{
+								Name: mainDiskName,
+								VolumeSource: kubevirtapis.VolumeSource{
+									DataVolume: &kubevirtapis.DataVolumeSource{Name: "worker-abcde-bootvolume"},
+								},
+							}},
+						},
+					},
+				},
+			}
+
+			if err := r.reconcileBootVolumeClone(vm); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, tracked := coordinator.clones[source]["worker-abcde"]; tracked != tc.expectTracked {
+				t.Errorf("Expected tracked %v, got %v", tc.expectTracked, tracked)
+			}
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.WaitingForCloneSlot)
+			if condition == nil || condition.Reason != tc.expectedReason {
+				t.Errorf("Expected reason %v, got condition %v", tc.expectedReason, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
+)
+
+const (
+	// coldMigrationStorageClassAnnotation requests the root disk of the VM to be moved to
+	// the given storage class while the VM is stopped.
+	coldMigrationStorageClassAnnotation = "kubevirtproviderconfig.openshift.io/cold-migration-storage-class"
+	// coldMigrationZoneAnnotation requests the VM to be moved to the given infra zone while
+	// it is stopped.
+	coldMigrationZoneAnnotation = "kubevirtproviderconfig.openshift.io/cold-migration-zone"
+)
+
+// coldMigrationTarget returns the storage class and zone the machine is requested to move to.
+func coldMigrationTarget(machine *machinev1.Machine) (string, string) {
+	return machine.Annotations[coldMigrationStorageClassAnnotation], machine.Annotations[coldMigrationZoneAnnotation]
+}
+
+func coldMigrationInProgress(status *kubevirtproviderv1.ColdMigrationStatus) bool {
+	return status != nil && status.Phase != kubevirtproviderv1.ColdMigrationSucceeded && status.Phase != kubevirtproviderv1.ColdMigrationFailed
+}
+
+// coldMigrationRequested returns true if a cold migration to a target other than the one of
+// the last cold migration is requested. A failed cold migration is retried once its target
+// annotations are changed, or removed and set again.
+func coldMigrationRequested(machine *machinev1.Machine, status *kubevirtproviderv1.ColdMigrationStatus) bool {
+	storageClass, zone := coldMigrationTarget(machine)
+	if storageClass == "" && zone == "" {
+		return false
+	}
+	return status == nil || status.TargetStorageClass != storageClass || status.TargetZone != zone
+}
+
+// newColdMigration starts a cold migration of the root disk of the VM.
+func newColdMigration(vm *kubevirtapis.VirtualMachine, storageClass, zone string, now metav1.Time) (*kubevirtproviderv1.ColdMigrationStatus, error) {
+	sourceVolume := rootDataVolumeName(vm)
+	if sourceVolume == "" {
+		return nil, fmt.Errorf("VirtualMachine %s has no root DataVolume", vm.Name)
+	}
+
+	return &kubevirtproviderv1.ColdMigrationStatus{
+		Phase:              kubevirtproviderv1.ColdMigrationStopping,
+		TargetStorageClass: storageClass,
+		TargetZone:         zone,
+		SourceVolume:       sourceVolume,
+		TargetVolume:       fmt.Sprintf("%s%s-%x", vm.Name, bootVolumeSuffix, now.Unix()),
+		StartTime:          &now,
+		Message:            "Stopping VirtualMachine",
+	}, nil
+}
+
+// rootDataVolumeName returns the name of the DataVolume backing the root disk of the VM.
+func rootDataVolumeName(vm *kubevirtapis.VirtualMachine) string {
+	if vm.Spec.Template == nil {
+		return ""
+	}
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.Name == mainDiskName && volume.DataVolume != nil {
+			return volume.DataVolume.Name
+		}
+	}
+	return ""
+}
+
+// buildColdMigrationVolume renders the DataVolume cloning the root disk of the stopped VM into the
+// target storage class. It is owned by the VM so that KubeVirt adopts it as a DataVolume template.
+func buildColdMigrationVolume(vm *kubevirtapis.VirtualMachine, status *kubevirtproviderv1.ColdMigrationStatus) (*cdiv1.DataVolume, error) {
+	var source *cdiv1.DataVolume
+	for i := range vm.Spec.DataVolumeTemplates {
+		if vm.Spec.DataVolumeTemplates[i].Name == status.SourceVolume {
+			source = &vm.Spec.DataVolumeTemplates[i]
+		}
+	}
+	if source == nil || source.Spec.PVC == nil {
+		return nil, fmt.Errorf("VirtualMachine %s has no DataVolume template for %s", vm.Name, status.SourceVolume)
+	}
+
+	pvcSpec := source.Spec.PVC.DeepCopy()
+	if status.TargetStorageClass != "" {
+		storageClassName := status.TargetStorageClass
+		pvcSpec.StorageClassName = &storageClassName
+	}
+
+	return &cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      status.TargetVolume,
+			Namespace: vm.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(vm, kubevirtapis.VirtualMachineGroupVersionKind),
+			},
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Source: cdiv1.DataVolumeSource{
+				PVC: &cdiv1.DataVolumeSourcePVC{
+					Name:      status.SourceVolume,
+					Namespace: vm.Namespace,
+				},
+			},
+			PVC: pvcSpec,
+		},
+	}, nil
+}
+
+// applyColdMigration switches the root disk of the VM to the target volume and pins the VM
+// to the target zone.
+func applyColdMigration(vm *kubevirtapis.VirtualMachine, targetVolume *cdiv1.DataVolume, zone string) {
+	template := cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      targetVolume.Name,
+			Namespace: targetVolume.Namespace,
+		},
+		Spec: *targetVolume.Spec.DeepCopy(),
+	}
+
+	sourceVolume := rootDataVolumeName(vm)
+	dataVolumeTemplates := []cdiv1.DataVolume{template}
+	for _, dataVolumeTemplate := range vm.Spec.DataVolumeTemplates {
+		if dataVolumeTemplate.Name != sourceVolume {
+			dataVolumeTemplates = append(dataVolumeTemplates, dataVolumeTemplate)
+		}
+	}
+	vm.Spec.DataVolumeTemplates = dataVolumeTemplates
+
+	for i := range vm.Spec.Template.Spec.Volumes {
+		if volume := &vm.Spec.Template.Spec.Volumes[i]; volume.Name == mainDiskName {
+			volume.DataVolume = &kubevirtapis.DataVolumeSource{Name: targetVolume.Name}
+		}
+	}
+
+	if zone != "" {
+		if vm.Spec.Template.Spec.NodeSelector == nil {
+			vm.Spec.Template.Spec.NodeSelector = map[string]string{}
+		}
+		vm.Spec.Template.Spec.NodeSelector[corev1.LabelZoneFailureDomainStable] = zone
+	}
+}
+
+// reconcileColdMigration drives the cold migration requested through the machine annotations,
+// one step per reconcile: stop the VM, clone its root disk, switch the VM over and start it again.
+// It returns a RequeueAfterError while the cold migration is in progress.
+func (r *Reconciler) reconcileColdMigration(vm *kubevirtapis.VirtualMachine) error {
+	status := r.machineScope.providerStatus.ColdMigration
+	if !coldMigrationInProgress(status) {
+		storageClass, zone := coldMigrationTarget(r.machine)
+		if storageClass == "" && zone == "" && status != nil && status.Phase == kubevirtproviderv1.ColdMigrationFailed {
+			// Forget the failed cold migration so that it can be requested again
+			r.machineScope.providerStatus.ColdMigration = nil
+		}
+		if !coldMigrationRequested(r.machine, status) {
+			return nil
+		}
+
+		var err error
+		if status, err = newColdMigration(vm, storageClass, zone, metav1.Now()); err != nil {
+			return fmt.Errorf("cannot cold migrate: %w", err)
+		}
+		klog.Infof("%s: starting cold migration of %s to storage class %q and zone %q", r.machine.Name, status.SourceVolume, storageClass, zone)
+		r.machineScope.providerStatus.ColdMigration = status
+	}
+
+	var err error
+	switch status.Phase {
+	case kubevirtproviderv1.ColdMigrationStopping:
+		err = r.stopForColdMigration(vm, status)
+	case kubevirtproviderv1.ColdMigrationCloning:
+		err = r.cloneForColdMigration(vm, status)
+	case kubevirtproviderv1.ColdMigrationStarting:
+		err = r.startAfterColdMigration(vm, status)
+	}
+	if err != nil {
+		return err
+	}
+
+	if coldMigrationInProgress(status) {
+		return &machinecontroller.RequeueAfterError{RequeueAfter: requeueAfterSeconds * time.Second}
+	}
+	return nil
+}
+
+func (r *Reconciler) stopForColdMigration(vm *kubevirtapis.VirtualMachine, status *kubevirtproviderv1.ColdMigrationStatus) error {
+	if !vmHalted(vm) {
+		haltVM(vm)
+		if _, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, vm.Namespace, vm); err != nil {
+			return fmt.Errorf("failed to stop VirtualMachine: %w", err)
+		}
+		return nil
+	}
+
+	vmi, err := r.getMachineVMI()
+	if err != nil {
+		return err
+	}
+	if vmi != nil {
+		// Wait for the VM to be stopped before cloning its root disk
+		return nil
+	}
+
+	targetVolume, err := buildColdMigrationVolume(vm, status)
+	if err != nil {
+		return err
+	}
+	if err := tracing.Trace(r.Context, "CreateDataVolume", func() error {
+		_, err := r.kubevirtClient.CreateDataVolume(r.Context, vm.Namespace, targetVolume)
+		return err
+	}, tracing.String("dataVolume.name", targetVolume.Name)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create DataVolume %s: %w", targetVolume.Name, err)
+	}
+
+	status.Phase = kubevirtproviderv1.ColdMigrationCloning
+	status.Message = fmt.Sprintf("Cloning %s to %s", status.SourceVolume, status.TargetVolume)
+	return nil
+}
+
+func (r *Reconciler) cloneForColdMigration(vm *kubevirtapis.VirtualMachine, status *kubevirtproviderv1.ColdMigrationStatus) error {
+	targetVolume, err := r.kubevirtClient.GetDataVolume(r.Context, vm.Namespace, status.TargetVolume, &metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get DataVolume %s: %w", status.TargetVolume, err)
+	}
+
+	runStrategy, err := resolveRunStrategy(r.providerSpec)
+	if err != nil {
+		return err
+	}
+
+	switch targetVolume.Status.Phase {
+	case cdiv1.Succeeded:
+		applyColdMigration(vm, targetVolume, status.TargetZone)
+		applyRunStrategy(vm, runStrategy)
+		if _, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, vm.Namespace, vm); err != nil {
+			return fmt.Errorf("failed to switch VirtualMachine to %s: %w", status.TargetVolume, err)
+		}
+		if err := startManualVM(r.Context, r.kubevirtClient, vm, runStrategy); err != nil {
+			return err
+		}
+		status.Phase = kubevirtproviderv1.ColdMigrationStarting
+		status.Message = fmt.Sprintf("Starting VirtualMachine from %s", status.TargetVolume)
+	case cdiv1.Failed:
+		klog.Errorf("%s: cold migration failed to clone %s to %s", r.machine.Name, status.SourceVolume, status.TargetVolume)
+		if err := r.kubevirtClient.DeleteDataVolume(r.Context, vm.Namespace, status.TargetVolume, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete DataVolume %s: %w", status.TargetVolume, err)
+		}
+		applyRunStrategy(vm, runStrategy)
+		if _, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, vm.Namespace, vm); err != nil {
+			return fmt.Errorf("failed to start VirtualMachine: %w", err)
+		}
+		if err := startManualVM(r.Context, r.kubevirtClient, vm, runStrategy); err != nil {
+			return err
+		}
+		now := metav1.Now()
+		status.Phase = kubevirtproviderv1.ColdMigrationFailed
+		status.CompletionTime = &now
+		status.Message = fmt.Sprintf("Failed to clone %s to %s, VirtualMachine restarted from %s", status.SourceVolume, status.TargetVolume, status.SourceVolume)
+	default:
+		status.Message = fmt.Sprintf("Cloning %s to %s: %s", status.SourceVolume, status.TargetVolume, targetVolume.Status.Progress)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) startAfterColdMigration(vm *kubevirtapis.VirtualMachine, status *kubevirtproviderv1.ColdMigrationStatus) error {
+	if !vm.Status.Ready {
+		return nil
+	}
+
+	if err := r.kubevirtClient.DeleteDataVolume(r.Context, vm.Namespace, status.SourceVolume, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete DataVolume %s: %w", status.SourceVolume, err)
+	}
+
+	klog.Infof("%s: cold migration to %s succeeded", r.machine.Name, status.TargetVolume)
+	now := metav1.Now()
+	status.Phase = kubevirtproviderv1.ColdMigrationSucceeded
+	status.CompletionTime = &now
+	status.Message = fmt.Sprintf("VirtualMachine running from %s", status.TargetVolume)
+	return nil
+}
@@ -0,0 +1,106 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestColdMigrationRequested(t *testing.T) {
+	testCases := []struct {
+		testcase        string
+		annotations     map[string]string
+		status          *kubevirtproviderv1.ColdMigrationStatus
+		expectRequested bool
+	}{
+		{
+			testcase: "no annotations",
+		},
+		{
+			testcase:        "first cold migration",
+			annotations:     map[string]string{coldMigrationStorageClassAnnotation: "fast"},
+			expectRequested: true,
+		},
+		{
+			testcase:    "already migrated",
+			annotations: map[string]string{coldMigrationStorageClassAnnotation: "fast"},
+			status: &kubevirtproviderv1.ColdMigrationStatus{
+				Phase:              kubevirtproviderv1.ColdMigrationSucceeded,
+				TargetStorageClass: "fast",
+			},
+		},
+		{
+			testcase:    "new target",
+			annotations: map[string]string{coldMigrationStorageClassAnnotation: "fast", coldMigrationZoneAnnotation: "zone-b"},
+			status: &kubevirtproviderv1.ColdMigrationStatus{
+				Phase:              kubevirtproviderv1.ColdMigrationSucceeded,
+				TargetStorageClass: "fast",
+			},
+			expectRequested: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if requested := coldMigrationRequested(machine, tc.status); requested != tc.expectRequested {
+				t.Errorf("Expected requested: %v, got: %v", tc.expectRequested, requested)
+			}
+		})
+	}
+}
+
+func TestApplyColdMigration(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubevirt-test",
+			Namespace: "kubevirt-test",
+		},
+	}
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:    "rhcos",
+		StorageClassName: "slow",
+	}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	vm.UID = "vm-uid"
+
+	status, err := newColdMigration(vm, "fast", "zone-b", metav1.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status.SourceVolume != "kubevirt-test-bootvolume" {
+		t.Errorf("Expected source volume %q, got %q", "kubevirt-test-bootvolume", status.SourceVolume)
+	}
+
+	targetVolume, err := buildColdMigrationVolume(vm, status)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if source := targetVolume.Spec.Source.PVC; source == nil || source.Name != status.SourceVolume {
+		t.Errorf("Expected target volume to clone %q, got %+v", status.SourceVolume, source)
+	}
+	if storageClass := targetVolume.Spec.PVC.StorageClassName; storageClass == nil || *storageClass != "fast" {
+		t.Errorf("Expected target storage class %q, got %v", "fast", storageClass)
+	}
+	if len(targetVolume.OwnerReferences) != 1 || targetVolume.OwnerReferences[0].UID != vm.UID {
+		t.Errorf("Expected target volume to be owned by the VM, got %+v", targetVolume.OwnerReferences)
+	}
+
+	applyColdMigration(vm, targetVolume, status.TargetZone)
+
+	if name := rootDataVolumeName(vm); name != status.TargetVolume {
+		t.Errorf("Expected root disk on %q, got %q", status.TargetVolume, name)
+	}
+	if len(vm.Spec.DataVolumeTemplates) != 1 || vm.Spec.DataVolumeTemplates[0].Name != status.TargetVolume {
+		t.Errorf("Expected the DataVolume template of %q only, got %+v", status.TargetVolume, vm.Spec.DataVolumeTemplates)
+	}
+	if zone := vm.Spec.Template.Spec.NodeSelector[corev1.LabelZoneFailureDomainStable]; zone != "zone-b" {
+		t.Errorf("Expected zone %q, got %q", "zone-b", zone)
+	}
+}
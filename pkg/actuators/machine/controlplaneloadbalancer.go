@@ -0,0 +1,233 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
+)
+
+const (
+	// controlPlaneServiceLabel is set on the VMs, their VMI template and virt-launcher pods to
+	// the name of the Service fronting their API server, which selects them by it
+	controlPlaneServiceLabel = "kubevirtproviderconfig.openshift.io/control-plane-service"
+	// defaultAPIServerPort is the API server port of the VMs when the provider spec does not say
+	defaultAPIServerPort = 6443
+	// apiServerPortName names the API server port of the Service
+	apiServerPortName = "https"
+)
+
+// validateControlPlaneLoadBalancer returns an error if the service name, type or port is invalid.
+func validateControlPlaneLoadBalancer(loadBalancer *kubevirtproviderv1.ControlPlaneLoadBalancer) error {
+	if errs := validation.IsDNS1035Label(loadBalancer.ServiceName); len(errs) > 0 {
+		return fmt.Errorf("invalid controlPlaneLoadBalancer serviceName %q: %s", loadBalancer.ServiceName, strings.Join(errs, ", "))
+	}
+	switch loadBalancer.Type {
+	case "", corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeNodePort:
+	default:
+		return fmt.Errorf("unsupported controlPlaneLoadBalancer type %q, must be one of %q or %q", loadBalancer.Type,
+			corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeNodePort)
+	}
+	if loadBalancer.Port != 0 {
+		if errs := validation.IsValidPortNum(int(loadBalancer.Port)); len(errs) > 0 {
+			return fmt.Errorf("invalid controlPlaneLoadBalancer port %d: %s", loadBalancer.Port, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+func controlPlaneServiceType(loadBalancer *kubevirtproviderv1.ControlPlaneLoadBalancer) corev1.ServiceType {
+	if loadBalancer.Type == "" {
+		return corev1.ServiceTypeLoadBalancer
+	}
+	return loadBalancer.Type
+}
+
+func apiServerPort(loadBalancer *kubevirtproviderv1.ControlPlaneLoadBalancer) int32 {
+	if loadBalancer.Port == 0 {
+		return defaultAPIServerPort
+	}
+	return loadBalancer.Port
+}
+
+// applyControlPlaneServiceSpec sets the type, selector and port of the Service fronting the
+// control plane VMs, keeping the node port the infra cluster allocated, and returns whether the
+// Service changed.
+func applyControlPlaneServiceSpec(service *corev1.Service, loadBalancer *kubevirtproviderv1.ControlPlaneLoadBalancer) bool {
+	port := apiServerPort(loadBalancer)
+	servicePort := corev1.ServicePort{
+		Name:       apiServerPortName,
+		Protocol:   corev1.ProtocolTCP,
+		Port:       port,
+		TargetPort: intstr.FromInt(int(port)),
+	}
+	if len(service.Spec.Ports) == 1 {
+		servicePort.NodePort = service.Spec.Ports[0].NodePort
+	}
+	desired := service.Spec.DeepCopy()
+	desired.Type = controlPlaneServiceType(loadBalancer)
+	desired.Selector = map[string]string{controlPlaneServiceLabel: loadBalancer.ServiceName}
+	desired.Ports = []corev1.ServicePort{servicePort}
+	if equality.Semantic.DeepEqual(&service.Spec, desired) {
+		return false
+	}
+	service.Spec = *desired
+	return true
+}
+
+// controlPlaneEndpoint returns the host:port the Service exposes the API server at, empty until
+// the infra cluster assigned the address of its load balancer.
+func controlPlaneEndpoint(service *corev1.Service) string {
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer || len(service.Spec.Ports) == 0 {
+		return ""
+	}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		host := ingress.IP
+		if host == "" {
+			host = ingress.Hostname
+		}
+		if host != "" {
+			return net.JoinHostPort(host, strconv.Itoa(int(service.Spec.Ports[0].Port)))
+		}
+	}
+	return ""
+}
+
+// controlPlaneLoadBalancerReadyCondition reports whether the Service fronting the API server of
+// the control plane VMs is reachable.
+func controlPlaneLoadBalancerReadyCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.ControlPlaneLoadBalancerReady,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reconcileControlPlaneLoadBalancer labels the VM and its running virt-launcher pod for the
+// Service fronting the API server to select them, creates or updates the Service and reports
+// its endpoint.
+func (r *Reconciler) reconcileControlPlaneLoadBalancer(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) (*kubevirtapis.VirtualMachine, error) {
+	loadBalancer := r.providerSpec.ControlPlaneLoadBalancer
+	if loadBalancer == nil {
+		r.providerStatus.ControlPlaneEndpoint = ""
+		return vm, nil
+	}
+	if err := validateControlPlaneLoadBalancer(loadBalancer); err != nil {
+		return vm, machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+
+	// VMs created before the load balancer was configured are labeled for their next VMIs
+	if vm.Spec.Template != nil && vm.Spec.Template.ObjectMeta.Labels[controlPlaneServiceLabel] != loadBalancer.ServiceName {
+		updated := vm.DeepCopy()
+		if updated.Spec.Template.ObjectMeta.Labels == nil {
+			updated.Spec.Template.ObjectMeta.Labels = map[string]string{}
+		}
+		updated.Spec.Template.ObjectMeta.Labels[controlPlaneServiceLabel] = loadBalancer.ServiceName
+		var err error
+		if vm, err = r.kubevirtClient.UpdateVirtualMachine(r.Context, updated.Namespace, updated); err != nil {
+			return vm, fmt.Errorf("failed to label VirtualMachine for control plane Service %s: %w", loadBalancer.ServiceName, err)
+		}
+	}
+	if err := r.labelLauncherPodForService(vmi, loadBalancer.ServiceName); err != nil {
+		return vm, err
+	}
+
+	service, err := r.ensureControlPlaneService(loadBalancer)
+	if err != nil {
+		return vm, err
+	}
+
+	if service.Spec.Type == corev1.ServiceTypeNodePort && len(service.Spec.Ports) > 0 {
+		r.providerStatus.ControlPlaneEndpoint = ""
+		r.machineScope.setProviderStatus(controlPlaneLoadBalancerReadyCondition(corev1.ConditionTrue, kubevirtproviderv1.LoadBalancerReady,
+			fmt.Sprintf("Service %s exposes the API server on node port %d of the infra nodes", service.Name, service.Spec.Ports[0].NodePort)))
+		return vm, nil
+	}
+	endpoint := controlPlaneEndpoint(service)
+	r.providerStatus.ControlPlaneEndpoint = endpoint
+	if endpoint == "" {
+		r.machineScope.setProviderStatus(controlPlaneLoadBalancerReadyCondition(corev1.ConditionFalse, kubevirtproviderv1.LoadBalancerPending,
+			fmt.Sprintf("Waiting for the infra cluster to assign the load balancer of Service %s", service.Name)))
+		return vm, nil
+	}
+	r.machineScope.setProviderStatus(controlPlaneLoadBalancerReadyCondition(corev1.ConditionTrue, kubevirtproviderv1.LoadBalancerReady,
+		fmt.Sprintf("Service %s exposes the API server at %s", service.Name, endpoint)))
+	return vm, nil
+}
+
+// labelLauncherPodForService labels the running virt-launcher pod of the VMI for the Service to
+// select it, the pod not picking up the labels of the VM template.
+func (r *Reconciler) labelLauncherPodForService(vmi *kubevirtapis.VirtualMachineInstance, serviceName string) error {
+	if vmi == nil {
+		return nil
+	}
+	pod, err := r.getLauncherPod(vmi)
+	if err != nil || pod == nil || pod.Labels[controlPlaneServiceLabel] == serviceName {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{controlPlaneServiceLabel: serviceName},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := r.kubevirtClient.PatchPod(r.Context, pod.Namespace, pod.Name, types.MergePatchType, patch); err != nil {
+		return fmt.Errorf("failed to label virt-launcher pod %s for control plane Service %s: %w", pod.Name, serviceName, err)
+	}
+	return nil
+}
+
+// ensureControlPlaneService creates the Service fronting the API server of the control plane
+// VMs, or updates it if it drifted from the provider spec.
+func (r *Reconciler) ensureControlPlaneService(loadBalancer *kubevirtproviderv1.ControlPlaneLoadBalancer) (*corev1.Service, error) {
+	namespace := infraNamespace(r.machine, r.providerSpec)
+	service, err := r.kubevirtClient.GetService(r.Context, namespace, loadBalancer.ServiceName, &metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get control plane Service %s: %w", loadBalancer.ServiceName, err)
+	}
+
+	if apierrors.IsNotFound(err) {
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      loadBalancer.ServiceName,
+				Namespace: namespace,
+			},
+		}
+		applyControlPlaneServiceSpec(service, loadBalancer)
+		r.log().Info("creating control plane Service", "service", service.Name, "type", service.Spec.Type)
+		err := tracing.Trace(r.Context, "CreateService", func() (err error) {
+			service, err = r.kubevirtClient.CreateService(r.Context, namespace, service)
+			return err
+		}, tracing.String("service.name", loadBalancer.ServiceName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create control plane Service %s: %w", loadBalancer.ServiceName, err)
+		}
+		return service, nil
+	}
+
+	if !applyControlPlaneServiceSpec(service, loadBalancer) {
+		return service, nil
+	}
+	r.log().Info("updating control plane Service", "service", service.Name, "type", service.Spec.Type)
+	if service, err = r.kubevirtClient.UpdateService(r.Context, namespace, service); err != nil {
+		return nil, fmt.Errorf("failed to update control plane Service %s: %w", loadBalancer.ServiceName, err)
+	}
+	return service, nil
+}
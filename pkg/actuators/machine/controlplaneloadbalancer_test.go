@@ -0,0 +1,208 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestValidateControlPlaneLoadBalancer(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		loadBalancer kubevirtproviderv1.ControlPlaneLoadBalancer
+		expectError  bool
+	}{
+		{
+			testcase:     "defaults",
+			loadBalancer: kubevirtproviderv1.ControlPlaneLoadBalancer{ServiceName: "tenant-a-api"},
+		},
+		{
+			testcase:     "node port",
+			loadBalancer: kubevirtproviderv1.ControlPlaneLoadBalancer{ServiceName: "tenant-a-api", Type: corev1.ServiceTypeNodePort, Port: 443},
+		},
+		{
+			testcase:     "invalid service name",
+			loadBalancer: kubevirtproviderv1.ControlPlaneLoadBalancer{ServiceName: "Tenant.API"},
+			expectError:  true,
+		},
+		{
+			testcase:     "unsupported type",
+			loadBalancer: kubevirtproviderv1.ControlPlaneLoadBalancer{ServiceName: "tenant-a-api", Type: corev1.ServiceTypeClusterIP},
+			expectError:  true,
+		},
+		{
+			testcase:     "invalid port",
+			loadBalancer: kubevirtproviderv1.ControlPlaneLoadBalancer{ServiceName: "tenant-a-api", Port: 70000},
+			expectError:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			if err := validateControlPlaneLoadBalancer(&tc.loadBalancer); (err != nil) != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestReconcileControlPlaneLoadBalancer(t *testing.T) {
+	serviceNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, "tenant-a-api")
+	existingService := func(serviceType corev1.ServiceType, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-a-api", Namespace: "tenant-a"},
+			Spec: corev1.ServiceSpec{
+				Type:      serviceType,
+				ClusterIP: "172.30.0.10",
+				Selector:  map[string]string{controlPlaneServiceLabel: "tenant-a-api"},
+				Ports: []corev1.ServicePort{{
+					Name:       apiServerPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       6443,
+					TargetPort: intstr.FromInt(6443),
+					NodePort:   31443,
+				}},
+			},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}},
+		}
+	}
+
+	testCases := []struct {
+		testcase       string
+		serviceType    corev1.ServiceType
+		expectClient   func(client *mockkubevirt.MockClient)
+		expectEndpoint string
+		expectReason   kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase: "creates the Service",
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetService(gomock.Any(), "tenant-a", "tenant-a-api", gomock.Any()).Return(nil, serviceNotFound)
+				client.EXPECT().CreateService(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
+					if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+						t.Errorf("Expected a LoadBalancer Service, got %v", service.Spec.Type)
+					}
+					if service.Spec.Selector[controlPlaneServiceLabel] != "tenant-a-api" {
+						t.Errorf("Expected the Service to select the control plane VMs, got %v", service.Spec.Selector)
+					}
+					if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != 6443 {
+						t.Errorf("Expected the API server port, got %v", service.Spec.Ports)
+					}
+					return service, nil
+				})
+			},
+			expectReason: kubevirtproviderv1.LoadBalancerPending,
+		},
+		{
+			testcase: "reports the load balancer address",
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetService(gomock.Any(), "tenant-a", "tenant-a-api", gomock.Any()).Return(existingService(corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{IP: "192.0.2.10"}), nil)
+			},
+			expectEndpoint: "192.0.2.10:6443",
+			expectReason:   kubevirtproviderv1.LoadBalancerReady,
+		},
+		{
+			testcase:    "switches the Service to a node port",
+			serviceType: corev1.ServiceTypeNodePort,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetService(gomock.Any(), "tenant-a", "tenant-a-api", gomock.Any()).Return(existingService(corev1.ServiceTypeLoadBalancer), nil)
+				client.EXPECT().UpdateService(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
+					if service.Spec.Type != corev1.ServiceTypeNodePort || service.Spec.Ports[0].NodePort != 31443 || service.Spec.ClusterIP != "172.30.0.10" {
+						t.Errorf("Expected a NodePort Service keeping its allocations, got %v", service.Spec)
+					}
+					return service, nil
+				})
+			},
+			expectReason: kubevirtproviderv1.LoadBalancerReady,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			tc.expectClient(client)
+
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec: kubevirtapis.VirtualMachineSpec{Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{controlPlaneServiceLabel: "tenant-a-api"}},
+				}},
+			}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "master-0", Namespace: "tenant-a"}},
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					ControlPlaneLoadBalancer: &kubevirtproviderv1.ControlPlaneLoadBalancer{ServiceName: "tenant-a-api", Type: tc.serviceType},
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			if _, err := r.reconcileControlPlaneLoadBalancer(vm, nil); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if r.providerStatus.ControlPlaneEndpoint != tc.expectEndpoint {
+				t.Errorf("Expected control plane endpoint %q, got %q", tc.expectEndpoint, r.providerStatus.ControlPlaneEndpoint)
+			}
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.ControlPlaneLoadBalancerReady)
+			if condition == nil || condition.Reason != tc.expectReason {
+				t.Errorf("Expected reason %v, got condition %v", tc.expectReason, condition)
+			}
+		})
+	}
+}
+
+func TestReconcileControlPlaneLoadBalancerLabelsVM(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client := mockkubevirt.NewMockClient(mockCtrl)
+	client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+		if vm.Spec.Template.ObjectMeta.Labels[controlPlaneServiceLabel] != "tenant-a-api" {
+			t.Errorf("Expected the VM template to be labeled, got %v", vm.Spec.Template.ObjectMeta.Labels)
+		}
+		return vm, nil
+	})
+	client.EXPECT().ListPods(gomock.Any(), "tenant-a", gomock.Any()).Return(&corev1.PodList{Items: []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "virt-launcher-master-0-x1y2z", Namespace: "tenant-a"}},
+	}}, nil)
+	client.EXPECT().PatchPod(gomock.Any(), "tenant-a", "virt-launcher-master-0-x1y2z", types.MergePatchType, gomock.Any()).Return(&corev1.Pod{}, nil)
+	client.EXPECT().GetService(gomock.Any(), "tenant-a", "tenant-a-api", gomock.Any()).Return(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a-api", Namespace: "tenant-a"},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{controlPlaneServiceLabel: "tenant-a-api"},
+			Ports:    []corev1.ServicePort{{Name: apiServerPortName, Protocol: corev1.ProtocolTCP, Port: 6443, TargetPort: intstr.FromInt(6443)}},
+		},
+	}, nil)
+
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "master-0", Namespace: "tenant-a"},
+		Spec:       kubevirtapis.VirtualMachineSpec{Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{}},
+	}
+	vmi := &kubevirtapis.VirtualMachineInstance{ObjectMeta: metav1.ObjectMeta{Name: "master-0", Namespace: "tenant-a", UID: "vmi-uid"}}
+	r := newReconciler(&machineScope{
+		Context:        context.Background(),
+		kubevirtClient: client,
+		machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "master-0", Namespace: "tenant-a"}},
+		providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+			ControlPlaneLoadBalancer: &kubevirtproviderv1.ControlPlaneLoadBalancer{ServiceName: "tenant-a-api"},
+		},
+		providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+	})
+	if _, err := r.reconcileControlPlaneLoadBalancer(vm, vmi); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
+)
+
+const (
+	// defaultDataExportTimeout bounds the export of the volumes of a VM when the provider spec
+	// does not.
+	defaultDataExportTimeout = time.Hour
+	// dataExportPollInterval is the delay between the checks of the volumes being exported.
+	dataExportPollInterval = 10 * time.Second
+
+	// exportedMachineLabel is set on the archived DataVolumes to the name of the machine
+	// their data was exported from.
+	exportedMachineLabel = "kubevirtproviderconfig.openshift.io/exported-machine"
+	// exportedNamespaceAnnotation is set on the archived DataVolumes to the namespace of the
+	// VM their data was exported from.
+	exportedNamespaceAnnotation = "kubevirtproviderconfig.openshift.io/exported-namespace"
+)
+
+// validateDataExport returns an error if the data export names no volume or archive namespace,
+// or if its timeout is not positive.
+func validateDataExport(dataExport *kubevirtproviderv1.DataExport) error {
+	if len(dataExport.Volumes) == 0 {
+		return fmt.Errorf("dataExport must name at least one volume")
+	}
+	if dataExport.ArchiveNamespace == "" {
+		return fmt.Errorf("dataExport must name an archive namespace")
+	}
+	if dataExport.Timeout != nil && dataExport.Timeout.Duration <= 0 {
+		return fmt.Errorf("dataExport timeout must be positive, got %v", dataExport.Timeout.Duration)
+	}
+	return nil
+}
+
+func dataExportTimeout(dataExport *kubevirtproviderv1.DataExport) time.Duration {
+	if dataExport.Timeout == nil {
+		return defaultDataExportTimeout
+	}
+	return dataExport.Timeout.Duration
+}
+
+// volumeClaimName returns the name of the PersistentVolumeClaim backing the volume of the VM.
+func volumeClaimName(vm *kubevirtapis.VirtualMachine, volumeName string) (string, error) {
+	if vm.Spec.Template != nil {
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			if volume.Name != volumeName {
+				continue
+			}
+			switch {
+			case volume.DataVolume != nil:
+				return volume.DataVolume.Name, nil
+			case volume.PersistentVolumeClaim != nil:
+				return volume.PersistentVolumeClaim.ClaimName, nil
+			default:
+				return "", fmt.Errorf("volume %s of VirtualMachine %s is not backed by a DataVolume or PersistentVolumeClaim", volumeName, vm.Name)
+			}
+		}
+	}
+	return "", fmt.Errorf("VirtualMachine %s has no volume %s", vm.Name, volumeName)
+}
+
+// archiveVolumeName returns the name of the DataVolume the volume of the machine is exported to.
+func archiveVolumeName(machineName, volumeName string) string {
+	return fmt.Sprintf("%s-%s", machineName, volumeName)
+}
+
+// buildArchiveVolume renders the DataVolume cloning the PersistentVolumeClaim of a volume into
+// the archive namespace, sized and accessed like the claim.
+func buildArchiveVolume(machineName, volumeName string, claim *corev1.PersistentVolumeClaim, dataExport *kubevirtproviderv1.DataExport) *cdiv1.DataVolume {
+	pvcSpec := &corev1.PersistentVolumeClaimSpec{
+		AccessModes: claim.Spec.AccessModes,
+		VolumeMode:  claim.Spec.VolumeMode,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: claim.Spec.Resources.Requests[corev1.ResourceStorage],
+			},
+		},
+		StorageClassName: claim.Spec.StorageClassName,
+	}
+	if dataExport.StorageClass != "" {
+		storageClassName := dataExport.StorageClass
+		pvcSpec.StorageClassName = &storageClassName
+	}
+
+	return &cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        archiveVolumeName(machineName, volumeName),
+			Namespace:   dataExport.ArchiveNamespace,
+			Labels:      map[string]string{exportedMachineLabel: machineName},
+			Annotations: map[string]string{exportedNamespaceAnnotation: claim.Namespace},
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Source: cdiv1.DataVolumeSource{
+				PVC: &cdiv1.DataVolumeSourcePVC{
+					Name:      claim.Name,
+					Namespace: claim.Namespace,
+				},
+			},
+			PVC: pvcSpec,
+		},
+	}
+}
+
+// dataExportDone returns true if the export succeeded or timed out, the VM being deleted then.
+func dataExportDone(condition *kubevirtproviderv1.KubevirtMachineProviderCondition) bool {
+	return condition != nil && (condition.Status == corev1.ConditionTrue || condition.Reason == kubevirtproviderv1.DataExportTimedOut)
+}
+
+// exportDataBeforeDelete stops the VM and clones the volumes of the data export into the
+// archive namespace, returning a RequeueAfterError until all of them were cloned or the export
+// timeout elapsed.
+func (r *Reconciler) exportDataBeforeDelete(vm *kubevirtapis.VirtualMachine) error {
+	dataExport := r.providerSpec.DataExport
+	if dataExport == nil {
+		return nil
+	}
+	if err := validateDataExport(dataExport); err != nil {
+		return machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+	if dataExportDone(findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.DataExported)) {
+		return nil
+	}
+
+	now := time.Now()
+	timeout := dataExportTimeout(dataExport)
+	if r.providerStatus.DataExportStartTime == nil {
+		r.log().Info("exporting volumes before deletion", "volumes", dataExport.Volumes, "archiveNamespace", dataExport.ArchiveNamespace, "timeout", timeout.String())
+		startTime := metav1.NewTime(now)
+		r.providerStatus.DataExportStartTime = &startTime
+	}
+
+	pending, err := r.exportVolumes(vm, dataExport)
+	if err != nil {
+		if _, transient := transientRequeue(err); !transient {
+			return err
+		}
+		// Keep waiting within the export timeout, e.g. for a volume to be bound
+		r.log().Info("volumes cannot be exported yet", "reason", err.Error())
+		pending = dataExport.Volumes
+	}
+	if len(pending) == 0 {
+		r.log().Info("exported volumes before deletion", "archiveNamespace", dataExport.ArchiveNamespace)
+		r.machineScope.setProviderStatus(kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.DataExported,
+			Status:  corev1.ConditionTrue,
+			Reason:  kubevirtproviderv1.DataExportSucceeded,
+			Message: fmt.Sprintf("Volumes %s exported to namespace %s", strings.Join(dataExport.Volumes, ", "), dataExport.ArchiveNamespace),
+		})
+		return nil
+	}
+
+	if r.providerStatus.DataExportStartTime.Add(timeout).Before(now) {
+		r.log().Info("volumes not exported within the data export timeout, deleting VM", "volumes", pending, "timeout", timeout.String())
+		r.machineScope.setProviderStatus(kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.DataExported,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.DataExportTimedOut,
+			Message: fmt.Sprintf("Volumes %s not exported within %v", strings.Join(pending, ", "), timeout),
+		})
+		return nil
+	}
+
+	r.machineScope.setProviderStatus(kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.DataExported,
+		Status:  corev1.ConditionFalse,
+		Reason:  kubevirtproviderv1.DataExportInProgress,
+		Message: fmt.Sprintf("Exporting volumes %s to namespace %s", strings.Join(pending, ", "), dataExport.ArchiveNamespace),
+	})
+	return &machinecontroller.RequeueAfterError{RequeueAfter: dataExportPollInterval}
+}
+
+// exportVolumes stops the VM, starts the clones of its volumes once its guest powered off and
+// returns the volumes whose clone did not succeed yet.
+func (r *Reconciler) exportVolumes(vm *kubevirtapis.VirtualMachine, dataExport *kubevirtproviderv1.DataExport) ([]string, error) {
+	if !vmHalted(vm) {
+		r.log().Info("stopping VirtualMachine to export its volumes")
+		haltVM(vm)
+		if _, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, vm.Namespace, vm); err != nil {
+			return nil, fmt.Errorf("failed to stop VirtualMachine: %w", err)
+		}
+		return dataExport.Volumes, nil
+	}
+	vmi, err := r.getMachineVMI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VirtualMachineInstance: %w", err)
+	}
+	if !guestPoweredOff(vmi) {
+		// Wait for the volumes to be released before cloning them
+		return dataExport.Volumes, nil
+	}
+
+	var pending []string
+	for _, volumeName := range dataExport.Volumes {
+		exported, err := r.exportVolume(vm, volumeName, dataExport)
+		if err != nil {
+			return nil, err
+		}
+		if !exported {
+			pending = append(pending, volumeName)
+		}
+	}
+	return pending, nil
+}
+
+// exportVolume creates the DataVolume cloning the volume into the archive namespace and returns
+// true once the clone succeeded.
+func (r *Reconciler) exportVolume(vm *kubevirtapis.VirtualMachine, volumeName string, dataExport *kubevirtproviderv1.DataExport) (bool, error) {
+	name := archiveVolumeName(r.machine.Name, volumeName)
+	archiveVolume, err := r.kubevirtClient.GetDataVolume(r.Context, dataExport.ArchiveNamespace, name, &metav1.GetOptions{})
+	if err == nil {
+		return archiveVolume.Status.Phase == cdiv1.Succeeded, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get DataVolume %s/%s: %w", dataExport.ArchiveNamespace, name, err)
+	}
+
+	claimName, err := volumeClaimName(vm, volumeName)
+	if err != nil {
+		return false, machinecontroller.InvalidMachineConfiguration("%v: cannot export volume: %v", r.machine.GetName(), err)
+	}
+	claim, err := r.kubevirtClient.GetPersistentVolumeClaim(r.Context, vm.Namespace, claimName, &metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get PersistentVolumeClaim %s: %w", claimName, err)
+	}
+	if claim.Status.Phase != corev1.ClaimBound {
+		return false, &storageNotBoundError{claim: claimName, phase: claim.Status.Phase}
+	}
+
+	archiveVolume = buildArchiveVolume(r.machine.Name, volumeName, claim, dataExport)
+	if err := tracing.Trace(r.Context, "CreateDataVolume", func() error {
+		_, err := r.kubevirtClient.CreateDataVolume(r.Context, dataExport.ArchiveNamespace, archiveVolume)
+		return err
+	}, tracing.String("dataVolume.name", name)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, fmt.Errorf("failed to create DataVolume %s/%s: %w", dataExport.ArchiveNamespace, name, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,216 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestValidateDataExport(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		dataExport  kubevirtproviderv1.DataExport
+		expectError bool
+	}{
+		{
+			testcase:   "valid",
+			dataExport: kubevirtproviderv1.DataExport{Volumes: []string{mainDiskName}, ArchiveNamespace: "archive"},
+		},
+		{
+			testcase:    "no volume",
+			dataExport:  kubevirtproviderv1.DataExport{ArchiveNamespace: "archive"},
+			expectError: true,
+		},
+		{
+			testcase:    "no archive namespace",
+			dataExport:  kubevirtproviderv1.DataExport{Volumes: []string{mainDiskName}},
+			expectError: true,
+		},
+		{
+			testcase: "negative timeout",
+			dataExport: kubevirtproviderv1.DataExport{
+				Volumes:          []string{mainDiskName},
+				ArchiveNamespace: "archive",
+				Timeout:          &metav1.Duration{Duration: -time.Minute},
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			if err := validateDataExport(&tc.dataExport); (err != nil) != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestDeleteWithDataExport(t *testing.T) {
+	vmiNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "virtualmachineinstances"}, "worker-abcde")
+	dataVolumeNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "datavolumes"}, "worker-abcde-rootdisk")
+	storageClassName := "fast"
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde-boot", Namespace: "tenant-a"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	archiveVolume := func(phase cdiv1.DataVolumePhase) *cdiv1.DataVolume {
+		return &cdiv1.DataVolume{Status: cdiv1.DataVolumeStatus{Phase: phase}}
+	}
+	expectDeletion := func(client *mockkubevirt.MockClient) {
+		client.EXPECT().DeleteVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil)
+		client.EXPECT().DeleteSecret(gomock.Any(), "tenant-a", "worker-abcde"+userDataSecretSuffix, gomock.Any()).Return(nil)
+	}
+
+	testCases := []struct {
+		testcase      string
+		startTime     *metav1.Time
+		halted        bool
+		expectClient  func(client *mockkubevirt.MockClient)
+		expectRequeue bool
+		expectReason  kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase: "stops the VM",
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+					if !vmHalted(vm) {
+						t.Errorf("Expected the VM to be halted, got run strategy %v", vm.Spec.RunStrategy)
+					}
+					return vm, nil
+				})
+			},
+			expectRequeue: true,
+			expectReason:  kubevirtproviderv1.DataExportInProgress,
+		},
+		{
+			testcase: "clones the volume once the guest powered off",
+			halted:   true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil, vmiNotFound)
+				client.EXPECT().GetDataVolume(gomock.Any(), "archive", "worker-abcde-rootdisk", gomock.Any()).Return(nil, dataVolumeNotFound)
+				client.EXPECT().GetPersistentVolumeClaim(gomock.Any(), "tenant-a", "worker-abcde-boot", gomock.Any()).Return(claim, nil)
+				client.EXPECT().CreateDataVolume(gomock.Any(), "archive", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, dataVolume *cdiv1.DataVolume) (*cdiv1.DataVolume, error) {
+					if source := dataVolume.Spec.Source.PVC; source == nil || source.Namespace != "tenant-a" || source.Name != "worker-abcde-boot" {
+						t.Errorf("Expected a clone of tenant-a/worker-abcde-boot, got %v", source)
+					}
+					if dataVolume.Labels[exportedMachineLabel] != "worker-abcde" {
+						t.Errorf("Expected the exported machine label, got %v", dataVolume.Labels)
+					}
+					if size := dataVolume.Spec.PVC.Resources.Requests[corev1.ResourceStorage]; size.String() != "20Gi" {
+						t.Errorf("Expected a 20Gi volume, got %v", size.String())
+					}
+					if storageClass := dataVolume.Spec.PVC.StorageClassName; storageClass == nil || *storageClass != "archive" {
+						t.Errorf("Expected the archive storage class, got %v", storageClass)
+					}
+					return dataVolume, nil
+				})
+			},
+			expectRequeue: true,
+			expectReason:  kubevirtproviderv1.DataExportInProgress,
+		},
+		{
+			testcase:  "deletes once the volume is exported",
+			startTime: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			halted:    true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil, vmiNotFound)
+				client.EXPECT().GetDataVolume(gomock.Any(), "archive", "worker-abcde-rootdisk", gomock.Any()).Return(archiveVolume(cdiv1.Succeeded), nil)
+				expectDeletion(client)
+			},
+			expectReason: kubevirtproviderv1.DataExportSucceeded,
+		},
+		{
+			testcase:  "deletes after the timeout",
+			startTime: &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+			halted:    true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil, vmiNotFound)
+				client.EXPECT().GetDataVolume(gomock.Any(), "archive", "worker-abcde-rootdisk", gomock.Any()).Return(archiveVolume(cdiv1.CloneInProgress), nil)
+				expectDeletion(client)
+			},
+			expectReason: kubevirtproviderv1.DataExportTimedOut,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			always := kubevirtapis.RunStrategyAlways
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec: kubevirtapis.VirtualMachineSpec{
+					RunStrategy: &always,
+					Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{
+						Spec: kubevirtapis.VirtualMachineInstanceSpec{
+							Volumes: []kubevirtapis.Volume{{
+								Name: mainDiskName,
+								VolumeSource: kubevirtapis.VolumeSource{
+									DataVolume: &kubevirtapis.DataVolumeSource{Name: "worker-abcde-boot"},
+								},
+							}},
+						},
+					},
+				},
+			}
+			if tc.halted {
+				haltVM(vm)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			client.EXPECT().GetVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(vm, nil)
+			tc.expectClient(client)
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}},
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					DataExport: &kubevirtproviderv1.DataExport{
+						Volumes:          []string{mainDiskName},
+						ArchiveNamespace: "archive",
+						StorageClass:     "archive",
+					},
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{DataExportStartTime: tc.startTime},
+			})
+
+			err := r.delete()
+			_, requeue := err.(*machinecontroller.RequeueAfterError)
+			if tc.expectRequeue != requeue {
+				t.Fatalf("Expected requeue %v, got error %v", tc.expectRequeue, err)
+			}
+			if !tc.expectRequeue && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if r.providerStatus.DataExportStartTime == nil {
+				t.Error("Expected the data export start time to be set")
+			}
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.DataExported)
+			if condition == nil || condition.Reason != tc.expectReason {
+				t.Errorf("Expected reason %v, got condition %v", tc.expectReason, condition)
+			}
+		})
+	}
+}
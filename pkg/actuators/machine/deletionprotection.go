@@ -0,0 +1,21 @@
+package machine
+
+import (
+	"fmt"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+)
+
+// deletionProtectedAnnotation set to true makes the provider spec webhook deny deletions of the
+// machine until it is removed, e.g. to keep control plane machines from being removed by an
+// accidental scale down of their MachineSet. The actuator refuses to delete the VM as well, for
+// machines deleted while the webhook is not serving.
+const deletionProtectedAnnotation = "kubevirtproviderconfig.openshift.io/deletion-protected"
+
+// CheckDeletionProtection returns an error if the machine is protected from deletion.
+func CheckDeletionProtection(machine *machinev1.Machine) error {
+	if machine.Annotations[deletionProtectedAnnotation] != "true" {
+		return nil
+	}
+	return fmt.Errorf("%v: machine is protected from deletion, remove the %s annotation to delete it", machine.GetName(), deletionProtectedAnnotation)
+}
@@ -0,0 +1,35 @@
+package machine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDeletionProtection(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "master-0",
+			Namespace:   "tenant-a",
+			Annotations: map[string]string{deletionProtectedAnnotation: "true"},
+		},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	err := NewActuator(ActuatorParams{EventRecorder: recorder}).Delete(context.Background(), machine)
+	if err == nil || !strings.Contains(err.Error(), deletionProtectedAnnotation) {
+		t.Fatalf("Expected the deletion to be refused, got %v", err)
+	}
+	if events := drainEvents(recorder); len(events) != 1 || !strings.HasPrefix(events[0], "Warning FailedDelete") {
+		t.Errorf("Expected a FailedDelete event, got %v", events)
+	}
+
+	machine.Annotations[deletionProtectedAnnotation] = "false"
+	if err := CheckDeletionProtection(machine); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+// shutdownPollInterval is the delay between the checks of a guest shutting down before the
+// deletion of its VM.
+const shutdownPollInterval = 10 * time.Second
+
+// validateGracefulShutdownTimeout returns an error if the timeout is not positive.
+func validateGracefulShutdownTimeout(timeout *metav1.Duration) error {
+	if timeout.Duration <= 0 {
+		return fmt.Errorf("gracefulShutdownTimeout must be positive, got %v", timeout.Duration)
+	}
+	return nil
+}
+
+// setTerminationGracePeriod gives the guest the timeout to shut down when KubeVirt stops the
+// VMI, before it is killed.
+func setTerminationGracePeriod(spec *kubevirtapis.VirtualMachineInstanceSpec, timeout time.Duration) {
+	seconds := int64(timeout.Seconds())
+	spec.TerminationGracePeriodSeconds = &seconds
+}
+
+// guestPoweredOff returns true if the VMI is gone or its guest stopped.
+func guestPoweredOff(vmi *kubevirtapis.VirtualMachineInstance) bool {
+	return vmi == nil || vmi.IsFinal()
+}
+
+// shutdownBeforeDelete stops the VM for its guest to shut down cleanly and returns a
+// RequeueAfterError until the guest powered off or the graceful shutdown timeout elapsed. It
+// returns true if the timeout elapsed, the VM being forcibly stopped along with its deletion.
+func (r *Reconciler) shutdownBeforeDelete(vm *kubevirtapis.VirtualMachine) (bool, error) {
+	timeout := r.providerSpec.GracefulShutdownTimeout
+	if timeout == nil {
+		return false, nil
+	}
+	if err := validateGracefulShutdownTimeout(timeout); err != nil {
+		return false, machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+
+	vmi, err := r.getMachineVMI()
+	if err != nil {
+		return false, fmt.Errorf("failed to get VirtualMachineInstance: %w", err)
+	}
+	if guestPoweredOff(vmi) {
+		return false, nil
+	}
+
+	now := time.Now()
+	if r.providerStatus.ShutdownStartTime == nil || !vmHalted(vm) {
+		r.log().Info("stopping VirtualMachine for the guest to shut down before deletion", "timeout", timeout.Duration.String())
+		haltVM(vm)
+		if _, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, vm.Namespace, vm); err != nil {
+			return false, fmt.Errorf("failed to stop VirtualMachine: %w", err)
+		}
+		if r.providerStatus.ShutdownStartTime == nil {
+			startTime := metav1.NewTime(now)
+			r.providerStatus.ShutdownStartTime = &startTime
+		}
+	}
+
+	remaining := r.providerStatus.ShutdownStartTime.Add(timeout.Duration).Sub(now)
+	if remaining <= 0 {
+		r.log().Info("guest did not shut down within the graceful shutdown timeout, forcing deletion", "timeout", timeout.Duration.String())
+		return true, nil
+	}
+	if remaining > shutdownPollInterval {
+		remaining = shutdownPollInterval
+	}
+	return false, &machinecontroller.RequeueAfterError{RequeueAfter: remaining}
+}
@@ -0,0 +1,122 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestDeleteWithGracefulShutdown(t *testing.T) {
+	vmiNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "virtualmachineinstances"}, "worker-abcde")
+	running := &kubevirtapis.VirtualMachineInstance{
+		Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running},
+	}
+
+	testCases := []struct {
+		testcase          string
+		shutdownStartTime *metav1.Time
+		halted            bool
+		expectClient      func(client *mockkubevirt.MockClient)
+		expectRequeue     bool
+	}{
+		{
+			testcase: "stops the running VM",
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(running, nil)
+				client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+					if !vmHalted(vm) {
+						t.Errorf("Expected the VM to be halted, got run strategy %v", vm.Spec.RunStrategy)
+					}
+					return vm, nil
+				})
+			},
+			expectRequeue: true,
+		},
+		{
+			testcase:          "waits for the guest",
+			shutdownStartTime: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			halted:            true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(running, nil)
+			},
+			expectRequeue: true,
+		},
+		{
+			testcase:          "deletes once the guest powered off",
+			shutdownStartTime: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			halted:            true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil, vmiNotFound)
+				client.EXPECT().DeleteVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", &metav1.DeleteOptions{}).Return(nil)
+				client.EXPECT().DeleteSecret(gomock.Any(), "tenant-a", "worker-abcde"+userDataSecretSuffix, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			testcase:          "forces the deletion after the timeout",
+			shutdownStartTime: &metav1.Time{Time: time.Now().Add(-10 * time.Minute)},
+			halted:            true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(running, nil)
+				client.EXPECT().DeleteVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).DoAndReturn(func(_ context.Context, namespace, name string, options *metav1.DeleteOptions) error {
+					if options.GracePeriodSeconds == nil || *options.GracePeriodSeconds != 0 {
+						t.Errorf("Expected a zero grace period, got %v", options.GracePeriodSeconds)
+					}
+					return nil
+				})
+				client.EXPECT().DeleteSecret(gomock.Any(), "tenant-a", "worker-abcde"+userDataSecretSuffix, gomock.Any()).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			always := kubevirtapis.RunStrategyAlways
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec:       kubevirtapis.VirtualMachineSpec{RunStrategy: &always},
+			}
+			if tc.halted {
+				haltVM(vm)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			client.EXPECT().GetVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(vm, nil)
+			tc.expectClient(client)
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}},
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					GracefulShutdownTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{ShutdownStartTime: tc.shutdownStartTime},
+			})
+
+			err := r.delete()
+			_, requeue := err.(*machinecontroller.RequeueAfterError)
+			if tc.expectRequeue != requeue {
+				t.Fatalf("Expected requeue %v, got error %v", tc.expectRequeue, err)
+			}
+			if !tc.expectRequeue && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if r.providerStatus.ShutdownStartTime == nil {
+				t.Error("Expected the shutdown start time to be set")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package machine
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+// DiagnosticsURLTemplates are the templates of the deep links into the infra cluster consoles,
+// keyed by link name. They are executed with the keys Namespace, VMName, MachineName and,
+// once the VM runs, VMIUID and NodeName. A link whose template refers to a key that is not
+// known yet is left out.
+type DiagnosticsURLTemplates map[string]*template.Template
+
+// ParseDiagnosticsURLTemplates parses URL templates given as name=template.
+func ParseDiagnosticsURLTemplates(specs []string) (DiagnosticsURLTemplates, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	templates := DiagnosticsURLTemplates{}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid diagnostics URL template %q, must be name=template", spec)
+		}
+		name := parts[0]
+		if _, ok := templates[name]; ok {
+			return nil, fmt.Errorf("duplicate diagnostics URL template %q", name)
+		}
+		urlTemplate, err := template.New(name).Option("missingkey=error").Parse(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid diagnostics URL template %q: %w", name, err)
+		}
+		templates[name] = urlTemplate
+	}
+	return templates, nil
+}
+
+// diagnosticsData returns the values known about the VM for the URL templates.
+func diagnosticsData(machineName string, vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) map[string]string {
+	data := map[string]string{
+		"Namespace":   vm.Namespace,
+		"VMName":      vm.Name,
+		"MachineName": machineName,
+	}
+	if vmi != nil {
+		if vmi.UID != "" {
+			data["VMIUID"] = string(vmi.UID)
+		}
+		if vmi.Status.NodeName != "" {
+			data["NodeName"] = vmi.Status.NodeName
+		}
+	}
+	return data
+}
+
+// render returns the URLs the templates render out of the data, leaving out the templates
+// that refer to unknown keys.
+func (t DiagnosticsURLTemplates) render(data map[string]string) map[string]string {
+	if len(t) == 0 {
+		return nil
+	}
+
+	urls := map[string]string{}
+	for name, urlTemplate := range t {
+		var url bytes.Buffer
+		if err := urlTemplate.Execute(&url, data); err != nil {
+			continue
+		}
+		urls[name] = url.String()
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	return urls
+}
+
+// setDiagnosticsURLs sets the deep links into the infra cluster consoles for the VM.
+func (s *machineScope) setDiagnosticsURLs(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) {
+	s.providerStatus.DiagnosticsURLs = s.diagnosticsURLTemplates.render(diagnosticsData(s.machine.Name, vm, vmi))
+}
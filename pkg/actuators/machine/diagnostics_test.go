@@ -0,0 +1,65 @@
+package machine
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+func TestDiagnosticsURLs(t *testing.T) {
+	templates, err := ParseDiagnosticsURLTemplates([]string{
+		"vm=https://console.infra.example.com/k8s/ns/{{.Namespace}}/kubevirt.io~v1~VirtualMachine/{{.VMName}}",
+		"launcherLogs=https://console.infra.example.com/k8s/ns/{{.Namespace}}/pods?labelSelector={{urlquery \"kubevirt.io/created-by=\" .VMIUID}}",
+		"node=https://grafana.infra.example.com/d/node?var-node={{.NodeName}}",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+	}
+	vmi := &kubevirtapis.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{UID: "1234"},
+		Status:     kubevirtapis.VirtualMachineInstanceStatus{NodeName: "infra-1"},
+	}
+
+	testCases := []struct {
+		testcase     string
+		vmi          *kubevirtapis.VirtualMachineInstance
+		expectedURLs map[string]string
+	}{
+		{
+			testcase: "VM not running",
+			expectedURLs: map[string]string{
+				"vm": "https://console.infra.example.com/k8s/ns/tenant-a/kubevirt.io~v1~VirtualMachine/worker-abcde",
+			},
+		},
+		{
+			testcase: "VM running",
+			vmi:      vmi,
+			expectedURLs: map[string]string{
+				"vm":           "https://console.infra.example.com/k8s/ns/tenant-a/kubevirt.io~v1~VirtualMachine/worker-abcde",
+				"launcherLogs": "https://console.infra.example.com/k8s/ns/tenant-a/pods?labelSelector=kubevirt.io%2Fcreated-by%3D1234",
+				"node":         "https://grafana.infra.example.com/d/node?var-node=infra-1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			urls := templates.render(diagnosticsData("worker-abcde", vm, tc.vmi))
+			if !reflect.DeepEqual(urls, tc.expectedURLs) {
+				t.Errorf("Expected URLs %v, got %v", tc.expectedURLs, urls)
+			}
+		})
+	}
+
+	for _, spec := range []string{"vm", "=https://example.com", "vm={{.VMName"} {
+		if _, err := ParseDiagnosticsURLTemplates([]string{spec}); err == nil {
+			t.Errorf("Expected error for template %q, got nil", spec)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package machine
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DNSBackend is the backend the DNS records of the machines are registered into.
+type DNSBackend string
+
+const (
+	// DNSBackendDNSEndpoint registers the records of each machine as an external-dns
+	// DNSEndpoint of the tenant cluster, owned by the machine.
+	DNSBackendDNSEndpoint DNSBackend = "DNSEndpoint"
+	// DNSBackendZoneSecret registers the records of each machine as hosts file entries under
+	// the machine name key of a Secret of the tenant cluster, e.g. mounted into the CoreDNS
+	// hosts plugin.
+	DNSBackendZoneSecret DNSBackend = "ZoneSecret"
+)
+
+// dnsEndpointKind is the kind of the external-dns records
+var dnsEndpointKind = schema.GroupVersionKind{Group: "externaldns.k8s.io", Version: "v1alpha1", Kind: "DNSEndpoint"}
+
+// DNSRegistration registers the hostnames of the machines, in the zone, with their internal
+// IPs, so that tenant nodes are resolvable by name, and removes them when the machines are
+// deleted.
+type DNSRegistration struct {
+	Backend DNSBackend
+	Zone    string
+	// SecretName is the name of the Secret in the namespace of the machines of the
+	// ZoneSecret backend.
+	SecretName string
+	// TTL is the TTL of the records of the DNSEndpoint backend, the external-dns default if zero.
+	TTL time.Duration
+}
+
+// NewDNSRegistration returns the registration of the machines into the backend.
+func NewDNSRegistration(backend DNSBackend, zone, secretName string, ttl time.Duration) (*DNSRegistration, error) {
+	zone, err := validateDomainSuffix(zone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS zone: %v", err)
+	}
+	switch backend {
+	case DNSBackendDNSEndpoint:
+	case DNSBackendZoneSecret:
+		if errs := validation.IsDNS1123Subdomain(secretName); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid zone Secret name %q: %s", secretName, strings.Join(errs, ", "))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported DNS backend %q, must be %s or %s", backend, DNSBackendDNSEndpoint, DNSBackendZoneSecret)
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("invalid DNS TTL %v", ttl)
+	}
+	return &DNSRegistration{Backend: backend, Zone: zone, SecretName: secretName, TTL: ttl}, nil
+}
+
+// dnsAddresses returns the internal IPv4 and IPv6 addresses of the machine, sorted.
+func dnsAddresses(machine *machinev1.Machine) ([]string, []string) {
+	var ipv4, ipv6 []string
+	for _, address := range machine.Status.Addresses {
+		if address.Type != corev1.NodeInternalIP {
+			continue
+		}
+		ip := net.ParseIP(address.Address)
+		switch {
+		case ip == nil:
+		case ip.To4() != nil:
+			ipv4 = append(ipv4, ip.String())
+		default:
+			ipv6 = append(ipv6, ip.String())
+		}
+	}
+	sort.Strings(ipv4)
+	sort.Strings(ipv6)
+	return ipv4, ipv6
+}
+
+// buildDNSEndpoint renders the DNSEndpoint of the A and AAAA records of the machine, owned by
+// the machine for external-dns to remove the records along with it.
+func buildDNSEndpoint(machine *machinev1.Machine, fqdn string, ttl time.Duration, ipv4, ipv6 []string) *unstructured.Unstructured {
+	var endpoints []interface{}
+	for _, record := range []struct {
+		recordType string
+		targets    []string
+	}{{"A", ipv4}, {"AAAA", ipv6}} {
+		if len(record.targets) == 0 {
+			continue
+		}
+		targets := make([]interface{}, 0, len(record.targets))
+		for _, target := range record.targets {
+			targets = append(targets, target)
+		}
+		endpoint := map[string]interface{}{
+			"dnsName":    fqdn,
+			"recordType": record.recordType,
+			"targets":    targets,
+		}
+		if ttl > 0 {
+			endpoint["recordTTL"] = int64(ttl / time.Second)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	dnsEndpoint := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"endpoints": endpoints,
+		},
+	}}
+	dnsEndpoint.SetGroupVersionKind(dnsEndpointKind)
+	dnsEndpoint.SetName(machine.Name)
+	dnsEndpoint.SetNamespace(machine.Namespace)
+	dnsEndpoint.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(machine, machinev1.SchemeGroupVersion.WithKind("Machine"))})
+	return dnsEndpoint
+}
+
+// hostsEntries renders the hosts file entries of the addresses of the machine.
+func hostsEntries(fqdn string, ipv4, ipv6 []string) string {
+	var entries strings.Builder
+	for _, ip := range append(append([]string{}, ipv4...), ipv6...) {
+		fmt.Fprintf(&entries, "%s %s\n", ip, fqdn)
+	}
+	return entries.String()
+}
+
+// reconcileDNSRegistration registers the hostname of the machine with its internal IPs once
+// the VMI reports them, and updates the records when the IPs change.
+func (r *Reconciler) reconcileDNSRegistration() error {
+	registration := r.dnsRegistration
+	if registration == nil {
+		return nil
+	}
+	fqdn, err := guestFQDN(guestHostname(r.machine.Name), registration.Zone)
+	if err != nil {
+		r.log().Info("machine name cannot be registered into DNS", "reason", err.Error())
+		return nil
+	}
+	ipv4, ipv6 := dnsAddresses(r.machine)
+	if len(ipv4) == 0 && len(ipv6) == 0 {
+		return nil
+	}
+
+	switch registration.Backend {
+	case DNSBackendDNSEndpoint:
+		err = r.registerDNSEndpoint(buildDNSEndpoint(r.machine, fqdn, registration.TTL, ipv4, ipv6))
+	case DNSBackendZoneSecret:
+		err = r.registerZoneSecret(registration.SecretName, hostsEntries(fqdn, ipv4, ipv6))
+	}
+	if err != nil {
+		return err
+	}
+	r.providerStatus.DNSName = fqdn
+	return nil
+}
+
+// registerDNSEndpoint creates the DNSEndpoint of the machine, or updates its records.
+func (r *Reconciler) registerDNSEndpoint(desired *unstructured.Unstructured) error {
+	dnsEndpoint := &unstructured.Unstructured{}
+	dnsEndpoint.SetGroupVersionKind(dnsEndpointKind)
+	err := r.client.Get(r.Context, types.NamespacedName{Namespace: desired.GetNamespace(), Name: desired.GetName()}, dnsEndpoint)
+	if apierrors.IsNotFound(err) {
+		r.log().Info("registering machine into DNS", "dnsEndpoint", desired.GetName())
+		if err := r.client.Create(r.Context, desired); err != nil {
+			return fmt.Errorf("failed to create DNSEndpoint %s: %w", desired.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get DNSEndpoint %s: %w", desired.GetName(), err)
+	}
+
+	if reflect.DeepEqual(dnsEndpoint.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+	r.log().Info("updating DNS records of machine", "dnsEndpoint", desired.GetName())
+	dnsEndpoint.Object["spec"] = desired.Object["spec"]
+	if err := r.client.Update(r.Context, dnsEndpoint); err != nil {
+		return fmt.Errorf("failed to update DNSEndpoint %s: %w", desired.GetName(), err)
+	}
+	return nil
+}
+
+// registerZoneSecret sets the hosts entries of the machine into the zone Secret, creating it
+// if needed.
+func (r *Reconciler) registerZoneSecret(secretName, entries string) error {
+	secret := &corev1.Secret{}
+	err := r.client.Get(r.Context, types.NamespacedName{Namespace: r.machine.Namespace, Name: secretName}, secret)
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: r.machine.Namespace},
+			Data:       map[string][]byte{r.machine.Name: []byte(entries)},
+		}
+		r.log().Info("registering machine into DNS", "secret", secretName)
+		if err := r.client.Create(r.Context, secret); err != nil {
+			return fmt.Errorf("failed to create zone Secret %s: %w", secretName, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get zone Secret %s: %w", secretName, err)
+	}
+
+	if string(secret.Data[r.machine.Name]) == entries {
+		return nil
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[r.machine.Name] = []byte(entries)
+	r.log().Info("updating DNS records of machine", "secret", secretName)
+	if err := r.client.Update(r.Context, secret); err != nil {
+		return fmt.Errorf("failed to update zone Secret %s: %w", secretName, err)
+	}
+	return nil
+}
+
+// deregisterDNS removes the records of the machine from the DNS backend.
+func (r *Reconciler) deregisterDNS() error {
+	registration := r.dnsRegistration
+	if registration == nil {
+		return nil
+	}
+
+	switch registration.Backend {
+	case DNSBackendDNSEndpoint:
+		dnsEndpoint := &unstructured.Unstructured{}
+		dnsEndpoint.SetGroupVersionKind(dnsEndpointKind)
+		dnsEndpoint.SetName(r.machine.Name)
+		dnsEndpoint.SetNamespace(r.machine.Namespace)
+		if err := r.client.Delete(r.Context, dnsEndpoint); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete DNSEndpoint %s: %w", r.machine.Name, err)
+		}
+	case DNSBackendZoneSecret:
+		secret := &corev1.Secret{}
+		err := r.client.Get(r.Context, types.NamespacedName{Namespace: r.machine.Namespace, Name: registration.SecretName}, secret)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get zone Secret %s: %w", registration.SecretName, err)
+		}
+		if _, ok := secret.Data[r.machine.Name]; !ok {
+			return nil
+		}
+		delete(secret.Data, r.machine.Name)
+		if err := r.client.Update(r.Context, secret); err != nil {
+			return fmt.Errorf("failed to update zone Secret %s: %w", registration.SecretName, err)
+		}
+	}
+	r.log().Info("deregistered machine from DNS")
+	return nil
+}
@@ -0,0 +1,154 @@
+package machine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestNewDNSRegistration(t *testing.T) {
+	registration, err := NewDNSRegistration(DNSBackendDNSEndpoint, "nodes.example.com.", "", time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if registration.Zone != "nodes.example.com" {
+		t.Errorf("Expected zone without trailing dot, got %q", registration.Zone)
+	}
+	if _, err := NewDNSRegistration(DNSBackendZoneSecret, "nodes.example.com", "", 0); err == nil {
+		t.Error("Expected an error for the ZoneSecret backend without Secret name")
+	}
+	if _, err := NewDNSRegistration("Route53", "nodes.example.com", "", 0); err == nil {
+		t.Error("Expected an error for an unsupported backend")
+	}
+	if _, err := NewDNSRegistration(DNSBackendDNSEndpoint, "", "", 0); err == nil {
+		t.Error("Expected an error for an empty zone")
+	}
+}
+
+func dnsRegistrationMachine() *machinev1.Machine {
+	return &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api", UID: "machine-uid"},
+		Status: machinev1.MachineStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "fd00::10"},
+				{Type: corev1.NodeInternalIP, Address: "192.168.1.10"},
+				{Type: corev1.NodeHostName, Address: "worker-abcde"},
+			},
+		},
+	}
+}
+
+func TestReconcileDNSRegistrationDNSEndpoint(t *testing.T) {
+	machine := dnsRegistrationMachine()
+	client := fake.NewFakeClientWithScheme(runtime.NewScheme())
+	providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{}
+	r := newReconciler(&machineScope{
+		Context:         context.Background(),
+		client:          client,
+		machine:         machine,
+		dnsRegistration: &DNSRegistration{Backend: DNSBackendDNSEndpoint, Zone: "nodes.example.com", TTL: time.Minute},
+		providerSpec:    &kubevirtproviderv1.KubevirtMachineProviderSpec{},
+		providerStatus:  providerStatus,
+	})
+
+	if err := r.reconcileDNSRegistration(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if providerStatus.DNSName != "worker-abcde.nodes.example.com" {
+		t.Errorf("Expected DNS name worker-abcde.nodes.example.com, got %q", providerStatus.DNSName)
+	}
+
+	dnsEndpoint := &unstructured.Unstructured{}
+	dnsEndpoint.SetGroupVersionKind(dnsEndpointKind)
+	key := types.NamespacedName{Namespace: machine.Namespace, Name: machine.Name}
+	if err := client.Get(context.Background(), key, dnsEndpoint); err != nil {
+		t.Fatalf("Expected the DNSEndpoint to be created: %v", err)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(dnsEndpoint.Object, "spec", "endpoints")
+	expected := []interface{}{
+		map[string]interface{}{"dnsName": "worker-abcde.nodes.example.com", "recordType": "A", "targets": []interface{}{"192.168.1.10"}, "recordTTL": int64(60)},
+		map[string]interface{}{"dnsName": "worker-abcde.nodes.example.com", "recordType": "AAAA", "targets": []interface{}{"fd00::10"}, "recordTTL": int64(60)},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("Expected endpoints %v, got %v", expected, endpoints)
+	}
+
+	// A changed address updates the records
+	machine.Status.Addresses = machine.Status.Addresses[1:]
+	if err := r.reconcileDNSRegistration(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.Get(context.Background(), key, dnsEndpoint); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if endpoints, _, _ := unstructured.NestedSlice(dnsEndpoint.Object, "spec", "endpoints"); len(endpoints) != 1 {
+		t.Errorf("Expected the A record only, got %v", endpoints)
+	}
+
+	if err := r.deregisterDNS(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.Get(context.Background(), key, dnsEndpoint); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the DNSEndpoint to be deleted, got %v", err)
+	}
+}
+
+func TestReconcileDNSRegistrationZoneSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	machine := dnsRegistrationMachine()
+	zoneSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-zone", Namespace: machine.Namespace},
+		Data:       map[string][]byte{"worker-fghij": []byte("192.168.1.11 worker-fghij.nodes.example.com\n")},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, zoneSecret)
+	r := newReconciler(&machineScope{
+		Context:         context.Background(),
+		client:          client,
+		machine:         machine,
+		dnsRegistration: &DNSRegistration{Backend: DNSBackendZoneSecret, Zone: "nodes.example.com", SecretName: "tenant-zone"},
+		providerSpec:    &kubevirtproviderv1.KubevirtMachineProviderSpec{},
+		providerStatus:  &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+	})
+
+	if err := r.reconcileDNSRegistration(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	key := types.NamespacedName{Namespace: machine.Namespace, Name: "tenant-zone"}
+	updated := &corev1.Secret{}
+	if err := client.Get(context.Background(), key, updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "192.168.1.10 worker-abcde.nodes.example.com\nfd00::10 worker-abcde.nodes.example.com\n"
+	if entries := string(updated.Data[machine.Name]); entries != expected {
+		t.Errorf("Expected hosts entries %q, got %q", expected, entries)
+	}
+
+	if err := r.deregisterDNS(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	updated = &corev1.Secret{}
+	if err := client.Get(context.Background(), key, updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := updated.Data[machine.Name]; ok {
+		t.Errorf("Expected the hosts entries of the machine to be removed, got %v", updated.Data)
+	}
+	if _, ok := updated.Data["worker-fghij"]; !ok {
+		t.Errorf("Expected the hosts entries of the other machines to be kept, got %v", updated.Data)
+	}
+}
@@ -0,0 +1,201 @@
+package machine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// reportedField is a field of the VM whose drift from the provider spec is only reported, no
+// update policy acting on it.
+type reportedField struct {
+	name string
+	// coldMigrated fields are changed on purpose by cold migrations, they are not compared
+	// once the VM was cold migrated.
+	coldMigrated bool
+	value        func(vm *kubevirtapis.VirtualMachine) interface{}
+}
+
+var reportedFields = []reportedField{
+	{
+		name:         "nodeSelector",
+		coldMigrated: true,
+		value:        func(vm *kubevirtapis.VirtualMachine) interface{} { return vm.Spec.Template.Spec.NodeSelector },
+	},
+	{
+		name:  "affinity",
+		value: func(vm *kubevirtapis.VirtualMachine) interface{} { return vm.Spec.Template.Spec.Affinity },
+	},
+	{
+		name:  "hostname",
+		value: func(vm *kubevirtapis.VirtualMachine) interface{} { return vm.Spec.Template.Spec.Hostname },
+	},
+	{
+		name:  "annotations",
+		value: vmiTemplateAnnotations,
+	},
+	{
+		name: "bootVolume.storage",
+		value: func(vm *kubevirtapis.VirtualMachine) interface{} {
+			if claim := bootVolumeClaim(vm); claim != nil {
+				return claim.Resources.Requests
+			}
+			return nil
+		},
+	},
+	{
+		name: "bootVolume.accessModes",
+		value: func(vm *kubevirtapis.VirtualMachine) interface{} {
+			if claim := bootVolumeClaim(vm); claim != nil {
+				return claim.AccessModes
+			}
+			return nil
+		},
+	},
+	{
+		name:         "bootVolume.storageClassName",
+		coldMigrated: true,
+		value: func(vm *kubevirtapis.VirtualMachine) interface{} {
+			if claim := bootVolumeClaim(vm); claim != nil {
+				return claim.StorageClassName
+			}
+			return nil
+		},
+	},
+}
+
+// vmiTemplateAnnotations returns the annotations of the VMI template, but the Ignition config
+// whose rendering takes the UserData.
+func vmiTemplateAnnotations(vm *kubevirtapis.VirtualMachine) interface{} {
+	annotations := map[string]string{}
+	for key, value := range vm.Spec.Template.ObjectMeta.Annotations {
+		if key != kubevirtapis.IgnitionAnnotation {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// bootVolumeClaim returns the claim of the DataVolume template of the root disk of the VM, nil
+// if the root disk is not a DataVolume template.
+func bootVolumeClaim(vm *kubevirtapis.VirtualMachine) *corev1.PersistentVolumeClaimSpec {
+	name := rootDataVolumeName(vm)
+	for i := range vm.Spec.DataVolumeTemplates {
+		if vm.Spec.DataVolumeTemplates[i].Name == name {
+			return vm.Spec.DataVolumeTemplates[i].Spec.PVC
+		}
+	}
+	return nil
+}
+
+// desiredVM renders the VM out of the current provider spec. The UserData only shapes the
+// cloud-init volume and the Ignition annotation, which are not compared.
+func (r *Reconciler) desiredVM() (*kubevirtapis.VirtualMachine, error) {
+	desired, _, err := buildVM(r.machine, r.providerSpec, nil)
+	if err != nil {
+		return nil, err
+	}
+	overcommitProfile, err := r.overcommitProfile()
+	if err != nil {
+		return nil, err
+	}
+	if overcommitProfile != nil {
+		applyOvercommitProfile(&desired.Spec.Template.Spec, overcommitProfile)
+	}
+	if r.providerSpec.LauncherOverhead != nil {
+		if err := applyLauncherOverhead(&desired.Spec.Template.Spec, r.providerSpec.LauncherOverhead); err != nil {
+			return nil, err
+		}
+	}
+	if r.metadataPropagation != nil {
+		applyMetadataPropagation(desired, r.machine, r.metadataPropagation)
+	}
+	return desired, nil
+}
+
+// diffVM compares the live VM with the desired one and returns the drifted template fields the
+// update policy acts on, along with the names of all the drifted fields.
+func (r *Reconciler) diffVM(live, desired *kubevirtapis.VirtualMachine) ([]templateField, []string, error) {
+	drifted, err := driftedTemplateFields(&live.Spec.Template.Spec, &desired.Spec.Template.Spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0, len(drifted))
+	for _, field := range drifted {
+		names = append(names, field.name)
+	}
+
+	coldMigrated := r.providerStatus.ColdMigration != nil && r.providerStatus.ColdMigration.Phase == kubevirtproviderv1.ColdMigrationSucceeded
+	for _, field := range reportedFields {
+		if field.coldMigrated && coldMigrated {
+			continue
+		}
+		liveValue, err := json.Marshal(field.value(live))
+		if err != nil {
+			return nil, nil, err
+		}
+		desiredValue, err := json.Marshal(field.value(desired))
+		if err != nil {
+			return nil, nil, err
+		}
+		if !bytes.Equal(liveValue, desiredValue) {
+			names = append(names, field.name)
+		}
+	}
+	return drifted, names, nil
+}
+
+// specDriftedCondition reports whether the VM differs from the current provider spec.
+func specDriftedCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.SpecDrifted,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reconcileSpecDrift compares the VM rendered out of the provider spec with the live VM, applies
+// the update policy to the drifted template fields and reports the fields still drifted
+// afterwards, whether or not the update policy acts on them.
+func (r *Reconciler) reconcileSpecDrift(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance, now time.Time) (*kubevirtapis.VirtualMachine, error) {
+	if vm.Spec.Template == nil {
+		return vm, nil
+	}
+	desired, err := r.desiredVM()
+	if err != nil {
+		return vm, fmt.Errorf("%v: failed to render VirtualMachine: %w", r.machine.GetName(), err)
+	}
+
+	drifted, _, err := r.diffVM(vm, desired)
+	if err != nil {
+		return vm, fmt.Errorf("failed to compare VirtualMachine with the provider spec: %w", err)
+	}
+	if vm, err = r.reconcileUpdatePolicy(vm, vmi, desired, drifted, now); err != nil {
+		return vm, err
+	}
+
+	_, names, err := r.diffVM(vm, desired)
+	if err != nil {
+		return vm, fmt.Errorf("failed to compare VirtualMachine with the provider spec: %w", err)
+	}
+	if len(names) == 0 {
+		r.providerStatus.DriftedFields = nil
+		r.machineScope.setProviderStatus(specDriftedCondition(corev1.ConditionFalse, kubevirtproviderv1.NoDrift, "VirtualMachine matches the provider spec"))
+		return vm, nil
+	}
+	if strings.Join(names, ",") != strings.Join(r.providerStatus.DriftedFields, ",") {
+		r.log().Info("VirtualMachine drifted from the provider spec", "fields", strings.Join(names, ", "))
+	}
+	r.providerStatus.DriftedFields = names
+	r.machineScope.setProviderStatus(specDriftedCondition(corev1.ConditionTrue, kubevirtproviderv1.FieldsDrifted,
+		fmt.Sprintf("VirtualMachine differs from the provider spec in %s", strings.Join(names, ", "))))
+	return vm, nil
+}
@@ -0,0 +1,101 @@
+package machine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestReconcileSpecDrift(t *testing.T) {
+	testCases := []struct {
+		testcase       string
+		liveCPU        uint32
+		mutate         func(vm *kubevirtapis.VirtualMachine)
+		coldMigration  *kubevirtproviderv1.ColdMigrationStatus
+		expectFields   []string
+		expectStatus   corev1.ConditionStatus
+		expectUpToDate kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase:       "matches the provider spec",
+			liveCPU:        4,
+			expectStatus:   corev1.ConditionFalse,
+			expectUpToDate: kubevirtproviderv1.VMMatchesSpec,
+		},
+		{
+			testcase: "node selector drifted",
+			liveCPU:  4,
+			mutate: func(vm *kubevirtapis.VirtualMachine) {
+				vm.Spec.Template.Spec.NodeSelector = map[string]string{corev1.LabelZoneFailureDomainStable: "zone-b"}
+			},
+			expectFields:   []string{"nodeSelector"},
+			expectStatus:   corev1.ConditionTrue,
+			expectUpToDate: kubevirtproviderv1.VMMatchesSpec,
+		},
+		{
+			testcase: "node selector of a cold migrated VM",
+			liveCPU:  4,
+			mutate: func(vm *kubevirtapis.VirtualMachine) {
+				vm.Spec.Template.Spec.NodeSelector = map[string]string{corev1.LabelZoneFailureDomainStable: "zone-b"}
+			},
+			coldMigration:  &kubevirtproviderv1.ColdMigrationStatus{Phase: kubevirtproviderv1.ColdMigrationSucceeded},
+			expectStatus:   corev1.ConditionFalse,
+			expectUpToDate: kubevirtproviderv1.VMMatchesSpec,
+		},
+		{
+			testcase: "cpu and boot volume drifted",
+			liveCPU:  2,
+			mutate: func(vm *kubevirtapis.VirtualMachine) {
+				storageClassName := "fast"
+				bootVolumeClaim(vm).StorageClassName = &storageClassName
+			},
+			expectFields:   []string{"cpu", "bootVolume.storageClassName"},
+			expectStatus:   corev1.ConditionTrue,
+			expectUpToDate: kubevirtproviderv1.ReplacementRequired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+			vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: tc.liveCPU}, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.mutate != nil {
+				tc.mutate(vm)
+			}
+
+			providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{ColdMigration: tc.coldMigration}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				machine:        machine,
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: 4},
+				providerStatus: providerStatus,
+			})
+			if _, err := r.reconcileSpecDrift(vm, nil, time.Now()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(providerStatus.DriftedFields, tc.expectFields) {
+				t.Errorf("Expected drifted fields %v, got %v", tc.expectFields, providerStatus.DriftedFields)
+			}
+			condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.SpecDrifted)
+			if condition == nil || condition.Status != tc.expectStatus {
+				t.Errorf("Expected status %v, got condition %v", tc.expectStatus, condition)
+			}
+			upToDate := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.VMUpToDate)
+			if upToDate == nil || upToDate.Reason != tc.expectUpToDate {
+				t.Errorf("Expected reason %v, got condition %v", tc.expectUpToDate, upToDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package machine
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// dryRunAnnotation set to "true" on a machine dry runs its operations: Create, Update and
+	// Delete render and validate its infra objects and log them instead of applying them, e.g.
+	// to check the machines of a MachineSet change before rolling it out.
+	dryRunAnnotation = "kubevirtproviderconfig.openshift.io/dry-run"
+
+	// dryRunRequeue is the delay before a dry run creation or deletion is run again.
+	dryRunRequeue = 5 * time.Minute
+)
+
+// inDryRun returns true if the operations of the machine are dry run.
+func (a *Actuator) inDryRun(machine *machinev1.Machine) bool {
+	return a.dryRun || machine.Annotations[dryRunAnnotation] == "true"
+}
+
+// dryRunApply renders and validates the VirtualMachine, its DataVolumes and the UserData secret
+// of the machine, and logs how they differ from the existing ones instead of applying them.
+func (r *Reconciler) dryRunApply() error {
+	if err := r.validateCreate(); err != nil {
+		return err
+	}
+	overcommitProfile, err := r.overcommitProfile()
+	if err != nil {
+		return machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+	userData, err := r.machineScope.getUserData()
+	if err != nil {
+		return fmt.Errorf("failed to get user data: %w", err)
+	}
+	if userData, err = r.renderUserData(userData); err != nil {
+		return err
+	}
+	vm, userDataSecret, err := renderVM(r.Context, r.machine, r.providerSpec, overcommitProfile, userData, r.kubevirtClient)
+	if err != nil {
+		return err
+	}
+
+	existingVM, err := r.getMachineVM()
+	if err != nil {
+		return err
+	}
+	if existingVM == nil {
+		spec, err := yaml.Marshal(vm.Spec)
+		if err != nil {
+			return fmt.Errorf("failed to render VirtualMachine spec: %w", err)
+		}
+		r.log().Info("dry run: would create VirtualMachine", "vm", vm.Name, "dataVolumes", dataVolumeTemplateNames(vm), "spec", string(spec))
+	} else if specDiff := vmSpecDiff(existingVM, vm); specDiff != "" {
+		r.log().Info("dry run: rendered VirtualMachine differs from the existing one", "vm", vm.Name, "diff", specDiff)
+	} else {
+		r.log().Info("dry run: rendered VirtualMachine matches the existing one", "vm", vm.Name)
+	}
+
+	if userDataSecret != nil {
+		return r.dryRunUserDataSecret(userDataSecret)
+	}
+	return nil
+}
+
+// vmSpecDiff returns the differences of the rendered VM spec from the existing one, empty if
+// they are equal.
+func vmSpecDiff(existing, rendered *kubevirtapis.VirtualMachine) string {
+	return diff.ObjectReflectDiff(existing.Spec, rendered.Spec)
+}
+
+// dryRunUserDataSecret logs whether the UserData secret would be created or updated, along
+// with the keys whose data would change but not the data itself.
+func (r *Reconciler) dryRunUserDataSecret(secret *corev1.Secret) error {
+	existing, err := r.kubevirtClient.GetSecret(r.Context, secret.Namespace, secret.Name, &metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		r.log().Info("dry run: would create UserData secret", "secret", secret.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get UserData secret %s: %w", secret.Name, err)
+	}
+	if changed := changedSecretKeys(existing.Data, secret.Data); len(changed) > 0 {
+		r.log().Info("dry run: would update UserData secret", "secret", secret.Name, "keys", changed)
+	}
+	return nil
+}
+
+// changedSecretKeys returns the sorted keys added, removed or changed from the existing secret
+// data to the rendered one.
+func changedSecretKeys(existing, rendered map[string][]byte) []string {
+	var changed []string
+	for key, value := range rendered {
+		if existingValue, ok := existing[key]; !ok || !bytes.Equal(existingValue, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range existing {
+		if _, ok := rendered[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// dryRunDelete logs the infra objects the deletion of the machine would delete instead of
+// deleting them.
+func (r *Reconciler) dryRunDelete() error {
+	vm, err := r.getMachineVM()
+	if err != nil {
+		return err
+	}
+	if vm == nil {
+		r.log().Info("dry run: no VirtualMachine to delete")
+	} else {
+		if dataExport := r.providerSpec.DataExport; dataExport != nil {
+			r.log().Info("dry run: would export volumes before deletion", "volumes", dataExport.Volumes, "archiveNamespace", dataExport.ArchiveNamespace)
+		}
+		r.log().Info("dry run: would delete VirtualMachine", "vm", vm.Name, "dataVolumes", dataVolumeTemplateNames(vm))
+	}
+	r.log().Info("dry run: would delete UserData secret", "secret", r.machine.Name+userDataSecretSuffix)
+	return nil
+}
@@ -0,0 +1,100 @@
+package machine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestChangedSecretKeys(t *testing.T) {
+	existing := map[string][]byte{"userdata": []byte("a"), "networkdata": []byte("b"), "stale": []byte("c")}
+	rendered := map[string][]byte{"userdata": []byte("a"), "networkdata": []byte("d"), "added": []byte("e")}
+
+	expected := []string{"added", "networkdata", "stale"}
+	if changed := changedSecretKeys(existing, rendered); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected changed keys %v, got %v", expected, changed)
+	}
+	if changed := changedSecretKeys(rendered, rendered); len(changed) != 0 {
+		t.Errorf("Expected no changed keys, got %v", changed)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-abcde",
+			Namespace: "tenant-a",
+			Labels:    map[string]string{machinev1.MachineClusterIDLabel: "tenant"},
+		},
+	}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"}
+	renderedVM, _, err := buildVM(machine, providerSpec, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	vmNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "virtualmachines"}, "worker-abcde")
+
+	testCases := []struct {
+		testcase   string
+		existingVM *kubevirtapis.VirtualMachine
+	}{
+		{
+			testcase: "no VirtualMachine",
+		},
+		{
+			testcase:   "existing VirtualMachine",
+			existingVM: renderedVM,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			// The mock fails on any request applying the infra objects
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.existingVM == nil {
+				client.EXPECT().GetVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil, vmNotFound).Times(2)
+			} else {
+				client.EXPECT().GetVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(tc.existingVM, nil).Times(2)
+			}
+			client.EXPECT().GetSecret(gomock.Any(), "tenant-a", gomock.Any(), gomock.Any()).Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "worker-abcde-userdata")).AnyTimes()
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        machine,
+				providerSpec:   providerSpec,
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			if err := r.dryRunApply(); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if err := r.dryRunDelete(); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDryRunInvalidProviderSpec(t *testing.T) {
+	r := newReconciler(&machineScope{
+		Context:        context.Background(),
+		machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}},
+		providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"},
+		providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+	})
+	if err := r.dryRunApply(); err == nil {
+		t.Error("Expected an error for a machine without cluster ID label")
+	}
+}
@@ -0,0 +1,51 @@
+package machine
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// resolveRootVolumeMode returns the mode of the root disk, validating it against the fields
+// shaping the DataVolume of persistent root disks and against live migration, which the
+// overlay local to the virt-launcher pod does not support.
+func resolveRootVolumeMode(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (kubevirtproviderv1.RootVolumeMode, error) {
+	switch providerSpec.RootVolumeMode {
+	case "", kubevirtproviderv1.RootVolumeModePersistent:
+		return kubevirtproviderv1.RootVolumeModePersistent, nil
+	case kubevirtproviderv1.RootVolumeModeEphemeral:
+	default:
+		return "", fmt.Errorf("unsupported rootVolumeMode %q, must be one of %q or %q", providerSpec.RootVolumeMode,
+			kubevirtproviderv1.RootVolumeModePersistent, kubevirtproviderv1.RootVolumeModeEphemeral)
+	}
+
+	if providerSpec.EvictionStrategy == kubevirtproviderv1.EvictionStrategyLiveMigrate {
+		return "", fmt.Errorf("rootVolumeMode %q cannot be live migrated, evictionStrategy must not be %q", providerSpec.RootVolumeMode, providerSpec.EvictionStrategy)
+	}
+	if providerSpec.RequestedStorage != "" {
+		return "", fmt.Errorf("requestedStorage cannot be set with rootVolumeMode %q, the root disk has the size of the source PVC", providerSpec.RootVolumeMode)
+	}
+	if providerSpec.RootVolumeAccessMode != "" {
+		return "", fmt.Errorf("rootVolumeAccessMode cannot be set with rootVolumeMode %q, the source PVC is attached read-only", providerSpec.RootVolumeMode)
+	}
+	return kubevirtproviderv1.RootVolumeModeEphemeral, nil
+}
+
+// buildEphemeralRootVolume renders the root disk volume attaching the source PVC read-only,
+// KubeVirt writing to an overlay in the virt-launcher pod instead.
+func buildEphemeralRootVolume(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) kubevirtapis.Volume {
+	return kubevirtapis.Volume{
+		Name: mainDiskName,
+		VolumeSource: kubevirtapis.VolumeSource{
+			Ephemeral: &kubevirtapis.EphemeralVolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: providerSpec.SourcePvcName,
+					ReadOnly:  true,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestResolveRootVolumeMode(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		expected     kubevirtproviderv1.RootVolumeMode
+		expectError  bool
+	}{
+		{
+			testcase: "default",
+			expected: kubevirtproviderv1.RootVolumeModePersistent,
+		},
+		{
+			testcase:     "ephemeral",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{RootVolumeMode: kubevirtproviderv1.RootVolumeModeEphemeral, EvictionStrategy: kubevirtproviderv1.EvictionStrategyNone},
+			expected:     kubevirtproviderv1.RootVolumeModeEphemeral,
+		},
+		{
+			testcase:     "unsupported",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{RootVolumeMode: "Snapshot"},
+			expectError:  true,
+		},
+		{
+			testcase:     "ephemeral live migration",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{RootVolumeMode: kubevirtproviderv1.RootVolumeModeEphemeral, EvictionStrategy: kubevirtproviderv1.EvictionStrategyLiveMigrate},
+			expectError:  true,
+		},
+		{
+			testcase:     "ephemeral requested storage",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{RootVolumeMode: kubevirtproviderv1.RootVolumeModeEphemeral, RequestedStorage: "35Gi"},
+			expectError:  true,
+		},
+		{
+			testcase:     "ephemeral access mode",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{RootVolumeMode: kubevirtproviderv1.RootVolumeModeEphemeral, RootVolumeAccessMode: corev1.ReadWriteMany},
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mode, err := resolveRootVolumeMode(&tc.providerSpec)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+			if mode != tc.expected {
+				t.Errorf("Expected root volume mode %q, got %q", tc.expected, mode)
+			}
+		})
+	}
+}
+
+func TestBuildVMEphemeralRoot(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RootVolumeMode: kubevirtproviderv1.RootVolumeModeEphemeral}
+
+	vm, _, err := buildVM(machine, providerSpec, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(vm.Spec.DataVolumeTemplates) != 0 {
+		t.Errorf("Expected no DataVolume template, got %v", dataVolumeTemplateNames(vm))
+	}
+	volume := vm.Spec.Template.Spec.Volumes[0]
+	if volume.Name != mainDiskName || volume.Ephemeral == nil || volume.Ephemeral.PersistentVolumeClaim == nil {
+		t.Fatalf("Expected an ephemeral root disk, got %v", volume)
+	}
+	if claim := volume.Ephemeral.PersistentVolumeClaim; claim.ClaimName != "rhcos" || !claim.ReadOnly {
+		t.Errorf("Expected the source PVC attached read-only, got %v", claim)
+	}
+	if name := rootDataVolumeName(vm); name != "" {
+		t.Errorf("Expected no root DataVolume, got %q", name)
+	}
+}
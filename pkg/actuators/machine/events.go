@@ -0,0 +1,155 @@
+package machine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// eventRetention is how long the events of an object are tracked at least, rate limited ones
+// included.
+const eventRetention = 10 * time.Minute
+
+// eventKey identifies the events collapsed together: the events of an object with the same
+// type, reason and message.
+type eventKey struct {
+	uid       types.UID
+	namespace string
+	name      string
+	eventType string
+	reason    string
+	message   string
+}
+
+type aggregatedEvent struct {
+	// firstSeen is when the first event of the key was seen
+	firstSeen time.Time
+	// emitted is when the last event of the key was recorded, zero if it was rate limited
+	emitted time.Time
+	// suppressed counts the events dropped since then
+	suppressed int
+}
+
+// EventAggregator is an event recorder protecting etcd from the events of machines failing
+// the same way on every reconciliation. The events of an object with the same type, reason and
+// message are recorded once per aggregation window, the next one recorded reports how many were
+// collapsed. Events beyond the rate limit are dropped and reported the same way.
+type EventAggregator struct {
+	recorder record.EventRecorder
+	window   time.Duration
+	limiter  flowcontrol.RateLimiter
+	now      func() time.Time
+
+	lock   sync.Mutex
+	events map[eventKey]*aggregatedEvent
+}
+
+// NewEventAggregator returns an EventAggregator recording to the recorder. A zero window
+// disables the aggregation, a zero qps the rate limit.
+func NewEventAggregator(recorder record.EventRecorder, window time.Duration, qps float32, burst int) *EventAggregator {
+	var limiter flowcontrol.RateLimiter
+	if qps > 0 {
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	}
+	return &EventAggregator{
+		recorder: recorder,
+		window:   window,
+		limiter:  limiter,
+		now:      time.Now,
+		events:   make(map[eventKey]*aggregatedEvent),
+	}
+}
+
+// Event implements record.EventRecorder.
+func (a *EventAggregator) Event(object runtime.Object, eventtype, reason, message string) {
+	if message, ok := a.admit(object, eventtype, reason, message); ok {
+		a.recorder.Event(object, eventtype, reason, message)
+	}
+}
+
+// Eventf implements record.EventRecorder.
+func (a *EventAggregator) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	a.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf implements record.EventRecorder.
+func (a *EventAggregator) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if message, ok := a.admit(object, eventtype, reason, fmt.Sprintf(messageFmt, args...)); ok {
+		a.recorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+	}
+}
+
+// admit returns whether the event is recorded, with its message reporting the events
+// collapsed into it.
+func (a *EventAggregator) admit(object runtime.Object, eventtype, reason, message string) (string, bool) {
+	key := eventKey{eventType: eventtype, reason: reason, message: message}
+	if accessor, err := meta.Accessor(object); err == nil {
+		key.uid, key.namespace, key.name = accessor.GetUID(), accessor.GetNamespace(), accessor.GetName()
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	now := a.now()
+	a.prune(now)
+	event, ok := a.events[key]
+	if !ok {
+		event = &aggregatedEvent{firstSeen: now}
+		a.events[key] = event
+	}
+	if a.window > 0 && !event.emitted.IsZero() && now.Sub(event.emitted) < a.window {
+		event.suppressed++
+		return "", false
+	}
+	if a.limiter != nil && !a.limiter.TryAccept() {
+		event.emitted = time.Time{}
+		event.suppressed++
+		return "", false
+	}
+
+	if event.suppressed > 0 {
+		message = fmt.Sprintf("%s (%d similar events collapsed)", message, event.suppressed)
+	}
+	event.emitted = now
+	event.suppressed = 0
+	return message, true
+}
+
+// prune forgets the events older than the aggregation window which collapsed nothing, the ones
+// of objects not seen for the retention, and the rate limited ones first seen before it.
+func (a *EventAggregator) prune(now time.Time) {
+	retention := a.retention()
+	for key, event := range a.events {
+		if event.emitted.IsZero() {
+			if now.Sub(event.firstSeen) > retention {
+				delete(a.events, key)
+			}
+			continue
+		}
+		age := now.Sub(event.emitted)
+		if age < a.window {
+			continue
+		}
+		if event.suppressed == 0 || age > retention {
+			delete(a.events, key)
+		}
+	}
+}
+
+// retention returns how long events are tracked, ten aggregation windows and at least the
+// event retention.
+func (a *EventAggregator) retention() time.Duration {
+	if retention := 10 * a.window; retention > eventRetention {
+		return retention
+	}
+	return eventRetention
+}
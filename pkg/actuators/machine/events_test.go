@@ -0,0 +1,107 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventAggregator(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "default", UID: "uid"}}
+	other := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-fghij", Namespace: "default", UID: "other-uid"}}
+
+	recorder := record.NewFakeRecorder(10)
+	aggregator := NewEventAggregator(recorder, time.Minute, 0, 0)
+	now := time.Now()
+	aggregator.now = func() time.Time { return now }
+
+	aggregator.Eventf(machine, corev1.EventTypeWarning, "FailedUpdate", "quota exceeded")
+	aggregator.Eventf(machine, corev1.EventTypeWarning, "FailedUpdate", "quota exceeded")
+	aggregator.Eventf(machine, corev1.EventTypeWarning, "FailedUpdate", "quota exceeded again")
+	aggregator.Eventf(other, corev1.EventTypeWarning, "FailedUpdate", "quota exceeded")
+	aggregator.Eventf(machine, corev1.EventTypeNormal, "Update", "Updated Machine")
+
+	expected := []string{
+		"Warning FailedUpdate quota exceeded",
+		"Warning FailedUpdate quota exceeded again",
+		"Warning FailedUpdate quota exceeded",
+		"Normal Update Updated Machine",
+	}
+	if events := drainEvents(recorder); !equalStrings(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+
+	now = now.Add(time.Minute)
+	aggregator.Eventf(machine, corev1.EventTypeWarning, "FailedUpdate", "quota exceeded")
+	expected = []string{"Warning FailedUpdate quota exceeded (1 similar events collapsed)"}
+	if events := drainEvents(recorder); !equalStrings(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestEventAggregatorRateLimit(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	aggregator := NewEventAggregator(recorder, 0, 0.001, 2)
+
+	for _, name := range []string{"worker-a", "worker-b", "worker-c"} {
+		machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		aggregator.Eventf(machine, corev1.EventTypeWarning, "FailedCreate", "quota exceeded")
+	}
+	if events := drainEvents(recorder); len(events) != 2 {
+		t.Errorf("Expected 2 events within the burst, got %v", events)
+	}
+	if len(aggregator.events) != 1 {
+		t.Errorf("Expected the rate limited event to be tracked, got %d tracked events", len(aggregator.events))
+	}
+}
+
+func TestEventAggregatorPruneRateLimited(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	aggregator := NewEventAggregator(recorder, time.Minute, 0.001, 1)
+	now := time.Now()
+	aggregator.now = func() time.Time { return now }
+
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-a", Namespace: "default"}}
+	other := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-b", Namespace: "default"}}
+	aggregator.Eventf(machine, corev1.EventTypeWarning, "FailedCreate", "quota exceeded")
+	aggregator.Eventf(other, corev1.EventTypeWarning, "FailedCreate", "quota exceeded")
+	if len(aggregator.events) != 2 {
+		t.Fatalf("Expected 2 tracked events, got %d", len(aggregator.events))
+	}
+
+	// The rate limited event is forgotten once first seen before the retention, the recorded
+	// one which collapsed nothing once older than the aggregation window
+	now = now.Add(eventRetention + time.Second)
+	aggregator.prune(now)
+	if len(aggregator.events) != 0 {
+		t.Errorf("Expected the events to be pruned, got %d tracked events", len(aggregator.events))
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
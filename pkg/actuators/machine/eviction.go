@@ -0,0 +1,78 @@
+package machine
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// resolveEvictionStrategy returns the eviction strategy of the VM template, nil leaves it
+// to the infra cluster.
+func resolveEvictionStrategy(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (*kubevirtapis.EvictionStrategy, error) {
+	switch providerSpec.EvictionStrategy {
+	case "", kubevirtproviderv1.EvictionStrategyNone:
+		return nil, nil
+	case kubevirtproviderv1.EvictionStrategyLiveMigrate:
+		strategy := kubevirtapis.EvictionStrategyLiveMigrate
+		return &strategy, nil
+	default:
+		return nil, fmt.Errorf("unsupported evictionStrategy %q, must be one of %q or %q", providerSpec.EvictionStrategy,
+			kubevirtproviderv1.EvictionStrategyLiveMigrate, kubevirtproviderv1.EvictionStrategyNone)
+	}
+}
+
+// resolveRootVolumeAccessMode returns the access mode of the root disk volume, validating it
+// against the eviction strategy.
+func resolveRootVolumeAccessMode(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (corev1.PersistentVolumeAccessMode, error) {
+	liveMigrate := providerSpec.EvictionStrategy == kubevirtproviderv1.EvictionStrategyLiveMigrate
+
+	switch providerSpec.RootVolumeAccessMode {
+	case "":
+		if liveMigrate {
+			return corev1.ReadWriteMany, nil
+		}
+		return corev1.ReadWriteOnce, nil
+	case corev1.ReadWriteMany:
+		return corev1.ReadWriteMany, nil
+	case corev1.ReadWriteOnce:
+		if liveMigrate {
+			return "", fmt.Errorf("evictionStrategy %q requires rootVolumeAccessMode %q", kubevirtproviderv1.EvictionStrategyLiveMigrate, corev1.ReadWriteMany)
+		}
+		return corev1.ReadWriteOnce, nil
+	default:
+		return "", fmt.Errorf("unsupported rootVolumeAccessMode %q, must be one of %q or %q", providerSpec.RootVolumeAccessMode, corev1.ReadWriteOnce, corev1.ReadWriteMany)
+	}
+}
+
+// liveMigratableCondition mirrors the LiveMigratable condition KubeVirt reports on the VMI.
+func liveMigratableCondition(vmi *kubevirtapis.VirtualMachineInstance) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Type != kubevirtapis.VirtualMachineInstanceIsMigratable {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			return kubevirtproviderv1.KubevirtMachineProviderCondition{
+				Type:    kubevirtproviderv1.LiveMigratable,
+				Status:  corev1.ConditionTrue,
+				Reason:  kubevirtproviderv1.VMIMigratable,
+				Message: "VirtualMachineInstance is live migratable",
+			}
+		}
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.LiveMigratable,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.VMINotMigratable,
+			Message: fmt.Sprintf("VirtualMachineInstance is not live migratable and is shut down on drains of its infra node: %s: %s", condition.Reason, condition.Message),
+		}
+	}
+
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.LiveMigratable,
+		Status:  corev1.ConditionUnknown,
+		Reason:  kubevirtproviderv1.VMINotMigratable,
+		Message: "VirtualMachineInstance does not report whether it is live migratable",
+	}
+}
@@ -0,0 +1,111 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestBuildVMEvictionStrategy(t *testing.T) {
+	testCases := []struct {
+		testcase                 string
+		evictionStrategy         kubevirtproviderv1.EvictionStrategy
+		rootVolumeAccessMode     corev1.PersistentVolumeAccessMode
+		expectedEvictionStrategy *kubevirtapis.EvictionStrategy
+		expectedAccessMode       corev1.PersistentVolumeAccessMode
+		expectError              bool
+	}{
+		{
+			testcase:           "default",
+			expectedAccessMode: corev1.ReadWriteOnce,
+		},
+		{
+			testcase:           "none",
+			evictionStrategy:   kubevirtproviderv1.EvictionStrategyNone,
+			expectedAccessMode: corev1.ReadWriteOnce,
+		},
+		{
+			testcase:                 "live migrate",
+			evictionStrategy:         kubevirtproviderv1.EvictionStrategyLiveMigrate,
+			expectedEvictionStrategy: func() *kubevirtapis.EvictionStrategy { s := kubevirtapis.EvictionStrategyLiveMigrate; return &s }(),
+			expectedAccessMode:       corev1.ReadWriteMany,
+		},
+		{
+			testcase:             "live migrate with a volume not shared",
+			evictionStrategy:     kubevirtproviderv1.EvictionStrategyLiveMigrate,
+			rootVolumeAccessMode: corev1.ReadWriteOnce,
+			expectError:          true,
+		},
+		{
+			testcase:         "unsupported",
+			evictionStrategy: "External",
+			expectError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kubevirt-test",
+					Namespace: "kubevirt-test",
+				},
+			}
+			vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:        "rhcos",
+				EvictionStrategy:     tc.evictionStrategy,
+				RootVolumeAccessMode: tc.rootVolumeAccessMode,
+			}, []byte("#cloud-config\n"))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			evictionStrategy := vm.Spec.Template.Spec.EvictionStrategy
+			if (evictionStrategy == nil) != (tc.expectedEvictionStrategy == nil) ||
+				(evictionStrategy != nil && *evictionStrategy != *tc.expectedEvictionStrategy) {
+				t.Errorf("Expected eviction strategy %v, got %v", tc.expectedEvictionStrategy, evictionStrategy)
+			}
+			accessModes := vm.Spec.DataVolumeTemplates[0].Spec.PVC.AccessModes
+			if len(accessModes) != 1 || accessModes[0] != tc.expectedAccessMode {
+				t.Errorf("Expected root volume access modes [%s], got %v", tc.expectedAccessMode, accessModes)
+			}
+		})
+	}
+}
+
+func TestLiveMigratableCondition(t *testing.T) {
+	vmi := &kubevirtapis.VirtualMachineInstance{}
+	if condition := liveMigratableCondition(vmi); condition.Status != corev1.ConditionUnknown {
+		t.Errorf("Expected status %s without a VMI condition, got %s", corev1.ConditionUnknown, condition.Status)
+	}
+
+	vmi.Status.Conditions = []kubevirtapis.VirtualMachineInstanceCondition{
+		{
+			Type:    kubevirtapis.VirtualMachineInstanceIsMigratable,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtapis.VirtualMachineInstanceReasonDisksNotMigratable,
+			Message: "cannot migrate VMI with non-shared PVCs",
+		},
+	}
+	condition := liveMigratableCondition(vmi)
+	if condition.Status != corev1.ConditionFalse || condition.Reason != kubevirtproviderv1.VMINotMigratable {
+		t.Errorf("Expected not migratable condition, got %+v", condition)
+	}
+
+	vmi.Status.Conditions[0].Status = corev1.ConditionTrue
+	condition = liveMigratableCondition(vmi)
+	if condition.Status != corev1.ConditionTrue || condition.Reason != kubevirtproviderv1.VMIMigratable {
+		t.Errorf("Expected migratable condition, got %+v", condition)
+	}
+}
@@ -0,0 +1,285 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// provisioningFailuresAnnotation counts the consecutive provisioning failures of the
+	// machines of a MachineSet. Operators reset the failure budget by removing it.
+	provisioningFailuresAnnotation = "kubevirtproviderconfig.openshift.io/provisioning-failures"
+	// lastProvisioningFailureAnnotation records the time of the last provisioning failure
+	// of the machines of a MachineSet.
+	lastProvisioningFailureAnnotation = "kubevirtproviderconfig.openshift.io/last-provisioning-failure"
+	// bootstrapFailureRecordedAnnotation marks a machine whose bootstrap failure was counted.
+	bootstrapFailureRecordedAnnotation = "kubevirtproviderconfig.openshift.io/bootstrap-failure-recorded"
+)
+
+var (
+	provisioningFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kubevirt_machineset_provisioning_failures_total",
+		Help: "Number of machines of a MachineSet which failed to be created or to bootstrap.",
+	}, []string{"namespace", "machineset"})
+	provisioningCircuitOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kubevirt_machineset_provisioning_circuit_open",
+		Help: "Whether the creation of machines of a MachineSet is stopped after too many consecutive provisioning failures.",
+	}, []string{"namespace", "machineset"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(provisioningFailuresTotal, provisioningCircuitOpen)
+}
+
+// FailureBudget stops the creation of VMs for a MachineSet whose machines keep failing to be
+// created or to bootstrap. Creations are delayed with an exponential backoff after each
+// consecutive failure, after the threshold the circuit opens and no VM is created until an
+// operator removes the provisioning-failures annotation of the MachineSet.
+type FailureBudget struct {
+	client           runtimeclient.Client
+	threshold        int
+	backoff          time.Duration
+	maxBackoff       time.Duration
+	bootstrapTimeout time.Duration
+}
+
+// NewFailureBudget returns a FailureBudget opening the circuit of a MachineSet after threshold
+// consecutive failures. Machines whose node did not join within the bootstrap timeout count
+// as failures.
+func NewFailureBudget(client runtimeclient.Client, threshold int, backoff, maxBackoff, bootstrapTimeout time.Duration) *FailureBudget {
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+	return &FailureBudget{
+		client:           client,
+		threshold:        threshold,
+		backoff:          backoff,
+		maxBackoff:       maxBackoff,
+		bootstrapTimeout: bootstrapTimeout,
+	}
+}
+
+// machineSetKey returns the MachineSet the machine belongs to, if any.
+func machineSetKey(machine *machinev1.Machine) (types.NamespacedName, bool) {
+	for _, ownerRef := range machine.OwnerReferences {
+		if ownerRef.Kind == "MachineSet" {
+			return types.NamespacedName{Namespace: machine.Namespace, Name: ownerRef.Name}, true
+		}
+	}
+	if name := machine.Labels[machineSetLabel]; name != "" {
+		return types.NamespacedName{Namespace: machine.Namespace, Name: name}, true
+	}
+	return types.NamespacedName{}, false
+}
+
+// provisioningFailures returns the consecutive provisioning failures recorded on the MachineSet
+// and the time of the last one.
+func provisioningFailures(machineSet *machinev1.MachineSet) (int, time.Time) {
+	failures, err := strconv.Atoi(machineSet.Annotations[provisioningFailuresAnnotation])
+	if err != nil || failures < 0 {
+		return 0, time.Time{}
+	}
+	last, err := time.Parse(time.RFC3339, machineSet.Annotations[lastProvisioningFailureAnnotation])
+	if err != nil {
+		return failures, time.Time{}
+	}
+	return failures, last
+}
+
+// failureBackoff returns the delay before the next creation after the consecutive failures.
+func failureBackoff(failures int, backoff, maxBackoff time.Duration) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := backoff
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// circuitOpenCondition reports the creation of the machine being stopped by the failure budget.
+func circuitOpenCondition(machineSet string, failures int) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:   kubevirtproviderv1.CircuitOpen,
+		Status: corev1.ConditionTrue,
+		Reason: kubevirtproviderv1.FailureBudgetExhausted,
+		Message: fmt.Sprintf("MachineSet %s had %d consecutive provisioning failures, remove its %s annotation to resume creating VirtualMachines",
+			machineSet, failures, provisioningFailuresAnnotation),
+	}
+}
+
+// Check returns whether a VM may be created for the machine now, along with the delay to wait
+// for otherwise. The condition is set if the circuit of the MachineSet is open.
+func (b *FailureBudget) Check(ctx context.Context, machine *machinev1.Machine, now time.Time) (time.Duration, *kubevirtproviderv1.KubevirtMachineProviderCondition, error) {
+	key, ok := machineSetKey(machine)
+	if !ok || b.threshold <= 0 {
+		return 0, nil, nil
+	}
+
+	machineSet := &machinev1.MachineSet{}
+	if err := b.client.Get(ctx, key, machineSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+
+	failures, last := provisioningFailures(machineSet)
+	if failures >= b.threshold {
+		provisioningCircuitOpen.WithLabelValues(key.Namespace, key.Name).Set(1)
+		condition := circuitOpenCondition(key.Name, failures)
+		return b.maxBackoff, &condition, nil
+	}
+	provisioningCircuitOpen.WithLabelValues(key.Namespace, key.Name).Set(0)
+
+	if delay := last.Add(failureBackoff(failures, b.backoff, b.maxBackoff)).Sub(now); delay > 0 {
+		return delay, nil, nil
+	}
+	return 0, nil, nil
+}
+
+// RecordFailure counts a provisioning failure of the machine against its MachineSet.
+func (b *FailureBudget) RecordFailure(ctx context.Context, machine *machinev1.Machine, now time.Time) error {
+	return b.updateMachineSet(ctx, machine, func(machineSet *machinev1.MachineSet) bool {
+		failures, _ := provisioningFailures(machineSet)
+		failures++
+		if machineSet.Annotations == nil {
+			machineSet.Annotations = map[string]string{}
+		}
+		machineSet.Annotations[provisioningFailuresAnnotation] = strconv.Itoa(failures)
+		machineSet.Annotations[lastProvisioningFailureAnnotation] = now.UTC().Format(time.RFC3339)
+		provisioningFailuresTotal.WithLabelValues(machineSet.Namespace, machineSet.Name).Inc()
+		if failures >= b.threshold {
+			klog.Warningf("MachineSet %s/%s had %d consecutive provisioning failures, stopping the creation of VirtualMachines", machineSet.Namespace, machineSet.Name, failures)
+		}
+		return true
+	})
+}
+
+// RecordSuccess resets the consecutive provisioning failures of the MachineSet of the machine,
+// unless its circuit is open already.
+func (b *FailureBudget) RecordSuccess(ctx context.Context, machine *machinev1.Machine) error {
+	return b.updateMachineSet(ctx, machine, func(machineSet *machinev1.MachineSet) bool {
+		failures, _ := provisioningFailures(machineSet)
+		if failures == 0 || failures >= b.threshold {
+			return false
+		}
+		delete(machineSet.Annotations, provisioningFailuresAnnotation)
+		delete(machineSet.Annotations, lastProvisioningFailureAnnotation)
+		return true
+	})
+}
+
+// BootstrapFailed returns true if the node of the machine did not join within the bootstrap
+// timeout and the failure was not counted yet.
+func (b *FailureBudget) BootstrapFailed(machine *machinev1.Machine, now time.Time) bool {
+	if b.bootstrapTimeout <= 0 || machine.Status.NodeRef != nil {
+		return false
+	}
+	if _, recorded := machine.Annotations[bootstrapFailureRecordedAnnotation]; recorded {
+		return false
+	}
+	return machine.CreationTimestamp.Add(b.bootstrapTimeout).Before(now)
+}
+
+func (b *FailureBudget) updateMachineSet(ctx context.Context, machine *machinev1.Machine, update func(machineSet *machinev1.MachineSet) bool) error {
+	key, ok := machineSetKey(machine)
+	if !ok || b.threshold <= 0 {
+		return nil
+	}
+
+	machineSet := &machinev1.MachineSet{}
+	if err := b.client.Get(ctx, key, machineSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	patch := runtimeclient.MergeFrom(machineSet.DeepCopy())
+	if !update(machineSet) {
+		return nil
+	}
+	return b.client.Patch(ctx, machineSet, patch)
+}
+
+// circuitClosedCondition reports the creation of the machine being resumed.
+func circuitClosedCondition(machineSet string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.CircuitOpen,
+		Status:  corev1.ConditionFalse,
+		Reason:  kubevirtproviderv1.FailureBudgetAvailable,
+		Message: fmt.Sprintf("MachineSet %s is within its provisioning failure budget", machineSet),
+	}
+}
+
+// checkFailureBudget returns an error to requeue the creation of the machine while its
+// MachineSet backs off or its circuit is open.
+func (r *Reconciler) checkFailureBudget() error {
+	delay, condition, err := r.failureBudget.Check(r.Context, r.machine, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to check provisioning failure budget: %w", err)
+	}
+
+	if condition != nil {
+		klog.Warningf("%s: %s", r.machine.Name, condition.Message)
+		r.machineScope.setProviderStatus(*condition)
+		return &machinecontroller.RequeueAfterError{RequeueAfter: delay}
+	}
+	if findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.CircuitOpen) != nil {
+		key, _ := machineSetKey(r.machine)
+		r.machineScope.setProviderStatus(circuitClosedCondition(key.Name))
+	}
+	if delay > 0 {
+		klog.Infof("%s: backing off creation of VirtualMachine for %v after provisioning failures", r.machine.Name, delay)
+		return &machinecontroller.RequeueAfterError{RequeueAfter: delay}
+	}
+	return nil
+}
+
+// recordProvisioningFailure counts a failure to create the VM or to bootstrap its node
+// against the MachineSet of the machine.
+func (r *Reconciler) recordProvisioningFailure() {
+	if err := r.failureBudget.RecordFailure(r.Context, r.machine, time.Now()); err != nil {
+		klog.Errorf("%s: failed to record provisioning failure: %v", r.machine.Name, err)
+	}
+}
+
+// updateFailureBudget resets the failure budget of the MachineSet once the node of the machine
+// joined, or counts a bootstrap failure if it did not join within the bootstrap timeout.
+func (r *Reconciler) updateFailureBudget() {
+	if r.machine.Status.NodeRef != nil {
+		if err := r.failureBudget.RecordSuccess(r.Context, r.machine); err != nil {
+			klog.Errorf("%s: failed to reset provisioning failures: %v", r.machine.Name, err)
+		}
+		return
+	}
+
+	if !r.failureBudget.BootstrapFailed(r.machine, time.Now()) {
+		return
+	}
+	klog.Warningf("%s: node did not join within %v, counting a bootstrap failure", r.machine.Name, r.failureBudget.bootstrapTimeout)
+	r.recordProvisioningFailure()
+	if r.machine.Annotations == nil {
+		r.machine.Annotations = map[string]string{}
+	}
+	r.machine.Annotations[bootstrapFailureRecordedAnnotation] = "true"
+}
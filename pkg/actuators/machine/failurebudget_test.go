@@ -0,0 +1,132 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestFailureBackoff(t *testing.T) {
+	testCases := []struct {
+		failures      int
+		expectedDelay time.Duration
+	}{
+		{failures: 0, expectedDelay: 0},
+		{failures: 1, expectedDelay: 30 * time.Second},
+		{failures: 2, expectedDelay: time.Minute},
+		{failures: 4, expectedDelay: 4 * time.Minute},
+		{failures: 10, expectedDelay: 10 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		if delay := failureBackoff(tc.failures, 30*time.Second, 10*time.Minute); delay != tc.expectedDelay {
+			t.Errorf("Expected delay %v after %d failures, got %v", tc.expectedDelay, tc.failures, delay)
+		}
+	}
+}
+
+func TestFailureBudget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := machinev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	machineSet := &machinev1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker",
+			Namespace: "default",
+		},
+	}
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-abcde",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "MachineSet", Name: "worker"},
+			},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, machineSet)
+	budget := NewFailureBudget(client, 2, 30*time.Second, 10*time.Minute, 20*time.Minute)
+	ctx := context.Background()
+	// Failure times are recorded with a precision of seconds
+	now := time.Now().Truncate(time.Second)
+
+	delay, condition, err := budget.Check(ctx, machine, now)
+	if err != nil || delay != 0 || condition != nil {
+		t.Fatalf("Expected creation to be allowed, got delay %v, condition %v, error %v", delay, condition, err)
+	}
+
+	if err := budget.RecordFailure(ctx, machine, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	delay, condition, err = budget.Check(ctx, machine, now.Add(10*time.Second))
+	if err != nil || delay != 20*time.Second || condition != nil {
+		t.Errorf("Expected creation to back off for 20s, got delay %v, condition %v, error %v", delay, condition, err)
+	}
+
+	if err := budget.RecordFailure(ctx, machine, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, condition, err = budget.Check(ctx, machine, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if condition == nil || condition.Type != kubevirtproviderv1.CircuitOpen {
+		t.Fatalf("Expected the circuit to be open, got condition %v", condition)
+	}
+
+	// Machines joining late do not close an open circuit
+	if err := budget.RecordSuccess(ctx, machine); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, condition, _ = budget.Check(ctx, machine, now.Add(time.Hour)); condition == nil {
+		t.Errorf("Expected the circuit to stay open")
+	}
+
+	// Operators reset the budget by removing the annotation
+	updated := &machinev1.MachineSet{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "worker"}, updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	delete(updated.Annotations, provisioningFailuresAnnotation)
+	if err := client.Update(ctx, updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	delay, condition, err = budget.Check(ctx, machine, now.Add(time.Hour))
+	if err != nil || delay != 0 || condition != nil {
+		t.Errorf("Expected creation to be allowed after reset, got delay %v, condition %v, error %v", delay, condition, err)
+	}
+}
+
+func TestBootstrapFailed(t *testing.T) {
+	budget := NewFailureBudget(nil, 5, 30*time.Second, 10*time.Minute, 20*time.Minute)
+	now := time.Now()
+
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+		},
+	}
+	if !budget.BootstrapFailed(machine, now) {
+		t.Errorf("Expected bootstrap failure of a machine without node after the bootstrap timeout")
+	}
+
+	machine.Annotations = map[string]string{bootstrapFailureRecordedAnnotation: "true"}
+	if budget.BootstrapFailed(machine, now) {
+		t.Errorf("Expected a recorded bootstrap failure not to be counted again")
+	}
+
+	machine.Annotations = nil
+	machine.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+	if budget.BootstrapFailed(machine, now) {
+		t.Errorf("Expected no bootstrap failure within the bootstrap timeout")
+	}
+}
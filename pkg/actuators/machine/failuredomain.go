@@ -0,0 +1,101 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// machineZoneLabel is the label machine-api reports the zone of a machine with.
+	machineZoneLabel = "machine.openshift.io/zone"
+	// machineSetLabel is the label identifying the MachineSet a machine belongs to.
+	machineSetLabel = "machine.openshift.io/cluster-api-machineset"
+	// failureDomainSpreadWeight is the weight of the anti-affinity spreading a MachineSet.
+	failureDomainSpreadWeight = 100
+)
+
+// failureDomainTopologyKey returns the infra node label identifying the failure domains.
+func failureDomainTopologyKey(failureDomain *kubevirtproviderv1.FailureDomain) string {
+	if failureDomain.TopologyKey == "" {
+		return corev1.LabelZoneFailureDomainStable
+	}
+	return failureDomain.TopologyKey
+}
+
+// validateFailureDomain validates the topology key and zone of the failure domain.
+func validateFailureDomain(failureDomain *kubevirtproviderv1.FailureDomain) error {
+	if failureDomain.TopologyKey != "" {
+		if errs := validation.IsQualifiedName(failureDomain.TopologyKey); len(errs) > 0 {
+			return fmt.Errorf("invalid failureDomain topologyKey %q: %s", failureDomain.TopologyKey, strings.Join(errs, ", "))
+		}
+	}
+	if failureDomain.Zone != "" {
+		if errs := validation.IsValidLabelValue(failureDomain.Zone); len(errs) > 0 {
+			return fmt.Errorf("invalid failureDomain zone %q: %s", failureDomain.Zone, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// applyFailureDomain places the VM template of the machine in the failure domain. A zone is
+// enforced with a node selector, spreading a MachineSet is a preference of its virt-launcher
+// pods to not share a failure domain, which needs the MachineSet label on the machine.
+func applyFailureDomain(template *kubevirtapis.VirtualMachineInstanceTemplateSpec, machine *machinev1.Machine, failureDomain *kubevirtproviderv1.FailureDomain) {
+	topologyKey := failureDomainTopologyKey(failureDomain)
+
+	if failureDomain.Zone != "" {
+		if template.Spec.NodeSelector == nil {
+			template.Spec.NodeSelector = map[string]string{}
+		}
+		template.Spec.NodeSelector[topologyKey] = failureDomain.Zone
+	}
+
+	machineSet := machine.Labels[machineSetLabel]
+	if !failureDomain.Spread || machineSet == "" {
+		return
+	}
+
+	if template.ObjectMeta.Labels == nil {
+		template.ObjectMeta.Labels = map[string]string{}
+	}
+	template.ObjectMeta.Labels[machineSetLabel] = machineSet
+
+	if template.Spec.Affinity == nil {
+		template.Spec.Affinity = &corev1.Affinity{}
+	}
+	if template.Spec.Affinity.PodAntiAffinity == nil {
+		template.Spec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	antiAffinity := template.Spec.Affinity.PodAntiAffinity
+	antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{
+			Weight: failureDomainSpreadWeight,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						machineSetLabel: machineSet,
+					},
+				},
+				TopologyKey: topologyKey,
+			},
+		})
+}
+
+// setMachineZoneLabel reports the zone of the failure domain on the machine.
+func setMachineZoneLabel(machine *machinev1.Machine, failureDomain *kubevirtproviderv1.FailureDomain) {
+	if failureDomain == nil || failureDomain.Zone == "" {
+		return
+	}
+	if machine.Labels == nil {
+		machine.Labels = map[string]string{}
+	}
+	machine.Labels[machineZoneLabel] = failureDomain.Zone
+}
@@ -0,0 +1,116 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestBuildVMFailureDomain(t *testing.T) {
+	testCases := []struct {
+		testcase             string
+		failureDomain        *kubevirtproviderv1.FailureDomain
+		expectedNodeSelector map[string]string
+		expectedTopologyKey  string
+		expectError          bool
+	}{
+		{
+			testcase:             "zone",
+			failureDomain:        &kubevirtproviderv1.FailureDomain{Zone: "zone-a"},
+			expectedNodeSelector: map[string]string{corev1.LabelZoneFailureDomainStable: "zone-a"},
+		},
+		{
+			testcase:            "spread",
+			failureDomain:       &kubevirtproviderv1.FailureDomain{Spread: true},
+			expectedTopologyKey: corev1.LabelZoneFailureDomainStable,
+		},
+		{
+			testcase:             "zone and spread by custom topology key",
+			failureDomain:        &kubevirtproviderv1.FailureDomain{Zone: "rack-1", TopologyKey: "example.com/rack", Spread: true},
+			expectedNodeSelector: map[string]string{"example.com/rack": "rack-1"},
+			expectedTopologyKey:  "example.com/rack",
+		},
+		{
+			testcase:      "invalid zone",
+			failureDomain: &kubevirtproviderv1.FailureDomain{Zone: "zone a"},
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kubevirt-test",
+					Namespace: "kubevirt-test",
+					Labels: map[string]string{
+						machineSetLabel: "kubevirt-test-worker",
+					},
+				},
+			}
+			vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName: "rhcos",
+				FailureDomain: tc.failureDomain,
+			}, []byte("#cloud-config\n"))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			template := vm.Spec.Template
+			for key, value := range tc.expectedNodeSelector {
+				if template.Spec.NodeSelector[key] != value {
+					t.Errorf("Expected node selector %s=%s, got %v", key, value, template.Spec.NodeSelector)
+				}
+			}
+
+			if tc.expectedTopologyKey == "" {
+				if template.Spec.Affinity != nil {
+					t.Errorf("Expected no affinity, got %+v", template.Spec.Affinity)
+				}
+				return
+			}
+			if template.ObjectMeta.Labels[machineSetLabel] != "kubevirt-test-worker" {
+				t.Errorf("Expected MachineSet label on the VM template, got %v", template.ObjectMeta.Labels)
+			}
+			if _, ok := vm.Labels[machineSetLabel]; ok {
+				t.Errorf("Expected MachineSet label only on the VM template, got %v", vm.Labels)
+			}
+			if template.Spec.Affinity == nil || template.Spec.Affinity.PodAntiAffinity == nil {
+				t.Fatalf("Expected pod anti-affinity, got %+v", template.Spec.Affinity)
+			}
+			terms := template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+			if len(terms) != 1 {
+				t.Fatalf("Expected 1 preferred anti-affinity term, got %d", len(terms))
+			}
+			if terms[0].PodAffinityTerm.TopologyKey != tc.expectedTopologyKey {
+				t.Errorf("Expected topology key %q, got %q", tc.expectedTopologyKey, terms[0].PodAffinityTerm.TopologyKey)
+			}
+			if terms[0].PodAffinityTerm.LabelSelector.MatchLabels[machineSetLabel] != "kubevirt-test-worker" {
+				t.Errorf("Expected anti-affinity to select the MachineSet, got %+v", terms[0].PodAffinityTerm.LabelSelector)
+			}
+		})
+	}
+}
+
+func TestSetMachineZoneLabel(t *testing.T) {
+	machine := &machinev1.Machine{}
+	setMachineZoneLabel(machine, &kubevirtproviderv1.FailureDomain{Spread: true})
+	if _, ok := machine.Labels[machineZoneLabel]; ok {
+		t.Errorf("Expected no zone label without a zone, got %v", machine.Labels)
+	}
+
+	setMachineZoneLabel(machine, &kubevirtproviderv1.FailureDomain{Zone: "zone-a"})
+	if machine.Labels[machineZoneLabel] != "zone-a" {
+		t.Errorf("Expected zone label %q, got %v", "zone-a", machine.Labels)
+	}
+}
@@ -0,0 +1,105 @@
+package machine
+
+import (
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// guestAgentConnected returns true if the VMI reports its guest agent connected.
+func guestAgentConnected(vmi *kubevirtapis.VirtualMachineInstance) bool {
+	if vmi == nil {
+		return false
+	}
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Type == kubevirtapis.VirtualMachineInstanceAgentConnected {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// agentConnectedCondition returns whether the guest agent of the VMI is connected.
+func agentConnectedCondition(vmi *kubevirtapis.VirtualMachineInstance) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	if guestAgentConnected(vmi) {
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.AgentConnected,
+			Status:  corev1.ConditionTrue,
+			Reason:  kubevirtproviderv1.GuestAgentConnected,
+			Message: "The guest agent is connected",
+		}
+	}
+	message := "The guest agent is not connected"
+	if vmi == nil || vmi.Status.Phase != kubevirtapis.Running {
+		message = "The guest agent is not connected, the VirtualMachineInstance is not running"
+	}
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.AgentConnected,
+		Status:  corev1.ConditionFalse,
+		Reason:  kubevirtproviderv1.GuestAgentNotConnected,
+		Message: message,
+	}
+}
+
+// guestOSInfo returns the operating system the guest agent of the VMI reports, nil if none.
+func guestOSInfo(vmi *kubevirtapis.VirtualMachineInstance) *kubevirtproviderv1.GuestOSInfo {
+	if !guestAgentConnected(vmi) {
+		return nil
+	}
+	info := vmi.Status.GuestOSInfo
+	if info == (kubevirtapis.VirtualMachineInstanceGuestOSInfo{}) {
+		return nil
+	}
+	return &kubevirtproviderv1.GuestOSInfo{
+		ID:            info.ID,
+		Name:          info.Name,
+		PrettyName:    info.PrettyName,
+		Version:       info.Version,
+		KernelRelease: info.KernelRelease,
+		Machine:       info.Machine,
+	}
+}
+
+// guestInterfaces returns the interfaces the guest agent of the VMI reports with all their
+// addresses, unlike the machine addresses which skip the link-local and loopback ones.
+func guestInterfaces(vmi *kubevirtapis.VirtualMachineInstance) []kubevirtproviderv1.GuestInterface {
+	if !guestAgentConnected(vmi) {
+		return nil
+	}
+	var interfaces []kubevirtproviderv1.GuestInterface
+	for _, iface := range vmi.Status.Interfaces {
+		ips := iface.IPs
+		if len(ips) == 0 && iface.IP != "" {
+			ips = []string{iface.IP}
+		}
+		interfaces = append(interfaces, kubevirtproviderv1.GuestInterface{
+			Name:          iface.Name,
+			InterfaceName: iface.InterfaceName,
+			MAC:           iface.MAC,
+			IPs:           append([]string(nil), ips...),
+		})
+	}
+	return interfaces
+}
+
+// setGuestAgentStatus reports the guest agent connection, the guest OS and the interfaces of
+// the guest in the provider status.
+func (s *machineScope) setGuestAgentStatus(vmi *kubevirtapis.VirtualMachineInstance) {
+	s.providerStatus.GuestOSInfo = guestOSInfo(vmi)
+	s.providerStatus.GuestInterfaces = guestInterfaces(vmi)
+	s.setProviderStatus(agentConnectedCondition(vmi))
+}
+
+// requeueIfGuestAgentNotConnected returns an error to keep updating the status of the machine
+// until its guest agent connects, when the provider spec requires the guest agent.
+func (r *Reconciler) requeueIfGuestAgentNotConnected(vmi *kubevirtapis.VirtualMachineInstance) error {
+	if !r.providerSpec.RequireGuestAgent || guestAgentConnected(vmi) {
+		return nil
+	}
+	r.log().Info("guest agent is not connected yet, returning an error to requeue")
+	return &machinecontroller.RequeueAfterError{RequeueAfter: requeueAfterSeconds * time.Second}
+}
@@ -0,0 +1,170 @@
+package machine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func guestAgentVMI(connected bool) *kubevirtapis.VirtualMachineInstance {
+	status := corev1.ConditionFalse
+	if connected {
+		status = corev1.ConditionTrue
+	}
+	return &kubevirtapis.VirtualMachineInstance{
+		Status: kubevirtapis.VirtualMachineInstanceStatus{
+			Phase: kubevirtapis.Running,
+			Conditions: []kubevirtapis.VirtualMachineInstanceCondition{
+				{Type: kubevirtapis.VirtualMachineInstanceAgentConnected, Status: status},
+			},
+			GuestOSInfo: kubevirtapis.VirtualMachineInstanceGuestOSInfo{
+				ID:            "rhcos",
+				Name:          "Red Hat Enterprise Linux CoreOS",
+				PrettyName:    "Red Hat Enterprise Linux CoreOS 46.82",
+				Version:       "46.82",
+				KernelRelease: "4.18.0-193.el8.x86_64",
+				Machine:       "x86_64",
+			},
+			Interfaces: []kubevirtapis.VirtualMachineInstanceNetworkInterface{
+				{
+					Name:          "default",
+					InterfaceName: "eth0",
+					MAC:           "02:00:00:00:00:01",
+					IP:            "10.0.2.2/24",
+					IPs:           []string{"10.0.2.2/24", "fe80::1/64"},
+				},
+			},
+		},
+	}
+}
+
+func TestAgentConnectedCondition(t *testing.T) {
+	testCases := []struct {
+		testcase       string
+		vmi            *kubevirtapis.VirtualMachineInstance
+		expectedStatus corev1.ConditionStatus
+		expectedReason kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase:       "stopped",
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: kubevirtproviderv1.GuestAgentNotConnected,
+		},
+		{
+			testcase:       "no condition",
+			vmi:            &kubevirtapis.VirtualMachineInstance{Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running}},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: kubevirtproviderv1.GuestAgentNotConnected,
+		},
+		{
+			testcase:       "disconnected",
+			vmi:            guestAgentVMI(false),
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: kubevirtproviderv1.GuestAgentNotConnected,
+		},
+		{
+			testcase:       "connected",
+			vmi:            guestAgentVMI(true),
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: kubevirtproviderv1.GuestAgentConnected,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			condition := agentConnectedCondition(tc.vmi)
+			if condition.Type != kubevirtproviderv1.AgentConnected || condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+				t.Errorf("Expected %s %s, got %s %s", tc.expectedStatus, tc.expectedReason, condition.Status, condition.Reason)
+			}
+		})
+	}
+}
+
+func TestSetGuestAgentStatus(t *testing.T) {
+	scope := &machineScope{
+		machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}},
+		providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{},
+		providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+	}
+
+	scope.setGuestAgentStatus(guestAgentVMI(true))
+	expectedOSInfo := &kubevirtproviderv1.GuestOSInfo{
+		ID:            "rhcos",
+		Name:          "Red Hat Enterprise Linux CoreOS",
+		PrettyName:    "Red Hat Enterprise Linux CoreOS 46.82",
+		Version:       "46.82",
+		KernelRelease: "4.18.0-193.el8.x86_64",
+		Machine:       "x86_64",
+	}
+	if !reflect.DeepEqual(scope.providerStatus.GuestOSInfo, expectedOSInfo) {
+		t.Errorf("Expected guest OS %v, got %v", expectedOSInfo, scope.providerStatus.GuestOSInfo)
+	}
+	expectedInterfaces := []kubevirtproviderv1.GuestInterface{
+		{Name: "default", InterfaceName: "eth0", MAC: "02:00:00:00:00:01", IPs: []string{"10.0.2.2/24", "fe80::1/64"}},
+	}
+	if !reflect.DeepEqual(scope.providerStatus.GuestInterfaces, expectedInterfaces) {
+		t.Errorf("Expected guest interfaces %v, got %v", expectedInterfaces, scope.providerStatus.GuestInterfaces)
+	}
+	if len(scope.providerStatus.Conditions) != 1 || scope.providerStatus.Conditions[0].Status != corev1.ConditionTrue {
+		t.Errorf("Expected the AgentConnected condition to be true, got %v", scope.providerStatus.Conditions)
+	}
+
+	scope.setGuestAgentStatus(guestAgentVMI(false))
+	if scope.providerStatus.GuestOSInfo != nil || scope.providerStatus.GuestInterfaces != nil {
+		t.Errorf("Expected the guest agent data to be cleared, got %v and %v", scope.providerStatus.GuestOSInfo, scope.providerStatus.GuestInterfaces)
+	}
+}
+
+func TestRequireGuestAgent(t *testing.T) {
+	testCases := []struct {
+		testcase          string
+		requireGuestAgent bool
+		connected         bool
+		expectAddresses   bool
+		expectRequeue     bool
+	}{
+		{
+			testcase:        "not required",
+			expectAddresses: true,
+		},
+		{
+			testcase:          "required and connected",
+			requireGuestAgent: true,
+			connected:         true,
+			expectAddresses:   true,
+		},
+		{
+			testcase:          "required and not connected",
+			requireGuestAgent: true,
+			expectRequeue:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}},
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{RequireGuestAgent: tc.requireGuestAgent},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			vmi := guestAgentVMI(tc.connected)
+
+			r.machineScope.setAddresses(vmi)
+			if tc.expectAddresses != (len(r.machine.Status.Addresses) > 0) {
+				t.Errorf("Expected addresses: %v, got %v", tc.expectAddresses, r.machine.Status.Addresses)
+			}
+			err := r.requeueIfGuestAgentNotConnected(vmi)
+			if tc.expectRequeue != (err != nil) {
+				t.Errorf("Expected requeue: %v, got: %v", tc.expectRequeue, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// guestProblemConditionTypes are the node conditions set by the node-problem-detector rules
+// of config/addons/node-problem-detector for problems of virtualized nodes.
+var guestProblemConditionTypes = []corev1.NodeConditionType{
+	"VirtioProblem",
+	"ClockUnstable",
+	"MemoryBalloonPressure",
+}
+
+// guestProblemMigrationWindow is how long after a live migration of the VM guest problems
+// are attributed to it.
+const guestProblemMigrationWindow = 10 * time.Minute
+
+// guestProblemsCondition computes the GuestProblems condition out of the node conditions,
+// correlating them with the last live migration of the VMI. It returns nil if the node
+// carries none of the conditions, i.e. node-problem-detector is not deployed.
+func guestProblemsCondition(node *corev1.Node, vmi *kubevirtapis.VirtualMachineInstance) *kubevirtproviderv1.KubevirtMachineProviderCondition {
+	monitored := false
+	problems := []string{}
+	for _, nodeCondition := range node.Status.Conditions {
+		if !isGuestProblemConditionType(nodeCondition.Type) {
+			continue
+		}
+		monitored = true
+		if nodeCondition.Status != corev1.ConditionTrue {
+			continue
+		}
+		problem := fmt.Sprintf("%s (%s: %s)", nodeCondition.Type, nodeCondition.Reason, nodeCondition.Message)
+		if migration := recentMigration(vmi, nodeCondition.LastTransitionTime.Time); migration != nil {
+			problem = fmt.Sprintf("%s since the live migration from %s to %s at %s", problem,
+				migration.SourceNode, migration.TargetNode, migration.EndTimestamp.UTC().Format(time.RFC3339))
+		}
+		problems = append(problems, problem)
+	}
+
+	if !monitored {
+		return nil
+	}
+	if len(problems) == 0 {
+		return &kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.GuestProblems,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.NoGuestProblems,
+			Message: fmt.Sprintf("Node %s reports no problems of the virtualized node", node.Name),
+		}
+	}
+	return &kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.GuestProblems,
+		Status:  corev1.ConditionTrue,
+		Reason:  kubevirtproviderv1.GuestProblemsDetected,
+		Message: fmt.Sprintf("Node %s reports %s", node.Name, strings.Join(problems, ", ")),
+	}
+}
+
+func isGuestProblemConditionType(conditionType corev1.NodeConditionType) bool {
+	for _, guestProblemConditionType := range guestProblemConditionTypes {
+		if conditionType == guestProblemConditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// recentMigration returns the live migration of the VMI that completed within the migration
+// window before the given time, if any.
+func recentMigration(vmi *kubevirtapis.VirtualMachineInstance, t time.Time) *kubevirtapis.VirtualMachineInstanceMigrationState {
+	if vmi == nil {
+		return nil
+	}
+	migration := vmi.Status.MigrationState
+	if migration == nil || !migration.Completed || migration.Failed || migration.EndTimestamp == nil {
+		return nil
+	}
+	end := migration.EndTimestamp.Time
+	if t.Before(end) || t.After(end.Add(guestProblemMigrationWindow)) {
+		return nil
+	}
+	return migration
+}
@@ -0,0 +1,95 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestGuestProblemsCondition(t *testing.T) {
+	migrationEnd := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	migratedVMI := &kubevirtapis.VirtualMachineInstance{
+		Status: kubevirtapis.VirtualMachineInstanceStatus{
+			MigrationState: &kubevirtapis.VirtualMachineInstanceMigrationState{
+				Completed:    true,
+				SourceNode:   "infra-a",
+				TargetNode:   "infra-b",
+				EndTimestamp: &metav1.Time{Time: migrationEnd},
+			},
+		},
+	}
+
+	testCases := []struct {
+		testcase        string
+		conditions      []corev1.NodeCondition
+		vmi             *kubevirtapis.VirtualMachineInstance
+		expectNil       bool
+		expectedStatus  corev1.ConditionStatus
+		expectedMessage string
+	}{
+		{
+			testcase: "not monitored",
+			conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+			expectNil: true,
+		},
+		{
+			testcase: "no problems",
+			conditions: []corev1.NodeCondition{
+				{Type: "VirtioProblem", Status: corev1.ConditionFalse},
+				{Type: "ClockUnstable", Status: corev1.ConditionFalse},
+			},
+			expectedStatus: corev1.ConditionFalse,
+		},
+		{
+			testcase: "problem",
+			conditions: []corev1.NodeCondition{
+				{Type: "VirtioProblem", Status: corev1.ConditionTrue, Reason: "VirtioDeviceBroken", Message: "virtio_blk virtio2: broken"},
+			},
+			vmi:             migratedVMI,
+			expectedStatus:  corev1.ConditionTrue,
+			expectedMessage: "Node worker reports VirtioProblem (VirtioDeviceBroken: virtio_blk virtio2: broken)",
+		},
+		{
+			testcase: "problem after live migration",
+			conditions: []corev1.NodeCondition{
+				{Type: "ClockUnstable", Status: corev1.ConditionTrue, Reason: "ClocksourceUnstable", Message: "tsc unstable",
+					LastTransitionTime: metav1.Time{Time: migrationEnd.Add(time.Minute)}},
+			},
+			vmi:             migratedVMI,
+			expectedStatus:  corev1.ConditionTrue,
+			expectedMessage: "Node worker reports ClockUnstable (ClocksourceUnstable: tsc unstable) since the live migration from infra-a to infra-b at 2020-06-01T12:00:00Z",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+				Status:     corev1.NodeStatus{Conditions: tc.conditions},
+			}
+			condition := guestProblemsCondition(node, tc.vmi)
+			if tc.expectNil {
+				if condition != nil {
+					t.Errorf("Expected no condition, got %+v", condition)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatalf("Expected condition, got nil")
+			}
+			if condition.Type != kubevirtproviderv1.GuestProblems || condition.Status != tc.expectedStatus {
+				t.Errorf("Expected %s condition with status %s, got %+v", kubevirtproviderv1.GuestProblems, tc.expectedStatus, condition)
+			}
+			if tc.expectedMessage != "" && condition.Message != tc.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tc.expectedMessage, condition.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package machine
+
+import (
+	"fmt"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// validateGuestShutdownPolicy returns an error if the guest shutdown policy is unsupported or
+// cannot be applied with the run strategy of the provider spec.
+func validateGuestShutdownPolicy(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	switch providerSpec.GuestShutdownPolicy {
+	case kubevirtproviderv1.GuestShutdownRestart, kubevirtproviderv1.GuestShutdownFail, kubevirtproviderv1.GuestShutdownHalt:
+	default:
+		return fmt.Errorf("unsupported guestShutdownPolicy %q, must be one of %q, %q or %q", providerSpec.GuestShutdownPolicy,
+			kubevirtproviderv1.GuestShutdownRestart, kubevirtproviderv1.GuestShutdownFail, kubevirtproviderv1.GuestShutdownHalt)
+	}
+
+	// With the Always run strategy KubeVirt restarts the guest before the policy can be applied
+	switch providerSpec.RunStrategy {
+	case kubevirtproviderv1.RunStrategyRerunOnFailure, kubevirtproviderv1.RunStrategyManual:
+		return nil
+	default:
+		return fmt.Errorf("guestShutdownPolicy requires the %q or %q runStrategy", kubevirtproviderv1.RunStrategyRerunOnFailure, kubevirtproviderv1.RunStrategyManual)
+	}
+}
+
+// guestShutdownReason returns the reason of the shutdown reported for the VirtualMachineInstance.
+func guestShutdownReason(vmi *kubevirtapis.VirtualMachineInstance) string {
+	if vmi.Status.Reason != "" {
+		return vmi.Status.Reason
+	}
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Status == corev1.ConditionFalse && condition.Message != "" {
+			return condition.Message
+		}
+	}
+	return "no reason reported"
+}
+
+// guestShutdownCondition returns the condition recording how the guest shutdown was handled.
+func guestShutdownCondition(reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.GuestShutdown,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reconcileGuestShutdown applies the guest shutdown policy once the guest powered itself off,
+// leaving the VirtualMachineInstance in the Succeeded phase.
+func (r *Reconciler) reconcileGuestShutdown(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) (*kubevirtapis.VirtualMachine, error) {
+	policy := r.providerSpec.GuestShutdownPolicy
+	if policy == "" || vmi == nil || vmi.Status.Phase != kubevirtapis.Succeeded {
+		return vm, nil
+	}
+	reason := guestShutdownReason(vmi)
+
+	switch policy {
+	case kubevirtproviderv1.GuestShutdownRestart:
+		klog.Infof("%s: guest shut down (%s), restarting VirtualMachine", r.machine.Name, reason)
+		if err := r.kubevirtClient.RestartVirtualMachine(r.Context, vm.Namespace, vm.Name); err != nil {
+			return vm, fmt.Errorf("failed to restart VirtualMachine: %w", err)
+		}
+		r.machineScope.setProviderStatus(guestShutdownCondition(kubevirtproviderv1.GuestShutdownRestarted, fmt.Sprintf("Guest shut down (%s), the VM was restarted", reason)))
+	case kubevirtproviderv1.GuestShutdownFail:
+		message := fmt.Sprintf("Guest shut down (%s)", reason)
+		if r.machine.Status.ErrorReason == nil {
+			klog.Infof("%s: guest shut down (%s), marking machine failed", r.machine.Name, reason)
+			errorReason := machinev1.UpdateMachineError
+			r.machine.Status.ErrorReason = &errorReason
+			r.machine.Status.ErrorMessage = &message
+		}
+		r.machineScope.setProviderStatus(guestShutdownCondition(kubevirtproviderv1.GuestShutdownFailed, message))
+	case kubevirtproviderv1.GuestShutdownHalt:
+		if !vmHalted(vm) {
+			klog.Infof("%s: guest shut down (%s), halting VirtualMachine", r.machine.Name, reason)
+			halted := vm.DeepCopy()
+			haltVM(halted)
+			updatedVM, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, halted.Namespace, halted)
+			if err != nil {
+				return vm, fmt.Errorf("failed to halt VirtualMachine: %w", err)
+			}
+			vm = updatedVM
+		}
+		r.machineScope.setProviderStatus(guestShutdownCondition(kubevirtproviderv1.GuestShutdownHalted, fmt.Sprintf("Guest shut down (%s), the VM is kept stopped", reason)))
+	}
+	return vm, nil
+}
@@ -0,0 +1,102 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestReconcileGuestShutdown(t *testing.T) {
+	testCases := []struct {
+		testcase       string
+		policy         kubevirtproviderv1.GuestShutdownPolicy
+		expectClient   func(client *mockkubevirt.MockClient)
+		expectedReason kubevirtproviderv1.KubevirtMachineProviderConditionReason
+		expectedFailed bool
+	}{
+		{
+			testcase: "restart",
+			policy:   kubevirtproviderv1.GuestShutdownRestart,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().RestartVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde").Return(nil)
+			},
+			expectedReason: kubevirtproviderv1.GuestShutdownRestarted,
+		},
+		{
+			testcase:       "fail",
+			policy:         kubevirtproviderv1.GuestShutdownFail,
+			expectClient:   func(client *mockkubevirt.MockClient) {},
+			expectedReason: kubevirtproviderv1.GuestShutdownFailed,
+			expectedFailed: true,
+		},
+		{
+			testcase: "halt",
+			policy:   kubevirtproviderv1.GuestShutdownHalt,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+					if !vmHalted(vm) {
+						t.Errorf("Expected the VM to be halted, got run strategy %v", vm.Spec.RunStrategy)
+					}
+					return vm, nil
+				})
+			},
+			expectedReason: kubevirtproviderv1.GuestShutdownHalted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			manual := kubevirtapis.RunStrategyManual
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec:       kubevirtapis.VirtualMachineSpec{RunStrategy: &manual},
+			}
+			vmi := &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Succeeded, Reason: "PoweredOff"},
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			tc.expectClient(client)
+
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        machine,
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					RunStrategy:         kubevirtproviderv1.RunStrategyManual,
+					GuestShutdownPolicy: tc.policy,
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			if _, err := r.reconcileGuestShutdown(vm, vmi); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.GuestShutdown)
+			if condition == nil || condition.Reason != tc.expectedReason {
+				t.Errorf("Expected GuestShutdown condition with reason %s, got %v", tc.expectedReason, condition)
+			}
+			if failed := machine.Status.ErrorReason != nil; failed != tc.expectedFailed {
+				t.Errorf("Expected machine failed %v, got %v", tc.expectedFailed, failed)
+			}
+			if tc.expectedFailed && *machine.Status.ErrorMessage != "Guest shut down (PoweredOff)" {
+				t.Errorf("Expected the guest reason in the error message, got %q", *machine.Status.ErrorMessage)
+			}
+		})
+	}
+
+	err := validateGuestShutdownPolicy(&kubevirtproviderv1.KubevirtMachineProviderSpec{GuestShutdownPolicy: kubevirtproviderv1.GuestShutdownHalt})
+	if err == nil {
+		t.Errorf("Expected error for a guest shutdown policy without run strategy, got nil")
+	}
+}
@@ -0,0 +1,79 @@
+package machine
+
+import (
+	"fmt"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// hibernateAnnotation set to "true" on a machine hibernates it: its VM is stopped, keeping its
+// volumes, until the annotation is removed, e.g. to save the infra resources of dev and test
+// tenant clusters overnight. The Node of a hibernated machine is not ready, MachineHealthChecks
+// covering it should be paused meanwhile.
+const hibernateAnnotation = "kubevirtproviderconfig.openshift.io/hibernate"
+
+// hibernationRequested returns true if the machine is requested to hibernate.
+func hibernationRequested(machine *machinev1.Machine) bool {
+	return machine.Annotations[hibernateAnnotation] == "true"
+}
+
+// hibernatedCondition reports whether the VM of the machine is hibernated.
+func hibernatedCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.Hibernated,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reconcileHibernation stops the VM of a machine requested to hibernate, and starts it again
+// with the run strategy of the provider spec once the machine is resumed. It returns true while
+// the machine is hibernated.
+func (r *Reconciler) reconcileHibernation(vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, bool, error) {
+	if hibernationRequested(r.machine) {
+		if !vmHalted(vm) {
+			r.log().Info("hibernating machine, stopping its VirtualMachine")
+			halted := vm.DeepCopy()
+			haltVM(halted)
+			updatedVM, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, halted.Namespace, halted)
+			if err != nil {
+				return vm, false, fmt.Errorf("failed to stop VirtualMachine: %w", err)
+			}
+			vm = updatedVM
+		}
+		r.machineScope.setProviderStatus(hibernatedCondition(corev1.ConditionTrue, kubevirtproviderv1.HibernationRequested,
+			"VirtualMachine is stopped until the machine is resumed, its volumes are kept"))
+		return vm, true, nil
+	}
+
+	condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.Hibernated)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		return vm, false, nil
+	}
+
+	runStrategy, err := resolveRunStrategy(r.providerSpec)
+	if err != nil {
+		return vm, false, fmt.Errorf("%v: %w", r.machine.GetName(), err)
+	}
+	if vmHalted(vm) {
+		r.log().Info("resuming hibernated machine, starting its VirtualMachine")
+		resumed := vm.DeepCopy()
+		applyRunStrategy(resumed, runStrategy)
+		updatedVM, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, resumed.Namespace, resumed)
+		if err != nil {
+			return vm, false, fmt.Errorf("failed to start VirtualMachine: %w", err)
+		}
+		if err := startManualVM(r.Context, r.kubevirtClient, updatedVM, runStrategy); err != nil {
+			return updatedVM, false, err
+		}
+		vm = updatedVM
+	}
+	r.machineScope.setProviderStatus(hibernatedCondition(corev1.ConditionFalse, kubevirtproviderv1.MachineResumed,
+		"VirtualMachine was started again after the hibernation of the machine"))
+	return vm, false, nil
+}
@@ -0,0 +1,135 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestReconcileHibernation(t *testing.T) {
+	testCases := []struct {
+		testcase          string
+		hibernate         bool
+		wasHibernated     bool
+		halted            bool
+		runStrategy       kubevirtproviderv1.RunStrategy
+		expectUpdate      bool
+		expectStart       bool
+		expectHalted      bool
+		expectHibernated  bool
+		expectedCondition corev1.ConditionStatus
+	}{
+		{
+			testcase: "running machine",
+		},
+		{
+			testcase:          "hibernates the machine",
+			hibernate:         true,
+			runStrategy:       kubevirtproviderv1.RunStrategyAlways,
+			expectUpdate:      true,
+			expectHalted:      true,
+			expectHibernated:  true,
+			expectedCondition: corev1.ConditionTrue,
+		},
+		{
+			testcase:          "hibernated machine",
+			hibernate:         true,
+			wasHibernated:     true,
+			halted:            true,
+			runStrategy:       kubevirtproviderv1.RunStrategyAlways,
+			expectHalted:      true,
+			expectHibernated:  true,
+			expectedCondition: corev1.ConditionTrue,
+		},
+		{
+			testcase:          "resumes the machine",
+			wasHibernated:     true,
+			halted:            true,
+			runStrategy:       kubevirtproviderv1.RunStrategyAlways,
+			expectUpdate:      true,
+			expectedCondition: corev1.ConditionFalse,
+		},
+		{
+			testcase:          "resumes the machine with the Manual run strategy",
+			wasHibernated:     true,
+			halted:            true,
+			runStrategy:       kubevirtproviderv1.RunStrategyManual,
+			expectUpdate:      true,
+			expectStart:       true,
+			expectedCondition: corev1.ConditionFalse,
+		},
+		{
+			testcase:     "leaves a VM halted otherwise alone",
+			halted:       true,
+			runStrategy:  kubevirtproviderv1.RunStrategyAlways,
+			expectHalted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			always := kubevirtapis.RunStrategyAlways
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				Spec:       kubevirtapis.VirtualMachineSpec{RunStrategy: &always},
+			}
+			if tc.halted {
+				haltVM(vm)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.expectUpdate {
+				client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+					return vm, nil
+				})
+			}
+			if tc.expectStart {
+				client.EXPECT().StartVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde").Return(nil)
+			}
+
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+			if tc.hibernate {
+				machine.Annotations = map[string]string{hibernateAnnotation: "true"}
+			}
+			providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{}
+			if tc.wasHibernated {
+				providerStatus.Conditions = []kubevirtproviderv1.KubevirtMachineProviderCondition{hibernatedCondition(corev1.ConditionTrue, kubevirtproviderv1.HibernationRequested, "")}
+			}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        machine,
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{RunStrategy: tc.runStrategy},
+				providerStatus: providerStatus,
+			})
+
+			updatedVM, hibernated, err := r.reconcileHibernation(vm)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if hibernated != tc.expectHibernated {
+				t.Errorf("Expected hibernated %v, got %v", tc.expectHibernated, hibernated)
+			}
+			if vmHalted(updatedVM) != tc.expectHalted {
+				t.Errorf("Expected halted %v, got run strategy %v", tc.expectHalted, updatedVM.Spec.RunStrategy)
+			}
+			condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.Hibernated)
+			if tc.expectedCondition == "" && condition != nil {
+				t.Errorf("Expected no Hibernated condition, got %v", condition)
+			}
+			if tc.expectedCondition != "" && (condition == nil || condition.Status != tc.expectedCondition) {
+				t.Errorf("Expected Hibernated %v, got condition %v", tc.expectedCondition, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const hostnamePath = "/etc/hostname"
+
+// guestHostname returns the hostname KubeVirt hands to the guest as local-hostname in the
+// instance metadata. Machine names which are not valid DNS labels are left to KubeVirt.
+func guestHostname(machineName string) string {
+	if len(validation.IsDNS1123Label(machineName)) > 0 {
+		return ""
+	}
+	return machineName
+}
+
+// validateDomainSuffix returns the domain suffix without trailing dot, if it is a valid DNS subdomain.
+func validateDomainSuffix(domainSuffix string) (string, error) {
+	domainSuffix = strings.TrimSuffix(domainSuffix, ".")
+	if errs := validation.IsDNS1123Subdomain(domainSuffix); len(errs) > 0 {
+		return "", fmt.Errorf("invalid domainSuffix %q: %s", domainSuffix, strings.Join(errs, ", "))
+	}
+	return domainSuffix, nil
+}
+
+// guestFQDN returns the FQDN of the guest out of the machine name and the domain suffix.
+func guestFQDN(machineName, domainSuffix string) (string, error) {
+	domainSuffix, err := validateDomainSuffix(domainSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	fqdn := machineName + "." + domainSuffix
+	if errs := validation.IsDNS1123Subdomain(fqdn); len(errs) > 0 {
+		return "", fmt.Errorf("invalid FQDN %q: %s", fqdn, strings.Join(errs, ", "))
+	}
+	return fqdn, nil
+}
+
+// injectFQDN sets the FQDN of the guest, through the hostname and fqdn keys for cloud-init
+// or /etc/hostname for Ignition.
+func injectFQDN(userData []byte, format kubevirtproviderv1.UserDataFormat, machineName, fqdn string) ([]byte, error) {
+	if format == kubevirtproviderv1.UserDataFormatIgnition {
+		return editIgnitionConfig(userData, func(ignitionConfig map[string]interface{}, version string) {
+			appendIgnitionFile(ignitionConfig, version, hostnamePath, fqdn+"\n")
+		})
+	}
+
+	return editCloudConfig(userData, func(cloudConfig map[string]interface{}) {
+		cloudConfig["hostname"] = machineName
+		cloudConfig["fqdn"] = fqdn
+		cloudConfig["prefer_fqdn_over_hostname"] = true
+	})
+}
@@ -0,0 +1,100 @@
+package machine
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestGuestHostname(t *testing.T) {
+	if hostname := guestHostname("worker-abcde"); hostname != "worker-abcde" {
+		t.Errorf("Expected hostname %q, got %q", "worker-abcde", hostname)
+	}
+	if hostname := guestHostname("worker.example.com"); hostname != "" {
+		t.Errorf("Expected no hostname for a machine name which is not a DNS label, got %q", hostname)
+	}
+}
+
+func TestGuestFQDN(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		domainSuffix string
+		expectedFQDN string
+		expectError  bool
+	}{
+		{
+			testcase:     "domain",
+			domainSuffix: "cluster.example.com",
+			expectedFQDN: "worker-abcde.cluster.example.com",
+		},
+		{
+			testcase:     "trailing dot",
+			domainSuffix: "cluster.example.com.",
+			expectedFQDN: "worker-abcde.cluster.example.com",
+		},
+		{
+			testcase:     "invalid domain",
+			domainSuffix: "Cluster_Example",
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			fqdn, err := guestFQDN("worker-abcde", tc.domainSuffix)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fqdn != tc.expectedFQDN {
+				t.Errorf("Expected FQDN %q, got %q", tc.expectedFQDN, fqdn)
+			}
+		})
+	}
+}
+
+func TestInjectFQDN(t *testing.T) {
+	fqdn := "worker-abcde.cluster.example.com"
+
+	userData, err := injectFQDN([]byte("#cloud-config\nruncmd:\n- echo hello\n"), kubevirtproviderv1.UserDataFormatCloudInit, "worker-abcde", fqdn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cloudConfig := map[string]interface{}{}
+	if err := yaml.Unmarshal(userData, &cloudConfig); err != nil {
+		t.Fatalf("Failed to parse rendered cloud-config: %v", err)
+	}
+	if cloudConfig["hostname"] != "worker-abcde" || cloudConfig["fqdn"] != fqdn {
+		t.Errorf("Expected hostname %q and fqdn %q, got %v and %v", "worker-abcde", fqdn, cloudConfig["hostname"], cloudConfig["fqdn"])
+	}
+	if cloudConfig["runcmd"] == nil {
+		t.Errorf("Expected runcmd to be preserved")
+	}
+
+	userData, err = injectFQDN([]byte(`{"ignition":{"version":"3.1.0"}}`), kubevirtproviderv1.UserDataFormatIgnition, "worker-abcde", fqdn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ignitionConfig := map[string]interface{}{}
+	if err := json.Unmarshal(userData, &ignitionConfig); err != nil {
+		t.Fatalf("Failed to parse rendered Ignition config: %v", err)
+	}
+	files := ignitionConfig["storage"].(map[string]interface{})["files"].([]interface{})
+	file := files[0].(map[string]interface{})
+	if file["path"] != hostnamePath {
+		t.Errorf("Expected file %q, got %q", hostnamePath, file["path"])
+	}
+	source := file["contents"].(map[string]interface{})["source"]
+	if expected := "data:," + url.PathEscape(fqdn+"\n"); source != expected {
+		t.Errorf("Expected contents %q, got %q", expected, source)
+	}
+}
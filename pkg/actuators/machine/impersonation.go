@@ -0,0 +1,68 @@
+package machine
+
+import (
+	"fmt"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/client-go/rest"
+
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// impersonationClusterExtra is the impersonated user extra carrying the tenant cluster ID
+	impersonationClusterExtra = "kubevirtproviderconfig.openshift.io/tenant-cluster"
+	// impersonationMachineExtra is the impersonated user extra carrying the namespaced name of
+	// the tenant machine
+	impersonationMachineExtra = "kubevirtproviderconfig.openshift.io/tenant-machine"
+)
+
+// Impersonation configures the identity the requests made to the infra cluster for a machine
+// impersonate, so that the audit logs of the infra cluster attribute every VM operation to the
+// tenant cluster and machine it originates from. The infra cluster RBAC has to allow the
+// identity of the credentials to impersonate the user, the groups and the userextras, and the
+// impersonated identity to manage the VMs.
+type Impersonation struct {
+	// UserName is the user impersonated. The tenant cluster ID is appended to it, separated
+	// with a colon, if set.
+	UserName string
+	// Groups are the groups impersonated, they are the subjects RBAC is usually granted to.
+	Groups []string
+	// ClusterID identifies the tenant cluster. Defaults to the cluster ID label of the machine.
+	ClusterID string
+	// ClientBuilder builds the impersonating clients. Defaults to NewImpersonatingClient.
+	ClientBuilder kubevirtclient.ImpersonatingClientBuilderFuncType
+}
+
+// impersonationConfig returns the identity the requests for the machine impersonate.
+func (i *Impersonation) impersonationConfig(machine *machinev1.Machine) rest.ImpersonationConfig {
+	config := rest.ImpersonationConfig{
+		UserName: i.UserName,
+		Groups:   i.Groups,
+		Extra: map[string][]string{
+			impersonationMachineExtra: {fmt.Sprintf("%s/%s", machine.Namespace, machine.Name)},
+		},
+	}
+	clusterID := i.ClusterID
+	if clusterID == "" {
+		clusterID, _ = getClusterID(machine)
+	}
+	if clusterID != "" {
+		config.UserName = fmt.Sprintf("%s:%s", i.UserName, clusterID)
+		config.Extra[impersonationClusterExtra] = []string{clusterID}
+	}
+	return config
+}
+
+// clientBuilder returns a builder of clients impersonating the identity of the machine.
+func (i *Impersonation) clientBuilder(machine *machinev1.Machine) kubevirtclient.KubevirtClientBuilderFuncType {
+	builder := i.ClientBuilder
+	if builder == nil {
+		builder = kubevirtclient.NewImpersonatingClient
+	}
+	config := i.impersonationConfig(machine)
+	return func(client runtimeclient.Client, secretName, namespace string) (kubevirtclient.Client, error) {
+		return builder(client, secretName, namespace, config)
+	}
+}
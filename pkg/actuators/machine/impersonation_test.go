@@ -0,0 +1,90 @@
+package machine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestImpersonation(t *testing.T) {
+	providerSpec, err := kubevirtproviderv1.RawExtensionFromProviderSpec(&kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-abcde",
+			Namespace: "openshift-machine-api",
+			Labels:    map[string]string{machinev1.MachineClusterIDLabel: "tenant-a"},
+		},
+		Spec: machinev1.MachineSpec{ProviderSpec: machinev1.ProviderSpec{Value: providerSpec}},
+	}
+
+	testCases := []struct {
+		testcase         string
+		clusterID        string
+		expectedUserName string
+		expectedCluster  string
+	}{
+		{
+			testcase:         "cluster ID label of the machine",
+			expectedUserName: "kubevirt-tenant:tenant-a",
+			expectedCluster:  "tenant-a",
+		},
+		{
+			testcase:         "configured cluster ID",
+			clusterID:        "tenant-b",
+			expectedUserName: "kubevirt-tenant:tenant-b",
+			expectedCluster:  "tenant-b",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			var impersonated *rest.ImpersonationConfig
+			impersonation := &Impersonation{
+				UserName:  "kubevirt-tenant",
+				Groups:    []string{"kubevirt-tenants"},
+				ClusterID: tc.clusterID,
+				ClientBuilder: func(client runtimeclient.Client, secretName, namespace string, impersonate rest.ImpersonationConfig) (kubevirtclient.Client, error) {
+					impersonated = &impersonate
+					return nil, nil
+				},
+			}
+			_, err := newMachineScope(machineScopeParams{
+				Context:       context.Background(),
+				client:        fake.NewFakeClientWithScheme(runtime.NewScheme()),
+				machine:       machine,
+				impersonation: impersonation,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if impersonated == nil {
+				t.Fatalf("Expected the impersonating client builder to be used")
+			}
+
+			expected := rest.ImpersonationConfig{
+				UserName: tc.expectedUserName,
+				Groups:   []string{"kubevirt-tenants"},
+				Extra: map[string][]string{
+					impersonationClusterExtra: {tc.expectedCluster},
+					impersonationMachineExtra: {"openshift-machine-api/worker-abcde"},
+				},
+			}
+			if !reflect.DeepEqual(*impersonated, expected) {
+				t.Errorf("Expected impersonation %v, got %v", expected, *impersonated)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+)
+
+// infraPermission is a permission the infra credentials need in the namespace of the VMs.
+type infraPermission struct {
+	group       string
+	resource    string
+	subresource string
+	verb        string
+}
+
+func (p infraPermission) String() string {
+	resource := p.resource
+	if p.group != "" {
+		resource = p.group + "/" + resource
+	}
+	if p.subresource != "" {
+		resource += "/" + p.subresource
+	}
+	return p.verb + " " + resource
+}
+
+// infraPermissions are the namespaced permissions the machine operations need on the infra cluster.
+var infraPermissions = []infraPermission{
+	{group: kubevirtapis.GroupName, resource: "virtualmachines", verb: "get"},
+	{group: kubevirtapis.GroupName, resource: "virtualmachines", verb: "create"},
+	{group: kubevirtapis.GroupName, resource: "virtualmachines", verb: "update"},
+	{group: kubevirtapis.GroupName, resource: "virtualmachines", verb: "delete"},
+	{group: "subresources.kubevirt.io", resource: "virtualmachines", subresource: "start", verb: "update"},
+	{group: "subresources.kubevirt.io", resource: "virtualmachines", subresource: "restart", verb: "update"},
+	{group: kubevirtapis.GroupName, resource: "virtualmachineinstances", verb: "get"},
+	{group: "cdi.kubevirt.io", resource: "datavolumes", verb: "get"},
+	{group: "cdi.kubevirt.io", resource: "datavolumes", verb: "create"},
+	{group: "cdi.kubevirt.io", resource: "datavolumes", verb: "delete"},
+	{resource: "persistentvolumeclaims", verb: "get"},
+	{resource: "secrets", verb: "get"},
+	{resource: "secrets", verb: "create"},
+	{resource: "secrets", verb: "update"},
+	{resource: "secrets", verb: "delete"},
+	{resource: "pods", verb: "list"},
+	{resource: "pods", verb: "patch"},
+	{resource: "events", verb: "list"},
+}
+
+// infraCachePermissions are the namespaced permissions the informers of the infra cache need.
+var infraCachePermissions = []infraPermission{
+	{group: kubevirtapis.GroupName, resource: "virtualmachines", verb: "list"},
+	{group: kubevirtapis.GroupName, resource: "virtualmachines", verb: "watch"},
+	{group: kubevirtapis.GroupName, resource: "virtualmachineinstances", verb: "list"},
+	{group: kubevirtapis.GroupName, resource: "virtualmachineinstances", verb: "watch"},
+	{group: "cdi.kubevirt.io", resource: "datavolumes", verb: "list"},
+	{group: "cdi.kubevirt.io", resource: "datavolumes", verb: "watch"},
+}
+
+// VerifyInfraAccess checks with SelfSubjectAccessReviews that the credentials of the client
+// hold the permissions the machine operations need in the infra namespace, along with those
+// of the infra cache if cached, and returns an error listing the missing ones.
+func VerifyInfraAccess(ctx context.Context, client kubevirtclient.Client, namespace string, cached bool) error {
+	permissions := infraPermissions
+	if cached {
+		permissions = append(append([]infraPermission{}, infraPermissions...), infraCachePermissions...)
+	}
+
+	var missing []string
+	for _, permission := range permissions {
+		review, err := client.CreateSelfSubjectAccessReview(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   namespace,
+					Group:       permission.group,
+					Resource:    permission.resource,
+					Subresource: permission.subresource,
+					Verb:        permission.verb,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to review the infra permission to %s: %w", permission, err)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, permission.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the infra credentials lack permissions in namespace %s: %s. Grant them with a Role bound to the identity of the credentials in that namespace",
+			namespace, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateInfraAccess returns an error if the provider spec needs infra access beyond the
+// namespace the infra credentials are scoped to.
+func (r *Reconciler) validateInfraAccess() error {
+	accessNamespace := r.infraAccessNamespace
+	if accessNamespace == "" {
+		return nil
+	}
+	if namespace := infraNamespace(r.machine, r.providerSpec); namespace != accessNamespace {
+		return machinecontroller.InvalidMachineConfiguration("%v: the VM namespace %s is outside of the infra access namespace %s", r.machine.GetName(), namespace, accessNamespace)
+	}
+	if r.providerSpec.CreateInfraNamespace {
+		return machinecontroller.InvalidMachineConfiguration("%v: createInfraNamespace requires cluster-scoped infra access", r.machine.GetName())
+	}
+	if r.providerSpec.NUMAResourceCheck {
+		return machinecontroller.InvalidMachineConfiguration("%v: numaResourceCheck requires cluster-scoped infra access to list nodes", r.machine.GetName())
+	}
+	if dataExport := r.providerSpec.DataExport; dataExport != nil && dataExport.ArchiveNamespace != accessNamespace {
+		return machinecontroller.InvalidMachineConfiguration("%v: the dataExport archive namespace %s is outside of the infra access namespace %s", r.machine.GetName(), dataExport.ArchiveNamespace, accessNamespace)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package machine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestVerifyInfraAccess(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client := mockkubevirt.NewMockClient(mockCtrl)
+	var reviewed int
+	client.EXPECT().CreateSelfSubjectAccessReview(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, review *authorizationv1.SelfSubjectAccessReview) (*authorizationv1.SelfSubjectAccessReview, error) {
+			reviewed++
+			attributes := review.Spec.ResourceAttributes
+			if attributes.Namespace != "tenant-a" {
+				t.Errorf("Expected the review in namespace tenant-a, got %q", attributes.Namespace)
+			}
+			// The credentials miss the permissions of the infra cache and to delete secrets
+			review.Status.Allowed = attributes.Verb != "watch" && !(attributes.Resource == "secrets" && attributes.Verb == "delete")
+			return review, nil
+		}).AnyTimes()
+
+	if err := VerifyInfraAccess(context.Background(), client, "tenant-a", false); err == nil || !strings.Contains(err.Error(), "delete secrets") {
+		t.Errorf("Expected an error listing the permission to delete secrets, got %v", err)
+	}
+	if reviewed != len(infraPermissions) {
+		t.Errorf("Expected %d reviews, got %d", len(infraPermissions), reviewed)
+	}
+
+	err := VerifyInfraAccess(context.Background(), client, "tenant-a", true)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, permission := range []string{"watch kubevirt.io/virtualmachines", "watch kubevirt.io/virtualmachineinstances", "watch cdi.kubevirt.io/datavolumes"} {
+		if !strings.Contains(err.Error(), permission) {
+			t.Errorf("Expected the error to list %q, got %v", permission, err)
+		}
+	}
+}
+
+func TestValidateInfraAccess(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		expectError  bool
+	}{
+		{
+			testcase: "namespace of the machine",
+		},
+		{
+			testcase:     "infra namespace",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "tenant-a"},
+		},
+		{
+			testcase:     "another namespace",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "tenant-b"},
+			expectError:  true,
+		},
+		{
+			testcase:     "namespace creation",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "tenant-a", CreateInfraNamespace: true},
+			expectError:  true,
+		},
+		{
+			testcase:     "NUMA resource check",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{NUMAResourceCheck: true},
+			expectError:  true,
+		},
+		{
+			testcase: "archive in another namespace",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				DataExport: &kubevirtproviderv1.DataExport{Volumes: []string{"rootdisk"}, ArchiveNamespace: "archive"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			r := newReconciler(&machineScope{
+				Context:              context.Background(),
+				machine:              &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}},
+				providerSpec:         &tc.providerSpec,
+				infraAccessNamespace: "tenant-a",
+			})
+			err := r.validateInfraAccess()
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package machine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	// maxInfraEvents bounds the infra events summarized in a failure event
+	maxInfraEvents = 3
+	// maxInfraEventMessage bounds the length of the message of a summarized infra event
+	maxInfraEventMessage = 120
+	// launcherPodPrefix prefixes the names of the virt-launcher pods of a VMI
+	launcherPodPrefix = "virt-launcher-"
+)
+
+// infraEventTime returns when the event last occurred.
+func infraEventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// relatedToMachine returns true if the event is about the VM of the machine, its VMI, its
+// virt-launcher pods or its boot volume.
+func relatedToMachine(event *corev1.Event, machineName string) bool {
+	object := event.InvolvedObject
+	switch object.Kind {
+	case "VirtualMachine", "VirtualMachineInstance":
+		return object.Name == machineName
+	case "Pod":
+		// virt-launcher pods are named after the VMI with a random suffix
+		prefix := launcherPodPrefix + machineName + "-"
+		return strings.HasPrefix(object.Name, prefix) && !strings.Contains(strings.TrimPrefix(object.Name, prefix), "-")
+	case "PersistentVolumeClaim", "DataVolume":
+		return object.Name == machineName+bootVolumeSuffix
+	}
+	return false
+}
+
+// summarizeInfraEvents returns a compact summary of the most recent warning events of the
+// infra objects of the machine which occurred within the window, empty if there are none.
+func summarizeInfraEvents(events []corev1.Event, machineName string, window time.Duration, now time.Time) string {
+	var related []*corev1.Event
+	for i := range events {
+		event := &events[i]
+		if event.Type == corev1.EventTypeWarning && relatedToMachine(event, machineName) && now.Sub(infraEventTime(event)) <= window {
+			related = append(related, event)
+		}
+	}
+	if len(related) == 0 {
+		return ""
+	}
+	sort.SliceStable(related, func(i, j int) bool {
+		return infraEventTime(related[i]).After(infraEventTime(related[j]))
+	})
+	if len(related) > maxInfraEvents {
+		related = related[:maxInfraEvents]
+	}
+
+	summaries := make([]string, 0, len(related))
+	for _, event := range related {
+		message := strings.Join(strings.Fields(event.Message), " ")
+		if len(message) > maxInfraEventMessage {
+			message = message[:maxInfraEventMessage] + "..."
+		}
+		summary := fmt.Sprintf("%s/%s %s: %s", event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason, message)
+		if event.Count > 1 {
+			summary += fmt.Sprintf(" (x%d)", event.Count)
+		}
+		summaries = append(summaries, summary)
+	}
+	return "recent infra events: " + strings.Join(summaries, "; ")
+}
+
+// infraEventSummary returns the summary of the recent infra events of the machine, empty if
+// there are none or they cannot be listed.
+func (s *machineScope) infraEventSummary(window time.Duration) string {
+	if window <= 0 {
+		return ""
+	}
+	events, err := s.kubevirtClient.ListEvents(s.Context, infraNamespace(s.machine, s.providerSpec), &metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String(),
+	})
+	if err != nil {
+		s.log().Error(err, "failed to list infra events")
+		return ""
+	}
+	return summarizeInfraEvents(events.Items, s.machine.Name, window, time.Now())
+}
@@ -0,0 +1,53 @@
+package machine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func infraEvent(kind, name, eventType, reason, message string, lastTimestamp time.Time) corev1.Event {
+	return corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: name},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		LastTimestamp:  metav1.NewTime(lastTimestamp),
+	}
+}
+
+func TestSummarizeInfraEvents(t *testing.T) {
+	now := time.Now()
+	events := []corev1.Event{
+		infraEvent("VirtualMachineInstance", "worker-abcde", corev1.EventTypeWarning, "FailedScheduling", "0/3 nodes are available:\n 3 Insufficient memory.", now.Add(-time.Minute)),
+		infraEvent("Pod", "virt-launcher-worker-abcde-x7k2p", corev1.EventTypeWarning, "FailedMount", "volume rootdisk not attached", now.Add(-2*time.Minute)),
+		infraEvent("PersistentVolumeClaim", "worker-abcde-bootvolume", corev1.EventTypeWarning, "ProvisioningFailed", "storageclass not found", now.Add(-30*time.Second)),
+		infraEvent("VirtualMachine", "worker-abcde", corev1.EventTypeNormal, "SuccessfulCreate", "created", now),
+		infraEvent("VirtualMachine", "worker-abcde", corev1.EventTypeWarning, "FailedCreate", "too old", now.Add(-time.Hour)),
+		infraEvent("VirtualMachineInstance", "worker-fghij", corev1.EventTypeWarning, "FailedScheduling", "other machine", now),
+		infraEvent("Pod", "virt-launcher-worker-abcde-longer-q9r8s", corev1.EventTypeWarning, "BackOff", "other machine", now),
+	}
+
+	summary := summarizeInfraEvents(events, "worker-abcde", 10*time.Minute, now)
+	expected := "recent infra events: " +
+		"PersistentVolumeClaim/worker-abcde-bootvolume ProvisioningFailed: storageclass not found; " +
+		"VirtualMachineInstance/worker-abcde FailedScheduling: 0/3 nodes are available: 3 Insufficient memory.; " +
+		"Pod/virt-launcher-worker-abcde-x7k2p FailedMount: volume rootdisk not attached"
+	if summary != expected {
+		t.Errorf("Expected summary %q, got %q", expected, summary)
+	}
+
+	if summary := summarizeInfraEvents(events[3:6], "worker-abcde", 10*time.Minute, now); summary != "" {
+		t.Errorf("Expected no summary, got %q", summary)
+	}
+
+	long := infraEvent("VirtualMachine", "worker-abcde", corev1.EventTypeWarning, "FailedCreate", strings.Repeat("x", 200), now)
+	long.Count = 4
+	summary = summarizeInfraEvents([]corev1.Event{long}, "worker-abcde", time.Minute, now)
+	if !strings.HasSuffix(summary, strings.Repeat("x", maxInfraEventMessage)+"... (x4)") {
+		t.Errorf("Expected truncated message with count, got %q", summary)
+	}
+}
@@ -0,0 +1,233 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+	"k8s.io/kubectl/pkg/drain"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/codec"
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+)
+
+const (
+	// infraEvictionAnnotation is set on the tenant Nodes cordoned because their VMI is being
+	// terminated by the infra cluster, to the UID of that VMI. The Node is uncordoned once
+	// another VMI of the machine runs.
+	infraEvictionAnnotation = "kubevirtproviderconfig.openshift.io/infra-eviction-vmi"
+	// infraEvictionDrainTimeout bounds each drain attempt, the drain is retried on the next check
+	infraEvictionDrainTimeout = 20 * time.Second
+)
+
+// InfraEvictionDrain cordons and drains the tenant Node of machines whose VMI the infra
+// cluster is terminating, e.g. evicting it for the maintenance of its infra node without live
+// migration, so that the workloads move before the VM goes away rather than after the machine
+// controller notices. The Node is uncordoned once the VM runs again.
+type InfraEvictionDrain struct {
+	client                runtimeclient.Client
+	kubeClient            kubernetes.Interface
+	kubevirtClientBuilder kubevirtclient.KubevirtClientBuilderFuncType
+	eventRecorder         record.EventRecorder
+	namespace             string
+	interval              time.Duration
+}
+
+// NewInfraEvictionDrain returns an InfraEvictionDrain checking the machines in namespace, or
+// in all namespaces if empty, at the interval.
+func NewInfraEvictionDrain(client runtimeclient.Client, kubeClient kubernetes.Interface, kubevirtClientBuilder kubevirtclient.KubevirtClientBuilderFuncType,
+	eventRecorder record.EventRecorder, namespace string, interval time.Duration) *InfraEvictionDrain {
+	return &InfraEvictionDrain{
+		client:                client,
+		kubeClient:            kubeClient,
+		kubevirtClientBuilder: kubevirtClientBuilder,
+		eventRecorder:         eventRecorder,
+		namespace:             namespace,
+		interval:              interval,
+	}
+}
+
+// Start periodically drains the Nodes of the machines whose VMI is being terminated.
+// It implements manager.Runnable.
+func (d *InfraEvictionDrain) Start(stop <-chan struct{}) error {
+	wait.Until(d.check, d.interval, stop)
+	return nil
+}
+
+func (d *InfraEvictionDrain) check() {
+	machines := &machinev1.MachineList{}
+	if err := d.client.List(context.Background(), machines, runtimeclient.InNamespace(d.namespace)); err != nil {
+		klog.Errorf("Failed to list machines to check the termination of their VMI: %v", err)
+		return
+	}
+
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		// The machine controller drains the Node of deleted machines itself
+		if machine.Status.NodeRef == nil || machine.DeletionTimestamp != nil {
+			continue
+		}
+		if _, skipped := machine.Annotations[machinecontroller.ExcludeNodeDrainingAnnotation]; skipped {
+			continue
+		}
+		if err := d.checkMachine(machine); err != nil {
+			klog.Errorf("%v: failed to check the termination of the VMI: %v", machine.Name, err)
+		}
+	}
+}
+
+func (d *InfraEvictionDrain) checkMachine(machine *machinev1.Machine) error {
+	providerSpec, err := codec.DecodeProviderSpec(machine.Spec.ProviderSpec.Value)
+	if err != nil {
+		return err
+	}
+	credentialsSecretName := ""
+	if providerSpec.CredentialsSecret != nil {
+		credentialsSecretName = providerSpec.CredentialsSecret.Name
+	}
+	kubevirtClient, err := d.kubevirtClientBuilder(d.client, credentialsSecretName, machine.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to create kubevirt client: %w", err)
+	}
+	vmi, reason, err := infraTermination(context.Background(), kubevirtClient, machine, providerSpec)
+	if err != nil {
+		return err
+	}
+
+	node := &corev1.Node{}
+	if err := d.client.Get(context.Background(), types.NamespacedName{Name: machine.Status.NodeRef.Name}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %v: %w", machine.Status.NodeRef.Name, err)
+	}
+
+	if reason == "" {
+		evictedVMI, cordoned := node.Annotations[infraEvictionAnnotation]
+		if !cordoned || vmi == nil || vmi.Status.Phase != kubevirtapis.Running || string(vmi.UID) == evictedVMI {
+			return nil
+		}
+		klog.Infof("%v: VMI runs again, uncordoning node %v", machine.Name, node.Name)
+		if err := drain.RunCordonOrUncordon(d.drainer(), node, false); err != nil {
+			return fmt.Errorf("failed to uncordon node %v: %w", node.Name, err)
+		}
+		return d.setEvictedVMI(node, nil)
+	}
+
+	if node.Annotations[infraEvictionAnnotation] != string(vmi.UID) {
+		klog.Infof("%v: %s, cordoning and draining node %v", machine.Name, reason, node.Name)
+		d.eventRecorder.Eventf(machine, corev1.EventTypeWarning, "InfraEviction", "%s, draining node %v", reason, node.Name)
+		uid := string(vmi.UID)
+		if err := d.setEvictedVMI(node, &uid); err != nil {
+			return err
+		}
+	}
+	drainer := d.drainer()
+	if err := drain.RunCordonOrUncordon(drainer, node, true); err != nil {
+		return fmt.Errorf("failed to cordon node %v: %w", node.Name, err)
+	}
+	if err := drain.RunNodeDrain(drainer, node.Name); err != nil {
+		return fmt.Errorf("failed to drain node %v: %w", node.Name, err)
+	}
+	return nil
+}
+
+// setEvictedVMI sets the infra eviction annotation of the Node to the UID, or removes it if nil.
+func (d *InfraEvictionDrain) setEvictedVMI(node *corev1.Node, uid *string) error {
+	patch := runtimeclient.MergeFrom(node.DeepCopy())
+	if uid == nil {
+		delete(node.Annotations, infraEvictionAnnotation)
+	} else {
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[infraEvictionAnnotation] = *uid
+	}
+	if err := d.client.Patch(context.Background(), node, patch); err != nil {
+		return fmt.Errorf("failed to annotate node %v: %w", node.Name, err)
+	}
+	return nil
+}
+
+func (d *InfraEvictionDrain) drainer() *drain.Helper {
+	return &drain.Helper{
+		Client:              d.kubeClient,
+		Force:               true,
+		IgnoreAllDaemonSets: true,
+		DeleteLocalData:     true,
+		GracePeriodSeconds:  -1,
+		Timeout:             infraEvictionDrainTimeout,
+		OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
+			klog.Infof("Evicted pod %s/%s off node %s of terminating VMI", pod.Namespace, pod.Name, pod.Spec.NodeName)
+		},
+		Out:    klogWriter{klog.Info},
+		ErrOut: klogWriter{klog.Error},
+	}
+}
+
+// infraTermination returns the VMI of the machine along with the reason the infra cluster is
+// terminating it, empty if it is not. Live migrations keep the VM running, they are not
+// terminations.
+func infraTermination(ctx context.Context, kubevirtClient kubevirtclient.Client, machine *machinev1.Machine,
+	providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (*kubevirtapis.VirtualMachineInstance, string, error) {
+	vmi, err := kubevirtClient.GetVirtualMachineInstance(ctx, infraNamespace(machine, providerSpec), machine.Name, &metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("failed to get VirtualMachineInstance: %w", err)
+	}
+
+	switch {
+	case vmi.DeletionTimestamp != nil:
+		return vmi, "VirtualMachineInstance is being deleted", nil
+	case vmi.Status.Phase == kubevirtapis.Failed:
+		return vmi, "VirtualMachineInstance failed", nil
+	case vmi.Status.Phase != kubevirtapis.Running:
+		return vmi, "", nil
+	case vmi.Status.MigrationState != nil && !vmi.Status.MigrationState.Completed:
+		return vmi, "", nil
+	}
+
+	pods, err := kubevirtClient.ListPods(ctx, vmi.Namespace, &metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", launcherCreatedByLabel, vmi.UID),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list virt-launcher pods of VirtualMachineInstance: %w", err)
+	}
+	var terminating *corev1.Pod
+	for i := range pods.Items {
+		if pods.Items[i].DeletionTimestamp == nil {
+			return vmi, "", nil
+		}
+		terminating = &pods.Items[i]
+	}
+	if terminating != nil {
+		return vmi, fmt.Sprintf("virt-launcher pod %s is being terminated", terminating.Name), nil
+	}
+	return vmi, "", nil
+}
+
+// klogWriter logs the output of the drain.
+type klogWriter struct {
+	log func(...interface{})
+}
+
+func (w klogWriter) Write(p []byte) (int, error) {
+	w.log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
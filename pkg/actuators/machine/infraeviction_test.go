@@ -0,0 +1,103 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestInfraTermination(t *testing.T) {
+	now := metav1.Now()
+	runningVMI := func() *kubevirtapis.VirtualMachineInstance {
+		return &kubevirtapis.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", UID: "1234"},
+			Status:     kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running},
+		}
+	}
+	pod := func(name string, deleting bool) corev1.Pod {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tenant-a"}}
+		if deleting {
+			pod.DeletionTimestamp = &now
+		}
+		return pod
+	}
+
+	testCases := []struct {
+		testcase     string
+		vmi          func() *kubevirtapis.VirtualMachineInstance
+		pods         []corev1.Pod
+		expectReason string
+	}{
+		{
+			testcase: "running",
+			vmi:      runningVMI,
+			pods:     []corev1.Pod{pod("virt-launcher-worker-abcde-xyz", false)},
+		},
+		{
+			testcase: "VMI being deleted",
+			vmi: func() *kubevirtapis.VirtualMachineInstance {
+				vmi := runningVMI()
+				vmi.DeletionTimestamp = &now
+				return vmi
+			},
+			expectReason: "VirtualMachineInstance is being deleted",
+		},
+		{
+			testcase: "VMI failed",
+			vmi: func() *kubevirtapis.VirtualMachineInstance {
+				vmi := runningVMI()
+				vmi.Status.Phase = kubevirtapis.Failed
+				return vmi
+			},
+			expectReason: "VirtualMachineInstance failed",
+		},
+		{
+			testcase:     "virt-launcher pod evicted",
+			vmi:          runningVMI,
+			pods:         []corev1.Pod{pod("virt-launcher-worker-abcde-xyz", true)},
+			expectReason: "virt-launcher pod virt-launcher-worker-abcde-xyz is being terminated",
+		},
+		{
+			testcase: "live migration",
+			vmi: func() *kubevirtapis.VirtualMachineInstance {
+				vmi := runningVMI()
+				vmi.Status.MigrationState = &kubevirtapis.VirtualMachineInstanceMigrationState{StartTimestamp: &now}
+				return vmi
+			},
+		},
+		{
+			testcase: "source pod of a completed live migration",
+			vmi:      runningVMI,
+			pods:     []corev1.Pod{pod("virt-launcher-worker-abcde-xyz", true), pod("virt-launcher-worker-abcde-uvw", false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			vmi := tc.vmi()
+			client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(vmi, nil)
+			client.EXPECT().ListPods(gomock.Any(), "tenant-a", &metav1.ListOptions{LabelSelector: "kubevirt.io/created-by=1234"}).
+				Return(&corev1.PodList{Items: tc.pods}, nil).AnyTimes()
+
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+			_, reason, err := infraTermination(context.Background(), client, machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if reason != tc.expectReason {
+				t.Errorf("Expected reason %q, got %q", tc.expectReason, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// infraNamespace returns the namespace of the infra cluster the VM of the machine lives in,
+// the InfraNamespace of the provider spec or else the namespace of the machine.
+func infraNamespace(machine *machinev1.Machine, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) string {
+	if providerSpec.InfraNamespace != "" {
+		return providerSpec.InfraNamespace
+	}
+	return machine.Namespace
+}
+
+// validateInfraNamespace returns an error if the InfraNamespace is not a valid namespace
+// name, or is to be created without being set.
+func validateInfraNamespace(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	if providerSpec.InfraNamespace == "" {
+		if providerSpec.CreateInfraNamespace {
+			return fmt.Errorf("createInfraNamespace requires infraNamespace")
+		}
+		return nil
+	}
+	if errs := validation.IsDNS1123Label(providerSpec.InfraNamespace); len(errs) > 0 {
+		return fmt.Errorf("invalid infraNamespace %q: %s", providerSpec.InfraNamespace, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// ensureInfraNamespace creates the InfraNamespace of the machine unless it exists, if
+// requested by the provider spec.
+func (r *Reconciler) ensureInfraNamespace() error {
+	if !r.providerSpec.CreateInfraNamespace || r.providerSpec.InfraNamespace == "" {
+		return nil
+	}
+	name := r.providerSpec.InfraNamespace
+	_, err := r.kubevirtClient.GetNamespace(r.Context, name, &metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get infra namespace %s: %w", name, err)
+	}
+
+	r.log().Info("creating infra namespace", "namespace", name)
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if _, err := r.kubevirtClient.CreateNamespace(r.Context, namespace); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create infra namespace %s: %w", name, err)
+	}
+	return nil
+}
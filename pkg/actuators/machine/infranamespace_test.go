@@ -0,0 +1,122 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestValidateInfraNamespace(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		expectError  bool
+	}{
+		{
+			testcase: "default",
+		},
+		{
+			testcase:     "infra namespace",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "tenant-a", CreateInfraNamespace: true},
+		},
+		{
+			testcase:     "invalid name",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "Tenant.A"},
+			expectError:  true,
+		},
+		{
+			testcase:     "creation without namespace",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{CreateInfraNamespace: true},
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateInfraNamespace(&tc.providerSpec)
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMInfraNamespace(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api"}}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:  "rhcos",
+		InfraNamespace: "tenant-a",
+	}
+	vm, userDataSecret, err := buildVM(machine, providerSpec, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vm.Namespace != "tenant-a" {
+		t.Errorf("Expected the VM in namespace tenant-a, got %q", vm.Namespace)
+	}
+	bootVolume := vm.Spec.DataVolumeTemplates[0]
+	if bootVolume.Namespace != "tenant-a" || bootVolume.Spec.Source.PVC.Namespace != "tenant-a" {
+		t.Errorf("Expected the boot volume cloned in namespace tenant-a, got %q out of %q", bootVolume.Namespace, bootVolume.Spec.Source.PVC.Namespace)
+	}
+	if userDataSecret == nil || userDataSecret.Namespace != "tenant-a" {
+		t.Errorf("Expected the UserData secret in namespace tenant-a, got %v", userDataSecret)
+	}
+}
+
+func TestEnsureInfraNamespace(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "tenant-a")
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		getErr       error
+		expectCreate bool
+	}{
+		{
+			testcase:     "not requested",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "tenant-a"},
+		},
+		{
+			testcase:     "existing",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "tenant-a", CreateInfraNamespace: true},
+		},
+		{
+			testcase:     "missing",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{InfraNamespace: "tenant-a", CreateInfraNamespace: true},
+			getErr:       notFound,
+			expectCreate: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.providerSpec.CreateInfraNamespace {
+				client.EXPECT().GetNamespace(gomock.Any(), "tenant-a", gomock.Any()).Return(&corev1.Namespace{}, tc.getErr)
+			}
+			if tc.expectCreate {
+				client.EXPECT().CreateNamespace(gomock.Any(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}}).Return(&corev1.Namespace{}, nil)
+			}
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api"}},
+				providerSpec:   &tc.providerSpec,
+			})
+			if err := r.ensureInfraNamespace(); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package machine
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// addressClaimPollInterval is the delay between the checks of the IPAddressClaims being bound.
+const addressClaimPollInterval = 10 * time.Second
+
+var (
+	// ipAddressClaimKind is the kind of the claims of addresses of the cluster-api IPAM contract
+	ipAddressClaimKind = schema.GroupVersionKind{Group: "ipam.cluster.x-k8s.io", Version: "v1alpha1", Kind: "IPAddressClaim"}
+	// ipAddressKind is the kind of the addresses IPAM providers bind the claims to
+	ipAddressKind = schema.GroupVersionKind{Group: "ipam.cluster.x-k8s.io", Version: "v1alpha1", Kind: "IPAddress"}
+)
+
+// addressPool is an IP pool an address is claimed out of for an interface of the guest.
+type addressPool struct {
+	claimName string
+	iface     string
+	poolRef   corev1.TypedLocalObjectReference
+}
+
+// addressPools returns the IP pools of the interfaces of the network data, along with the names
+// of their claims, unique to the machine, interface and pool.
+func addressPools(machineName string, networkData *kubevirtproviderv1.NetworkData) []addressPool {
+	if networkData == nil {
+		return nil
+	}
+	var pools []addressPool
+	add := func(iface string, config *kubevirtproviderv1.InterfaceConfig) {
+		for i, poolRef := range config.AddressesFromPools {
+			pools = append(pools, addressPool{
+				claimName: fmt.Sprintf("%s-%s-%d", machineName, iface, i),
+				iface:     iface,
+				poolRef:   poolRef,
+			})
+		}
+	}
+	for i := range networkData.Ethernets {
+		add(networkData.Ethernets[i].Name, &networkData.Ethernets[i].InterfaceConfig)
+	}
+	for i := range networkData.VLANs {
+		add(networkData.VLANs[i].Name, &networkData.VLANs[i].InterfaceConfig)
+	}
+	return pools
+}
+
+// validateAddressPools returns an error if an IP pool reference is incomplete.
+func validateAddressPools(networkData *kubevirtproviderv1.NetworkData) error {
+	for _, pool := range addressPools("", networkData) {
+		if pool.poolRef.APIGroup == nil || *pool.poolRef.APIGroup == "" || pool.poolRef.Kind == "" || pool.poolRef.Name == "" {
+			return fmt.Errorf("interface %q: addressesFromPools must set the apiGroup, kind and name of the pool", pool.iface)
+		}
+	}
+	return nil
+}
+
+// buildAddressClaim renders the IPAddressClaim of the pool, owned by the machine for the address
+// to be released along with it.
+func buildAddressClaim(machine *machinev1.Machine, pool addressPool) *unstructured.Unstructured {
+	claim := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"poolRef": map[string]interface{}{
+				"apiGroup": *pool.poolRef.APIGroup,
+				"kind":     pool.poolRef.Kind,
+				"name":     pool.poolRef.Name,
+			},
+		},
+	}}
+	claim.SetGroupVersionKind(ipAddressClaimKind)
+	claim.SetName(pool.claimName)
+	claim.SetNamespace(machine.Namespace)
+	claim.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(machine, machinev1.SchemeGroupVersion.WithKind("Machine"))})
+	return claim
+}
+
+// allocatedAddress returns the address the IPAddress holds, in CIDR notation, along with its gateway.
+func allocatedAddress(ipAddress *unstructured.Unstructured) (string, string, error) {
+	address, _, _ := unstructured.NestedString(ipAddress.Object, "spec", "address")
+	prefix, _, _ := unstructured.NestedInt64(ipAddress.Object, "spec", "prefix")
+	gateway, _, _ := unstructured.NestedString(ipAddress.Object, "spec", "gateway")
+	cidr := address + "/" + strconv.FormatInt(prefix, 10)
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return "", "", fmt.Errorf("IPAddress %s holds invalid address %q", ipAddress.GetName(), cidr)
+	}
+	if gateway != "" && net.ParseIP(gateway) == nil {
+		return "", "", fmt.Errorf("IPAddress %s holds invalid gateway %q", ipAddress.GetName(), gateway)
+	}
+	return cidr, gateway, nil
+}
+
+// addressesAllocatedCondition reports the allocation of the addresses of the IP pools.
+func addressesAllocatedCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.AddressesAllocated,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// allocateAddresses claims an address out of each IP pool of the network data and records the
+// addresses in the provider status, returning a RequeueAfterError until all claims are bound.
+func (r *Reconciler) allocateAddresses() error {
+	pools := addressPools(r.machine.Name, r.providerSpec.NetworkData)
+	if len(pools) == 0 {
+		return nil
+	}
+	if len(r.providerStatus.AllocatedAddresses) == len(pools) {
+		return nil
+	}
+
+	var allocated []kubevirtproviderv1.AllocatedAddress
+	var pending []string
+	for _, pool := range pools {
+		address, reason, err := r.claimAddress(pool)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			pending = append(pending, reason)
+			continue
+		}
+		allocated = append(allocated, *address)
+	}
+	if len(pending) > 0 {
+		r.machineScope.setProviderStatus(addressesAllocatedCondition(corev1.ConditionFalse, kubevirtproviderv1.AddressClaimPending, strings.Join(pending, ", ")))
+		return &machinecontroller.RequeueAfterError{RequeueAfter: addressClaimPollInterval}
+	}
+
+	r.log().Info("allocated addresses out of IP pools", "addresses", len(allocated))
+	r.providerStatus.AllocatedAddresses = allocated
+	r.machineScope.setProviderStatus(addressesAllocatedCondition(corev1.ConditionTrue, kubevirtproviderv1.AddressClaimsBound,
+		fmt.Sprintf("%d addresses allocated out of IP pools", len(allocated))))
+	return nil
+}
+
+// claimAddress creates the IPAddressClaim of the pool and returns its address once bound, or else
+// the reason it is pending.
+func (r *Reconciler) claimAddress(pool addressPool) (*kubevirtproviderv1.AllocatedAddress, string, error) {
+	claim := &unstructured.Unstructured{}
+	claim.SetGroupVersionKind(ipAddressClaimKind)
+	err := r.client.Get(r.Context, types.NamespacedName{Namespace: r.machine.Namespace, Name: pool.claimName}, claim)
+	if apierrors.IsNotFound(err) {
+		r.log().Info("claiming address out of IP pool", "claim", pool.claimName, "pool", pool.poolRef.Name, "interface", pool.iface)
+		if err := r.client.Create(r.Context, buildAddressClaim(r.machine, pool)); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, "", fmt.Errorf("failed to create IPAddressClaim %s: %w", pool.claimName, err)
+		}
+		return nil, fmt.Sprintf("Waiting for IPAddressClaim %s to be bound", pool.claimName), nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get IPAddressClaim %s: %w", pool.claimName, err)
+	}
+
+	addressName, _, _ := unstructured.NestedString(claim.Object, "status", "addressRef", "name")
+	if addressName == "" {
+		return nil, fmt.Sprintf("Waiting for IPAddressClaim %s to be bound", pool.claimName), nil
+	}
+	ipAddress := &unstructured.Unstructured{}
+	ipAddress.SetGroupVersionKind(ipAddressKind)
+	if err := r.client.Get(r.Context, types.NamespacedName{Namespace: r.machine.Namespace, Name: addressName}, ipAddress); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Sprintf("Waiting for IPAddress %s of IPAddressClaim %s", addressName, pool.claimName), nil
+		}
+		return nil, "", fmt.Errorf("failed to get IPAddress %s: %w", addressName, err)
+	}
+	address, gateway, err := allocatedAddress(ipAddress)
+	if err != nil {
+		return nil, "", machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+	return &kubevirtproviderv1.AllocatedAddress{
+		Interface: pool.iface,
+		Claim:     pool.claimName,
+		Address:   address,
+		Gateway:   gateway,
+	}, "", nil
+}
+
+// applyAllocatedAddresses adds the allocated addresses to the static addresses of their
+// interface in the network data, along with a default route through their gateway, and to the
+// static IP addresses passed to the kubelet as node IPs. It only changes the decoded provider
+// spec, the addresses are kept in the provider status.
+func applyAllocatedAddresses(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, addresses []kubevirtproviderv1.AllocatedAddress) {
+	if providerSpec.NetworkData == nil {
+		return
+	}
+	for _, address := range addresses {
+		config := interfaceConfig(providerSpec.NetworkData, address.Interface)
+		if config == nil {
+			continue
+		}
+		ip, _, err := net.ParseCIDR(address.Address)
+		if err != nil {
+			continue
+		}
+		config.Addresses = appendMissing(config.Addresses, address.Address)
+		providerSpec.StaticIPAddresses = appendMissing(providerSpec.StaticIPAddresses, ip.String())
+
+		if address.Gateway == "" {
+			continue
+		}
+		defaultRoute := "0.0.0.0/0"
+		if ip.To4() == nil {
+			defaultRoute = "::/0"
+		}
+		hasDefaultRoute := false
+		for _, route := range config.Routes {
+			hasDefaultRoute = hasDefaultRoute || route.To == defaultRoute
+		}
+		if !hasDefaultRoute {
+			config.Routes = append(config.Routes, kubevirtproviderv1.Route{To: defaultRoute, Via: address.Gateway})
+		}
+	}
+}
+
+// interfaceConfig returns the configuration of the named interface of the network data, nil if
+// it has no such interface.
+func interfaceConfig(networkData *kubevirtproviderv1.NetworkData, name string) *kubevirtproviderv1.InterfaceConfig {
+	for i := range networkData.Ethernets {
+		if networkData.Ethernets[i].Name == name {
+			return &networkData.Ethernets[i].InterfaceConfig
+		}
+	}
+	for i := range networkData.VLANs {
+		if networkData.VLANs[i].Name == name {
+			return &networkData.VLANs[i].InterfaceConfig
+		}
+	}
+	return nil
+}
+
+func appendMissing(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
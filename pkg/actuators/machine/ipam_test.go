@@ -0,0 +1,143 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func poolNetworkData(poolName string) *kubevirtproviderv1.NetworkData {
+	return &kubevirtproviderv1.NetworkData{
+		Ethernets: []kubevirtproviderv1.EthernetInterface{{
+			Name: "eth0",
+			InterfaceConfig: kubevirtproviderv1.InterfaceConfig{
+				AddressesFromPools: []corev1.TypedLocalObjectReference{{
+					APIGroup: pointer.StringPtr("ipam.cluster.x-k8s.io"),
+					Kind:     "InClusterIPPool",
+					Name:     poolName,
+				}},
+			},
+		}},
+	}
+}
+
+func TestValidateAddressPools(t *testing.T) {
+	if err := validateAddressPools(poolNetworkData("workers")); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	networkData := poolNetworkData("workers")
+	networkData.Ethernets[0].AddressesFromPools[0].APIGroup = nil
+	if err := validateAddressPools(networkData); err == nil {
+		t.Error("Expected an error for a pool without apiGroup")
+	}
+	if err := validateAddressPools(poolNetworkData("")); err == nil {
+		t.Error("Expected an error for a pool without name")
+	}
+}
+
+func TestApplyAllocatedAddresses(t *testing.T) {
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{NetworkData: poolNetworkData("workers")}
+	addresses := []kubevirtproviderv1.AllocatedAddress{
+		{Interface: "eth0", Claim: "worker-abcde-eth0-0", Address: "10.0.0.5/24", Gateway: "10.0.0.1"},
+		{Interface: "eth0", Claim: "worker-abcde-eth0-1", Address: "fd00::5/64"},
+	}
+
+	// Reconciling again must not duplicate the addresses
+	applyAllocatedAddresses(providerSpec, addresses)
+	applyAllocatedAddresses(providerSpec, addresses)
+
+	config := providerSpec.NetworkData.Ethernets[0].InterfaceConfig
+	if expected := []string{"10.0.0.5/24", "fd00::5/64"}; !reflect.DeepEqual(config.Addresses, expected) {
+		t.Errorf("Expected addresses %v, got %v", expected, config.Addresses)
+	}
+	if expected := []kubevirtproviderv1.Route{{To: "0.0.0.0/0", Via: "10.0.0.1"}}; !reflect.DeepEqual(config.Routes, expected) {
+		t.Errorf("Expected routes %v, got %v", expected, config.Routes)
+	}
+	if expected := []string{"10.0.0.5", "fd00::5"}; !reflect.DeepEqual(providerSpec.StaticIPAddresses, expected) {
+		t.Errorf("Expected static IP addresses %v, got %v", expected, providerSpec.StaticIPAddresses)
+	}
+}
+
+func TestAllocateAddresses(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api", UID: "machine-uid"},
+	}
+	newAllocationReconciler := func(objects ...runtime.Object) (*Reconciler, *kubevirtproviderv1.KubevirtMachineProviderStatus) {
+		providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{}
+		return newReconciler(&machineScope{
+			Context:        context.Background(),
+			client:         fake.NewFakeClientWithScheme(runtime.NewScheme(), objects...),
+			machine:        machine,
+			providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{NetworkData: poolNetworkData("workers")},
+			providerStatus: providerStatus,
+		}), providerStatus
+	}
+
+	t.Run("claim pending", func(t *testing.T) {
+		r, providerStatus := newAllocationReconciler()
+		err := r.allocateAddresses()
+		var requeueErr *machinecontroller.RequeueAfterError
+		if !errors.As(err, &requeueErr) {
+			t.Fatalf("Expected a RequeueAfterError, got %v", err)
+		}
+
+		claim := &unstructured.Unstructured{}
+		claim.SetGroupVersionKind(ipAddressClaimKind)
+		if err := r.client.Get(context.Background(), types.NamespacedName{Namespace: machine.Namespace, Name: "worker-abcde-eth0-0"}, claim); err != nil {
+			t.Fatalf("Expected the IPAddressClaim to be created: %v", err)
+		}
+		if poolName, _, _ := unstructured.NestedString(claim.Object, "spec", "poolRef", "name"); poolName != "workers" {
+			t.Errorf("Expected the claim of pool workers, got %q", poolName)
+		}
+		if owners := claim.GetOwnerReferences(); len(owners) != 1 || owners[0].UID != machine.UID {
+			t.Errorf("Expected the claim to be owned by the machine, got %v", owners)
+		}
+		condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.AddressesAllocated)
+		if condition == nil || condition.Reason != kubevirtproviderv1.AddressClaimPending {
+			t.Errorf("Expected the %s condition with reason %s, got %v", kubevirtproviderv1.AddressesAllocated, kubevirtproviderv1.AddressClaimPending, condition)
+		}
+	})
+
+	t.Run("claim bound", func(t *testing.T) {
+		claim := buildAddressClaim(machine, addressPools(machine.Name, poolNetworkData("workers"))[0])
+		if err := unstructured.SetNestedField(claim.Object, "worker-abcde-eth0-0", "status", "addressRef", "name"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ipAddress := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"address": "10.0.0.5",
+				"prefix":  int64(24),
+				"gateway": "10.0.0.1",
+			},
+		}}
+		ipAddress.SetGroupVersionKind(ipAddressKind)
+		ipAddress.SetName("worker-abcde-eth0-0")
+		ipAddress.SetNamespace(machine.Namespace)
+
+		r, providerStatus := newAllocationReconciler(claim, ipAddress)
+		if err := r.allocateAddresses(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []kubevirtproviderv1.AllocatedAddress{{Interface: "eth0", Claim: "worker-abcde-eth0-0", Address: "10.0.0.5/24", Gateway: "10.0.0.1"}}
+		if !reflect.DeepEqual(providerStatus.AllocatedAddresses, expected) {
+			t.Errorf("Expected allocated addresses %v, got %v", expected, providerStatus.AllocatedAddresses)
+		}
+		condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.AddressesAllocated)
+		if condition == nil || condition.Status != corev1.ConditionTrue {
+			t.Errorf("Expected the %s condition to be true, got %v", kubevirtproviderv1.AddressesAllocated, condition)
+		}
+	})
+}
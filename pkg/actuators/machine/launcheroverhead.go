@@ -0,0 +1,169 @@
+package machine
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// defaultLauncherCPUOverhead is the CPU of virt-launcher, libvirt and QEMU when not set
+	defaultLauncherCPUOverhead = "100m"
+
+	// The memory overhead KubeVirt adds to the memory request of the virt-launcher pod: the
+	// launcher, libvirt and QEMU, the CPU tables of each vCPU, the video memory, and the page
+	// tables of the guest memory, one byte per 512.
+	launcherFixedMemoryOverhead    = "128M"
+	launcherVCPUMemoryOverhead     = "8Mi"
+	launcherGraphicsMemoryOverhead = "16Mi"
+	launcherPageTableRatio         = 512
+)
+
+// launcherCPUOverhead returns the CPU overhead of the virt-launcher pod.
+func launcherCPUOverhead(overhead *kubevirtproviderv1.LauncherOverhead) (resource.Quantity, error) {
+	cpu := overhead.CPU
+	if cpu == "" {
+		cpu = defaultLauncherCPUOverhead
+	}
+	quantity, err := resource.ParseQuantity(cpu)
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("invalid cpu %q: %v", cpu, err)
+	}
+	return quantity, nil
+}
+
+// launcherMemoryOverhead returns the memory overhead of the virt-launcher pod of a guest of
+// the memory and vCPUs, the estimate of KubeVirt if not set.
+func launcherMemoryOverhead(overhead *kubevirtproviderv1.LauncherOverhead, guestMemory resource.Quantity, vcpus uint32) (resource.Quantity, error) {
+	if overhead.Memory != "" {
+		quantity, err := resource.ParseQuantity(overhead.Memory)
+		if err != nil {
+			return resource.Quantity{}, fmt.Errorf("invalid memory %q: %v", overhead.Memory, err)
+		}
+		return quantity, nil
+	}
+
+	estimate := resource.NewQuantity(guestMemory.Value()/launcherPageTableRatio, resource.BinarySI)
+	estimate.Add(resource.MustParse(launcherFixedMemoryOverhead))
+	for i := uint32(0); i < vcpus; i++ {
+		estimate.Add(resource.MustParse(launcherVCPUMemoryOverhead))
+	}
+	estimate.Add(resource.MustParse(launcherGraphicsMemoryOverhead))
+	return *estimate, nil
+}
+
+// validateLauncherOverhead returns an error if the overhead is malformed, negative or
+// combined with settings owning the requests of the VM.
+func validateLauncherOverhead(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	if providerSpec.DedicatedCPUPlacement {
+		return fmt.Errorf("launcherOverhead cannot be combined with dedicatedCpuPlacement, which requires CPU requests equal to the vCPUs")
+	}
+	if providerSpec.OvercommitGuestOverhead {
+		return fmt.Errorf("launcherOverhead cannot be combined with overcommitGuestOverhead")
+	}
+	cpu, err := launcherCPUOverhead(providerSpec.LauncherOverhead)
+	if err != nil {
+		return err
+	}
+	memory, err := launcherMemoryOverhead(providerSpec.LauncherOverhead, resource.Quantity{}, 0)
+	if err != nil {
+		return err
+	}
+	if cpu.Sign() < 0 || memory.Sign() < 0 {
+		return fmt.Errorf("launcherOverhead must not be negative")
+	}
+	return nil
+}
+
+// applyLauncherOverhead adds the overhead of the virt-launcher pod to the requests of the VMI
+// template, after any overcommit. The memory seen by the guest is kept, and KubeVirt no longer
+// adds its own memory overhead to the request. KubeVirt adds it to the memory limit still.
+func applyLauncherOverhead(spec *kubevirtapis.VirtualMachineInstanceSpec, overhead *kubevirtproviderv1.LauncherOverhead) error {
+	resources := &spec.Domain.Resources
+	if resources.Requests == nil {
+		resources.Requests = corev1.ResourceList{}
+	}
+	var vcpus uint32
+	if spec.Domain.CPU != nil {
+		vcpus = spec.Domain.CPU.Cores
+	}
+
+	cpuOverhead, err := launcherCPUOverhead(overhead)
+	if err != nil {
+		return err
+	}
+	cpu, ok := resources.Requests[corev1.ResourceCPU]
+	if !ok {
+		cpu = *resource.NewQuantity(int64(vcpus), resource.DecimalSI)
+	}
+	cpu.Add(cpuOverhead)
+	resources.Requests[corev1.ResourceCPU] = cpu
+	if limit, ok := resources.Limits[corev1.ResourceCPU]; ok {
+		limit.Add(cpuOverhead)
+		resources.Limits[corev1.ResourceCPU] = limit
+	}
+
+	memory, ok := resources.Requests[corev1.ResourceMemory]
+	if !ok {
+		return nil
+	}
+	if spec.Domain.Memory == nil {
+		spec.Domain.Memory = &kubevirtapis.Memory{}
+	}
+	if spec.Domain.Memory.Guest == nil {
+		guest := memory.DeepCopy()
+		spec.Domain.Memory.Guest = &guest
+	}
+	memoryOverhead, err := launcherMemoryOverhead(overhead, *spec.Domain.Memory.Guest, vcpus)
+	if err != nil {
+		return err
+	}
+	memory.Add(memoryOverhead)
+	resources.Requests[corev1.ResourceMemory] = memory
+	resources.OvercommitGuestOverhead = true
+	return nil
+}
+
+// InfraFootprint returns the CPU and memory a machine of the provider spec consumes on the
+// infra cluster, the resources of its guest plus the overhead of its virt-launcher pod, before
+// any overcommit. The provider spec must set a launcher overhead.
+func InfraFootprint(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (resource.Quantity, resource.Quantity, error) {
+	if providerSpec.LauncherOverhead == nil {
+		return resource.Quantity{}, resource.Quantity{}, fmt.Errorf("launcherOverhead is not set")
+	}
+	requestedMemory := providerSpec.RequestedMemory
+	if requestedMemory == "" {
+		requestedMemory = defaultRequestedMemory
+	}
+	memory, err := resource.ParseQuantity(requestedMemory)
+	if err != nil {
+		return resource.Quantity{}, resource.Quantity{}, fmt.Errorf("invalid requestedMemory %q: %v", requestedMemory, err)
+	}
+	guestMemory := memory
+	if providerSpec.GuestMemory != "" {
+		if guestMemory, err = resource.ParseQuantity(providerSpec.GuestMemory); err != nil {
+			return resource.Quantity{}, resource.Quantity{}, fmt.Errorf("invalid guestMemory %q: %v", providerSpec.GuestMemory, err)
+		}
+	}
+	vcpus := providerSpec.RequestedCPU
+	if vcpus == 0 {
+		vcpus = defaultRequestedCPU
+	}
+
+	cpu := *resource.NewQuantity(int64(vcpus), resource.DecimalSI)
+	cpuOverhead, err := launcherCPUOverhead(providerSpec.LauncherOverhead)
+	if err != nil {
+		return resource.Quantity{}, resource.Quantity{}, err
+	}
+	cpu.Add(cpuOverhead)
+	memoryOverhead, err := launcherMemoryOverhead(providerSpec.LauncherOverhead, guestMemory, vcpus)
+	if err != nil {
+		return resource.Quantity{}, resource.Quantity{}, err
+	}
+	memory.Add(memoryOverhead)
+	return cpu, memory, nil
+}
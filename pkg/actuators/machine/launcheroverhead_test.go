@@ -0,0 +1,134 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateLauncherOverhead(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		expectError  bool
+	}{
+		{
+			testcase:     "defaults",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{LauncherOverhead: &kubevirtproviderv1.LauncherOverhead{}},
+		},
+		{
+			testcase:     "overhead",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{LauncherOverhead: &kubevirtproviderv1.LauncherOverhead{CPU: "250m", Memory: "300Mi"}},
+		},
+		{
+			testcase:     "invalid memory",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{LauncherOverhead: &kubevirtproviderv1.LauncherOverhead{Memory: "lots"}},
+			expectError:  true,
+		},
+		{
+			testcase:     "negative CPU",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{LauncherOverhead: &kubevirtproviderv1.LauncherOverhead{CPU: "-100m"}},
+			expectError:  true,
+		},
+		{
+			testcase: "dedicated CPU placement",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				LauncherOverhead:      &kubevirtproviderv1.LauncherOverhead{},
+				DedicatedCPUPlacement: true,
+			},
+			expectError: true,
+		},
+		{
+			testcase: "overcommitted guest overhead",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				LauncherOverhead:        &kubevirtproviderv1.LauncherOverhead{},
+				OvercommitGuestOverhead: true,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateLauncherOverhead(&tc.providerSpec)
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestLauncherMemoryOverheadEstimate(t *testing.T) {
+	overhead, err := launcherMemoryOverhead(&kubevirtproviderv1.LauncherOverhead{}, resource.MustParse("2048M"), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 4M of page tables, 128M fixed, 2 x 8Mi of vCPUs and 16Mi of video memory
+	if expected := int64(4000000 + 128000000 + 32*1024*1024); overhead.Value() != expected {
+		t.Errorf("Expected an overhead of %d, got %d", expected, overhead.Value())
+	}
+}
+
+func TestRenderVMLauncherOverhead(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	testCases := []struct {
+		testcase          string
+		overcommitProfile *OvercommitProfile
+		expectedCPU       string
+		expectedMemory    string
+		expectedCPULimit  string
+	}{
+		{
+			testcase:       "guest resources",
+			expectedCPU:    "2200m",
+			expectedMemory: "4352Mi",
+		},
+		{
+			testcase:          "overcommitted",
+			overcommitProfile: &OvercommitProfile{CPURatio: 4, MemoryRatio: 2, Guaranteed: true},
+			expectedCPU:       "700m",
+			expectedMemory:    "2304Mi",
+			expectedCPULimit:  "700m",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:    "rhcos",
+				RequestedCPU:     2,
+				RequestedMemory:  "4Gi",
+				LauncherOverhead: &kubevirtproviderv1.LauncherOverhead{CPU: "200m", Memory: "256Mi"},
+			}
+			vm, _, err := renderVM(context.Background(), machine, providerSpec, tc.overcommitProfile, nil, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			spec := vm.Spec.Template.Spec
+			requests := spec.Domain.Resources.Requests
+			if cpu := requests[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse(tc.expectedCPU)) != 0 {
+				t.Errorf("Expected a CPU request of %s, got %s", tc.expectedCPU, cpu.String())
+			}
+			if memory := requests[corev1.ResourceMemory]; memory.Cmp(resource.MustParse(tc.expectedMemory)) != 0 {
+				t.Errorf("Expected a memory request of %s, got %s", tc.expectedMemory, memory.String())
+			}
+			if tc.expectedCPULimit != "" {
+				if limit := spec.Domain.Resources.Limits[corev1.ResourceCPU]; limit.Cmp(resource.MustParse(tc.expectedCPULimit)) != 0 {
+					t.Errorf("Expected a CPU limit of %s, got %s", tc.expectedCPULimit, limit.String())
+				}
+			}
+			if spec.Domain.Memory == nil || spec.Domain.Memory.Guest == nil || spec.Domain.Memory.Guest.Cmp(resource.MustParse("4Gi")) != 0 {
+				t.Errorf("Expected the guest to keep 4Gi of memory, got %v", spec.Domain.Memory)
+			}
+			if !spec.Domain.Resources.OvercommitGuestOverhead {
+				t.Error("Expected KubeVirt not to add its own memory overhead")
+			}
+		})
+	}
+}
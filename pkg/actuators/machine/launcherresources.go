@@ -0,0 +1,212 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// launcherComputeContainer is the container of the virt-launcher pod running the VM.
+	launcherComputeContainer = "compute"
+
+	// launcherCreatedByLabel is set by KubeVirt on the virt-launcher pod to the UID of its
+	// VirtualMachineInstance.
+	launcherCreatedByLabel = "kubevirt.io/created-by"
+)
+
+// launcherLimits returns the virt-launcher limits of the provider spec, nil if it sets none.
+func launcherLimits(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (corev1.ResourceList, error) {
+	resources := providerSpec.LauncherResources
+	if resources == nil {
+		return nil, nil
+	}
+
+	limits := corev1.ResourceList{}
+	if resources.CPULimit != "" {
+		cpu, err := resource.ParseQuantity(resources.CPULimit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpuLimit %q: %v", resources.CPULimit, err)
+		}
+		limits[corev1.ResourceCPU] = cpu
+	}
+	if resources.MemoryLimit != "" {
+		memory, err := resource.ParseQuantity(resources.MemoryLimit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memoryLimit %q: %v", resources.MemoryLimit, err)
+		}
+		limits[corev1.ResourceMemory] = memory
+	}
+	return limits, nil
+}
+
+// validateLauncherResources returns an error if the virt-launcher limits are malformed or
+// below the memory seen by the guest.
+func validateLauncherResources(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	if providerSpec.DedicatedCPUPlacement {
+		return fmt.Errorf("launcherResources cannot be combined with dedicatedCpuPlacement, which requires limits equal to requests")
+	}
+	limits, err := launcherLimits(providerSpec)
+	if err != nil {
+		return err
+	}
+
+	memoryLimit, ok := limits[corev1.ResourceMemory]
+	if !ok {
+		return nil
+	}
+	requestedMemory := providerSpec.RequestedMemory
+	if requestedMemory == "" {
+		requestedMemory = defaultRequestedMemory
+	}
+	memory, err := resource.ParseQuantity(requestedMemory)
+	if err != nil {
+		return fmt.Errorf("invalid requestedMemory %q: %v", requestedMemory, err)
+	}
+	if memoryLimit.Cmp(memory) < 0 {
+		return fmt.Errorf("memoryLimit %s is below requestedMemory %s", memoryLimit.String(), requestedMemory)
+	}
+	return nil
+}
+
+// setLauncherLimits sets the virt-launcher limits in the VMI template and keeps the memory seen
+// by the guest at the requested memory, rather than at the limit.
+func setLauncherLimits(spec *kubevirtapis.VirtualMachineInstanceSpec, limits corev1.ResourceList) {
+	spec.Domain.Resources.Limits = limits
+	memory, ok := spec.Domain.Resources.Requests[corev1.ResourceMemory]
+	if _, limited := limits[corev1.ResourceMemory]; !ok || !limited {
+		return
+	}
+	if spec.Domain.Memory == nil {
+		spec.Domain.Memory = &kubevirtapis.Memory{}
+	}
+	guest := memory.DeepCopy()
+	spec.Domain.Memory.Guest = &guest
+}
+
+// launcherPodLimits returns the limits of the compute container of the virt-launcher pod for the
+// new VMI limits. KubeVirt adds the memory overhead of the launcher to the VMI memory, which
+// is carried over from the memory request of the container.
+func launcherPodLimits(vmi *kubevirtapis.VirtualMachineInstance, container *corev1.Container, limits corev1.ResourceList) corev1.ResourceList {
+	podLimits := corev1.ResourceList{}
+	for name, limit := range limits {
+		podLimits[name] = limit.DeepCopy()
+	}
+
+	memoryLimit, ok := podLimits[corev1.ResourceMemory]
+	vmiRequest, hasVMIRequest := vmi.Spec.Domain.Resources.Requests[corev1.ResourceMemory]
+	podRequest, hasPodRequest := container.Resources.Requests[corev1.ResourceMemory]
+	if ok && hasVMIRequest && hasPodRequest && podRequest.Cmp(vmiRequest) > 0 {
+		podRequest.Sub(vmiRequest)
+		memoryLimit.Add(podRequest)
+		podLimits[corev1.ResourceMemory] = memoryLimit
+	}
+	return podLimits
+}
+
+// getLauncherPod returns the virt-launcher pod of the VMI, nil if it is not found.
+func (r *Reconciler) getLauncherPod(vmi *kubevirtapis.VirtualMachineInstance) (*corev1.Pod, error) {
+	pods, err := r.kubevirtClient.ListPods(r.Context, vmi.Namespace, &metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", launcherCreatedByLabel, vmi.UID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list virt-launcher pods of VirtualMachineInstance: %w", err)
+	}
+	for i := range pods.Items {
+		if pods.Items[i].DeletionTimestamp == nil {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// reconcileLauncherResources applies the virt-launcher limits of the provider spec to the VM
+// template, then to the running virt-launcher pod, falling back to the next restart of the VM
+// if the infra cluster does not support in-place pod resize.
+func (r *Reconciler) reconcileLauncherResources(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) (*kubevirtapis.VirtualMachine, error) {
+	limits, err := launcherLimits(r.providerSpec)
+	if err != nil {
+		return vm, err
+	}
+	if limits == nil {
+		r.providerStatus.LauncherResources = nil
+		return vm, nil
+	}
+
+	if !equality.Semantic.DeepEqual(vm.Spec.Template.Spec.Domain.Resources.Limits, limits) {
+		updated := vm.DeepCopy()
+		setLauncherLimits(&updated.Spec.Template.Spec, limits)
+		if vm, err = r.kubevirtClient.UpdateVirtualMachine(r.Context, updated.Namespace, updated); err != nil {
+			return vm, fmt.Errorf("failed to update virt-launcher limits of VirtualMachine: %w", err)
+		}
+	}
+
+	if vmi == nil || vmi.Status.Phase != kubevirtapis.Running || equality.Semantic.DeepEqual(vmi.Spec.Domain.Resources.Limits, limits) {
+		return vm, nil
+	}
+	pod, err := r.getLauncherPod(vmi)
+	if err != nil || pod == nil {
+		return vm, err
+	}
+	status := r.providerStatus.LauncherResources
+	if status != nil && status.PodName == pod.Name && status.CPULimit == r.providerSpec.LauncherResources.CPULimit && status.MemoryLimit == r.providerSpec.LauncherResources.MemoryLimit {
+		return vm, nil
+	}
+
+	var container *corev1.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == launcherComputeContainer {
+			container = &pod.Spec.Containers[i]
+		}
+	}
+	if container == nil {
+		return vm, fmt.Errorf("virt-launcher pod %s has no %s container", pod.Name, launcherComputeContainer)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []map[string]interface{}{
+				{
+					"name": launcherComputeContainer,
+					"resources": map[string]interface{}{
+						"limits": launcherPodLimits(vmi, container, limits),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return vm, err
+	}
+
+	status = &kubevirtproviderv1.LauncherResourcesStatus{
+		PodName:     pod.Name,
+		CPULimit:    r.providerSpec.LauncherResources.CPULimit,
+		MemoryLimit: r.providerSpec.LauncherResources.MemoryLimit,
+	}
+	_, err = r.kubevirtClient.PatchPod(r.Context, pod.Namespace, pod.Name, types.StrategicMergePatchType, patch)
+	switch {
+	case err == nil:
+		klog.Infof("%s: resized virt-launcher pod %s in place", r.machine.Name, pod.Name)
+		status.Path = kubevirtproviderv1.LauncherResizeInPlace
+		status.Message = fmt.Sprintf("Resized running virt-launcher pod %s", pod.Name)
+	case apierrors.IsInvalid(err) || apierrors.IsForbidden(err):
+		klog.Infof("%s: infra cluster refused to resize virt-launcher pod %s in place: %v", r.machine.Name, pod.Name, err)
+		status.Path = kubevirtproviderv1.LauncherResizeOnRestart
+		status.Message = "Infra cluster does not support in-place pod resize, the limits apply from the next restart of the VM"
+	default:
+		return vm, fmt.Errorf("failed to resize virt-launcher pod %s: %w", pod.Name, err)
+	}
+	r.providerStatus.LauncherResources = status
+	return vm, nil
+}
@@ -0,0 +1,154 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestBuildVMLauncherResources(t *testing.T) {
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:     "rhcos",
+		RequestedMemory:   "2Gi",
+		LauncherResources: &kubevirtproviderv1.LauncherResources{CPULimit: "3", MemoryLimit: "3Gi"},
+	}
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "kubevirt-test"}}
+
+	vm, _, err := buildVM(machine, providerSpec, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	domain := vm.Spec.Template.Spec.Domain
+	if limit := domain.Resources.Limits[corev1.ResourceMemory]; limit.String() != "3Gi" {
+		t.Errorf("Expected memory limit 3Gi, got %s", limit.String())
+	}
+	if domain.Memory == nil || domain.Memory.Guest == nil || domain.Memory.Guest.String() != "2Gi" {
+		t.Errorf("Expected the guest to keep 2Gi of memory, got %v", domain.Memory)
+	}
+
+	invalid := providerSpec.DeepCopy()
+	invalid.LauncherResources.MemoryLimit = "1Gi"
+	if errs := ValidateProviderSpec(invalid, field.NewPath("providerSpec")); len(errs) == 0 {
+		t.Errorf("Expected error for a memory limit below the requested memory, got none")
+	}
+	invalid = providerSpec.DeepCopy()
+	invalid.DedicatedCPUPlacement = true
+	if errs := ValidateProviderSpec(invalid, field.NewPath("providerSpec")); len(errs) == 0 {
+		t.Errorf("Expected error for launcher limits with dedicated CPU placement, got none")
+	}
+}
+
+func TestReconcileLauncherResources(t *testing.T) {
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		RequestedMemory:   "2Gi",
+		LauncherResources: &kubevirtproviderv1.LauncherResources{MemoryLimit: "3Gi"},
+	}
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+		Spec: kubevirtapis.VirtualMachineSpec{
+			Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{},
+		},
+	}
+	vmi := &kubevirtapis.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", UID: "1234"},
+		Spec: kubevirtapis.VirtualMachineInstanceSpec{
+			Domain: kubevirtapis.DomainSpec{
+				Resources: kubevirtapis.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+			},
+		},
+		Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running},
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "virt-launcher-worker-abcde-xyz", Namespace: "tenant-a"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: launcherComputeContainer,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2304Mi")},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		testcase     string
+		patchError   error
+		expectedPath kubevirtproviderv1.LauncherResizePath
+	}{
+		{
+			testcase:     "in-place resize",
+			expectedPath: kubevirtproviderv1.LauncherResizeInPlace,
+		},
+		{
+			testcase:     "in-place resize not supported",
+			patchError:   apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, pod.Name, field.ErrorList{field.Forbidden(field.NewPath("spec"), "pod updates may not change fields other than ...")}),
+			expectedPath: kubevirtproviderv1.LauncherResizeOnRestart,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, updated *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+				return updated, nil
+			})
+			client.EXPECT().ListPods(gomock.Any(), "tenant-a", &metav1.ListOptions{LabelSelector: "kubevirt.io/created-by=1234"}).Return(&corev1.PodList{Items: []corev1.Pod{pod}}, nil)
+			client.EXPECT().PatchPod(gomock.Any(), "tenant-a", pod.Name, types.StrategicMergePatchType, gomock.Any()).DoAndReturn(func(_ context.Context, namespace, name string, patchType types.PatchType, data []byte) (*corev1.Pod, error) {
+				patch := struct {
+					Spec corev1.PodSpec `json:"spec"`
+				}{}
+				if err := json.Unmarshal(data, &patch); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				// The 256Mi of launcher overhead are kept on top of the new limit
+				if limit := patch.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]; limit.Cmp(resource.MustParse("3328Mi")) != 0 {
+					t.Errorf("Expected memory limit 3328Mi, got %s", limit.String())
+				}
+				return &pod, tc.patchError
+			})
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}},
+				providerSpec:   providerSpec,
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			if _, err := r.reconcileLauncherResources(vm, vmi); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			status := r.providerStatus.LauncherResources
+			if status == nil || status.Path != tc.expectedPath || status.PodName != pod.Name {
+				t.Errorf("Expected resize path %s for pod %s, got %v", tc.expectedPath, pod.Name, status)
+			}
+
+			// Once recorded, the resize is not attempted again for the same pod
+			updated := vm.DeepCopy()
+			setLauncherLimits(&updated.Spec.Template.Spec, corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("3Gi")})
+			client.EXPECT().ListPods(gomock.Any(), "tenant-a", gomock.Any()).Return(&corev1.PodList{Items: []corev1.Pod{pod}}, nil)
+			if _, err := r.reconcileLauncherResources(updated, vmi); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package machine
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// seccompLocalhostPrefix prefixes the path of Localhost profiles in the seccomp annotations.
+	seccompLocalhostPrefix = "localhost/"
+	// seccompUnconfined is the seccomp annotation value applying no profile.
+	seccompUnconfined = "unconfined"
+)
+
+// validateLauncherSecurity returns an error if the seccomp profile is unsupported or its
+// Localhost profile is not a path within the seccomp profile root.
+func validateLauncherSecurity(launcherSecurity *kubevirtproviderv1.LauncherSecurity) error {
+	profile := launcherSecurity.SeccompProfile
+	if profile == nil {
+		return nil
+	}
+
+	switch profile.Type {
+	case kubevirtproviderv1.SeccompProfileTypeLocalhost:
+		if profile.LocalhostProfile == "" {
+			return fmt.Errorf("seccompProfile localhostProfile must be specified with type %q", profile.Type)
+		}
+		if path.IsAbs(profile.LocalhostProfile) || strings.HasPrefix(path.Clean(profile.LocalhostProfile), "..") {
+			return fmt.Errorf("seccompProfile localhostProfile %q must be a path relative to the seccomp profile root", profile.LocalhostProfile)
+		}
+		return nil
+	case kubevirtproviderv1.SeccompProfileTypeRuntimeDefault, kubevirtproviderv1.SeccompProfileTypeUnconfined:
+	default:
+		return fmt.Errorf("unsupported seccompProfile type %q, must be one of %q, %q or %q", profile.Type,
+			kubevirtproviderv1.SeccompProfileTypeRuntimeDefault, kubevirtproviderv1.SeccompProfileTypeLocalhost, kubevirtproviderv1.SeccompProfileTypeUnconfined)
+	}
+	if profile.LocalhostProfile != "" {
+		return fmt.Errorf("seccompProfile localhostProfile requires type %q", kubevirtproviderv1.SeccompProfileTypeLocalhost)
+	}
+	return nil
+}
+
+// seccompAnnotationValue returns the value of the seccomp annotations selecting the profile.
+func seccompAnnotationValue(profile *kubevirtproviderv1.SeccompProfile) string {
+	switch profile.Type {
+	case kubevirtproviderv1.SeccompProfileTypeLocalhost:
+		return seccompLocalhostPrefix + profile.LocalhostProfile
+	case kubevirtproviderv1.SeccompProfileTypeUnconfined:
+		return seccompUnconfined
+	default:
+		return corev1.SeccompProfileRuntimeDefault
+	}
+}
+
+// setLauncherSecurityAnnotations sets the annotations of the VM template KubeVirt copies onto
+// the virt-launcher pod to apply the security profile. The pod wide seccomp annotation covers
+// the hook sidecars along with the compute container.
+func setLauncherSecurityAnnotations(annotations map[string]string, launcherSecurity *kubevirtproviderv1.LauncherSecurity) {
+	if profile := launcherSecurity.SeccompProfile; profile != nil {
+		annotations[corev1.SeccompPodAnnotationKey] = seccompAnnotationValue(profile)
+	}
+}
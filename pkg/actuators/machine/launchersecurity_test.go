@@ -0,0 +1,89 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateLauncherSecurity(t *testing.T) {
+	testCases := []struct {
+		testcase       string
+		seccompProfile *kubevirtproviderv1.SeccompProfile
+		expectError    bool
+	}{
+		{
+			testcase: "no seccomp profile",
+		},
+		{
+			testcase:       "runtime default",
+			seccompProfile: &kubevirtproviderv1.SeccompProfile{Type: kubevirtproviderv1.SeccompProfileTypeRuntimeDefault},
+		},
+		{
+			testcase:       "localhost",
+			seccompProfile: &kubevirtproviderv1.SeccompProfile{Type: kubevirtproviderv1.SeccompProfileTypeLocalhost, LocalhostProfile: "kubevirt/virt-launcher.json"},
+		},
+		{
+			testcase:       "localhost without profile",
+			seccompProfile: &kubevirtproviderv1.SeccompProfile{Type: kubevirtproviderv1.SeccompProfileTypeLocalhost},
+			expectError:    true,
+		},
+		{
+			testcase:       "localhost outside of the profile root",
+			seccompProfile: &kubevirtproviderv1.SeccompProfile{Type: kubevirtproviderv1.SeccompProfileTypeLocalhost, LocalhostProfile: "../etc/profile.json"},
+			expectError:    true,
+		},
+		{
+			testcase:       "absolute localhost profile",
+			seccompProfile: &kubevirtproviderv1.SeccompProfile{Type: kubevirtproviderv1.SeccompProfileTypeLocalhost, LocalhostProfile: "/var/lib/kubelet/seccomp/profile.json"},
+			expectError:    true,
+		},
+		{
+			testcase:       "profile without localhost type",
+			seccompProfile: &kubevirtproviderv1.SeccompProfile{Type: kubevirtproviderv1.SeccompProfileTypeUnconfined, LocalhostProfile: "profile.json"},
+			expectError:    true,
+		},
+		{
+			testcase:       "unsupported type",
+			seccompProfile: &kubevirtproviderv1.SeccompProfile{Type: "docker/default"},
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateLauncherSecurity(&kubevirtproviderv1.LauncherSecurity{SeccompProfile: tc.seccompProfile})
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMLauncherSecurity(t *testing.T) {
+	testCases := map[kubevirtproviderv1.SeccompProfile]string{
+		{Type: kubevirtproviderv1.SeccompProfileTypeRuntimeDefault}:                                             "runtime/default",
+		{Type: kubevirtproviderv1.SeccompProfileTypeLocalhost, LocalhostProfile: "kubevirt/virt-launcher.json"}: "localhost/kubevirt/virt-launcher.json",
+		{Type: kubevirtproviderv1.SeccompProfileTypeUnconfined}:                                                 "unconfined",
+	}
+
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	for profile, expected := range testCases {
+		profile := profile
+		providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+			SourcePvcName:    "rhcos",
+			LauncherSecurity: &kubevirtproviderv1.LauncherSecurity{SeccompProfile: &profile},
+		}
+		vm, _, err := buildVM(machine, providerSpec, []byte("#cloud-config\n"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value := vm.Spec.Template.ObjectMeta.Annotations[corev1.SeccompPodAnnotationKey]; value != expected {
+			t.Errorf("Expected seccomp annotation %q for %s, got %q", expected, profile.Type, value)
+		}
+	}
+}
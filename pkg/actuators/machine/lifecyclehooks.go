@@ -0,0 +1,33 @@
+package machine
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+)
+
+const (
+	// preTerminateHookPrefix prefixes the annotations of the hooks run on a deleted machine
+	// before its VM is deleted, one per hook named after the prefix.
+	preTerminateHookPrefix = "pre-terminate.delete.hook.machine.openshift.io/"
+
+	// lifecycleHookRequeue is the delay between the checks of the lifecycle hooks of a
+	// deleted machine.
+	lifecycleHookRequeue = 20 * time.Second
+)
+
+// pendingLifecycleHooks returns the sorted annotations of the pre-terminate hooks set on the
+// machine. Pre-drain hooks are not supported: the machine controller drains the Node before
+// the actuator is called, the actuator cannot hold the drain.
+func pendingLifecycleHooks(machine *machinev1.Machine) []string {
+	var hooks []string
+	for annotation := range machine.Annotations {
+		if strings.HasPrefix(annotation, preTerminateHookPrefix) {
+			hooks = append(hooks, annotation)
+		}
+	}
+	sort.Strings(hooks)
+	return hooks
+}
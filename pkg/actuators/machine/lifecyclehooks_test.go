@@ -0,0 +1,41 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPendingLifecycleHooks(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				preTerminateHookPrefix + "backup":                           "backup-controller",
+				"pre-drain.delete.hook.machine.openshift.io/storage-detach": "storage-controller",
+				"machine.openshift.io/instance-state":                       "Running",
+				"pre-terminate.delete.hook.example.com/foo":                 "",
+			},
+		},
+	}
+	expected := []string{preTerminateHookPrefix + "backup"}
+	if hooks := pendingLifecycleHooks(machine); !equalStrings(hooks, expected) {
+		t.Errorf("Expected hooks %v, got %v", expected, hooks)
+	}
+}
+
+func TestDeleteWaitsForLifecycleHooks(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "worker-abcde",
+			Namespace:   "tenant-a",
+			Annotations: map[string]string{preTerminateHookPrefix + "backup": ""},
+		},
+	}
+	err := NewActuator(ActuatorParams{}).Delete(context.Background(), machine)
+	if requeue, ok := err.(*machinecontroller.RequeueAfterError); !ok || requeue.RequeueAfter != lifecycleHookRequeue {
+		t.Errorf("Expected a requeue after %v, got %v", lifecycleHookRequeue, err)
+	}
+}
@@ -3,14 +3,17 @@ package machine
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/codec"
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
 	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
 
-	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/go-logr/logr"
 	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	machineapierros "github.com/openshift/machine-api-operator/pkg/controller/machine"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/klog"
+	"k8s.io/klog/klogr"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -18,11 +21,34 @@ const (
 	userDataSecretKey = "userData"
 )
 
+// defaultLogger logs the operations of scopes built without a logger.
+var defaultLogger = klogr.New()
+
 // machineScopeParams defines the input parameters used to create a new MachineScope.
 type machineScopeParams struct {
 	context.Context
 
 	kubevirtClientBuilder kubevirtclient.KubevirtClientBuilderFuncType
+	// advancedTuningEnabled allows the advanced tuning section of the provider spec
+	advancedTuningEnabled bool
+	// failureBudget is optional, it stops VM creations of failing MachineSets
+	failureBudget *FailureBudget
+	// cloneCoordinator is optional, it bounds the concurrent boot volume clones of a source PVC
+	cloneCoordinator *CloneCoordinator
+	// diagnosticsURLTemplates render the infra diagnostics links of the provider status
+	diagnosticsURLTemplates DiagnosticsURLTemplates
+	// overcommitProfiles are the overcommit profiles provider specs refer to
+	overcommitProfiles OvercommitProfiles
+	// metadataPropagation is optional, it holds the keys of the machine metadata propagated onto the VM
+	metadataPropagation *MetadataPropagation
+	// dnsRegistration is optional, it registers the machine hostname into a DNS backend
+	dnsRegistration *DNSRegistration
+	// infraAccessNamespace is optional, it is the namespace the infra credentials are scoped to
+	infraAccessNamespace string
+	// impersonation is optional, it sets the identity the infra cluster requests impersonate
+	impersonation *Impersonation
+	// logger logs the machine operation
+	logger logr.Logger
 	// api server controller runtime client
 	client runtimeclient.Client
 	// machine resource
@@ -32,60 +58,104 @@ type machineScopeParams struct {
 type machineScope struct {
 	context.Context
 
-	// client for interacting with AWS
+	// client for interacting with KubeVirt
 	kubevirtClient kubevirtclient.Client
+	// advancedTuningEnabled allows the advanced tuning section of the provider spec
+	advancedTuningEnabled bool
+	// failureBudget is optional, it stops VM creations of failing MachineSets
+	failureBudget *FailureBudget
+	// cloneCoordinator is optional, it bounds the concurrent boot volume clones of a source PVC
+	cloneCoordinator *CloneCoordinator
+	// diagnosticsURLTemplates render the infra diagnostics links of the provider status
+	diagnosticsURLTemplates DiagnosticsURLTemplates
+	// overcommitProfiles are the overcommit profiles provider specs refer to
+	overcommitProfiles OvercommitProfiles
+	// metadataPropagation is optional, it holds the keys of the machine metadata propagated onto the VM
+	metadataPropagation *MetadataPropagation
+	// dnsRegistration is optional, it registers the machine hostname into a DNS backend
+	dnsRegistration *DNSRegistration
+	// infraAccessNamespace is optional, it is the namespace the infra credentials are scoped to
+	infraAccessNamespace string
+	// logger logs the machine operation, defaults to klog
+	logger logr.Logger
 	// api server controller runtime client
 	client runtimeclient.Client
 	// machine resource
 	machine            *machinev1.Machine
 	machineToBePatched runtimeclient.Patch
-	providerSpec       string //*awsproviderv1.AWSMachineProviderConfig
-	providerStatus     string //*awsproviderv1.AWSMachineProviderStatus
+	providerSpec       *kubevirtproviderv1.KubevirtMachineProviderSpec
+	providerStatus     *kubevirtproviderv1.KubevirtMachineProviderStatus
 }
 
 func newMachineScope(params machineScopeParams) (*machineScope, error) {
-	providerSpec, err := awsproviderv1.ProviderSpecFromRawExtension(params.machine.Spec.ProviderSpec.Value)
+	providerSpec, err := codec.DecodeProviderSpec(params.machine.Spec.ProviderSpec.Value)
 	if err != nil {
 		return nil, machineapierros.InvalidMachineConfiguration("failed to get machine config: %v", err)
 	}
 
-	providerStatus, err := awsproviderv1.ProviderStatusFromRawExtension(params.machine.Status.ProviderStatus)
+	providerStatus, err := kubevirtproviderv1.ProviderStatusFromRawExtension(params.machine.Status.ProviderStatus)
 	if err != nil {
 		return nil, machineapierros.InvalidMachineConfiguration("failed to get machine provider status: %v", err.Error())
 	}
 
-	//(client kubecli.KubevirtClient, namespace, region string) returns:Client
-	kubeClient, err := params.kubevirtClientBuilder(params.client, params.machine.Namespace)
+	credentialsSecretName := ""
+	if providerSpec.CredentialsSecret != nil {
+		credentialsSecretName = providerSpec.CredentialsSecret.Name
+	}
+
+	kubevirtClientBuilder := params.kubevirtClientBuilder
+	if params.impersonation != nil {
+		kubevirtClientBuilder = params.impersonation.clientBuilder(params.machine)
+	}
+	kubeClient, err := kubevirtClientBuilder(params.client, credentialsSecretName, params.machine.Namespace)
 	if err != nil {
-		return nil, machineapierros.InvalidMachineConfiguration("failed to create aws client: %v", err.Error())
+		return nil, machineapierros.InvalidMachineConfiguration("failed to create kubevirt client: %v", err.Error())
 	}
 
 	return &machineScope{
-		Context:            params.Context,
-		kubevirtClient:     kubeClient,
-		client:             params.client,
-		machine:            params.machine,
-		machineToBePatched: runtimeclient.MergeFrom(params.machine.DeepCopy()),
-		providerSpec:       providerSpec,
-		providerStatus:     providerStatus,
+		Context:                 params.Context,
+		kubevirtClient:          kubeClient,
+		advancedTuningEnabled:   params.advancedTuningEnabled,
+		failureBudget:           params.failureBudget,
+		cloneCoordinator:        params.cloneCoordinator,
+		diagnosticsURLTemplates: params.diagnosticsURLTemplates,
+		overcommitProfiles:      params.overcommitProfiles,
+		metadataPropagation:     params.metadataPropagation,
+		dnsRegistration:         params.dnsRegistration,
+		infraAccessNamespace:    params.infraAccessNamespace,
+		client:                  params.client,
+		machine:                 params.machine,
+		machineToBePatched:      runtimeclient.MergeFrom(params.machine.DeepCopy()),
+		logger:                  params.logger,
+		providerSpec:            providerSpec,
+		providerStatus:          providerStatus,
 	}, nil
 }
 
+// log returns the logger of the machine operation.
+func (s *machineScope) log() logr.Logger {
+	if s.logger == nil {
+		return defaultLogger
+	}
+	return s.logger
+}
+
 // Patch patches the machine spec and machine status after reconciling.
 func (s *machineScope) patchMachine() error {
-	klog.V(3).Infof("%v: patching machine", s.machine.GetName())
+	s.log().V(3).Info("patching machine")
 
-	providerStatus, err := awsproviderv1.RawExtensionFromProviderStatus(s.providerStatus)
+	providerStatus, err := kubevirtproviderv1.RawExtensionFromProviderStatus(s.providerStatus)
 	if err != nil {
 		return machineapierros.InvalidMachineConfiguration("failed to get machine provider status: %v", err.Error())
 	}
 	s.machine.Status.ProviderStatus = providerStatus
+	setOperationStateVersion(s.machine, s.providerStatus)
 
 	statusCopy := *s.machine.Status.DeepCopy()
 
 	// patch machine
 	if err := s.client.Patch(context.Background(), s.machine, s.machineToBePatched); err != nil {
-		klog.Errorf("Failed to patch machine %q: %v", s.machine.GetName(), err)
+		s.log().Error(err, "failed to patch machine")
 		return err
 	}
 
@@ -93,7 +163,7 @@ func (s *machineScope) patchMachine() error {
 
 	// patch status
 	if err := s.client.Status().Patch(context.Background(), s.machine, s.machineToBePatched); err != nil {
-		klog.Errorf("Failed to patch machine status %q: %v", s.machine.GetName(), err)
+		s.log().Error(err, "failed to patch machine status")
 		return err
 	}
 
@@ -110,48 +180,73 @@ func (s *machineScope) getUserData() ([]byte, error) {
 	userDataSecret := &corev1.Secret{}
 
 	objKey := runtimeclient.ObjectKey{
-		Namespace: s.machine.Namespace,
+		Namespace: s.providerSpec.UserDataSecret.Namespace,
 		Name:      s.providerSpec.UserDataSecret.Name,
 	}
+	if objKey.Namespace == "" {
+		objKey.Namespace = s.machine.Namespace
+	}
 
 	if err := s.client.Get(s.Context, objKey, userDataSecret); err != nil {
 		return nil, err
 	}
 
-	userData, exists := userDataSecret.Data[userDataSecretKey]
+	key := s.providerSpec.UserDataSecret.Key
+	if key == "" {
+		key = userDataSecretKey
+	}
+
+	userData, exists := userDataSecret.Data[key]
 	if !exists {
-		return nil, fmt.Errorf("secret %s missing %s key", objKey, userDataSecretKey)
+		return nil, fmt.Errorf("secret %s missing %s key", objKey, key)
 	}
 
 	return userData, nil
 }
 
-func (s *machineScope) setProviderStatus(instance *ec2.Instance, condition awsproviderv1.AWSMachineProviderCondition) error {
-	klog.Infof("%s: Updating status", s.machine.Name)
+// getSSHKeys returns the SSH public keys from the Machine's provider spec, both
+// inline and from the referenced secret.
+func (s *machineScope) getSSHKeys() ([]string, error) {
+	if s.providerSpec == nil || s.providerSpec.SSHKeys == nil {
+		return nil, nil
+	}
 
-	networkAddresses := []corev1.NodeAddress{}
+	keys := []string{}
+	for _, key := range s.providerSpec.SSHKeys.Keys {
+		keys = append(keys, parseSSHKeys(key)...)
+	}
 
-	// TODO: remove 139-141, no need to clean instance id ands state
-	// Instance may have existed but been deleted outside our control, clear it's status if so:
-	if instance == nil {
-		s.providerStatus.InstanceID = nil
-		s.providerStatus.InstanceState = nil
-	} else {
-		s.providerStatus.InstanceID = instance.InstanceId
-		s.providerStatus.InstanceState = instance.State.Name
+	if s.providerSpec.SSHKeys.SecretRef == nil {
+		return keys, nil
+	}
 
-		addresses, err := extractNodeAddresses(instance)
-		if err != nil {
-			klog.Errorf("%s: Error extracting instance IP addresses: %v", s.machine.Name, err)
-			return err
-		}
+	sshKeysSecret := &corev1.Secret{}
 
-		networkAddresses = append(networkAddresses, addresses...)
+	objKey := runtimeclient.ObjectKey{
+		Namespace: s.machine.Namespace,
+		Name:      s.providerSpec.SSHKeys.SecretRef.Name,
 	}
-	klog.Infof("%s: finished calculating AWS status", s.machine.Name)
 
-	s.machine.Status.Addresses = networkAddresses
-	s.providerStatus.Conditions = setAWSMachineProviderCondition(condition, s.providerStatus.Conditions)
+	if err := s.client.Get(s.Context, objKey, sshKeysSecret); err != nil {
+		return nil, err
+	}
 
-	return nil
+	// Sort the secret keys, so that the rendered user data is stable
+	secretKeys := make([]string, 0, len(sshKeysSecret.Data))
+	for secretKey := range sshKeysSecret.Data {
+		secretKeys = append(secretKeys, secretKey)
+	}
+	sort.Strings(secretKeys)
+
+	for _, secretKey := range secretKeys {
+		keys = append(keys, parseSSHKeys(string(sshKeysSecret.Data[secretKey]))...)
+	}
+
+	return keys, nil
+}
+
+func (s *machineScope) setProviderStatus(condition kubevirtproviderv1.KubevirtMachineProviderCondition) {
+	s.log().Info("updating status", "condition", condition.Type, "reason", condition.Reason)
+
+	s.providerStatus.Conditions = setKubevirtMachineProviderCondition(condition, s.providerStatus.Conditions)
 }
@@ -0,0 +1,29 @@
+package machine
+
+import (
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+)
+
+// excludeAnnotation excludes a machine from reconciliation when set to "true".
+const excludeAnnotation = "kubevirtproviderconfig.openshift.io/exclude"
+
+// MachineFilter decides which machines the actuator leaves alone. The VMs of excluded machines
+// are neither created, updated nor deleted, excluded machines are reported as existing until
+// they are deleted, so that their deletion releases them without touching their VM.
+type MachineFilter interface {
+	Excluded(machine *machinev1.Machine) bool
+}
+
+// MachineFilterFunc adapts a function to a MachineFilter.
+type MachineFilterFunc func(machine *machinev1.Machine) bool
+
+// Excluded implements MachineFilter.
+func (f MachineFilterFunc) Excluded(machine *machinev1.Machine) bool {
+	return f(machine)
+}
+
+// AnnotationMachineFilter excludes the machines annotated with
+// kubevirtproviderconfig.openshift.io/exclude: "true". It is the default filter of the actuator.
+var AnnotationMachineFilter MachineFilter = MachineFilterFunc(func(machine *machinev1.Machine) bool {
+	return machine.Annotations[excludeAnnotation] == "true"
+})
@@ -0,0 +1,78 @@
+package machine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExcludedMachines(t *testing.T) {
+	now := metav1.Now()
+
+	testCases := []struct {
+		testcase       string
+		filter         MachineFilter
+		machine        *machinev1.Machine
+		expectedExists bool
+	}{
+		{
+			testcase: "annotated machine",
+			machine: &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "worker-abcde",
+					Annotations: map[string]string{excludeAnnotation: "true"},
+				},
+			},
+			expectedExists: true,
+		},
+		{
+			testcase: "annotated machine being deleted",
+			machine: &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "worker-abcde",
+					Annotations:       map[string]string{excludeAnnotation: "true"},
+					DeletionTimestamp: &now,
+				},
+			},
+			expectedExists: false,
+		},
+		{
+			testcase: "machine excluded by a custom filter",
+			filter: MachineFilterFunc(func(machine *machinev1.Machine) bool {
+				return strings.HasPrefix(machine.Name, "e2e-")
+			}),
+			machine: &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "e2e-worker-abcde"},
+			},
+			expectedExists: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			// Without clients, any attempt to reconcile the machine panics
+			actuator := NewActuator(ActuatorParams{MachineFilter: tc.filter})
+			ctx := context.Background()
+
+			if err := actuator.Create(ctx, tc.machine); err != nil {
+				t.Errorf("Unexpected error on create: %v", err)
+			}
+			exists, err := actuator.Exists(ctx, tc.machine)
+			if err != nil {
+				t.Errorf("Unexpected error on exists: %v", err)
+			}
+			if exists != tc.expectedExists {
+				t.Errorf("Expected exists %v, got %v", tc.expectedExists, exists)
+			}
+			if err := actuator.Update(ctx, tc.machine); err != nil {
+				t.Errorf("Unexpected error on update: %v", err)
+			}
+			if err := actuator.Delete(ctx, tc.machine); err != nil {
+				t.Errorf("Unexpected error on delete: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// deleteMachineAnnotation marks a machine to be deleted first when its MachineSet scales down.
+const deleteMachineAnnotation = "machine.openshift.io/cluster-api-delete-machine"
+
+// validateMaxLifetime returns an error if the maximum lifetime is not positive.
+func validateMaxLifetime(maxLifetime *metav1.Duration) error {
+	if maxLifetime.Duration <= 0 {
+		return fmt.Errorf("maxLifetime must be positive, got %v", maxLifetime.Duration)
+	}
+	return nil
+}
+
+// resolveExpiredMachineAction returns the action applied to expired machines, defaulting to Report.
+func resolveExpiredMachineAction(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (kubevirtproviderv1.ExpiredMachineAction, error) {
+	switch providerSpec.ExpiredMachineAction {
+	case "":
+		return kubevirtproviderv1.ExpiredMachineReport, nil
+	case kubevirtproviderv1.ExpiredMachineReport, kubevirtproviderv1.ExpiredMachineMarkForDeletion, kubevirtproviderv1.ExpiredMachineDelete:
+		return providerSpec.ExpiredMachineAction, nil
+	default:
+		return "", fmt.Errorf("unsupported expiredMachineAction %q, must be one of %q, %q or %q", providerSpec.ExpiredMachineAction,
+			kubevirtproviderv1.ExpiredMachineReport, kubevirtproviderv1.ExpiredMachineMarkForDeletion, kubevirtproviderv1.ExpiredMachineDelete)
+	}
+}
+
+// lifetimeCondition returns the condition reporting whether the machine created at created is
+// older than the maximum lifetime.
+func lifetimeCondition(created time.Time, maxLifetime time.Duration, now time.Time) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	expiration := created.Add(maxLifetime).UTC().Format(time.RFC3339)
+	if now.Before(created.Add(maxLifetime)) {
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.LifetimeExceeded,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.WithinMaxLifetime,
+			Message: fmt.Sprintf("Machine expires at %s", expiration),
+		}
+	}
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.LifetimeExceeded,
+		Status:  corev1.ConditionTrue,
+		Reason:  kubevirtproviderv1.MaxLifetimeExceeded,
+		Message: fmt.Sprintf("Machine expired at %s", expiration),
+	}
+}
+
+// machineSetDeleting returns true if another machine of the MachineSet of the machine is being
+// deleted.
+func (r *Reconciler) machineSetDeleting() (bool, error) {
+	machineSet, ok := machineSetKey(r.machine)
+	if !ok {
+		return false, nil
+	}
+	machines := &machinev1.MachineList{}
+	if err := r.client.List(r.Context, machines, runtimeclient.InNamespace(machineSet.Namespace), runtimeclient.MatchingLabels{machineSetLabel: machineSet.Name}); err != nil {
+		return false, fmt.Errorf("failed to list machines of MachineSet %s: %w", machineSet.Name, err)
+	}
+	for i := range machines.Items {
+		if machine := &machines.Items[i]; machine.UID != r.machine.UID && machine.DeletionTimestamp != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcileMaxLifetime reports whether the machine exceeded its maximum lifetime and applies the
+// expired machine action once it did.
+func (r *Reconciler) reconcileMaxLifetime(now time.Time) error {
+	if r.providerSpec.MaxLifetime == nil {
+		return nil
+	}
+	if err := validateMaxLifetime(r.providerSpec.MaxLifetime); err != nil {
+		return machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+	action, err := resolveExpiredMachineAction(r.providerSpec)
+	if err != nil {
+		return machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+
+	condition := lifetimeCondition(r.machine.CreationTimestamp.Time, r.providerSpec.MaxLifetime.Duration, now)
+	r.machineScope.setProviderStatus(condition)
+	if condition.Status != corev1.ConditionTrue {
+		return nil
+	}
+
+	switch action {
+	case kubevirtproviderv1.ExpiredMachineMarkForDeletion:
+		if _, marked := r.machine.Annotations[deleteMachineAnnotation]; marked {
+			return nil
+		}
+		r.log().Info("marking expired machine for deletion", "maxLifetime", r.providerSpec.MaxLifetime.Duration.String())
+		if r.machine.Annotations == nil {
+			r.machine.Annotations = map[string]string{}
+		}
+		r.machine.Annotations[deleteMachineAnnotation] = "true"
+	case kubevirtproviderv1.ExpiredMachineDelete:
+		if _, ok := machineSetKey(r.machine); !ok {
+			// Nothing would replace the machine
+			return nil
+		}
+		deleting, err := r.machineSetDeleting()
+		if err != nil {
+			return err
+		}
+		if deleting {
+			return nil
+		}
+		r.log().Info("deleting expired machine for its MachineSet to replace it", "maxLifetime", r.providerSpec.MaxLifetime.Duration.String())
+		if err := r.client.Delete(r.Context, r.machine); err != nil {
+			return fmt.Errorf("failed to delete expired machine: %w", err)
+		}
+	}
+	return nil
+}
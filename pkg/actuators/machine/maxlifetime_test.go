@@ -0,0 +1,135 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestReconcileMaxLifetime(t *testing.T) {
+	now := time.Now()
+
+	newMachine := func(name string, age time.Duration, machineSet string) *machinev1.Machine {
+		machine := &machinev1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "tenant-a",
+				UID:               types.UID(name),
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+		}
+		if machineSet != "" {
+			machine.Labels = map[string]string{machineSetLabel: machineSet}
+		}
+		return machine
+	}
+
+	testCases := []struct {
+		testcase       string
+		age            time.Duration
+		machineSet     string
+		action         kubevirtproviderv1.ExpiredMachineAction
+		otherDeleting  bool
+		expectExceeded bool
+		expectMarked   bool
+		expectDeleted  bool
+	}{
+		{
+			testcase: "within the lifetime",
+			age:      24 * time.Hour,
+			action:   kubevirtproviderv1.ExpiredMachineDelete,
+		},
+		{
+			testcase:       "reports an expired machine",
+			age:            31 * 24 * time.Hour,
+			machineSet:     "workers",
+			expectExceeded: true,
+		},
+		{
+			testcase:       "marks an expired machine for deletion",
+			age:            31 * 24 * time.Hour,
+			machineSet:     "workers",
+			action:         kubevirtproviderv1.ExpiredMachineMarkForDeletion,
+			expectExceeded: true,
+			expectMarked:   true,
+		},
+		{
+			testcase:       "deletes an expired machine",
+			age:            31 * 24 * time.Hour,
+			machineSet:     "workers",
+			action:         kubevirtproviderv1.ExpiredMachineDelete,
+			expectExceeded: true,
+			expectDeleted:  true,
+		},
+		{
+			testcase:       "waits for the other machines of the MachineSet to be deleted",
+			age:            31 * 24 * time.Hour,
+			machineSet:     "workers",
+			action:         kubevirtproviderv1.ExpiredMachineDelete,
+			otherDeleting:  true,
+			expectExceeded: true,
+		},
+		{
+			testcase:       "keeps an expired machine without MachineSet",
+			age:            31 * 24 * time.Hour,
+			action:         kubevirtproviderv1.ExpiredMachineDelete,
+			expectExceeded: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := machinev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			machine := newMachine("worker-abcde", tc.age, tc.machineSet)
+			other := newMachine("worker-fghij", tc.age, tc.machineSet)
+			if tc.otherDeleting {
+				deletionTimestamp := metav1.NewTime(now)
+				other.DeletionTimestamp = &deletionTimestamp
+				other.Finalizers = []string{machinev1.MachineFinalizer}
+			}
+			client := fake.NewFakeClientWithScheme(scheme, machine.DeepCopy(), other)
+
+			r := newReconciler(&machineScope{
+				Context: context.Background(),
+				client:  client,
+				machine: machine,
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					MaxLifetime:          &metav1.Duration{Duration: 30 * 24 * time.Hour},
+					ExpiredMachineAction: tc.action,
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			if err := r.reconcileMaxLifetime(now); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.LifetimeExceeded)
+			if condition == nil {
+				t.Fatal("Expected the LifetimeExceeded condition to be set")
+			}
+			if exceeded := condition.Status == corev1.ConditionTrue; exceeded != tc.expectExceeded {
+				t.Errorf("Expected lifetime exceeded %v, got %v", tc.expectExceeded, exceeded)
+			}
+			if _, marked := r.machine.Annotations[deleteMachineAnnotation]; marked != tc.expectMarked {
+				t.Errorf("Expected marked for deletion %v, got %v", tc.expectMarked, marked)
+			}
+			err := client.Get(context.Background(), types.NamespacedName{Namespace: "tenant-a", Name: "worker-abcde"}, &machinev1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectDeleted {
+				t.Errorf("Expected deleted %v, got error %v", tc.expectDeleted, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+// MetadataPropagation holds the keys of the Machine labels and annotations propagated onto its
+// VirtualMachine, the VMI template and the running virt-launcher pod, for infra-side tooling to
+// group and bill VMs.
+type MetadataPropagation struct {
+	Labels      []string
+	Annotations []string
+}
+
+// NewMetadataPropagation returns the propagation of the given label and annotation keys.
+// Keys of the kubevirt.io domains are refused, KubeVirt owns them.
+func NewMetadataPropagation(labels, annotations []string) (*MetadataPropagation, error) {
+	for _, key := range append(append([]string{}, labels...), annotations...) {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid propagated key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if i := strings.Index(key, "/"); i > 0 {
+			if prefix := key[:i]; prefix == kubevirtapis.GroupName || strings.HasSuffix(prefix, "."+kubevirtapis.GroupName) {
+				return nil, fmt.Errorf("invalid propagated key %q: the %s domain is reserved to KubeVirt", key, kubevirtapis.GroupName)
+			}
+		}
+	}
+	return &MetadataPropagation{Labels: labels, Annotations: annotations}, nil
+}
+
+// propagatedValues returns the values of the keys in the machine metadata, nil for the keys the
+// machine does not have, which are removed from the infra objects.
+func propagatedValues(keys []string, source map[string]string) map[string]*string {
+	values := make(map[string]*string, len(keys))
+	for _, key := range keys {
+		if value, ok := source[key]; ok {
+			values[key] = &value
+		} else {
+			values[key] = nil
+		}
+	}
+	return values
+}
+
+// applyPropagatedValues sets the values onto the metadata and returns whether it changed.
+func applyPropagatedValues(metadata *map[string]string, values map[string]*string) bool {
+	changed := false
+	for key, value := range values {
+		current, ok := (*metadata)[key]
+		switch {
+		case value == nil && ok:
+			delete(*metadata, key)
+			changed = true
+		case value != nil && (!ok || current != *value):
+			if *metadata == nil {
+				*metadata = map[string]string{}
+			}
+			(*metadata)[key] = *value
+			changed = true
+		}
+	}
+	return changed
+}
+
+// applyMetadataPropagation propagates the machine labels and annotations onto the VM and onto
+// its VMI template, for the virt-launcher pods of the next VMIs to carry them. It returns
+// whether the VM changed.
+func applyMetadataPropagation(vm *kubevirtapis.VirtualMachine, machine metav1.Object, propagation *MetadataPropagation) bool {
+	labels := propagatedValues(propagation.Labels, machine.GetLabels())
+	annotations := propagatedValues(propagation.Annotations, machine.GetAnnotations())
+	changed := applyPropagatedValues(&vm.Labels, labels)
+	changed = applyPropagatedValues(&vm.Annotations, annotations) || changed
+	if vm.Spec.Template != nil {
+		changed = applyPropagatedValues(&vm.Spec.Template.ObjectMeta.Labels, labels) || changed
+		changed = applyPropagatedValues(&vm.Spec.Template.ObjectMeta.Annotations, annotations) || changed
+	}
+	return changed
+}
+
+// reconcileMetadataPropagation propagates the machine labels and annotations onto the VM and
+// patches them onto the running virt-launcher pod, which does not pick up changes of the VM.
+func (r *Reconciler) reconcileMetadataPropagation(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) (*kubevirtapis.VirtualMachine, error) {
+	propagation := r.metadataPropagation
+	if propagation == nil {
+		return vm, nil
+	}
+
+	updated := vm.DeepCopy()
+	if applyMetadataPropagation(updated, r.machine, propagation) {
+		r.log().Info("propagating machine labels and annotations onto VirtualMachine")
+		var err error
+		if vm, err = r.kubevirtClient.UpdateVirtualMachine(r.Context, updated.Namespace, updated); err != nil {
+			return vm, fmt.Errorf("failed to propagate machine labels and annotations onto VirtualMachine: %w", err)
+		}
+	}
+
+	if vmi == nil {
+		return vm, nil
+	}
+	pod, err := r.getLauncherPod(vmi)
+	if err != nil || pod == nil {
+		return vm, err
+	}
+	labels := propagatedValues(propagation.Labels, r.machine.Labels)
+	annotations := propagatedValues(propagation.Annotations, r.machine.Annotations)
+	if !podMetadataChanged(pod, labels, annotations) {
+		return vm, nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      labels,
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return vm, err
+	}
+	if _, err := r.kubevirtClient.PatchPod(r.Context, pod.Namespace, pod.Name, types.MergePatchType, patch); err != nil {
+		return vm, fmt.Errorf("failed to propagate machine labels and annotations onto virt-launcher pod %s: %w", pod.Name, err)
+	}
+	return vm, nil
+}
+
+// podMetadataChanged returns whether propagating the values would change the metadata of the pod.
+func podMetadataChanged(pod *corev1.Pod, labels, annotations map[string]*string) bool {
+	metadata := pod.ObjectMeta.DeepCopy()
+	changed := applyPropagatedValues(&metadata.Labels, labels)
+	return applyPropagatedValues(&metadata.Annotations, annotations) || changed
+}
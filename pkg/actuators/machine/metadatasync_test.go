@@ -0,0 +1,132 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestNewMetadataPropagation(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		labels      []string
+		annotations []string
+		expectError bool
+	}{
+		{
+			testcase:    "valid keys",
+			labels:      []string{machineSetLabel, "cost-center"},
+			annotations: []string{"billing.example.com/owner"},
+		},
+		{
+			testcase:    "invalid key",
+			labels:      []string{"cost center"},
+			expectError: true,
+		},
+		{
+			testcase:    "kubevirt key",
+			annotations: []string{"vm.kubevirt.io/flavor"},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			if _, err := NewMetadataPropagation(tc.labels, tc.annotations); (err != nil) != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestReconcileMetadataPropagation(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{
+		Name:        "worker-abcde",
+		Namespace:   "tenant-a",
+		Labels:      map[string]string{machineSetLabel: "worker", "unrelated": "value"},
+		Annotations: map[string]string{"billing.example.com/owner": "team-a"},
+	}}
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-abcde",
+			Namespace: "tenant-a",
+			Labels:    map[string]string{kubevirtapis.VirtualMachineLabel: "worker-abcde", "cost-center": "1234"},
+		},
+		Spec: kubevirtapis.VirtualMachineSpec{
+			Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{},
+		},
+	}
+	vmi := &kubevirtapis.VirtualMachineInstance{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", UID: "vmi-uid"}}
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "virt-launcher-worker-abcde-x1y2z",
+		Namespace: "tenant-a",
+		Labels:    map[string]string{"cost-center": "1234"},
+	}}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client := mockkubevirt.NewMockClient(mockCtrl)
+	client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+		for _, labels := range []map[string]string{vm.Labels, vm.Spec.Template.ObjectMeta.Labels} {
+			if labels[machineSetLabel] != "worker" {
+				t.Errorf("Expected the machineset label to be propagated, got %v", labels)
+			}
+			if _, ok := labels["cost-center"]; ok {
+				t.Errorf("Expected the label the machine does not have to be removed, got %v", labels)
+			}
+			if _, ok := labels["unrelated"]; ok {
+				t.Errorf("Expected only the configured labels to be propagated, got %v", labels)
+			}
+		}
+		if vm.Labels[kubevirtapis.VirtualMachineLabel] != "worker-abcde" {
+			t.Errorf("Expected the VM label to be kept, got %v", vm.Labels)
+		}
+		if vm.Spec.Template.ObjectMeta.Annotations["billing.example.com/owner"] != "team-a" {
+			t.Errorf("Expected the annotation to be propagated, got %v", vm.Spec.Template.ObjectMeta.Annotations)
+		}
+		return vm, nil
+	})
+	client.EXPECT().ListPods(gomock.Any(), "tenant-a", gomock.Any()).Return(&corev1.PodList{Items: []corev1.Pod{pod}}, nil)
+	client.EXPECT().PatchPod(gomock.Any(), "tenant-a", pod.Name, types.MergePatchType, gomock.Any()).DoAndReturn(func(_ context.Context, namespace, name string, patchType types.PatchType, data []byte) (*corev1.Pod, error) {
+		var patch struct {
+			Metadata struct {
+				Labels      map[string]*string `json:"labels"`
+				Annotations map[string]*string `json:"annotations"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &patch); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value := patch.Metadata.Labels[machineSetLabel]; value == nil || *value != "worker" {
+			t.Errorf("Expected the machineset label to be patched, got %s", data)
+		}
+		if value, ok := patch.Metadata.Labels["cost-center"]; !ok || value != nil {
+			t.Errorf("Expected the label the machine does not have to be removed, got %s", data)
+		}
+		return &pod, nil
+	})
+
+	r := newReconciler(&machineScope{
+		Context:        context.Background(),
+		kubevirtClient: client,
+		machine:        machine,
+		metadataPropagation: &MetadataPropagation{
+			Labels:      []string{machineSetLabel, "cost-center"},
+			Annotations: []string{"billing.example.com/owner"},
+		},
+		providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{},
+		providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+	})
+	if _, err := r.reconcileMetadataPropagation(vm, vmi); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package machine
+
+import (
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/monitoring"
+)
+
+// machineControllerSelector selects the series of the machine controller among the
+// controller-runtime metrics of the manager.
+const machineControllerSelector = `controller="machine_controller"`
+
+// Metrics returns the descriptions of the metrics of the machine controller and of the alerts
+// on them, which the monitoring manifests are generated from.
+func Metrics() []monitoring.Metric {
+	return []monitoring.Metric{
+		{
+			Name:   "kubevirt_machineset_provisioning_failures_total",
+			Help:   "Machines failing to be created or to bootstrap",
+			Type:   monitoring.Counter,
+			Labels: []string{"namespace", "machineset"},
+			Alerts: []monitoring.Alert{{
+				Name:     "KubevirtMachineSetProvisioningFailing",
+				Expr:     "increase(kubevirt_machineset_provisioning_failures_total[30m]) > 2",
+				For:      "5m",
+				Severity: "warning",
+				Summary:  "Machines of MachineSet {{ $labels.namespace }}/{{ $labels.machineset }} keep failing to be created or to bootstrap.",
+			}},
+		},
+		{
+			Name:   "kubevirt_machineset_provisioning_circuit_open",
+			Help:   "MachineSets whose machine creations are stopped",
+			Type:   monitoring.Gauge,
+			Labels: []string{"namespace", "machineset"},
+			Alerts: []monitoring.Alert{{
+				Name:     "KubevirtMachineSetProvisioningCircuitOpen",
+				Expr:     "max by (namespace, machineset) (kubevirt_machineset_provisioning_circuit_open) > 0",
+				For:      "5m",
+				Severity: "critical",
+				Summary:  "No machine of MachineSet {{ $labels.namespace }}/{{ $labels.machineset }} is created until its provisioning-failures annotation is removed.",
+			}},
+		},
+		{
+			Name:     "controller_runtime_reconcile_errors_total",
+			Help:     "Machine reconcile errors, e.g. failing infra cluster requests",
+			Type:     monitoring.Counter,
+			Selector: machineControllerSelector,
+			Alerts: []monitoring.Alert{{
+				Name:     "KubevirtMachineReconcileErrors",
+				Expr:     "sum(rate(controller_runtime_reconcile_errors_total{" + machineControllerSelector + "}[15m])) > 0.1",
+				For:      "15m",
+				Severity: "warning",
+				Summary:  "Machines keep failing to be reconciled against their VMs.",
+			}},
+		},
+		{
+			Name:     "controller_runtime_reconcile_time_seconds",
+			Help:     "Machine reconcile latency",
+			Type:     monitoring.Histogram,
+			Selector: machineControllerSelector,
+		},
+	}
+}
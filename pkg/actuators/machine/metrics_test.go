@@ -0,0 +1,34 @@
+package machine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsRegistered(t *testing.T) {
+	descs := make(chan *prometheus.Desc, 10)
+	provisioningFailuresTotal.Describe(descs)
+	provisioningCircuitOpen.Describe(descs)
+	close(descs)
+	var registered []string
+	for desc := range descs {
+		registered = append(registered, desc.String())
+	}
+
+	for _, metric := range Metrics() {
+		if !strings.HasPrefix(metric.Name, "kubevirt_") {
+			continue
+		}
+		found := false
+		for _, desc := range registered {
+			if strings.Contains(desc, `fqName: "`+metric.Name+`"`) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected metric %s to be exported by the machine controller", metric.Name)
+		}
+	}
+}
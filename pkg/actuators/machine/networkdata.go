@@ -0,0 +1,219 @@
+package machine
+
+import (
+	"fmt"
+	"net"
+
+	"sigs.k8s.io/yaml"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// cloudInitNetworkDataKey is the key of the network data in the user data secret.
+const cloudInitNetworkDataKey = "networkdata"
+
+// validateNetworkData validates the interfaces of the network data and the addresses,
+// routes and routing policy rules they declare.
+func validateNetworkData(networkData *kubevirtproviderv1.NetworkData) error {
+	names := map[string]bool{}
+	ethernets := map[string]bool{}
+	podNetwork := false
+
+	for _, ethernet := range networkData.Ethernets {
+		if ethernet.Name == "" {
+			return fmt.Errorf("ethernet interface name must be specified")
+		}
+		if names[ethernet.Name] {
+			return fmt.Errorf("duplicate interface %q", ethernet.Name)
+		}
+		names[ethernet.Name] = true
+		ethernets[ethernet.Name] = true
+
+		if ethernet.MACAddress != "" {
+			if _, err := net.ParseMAC(ethernet.MACAddress); err != nil {
+				return fmt.Errorf("interface %q: invalid macAddress %q", ethernet.Name, ethernet.MACAddress)
+			}
+		}
+		if ethernet.PodNetwork {
+			if podNetwork {
+				return fmt.Errorf("interface %q: only one interface can be attached to the pod network", ethernet.Name)
+			}
+			podNetwork = true
+		}
+		if err := validateInterfaceConfig(&ethernet.InterfaceConfig); err != nil {
+			return fmt.Errorf("interface %q: %v", ethernet.Name, err)
+		}
+	}
+
+	for _, vlan := range networkData.VLANs {
+		if vlan.Name == "" {
+			return fmt.Errorf("VLAN interface name must be specified")
+		}
+		if names[vlan.Name] {
+			return fmt.Errorf("duplicate interface %q", vlan.Name)
+		}
+		names[vlan.Name] = true
+
+		if vlan.ID < 1 || vlan.ID > 4094 {
+			return fmt.Errorf("VLAN %q: invalid id %d, must be between 1 and 4094", vlan.Name, vlan.ID)
+		}
+		if !ethernets[vlan.Link] {
+			return fmt.Errorf("VLAN %q: link %q is not an ethernet interface", vlan.Name, vlan.Link)
+		}
+		if err := validateInterfaceConfig(&vlan.InterfaceConfig); err != nil {
+			return fmt.Errorf("VLAN %q: %v", vlan.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateInterfaceConfig(config *kubevirtproviderv1.InterfaceConfig) error {
+	for _, address := range config.Addresses {
+		if _, _, err := net.ParseCIDR(address); err != nil {
+			return fmt.Errorf("invalid address %q, must be in CIDR notation", address)
+		}
+	}
+	if config.MTU < 0 {
+		return fmt.Errorf("invalid MTU %d", config.MTU)
+	}
+
+	for _, route := range config.Routes {
+		if _, _, err := net.ParseCIDR(route.To); err != nil {
+			return fmt.Errorf("invalid route destination %q, must be in CIDR notation", route.To)
+		}
+		if route.Via != "" && net.ParseIP(route.Via) == nil {
+			return fmt.Errorf("invalid route gateway %q", route.Via)
+		}
+		if route.Metric < 0 || route.Table < 0 {
+			return fmt.Errorf("route to %q: metric and table must not be negative", route.To)
+		}
+	}
+
+	for _, rule := range config.RoutingPolicy {
+		if rule.From == "" && rule.To == "" {
+			return fmt.Errorf("routing policy rule for table %d must match from or to", rule.Table)
+		}
+		for _, cidr := range []string{rule.From, rule.To} {
+			if cidr == "" {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid routing policy match %q, must be in CIDR notation", cidr)
+			}
+		}
+		if rule.Table < 1 {
+			return fmt.Errorf("routing policy rule table must be specified")
+		}
+		if rule.Priority < 0 {
+			return fmt.Errorf("invalid routing policy rule priority %d", rule.Priority)
+		}
+	}
+
+	return nil
+}
+
+// renderNetworkData renders the network data as cloud-init network config version 2, with
+// DHCP for the families of the address family policy.
+func renderNetworkData(networkData *kubevirtproviderv1.NetworkData, policy kubevirtproviderv1.AddressFamilyPolicy) ([]byte, error) {
+	if err := validateNetworkData(networkData); err != nil {
+		return nil, err
+	}
+
+	config := map[string]interface{}{
+		"version": 2,
+	}
+
+	if len(networkData.Ethernets) > 0 {
+		ethernets := map[string]interface{}{}
+		for _, ethernet := range networkData.Ethernets {
+			interfaceConfig := ethernet.InterfaceConfig.DeepCopy()
+			if ethernet.PodNetwork {
+				applyMasqueradeIPv6(interfaceConfig, policy)
+			}
+			rendered := renderInterfaceConfig(interfaceConfig, policy)
+			if ethernet.MACAddress != "" {
+				rendered["match"] = map[string]interface{}{
+					"macaddress": ethernet.MACAddress,
+				}
+				rendered["set-name"] = ethernet.Name
+			}
+			ethernets[ethernet.Name] = rendered
+		}
+		config["ethernets"] = ethernets
+	}
+
+	if len(networkData.VLANs) > 0 {
+		vlans := map[string]interface{}{}
+		for _, vlan := range networkData.VLANs {
+			rendered := renderInterfaceConfig(&vlan.InterfaceConfig, policy)
+			rendered["id"] = vlan.ID
+			rendered["link"] = vlan.Link
+			vlans[vlan.Name] = rendered
+		}
+		config["vlans"] = vlans
+	}
+
+	return yaml.Marshal(config)
+}
+
+func renderInterfaceConfig(config *kubevirtproviderv1.InterfaceConfig, policy kubevirtproviderv1.AddressFamilyPolicy) map[string]interface{} {
+	rendered := map[string]interface{}{
+		"dhcp4": config.DHCP4,
+	}
+	if policy != "" {
+		ipv4, ipv6 := addressFamilies(policy)
+		rendered["dhcp4"] = config.DHCP4 && ipv4
+		if config.DHCP4 && ipv6 {
+			rendered["dhcp6"] = true
+		}
+	}
+	if len(config.Addresses) > 0 {
+		rendered["addresses"] = config.Addresses
+	}
+	if config.MTU > 0 {
+		rendered["mtu"] = config.MTU
+	}
+
+	if len(config.Routes) > 0 {
+		routes := []interface{}{}
+		for _, route := range config.Routes {
+			renderedRoute := map[string]interface{}{
+				"to": route.To,
+			}
+			if route.Via != "" {
+				renderedRoute["via"] = route.Via
+			}
+			if route.Metric > 0 {
+				renderedRoute["metric"] = route.Metric
+			}
+			if route.Table > 0 {
+				renderedRoute["table"] = route.Table
+			}
+			routes = append(routes, renderedRoute)
+		}
+		rendered["routes"] = routes
+	}
+
+	if len(config.RoutingPolicy) > 0 {
+		rules := []interface{}{}
+		for _, rule := range config.RoutingPolicy {
+			renderedRule := map[string]interface{}{
+				"table": rule.Table,
+			}
+			if rule.From != "" {
+				renderedRule["from"] = rule.From
+			}
+			if rule.To != "" {
+				renderedRule["to"] = rule.To
+			}
+			if rule.Priority > 0 {
+				renderedRule["priority"] = rule.Priority
+			}
+			rules = append(rules, renderedRule)
+		}
+		rendered["routing-policy"] = rules
+	}
+
+	return rendered
+}
@@ -0,0 +1,196 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestRenderNetworkData(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		networkData  *kubevirtproviderv1.NetworkData
+		expectedYAML string
+		expectError  bool
+	}{
+		{
+			testcase: "VLAN with routes and policy routing",
+			networkData: &kubevirtproviderv1.NetworkData{
+				Ethernets: []kubevirtproviderv1.EthernetInterface{
+					{
+						Name:            "eth0",
+						InterfaceConfig: kubevirtproviderv1.InterfaceConfig{DHCP4: true},
+					},
+				},
+				VLANs: []kubevirtproviderv1.VLANInterface{
+					{
+						Name: "eth0.100",
+						ID:   100,
+						Link: "eth0",
+						InterfaceConfig: kubevirtproviderv1.InterfaceConfig{
+							Addresses: []string{"192.168.100.10/24"},
+							MTU:       1450,
+							Routes: []kubevirtproviderv1.Route{
+								{To: "10.10.0.0/16", Via: "192.168.100.1", Metric: 50},
+								{To: "0.0.0.0/0", Via: "192.168.100.1", Table: 100},
+							},
+							RoutingPolicy: []kubevirtproviderv1.RoutingPolicyRule{
+								{From: "192.168.100.0/24", Table: 100, Priority: 10},
+							},
+						},
+					},
+				},
+			},
+			expectedYAML: `ethernets:
+  eth0:
+    dhcp4: true
+version: 2
+vlans:
+  eth0.100:
+    addresses:
+    - 192.168.100.10/24
+    dhcp4: false
+    id: 100
+    link: eth0
+    mtu: 1450
+    routes:
+    - metric: 50
+      to: 10.10.0.0/16
+      via: 192.168.100.1
+    - table: 100
+      to: 0.0.0.0/0
+      via: 192.168.100.1
+    routing-policy:
+    - from: 192.168.100.0/24
+      priority: 10
+      table: 100
+`,
+		},
+		{
+			testcase: "interface matched by MAC address",
+			networkData: &kubevirtproviderv1.NetworkData{
+				Ethernets: []kubevirtproviderv1.EthernetInterface{
+					{
+						Name:            "data0",
+						MACAddress:      "02:00:00:00:00:01",
+						InterfaceConfig: kubevirtproviderv1.InterfaceConfig{Addresses: []string{"fd00::10/64"}},
+					},
+				},
+			},
+			expectedYAML: `ethernets:
+  data0:
+    addresses:
+    - fd00::10/64
+    dhcp4: false
+    match:
+      macaddress: "02:00:00:00:00:01"
+    set-name: data0
+version: 2
+`,
+		},
+		{
+			testcase: "VLAN on unknown link",
+			networkData: &kubevirtproviderv1.NetworkData{
+				VLANs: []kubevirtproviderv1.VLANInterface{
+					{Name: "eth1.100", ID: 100, Link: "eth1"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			testcase: "invalid VLAN id",
+			networkData: &kubevirtproviderv1.NetworkData{
+				Ethernets: []kubevirtproviderv1.EthernetInterface{{Name: "eth0"}},
+				VLANs: []kubevirtproviderv1.VLANInterface{
+					{Name: "eth0.5000", ID: 5000, Link: "eth0"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			testcase: "routing policy rule without table",
+			networkData: &kubevirtproviderv1.NetworkData{
+				Ethernets: []kubevirtproviderv1.EthernetInterface{
+					{
+						Name: "eth0",
+						InterfaceConfig: kubevirtproviderv1.InterfaceConfig{
+							RoutingPolicy: []kubevirtproviderv1.RoutingPolicyRule{{From: "10.0.0.0/8"}},
+						},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			testcase: "address without prefix length",
+			networkData: &kubevirtproviderv1.NetworkData{
+				Ethernets: []kubevirtproviderv1.EthernetInterface{
+					{Name: "eth0", InterfaceConfig: kubevirtproviderv1.InterfaceConfig{Addresses: []string{"10.0.0.10"}}},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			networkData, err := renderNetworkData(tc.networkData, "")
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(networkData) != tc.expectedYAML {
+				t.Errorf("Expected network data:\n%s\ngot:\n%s", tc.expectedYAML, networkData)
+			}
+		})
+	}
+}
+
+func TestBuildVMNetworkData(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubevirt-test",
+			Namespace: "kubevirt-test",
+		},
+	}
+	networkData := &kubevirtproviderv1.NetworkData{
+		Ethernets: []kubevirtproviderv1.EthernetInterface{
+			{Name: "eth0", InterfaceConfig: kubevirtproviderv1.InterfaceConfig{DHCP4: true}},
+		},
+	}
+
+	vm, userDataSecret, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		NetworkData:   networkData,
+	}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := userDataSecret.Data[cloudInitNetworkDataKey]; !ok {
+		t.Errorf("Expected network data in the UserData secret, got keys %v", userDataSecret.Data)
+	}
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.Name != cloudInitVolumeName {
+			continue
+		}
+		if ref := volume.CloudInitNoCloud.NetworkDataSecretRef; ref == nil || ref.Name != userDataSecret.Name {
+			t.Errorf("Expected network data secret %q, got %v", userDataSecret.Name, ref)
+		}
+	}
+
+	_, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		NetworkData:   networkData,
+	}, []byte(`{"ignition": {"version": "3.1.0"}}`))
+	if err == nil {
+		t.Errorf("Expected error for network data with Ignition UserData, got nil")
+	}
+}
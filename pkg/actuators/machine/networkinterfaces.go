@@ -0,0 +1,131 @@
+package machine
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// masqueradeIPv6Address and masqueradeIPv6Gateway are the guest address and gateway of the
+	// IPv6 network of the KubeVirt masquerade binding, which the guest has to configure itself
+	masqueradeIPv6Address = "fd10:0:2::2/120"
+	masqueradeIPv6Gateway = "fd10:0:2::1"
+)
+
+// interfaceBinding returns the binding of the interface, Masquerade on the pod network and
+// Bridge on secondary networks when not set.
+func interfaceBinding(iface *kubevirtproviderv1.NetworkInterface) kubevirtproviderv1.InterfaceBinding {
+	if iface.Binding != "" {
+		return iface.Binding
+	}
+	if iface.NetworkName == "" {
+		return kubevirtproviderv1.InterfaceBindingMasquerade
+	}
+	return kubevirtproviderv1.InterfaceBindingBridge
+}
+
+// validateNetworkInterfaces returns an error if an interface is invalid, more than one is
+// attached to the pod network, or the pod network of an IPv6 or Dual machine does not use the
+// Masquerade binding.
+func validateNetworkInterfaces(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	names := map[string]bool{}
+	podNetwork := false
+	for i := range providerSpec.Interfaces {
+		iface := &providerSpec.Interfaces[i]
+		if errs := validation.IsDNS1123Label(iface.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid interface name %q: %s", iface.Name, strings.Join(errs, ", "))
+		}
+		if names[iface.Name] {
+			return fmt.Errorf("duplicate interface %q", iface.Name)
+		}
+		names[iface.Name] = true
+
+		if iface.MACAddress != "" {
+			if _, err := net.ParseMAC(iface.MACAddress); err != nil {
+				return fmt.Errorf("interface %q: invalid macAddress %q", iface.Name, iface.MACAddress)
+			}
+		}
+
+		binding := interfaceBinding(iface)
+		switch binding {
+		case kubevirtproviderv1.InterfaceBindingBridge, kubevirtproviderv1.InterfaceBindingMasquerade, kubevirtproviderv1.InterfaceBindingSRIOV:
+		default:
+			return fmt.Errorf("interface %q: unsupported binding %q, must be %s, %s or %s", iface.Name, binding,
+				kubevirtproviderv1.InterfaceBindingBridge, kubevirtproviderv1.InterfaceBindingMasquerade, kubevirtproviderv1.InterfaceBindingSRIOV)
+		}
+
+		if iface.NetworkName != "" {
+			if binding == kubevirtproviderv1.InterfaceBindingMasquerade {
+				return fmt.Errorf("interface %q: the %s binding is only supported on the pod network", iface.Name, binding)
+			}
+			for _, part := range strings.SplitN(iface.NetworkName, "/", 2) {
+				if errs := validation.IsDNS1123Subdomain(part); len(errs) > 0 {
+					return fmt.Errorf("interface %q: invalid networkName %q, must be <name> or <namespace>/<name>", iface.Name, iface.NetworkName)
+				}
+			}
+			continue
+		}
+
+		if podNetwork {
+			return fmt.Errorf("interface %q: only one interface can be attached to the pod network", iface.Name)
+		}
+		podNetwork = true
+		_, ipv6 := addressFamilies(providerSpec.AddressFamilyPolicy)
+		if providerSpec.AddressFamilyPolicy != "" && ipv6 && binding != kubevirtproviderv1.InterfaceBindingMasquerade {
+			return fmt.Errorf("interface %q: the pod network of addressFamilyPolicy %s requires the %s binding", iface.Name,
+				providerSpec.AddressFamilyPolicy, kubevirtproviderv1.InterfaceBindingMasquerade)
+		}
+	}
+	return nil
+}
+
+// applyNetworkInterfaces attaches the VMI to the networks of the interfaces.
+func applyNetworkInterfaces(spec *kubevirtapis.VirtualMachineInstanceSpec, interfaces []kubevirtproviderv1.NetworkInterface) {
+	for i := range interfaces {
+		iface := &interfaces[i]
+		vmiInterface := kubevirtapis.Interface{
+			Name:       iface.Name,
+			MacAddress: iface.MACAddress,
+		}
+		switch interfaceBinding(iface) {
+		case kubevirtproviderv1.InterfaceBindingMasquerade:
+			vmiInterface.Masquerade = &kubevirtapis.InterfaceMasquerade{}
+		case kubevirtproviderv1.InterfaceBindingSRIOV:
+			vmiInterface.SRIOV = &kubevirtapis.InterfaceSRIOV{}
+		default:
+			vmiInterface.Bridge = &kubevirtapis.InterfaceBridge{}
+		}
+
+		network := kubevirtapis.Network{Name: iface.Name}
+		if iface.NetworkName == "" {
+			network.Pod = &kubevirtapis.PodNetwork{}
+		} else {
+			network.Multus = &kubevirtapis.MultusNetwork{NetworkName: iface.NetworkName}
+		}
+
+		spec.Domain.Devices.Interfaces = append(spec.Domain.Devices.Interfaces, vmiInterface)
+		spec.Networks = append(spec.Networks, network)
+	}
+}
+
+// applyMasqueradeIPv6 adds the static IPv6 address and default route of the masquerade
+// network to the configuration of the guest interface attached to the pod network, under the
+// IPv6 and Dual address family policies.
+func applyMasqueradeIPv6(config *kubevirtproviderv1.InterfaceConfig, policy kubevirtproviderv1.AddressFamilyPolicy) {
+	if _, ipv6 := addressFamilies(policy); policy == "" || !ipv6 {
+		return
+	}
+	config.Addresses = appendMissing(config.Addresses, masqueradeIPv6Address)
+	for _, route := range config.Routes {
+		if route.To == "::/0" {
+			return
+		}
+	}
+	config.Routes = append(config.Routes, kubevirtproviderv1.Route{To: "::/0", Via: masqueradeIPv6Gateway})
+}
@@ -0,0 +1,151 @@
+package machine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateNetworkInterfaces(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		policy      kubevirtproviderv1.AddressFamilyPolicy
+		interfaces  []kubevirtproviderv1.NetworkInterface
+		expectError bool
+	}{
+		{
+			testcase: "pod network and secondary networks",
+			policy:   kubevirtproviderv1.AddressFamilyDual,
+			interfaces: []kubevirtproviderv1.NetworkInterface{
+				{Name: "default"},
+				{Name: "storage", NetworkName: "infra/storage", MACAddress: "02:00:00:00:00:01"},
+				{Name: "fast", NetworkName: "sriov", Binding: kubevirtproviderv1.InterfaceBindingSRIOV},
+			},
+		},
+		{
+			testcase:    "duplicate interface",
+			interfaces:  []kubevirtproviderv1.NetworkInterface{{Name: "default"}, {Name: "default", NetworkName: "storage"}},
+			expectError: true,
+		},
+		{
+			testcase:    "two pod networks",
+			interfaces:  []kubevirtproviderv1.NetworkInterface{{Name: "default"}, {Name: "other"}},
+			expectError: true,
+		},
+		{
+			testcase:    "masquerade on a secondary network",
+			interfaces:  []kubevirtproviderv1.NetworkInterface{{Name: "storage", NetworkName: "storage", Binding: kubevirtproviderv1.InterfaceBindingMasquerade}},
+			expectError: true,
+		},
+		{
+			testcase:    "bridged pod network of a dual stack machine",
+			policy:      kubevirtproviderv1.AddressFamilyDual,
+			interfaces:  []kubevirtproviderv1.NetworkInterface{{Name: "default", Binding: kubevirtproviderv1.InterfaceBindingBridge}},
+			expectError: true,
+		},
+		{
+			testcase:   "bridged pod network of an IPv4 machine",
+			policy:     kubevirtproviderv1.AddressFamilyIPv4,
+			interfaces: []kubevirtproviderv1.NetworkInterface{{Name: "default", Binding: kubevirtproviderv1.InterfaceBindingBridge}},
+		},
+		{
+			testcase:    "invalid network name",
+			interfaces:  []kubevirtproviderv1.NetworkInterface{{Name: "storage", NetworkName: "infra/storage/net"}},
+			expectError: true,
+		},
+		{
+			testcase:    "invalid MAC address",
+			interfaces:  []kubevirtproviderv1.NetworkInterface{{Name: "default", MACAddress: "02:00"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateNetworkInterfaces(&kubevirtproviderv1.KubevirtMachineProviderSpec{
+				AddressFamilyPolicy: tc.policy,
+				Interfaces:          tc.interfaces,
+			})
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMNetworkInterfaces(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubevirt-test",
+			Namespace: "kubevirt-test",
+		},
+	}
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		Interfaces: []kubevirtproviderv1.NetworkInterface{
+			{Name: "default"},
+			{Name: "storage", NetworkName: "infra/storage", MACAddress: "02:00:00:00:00:01"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedInterfaces := []kubevirtapis.Interface{
+		{
+			Name:                   "default",
+			InterfaceBindingMethod: kubevirtapis.InterfaceBindingMethod{Masquerade: &kubevirtapis.InterfaceMasquerade{}},
+		},
+		{
+			Name:                   "storage",
+			InterfaceBindingMethod: kubevirtapis.InterfaceBindingMethod{Bridge: &kubevirtapis.InterfaceBridge{}},
+			MacAddress:             "02:00:00:00:00:01",
+		},
+	}
+	if interfaces := vm.Spec.Template.Spec.Domain.Devices.Interfaces; !reflect.DeepEqual(interfaces, expectedInterfaces) {
+		t.Errorf("Expected interfaces %v, got %v", expectedInterfaces, interfaces)
+	}
+	expectedNetworks := []kubevirtapis.Network{
+		{Name: "default", NetworkSource: kubevirtapis.NetworkSource{Pod: &kubevirtapis.PodNetwork{}}},
+		{Name: "storage", NetworkSource: kubevirtapis.NetworkSource{Multus: &kubevirtapis.MultusNetwork{NetworkName: "infra/storage"}}},
+	}
+	if networks := vm.Spec.Template.Spec.Networks; !reflect.DeepEqual(networks, expectedNetworks) {
+		t.Errorf("Expected networks %v, got %v", expectedNetworks, networks)
+	}
+}
+
+func TestRenderNetworkDataMasqueradeIPv6(t *testing.T) {
+	networkData := &kubevirtproviderv1.NetworkData{
+		Ethernets: []kubevirtproviderv1.EthernetInterface{
+			{Name: "eth0", PodNetwork: true, InterfaceConfig: kubevirtproviderv1.InterfaceConfig{DHCP4: true}},
+			{Name: "eth1", InterfaceConfig: kubevirtproviderv1.InterfaceConfig{DHCP4: true}},
+		},
+	}
+
+	rendered, err := renderNetworkData(networkData, kubevirtproviderv1.AddressFamilyDual)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{masqueradeIPv6Address, "via: " + masqueradeIPv6Gateway} {
+		if strings.Count(string(rendered), expected) != 1 {
+			t.Errorf("Expected network data with %q once, got %s", expected, rendered)
+		}
+	}
+	if len(networkData.Ethernets[0].Addresses) > 0 {
+		t.Errorf("Expected the network data of the provider spec to be left untouched, got addresses %v", networkData.Ethernets[0].Addresses)
+	}
+
+	rendered, err = renderNetworkData(networkData, kubevirtproviderv1.AddressFamilyIPv4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(rendered), masqueradeIPv6Address) {
+		t.Errorf("Expected network data without the masquerade IPv6 address, got %s", rendered)
+	}
+}
@@ -0,0 +1,93 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/codec"
+)
+
+// validateNodeDrainTimeout returns an error if the timeout is not positive.
+func validateNodeDrainTimeout(timeout *metav1.Duration) error {
+	if timeout.Duration <= 0 {
+		return fmt.Errorf("nodeDrainTimeout must be positive, got %v", timeout.Duration)
+	}
+	return nil
+}
+
+// NodeDrainTimeout bounds the drain of the tenant Node of deleted machines. The machine
+// controller cordons and drains the Node before the actuator deletes the VM, retrying as long
+// as pods cannot be evicted, unless the machine has the exclude-node-draining annotation.
+// Once the nodeDrainTimeout of the provider spec elapsed since the deletion of the machine,
+// its drain is skipped by setting that annotation.
+type NodeDrainTimeout struct {
+	client    runtimeclient.Client
+	namespace string
+	interval  time.Duration
+}
+
+// NewNodeDrainTimeout returns a NodeDrainTimeout checking the machines in namespace, or in all
+// namespaces if empty, at the interval.
+func NewNodeDrainTimeout(client runtimeclient.Client, namespace string, interval time.Duration) *NodeDrainTimeout {
+	return &NodeDrainTimeout{
+		client:    client,
+		namespace: namespace,
+		interval:  interval,
+	}
+}
+
+// Start periodically skips the drain of the deleted machines whose drain timed out.
+// It implements manager.Runnable.
+func (d *NodeDrainTimeout) Start(stop <-chan struct{}) error {
+	wait.Until(func() { d.enforce(time.Now()) }, d.interval, stop)
+	return nil
+}
+
+func (d *NodeDrainTimeout) enforce(now time.Time) {
+	machines := &machinev1.MachineList{}
+	if err := d.client.List(context.Background(), machines, runtimeclient.InNamespace(d.namespace)); err != nil {
+		klog.Errorf("Failed to list machines to check their node drain timeout: %v", err)
+		return
+	}
+
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if !nodeDrainTimedOut(machine, now) {
+			continue
+		}
+		klog.Infof("%v: node %v was not drained within the node drain timeout, skipping the drain", machine.Name, machine.Status.NodeRef.Name)
+		patch := runtimeclient.MergeFrom(machine.DeepCopy())
+		if machine.Annotations == nil {
+			machine.Annotations = map[string]string{}
+		}
+		machine.Annotations[machinecontroller.ExcludeNodeDrainingAnnotation] = ""
+		if err := d.client.Patch(context.Background(), machine, patch); err != nil {
+			klog.Errorf("%v: failed to skip the node drain: %v", machine.Name, err)
+		}
+	}
+}
+
+// nodeDrainTimedOut returns true if the machine is being deleted, its Node is still being
+// drained and the node drain timeout of its provider spec elapsed since its deletion.
+func nodeDrainTimedOut(machine *machinev1.Machine, now time.Time) bool {
+	if machine.DeletionTimestamp == nil || machine.Status.NodeRef == nil {
+		return false
+	}
+	if _, skipped := machine.Annotations[machinecontroller.ExcludeNodeDrainingAnnotation]; skipped {
+		return false
+	}
+
+	providerSpec, err := codec.DecodeProviderSpec(machine.Spec.ProviderSpec.Value)
+	if err != nil || providerSpec.NodeDrainTimeout == nil || validateNodeDrainTimeout(providerSpec.NodeDrainTimeout) != nil {
+		return false
+	}
+	return now.Sub(machine.DeletionTimestamp.Time) >= providerSpec.NodeDrainTimeout.Duration
+}
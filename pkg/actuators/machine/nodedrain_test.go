@@ -0,0 +1,111 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestNodeDrainTimeout(t *testing.T) {
+	now := time.Now()
+	timeout := &metav1.Duration{Duration: 10 * time.Minute}
+
+	testCases := []struct {
+		testcase       string
+		deletedAgo     time.Duration
+		timeout        *metav1.Duration
+		annotations    map[string]string
+		noNode         bool
+		expectSkip     bool
+		expectTimedOut bool
+		notDeleted     bool
+	}{
+		{
+			testcase:       "skips the drain after the timeout",
+			deletedAgo:     11 * time.Minute,
+			timeout:        timeout,
+			expectSkip:     true,
+			expectTimedOut: true,
+		},
+		{
+			testcase:   "keeps draining within the timeout",
+			deletedAgo: 5 * time.Minute,
+			timeout:    timeout,
+		},
+		{
+			testcase:   "keeps draining without a timeout",
+			deletedAgo: time.Hour,
+		},
+		{
+			testcase:   "ignores machines which are not deleted",
+			timeout:    timeout,
+			notDeleted: true,
+		},
+		{
+			testcase:   "ignores machines without node",
+			deletedAgo: time.Hour,
+			timeout:    timeout,
+			noNode:     true,
+		},
+		{
+			testcase:    "ignores machines whose drain is skipped",
+			deletedAgo:  time.Hour,
+			timeout:     timeout,
+			annotations: map[string]string{machinecontroller.ExcludeNodeDrainingAnnotation: ""},
+			expectSkip:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			providerSpec, err := kubevirtproviderv1.RawExtensionFromProviderSpec(&kubevirtproviderv1.KubevirtMachineProviderSpec{NodeDrainTimeout: tc.timeout})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "worker-abcde",
+					Namespace:   "tenant-a",
+					Annotations: tc.annotations,
+				},
+				Spec: machinev1.MachineSpec{ProviderSpec: machinev1.ProviderSpec{Value: providerSpec}},
+			}
+			if !tc.notDeleted {
+				deletionTimestamp := metav1.NewTime(now.Add(-tc.deletedAgo))
+				machine.DeletionTimestamp = &deletionTimestamp
+			}
+			if !tc.noNode {
+				machine.Status.NodeRef = &corev1.ObjectReference{Name: "worker-abcde"}
+			}
+
+			if timedOut := nodeDrainTimedOut(machine, now); timedOut != tc.expectTimedOut {
+				t.Errorf("Expected timed out %v, got %v", tc.expectTimedOut, timedOut)
+			}
+
+			scheme := runtime.NewScheme()
+			if err := machinev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			client := fake.NewFakeClientWithScheme(scheme, machine)
+			NewNodeDrainTimeout(client, "tenant-a", time.Minute).enforce(now)
+
+			updated := &machinev1.Machine{}
+			if err := client.Get(context.Background(), types.NamespacedName{Namespace: "tenant-a", Name: "worker-abcde"}, updated); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, skipped := updated.Annotations[machinecontroller.ExcludeNodeDrainingAnnotation]; skipped != tc.expectSkip {
+				t.Errorf("Expected drain skipped %v, got %v", tc.expectSkip, skipped)
+			}
+		})
+	}
+}
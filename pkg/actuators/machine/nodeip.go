@@ -0,0 +1,161 @@
+package machine
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// kubeletNodeIPDropInPath is the kubelet systemd drop-in setting KUBELET_NODE_IP,
+	// which the kubelet unit passes to --node-ip.
+	kubeletNodeIPDropInPath = "/etc/systemd/system/kubelet.service.d/20-nodenet.conf"
+	nodeUsernamePrefix      = "system:node:"
+)
+
+// parseStaticIPAddresses validates the static IP addresses and returns them in canonical form.
+func parseStaticIPAddresses(addresses []string) ([]string, error) {
+	ips := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid static IP address %q", address)
+		}
+		ips = append(ips, ip.String())
+	}
+	return ips, nil
+}
+
+func kubeletNodeIPDropIn(ips []string) string {
+	return fmt.Sprintf("[Service]\nEnvironment=\"KUBELET_NODE_IP=%s\"\n", strings.Join(ips, ","))
+}
+
+// injectNodeIPs adds a kubelet drop-in pinning the node IPs to the static IP addresses of the
+// machine, so that the kubelet serving certificate is requested with them as SANs.
+func injectNodeIPs(userData []byte, format kubevirtproviderv1.UserDataFormat, addresses []string) ([]byte, error) {
+	if len(addresses) == 0 {
+		return userData, nil
+	}
+
+	ips, err := parseStaticIPAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case kubevirtproviderv1.UserDataFormatIgnition:
+		return injectIgnitionNodeIPs(userData, ips)
+	default:
+		return injectCloudConfigNodeIPs(userData, ips)
+	}
+}
+
+func injectCloudConfigNodeIPs(userData []byte, ips []string) ([]byte, error) {
+	return editCloudConfig(userData, func(cloudConfig map[string]interface{}) {
+		writeFiles, _ := cloudConfig["write_files"].([]interface{})
+		cloudConfig["write_files"] = append(writeFiles, map[string]interface{}{
+			"path":        kubeletNodeIPDropInPath,
+			"permissions": "0644",
+			"content":     kubeletNodeIPDropIn(ips),
+		})
+	})
+}
+
+func injectIgnitionNodeIPs(userData []byte, ips []string) ([]byte, error) {
+	return editIgnitionConfig(userData, func(ignitionConfig map[string]interface{}, version string) {
+		appendIgnitionFile(ignitionConfig, version, kubeletNodeIPDropInPath, kubeletNodeIPDropIn(ips))
+	})
+}
+
+// latestServingCSR returns the most recent kubelet serving certificate request of the node.
+func latestServingCSR(nodeName string, csrs []certificatesv1beta1.CertificateSigningRequest) *certificatesv1beta1.CertificateSigningRequest {
+	var latest *certificatesv1beta1.CertificateSigningRequest
+	for i := range csrs {
+		csr := &csrs[i]
+		if csr.Spec.Username != nodeUsernamePrefix+nodeName || !hasUsage(csr, certificatesv1beta1.UsageServerAuth) {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&csr.CreationTimestamp) {
+			latest = csr
+		}
+	}
+	return latest
+}
+
+func hasUsage(csr *certificatesv1beta1.CertificateSigningRequest, usage certificatesv1beta1.KeyUsage) bool {
+	for _, u := range csr.Spec.Usages {
+		if u == usage {
+			return true
+		}
+	}
+	return false
+}
+
+// missingCertificateSANs returns the static IP addresses not covered by the IP SANs of the request.
+func missingCertificateSANs(csr *certificatesv1beta1.CertificateSigningRequest, ips []string) ([]string, error) {
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil {
+		return nil, fmt.Errorf("certificate request %s is not PEM encoded", csr.Name)
+	}
+
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate request %s: %v", csr.Name, err)
+	}
+
+	sans := map[string]bool{}
+	for _, ip := range request.IPAddresses {
+		sans[ip.String()] = true
+	}
+
+	missing := []string{}
+	for _, ip := range ips {
+		if !sans[ip] {
+			missing = append(missing, ip)
+		}
+	}
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+// certificateSANsCondition computes the KubeletCertificateSANs condition from the latest serving
+// certificate request of the node.
+func certificateSANsCondition(nodeName string, csrs []certificatesv1beta1.CertificateSigningRequest, ips []string) (kubevirtproviderv1.KubevirtMachineProviderCondition, error) {
+	condition := kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type: kubevirtproviderv1.KubeletCertificateSANs,
+	}
+
+	csr := latestServingCSR(nodeName, csrs)
+	if csr == nil {
+		condition.Status = corev1.ConditionUnknown
+		condition.Reason = kubevirtproviderv1.CertificateNotFound
+		condition.Message = fmt.Sprintf("No kubelet serving certificate request found for node %s", nodeName)
+		return condition, nil
+	}
+
+	missing, err := missingCertificateSANs(csr, ips)
+	if err != nil {
+		return condition, err
+	}
+
+	if len(missing) > 0 {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = kubevirtproviderv1.CertificateSANsMismatch
+		condition.Message = fmt.Sprintf("Kubelet serving certificate request %s is missing IP SANs %s", csr.Name, strings.Join(missing, ", "))
+		return condition, nil
+	}
+
+	condition.Status = corev1.ConditionTrue
+	condition.Reason = kubevirtproviderv1.CertificateSANsMatch
+	condition.Message = fmt.Sprintf("Kubelet serving certificate request %s covers all static IP addresses", csr.Name)
+	return condition, nil
+}
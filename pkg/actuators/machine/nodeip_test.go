@@ -0,0 +1,195 @@
+package machine
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"strings"
+	"testing"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestInjectNodeIPs(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		format      kubevirtproviderv1.UserDataFormat
+		userData    string
+		addresses   []string
+		expectError bool
+	}{
+		{
+			testcase:  "cloud-config",
+			format:    kubevirtproviderv1.UserDataFormatCloudInit,
+			userData:  "#cloud-config\nwrite_files:\n- path: /etc/foo\n  content: bar\n",
+			addresses: []string{"192.168.1.10", "fd00::10"},
+		},
+		{
+			testcase:  "empty cloud-init",
+			format:    kubevirtproviderv1.UserDataFormatCloudInit,
+			addresses: []string{"192.168.1.10"},
+		},
+		{
+			testcase:  "ignition v3",
+			format:    kubevirtproviderv1.UserDataFormatIgnition,
+			userData:  `{"ignition":{"version":"3.1.0"}}`,
+			addresses: []string{"192.168.1.10"},
+		},
+		{
+			testcase:  "ignition v2",
+			format:    kubevirtproviderv1.UserDataFormatIgnition,
+			userData:  `{"ignition":{"version":"2.2.0"},"storage":{"files":[{"path":"/etc/foo"}]}}`,
+			addresses: []string{"192.168.1.10"},
+		},
+		{
+			testcase:    "script",
+			format:      kubevirtproviderv1.UserDataFormatCloudInit,
+			userData:    "#!/bin/bash\necho hello\n",
+			addresses:   []string{"192.168.1.10"},
+			expectError: true,
+		},
+		{
+			testcase:    "invalid address",
+			format:      kubevirtproviderv1.UserDataFormatCloudInit,
+			userData:    "#cloud-config\n",
+			addresses:   []string{"not-an-ip"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			userData, err := injectNodeIPs([]byte(tc.userData), tc.format, tc.addresses)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var files []interface{}
+			switch tc.format {
+			case kubevirtproviderv1.UserDataFormatIgnition:
+				ignitionConfig := map[string]interface{}{}
+				if err := json.Unmarshal(userData, &ignitionConfig); err != nil {
+					t.Fatalf("Failed to parse rendered Ignition config: %v", err)
+				}
+				files = ignitionConfig["storage"].(map[string]interface{})["files"].([]interface{})
+			default:
+				if !strings.HasPrefix(string(userData), cloudConfigHeader) {
+					t.Errorf("Expected rendered user data to start with %q", cloudConfigHeader)
+				}
+				cloudConfig := map[string]interface{}{}
+				if err := yaml.Unmarshal(userData, &cloudConfig); err != nil {
+					t.Fatalf("Failed to parse rendered cloud-config: %v", err)
+				}
+				files = cloudConfig["write_files"].([]interface{})
+			}
+
+			dropIn := files[len(files)-1].(map[string]interface{})
+			if dropIn["path"] != kubeletNodeIPDropInPath {
+				t.Errorf("Expected last file to be %q, got %q", kubeletNodeIPDropInPath, dropIn["path"])
+			}
+			if !strings.Contains(string(userData), tc.addresses[0]) {
+				t.Errorf("Expected rendered user data to contain %q", tc.addresses[0])
+			}
+		})
+	}
+}
+
+func servingCSR(t *testing.T, name, nodeName string, ips ...string) certificatesv1beta1.CertificateSigningRequest {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: nodeUsernamePrefix + nodeName},
+	}
+	for _, ip := range ips {
+		template.IPAddresses = append(template.IPAddresses, net.ParseIP(ip))
+	}
+	request, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate request: %v", err)
+	}
+
+	return certificatesv1beta1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+			Request:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: request}),
+			Username: nodeUsernamePrefix + nodeName,
+			Usages: []certificatesv1beta1.KeyUsage{
+				certificatesv1beta1.UsageDigitalSignature,
+				certificatesv1beta1.UsageKeyEncipherment,
+				certificatesv1beta1.UsageServerAuth,
+			},
+		},
+	}
+}
+
+func TestCertificateSANsCondition(t *testing.T) {
+	ips := []string{"192.168.1.10"}
+
+	testCases := []struct {
+		testcase       string
+		csrs           []certificatesv1beta1.CertificateSigningRequest
+		expectedStatus corev1.ConditionStatus
+		expectedReason kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase:       "no request",
+			expectedStatus: corev1.ConditionUnknown,
+			expectedReason: kubevirtproviderv1.CertificateNotFound,
+		},
+		{
+			testcase:       "other node",
+			csrs:           []certificatesv1beta1.CertificateSigningRequest{servingCSR(t, "csr-1", "other", "192.168.1.10")},
+			expectedStatus: corev1.ConditionUnknown,
+			expectedReason: kubevirtproviderv1.CertificateNotFound,
+		},
+		{
+			testcase:       "match",
+			csrs:           []certificatesv1beta1.CertificateSigningRequest{servingCSR(t, "csr-1", "node", "192.168.1.10", "10.0.0.1")},
+			expectedStatus: corev1.ConditionTrue,
+			expectedReason: kubevirtproviderv1.CertificateSANsMatch,
+		},
+		{
+			testcase:       "mismatch",
+			csrs:           []certificatesv1beta1.CertificateSigningRequest{servingCSR(t, "csr-1", "node", "10.0.0.1")},
+			expectedStatus: corev1.ConditionFalse,
+			expectedReason: kubevirtproviderv1.CertificateSANsMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			condition, err := certificateSANsCondition("node", tc.csrs, ips)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if condition.Status != tc.expectedStatus {
+				t.Errorf("Expected condition status %q, got %q", tc.expectedStatus, condition.Status)
+			}
+			if condition.Reason != tc.expectedReason {
+				t.Errorf("Expected condition reason %q, got %q", tc.expectedReason, condition.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package machine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// machineRoleLabel is set on machines to their role, e.g. worker or infra
+	machineRoleLabel = "machine.openshift.io/cluster-api-machine-role"
+	// nodeRoleLabelPrefix prefixes the role of a node in its node-role label, which the
+	// kubelet is not allowed to set on its own node
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+)
+
+// validateNodeLabels returns an error if a node label is not a valid label.
+func validateNodeLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid nodeLabels key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid nodeLabels value %q of %s: %s", value, key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// nodeLabelsForMachine returns the labels set onto the Node of the machine: the node-role label
+// of the machine role, the labels of the machine spec and the node labels of the provider spec,
+// which take precedence.
+func nodeLabelsForMachine(machine *machinev1.Machine, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) map[string]string {
+	labels := map[string]string{}
+	if role := machine.Labels[machineRoleLabel]; role != "" && len(validation.IsQualifiedName(nodeRoleLabelPrefix+role)) == 0 {
+		labels[nodeRoleLabelPrefix+role] = ""
+	}
+	for key, value := range machine.Spec.Labels {
+		labels[key] = value
+	}
+	for key, value := range providerSpec.NodeLabels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// reconcileNodeLabels sets the labels of the machine onto its Node once it joined the tenant cluster.
+func (r *Reconciler) reconcileNodeLabels() error {
+	nodeName := nodeNameForMachine(r.machine.Name, r.machine.Status.NodeRef)
+	if nodeName == "" {
+		return nil
+	}
+	labels := nodeLabelsForMachine(r.machine, r.providerSpec)
+	if len(labels) == 0 {
+		return nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.client.Get(r.Context, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	var changed []string
+	patch := runtimeclient.MergeFrom(node.DeepCopy())
+	for key, value := range labels {
+		if current, ok := node.Labels[key]; ok && current == value {
+			continue
+		}
+		if node.Labels == nil {
+			node.Labels = map[string]string{}
+		}
+		node.Labels[key] = value
+		changed = append(changed, key)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Strings(changed)
+	r.log().Info("setting machine labels on node", "node", nodeName, "labels", strings.Join(changed, ", "))
+	if err := r.client.Patch(r.Context, node, patch); err != nil {
+		return fmt.Errorf("failed to set labels of node %s: %w", nodeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package machine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateNodeLabels(t *testing.T) {
+	if err := validateNodeLabels(map[string]string{"pool.example.com/name": "gpu", "node-role.kubernetes.io/gpu": ""}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateNodeLabels(map[string]string{"pool name": "gpu"}); err == nil {
+		t.Error("Expected an error for an invalid key")
+	}
+	if err := validateNodeLabels(map[string]string{"pool": "gpu pool"}); err == nil {
+		t.Error("Expected an error for an invalid value")
+	}
+}
+
+func TestReconcileNodeLabels(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		nodeLabels   map[string]string
+		machineRole  string
+		specLabels   map[string]string
+		providerSpec map[string]string
+		expectLabels map[string]string
+	}{
+		{
+			testcase:     "role label",
+			nodeLabels:   map[string]string{"kubernetes.io/hostname": "worker-abcde"},
+			machineRole:  "worker",
+			expectLabels: map[string]string{"kubernetes.io/hostname": "worker-abcde", "node-role.kubernetes.io/worker": ""},
+		},
+		{
+			testcase:     "machine spec and provider spec labels",
+			nodeLabels:   map[string]string{"pool": "old"},
+			specLabels:   map[string]string{"pool": "cpu", "zone": "a"},
+			providerSpec: map[string]string{"pool": "gpu"},
+			expectLabels: map[string]string{"pool": "gpu", "zone": "a"},
+		},
+		{
+			testcase:     "no labels",
+			nodeLabels:   map[string]string{"kubernetes.io/hostname": "worker-abcde"},
+			expectLabels: map[string]string{"kubernetes.io/hostname": "worker-abcde"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Labels: tc.nodeLabels}}
+			client := fake.NewFakeClientWithScheme(scheme, node)
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "worker-abcde",
+					Namespace: "openshift-machine-api",
+					Labels:    map[string]string{machineRoleLabel: tc.machineRole},
+				},
+				Spec: machinev1.MachineSpec{ObjectMeta: machinev1.ObjectMeta{Labels: tc.specLabels}},
+			}
+			r := newReconciler(&machineScope{
+				Context:      context.Background(),
+				client:       client,
+				machine:      machine,
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{NodeLabels: tc.providerSpec},
+			})
+
+			if err := r.reconcileNodeLabels(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			updated := &corev1.Node{}
+			if err := client.Get(context.Background(), types.NamespacedName{Name: "worker-abcde"}, updated); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(updated.Labels, tc.expectLabels) {
+				t.Errorf("Expected node labels %v, got %v", tc.expectLabels, updated.Labels)
+			}
+		})
+	}
+}
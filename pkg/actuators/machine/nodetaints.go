@@ -0,0 +1,94 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// validateNodeTaints returns an error if a node taint is invalid or set twice for the same key
+// and effect.
+func validateNodeTaints(taints []corev1.Taint) error {
+	seen := map[string]bool{}
+	for _, taint := range taints {
+		if errs := validation.IsQualifiedName(taint.Key); len(errs) > 0 {
+			return fmt.Errorf("invalid nodeTaints key %q: %s", taint.Key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(taint.Value); len(errs) > 0 {
+			return fmt.Errorf("invalid nodeTaints value %q of %s: %s", taint.Value, taint.Key, strings.Join(errs, ", "))
+		}
+		switch taint.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("unsupported nodeTaints effect %q of %s, must be one of %q, %q or %q", taint.Effect, taint.Key,
+				corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute)
+		}
+		id := taint.Key + ":" + string(taint.Effect)
+		if seen[id] {
+			return fmt.Errorf("duplicate nodeTaints %s", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// applyNodeTaints adds the taints to the node, or updates the value of the taints of the node
+// with the same key and effect, and returns the taints which changed.
+func applyNodeTaints(node *corev1.Node, taints []corev1.Taint) []string {
+	var changed []string
+	for _, taint := range taints {
+		found := false
+		for i := range node.Spec.Taints {
+			current := &node.Spec.Taints[i]
+			if current.Key != taint.Key || current.Effect != taint.Effect {
+				continue
+			}
+			found = true
+			if current.Value != taint.Value {
+				current.Value = taint.Value
+				changed = append(changed, taint.ToString())
+			}
+		}
+		if !found {
+			node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: taint.Key, Value: taint.Value, Effect: taint.Effect})
+			changed = append(changed, taint.ToString())
+		}
+	}
+	return changed
+}
+
+// reconcileNodeTaints applies the node taints of the provider spec to the Node of the machine
+// once it registered with the tenant cluster.
+func (r *Reconciler) reconcileNodeTaints() error {
+	if len(r.providerSpec.NodeTaints) == 0 {
+		return nil
+	}
+	nodeName := nodeNameForMachine(r.machine.Name, r.machine.Status.NodeRef)
+	if nodeName == "" {
+		return nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.client.Get(r.Context, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	patch := runtimeclient.MergeFrom(node.DeepCopy())
+	changed := applyNodeTaints(node, r.providerSpec.NodeTaints)
+	if len(changed) == 0 {
+		return nil
+	}
+	r.log().Info("tainting node", "node", nodeName, "taints", strings.Join(changed, ", "))
+	if err := r.client.Patch(r.Context, node, patch); err != nil {
+		return fmt.Errorf("failed to taint node %s: %w", nodeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package machine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateNodeTaints(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		taints      []corev1.Taint
+		expectError bool
+	}{
+		{
+			testcase: "valid taints",
+			taints: []corev1.Taint{
+				{Key: "nvidia.com/gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "nvidia.com/gpu", Value: "true", Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+		{
+			testcase:    "invalid key",
+			taints:      []corev1.Taint{{Key: "gpu pool", Effect: corev1.TaintEffectNoSchedule}},
+			expectError: true,
+		},
+		{
+			testcase:    "missing effect",
+			taints:      []corev1.Taint{{Key: "dedicated", Value: "infra"}},
+			expectError: true,
+		},
+		{
+			testcase: "duplicate taint",
+			taints: []corev1.Taint{
+				{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			if err := validateNodeTaints(tc.taints); (err != nil) != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestReconcileNodeTaints(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"},
+		Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+			{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		}},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, node)
+	r := newReconciler(&machineScope{
+		Context: context.Background(),
+		client:  client,
+		machine: &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api"}},
+		providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{NodeTaints: []corev1.Taint{
+			{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoExecute},
+		}},
+	})
+
+	if err := r.reconcileNodeTaints(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	updated := &corev1.Node{}
+	if err := client.Get(context.Background(), types.NamespacedName{Name: "worker-abcde"}, updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []corev1.Taint{
+		{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoExecute},
+	}
+	if !reflect.DeepEqual(updated.Spec.Taints, expected) {
+		t.Errorf("Expected node taints %v, got %v", expected, updated.Spec.Taints)
+	}
+}
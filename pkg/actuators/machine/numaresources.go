@@ -0,0 +1,263 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// numaResourceRequeueAfter is the delay of the next NUMA resource check of a machine
+	// whose VM does not fit any infra node
+	numaResourceRequeueAfter = time.Minute
+	// numaZoneType is the type of the NodeResourceTopology zones of NUMA nodes
+	numaZoneType = "Node"
+)
+
+// nodeResourceTopology is the part of a NodeResourceTopology the NUMA resource check reads.
+type nodeResourceTopology struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Zones             []numaZone `json:"zones,omitempty"`
+}
+
+type numaZone struct {
+	Name      string         `json:"name"`
+	Type      string         `json:"type"`
+	Resources []zoneResource `json:"resources,omitempty"`
+}
+
+type zoneResource struct {
+	Name      string            `json:"name"`
+	Available resource.Quantity `json:"available"`
+}
+
+// numaRequirements are the resources the VM needs on a single NUMA node.
+type numaRequirements struct {
+	hugepagesResource corev1.ResourceName
+	hugepages         resource.Quantity
+	dedicatedCPUs     int64
+}
+
+// validateHugepages returns an error if the page size is unsupported or the requested memory
+// is not a multiple of it.
+func validateHugepages(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	pageSize, err := resource.ParseQuantity(providerSpec.Hugepages.PageSize)
+	if err != nil || (pageSize.String() != "2Mi" && pageSize.String() != "1Gi") {
+		return fmt.Errorf("unsupported hugepages pageSize %q, must be 2Mi or 1Gi", providerSpec.Hugepages.PageSize)
+	}
+	memory, err := requestedMemory(providerSpec)
+	if err != nil {
+		return err
+	}
+	if memory.Value()%pageSize.Value() != 0 {
+		return fmt.Errorf("requestedMemory %s is not a multiple of the hugepages pageSize %s", memory.String(), pageSize.String())
+	}
+	return nil
+}
+
+// requestedMemory returns the memory of the VM.
+func requestedMemory(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (resource.Quantity, error) {
+	requested := providerSpec.RequestedMemory
+	if requested == "" {
+		requested = defaultRequestedMemory
+	}
+	memory, err := resource.ParseQuantity(requested)
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("invalid requestedMemory %q: %v", requested, err)
+	}
+	return memory, nil
+}
+
+// validateNUMAResourceCheck returns an error if the provider spec requests no NUMA bound resource.
+func validateNUMAResourceCheck(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	if providerSpec.Hugepages == nil && !providerSpec.DedicatedCPUPlacement {
+		return fmt.Errorf("numaResourceCheck requires hugepages or dedicatedCpuPlacement")
+	}
+	return nil
+}
+
+// setHugepages backs the memory of the VMI with the hugepages of the provider spec.
+func setHugepages(spec *kubevirtapis.VirtualMachineInstanceSpec, hugepages *kubevirtproviderv1.Hugepages) {
+	if spec.Domain.Memory == nil {
+		spec.Domain.Memory = &kubevirtapis.Memory{}
+	}
+	spec.Domain.Memory.Hugepages = &kubevirtapis.Hugepages{PageSize: hugepages.PageSize}
+}
+
+// resolveNUMARequirements returns the resources the VM of the provider spec needs on a single
+// NUMA node.
+func resolveNUMARequirements(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (*numaRequirements, error) {
+	requirements := &numaRequirements{}
+	if providerSpec.Hugepages != nil {
+		if err := validateHugepages(providerSpec); err != nil {
+			return nil, err
+		}
+		pageSize := resource.MustParse(providerSpec.Hugepages.PageSize)
+		requirements.hugepagesResource = corev1.ResourceName(corev1.ResourceHugePagesPrefix + pageSize.String())
+		requirements.hugepages, _ = requestedMemory(providerSpec)
+	}
+	if providerSpec.DedicatedCPUPlacement {
+		requirements.dedicatedCPUs = int64(providerSpec.RequestedCPU)
+		if requirements.dedicatedCPUs == 0 {
+			requirements.dedicatedCPUs = int64(defaultRequestedCPU)
+		}
+	}
+	return requirements, nil
+}
+
+// String describes the requirements for the InsufficientResources messages.
+func (n *numaRequirements) String() string {
+	var hugepages, cpus string
+	if n.hugepagesResource != "" {
+		hugepages = fmt.Sprintf("%s of %s", n.hugepages.String(), n.hugepagesResource)
+	}
+	if n.dedicatedCPUs > 0 {
+		cpus = fmt.Sprintf("%d dedicated CPUs", n.dedicatedCPUs)
+	}
+	switch {
+	case hugepages != "" && cpus != "":
+		return hugepages + " and " + cpus
+	case hugepages != "":
+		return hugepages
+	default:
+		return cpus
+	}
+}
+
+// zoneAvailable returns the available quantity of the resource on the NUMA node.
+func zoneAvailable(zone numaZone, name corev1.ResourceName) resource.Quantity {
+	for _, r := range zone.Resources {
+		if r.Name == string(name) {
+			return r.Available
+		}
+	}
+	return resource.Quantity{}
+}
+
+// numaFit tells which constraints the NUMA nodes of the infra nodes failed.
+type numaFit struct {
+	nodes                int
+	zones                int
+	insufficientMemory   int
+	insufficientCPUs     int
+	nodesWithoutTopology int
+}
+
+// fits returns true if the NUMA node has the required resources free, otherwise it counts the
+// failed constraints.
+func (f *numaFit) fits(zone numaZone, requirements *numaRequirements) bool {
+	f.zones++
+	fits := true
+	if requirements.hugepagesResource != "" {
+		available := zoneAvailable(zone, requirements.hugepagesResource)
+		if available.Cmp(requirements.hugepages) < 0 {
+			f.insufficientMemory++
+			fits = false
+		}
+	}
+	if requirements.dedicatedCPUs > 0 {
+		available := zoneAvailable(zone, corev1.ResourceCPU)
+		if available.Value() < requirements.dedicatedCPUs {
+			f.insufficientCPUs++
+			fits = false
+		}
+	}
+	return fits
+}
+
+// message returns the InsufficientResources message naming the failed constraints.
+func (f *numaFit) message(requirements *numaRequirements) string {
+	if f.nodes == 0 {
+		return fmt.Sprintf("no schedulable infra node matches the node selector, %s are required on a single NUMA node", requirements)
+	}
+	message := fmt.Sprintf("none of the %d infra nodes matching the node selector has %s free on a single NUMA node", f.nodes, requirements)
+	if requirements.hugepagesResource != "" {
+		message += fmt.Sprintf(", %s insufficient on %d of %d NUMA nodes", requirements.hugepagesResource, f.insufficientMemory, f.zones)
+	}
+	if requirements.dedicatedCPUs > 0 {
+		message += fmt.Sprintf(", dedicated CPUs insufficient on %d of %d NUMA nodes", f.insufficientCPUs, f.zones)
+	}
+	if f.nodesWithoutTopology > 0 {
+		message += fmt.Sprintf(", %d nodes report no NodeResourceTopology", f.nodesWithoutTopology)
+	}
+	return message
+}
+
+// parseNodeResourceTopologies returns the NodeResourceTopology objects by node name.
+func parseNodeResourceTopologies(list *unstructured.UnstructuredList) (map[string]*nodeResourceTopology, error) {
+	topologies := map[string]*nodeResourceTopology{}
+	for i := range list.Items {
+		raw, err := json.Marshal(list.Items[i].Object)
+		if err != nil {
+			return nil, err
+		}
+		topology := &nodeResourceTopology{}
+		if err := json.Unmarshal(raw, topology); err != nil {
+			return nil, fmt.Errorf("failed to parse NodeResourceTopology %s: %v", list.Items[i].GetName(), err)
+		}
+		topologies[topology.Name] = topology
+	}
+	return topologies, nil
+}
+
+// checkNUMAResources returns a RequeueAfterError and sets the InsufficientResources condition
+// unless an infra node matching the node selector has the resources of the VM free on a single
+// NUMA node.
+func (r *Reconciler) checkNUMAResources() error {
+	requirements, err := resolveNUMARequirements(r.providerSpec)
+	if err != nil {
+		return machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+
+	selector := labels.SelectorFromSet(r.providerSpec.NodeSelector).String()
+	nodes, err := r.kubevirtClient.ListNodes(r.Context, &metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list infra nodes: %w", err)
+	}
+	list, err := r.kubevirtClient.ListNodeResourceTopologies(r.Context, &metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list NodeResourceTopologies: %w", err)
+	}
+	topologies, err := parseNodeResourceTopologies(list)
+	if err != nil {
+		return err
+	}
+
+	fit := &numaFit{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		fit.nodes++
+		topology, ok := topologies[node.Name]
+		if !ok {
+			fit.nodesWithoutTopology++
+			continue
+		}
+		for _, zone := range topology.Zones {
+			if zone.Type == numaZoneType && fit.fits(zone, requirements) {
+				klog.V(3).Infof("%s: NUMA node %s of infra node %s fits %s", r.machine.Name, zone.Name, node.Name, requirements)
+				return nil
+			}
+		}
+	}
+
+	message := fit.message(requirements)
+	klog.Warningf("%s: %s", r.machine.Name, message)
+	condition := conditionFailed()
+	condition.Reason = kubevirtproviderv1.InsufficientResources
+	condition.Message = message
+	r.machineScope.setProviderStatus(condition)
+	return &machinecontroller.RequeueAfterError{RequeueAfter: numaResourceRequeueAfter}
+}
@@ -0,0 +1,116 @@
+package machine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func newNodeResourceTopology(node string, zones ...map[string]interface{}) unstructured.Unstructured {
+	items := []interface{}{}
+	for _, zone := range zones {
+		items = append(items, zone)
+	}
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "topology.node.k8s.io/v1alpha1",
+		"kind":       "NodeResourceTopology",
+		"metadata":   map[string]interface{}{"name": node},
+		"zones":      items,
+	}}
+}
+
+func newNUMAZone(name, hugepages, cpus string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"type": "Node",
+		"resources": []interface{}{
+			map[string]interface{}{"name": "hugepages-1Gi", "capacity": "32Gi", "allocatable": "32Gi", "available": hugepages},
+			map[string]interface{}{"name": "cpu", "capacity": "16", "allocatable": "16", "available": cpus},
+		},
+	}
+}
+
+func TestCheckNUMAResources(t *testing.T) {
+	testCases := []struct {
+		testcase        string
+		topologies      []unstructured.Unstructured
+		expectedFit     bool
+		expectedMessage string
+	}{
+		{
+			testcase: "fits a single NUMA node",
+			topologies: []unstructured.Unstructured{
+				newNodeResourceTopology("infra-a", newNUMAZone("node-0", "4Gi", "8"), newNUMAZone("node-1", "16Gi", "2")),
+				newNodeResourceTopology("infra-b", newNUMAZone("node-0", "8Gi", "6")),
+			},
+			expectedFit: true,
+		},
+		{
+			testcase: "resources split across NUMA nodes",
+			topologies: []unstructured.Unstructured{
+				newNodeResourceTopology("infra-a", newNUMAZone("node-0", "4Gi", "8"), newNUMAZone("node-1", "16Gi", "2")),
+			},
+			expectedMessage: "none of the 2 infra nodes matching the node selector has 8Gi of hugepages-1Gi and 4 dedicated CPUs free on a single NUMA node, hugepages-1Gi insufficient on 1 of 2 NUMA nodes, dedicated CPUs insufficient on 1 of 2 NUMA nodes, 1 nodes report no NodeResourceTopology",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			client.EXPECT().ListNodes(gomock.Any(), &metav1.ListOptions{LabelSelector: "pool=numa"}).Return(&corev1.NodeList{Items: []corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "infra-a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "infra-b"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "infra-c"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+			}}, nil)
+			client.EXPECT().ListNodeResourceTopologies(gomock.Any(), gomock.Any()).Return(&unstructured.UnstructuredList{Items: tc.topologies}, nil)
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}},
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					RequestedMemory:       "8Gi",
+					RequestedCPU:          4,
+					DedicatedCPUPlacement: true,
+					Hugepages:             &kubevirtproviderv1.Hugepages{PageSize: "1Gi"},
+					NUMAResourceCheck:     true,
+					NodeSelector:          map[string]string{"pool": "numa"},
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			err := r.checkNUMAResources()
+			if tc.expectedFit {
+				if err != nil {
+					t.Errorf("Expected the VM to fit, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected a requeue, got nil")
+			}
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.MachineCreation)
+			if condition == nil || condition.Reason != kubevirtproviderv1.InsufficientResources {
+				t.Fatalf("Expected MachineCreation condition with reason %s, got %v", kubevirtproviderv1.InsufficientResources, condition)
+			}
+			if condition.Message != tc.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tc.expectedMessage, condition.Message)
+			}
+		})
+	}
+
+	err := validateHugepages(&kubevirtproviderv1.KubevirtMachineProviderSpec{Hugepages: &kubevirtproviderv1.Hugepages{PageSize: "1Gi"}})
+	if err == nil || !strings.Contains(err.Error(), "not a multiple") {
+		t.Errorf("Expected error for the default memory backed by 1Gi hugepages, got %v", err)
+	}
+}
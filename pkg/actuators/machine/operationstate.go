@@ -0,0 +1,163 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// OperationStateVersion is the version of the encoding of the state of in-flight operations,
+	// such as cold migrations, the actuator keeps in the provider status of machines. It has to
+	// be bumped on any change a provider of the previous version cannot resume from.
+	OperationStateVersion = 1
+	// minOperationStateVersion is the oldest encoding of in-flight operation state this
+	// provider resumes from.
+	minOperationStateVersion = 1
+	// operationStateVersionAnnotation records the encoding version of the in-flight operation
+	// state of a machine. Machines without it were written by version 1.
+	operationStateVersionAnnotation = "kubevirtproviderconfig.openshift.io/operation-state-version"
+	// operationGatedRequeue is the delay after which operations refused by a paused gate are retried.
+	operationGatedRequeue = 10 * time.Second
+)
+
+// operationInFlight returns true if the provider status holds the state of an operation the
+// actuator resumes on the next reconcile.
+func operationInFlight(status *kubevirtproviderv1.KubevirtMachineProviderStatus) bool {
+	return status != nil && coldMigrationInProgress(status.ColdMigration)
+}
+
+// setOperationStateVersion records the encoding version of the in-flight operation state of
+// the machine.
+func setOperationStateVersion(machine *machinev1.Machine, status *kubevirtproviderv1.KubevirtMachineProviderStatus) {
+	if !operationInFlight(status) {
+		return
+	}
+	if machine.Annotations == nil {
+		machine.Annotations = map[string]string{}
+	}
+	machine.Annotations[operationStateVersionAnnotation] = strconv.Itoa(OperationStateVersion)
+}
+
+// operationStateVersion returns the encoding version of the in-flight operation state of the machine.
+func operationStateVersion(machine *machinev1.Machine) (int, error) {
+	value, ok := machine.Annotations[operationStateVersionAnnotation]
+	if !ok {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation %q", operationStateVersionAnnotation, value)
+	}
+	return version, nil
+}
+
+// CheckOperationStateCompatibility returns an error if any machine of the namespace, or of all
+// namespaces if empty, has an in-flight operation whose state this provider cannot resume.
+// It is run before the provider takes part in leader election, so that an incompatible
+// version never takes over reconciliation in the middle of an operation.
+func CheckOperationStateCompatibility(ctx context.Context, client runtimeclient.Reader, namespace string) error {
+	machines := &machinev1.MachineList{}
+	if err := client.List(ctx, machines, runtimeclient.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list machines: %w", err)
+	}
+
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		status, err := kubevirtproviderv1.ProviderStatusFromRawExtension(machine.Status.ProviderStatus)
+		if err != nil {
+			return fmt.Errorf("%s/%s: failed to decode provider status: %w", machine.Namespace, machine.Name, err)
+		}
+		if !operationInFlight(status) {
+			continue
+		}
+		version, err := operationStateVersion(machine)
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", machine.Namespace, machine.Name, err)
+		}
+		if version < minOperationStateVersion || version > OperationStateVersion {
+			return fmt.Errorf("%s/%s: in-flight operation state version %d is not supported, this provider supports versions %d to %d",
+				machine.Namespace, machine.Name, version, minOperationStateVersion, OperationStateVersion)
+		}
+	}
+	return nil
+}
+
+// OperationGate tracks the machine operations in flight, so that a leader handing off
+// reconciliation stops starting new operations and waits for the running ones to complete.
+type OperationGate struct {
+	lock     sync.Mutex
+	paused   bool
+	inFlight int
+	idle     chan struct{}
+}
+
+// NewOperationGate returns an open operation gate.
+func NewOperationGate() *OperationGate {
+	return &OperationGate{}
+}
+
+// enter returns false if the gate is paused, else the operation is tracked until leave is called.
+func (g *OperationGate) enter() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.paused {
+		return false
+	}
+	g.inFlight++
+	return true
+}
+
+func (g *OperationGate) leave() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.inFlight--
+	if g.inFlight == 0 && g.idle != nil {
+		close(g.idle)
+		g.idle = nil
+	}
+}
+
+// Pause stops new operations from starting.
+func (g *OperationGate) Pause() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.paused = true
+}
+
+// Resume lets operations start again.
+func (g *OperationGate) Resume() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.paused = false
+}
+
+// Drain pauses the gate and waits for the operations in flight to complete, or for the
+// context to be done.
+func (g *OperationGate) Drain(ctx context.Context) error {
+	g.lock.Lock()
+	g.paused = true
+	if g.inFlight == 0 {
+		g.lock.Unlock()
+		return nil
+	}
+	if g.idle == nil {
+		g.idle = make(chan struct{})
+	}
+	idle := g.idle
+	g.lock.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("operations still in flight: %w", ctx.Err())
+	}
+}
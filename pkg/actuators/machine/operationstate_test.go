@@ -0,0 +1,102 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestCheckOperationStateCompatibility(t *testing.T) {
+	inFlight, err := kubevirtproviderv1.RawExtensionFromProviderStatus(&kubevirtproviderv1.KubevirtMachineProviderStatus{
+		ColdMigration: &kubevirtproviderv1.ColdMigrationStatus{Phase: kubevirtproviderv1.ColdMigrationCloning},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		testcase    string
+		annotations map[string]string
+		status      *runtime.RawExtension
+		expectError bool
+	}{
+		{
+			testcase:    "in-flight operation of the current version",
+			annotations: map[string]string{operationStateVersionAnnotation: "1"},
+			status:      inFlight,
+		},
+		{
+			testcase: "in-flight operation without version",
+			status:   inFlight,
+		},
+		{
+			testcase:    "in-flight operation of a newer version",
+			annotations: map[string]string{operationStateVersionAnnotation: "2"},
+			status:      inFlight,
+			expectError: true,
+		},
+		{
+			testcase:    "newer version without in-flight operation",
+			annotations: map[string]string{operationStateVersionAnnotation: "2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := machinev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "worker-abcde",
+					Namespace:   "default",
+					Annotations: tc.annotations,
+				},
+				Status: machinev1.MachineStatus{ProviderStatus: tc.status},
+			}
+			client := fake.NewFakeClientWithScheme(scheme, machine)
+
+			err := CheckOperationStateCompatibility(context.Background(), client, "default")
+			if tc.expectError && err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestOperationGate(t *testing.T) {
+	gate := NewOperationGate()
+	if !gate.enter() {
+		t.Fatalf("Expected an open gate to let operations in")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := gate.Drain(ctx); err == nil {
+		t.Errorf("Expected drain to time out with an operation in flight")
+	}
+	if gate.enter() {
+		t.Errorf("Expected a paused gate to refuse operations")
+	}
+
+	gate.leave()
+	if err := gate.Drain(context.Background()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	gate.Resume()
+	if !gate.enter() {
+		t.Errorf("Expected a resumed gate to let operations in")
+	}
+}
@@ -0,0 +1,210 @@
+package machine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// OvercommitProfile sets how the resources seen by the guest of a VM relate to the resources
+// it requests on the infra cluster.
+type OvercommitProfile struct {
+	// CPURatio is the number of vCPUs per requested CPU, the VM requests its cores divided by
+	// it. Zero leaves the CPU request to KubeVirt.
+	CPURatio float64
+	// MemoryRatio is the guest memory per requested memory, the VM requests its memory divided
+	// by it while the guest sees all of it. KubeVirt attaches a memory balloon to every VMI,
+	// which reports the free guest memory for the infra node to reclaim. Zero or one does not
+	// overcommit memory.
+	MemoryRatio float64
+	// Guaranteed sets the limits of the VM to its requests, for the Guaranteed QoS class.
+	Guaranteed bool
+	// GuestOverhead counts the memory overhead of the virt-launcher pod within the requested
+	// memory rather than on top of it.
+	GuestOverhead bool
+}
+
+// OvercommitProfiles are the overcommit profiles provider specs refer to, keyed by name.
+type OvercommitProfiles map[string]*OvercommitProfile
+
+// ParseOvercommitProfiles parses overcommit profiles given as name=key:value,...
+// with the keys cpuRatio, memoryRatio, guaranteed and guestOverhead.
+func ParseOvercommitProfiles(specs []string) (OvercommitProfiles, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	profiles := OvercommitProfiles{}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid overcommit profile %q, must be name=key:value,...", spec)
+		}
+		name := parts[0]
+		if _, ok := profiles[name]; ok {
+			return nil, fmt.Errorf("duplicate overcommit profile %q", name)
+		}
+		profile, err := parseOvercommitProfile(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid overcommit profile %q: %w", name, err)
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// parseOvercommitProfile parses the settings of an overcommit profile.
+func parseOvercommitProfile(settings string) (*OvercommitProfile, error) {
+	profile := &OvercommitProfile{}
+	for _, setting := range strings.Split(settings, ",") {
+		parts := strings.SplitN(setting, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid setting %q, must be key:value", setting)
+		}
+		key, value := parts[0], parts[1]
+		var err error
+		switch key {
+		case "cpuRatio":
+			profile.CPURatio, err = parseRatio(value)
+		case "memoryRatio":
+			profile.MemoryRatio, err = parseRatio(value)
+		case "guaranteed":
+			profile.Guaranteed, err = strconv.ParseBool(value)
+		case "guestOverhead":
+			profile.GuestOverhead, err = strconv.ParseBool(value)
+		default:
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", key, value, err)
+		}
+	}
+	if profile.Guaranteed && profile.MemoryRatio > 1 {
+		return nil, fmt.Errorf("guaranteed cannot be combined with a memoryRatio above 1, the memory limit would be below the guest memory")
+	}
+	return profile, nil
+}
+
+// parseRatio parses an allocation ratio, which cannot be below 1.
+func parseRatio(value string) (float64, error) {
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if ratio < 1 {
+		return 0, fmt.Errorf("must be at least 1")
+	}
+	return ratio, nil
+}
+
+// validateOvercommitProfile returns an error if the profile conflicts with the provider spec.
+func validateOvercommitProfile(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, profile *OvercommitProfile) error {
+	if providerSpec.DedicatedCPUPlacement && profile.CPURatio > 1 {
+		return fmt.Errorf("overcommit profile %q overcommits CPUs, which cannot be combined with dedicatedCpuPlacement", providerSpec.OvercommitProfile)
+	}
+	if providerSpec.Hugepages != nil && profile.MemoryRatio > 1 {
+		return fmt.Errorf("overcommit profile %q overcommits memory, which cannot be combined with hugepages", providerSpec.OvercommitProfile)
+	}
+	if providerSpec.LauncherResources != nil && profile.Guaranteed {
+		return fmt.Errorf("overcommit profile %q sets the limits, which cannot be combined with launcherResources", providerSpec.OvercommitProfile)
+	}
+	if providerSpec.GuestMemory != "" && (profile.MemoryRatio > 1 || profile.Guaranteed) {
+		return fmt.Errorf("overcommit profile %q sets the memory of the VM, which cannot be combined with guestMemory", providerSpec.OvercommitProfile)
+	}
+	return nil
+}
+
+// validateGuestMemory returns an error if the guest memory cannot be parsed, is below the
+// requested memory or is combined with hugepages.
+func validateGuestMemory(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	guest, err := resource.ParseQuantity(providerSpec.GuestMemory)
+	if err != nil {
+		return fmt.Errorf("invalid guestMemory %q: %v", providerSpec.GuestMemory, err)
+	}
+	requestedMemory := providerSpec.RequestedMemory
+	if requestedMemory == "" {
+		requestedMemory = defaultRequestedMemory
+	}
+	requested, err := resource.ParseQuantity(requestedMemory)
+	if err != nil {
+		return fmt.Errorf("invalid requestedMemory %q: %v", requestedMemory, err)
+	}
+	if guest.Cmp(requested) < 0 {
+		return fmt.Errorf("guestMemory %s must not be below requestedMemory %s", providerSpec.GuestMemory, requestedMemory)
+	}
+	if providerSpec.Hugepages != nil {
+		return fmt.Errorf("guestMemory cannot be combined with hugepages, which back all the guest memory")
+	}
+	return nil
+}
+
+// applyGuestMemory sets the memory seen by the guest of the VMI template, above the memory
+// it requests.
+func applyGuestMemory(spec *kubevirtapis.VirtualMachineInstanceSpec, guestMemory string) error {
+	guest, err := resource.ParseQuantity(guestMemory)
+	if err != nil {
+		return fmt.Errorf("invalid guestMemory %q: %v", guestMemory, err)
+	}
+	if spec.Domain.Memory == nil {
+		spec.Domain.Memory = &kubevirtapis.Memory{}
+	}
+	spec.Domain.Memory.Guest = &guest
+	return nil
+}
+
+// applyOvercommitProfile sets the requests and limits of the VMI template out of the resources
+// seen by the guest.
+func applyOvercommitProfile(spec *kubevirtapis.VirtualMachineInstanceSpec, profile *OvercommitProfile) {
+	resources := &spec.Domain.Resources
+	if resources.Requests == nil {
+		resources.Requests = corev1.ResourceList{}
+	}
+
+	if memory, ok := resources.Requests[corev1.ResourceMemory]; ok && profile.MemoryRatio > 1 {
+		if spec.Domain.Memory == nil {
+			spec.Domain.Memory = &kubevirtapis.Memory{}
+		}
+		guest := memory.DeepCopy()
+		spec.Domain.Memory.Guest = &guest
+		resources.Requests[corev1.ResourceMemory] = *resource.NewQuantity(int64(float64(memory.Value())/profile.MemoryRatio), resource.BinarySI)
+	}
+
+	if spec.Domain.CPU != nil && profile.CPURatio > 0 {
+		millis := int64(float64(spec.Domain.CPU.Cores) * 1000 / profile.CPURatio)
+		resources.Requests[corev1.ResourceCPU] = *resource.NewMilliQuantity(millis, resource.DecimalSI)
+	}
+
+	if profile.Guaranteed {
+		resources.Limits = corev1.ResourceList{}
+		for name, request := range resources.Requests {
+			resources.Limits[name] = request.DeepCopy()
+		}
+	}
+
+	if profile.GuestOverhead {
+		resources.OvercommitGuestOverhead = true
+	}
+}
+
+// overcommitProfile returns the overcommit profile the provider spec refers to, nil if it
+// refers to none.
+func (s *machineScope) overcommitProfile() (*OvercommitProfile, error) {
+	name := s.providerSpec.OvercommitProfile
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := s.overcommitProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown overcommit profile %q", name)
+	}
+	if err := validateOvercommitProfile(s.providerSpec, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
@@ -0,0 +1,202 @@
+package machine
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestParseOvercommitProfiles(t *testing.T) {
+	profiles, err := ParseOvercommitProfiles([]string{
+		"dense=cpuRatio:4,memoryRatio:1.5,guestOverhead:true",
+		"guaranteed=guaranteed:true",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	dense := profiles["dense"]
+	if dense == nil || dense.CPURatio != 4 || dense.MemoryRatio != 1.5 || !dense.GuestOverhead || dense.Guaranteed {
+		t.Errorf("Expected the dense profile to be parsed, got %+v", dense)
+	}
+	if guaranteed := profiles["guaranteed"]; guaranteed == nil || !guaranteed.Guaranteed {
+		t.Errorf("Expected the guaranteed profile to be parsed, got %+v", guaranteed)
+	}
+
+	for _, spec := range []string{
+		"dense",
+		"=cpuRatio:4",
+		"dense=cpuRatio",
+		"dense=cpuRatio:0.5",
+		"dense=memoryRatio:x",
+		"dense=balloon:true",
+		"dense=guaranteed:true,memoryRatio:2",
+	} {
+		if _, err := ParseOvercommitProfiles([]string{spec}); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+	if _, err := ParseOvercommitProfiles([]string{"dense=cpuRatio:2", "dense=cpuRatio:4"}); err == nil {
+		t.Error("Expected an error for a duplicate profile")
+	}
+}
+
+func TestApplyOvercommitProfile(t *testing.T) {
+	testCases := []struct {
+		testcase       string
+		profile        *OvercommitProfile
+		expectRequests corev1.ResourceList
+		expectLimits   corev1.ResourceList
+		expectGuest    string
+	}{
+		{
+			testcase: "dense",
+			profile:  &OvercommitProfile{CPURatio: 4, MemoryRatio: 2, GuestOverhead: true},
+			expectRequests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			expectGuest: "4Gi",
+		},
+		{
+			testcase: "guaranteed",
+			profile:  &OvercommitProfile{CPURatio: 1, Guaranteed: true},
+			expectRequests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			expectLimits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			spec := &kubevirtapis.VirtualMachineInstanceSpec{
+				Domain: kubevirtapis.DomainSpec{
+					CPU: &kubevirtapis.CPU{Cores: 2},
+					Resources: kubevirtapis.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+					},
+				},
+			}
+			applyOvercommitProfile(spec, tc.profile)
+
+			resources := spec.Domain.Resources
+			if !equalResources(resources.Requests, tc.expectRequests) {
+				t.Errorf("Expected requests %v, got %v", tc.expectRequests, resources.Requests)
+			}
+			if !equalResources(resources.Limits, tc.expectLimits) {
+				t.Errorf("Expected limits %v, got %v", tc.expectLimits, resources.Limits)
+			}
+			if resources.OvercommitGuestOverhead != tc.profile.GuestOverhead {
+				t.Errorf("Expected overcommitGuestOverhead %v, got %v", tc.profile.GuestOverhead, resources.OvercommitGuestOverhead)
+			}
+			guest := ""
+			if spec.Domain.Memory != nil && spec.Domain.Memory.Guest != nil {
+				guest = spec.Domain.Memory.Guest.String()
+			}
+			if guest != tc.expectGuest {
+				t.Errorf("Expected guest memory %q, got %q", tc.expectGuest, guest)
+			}
+		})
+	}
+}
+
+func TestValidateOvercommitProfile(t *testing.T) {
+	dense := &OvercommitProfile{CPURatio: 4, MemoryRatio: 2}
+	if err := validateOvercommitProfile(&kubevirtproviderv1.KubevirtMachineProviderSpec{OvercommitProfile: "dense"}, dense); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateOvercommitProfile(&kubevirtproviderv1.KubevirtMachineProviderSpec{OvercommitProfile: "dense", DedicatedCPUPlacement: true}, dense); err == nil {
+		t.Error("Expected an error for an overcommitted dedicated CPU placement")
+	}
+	guaranteed := &OvercommitProfile{Guaranteed: true}
+	if err := validateOvercommitProfile(&kubevirtproviderv1.KubevirtMachineProviderSpec{
+		OvercommitProfile: "guaranteed",
+		LauncherResources: &kubevirtproviderv1.LauncherResources{MemoryLimit: "8Gi"},
+	}, guaranteed); err == nil {
+		t.Error("Expected an error for a guaranteed profile with launcher resources")
+	}
+
+	if err := validateOvercommitProfile(&kubevirtproviderv1.KubevirtMachineProviderSpec{OvercommitProfile: "dense", GuestMemory: "4Gi"}, dense); err == nil {
+		t.Error("Expected an error for a memory overcommitting profile with guest memory")
+	}
+
+	scope := &machineScope{
+		providerSpec:       &kubevirtproviderv1.KubevirtMachineProviderSpec{OvercommitProfile: "sparse"},
+		overcommitProfiles: OvercommitProfiles{"dense": dense},
+	}
+	if _, err := scope.overcommitProfile(); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+}
+
+func TestGuestMemory(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		expectError  bool
+	}{
+		{
+			testcase:     "above the requested memory",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{RequestedMemory: "2Gi", GuestMemory: "4Gi"},
+		},
+		{
+			testcase:     "above the default requested memory",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{GuestMemory: "4Gi"},
+		},
+		{
+			testcase:     "below the requested memory",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{RequestedMemory: "4Gi", GuestMemory: "2Gi"},
+			expectError:  true,
+		},
+		{
+			testcase:     "invalid",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{GuestMemory: "lots"},
+			expectError:  true,
+		},
+		{
+			testcase: "with hugepages",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				RequestedMemory: "2Gi",
+				GuestMemory:     "4Gi",
+				Hugepages:       &kubevirtproviderv1.Hugepages{PageSize: "2Mi"},
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			if err := validateGuestMemory(&tc.providerSpec); (err != nil) != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+
+	spec := &kubevirtapis.VirtualMachineInstanceSpec{}
+	if err := applyGuestMemory(spec, "4Gi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spec.Domain.Memory == nil || spec.Domain.Memory.Guest == nil || spec.Domain.Memory.Guest.String() != "4Gi" {
+		t.Errorf("Expected 4Gi of guest memory, got %v", spec.Domain.Memory)
+	}
+}
+
+func equalResources(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, quantity := range a {
+		other, ok := b[name]
+		if !ok || quantity.Cmp(other) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package machine
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// pausedAnnotation set to "true" on a machine, or on its MachineSet for all of its
+	// machines, pauses the reconciliation of their VMs, e.g. during maintenance or while
+	// debugging a VM by hand.
+	pausedAnnotation = "kubevirtproviderconfig.openshift.io/paused"
+
+	// pausedReason is the reason of the events of operations skipped on paused machines.
+	pausedReason = "Paused"
+
+	// pausedRequeue is the delay before a paused creation or deletion is retried.
+	pausedRequeue = time.Minute
+)
+
+// paused returns true if the machine or its MachineSet is paused. A MachineSet which cannot be
+// read does not pause its machines.
+func (a *Actuator) paused(ctx context.Context, machine *machinev1.Machine, logger logr.Logger) bool {
+	if machine.Annotations[pausedAnnotation] == "true" {
+		return true
+	}
+	key, ok := machineSetKey(machine)
+	if !ok || a.client == nil {
+		return false
+	}
+	machineSet := &machinev1.MachineSet{}
+	if err := a.client.Get(ctx, key, machineSet); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to get MachineSet to check whether it is paused", "machineset", key.Name)
+		}
+		return false
+	}
+	return machineSet.Annotations[pausedAnnotation] == "true"
+}
+
+// skipPaused returns true and emits a Paused event if the operation is skipped as the machine
+// is paused.
+func (a *Actuator) skipPaused(ctx context.Context, machine *machinev1.Machine, operation string, logger logr.Logger) bool {
+	if !a.paused(ctx, machine, logger) {
+		return false
+	}
+	logger.Info("machine paused, skipping")
+	a.eventRecorder.Eventf(machine, corev1.EventTypeNormal, pausedReason, "Machine is paused, skipped %s", operation)
+	return true
+}
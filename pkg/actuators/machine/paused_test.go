@@ -0,0 +1,79 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPausedMachines(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := machinev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pausedMachineSet := &machinev1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "workers",
+			Namespace:   "tenant-a",
+			Annotations: map[string]string{pausedAnnotation: "true"},
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	actuator := NewActuator(ActuatorParams{
+		Client:        fake.NewFakeClientWithScheme(scheme, pausedMachineSet),
+		EventRecorder: recorder,
+	})
+
+	paused := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "worker-abcde",
+			Namespace:   "tenant-a",
+			Annotations: map[string]string{pausedAnnotation: "true"},
+		},
+	}
+	err := actuator.Create(context.Background(), paused)
+	if requeue, ok := err.(*machinecontroller.RequeueAfterError); !ok || requeue.RequeueAfter != pausedRequeue {
+		t.Errorf("Expected a requeue after %v, got %v", pausedRequeue, err)
+	}
+	err = actuator.Delete(context.Background(), paused)
+	if _, ok := err.(*machinecontroller.RequeueAfterError); !ok {
+		t.Errorf("Expected a requeue, got %v", err)
+	}
+
+	inPausedMachineSet := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-fghij",
+			Namespace: "tenant-a",
+			Labels:    map[string]string{machineSetLabel: "workers"},
+		},
+	}
+	if err := actuator.Update(context.Background(), inPausedMachineSet); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Normal Paused Machine is paused, skipped Create",
+		"Normal Paused Machine is paused, skipped Delete",
+		"Normal Paused Machine is paused, skipped Update",
+	}
+	if events := drainEvents(recorder); !equalStrings(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+
+	other := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-klmno",
+			Namespace: "tenant-a",
+			Labels:    map[string]string{machineSetLabel: "masters"},
+		},
+	}
+	if actuator.paused(context.Background(), other, actuator.logger) {
+		t.Error("Expected the machine not to be paused")
+	}
+}
@@ -0,0 +1,164 @@
+package machine
+
+import (
+	"fmt"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// defaultPreemptiblePriorityClass is the priority class of the virt-launcher pods of
+	// preemptible machines which do not set one.
+	defaultPreemptiblePriorityClass = "kubevirt-machine-preemptible"
+	// preemptibleTaintKey taints the Nodes of preemptible machines, for workloads to opt in to
+	// running on them.
+	preemptibleTaintKey = "kubevirtproviderconfig.openshift.io/preemptible"
+	// preemptionCordonAnnotation marks a Node cordoned while the VM of its machine was evicted,
+	// for the Node to be uncordoned once the VM runs again.
+	preemptionCordonAnnotation = "kubevirtproviderconfig.openshift.io/preemption-cordoned"
+	// evictedPodReason is the reason of the pods evicted by the kubelet.
+	evictedPodReason = "Evicted"
+)
+
+// validatePreemptible returns an error if the machine cannot be preemptible.
+func validatePreemptible(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	if providerSpec.EvictionStrategy == kubevirtproviderv1.EvictionStrategyLiveMigrate {
+		return fmt.Errorf("preemptible machines are shut down on drains, they do not support evictionStrategy %q", kubevirtproviderv1.EvictionStrategyLiveMigrate)
+	}
+	return nil
+}
+
+// applyPreemptible sets the priority class of the virt-launcher pod of a preemptible VM.
+func applyPreemptible(template *kubevirtapis.VirtualMachineInstanceTemplateSpec, preemptible *kubevirtproviderv1.Preemptible) {
+	priorityClassName := preemptible.PriorityClassName
+	if priorityClassName == "" {
+		priorityClassName = defaultPreemptiblePriorityClass
+	}
+	template.Spec.PriorityClassName = priorityClassName
+}
+
+// setMachinePreemptible labels a preemptible machine interruptible and taints it, which the
+// node link controller propagates to its Node.
+func setMachinePreemptible(machine *machinev1.Machine, preemptible *kubevirtproviderv1.Preemptible) {
+	if preemptible == nil {
+		return
+	}
+	if machine.Spec.Labels == nil {
+		machine.Spec.Labels = map[string]string{}
+	}
+	machine.Spec.Labels[machinecontroller.MachineInterruptibleInstanceLabelName] = ""
+
+	for _, taint := range machine.Spec.Taints {
+		if taint.Key == preemptibleTaintKey {
+			return
+		}
+	}
+	machine.Spec.Taints = append(machine.Spec.Taints, corev1.Taint{
+		Key:    preemptibleTaintKey,
+		Value:  "true",
+		Effect: corev1.TaintEffectPreferNoSchedule,
+	})
+}
+
+// launcherEvicted returns true if a virt-launcher pod of the VMI is being deleted, e.g.
+// preempted by the infra scheduler or evicted from a drained infra node, or was evicted by
+// the kubelet.
+func (r *Reconciler) launcherEvicted(vmi *kubevirtapis.VirtualMachineInstance) (bool, error) {
+	if vmi.DeletionTimestamp != nil {
+		return true, nil
+	}
+	pods, err := r.kubevirtClient.ListPods(r.Context, vmi.Namespace, &metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", launcherCreatedByLabel, vmi.UID),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list virt-launcher pods of VirtualMachineInstance: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Reason == evictedPodReason {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// preemptedCondition reports whether the VM of a preemptible machine is being evicted.
+func preemptedCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.Preempted,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reconcilePreemption cordons the tenant Node of a preemptible machine as soon as its VM is
+// being evicted, as a termination notice for no more pods to be scheduled on it, and
+// uncordons it once the VM runs again. Nodes cordoned by others are left cordoned.
+func (r *Reconciler) reconcilePreemption(vmi *kubevirtapis.VirtualMachineInstance) error {
+	if vmi == nil {
+		// Stopped or being restarted, the Node stays as it is until the VM runs
+		return nil
+	}
+	evicted, err := r.launcherEvicted(vmi)
+	if err != nil {
+		return err
+	}
+	if !evicted {
+		condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.Preempted)
+		if condition == nil || condition.Status != corev1.ConditionTrue || vmi.Status.Phase != kubevirtapis.Running {
+			return nil
+		}
+	}
+
+	nodeName := nodeNameForMachine(r.machine.Name, r.machine.Status.NodeRef)
+	node := &corev1.Node{}
+	if err := r.client.Get(r.Context, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	_, cordoned := node.Annotations[preemptionCordonAnnotation]
+	if evicted {
+		r.machineScope.setProviderStatus(preemptedCondition(corev1.ConditionTrue, kubevirtproviderv1.LauncherEvicted,
+			fmt.Sprintf("VirtualMachineInstance is being evicted from infra node %s, node %s is cordoned", vmi.Status.NodeName, nodeName)))
+		if cordoned || node.Spec.Unschedulable {
+			return nil
+		}
+		klog.Infof("%s: VirtualMachineInstance is being evicted, cordoning node %s", r.machine.Name, nodeName)
+		patch := runtimeclient.MergeFrom(node.DeepCopy())
+		node.Spec.Unschedulable = true
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[preemptionCordonAnnotation] = ""
+		if err := r.client.Patch(r.Context, node, patch); err != nil {
+			return fmt.Errorf("failed to cordon node %s: %w", nodeName, err)
+		}
+		return nil
+	}
+
+	if cordoned {
+		klog.Infof("%s: VirtualMachineInstance runs again, uncordoning node %s", r.machine.Name, nodeName)
+		patch := runtimeclient.MergeFrom(node.DeepCopy())
+		node.Spec.Unschedulable = false
+		delete(node.Annotations, preemptionCordonAnnotation)
+		if err := r.client.Patch(r.Context, node, patch); err != nil {
+			return fmt.Errorf("failed to uncordon node %s: %w", nodeName, err)
+		}
+	}
+	r.machineScope.setProviderStatus(preemptedCondition(corev1.ConditionFalse, kubevirtproviderv1.LauncherRunning,
+		fmt.Sprintf("VirtualMachineInstance runs on infra node %s after its eviction", vmi.Status.NodeName)))
+	return nil
+}
@@ -0,0 +1,186 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestValidatePreemptible(t *testing.T) {
+	for _, evictionStrategy := range []kubevirtproviderv1.EvictionStrategy{"", kubevirtproviderv1.EvictionStrategyNone} {
+		if err := validatePreemptible(&kubevirtproviderv1.KubevirtMachineProviderSpec{EvictionStrategy: evictionStrategy}); err != nil {
+			t.Errorf("Unexpected error with eviction strategy %q: %v", evictionStrategy, err)
+		}
+	}
+	if err := validatePreemptible(&kubevirtproviderv1.KubevirtMachineProviderSpec{EvictionStrategy: kubevirtproviderv1.EvictionStrategyLiveMigrate}); err == nil {
+		t.Error("Expected an error with the LiveMigrate eviction strategy")
+	}
+}
+
+func TestApplyPreemptible(t *testing.T) {
+	template := &kubevirtapis.VirtualMachineInstanceTemplateSpec{}
+	applyPreemptible(template, &kubevirtproviderv1.Preemptible{})
+	if template.Spec.PriorityClassName != defaultPreemptiblePriorityClass {
+		t.Errorf("Expected the default priority class, got %q", template.Spec.PriorityClassName)
+	}
+	applyPreemptible(template, &kubevirtproviderv1.Preemptible{PriorityClassName: "spot"})
+	if template.Spec.PriorityClassName != "spot" {
+		t.Errorf("Expected priority class spot, got %q", template.Spec.PriorityClassName)
+	}
+}
+
+func TestSetMachinePreemptible(t *testing.T) {
+	machine := &machinev1.Machine{}
+	setMachinePreemptible(machine, nil)
+	if len(machine.Spec.Labels) != 0 || len(machine.Spec.Taints) != 0 {
+		t.Fatalf("Expected a machine which is not preemptible to be left alone, got %v", machine.Spec)
+	}
+
+	for i := 0; i < 2; i++ {
+		setMachinePreemptible(machine, &kubevirtproviderv1.Preemptible{})
+	}
+	if _, ok := machine.Spec.Labels[machinecontroller.MachineInterruptibleInstanceLabelName]; !ok {
+		t.Errorf("Expected the interruptible instance label, got %v", machine.Spec.Labels)
+	}
+	if len(machine.Spec.Taints) != 1 || machine.Spec.Taints[0].Key != preemptibleTaintKey || machine.Spec.Taints[0].Effect != corev1.TaintEffectPreferNoSchedule {
+		t.Errorf("Expected a single preemptible taint, got %v", machine.Spec.Taints)
+	}
+}
+
+func TestReconcilePreemption(t *testing.T) {
+	now := metav1.Now()
+	runningLauncher := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "virt-launcher-worker-abcde"}}
+	terminatingLauncher := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "virt-launcher-worker-abcde", DeletionTimestamp: &now}}
+	evictedLauncher := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "virt-launcher-worker-abcde"},
+		Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: evictedPodReason},
+	}
+
+	testCases := []struct {
+		testcase           string
+		launcher           corev1.Pod
+		preempted          bool
+		cordonedByOthers   bool
+		cordonedByProvider bool
+		expectCordoned     bool
+		expectAnnotation   bool
+		expectCondition    corev1.ConditionStatus
+	}{
+		{
+			testcase: "running",
+			launcher: runningLauncher,
+		},
+		{
+			testcase:         "launcher preempted",
+			launcher:         terminatingLauncher,
+			expectCordoned:   true,
+			expectAnnotation: true,
+			expectCondition:  corev1.ConditionTrue,
+		},
+		{
+			testcase:         "launcher evicted by the kubelet",
+			launcher:         evictedLauncher,
+			expectCordoned:   true,
+			expectAnnotation: true,
+			expectCondition:  corev1.ConditionTrue,
+		},
+		{
+			testcase:         "node cordoned by others",
+			launcher:         terminatingLauncher,
+			cordonedByOthers: true,
+			expectCordoned:   true,
+			expectCondition:  corev1.ConditionTrue,
+		},
+		{
+			testcase:           "running again",
+			launcher:           runningLauncher,
+			preempted:          true,
+			cordonedByProvider: true,
+			expectCondition:    corev1.ConditionFalse,
+		},
+		{
+			testcase:         "running again on a node cordoned by others",
+			launcher:         runningLauncher,
+			preempted:        true,
+			cordonedByOthers: true,
+			expectCordoned:   true,
+			expectCondition:  corev1.ConditionFalse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"},
+				Spec:       corev1.NodeSpec{Unschedulable: tc.cordonedByOthers || tc.cordonedByProvider},
+			}
+			if tc.cordonedByProvider {
+				node.Annotations = map[string]string{preemptionCordonAnnotation: ""}
+			}
+			client := fake.NewFakeClientWithScheme(scheme, node)
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			kubevirtClient := mockkubevirt.NewMockClient(mockCtrl)
+			kubevirtClient.EXPECT().ListPods(gomock.Any(), "tenant-a", gomock.Any()).Return(&corev1.PodList{Items: []corev1.Pod{tc.launcher}}, nil)
+
+			providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{}
+			if tc.preempted {
+				providerStatus.Conditions = []kubevirtproviderv1.KubevirtMachineProviderCondition{preemptedCondition(corev1.ConditionTrue, kubevirtproviderv1.LauncherEvicted, "")}
+			}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				client:         client,
+				kubevirtClient: kubevirtClient,
+				machine: &machinev1.Machine{
+					ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api"},
+					Status:     machinev1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "worker-abcde"}},
+				},
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{Preemptible: &kubevirtproviderv1.Preemptible{}},
+				providerStatus: providerStatus,
+			})
+			vmi := &kubevirtapis.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", UID: "vmi-uid"},
+				Status:     kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running, NodeName: "infra-1"},
+			}
+
+			if err := r.reconcilePreemption(vmi); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			updated := &corev1.Node{}
+			if err := client.Get(context.Background(), types.NamespacedName{Name: "worker-abcde"}, updated); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if updated.Spec.Unschedulable != tc.expectCordoned {
+				t.Errorf("Expected cordoned %v, got %v", tc.expectCordoned, updated.Spec.Unschedulable)
+			}
+			if _, ok := updated.Annotations[preemptionCordonAnnotation]; ok != tc.expectAnnotation {
+				t.Errorf("Expected the preemption cordon annotation %v, got %v", tc.expectAnnotation, updated.Annotations)
+			}
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.Preempted)
+			switch {
+			case tc.expectCondition == "" && condition != nil:
+				t.Errorf("Expected no Preempted condition, got %v", condition)
+			case tc.expectCondition != "" && (condition == nil || condition.Status != tc.expectCondition):
+				t.Errorf("Expected Preempted %v, got condition %v", tc.expectCondition, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package machine
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// providerIDPrefix is the scheme of the provider IDs of KubeVirt VMs.
+const providerIDPrefix = "kubevirt://"
+
+// providerIDForVM returns the stable provider ID of the VM, kubevirt://<namespace>/<name>.
+func providerIDForVM(vm *kubevirtapis.VirtualMachine) string {
+	return fmt.Sprintf("%s%s/%s", providerIDPrefix, vm.Namespace, vm.Name)
+}
+
+// setProviderID sets the provider ID of the VM on the machine.
+func (s *machineScope) setProviderID(vm *kubevirtapis.VirtualMachine) {
+	providerID := providerIDForVM(vm)
+	if s.machine.Spec.ProviderID != nil && *s.machine.Spec.ProviderID == providerID {
+		return
+	}
+	s.machine.Spec.ProviderID = &providerID
+}
+
+// nodeNameForMachine returns the name of the node of the machine, the node the machine is
+// linked to or else the node named after the guest hostname.
+func nodeNameForMachine(machineName string, nodeRef *corev1.ObjectReference) string {
+	if nodeRef != nil {
+		return nodeRef.Name
+	}
+	return guestHostname(machineName)
+}
+
+// reconcileNodeProviderID sets the provider ID of the VM on the node of the machine. Without
+// a cloud provider the kubelet registers the node without provider ID, which the machine-api
+// node link controller and the cluster autoscaler need to match the node with the machine.
+func (r *Reconciler) reconcileNodeProviderID(vm *kubevirtapis.VirtualMachine) error {
+	nodeName := nodeNameForMachine(r.machine.Name, r.machine.Status.NodeRef)
+	if nodeName == "" {
+		return nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.client.Get(r.Context, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+
+	providerID := providerIDForVM(vm)
+	switch node.Spec.ProviderID {
+	case providerID:
+		return nil
+	case "":
+		klog.Infof("%s: setting provider ID %s on node %s", r.machine.Name, providerID, nodeName)
+		patch := runtimeclient.MergeFrom(node.DeepCopy())
+		node.Spec.ProviderID = providerID
+		if err := r.client.Patch(r.Context, node, patch); err != nil {
+			return fmt.Errorf("failed to set provider ID of node %s: %w", nodeName, err)
+		}
+	default:
+		// The provider ID of a node is immutable once set
+		klog.Warningf("%s: node %s has provider ID %s instead of %s", r.machine.Name, nodeName, node.Spec.ProviderID, providerID)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileNodeProviderID(t *testing.T) {
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker-abcde",
+			Namespace: "tenant-a",
+		},
+	}
+	if providerID := providerIDForVM(vm); providerID != "kubevirt://tenant-a/worker-abcde" {
+		t.Fatalf("Unexpected provider ID %q", providerID)
+	}
+
+	testCases := []struct {
+		testcase           string
+		nodeProviderID     string
+		expectedProviderID string
+	}{
+		{
+			testcase:           "node without provider ID",
+			expectedProviderID: "kubevirt://tenant-a/worker-abcde",
+		},
+		{
+			testcase:           "node with another provider ID",
+			nodeProviderID:     "kubevirt://tenant-b/worker-abcde",
+			expectedProviderID: "kubevirt://tenant-b/worker-abcde",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"},
+				Spec:       corev1.NodeSpec{ProviderID: tc.nodeProviderID},
+			}
+			client := fake.NewFakeClientWithScheme(scheme, node)
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "worker-abcde",
+					Namespace: "openshift-machine-api",
+				},
+			}
+			r := newReconciler(&machineScope{
+				Context: context.Background(),
+				client:  client,
+				machine: machine,
+			})
+
+			if err := r.reconcileNodeProviderID(vm); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			r.machineScope.setProviderID(vm)
+
+			updated := &corev1.Node{}
+			if err := client.Get(context.Background(), types.NamespacedName{Name: "worker-abcde"}, updated); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if updated.Spec.ProviderID != tc.expectedProviderID {
+				t.Errorf("Expected node provider ID %q, got %q", tc.expectedProviderID, updated.Spec.ProviderID)
+			}
+			if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID != "kubevirt://tenant-a/worker-abcde" {
+				t.Errorf("Expected machine provider ID %q, got %v", "kubevirt://tenant-a/worker-abcde", machine.Spec.ProviderID)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package machine
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// minRateLimitedRequeue is the shortest delay before an operation refused by its rate limiter
+// is retried.
+const minRateLimitedRequeue = 5 * time.Second
+
+// RateLimit bounds the rate of an operation across all machines. A zero QPS leaves the
+// operation unlimited.
+type RateLimit struct {
+	QPS   float32
+	Burst int
+}
+
+// OperationRateLimits bound the rates of the machine operations, e.g. for a large scale-up to
+// create its VMs at a pace the infra cluster keeps up with.
+type OperationRateLimits struct {
+	Create RateLimit
+	Update RateLimit
+	Delete RateLimit
+}
+
+// operationRateLimiters holds the rate limiters of the rate limited operations.
+type operationRateLimiters map[string]flowcontrol.RateLimiter
+
+func newOperationRateLimiters(limits OperationRateLimits) operationRateLimiters {
+	limiters := operationRateLimiters{}
+	for operation, limit := range map[string]RateLimit{
+		"Create": limits.Create,
+		"Update": limits.Update,
+		"Delete": limits.Delete,
+	} {
+		if limit.QPS <= 0 {
+			continue
+		}
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		limiters[operation] = flowcontrol.NewTokenBucketRateLimiter(limit.QPS, burst)
+	}
+	return limiters
+}
+
+// rateLimited returns true and the delay to retry after if the operation exceeds its rate.
+func (l operationRateLimiters) rateLimited(operation string) (time.Duration, bool) {
+	limiter, ok := l[operation]
+	if !ok || limiter.TryAccept() {
+		return 0, false
+	}
+	delay := time.Duration(float64(time.Second) / float64(limiter.QPS()))
+	if delay < minRateLimitedRequeue {
+		delay = minRateLimitedRequeue
+	}
+	return delay, true
+}
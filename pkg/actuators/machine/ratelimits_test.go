@@ -0,0 +1,30 @@
+package machine
+
+import (
+	"testing"
+)
+
+func TestOperationRateLimiters(t *testing.T) {
+	limiters := newOperationRateLimiters(OperationRateLimits{
+		Create: RateLimit{QPS: 0.1, Burst: 2},
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, limited := limiters.rateLimited("Create"); limited {
+			t.Fatalf("Expected create %d within the burst to be accepted", i)
+		}
+	}
+	delay, limited := limiters.rateLimited("Create")
+	if !limited {
+		t.Fatal("Expected the create beyond the burst to be rate limited")
+	}
+	if delay < minRateLimitedRequeue {
+		t.Errorf("Expected a delay of at least %v, got %v", minRateLimitedRequeue, delay)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, limited := limiters.rateLimited("Delete"); limited {
+			t.Fatal("Expected deletions without rate limit to be accepted")
+		}
+	}
+}
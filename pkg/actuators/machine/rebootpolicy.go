@@ -0,0 +1,181 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	defaultRebootMaxUnavailable = 1
+
+	// rebootSettleTime is how long a rebooted machine counts against the reboot budget of its
+	// MachineSet, covering the time until its status reports the restarted VM.
+	rebootSettleTime = 10 * time.Minute
+)
+
+// validateRebootPolicy returns an error if the reboot policy is malformed.
+func validateRebootPolicy(policy *kubevirtproviderv1.RebootPolicy) error {
+	if policy.Interval.Duration <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if policy.MaxUnavailable < 0 {
+		return fmt.Errorf("maxUnavailable must not be negative")
+	}
+	if policy.MaintenanceWindow != nil {
+		if _, err := maintenanceWindowStart(policy.MaintenanceWindow); err != nil {
+			return err
+		}
+		if duration := policy.MaintenanceWindow.Duration.Duration; duration <= 0 || duration > 24*time.Hour {
+			return fmt.Errorf("maintenance window duration must be positive and at most 24h")
+		}
+	}
+	return nil
+}
+
+// maintenanceWindowStart returns the offset of the opening of the maintenance window from UTC midnight.
+func maintenanceWindowStart(window *kubevirtproviderv1.MaintenanceWindow) (time.Duration, error) {
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return 0, fmt.Errorf("maintenance window start %q is not in HH:MM format", window.Start)
+	}
+	return time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute, nil
+}
+
+// nextWindowOpening returns t if it is within the maintenance window, the next opening of the
+// window otherwise. Without window, reboots are allowed at any time.
+func nextWindowOpening(window *kubevirtproviderv1.MaintenanceWindow, t time.Time) time.Time {
+	if window == nil {
+		return t
+	}
+	start, err := maintenanceWindowStart(window)
+	if err != nil {
+		return t
+	}
+
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	// The window opened yesterday may still be open
+	for _, opening := range []time.Time{midnight.Add(start - 24*time.Hour), midnight.Add(start)} {
+		if !t.Before(opening) && t.Before(opening.Add(window.Duration.Duration)) {
+			return t
+		}
+	}
+	opening := midnight.Add(start)
+	if opening.Before(t) {
+		opening = opening.Add(24 * time.Hour)
+	}
+	return opening
+}
+
+// nextRebootTime returns the time the reboot following the one at last is due.
+func nextRebootTime(policy *kubevirtproviderv1.RebootPolicy, last time.Time) time.Time {
+	return nextWindowOpening(policy.MaintenanceWindow, last.Add(policy.Interval.Duration))
+}
+
+// machineUnavailable returns whether a machine counts against the reboot budget of its MachineSet.
+func machineUnavailable(machine *machinev1.Machine, now time.Time) (bool, error) {
+	status, err := kubevirtproviderv1.ProviderStatusFromRawExtension(machine.Status.ProviderStatus)
+	if err != nil {
+		return false, err
+	}
+	if status.VMState != kubevirtproviderv1.VMStateRunning {
+		return true, nil
+	}
+	return status.Reboot != nil && status.Reboot.LastRebootTime != nil && now.Sub(status.Reboot.LastRebootTime.Time) < rebootSettleTime, nil
+}
+
+// rebootBudgetAvailable returns whether fewer than maxUnavailable other machines of the MachineSet
+// of the machine are unavailable, so that the machine may be rebooted.
+func (r *Reconciler) rebootBudgetAvailable(policy *kubevirtproviderv1.RebootPolicy, now time.Time) (bool, error) {
+	machineSet, ok := machineSetKey(r.machine)
+	if !ok {
+		return true, nil
+	}
+	maxUnavailable := int(policy.MaxUnavailable)
+	if maxUnavailable == 0 {
+		maxUnavailable = defaultRebootMaxUnavailable
+	}
+
+	machines := &machinev1.MachineList{}
+	if err := r.client.List(r.Context, machines, runtimeclient.InNamespace(machineSet.Namespace), runtimeclient.MatchingLabels{machineSetLabel: machineSet.Name}); err != nil {
+		return false, fmt.Errorf("failed to list machines of MachineSet %s: %w", machineSet.Name, err)
+	}
+
+	unavailable := 0
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if machine.UID == r.machine.UID || machine.DeletionTimestamp != nil {
+			continue
+		}
+		isUnavailable, err := machineUnavailable(machine, now)
+		if err != nil {
+			return false, fmt.Errorf("failed to decode provider status of machine %s: %w", machine.Name, err)
+		}
+		if isUnavailable {
+			unavailable++
+		}
+	}
+	return unavailable < maxUnavailable, nil
+}
+
+// reconcileReboot reboots the guest once a reboot is due according to the reboot policy, within
+// the maintenance window and the reboot budget of the MachineSet of the machine.
+func (r *Reconciler) reconcileReboot(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) error {
+	policy := r.providerSpec.RebootPolicy
+	if policy == nil {
+		r.providerStatus.Reboot = nil
+		return nil
+	}
+
+	status := r.providerStatus.Reboot
+	if status == nil {
+		status = &kubevirtproviderv1.RebootStatus{}
+		r.providerStatus.Reboot = status
+	}
+	last := r.machine.CreationTimestamp.Time
+	if status.LastRebootTime != nil {
+		last = status.LastRebootTime.Time
+	}
+	now := time.Now()
+	next := nextRebootTime(policy, last)
+	status.NextRebootTime = &metav1.Time{Time: next}
+	status.Message = ""
+
+	if now.Before(next) {
+		return nil
+	}
+	if opening := nextWindowOpening(policy.MaintenanceWindow, now); opening.After(now) {
+		status.NextRebootTime = &metav1.Time{Time: opening}
+		status.Message = "Reboot is due, waiting for the maintenance window"
+		return nil
+	}
+	if vmi == nil || vmi.Status.Phase != kubevirtapis.Running {
+		status.Message = "Reboot is due, waiting for the VM to run"
+		return nil
+	}
+
+	available, err := r.rebootBudgetAvailable(policy, now)
+	if err != nil {
+		return err
+	}
+	if !available {
+		status.Message = "Reboot is due, waiting for other machines of the MachineSet to become available"
+		return nil
+	}
+
+	klog.Infof("%s: rebooting VirtualMachine as scheduled by the reboot policy", r.machine.Name)
+	if err := r.kubevirtClient.RestartVirtualMachine(r.Context, vm.Namespace, vm.Name); err != nil {
+		return fmt.Errorf("failed to restart VirtualMachine: %w", err)
+	}
+	status.LastRebootTime = &metav1.Time{Time: now}
+	status.NextRebootTime = &metav1.Time{Time: nextRebootTime(policy, now)}
+	return nil
+}
@@ -0,0 +1,171 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestNextRebootTime(t *testing.T) {
+	window := &kubevirtproviderv1.MaintenanceWindow{Start: "22:00", Duration: metav1.Duration{Duration: 4 * time.Hour}}
+	day := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testcase     string
+		window       *kubevirtproviderv1.MaintenanceWindow
+		last         time.Time
+		expectedNext time.Time
+	}{
+		{
+			testcase:     "without window",
+			last:         day.Add(12 * time.Hour),
+			expectedNext: day.Add(36 * time.Hour),
+		},
+		{
+			testcase:     "due before the window",
+			window:       window,
+			last:         day.Add(12 * time.Hour),
+			expectedNext: day.Add(46 * time.Hour),
+		},
+		{
+			testcase:     "due within the window",
+			window:       window,
+			last:         day.Add(23 * time.Hour),
+			expectedNext: day.Add(47 * time.Hour),
+		},
+		{
+			testcase:     "due within the window opened the day before",
+			window:       window,
+			last:         day.Add(25 * time.Hour),
+			expectedNext: day.Add(49 * time.Hour),
+		},
+		{
+			testcase:     "due after the window closed",
+			window:       window,
+			last:         day.Add(27 * time.Hour),
+			expectedNext: day.Add(70 * time.Hour),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			policy := &kubevirtproviderv1.RebootPolicy{Interval: metav1.Duration{Duration: 24 * time.Hour}, MaintenanceWindow: tc.window}
+			if next := nextRebootTime(policy, tc.last); !next.Equal(tc.expectedNext) {
+				t.Errorf("Expected next reboot at %v, got %v", tc.expectedNext, next)
+			}
+		})
+	}
+
+	for _, window := range []*kubevirtproviderv1.MaintenanceWindow{
+		{Start: "25:00", Duration: metav1.Duration{Duration: time.Hour}},
+		{Start: "02:00", Duration: metav1.Duration{Duration: 25 * time.Hour}},
+	} {
+		policy := &kubevirtproviderv1.RebootPolicy{Interval: metav1.Duration{Duration: 24 * time.Hour}, MaintenanceWindow: window}
+		if err := validateRebootPolicy(policy); err == nil {
+			t.Errorf("Expected error for maintenance window %v, got nil", window)
+		}
+	}
+}
+
+func TestReconcileReboot(t *testing.T) {
+	policy := &kubevirtproviderv1.RebootPolicy{Interval: metav1.Duration{Duration: 24 * time.Hour}}
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+	}
+	vmi := &kubevirtapis.VirtualMachineInstance{
+		Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running},
+	}
+
+	newMachine := func(name string, created time.Time, state kubevirtproviderv1.VMState) *machinev1.Machine {
+		status, err := kubevirtproviderv1.RawExtensionFromProviderStatus(&kubevirtproviderv1.KubevirtMachineProviderStatus{VMState: state})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return &machinev1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				UID:               types.UID(name),
+				Labels:            map[string]string{machineSetLabel: "worker"},
+				CreationTimestamp: metav1.Time{Time: created},
+			},
+			Status: machinev1.MachineStatus{ProviderStatus: status},
+		}
+	}
+
+	testCases := []struct {
+		testcase       string
+		created        time.Time
+		otherState     kubevirtproviderv1.VMState
+		expectedReboot bool
+	}{
+		{
+			testcase:   "not due",
+			created:    time.Now().Add(-time.Hour),
+			otherState: kubevirtproviderv1.VMStateRunning,
+		},
+		{
+			testcase:       "due",
+			created:        time.Now().Add(-25 * time.Hour),
+			otherState:     kubevirtproviderv1.VMStateRunning,
+			expectedReboot: true,
+		},
+		{
+			testcase:   "due while another machine is unavailable",
+			created:    time.Now().Add(-25 * time.Hour),
+			otherState: kubevirtproviderv1.VMStateStarting,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := machinev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			machine := newMachine("worker-abcde", tc.created, kubevirtproviderv1.VMStateRunning)
+			other := newMachine("worker-fghij", tc.created, tc.otherState)
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.expectedReboot {
+				client.EXPECT().RestartVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde").Return(nil)
+			}
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				client:         fake.NewFakeClientWithScheme(scheme, machine, other),
+				kubevirtClient: client,
+				machine:        machine,
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{RebootPolicy: policy},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			})
+			if err := r.reconcileReboot(vm, vmi); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			status := r.providerStatus.Reboot
+			if status == nil || status.NextRebootTime == nil {
+				t.Fatalf("Expected the next reboot to be tracked, got %v", status)
+			}
+			if rebooted := status.LastRebootTime != nil; rebooted != tc.expectedReboot {
+				t.Errorf("Expected reboot %v, got %v", tc.expectedReboot, rebooted)
+			}
+			if !tc.expectedReboot && status.NextRebootTime.Before(&metav1.Time{Time: time.Now()}) && status.Message == "" {
+				t.Errorf("Expected a message on the postponed reboot")
+			}
+		})
+	}
+}
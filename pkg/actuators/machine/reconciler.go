@@ -4,15 +4,16 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/ec2"
 	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	errorutil "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/klog"
-	kubevirtapis "kubevirt.io/kubevirt/pkg/api/v1"
-	awsproviderv1 "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
 )
 
 const (
@@ -34,169 +35,408 @@ func newReconciler(scope *machineScope) *Reconciler {
 
 // create creates machine if it does not exists.
 func (r *Reconciler) create() error {
-	klog.Infof("%s: creating machine", r.machine.Name)
+	r.log().Info("creating machine")
 
-	if err := validateMachine(*r.machine); err != nil {
-		return fmt.Errorf("%v: failed validating machine provider spec: %w", r.machine.GetName(), err)
+	if err := r.validateCreate(); err != nil {
+		return err
+	}
+	r.pruneExpiredSnapshots(time.Now())
+
+	if err := r.allocateAddresses(); err != nil {
+		return err
+	}
+	applyAllocatedAddresses(r.providerSpec, r.providerStatus.AllocatedAddresses)
+
+	if r.failureBudget != nil {
+		if err := r.checkFailureBudget(); err != nil {
+			return err
+		}
+	}
+
+	if r.providerSpec.NUMAResourceCheck {
+		if err := r.checkNUMAResources(); err != nil {
+			return err
+		}
+	}
+
+	overcommitProfile, err := r.overcommitProfile()
+	if err != nil {
+		return machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
 	}
 
 	userData, err := r.machineScope.getUserData()
+	if apierrors.IsNotFound(err) {
+		return r.waitForBootstrapData()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get user data: %w", err)
 	}
+	r.bootstrapDataFound()
+
+	if userData, err = r.renderUserData(userData); err != nil {
+		return err
+	}
+
+	// Ephemeral root disks are not cloned
+	if r.cloneCoordinator != nil && r.providerSpec.RootVolumeMode != kubevirtproviderv1.RootVolumeModeEphemeral {
+		if err := r.acquireCloneSlot(); err != nil {
+			return err
+		}
+	}
+
+	if err := r.ensureInfraNamespace(); err != nil {
+		return err
+	}
 
-	//instance, err := launchInstance(r.machine, r.providerSpec, userData, r.awsClient)
-	vm, err := createVm(r.machine, r.providerSpec, userData, r.kubevirtClient)
+	vm, err := createVM(r.Context, r.machine, r.providerSpec, overcommitProfile, userData, r.kubevirtClient)
 	if err != nil {
-		klog.Errorf("%s: error creating machine: %v", r.machine.Name, err)
+		r.log().Error(err, "failed to create VirtualMachine")
 		conditionFailed := conditionFailed()
 		conditionFailed.Message = err.Error()
-		r.machineScope.setProviderStatus(nil, conditionFailed)
-		return fmt.Errorf("failed to launch instance: %w", err)
+		r.machineScope.setProviderStatus(conditionFailed)
+		if r.failureBudget != nil && !isTransient(err) {
+			r.recordProvisioningFailure()
+		}
+		if r.cloneCoordinator != nil {
+			r.cloneCoordinator.release(r.cloneSource(), r.machine.Name)
+		}
+		return fmt.Errorf("failed to create VirtualMachine: %w", err)
+	}
+
+	r.log().Info("created machine", "vm", vm.Name)
+
+	r.machineScope.setProviderID(vm)
+	r.machineScope.setVMStatus(vm, nil)
+
+	r.machineScope.setProviderStatus(conditionSuccess())
+	if r.providerSpec.AdvancedTuning != nil {
+		r.machineScope.setProviderStatus(advancedTuningCondition(r.providerSpec.AdvancedTuning))
+	}
+	setMachineZoneLabel(r.machine, r.providerSpec.FailureDomain)
+	setMachinePreemptible(r.machine, r.providerSpec.Preemptible)
+
+	return r.requeueIfVMNotReady(vm)
+}
+
+// validateCreate returns an error if the machine cannot be created out of its provider spec.
+func (r *Reconciler) validateCreate() error {
+	if err := validateMachine(*r.machine); err != nil {
+		return fmt.Errorf("%v: failed validating machine provider spec: %w", r.machine.GetName(), err)
+	}
+	if err := r.validateProviderSpec(); err != nil {
+		return err
+	}
+
+	if r.providerSpec.AdvancedTuning != nil && !r.advancedTuningEnabled {
+		return machinecontroller.InvalidMachineConfiguration("%v: advancedTuning is not enabled on this controller", r.machine.GetName())
+	}
+	if err := r.validateInfraAccess(); err != nil {
+		return err
+	}
+	return r.validateSecretReferences()
+}
+
+// validateSecretReferences returns an error, requeueing the machine, while the SSH keys secret
+// referenced by the provider spec does not exist. The credentials secret is read by the client
+// builder when the machine scope is created.
+func (r *Reconciler) validateSecretReferences() error {
+	sshKeys := r.providerSpec.SSHKeys
+	if sshKeys == nil || sshKeys.SecretRef == nil || sshKeys.SecretRef.Name == "" {
+		return nil
+	}
+	key := types.NamespacedName{Namespace: r.machine.Namespace, Name: sshKeys.SecretRef.Name}
+	if err := r.client.Get(r.Context, key, &corev1.Secret{}); err != nil {
+		return fmt.Errorf("%v: failed to get SSH keys secret %s: %w", r.machine.GetName(), key, err)
+	}
+	return nil
+}
+
+// renderUserData merges the SSH keys, the node IPs and the FQDN of the provider spec into the user data.
+func (r *Reconciler) renderUserData(userData []byte) ([]byte, error) {
+	sshKeys, err := r.machineScope.getSSHKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH keys: %w", err)
+	}
+
+	if len(sshKeys) == 0 && len(r.providerSpec.StaticIPAddresses) == 0 && r.providerSpec.DomainSuffix == "" && r.providerSpec.AddressFamilyPolicy == "" {
+		return userData, nil
 	}
 
-	klog.Infof("Created Machine %v", r.machine.Name)
+	format, err := resolveUserDataFormat(r.providerSpec.UserDataFormat, userData)
+	if err != nil {
+		return nil, machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
 
-	if err = r.setProviderID(vm); err != nil {
-		return fmt.Errorf("failed to update machine object with providerID: %w", err)
+	if userData, err = injectSSHKeys(userData, format, sshKeys); err != nil {
+		return nil, machinecontroller.InvalidMachineConfiguration("%v: failed to inject SSH keys into user data: %v", r.machine.GetName(), err)
 	}
 
-	if err = r.setMachineCloudProviderSpecifics(vm); err != nil {
-		return fmt.Errorf("failed to set machine cloud provider specifics: %w", err)
+	nodeIPs, err := resolveNodeIPs(r.providerSpec)
+	if err != nil {
+		return nil, machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+	if userData, err = injectNodeIPs(userData, format, nodeIPs); err != nil {
+		return nil, machinecontroller.InvalidMachineConfiguration("%v: failed to inject node IPs into user data: %v", r.machine.GetName(), err)
 	}
 
-	r.machineScope.setProviderStatus(vm, conditionSuccess())
+	if r.providerSpec.DomainSuffix != "" {
+		fqdn, err := guestFQDN(r.machine.GetName(), r.providerSpec.DomainSuffix)
+		if err != nil {
+			return nil, machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+		}
+		if userData, err = injectFQDN(userData, format, r.machine.GetName(), fqdn); err != nil {
+			return nil, machinecontroller.InvalidMachineConfiguration("%v: failed to inject FQDN into user data: %v", r.machine.GetName(), err)
+		}
+	}
 
-	return r.requeueIfInstancePending(vm)
+	return userData, nil
 }
 
 // delete deletes machine
 func (r *Reconciler) delete() error {
-	klog.Infof("%s: deleting machine", r.machine.Name)
+	r.log().Info("deleting machine")
 
-	// Get all instances not terminated.
-	existingInstances, err := r.getMachineInstances()
+	vm, err := r.getMachineVM()
 	if err != nil {
-		klog.Errorf("%s: error getting existing instances: %v", r.machine.Name, err)
+		r.log().Error(err, "failed to get existing VirtualMachine")
 		return err
 	}
 
-	existingLen := len(existingInstances)
-	klog.Infof("%s: found %d existing instances for machine", r.machine.Name, existingLen)
-	if existingLen == 0 {
-		klog.Warningf("%s: no instances found to delete for machine", r.machine.Name)
-		return nil
+	if vm == nil {
+		r.log().Info("no VirtualMachine found to delete for machine")
+	} else {
+		if err := r.exportDataBeforeDelete(vm); err != nil {
+			return err
+		}
+		if err := r.snapshotBeforeDelete(vm); err != nil {
+			return err
+		}
+		force, err := r.shutdownBeforeDelete(vm)
+		if err != nil {
+			return err
+		}
+		options := &metav1.DeleteOptions{}
+		if force {
+			gracePeriod := int64(0)
+			options.GracePeriodSeconds = &gracePeriod
+		}
+		if err := tracing.Trace(r.Context, "DeleteVirtualMachine", func() error {
+			return r.kubevirtClient.DeleteVirtualMachine(r.Context, vm.Namespace, vm.Name, options)
+		}, tracing.String("vm.name", vm.Name)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete VirtualMachine: %w", err)
+		}
 	}
 
-	terminatingInstances, err := terminateInstances(r.kubevirtClient, existingInstances)
-	if err != nil {
-		return fmt.Errorf("failed to delete instaces: %w", err)
+	userDataSecretName := r.machine.Name + userDataSecretSuffix
+	if err := tracing.Trace(r.Context, "DeleteUserDataSecret", func() error {
+		return r.kubevirtClient.DeleteSecret(r.Context, infraNamespace(r.machine, r.providerSpec), userDataSecretName, &metav1.DeleteOptions{})
+	}, tracing.String("secret.name", userDataSecretName)); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete UserData secret %s: %w", userDataSecretName, err)
 	}
 
-	if len(terminatingInstances) == 1 {
-		if terminatingInstances[0] != nil && terminatingInstances[0].CurrentState != nil && terminatingInstances[0].CurrentState.Name != nil {
-			r.machine.Annotations[machinecontroller.MachineInstanceStateAnnotationName] = aws.StringValue(terminatingInstances[0].CurrentState.Name)
-		}
+	if err := r.deregisterDNS(); err != nil {
+		return err
+	}
+
+	if r.cloneCoordinator != nil {
+		r.cloneCoordinator.release(r.cloneSource(), r.machine.Name)
 	}
+	r.pruneExpiredSnapshots(time.Now())
 
-	klog.Infof("Deleted machine %v", r.machine.Name)
+	r.log().Info("deleted machine")
 
 	return nil
 }
 
 // update finds a vm and reconciles the machine resource status against it.
 func (r *Reconciler) update() error {
-	klog.Infof("%s: updating machine", r.machine.Name)
+	r.log().Info("updating machine")
 
 	if err := validateMachine(*r.machine); err != nil {
 		return fmt.Errorf("%v: failed validating machine provider spec: %v", r.machine.GetName(), err)
 	}
+	applyAllocatedAddresses(r.providerSpec, r.providerStatus.AllocatedAddresses)
 
-	// Get all instances not terminated.
-	existingInstances, err := r.getMachineInstances()
+	vm, err := r.getMachineVM()
 	if err != nil {
-		klog.Errorf("%s: error getting existing instances: %v", r.machine.Name, err)
+		r.log().Error(err, "failed to get existing VirtualMachine")
 		return err
 	}
 
-	existingLen := len(existingInstances)
-	if existingLen == 0 {
+	if vm == nil {
 		if r.machine.Spec.ProviderID != nil && *r.machine.Spec.ProviderID != "" && (r.machine.Status.LastUpdated == nil || r.machine.Status.LastUpdated.Add(requeueAfterSeconds*time.Second).After(time.Now())) {
-			klog.Infof("%s: Possible eventual-consistency discrepancy; returning an error to requeue", r.machine.Name)
+			r.log().Info("possible eventual-consistency discrepancy, returning an error to requeue")
 			return &machinecontroller.RequeueAfterError{RequeueAfter: requeueAfterSeconds * time.Second}
 		}
 
-		klog.Warningf("%s: attempted to update machine but no instances found", r.machine.Name)
+		r.log().Info("attempted to update machine but no VirtualMachine found")
 
 		// Update status to clear out machine details.
-		r.machineScope.setProviderStatus(nil, conditionSuccess())
+		r.providerStatus.VMID = ""
+		r.providerStatus.Phase = ""
+		r.providerStatus.VMState = ""
+		r.machineScope.setProviderStatus(conditionSuccess())
 		// This is an unrecoverable error condition.  We should delay to
 		// minimize unnecessary API calls.
 		return &machinecontroller.RequeueAfterError{RequeueAfter: requeueAfterFatalSeconds * time.Second}
 	}
 
-	sortInstances(existingInstances)
-	runningInstances := getRunningFromInstances(existingInstances)
-	runningLen := len(runningInstances)
-	var newestInstance *ec2.Instance
+	if err := r.reconcileColdMigration(vm); err != nil {
+		return err
+	}
 
-	if runningLen > 0 {
-		// It would be very unusual to have more than one here, but it is
-		// possible if someone manually provisions a machine with same tag name.
-		klog.Infof("%s: found %d running instances for machine", r.machine.Name, runningLen)
-		newestInstance = runningInstances[0]
+	if err := r.reconcileBootVolumeImport(vm); err != nil {
+		return err
+	}
 
-		err = r.updateLoadBalancers(newestInstance)
+	if r.cloneCoordinator != nil {
+		if err := r.reconcileBootVolumeClone(vm); err != nil {
+			return err
+		}
+	}
+
+	var hibernated bool
+	if vm, hibernated, err = r.reconcileHibernation(vm); err != nil {
+		return err
+	}
+	if hibernated {
+		// The VM is stopped on purpose, none of the reconciliation of running VMs applies
+		vmi, err := r.getMachineVMI()
 		if err != nil {
-			return fmt.Errorf("failed to updated update load balancers: %w", err)
+			r.log().Error(err, "failed to get VirtualMachineInstance")
+			return err
 		}
-	} else {
-		// Didn't find any running instances, just newest existing one.
-		// In most cases, there should only be one existing Instance.
-		newestInstance = existingInstances[0]
+		r.machineScope.setProviderID(vm)
+		r.machineScope.setVMStatus(vm, vmi)
+		r.machineScope.setProviderStatus(conditionSuccess())
+		r.log().Info("updated hibernated machine")
+		return nil
 	}
 
-	if err = r.setProviderID(newestInstance); err != nil {
-		return fmt.Errorf("failed to update machine object with providerID: %w", err)
+	if vm, err = r.reconcileRunStrategy(vm); err != nil {
+		return err
+	}
+
+	vmi, err := r.getMachineVMI()
+	if err != nil {
+		r.log().Error(err, "failed to get VirtualMachineInstance")
+		return err
+	}
+	if err := r.reconcileBootVolumeProgress(vm, vmi); err != nil {
+		return err
+	}
+	if vm, err = r.reconcileGuestShutdown(vm, vmi); err != nil {
+		return err
+	}
+	if vm, err = r.reconcileLauncherResources(vm, vmi); err != nil {
+		return err
+	}
+	if vm, err = r.reconcileMetadataPropagation(vm, vmi); err != nil {
+		return err
+	}
+	if vm, err = r.reconcileControlPlaneLoadBalancer(vm, vmi); err != nil {
+		return err
+	}
+	if vm, err = r.reconcileSpecDrift(vm, vmi, time.Now()); err != nil {
+		return err
+	}
+	if r.providerSpec.Preemptible != nil {
+		if err = r.reconcilePreemption(vmi); err != nil {
+			return err
+		}
+	}
+	if err = r.reconcileRemediation(vm, vmi, time.Now()); err != nil {
+		return err
+	}
+	r.machineScope.setAddresses(vmi)
+	r.machineScope.setGuestAgentStatus(vmi)
+	r.machineScope.setProviderID(vm)
+	r.machineScope.setVMStatus(vm, vmi)
+	r.machineScope.setDiagnosticsURLs(vm, vmi)
+
+	if err = r.reconcileNodeProviderID(vm); err != nil {
+		return err
+	}
+	if err = r.reconcileNodeLabels(); err != nil {
+		return err
+	}
+	if err = r.reconcileNodeTaints(); err != nil {
+		return err
+	}
+	if err = r.reconcileDNSRegistration(); err != nil {
+		return err
+	}
+	if err = r.reconcileReboot(vm, vmi); err != nil {
+		return err
+	}
+	r.reconcileSerialConsoleLog(vmi, time.Now())
+	if vmi != nil && r.providerSpec.EvictionStrategy == kubevirtproviderv1.EvictionStrategyLiveMigrate {
+		r.machineScope.setProviderStatus(liveMigratableCondition(vmi))
 	}
 
-	if err = r.setMachineCloudProviderSpecifics(newestInstance); err != nil {
-		return fmt.Errorf("failed to set machine cloud provider specifics: %w", err)
+	if err = r.updateBootImageCondition(vm); err != nil {
+		return fmt.Errorf("failed to check boot image of VirtualMachine: %w", err)
 	}
 
-	klog.Infof("Updated machine %s", r.machine.Name)
+	if err = r.updateCertificateSANsCondition(); err != nil {
+		return fmt.Errorf("failed to verify kubelet serving certificate SANs: %w", err)
+	}
+
+	if err = r.updateGuestProblemsCondition(vmi); err != nil {
+		return fmt.Errorf("failed to check guest problems of node: %w", err)
+	}
 
-	r.machineScope.setProviderStatus(newestInstance, conditionSuccess())
+	if err = r.reconcileMaxLifetime(time.Now()); err != nil {
+		return err
+	}
 
-	return r.requeueIfInstancePending(newestInstance)
+	if r.failureBudget != nil {
+		r.updateFailureBudget()
+	}
+
+	r.log().Info("updated machine")
+
+	r.machineScope.setProviderStatus(conditionSuccess())
+	if r.providerSpec.AdvancedTuning != nil {
+		r.machineScope.setProviderStatus(advancedTuningCondition(r.providerSpec.AdvancedTuning))
+	}
+	setMachineZoneLabel(r.machine, r.providerSpec.FailureDomain)
+	setMachinePreemptible(r.machine, r.providerSpec.Preemptible)
+
+	if err := r.requeueIfRemediating(); err != nil {
+		return err
+	}
+	if err := r.requeueIfVMNotReady(vm); err != nil {
+		return err
+	}
+	return r.requeueIfGuestAgentNotConnected(vmi)
 }
 
 // exists returns true if machine exists.
 func (r *Reconciler) exists() (bool, error) {
-	// Get all instances not terminated.
-	existingInstances, err := r.getMachineInstances()
+	vm, err := r.getMachineVM()
 	if err != nil {
-		klog.Errorf("%s: error getting existing instances: %v", r.machine.Name, err)
+		r.log().Error(err, "failed to get existing VirtualMachine")
 		return false, err
 	}
 
-	if len(existingInstances) == 0 {
+	if vm == nil {
 		if r.machine.Spec.ProviderID != nil && *r.machine.Spec.ProviderID != "" && (r.machine.Status.LastUpdated == nil || r.machine.Status.LastUpdated.Add(requeueAfterSeconds*time.Second).After(time.Now())) {
-			klog.Infof("%s: Possible eventual-consistency discrepancy; returning an error to requeue", r.machine.Name)
+			r.log().Info("possible eventual-consistency discrepancy, returning an error to requeue")
 			return false, &machinecontroller.RequeueAfterError{RequeueAfter: requeueAfterSeconds * time.Second}
 		}
 
-		klog.Infof("%s: Instance does not exist", r.machine.Name)
+		r.log().Info("VirtualMachine does not exist")
 		return false, nil
 	}
 
-	return existingInstances[0] != nil, err
+	return true, nil
 }
 
 // isMaster returns true if the machine is part of a cluster's control plane
 func (r *Reconciler) isMaster() (bool, error) {
 	if r.machine.Status.NodeRef == nil {
-		klog.Errorf("NodeRef not found in machine %s", r.machine.Name)
+		r.log().Error(nil, "NodeRef not found in machine")
 		return false, nil
 	}
 	node := &corev1.Node{}
@@ -216,136 +456,139 @@ func (r *Reconciler) isMaster() (bool, error) {
 	return false, nil
 }
 
-// updateLoadBalancers adds a given machine instance to the load balancers specified in its provider config
-func (r *Reconciler) updateLoadBalancers(instance *ec2.Instance) error {
-	if len(r.providerSpec.LoadBalancers) == 0 {
-		klog.V(4).Infof("%s: Instance %q has no load balancers configured. Skipping", r.machine.Name, *instance.InstanceId)
-		return nil
+// reconcileRunStrategy updates the run strategy of the VM to the one of the provider spec.
+func (r *Reconciler) reconcileRunStrategy(vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	runStrategy, err := resolveRunStrategy(r.providerSpec)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", r.machine.GetName(), err)
 	}
-	errs := []error{}
-	classicLoadBalancerNames := []string{}
-	networkLoadBalancerNames := []string{}
-	for _, loadBalancerRef := range r.providerSpec.LoadBalancers {
-		switch loadBalancerRef.Type {
-		case awsproviderv1.NetworkLoadBalancerType:
-			networkLoadBalancerNames = append(networkLoadBalancerNames, loadBalancerRef.Name)
-		case awsproviderv1.ClassicLoadBalancerType:
-			classicLoadBalancerNames = append(classicLoadBalancerNames, loadBalancerRef.Name)
-		}
+	if !runStrategyOutdated(vm, runStrategy) {
+		return vm, nil
 	}
 
-	var err error
-	if len(classicLoadBalancerNames) > 0 {
-		err := registerWithClassicLoadBalancers(r.awsClient, classicLoadBalancerNames, instance)
-		if err != nil {
-			klog.Errorf("%s: Failed to register classic load balancers: %v", r.machine.Name, err)
-			errs = append(errs, err)
-		}
-	}
-	if len(networkLoadBalancerNames) > 0 {
-		err = registerWithNetworkLoadBalancers(r.awsClient, networkLoadBalancerNames, instance)
-		if err != nil {
-			klog.Errorf("%s: Failed to register network load balancers: %v", r.machine.Name, err)
-			errs = append(errs, err)
-		}
-	}
-	if len(errs) > 0 {
-		return errorutil.NewAggregate(errs)
+	r.log().Info("updating run strategy of VirtualMachine", "runStrategy", runStrategy)
+	applyRunStrategy(vm, runStrategy)
+	updatedVM, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, vm.Namespace, vm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update run strategy of VirtualMachine: %w", err)
 	}
-	return nil
+	return updatedVM, nil
 }
 
-// setProviderID adds providerID in the machine spec
-func (r *Reconciler) setProviderID(vm *kubevirtapis.VirtualMachineInstance) error {
-	existingProviderID := r.machine.Spec.ProviderID
-	if instance == nil {
+// updateBootImageCondition sets the OutdatedBootImage condition when boot image tracking
+// is enabled, by comparing the source PVC the VM was cloned from with the current one.
+func (r *Reconciler) updateBootImageCondition(vm *kubevirtapis.VirtualMachine) error {
+	if !r.providerSpec.TrackBootImage {
 		return nil
 	}
-	availabilityZone := ""
-	if instance.Placement != nil {
-		availabilityZone = aws.StringValue(instance.Placement.AvailabilityZone)
+
+	sourcePvcName, err := resolveBootImageSource(r.Context, r.kubevirtClient, vm.Namespace, r.providerSpec)
+	if err != nil {
+		return err
+	}
+	sourcePvc, err := r.kubevirtClient.GetPersistentVolumeClaim(r.Context, vm.Namespace, sourcePvcName, &metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get source PVC %s/%s: %w", vm.Namespace, sourcePvcName, err)
 	}
-	providerID := fmt.Sprintf("aws:///%s/%s", availabilityZone, aws.StringValue(instance.InstanceId))
 
-	if existingProviderID != nil && *existingProviderID == providerID {
-		klog.Infof("%s: ProviderID already set in the machine Spec with value:%s", r.machine.Name, *existingProviderID)
-		return nil
+	condition := bootImageCondition(vm, sourcePvc)
+	if condition.Reason == kubevirtproviderv1.BootImageUpdated {
+		r.log().Info("VirtualMachine was created from an outdated revision of the source PVC", "pvc", sourcePvc.Name)
 	}
-	r.machine.Spec.ProviderID = &providerID
-	klog.Infof("%s: ProviderID set at machine spec: %s", r.machine.Name, providerID)
+	r.machineScope.setProviderStatus(condition)
+
 	return nil
 }
 
-func (r *Reconciler) setMachineCloudProviderSpecifics(instance *ec2.Instance) error {
-	if instance == nil {
+// updateCertificateSANsCondition verifies, once the node joined, that the kubelet serving
+// certificate was requested with the static IP addresses of the machine as SANs.
+func (r *Reconciler) updateCertificateSANsCondition() error {
+	if len(r.providerSpec.StaticIPAddresses) == 0 || r.machine.Status.NodeRef == nil {
 		return nil
 	}
 
-	if r.machine.Labels == nil {
-		r.machine.Labels = make(map[string]string)
-	}
-
-	if r.machine.Spec.Labels == nil {
-		r.machine.Spec.Labels = make(map[string]string)
+	ips, err := parseStaticIPAddresses(r.providerSpec.StaticIPAddresses)
+	if err != nil {
+		return err
 	}
 
-	if r.machine.Annotations == nil {
-		r.machine.Annotations = make(map[string]string)
+	csrs := &certificatesv1beta1.CertificateSigningRequestList{}
+	if err := r.client.List(r.Context, csrs); err != nil {
+		return fmt.Errorf("failed to list certificate signing requests: %w", err)
 	}
 
-	// Reaching to machine provider config since the region is not directly
-	// providing by *ec2.Instance object
-	machineProviderConfig, err := awsproviderv1.ProviderSpecFromRawExtension(r.machine.Spec.ProviderSpec.Value)
+	condition, err := certificateSANsCondition(r.machine.Status.NodeRef.Name, csrs.Items, ips)
 	if err != nil {
-		return fmt.Errorf("error decoding MachineProviderConfig: %w", err)
+		return err
+	}
+	if condition.Status == corev1.ConditionFalse {
+		r.log().Info(condition.Message, "condition", condition.Type, "reason", condition.Reason)
 	}
+	r.machineScope.setProviderStatus(condition)
 
-	r.machine.Labels[machinecontroller.MachineRegionLabelName] = machineProviderConfig.Placement.Region
+	return nil
+}
 
-	if instance.Placement != nil {
-		r.machine.Labels[machinecontroller.MachineAZLabelName] = aws.StringValue(instance.Placement.AvailabilityZone)
+// updateGuestProblemsCondition reports, once the node joined, the problems node-problem-detector
+// detects on the virtualized node.
+func (r *Reconciler) updateGuestProblemsCondition(vmi *kubevirtapis.VirtualMachineInstance) error {
+	if r.machine.Status.NodeRef == nil {
+		return nil
 	}
 
-	if instance.InstanceType != nil {
-		r.machine.Labels[machinecontroller.MachineInstanceTypeLabelName] = aws.StringValue(instance.InstanceType)
+	node := &corev1.Node{}
+	if err := r.client.Get(r.Context, types.NamespacedName{Name: r.machine.Status.NodeRef.Name}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
 	}
 
-	if instance.State != nil && instance.State.Name != nil {
-		r.machine.Annotations[machinecontroller.MachineInstanceStateAnnotationName] = aws.StringValue(instance.State.Name)
+	condition := guestProblemsCondition(node, vmi)
+	if condition == nil {
+		return nil
 	}
-
-	if instance.InstanceLifecycle != nil && *instance.InstanceLifecycle == ec2.InstanceLifecycleTypeSpot {
-		// Label on the Spec so that it is propogated to the Node
-		r.machine.Spec.Labels[machinecontroller.MachineInterruptibleInstanceLabelName] = ""
+	if condition.Status == corev1.ConditionTrue {
+		r.log().Info(condition.Message, "condition", condition.Type, "reason", condition.Reason)
 	}
+	r.machineScope.setProviderStatus(*condition)
 
 	return nil
 }
 
-func (r *Reconciler) requeueIfInstancePending(instance *ec2.Instance) error {
-	// If machine state is still pending, we will return an error to keep the controllers
-	// attempting to update status until it hits a more permanent state. This will ensure
-	// we get a public IP populated more quickly.
-	if instance.State != nil && *instance.State.Name == ec2.InstanceStateNamePending {
-		klog.Infof("%s: Instance state still pending, returning an error to requeue", r.machine.Name)
+func (r *Reconciler) requeueIfVMNotReady(vm *kubevirtapis.VirtualMachine) error {
+	// If the VM is not ready yet, we will return an error to keep the controllers
+	// attempting to update status until it hits a more permanent state.
+	if !vm.Status.Ready {
+		r.log().Info("VirtualMachine is not ready yet, returning an error to requeue")
 		return &machinecontroller.RequeueAfterError{RequeueAfter: requeueAfterSeconds * time.Second}
 	}
 
 	return nil
 }
 
-func (r *Reconciler) getMachineInstances() ([]*apis.VirtualMachineInstance, error) {
-	// If there is a non-empty instance ID, search using that, otherwise
-	// fallback to filtering based on tags.
-	if r.providerStatus.InstanceID != nil && *r.providerStatus.InstanceID != "" {
-		i, err := getExistingInstanceByID(*r.providerStatus.InstanceID, r.awsClient)
-		if err != nil {
-			klog.Warningf("%s: Failed to find existing instance by id %s: %v", r.machine.Name, *r.providerStatus.InstanceID, err)
-		} else {
-			klog.Infof("%s: Found instance by id: %s", r.machine.Name, *r.providerStatus.InstanceID)
-			return []*ec2.Instance{i}, nil
+// getMachineVM returns the VirtualMachine backing the machine, or nil if it does not exist.
+func (r *Reconciler) getMachineVM() (*kubevirtapis.VirtualMachine, error) {
+	vm, err := r.kubevirtClient.GetVirtualMachine(r.Context, infraNamespace(r.machine, r.providerSpec), r.machine.Name, &metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return vm, nil
+}
+
+// getMachineVMI returns the VirtualMachineInstance backing the machine, or nil if the VM is not running.
+func (r *Reconciler) getMachineVMI() (*kubevirtapis.VirtualMachineInstance, error) {
+	vmi, err := r.kubevirtClient.GetVirtualMachineInstance(r.Context, infraNamespace(r.machine, r.providerSpec), r.machine.Name, &metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
 		}
+		return nil, err
 	}
 
-	return getExistingInstances(r.machine, r.awsClient)
+	return vmi, nil
 }
@@ -0,0 +1,98 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// externalRemediationAnnotation is set on unhealthy machines, instead of deleting them, by
+	// the MachineHealthChecks annotated with the external-baremetal remediation strategy. The
+	// provider remediates those machines by restarting their VM, which keeps their volumes, and
+	// removes the annotation once the VM runs again. It may also be set by hand.
+	externalRemediationAnnotation = "host.metal3.io/external-remediation"
+
+	// remediationTimeout is how long the restarted VM of a remediated machine has to run again
+	// before the machine is deleted for its MachineSet to replace it.
+	remediationTimeout = 10 * time.Minute
+	// remediationRequeue is the delay between the checks of a restarted VM.
+	remediationRequeue = 15 * time.Second
+)
+
+// remediatedCondition reports the remediation of the machine.
+func remediatedCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.Remediated,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reconcileRemediation remediates a machine with the external remediation annotation in place,
+// restarting its VM instead of recreating it. Once a VMI created after the restart runs the
+// annotation is removed. If none does within the remediation timeout, the machine is deleted.
+func (r *Reconciler) reconcileRemediation(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance, now time.Time) error {
+	if _, ok := r.machine.Annotations[externalRemediationAnnotation]; !ok {
+		r.providerStatus.RemediationRestartTime = nil
+		return nil
+	}
+
+	restartTime := r.providerStatus.RemediationRestartTime
+	if restartTime == nil {
+		r.log().Info("remediating unhealthy machine by restarting its VirtualMachine")
+		if err := r.kubevirtClient.RestartVirtualMachine(r.Context, vm.Namespace, vm.Name); err != nil {
+			return fmt.Errorf("failed to restart VirtualMachine: %w", err)
+		}
+		r.providerStatus.RemediationRestartTime = &metav1.Time{Time: now}
+		r.machineScope.setProviderStatus(remediatedCondition(corev1.ConditionFalse, kubevirtproviderv1.RemediationInProgress,
+			"VirtualMachine restarted to remediate the unhealthy machine"))
+		return nil
+	}
+
+	// The provider status only keeps the restart time to the second
+	if vmi != nil && vmi.Status.Phase == kubevirtapis.Running && !vmi.CreationTimestamp.Time.Before(restartTime.Time.Truncate(time.Second)) {
+		r.log().Info("remediated unhealthy machine, its VirtualMachine runs again")
+		delete(r.machine.Annotations, externalRemediationAnnotation)
+		r.providerStatus.RemediationRestartTime = nil
+		r.machineScope.setProviderStatus(remediatedCondition(corev1.ConditionTrue, kubevirtproviderv1.RemediationSucceeded,
+			fmt.Sprintf("VirtualMachine restarted at %s to remediate the unhealthy machine", restartTime.UTC().Format(time.RFC3339))))
+		return nil
+	}
+	if now.Sub(restartTime.Time) < remediationTimeout {
+		return nil
+	}
+
+	message := fmt.Sprintf("VirtualMachine restarted at %s does not run after %v", restartTime.UTC().Format(time.RFC3339), remediationTimeout)
+	r.machineScope.setProviderStatus(remediatedCondition(corev1.ConditionFalse, kubevirtproviderv1.RemediationFailed, message))
+	if _, ok := machineSetKey(r.machine); !ok {
+		// Nothing would replace the machine
+		return nil
+	}
+	if r.machine.DeletionTimestamp != nil {
+		return nil
+	}
+	klog.Warningf("%s: %s, deleting machine for its MachineSet to replace it", r.machine.Name, message)
+	if err := r.client.Delete(r.Context, r.machine); err != nil {
+		return fmt.Errorf("failed to delete unremediated machine: %w", err)
+	}
+	return nil
+}
+
+// requeueIfRemediating requeues the update of a machine being remediated, until its restarted
+// VM runs again or the remediation times out.
+func (r *Reconciler) requeueIfRemediating() error {
+	condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.Remediated)
+	if r.providerStatus.RemediationRestartTime != nil && condition != nil && condition.Reason == kubevirtproviderv1.RemediationInProgress {
+		return &machinecontroller.RequeueAfterError{RequeueAfter: remediationRequeue}
+	}
+	return nil
+}
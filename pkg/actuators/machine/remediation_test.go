@@ -0,0 +1,167 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestReconcileRemediation(t *testing.T) {
+	now := time.Now()
+	restartTime := metav1.NewTime(now.Add(-time.Minute).Truncate(time.Second))
+	timedOutRestartTime := metav1.NewTime(now.Add(-remediationTimeout - time.Minute))
+
+	testCases := []struct {
+		testcase          string
+		annotated         bool
+		restartTime       *metav1.Time
+		vmiCreated        time.Time
+		vmiPhase          kubevirtapis.VirtualMachineInstancePhase
+		inMachineSet      bool
+		expectRestart     bool
+		expectAnnotation  bool
+		expectRestartTime bool
+		expectReason      kubevirtproviderv1.KubevirtMachineProviderConditionReason
+		expectDeleted     bool
+		expectRequeue     bool
+	}{
+		{
+			testcase: "healthy machine",
+		},
+		{
+			testcase:          "restarts the VM",
+			annotated:         true,
+			vmiCreated:        now.Add(-time.Hour),
+			vmiPhase:          kubevirtapis.Running,
+			expectRestart:     true,
+			expectAnnotation:  true,
+			expectRestartTime: true,
+			expectReason:      kubevirtproviderv1.RemediationInProgress,
+			expectRequeue:     true,
+		},
+		{
+			testcase:          "waits for the restarted VM",
+			annotated:         true,
+			restartTime:       &restartTime,
+			vmiCreated:        now.Add(-time.Hour),
+			vmiPhase:          kubevirtapis.Running,
+			expectAnnotation:  true,
+			expectRestartTime: true,
+			expectReason:      kubevirtproviderv1.RemediationInProgress,
+			expectRequeue:     true,
+		},
+		{
+			testcase:     "removes the annotation once the VM runs again",
+			annotated:    true,
+			restartTime:  &restartTime,
+			vmiCreated:   restartTime.Time,
+			vmiPhase:     kubevirtapis.Running,
+			expectReason: kubevirtproviderv1.RemediationSucceeded,
+		},
+		{
+			testcase:          "deletes the machine once the remediation timed out",
+			annotated:         true,
+			restartTime:       &timedOutRestartTime,
+			vmiCreated:        now,
+			vmiPhase:          kubevirtapis.Scheduling,
+			inMachineSet:      true,
+			expectAnnotation:  true,
+			expectRestartTime: true,
+			expectReason:      kubevirtproviderv1.RemediationFailed,
+			expectDeleted:     true,
+		},
+		{
+			testcase:          "keeps a machine without MachineSet",
+			annotated:         true,
+			restartTime:       &timedOutRestartTime,
+			vmiCreated:        now,
+			vmiPhase:          kubevirtapis.Scheduling,
+			expectAnnotation:  true,
+			expectRestartTime: true,
+			expectReason:      kubevirtproviderv1.RemediationFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api"},
+			}
+			if tc.annotated {
+				machine.Annotations = map[string]string{externalRemediationAnnotation: ""}
+			}
+			if tc.inMachineSet {
+				machine.Labels = map[string]string{machineSetLabel: "worker"}
+			}
+			scheme := runtime.NewScheme()
+			if err := machinev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			client := fake.NewFakeClientWithScheme(scheme, machine.DeepCopy())
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			kubevirtClient := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.expectRestart {
+				kubevirtClient.EXPECT().RestartVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde").Return(nil)
+			}
+
+			providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{RemediationRestartTime: tc.restartTime}
+			if tc.restartTime != nil {
+				providerStatus.Conditions = []kubevirtproviderv1.KubevirtMachineProviderCondition{remediatedCondition(corev1.ConditionFalse, kubevirtproviderv1.RemediationInProgress, "")}
+			}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				client:         client,
+				kubevirtClient: kubevirtClient,
+				machine:        machine,
+				providerStatus: providerStatus,
+			})
+			vm := &kubevirtapis.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+			vmi := &kubevirtapis.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", CreationTimestamp: metav1.NewTime(tc.vmiCreated)},
+				Status:     kubevirtapis.VirtualMachineInstanceStatus{Phase: tc.vmiPhase},
+			}
+
+			if err := r.reconcileRemediation(vm, vmi, now); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if _, ok := machine.Annotations[externalRemediationAnnotation]; ok != tc.expectAnnotation {
+				t.Errorf("Expected the external remediation annotation %v, got %v", tc.expectAnnotation, machine.Annotations)
+			}
+			if (providerStatus.RemediationRestartTime != nil) != tc.expectRestartTime {
+				t.Errorf("Expected a remediation restart time %v, got %v", tc.expectRestartTime, providerStatus.RemediationRestartTime)
+			}
+			condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.Remediated)
+			if tc.expectReason == "" && condition != nil {
+				t.Errorf("Expected no Remediated condition, got %v", condition)
+			}
+			if tc.expectReason != "" && (condition == nil || condition.Reason != tc.expectReason) {
+				t.Errorf("Expected reason %v, got condition %v", tc.expectReason, condition)
+			}
+			err := client.Get(context.Background(), types.NamespacedName{Namespace: machine.Namespace, Name: machine.Name}, &machinev1.Machine{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectDeleted {
+				t.Errorf("Expected the machine deleted %v, got error %v", tc.expectDeleted, err)
+			}
+			_, requeue := r.requeueIfRemediating().(*machinecontroller.RequeueAfterError)
+			if requeue != tc.expectRequeue {
+				t.Errorf("Expected requeue %v, got %v", tc.expectRequeue, requeue)
+			}
+		})
+	}
+}
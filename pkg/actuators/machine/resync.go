@@ -0,0 +1,136 @@
+package machine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// machinePhaseRunning is the phase the machine controller sets once the node joined.
+const machinePhaseRunning = "Running"
+
+type syncRecord struct {
+	resourceVersion string
+	time            time.Time
+}
+
+// ResyncTracker adapts the resync interval of running machines to the health of the fleet.
+// While every machine is running, periodic resyncs of a machine against its VM are stretched
+// to the maximum interval. As soon as one machine is provisioning, deleting or failing, they
+// are brought back to the minimum interval. The manager sync period must be set to the
+// minimum interval, resyncs falling within the current interval are skipped.
+type ResyncTracker struct {
+	client      runtimeclient.Client
+	namespace   string
+	minInterval time.Duration
+	maxInterval time.Duration
+
+	mu       sync.Mutex
+	interval time.Duration
+	synced   map[types.NamespacedName]syncRecord
+}
+
+// NewResyncTracker returns a ResyncTracker for the machines in namespace, or in all
+// namespaces if empty.
+func NewResyncTracker(client runtimeclient.Client, namespace string, minInterval, maxInterval time.Duration) *ResyncTracker {
+	if maxInterval < minInterval {
+		maxInterval = minInterval
+	}
+	return &ResyncTracker{
+		client:      client,
+		namespace:   namespace,
+		minInterval: minInterval,
+		maxInterval: maxInterval,
+		interval:    minInterval,
+		synced:      make(map[types.NamespacedName]syncRecord),
+	}
+}
+
+// Start periodically recomputes the resync interval out of the health of the fleet.
+// It implements manager.Runnable.
+func (t *ResyncTracker) Start(stop <-chan struct{}) error {
+	wait.Until(t.refresh, t.minInterval, stop)
+	return nil
+}
+
+func (t *ResyncTracker) refresh() {
+	machines := &machinev1.MachineList{}
+	if err := t.client.List(context.Background(), machines, runtimeclient.InNamespace(t.namespace)); err != nil {
+		klog.Errorf("Failed to list machines to compute resync interval: %v", err)
+		return
+	}
+
+	interval := fleetResyncInterval(machines.Items, t.minInterval, t.maxInterval)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if interval != t.interval {
+		klog.Infof("Machine resync interval changed from %v to %v", t.interval, interval)
+		t.interval = interval
+	}
+}
+
+// Interval returns the current resync interval.
+func (t *ResyncTracker) Interval() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.interval
+}
+
+// Due returns true if the machine has to be synced against its VM. Machines which are not
+// running, changed since their last sync, or were last synced longer than the interval ago
+// are always due.
+func (t *ResyncTracker) Due(machine *machinev1.Machine, now time.Time) bool {
+	if !machineIsHealthy(machine) {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.synced[types.NamespacedName{Namespace: machine.Namespace, Name: machine.Name}]
+	if !ok || record.resourceVersion != machine.ResourceVersion {
+		return true
+	}
+
+	return now.Sub(record.time) >= t.interval
+}
+
+// Synced records that the machine was synced against its VM.
+func (t *ResyncTracker) Synced(machine *machinev1.Machine, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.synced[types.NamespacedName{Namespace: machine.Namespace, Name: machine.Name}] = syncRecord{
+		resourceVersion: machine.ResourceVersion,
+		time:            now,
+	}
+}
+
+// Forget drops the sync record of the machine.
+func (t *ResyncTracker) Forget(machine *machinev1.Machine) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.synced, types.NamespacedName{Namespace: machine.Namespace, Name: machine.Name})
+}
+
+func machineIsHealthy(machine *machinev1.Machine) bool {
+	return machine.DeletionTimestamp == nil &&
+		machine.Status.ErrorReason == nil &&
+		machine.Status.Phase != nil && *machine.Status.Phase == machinePhaseRunning
+}
+
+// fleetResyncInterval returns maxInterval if all machines are healthy, minInterval otherwise.
+func fleetResyncInterval(machines []machinev1.Machine, minInterval, maxInterval time.Duration) time.Duration {
+	for i := range machines {
+		if !machineIsHealthy(&machines[i]) {
+			return minInterval
+		}
+	}
+	return maxInterval
+}
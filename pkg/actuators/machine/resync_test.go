@@ -0,0 +1,110 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func machineInPhase(name, phase string) machinev1.Machine {
+	return machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			ResourceVersion: "1",
+		},
+		Status: machinev1.MachineStatus{
+			Phase: &phase,
+		},
+	}
+}
+
+func TestFleetResyncInterval(t *testing.T) {
+	minInterval, maxInterval := time.Minute, 10*time.Minute
+
+	failed := machineInPhase("failed", machinePhaseRunning)
+	errorReason := machinev1.InvalidConfigurationMachineError
+	failed.Status.ErrorReason = &errorReason
+
+	deleting := machineInPhase("deleting", machinePhaseRunning)
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	testCases := []struct {
+		testcase         string
+		machines         []machinev1.Machine
+		expectedInterval time.Duration
+	}{
+		{
+			testcase:         "no machines",
+			expectedInterval: maxInterval,
+		},
+		{
+			testcase:         "all running",
+			machines:         []machinev1.Machine{machineInPhase("a", machinePhaseRunning), machineInPhase("b", machinePhaseRunning)},
+			expectedInterval: maxInterval,
+		},
+		{
+			testcase:         "provisioning",
+			machines:         []machinev1.Machine{machineInPhase("a", machinePhaseRunning), machineInPhase("b", "Provisioning")},
+			expectedInterval: minInterval,
+		},
+		{
+			testcase:         "failing",
+			machines:         []machinev1.Machine{machineInPhase("a", machinePhaseRunning), failed},
+			expectedInterval: minInterval,
+		},
+		{
+			testcase:         "deleting",
+			machines:         []machinev1.Machine{deleting},
+			expectedInterval: minInterval,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			interval := fleetResyncInterval(tc.machines, minInterval, maxInterval)
+			if interval != tc.expectedInterval {
+				t.Errorf("Expected interval %v, got %v", tc.expectedInterval, interval)
+			}
+		})
+	}
+}
+
+func TestResyncTrackerDue(t *testing.T) {
+	tracker := NewResyncTracker(nil, "", time.Minute, 10*time.Minute)
+	tracker.interval = 10 * time.Minute
+
+	now := time.Now()
+	machine := machineInPhase("a", machinePhaseRunning)
+
+	if !tracker.Due(&machine, now) {
+		t.Errorf("Expected never synced machine to be due")
+	}
+
+	tracker.Synced(&machine, now)
+	if tracker.Due(&machine, now.Add(5*time.Minute)) {
+		t.Errorf("Expected machine synced within the interval not to be due")
+	}
+	if !tracker.Due(&machine, now.Add(10*time.Minute)) {
+		t.Errorf("Expected machine synced before the interval to be due")
+	}
+
+	changed := machine.DeepCopy()
+	changed.ResourceVersion = "2"
+	if !tracker.Due(changed, now.Add(time.Minute)) {
+		t.Errorf("Expected changed machine to be due")
+	}
+
+	provisioning := machineInPhase("a", "Provisioning")
+	if !tracker.Due(&provisioning, now.Add(time.Minute)) {
+		t.Errorf("Expected provisioning machine to be due")
+	}
+
+	tracker.Forget(&machine)
+	if !tracker.Due(&machine, now.Add(time.Minute)) {
+		t.Errorf("Expected forgotten machine to be due")
+	}
+}
@@ -0,0 +1,85 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+)
+
+// resolveRunStrategy returns the run strategy of the VM, nil runs the VM through the
+// running field as VMs created before the run strategy was configurable.
+func resolveRunStrategy(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (*kubevirtapis.VirtualMachineRunStrategy, error) {
+	var strategy kubevirtapis.VirtualMachineRunStrategy
+	switch providerSpec.RunStrategy {
+	case "":
+		return nil, nil
+	case kubevirtproviderv1.RunStrategyAlways:
+		strategy = kubevirtapis.RunStrategyAlways
+	case kubevirtproviderv1.RunStrategyRerunOnFailure:
+		strategy = kubevirtapis.RunStrategyRerunOnFailure
+	case kubevirtproviderv1.RunStrategyManual:
+		strategy = kubevirtapis.RunStrategyManual
+	default:
+		return nil, fmt.Errorf("unsupported runStrategy %q, must be one of %q, %q or %q", providerSpec.RunStrategy,
+			kubevirtproviderv1.RunStrategyAlways, kubevirtproviderv1.RunStrategyRerunOnFailure, kubevirtproviderv1.RunStrategyManual)
+	}
+	return &strategy, nil
+}
+
+// applyRunStrategy sets the run strategy of the VM, the running field and the run strategy
+// are mutually exclusive.
+func applyRunStrategy(vm *kubevirtapis.VirtualMachine, strategy *kubevirtapis.VirtualMachineRunStrategy) {
+	if strategy == nil {
+		running := true
+		vm.Spec.Running = &running
+		vm.Spec.RunStrategy = nil
+		return
+	}
+	runStrategy := *strategy
+	vm.Spec.Running = nil
+	vm.Spec.RunStrategy = &runStrategy
+}
+
+// haltVM stops the VM, keeping it stopped until its run strategy is applied again.
+func haltVM(vm *kubevirtapis.VirtualMachine) {
+	if vm.Spec.RunStrategy != nil {
+		halted := kubevirtapis.RunStrategyHalted
+		vm.Spec.RunStrategy = &halted
+		return
+	}
+	running := false
+	vm.Spec.Running = &running
+}
+
+// vmHalted returns true if the VM is requested to be stopped.
+func vmHalted(vm *kubevirtapis.VirtualMachine) bool {
+	strategy, err := vm.RunStrategy()
+	return err == nil && strategy == kubevirtapis.RunStrategyHalted
+}
+
+// runStrategyOutdated returns true if the run strategy of the VM differs from the one of the
+// provider spec. Halted VMs were stopped on purpose and are left alone.
+func runStrategyOutdated(vm *kubevirtapis.VirtualMachine, strategy *kubevirtapis.VirtualMachineRunStrategy) bool {
+	if vmHalted(vm) {
+		return false
+	}
+	if strategy == nil {
+		return vm.Spec.Running == nil || vm.Spec.RunStrategy != nil
+	}
+	return vm.Spec.Running != nil || vm.Spec.RunStrategy == nil || *vm.Spec.RunStrategy != *strategy
+}
+
+// startManualVM starts a VM with the Manual run strategy, which KubeVirt does not start on its own.
+func startManualVM(ctx context.Context, client kubevirtclient.Client, vm *kubevirtapis.VirtualMachine, strategy *kubevirtapis.VirtualMachineRunStrategy) error {
+	if strategy == nil || *strategy != kubevirtapis.RunStrategyManual {
+		return nil
+	}
+	if err := client.StartVirtualMachine(ctx, vm.Namespace, vm.Name); err != nil {
+		return fmt.Errorf("failed to start VirtualMachine: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestBuildVMRunStrategy(t *testing.T) {
+	testCases := []struct {
+		testcase            string
+		runStrategy         kubevirtproviderv1.RunStrategy
+		expectedRunStrategy kubevirtapis.VirtualMachineRunStrategy
+		expectError         bool
+	}{
+		{
+			testcase: "default",
+		},
+		{
+			testcase:            "always",
+			runStrategy:         kubevirtproviderv1.RunStrategyAlways,
+			expectedRunStrategy: kubevirtapis.RunStrategyAlways,
+		},
+		{
+			testcase:            "rerun on failure",
+			runStrategy:         kubevirtproviderv1.RunStrategyRerunOnFailure,
+			expectedRunStrategy: kubevirtapis.RunStrategyRerunOnFailure,
+		},
+		{
+			testcase:            "manual",
+			runStrategy:         kubevirtproviderv1.RunStrategyManual,
+			expectedRunStrategy: kubevirtapis.RunStrategyManual,
+		},
+		{
+			testcase:    "halted",
+			runStrategy: "Halted",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kubevirt-test",
+					Namespace: "kubevirt-test",
+				},
+			}
+			vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName: "rhcos",
+				RunStrategy:   tc.runStrategy,
+			}, []byte("#cloud-config\n"))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tc.expectedRunStrategy == "" {
+				if vm.Spec.Running == nil || !*vm.Spec.Running || vm.Spec.RunStrategy != nil {
+					t.Errorf("Expected running VM without run strategy, got running %v, run strategy %v", vm.Spec.Running, vm.Spec.RunStrategy)
+				}
+				return
+			}
+			if vm.Spec.Running != nil {
+				t.Errorf("Expected running to be unset with a run strategy, got %v", *vm.Spec.Running)
+			}
+			if vm.Spec.RunStrategy == nil || *vm.Spec.RunStrategy != tc.expectedRunStrategy {
+				t.Errorf("Expected run strategy %s, got %v", tc.expectedRunStrategy, vm.Spec.RunStrategy)
+			}
+		})
+	}
+}
+
+func TestRunStrategyOutdated(t *testing.T) {
+	always := kubevirtapis.RunStrategyAlways
+	manual := kubevirtapis.RunStrategyManual
+
+	vm := &kubevirtapis.VirtualMachine{}
+	applyRunStrategy(vm, nil)
+	if runStrategyOutdated(vm, nil) {
+		t.Errorf("Expected running VM to match the default run strategy")
+	}
+	if !runStrategyOutdated(vm, &manual) {
+		t.Errorf("Expected running VM to be outdated against run strategy %s", manual)
+	}
+
+	applyRunStrategy(vm, &always)
+	if runStrategyOutdated(vm, &always) {
+		t.Errorf("Expected VM to match run strategy %s", always)
+	}
+	if !runStrategyOutdated(vm, &manual) {
+		t.Errorf("Expected VM with run strategy %s to be outdated against %s", always, manual)
+	}
+
+	haltVM(vm)
+	if !vmHalted(vm) {
+		t.Errorf("Expected VM to be halted")
+	}
+	if runStrategyOutdated(vm, &manual) {
+		t.Errorf("Expected halted VM to be left alone")
+	}
+}
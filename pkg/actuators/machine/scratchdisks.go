@@ -0,0 +1,86 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// reservedVolumeNames are the names of the volumes the provider attaches to VMs itself.
+var reservedVolumeNames = map[string]bool{
+	mainDiskName:        true,
+	cloudInitVolumeName: true,
+	sysprepVolumeName:   true,
+}
+
+// validateScratchDisks returns an error if a scratch disk has an invalid or duplicate name,
+// size or bus.
+func validateScratchDisks(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	names := map[string]bool{}
+	for _, disk := range providerSpec.ScratchDisks {
+		if errs := validation.IsDNS1123Label(disk.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid scratch disk name %q: %s", disk.Name, strings.Join(errs, ", "))
+		}
+		if reservedVolumeNames[disk.Name] {
+			return fmt.Errorf("scratch disk name %q is reserved", disk.Name)
+		}
+		if names[disk.Name] {
+			return fmt.Errorf("duplicate scratch disk name %q", disk.Name)
+		}
+		names[disk.Name] = true
+
+		size, err := resource.ParseQuantity(disk.Size)
+		if err != nil {
+			return fmt.Errorf("invalid size %q of scratch disk %s: %v", disk.Size, disk.Name, err)
+		}
+		if size.Sign() <= 0 {
+			return fmt.Errorf("size of scratch disk %s must be positive, got %q", disk.Name, disk.Size)
+		}
+
+		switch disk.Bus {
+		case "", kubevirtproviderv1.DiskBusVirtio, kubevirtproviderv1.DiskBusSCSI:
+		case kubevirtproviderv1.DiskBusSATA:
+			if providerSpec.Architecture == kubevirtproviderv1.ArchitectureARM64 {
+				return fmt.Errorf("bus %q of scratch disk %s is not supported on architecture %q", disk.Bus, disk.Name, providerSpec.Architecture)
+			}
+		default:
+			return fmt.Errorf("unsupported bus %q of scratch disk %s, must be one of %q, %q or %q", disk.Bus, disk.Name,
+				kubevirtproviderv1.DiskBusVirtio, kubevirtproviderv1.DiskBusSCSI, kubevirtproviderv1.DiskBusSATA)
+		}
+	}
+	return nil
+}
+
+// buildScratchDisks renders the disks and emptyDisk volumes of the scratch disks, attached with
+// the bus of the root disk unless set otherwise. The disk name is set as its serial number for
+// guests to find it under /dev/disk/by-id.
+func buildScratchDisks(scratchDisks []kubevirtproviderv1.ScratchDisk, rootDiskBus kubevirtproviderv1.DiskBus) ([]kubevirtapis.Disk, []kubevirtapis.Volume, error) {
+	var disks []kubevirtapis.Disk
+	var volumes []kubevirtapis.Volume
+	for _, scratchDisk := range scratchDisks {
+		size, err := resource.ParseQuantity(scratchDisk.Size)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid size %q of scratch disk %s: %v", scratchDisk.Size, scratchDisk.Name, err)
+		}
+		bus := scratchDisk.Bus
+		if bus == "" {
+			bus = rootDiskBus
+		}
+
+		disk := buildDisk(scratchDisk.Name, bus)
+		disk.Serial = scratchDisk.Name
+		disks = append(disks, disk)
+		volumes = append(volumes, kubevirtapis.Volume{
+			Name: scratchDisk.Name,
+			VolumeSource: kubevirtapis.VolumeSource{
+				EmptyDisk: &kubevirtapis.EmptyDiskSource{Capacity: size},
+			},
+		})
+	}
+	return disks, volumes, nil
+}
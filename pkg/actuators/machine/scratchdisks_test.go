@@ -0,0 +1,105 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateScratchDisks(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		scratchDisks []kubevirtproviderv1.ScratchDisk
+		architecture kubevirtproviderv1.Architecture
+		expectError  bool
+	}{
+		{
+			testcase:     "scratch disks",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: "builds", Size: "50Gi"}, {Name: "cache", Size: "10Gi", Bus: kubevirtproviderv1.DiskBusSCSI}},
+		},
+		{
+			testcase:     "invalid name",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: "Builds", Size: "50Gi"}},
+			expectError:  true,
+		},
+		{
+			testcase:     "reserved name",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: cloudInitVolumeName, Size: "50Gi"}},
+			expectError:  true,
+		},
+		{
+			testcase:     "duplicate name",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: "builds", Size: "50Gi"}, {Name: "builds", Size: "10Gi"}},
+			expectError:  true,
+		},
+		{
+			testcase:     "invalid size",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: "builds", Size: "fifty"}},
+			expectError:  true,
+		},
+		{
+			testcase:     "zero size",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: "builds", Size: "0"}},
+			expectError:  true,
+		},
+		{
+			testcase:     "unsupported bus",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: "builds", Size: "50Gi", Bus: "ide"}},
+			expectError:  true,
+		},
+		{
+			testcase:     "arm64 sata",
+			scratchDisks: []kubevirtproviderv1.ScratchDisk{{Name: "builds", Size: "50Gi", Bus: kubevirtproviderv1.DiskBusSATA}},
+			architecture: kubevirtproviderv1.ArchitectureARM64,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateScratchDisks(&kubevirtproviderv1.KubevirtMachineProviderSpec{ScratchDisks: tc.scratchDisks, Architecture: tc.architecture})
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMScratchDisks(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		ScratchDisks:  []kubevirtproviderv1.ScratchDisk{{Name: "builds", Size: "50Gi"}, {Name: "cache", Size: "10Gi", Bus: kubevirtproviderv1.DiskBusSCSI}},
+	}
+
+	vm, _, err := buildVM(machine, providerSpec, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec := vm.Spec.Template.Spec
+	expectedBuses := map[string]string{"builds": "virtio", "cache": "scsi"}
+	for _, disk := range spec.Domain.Devices.Disks {
+		if bus, ok := expectedBuses[disk.Name]; ok {
+			if disk.Disk == nil || disk.Disk.Bus != bus || disk.Serial != disk.Name {
+				t.Errorf("Expected scratch disk %s on bus %s with its name as serial, got %v", disk.Name, bus, disk)
+			}
+			delete(expectedBuses, disk.Name)
+		}
+	}
+	if len(expectedBuses) != 0 {
+		t.Errorf("Expected scratch disks %v", expectedBuses)
+	}
+
+	capacities := map[string]string{}
+	for _, volume := range spec.Volumes {
+		if volume.EmptyDisk != nil {
+			capacities[volume.Name] = volume.EmptyDisk.Capacity.String()
+		}
+	}
+	if len(capacities) != 2 || capacities["builds"] != "50Gi" || capacities["cache"] != "10Gi" {
+		t.Errorf("Expected the emptyDisk volumes of the scratch disks, got %v", capacities)
+	}
+}
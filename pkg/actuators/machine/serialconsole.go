@@ -0,0 +1,223 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	// hookSidecarsAnnotation lists the hook sidecars KubeVirt adds to the virt-launcher pod
+	hookSidecarsAnnotation = "hooks.kubevirt.io/hookSidecars"
+
+	// serialConsoleLogKey is the key of the capture in the serial console ConfigMap
+	serialConsoleLogKey = "serial-console.log"
+
+	defaultSerialConsoleBootTimeout = 15 * time.Minute
+	defaultSerialConsoleMaxBytes    = 64 * 1024
+	maxSerialConsoleMaxBytes        = 512 * 1024
+
+	// serialConsoleReadDuration is how long the serial console is read without a sidecar
+	serialConsoleReadDuration = 10 * time.Second
+	// serialConsoleTailLines bounds the log of the sidecar fetched before it is trimmed
+	serialConsoleTailLines = 5000
+)
+
+// serialConsoleConfigMapName returns the name of the ConfigMap holding the serial console
+// capture of the machine.
+func serialConsoleConfigMapName(machineName string) string {
+	return machineName + "-serial-console"
+}
+
+func serialConsoleBootTimeout(serialConsoleLog *kubevirtproviderv1.SerialConsoleLog) time.Duration {
+	if serialConsoleLog.BootTimeout == nil {
+		return defaultSerialConsoleBootTimeout
+	}
+	return serialConsoleLog.BootTimeout.Duration
+}
+
+func serialConsoleMaxBytes(serialConsoleLog *kubevirtproviderv1.SerialConsoleLog) int {
+	if serialConsoleLog.MaxBytes == 0 {
+		return defaultSerialConsoleMaxBytes
+	}
+	return int(serialConsoleLog.MaxBytes)
+}
+
+// validateSerialConsoleLog returns an error if the boot timeout or the size of the capture are
+// out of range.
+func validateSerialConsoleLog(serialConsoleLog *kubevirtproviderv1.SerialConsoleLog) error {
+	if serialConsoleLog.BootTimeout != nil && serialConsoleLog.BootTimeout.Duration <= 0 {
+		return fmt.Errorf("bootTimeout must be positive")
+	}
+	if serialConsoleLog.MaxBytes < 0 || serialConsoleLog.MaxBytes > maxSerialConsoleMaxBytes {
+		return fmt.Errorf("maxBytes must be between 0 and %d", maxSerialConsoleMaxBytes)
+	}
+	return nil
+}
+
+// addSerialConsoleSidecar appends the serial console log sidecar to the hook sidecars of the
+// template annotations, after those of the advanced tuning annotations.
+func addSerialConsoleSidecar(annotations map[string]string, image string) error {
+	var sidecars []map[string]interface{}
+	if value, ok := annotations[hookSidecarsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &sidecars); err != nil {
+			return fmt.Errorf("invalid %s annotation: %v", hookSidecarsAnnotation, err)
+		}
+	}
+	sidecars = append(sidecars, map[string]interface{}{"image": image})
+	value, err := json.Marshal(sidecars)
+	if err != nil {
+		return err
+	}
+	annotations[hookSidecarsAnnotation] = string(value)
+	return nil
+}
+
+// serialConsoleSidecarContainer returns the name of the container of the serial console log
+// sidecar in the virt-launcher pod, empty if it is not found.
+func serialConsoleSidecarContainer(pod *corev1.Pod, image string) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Image == image {
+			return container.Name
+		}
+	}
+	return ""
+}
+
+// trimSerialConsoleLog keeps the last maxBytes of the output.
+func trimSerialConsoleLog(output []byte, maxBytes int) []byte {
+	if len(output) <= maxBytes {
+		return output
+	}
+	return output[len(output)-maxBytes:]
+}
+
+// serialConsoleCaptureDue returns true if the node of the machine did not join within the boot
+// timeout of the running VMI, whose serial console was not captured yet.
+func serialConsoleCaptureDue(machine *machinev1.Machine, serialConsoleLog *kubevirtproviderv1.SerialConsoleLog, status *kubevirtproviderv1.SerialConsoleLogStatus, vmi *kubevirtapis.VirtualMachineInstance, now time.Time) bool {
+	if machine.Status.NodeRef != nil || vmi == nil || vmi.Status.Phase != kubevirtapis.Running {
+		return false
+	}
+	if status != nil && status.VMIUID == string(vmi.UID) {
+		return false
+	}
+	return vmi.CreationTimestamp.Add(serialConsoleBootTimeout(serialConsoleLog)).Before(now)
+}
+
+// reconcileSerialConsoleLog captures the serial console of the guest into the serial console
+// ConfigMap of the machine once its node did not join within the boot timeout. A failed capture
+// is reported in the SerialConsoleCaptured condition without failing the update of the machine.
+// The serial console is captured once per VMI.
+func (r *Reconciler) reconcileSerialConsoleLog(vmi *kubevirtapis.VirtualMachineInstance, now time.Time) {
+	serialConsoleLog := r.providerSpec.SerialConsoleLog
+	if serialConsoleLog == nil || !serialConsoleCaptureDue(r.machine, serialConsoleLog, r.providerStatus.SerialConsoleLog, vmi, now) {
+		return
+	}
+
+	timeout := serialConsoleBootTimeout(serialConsoleLog)
+	output, err := r.captureSerialConsole(vmi, serialConsoleLog)
+	if err == nil {
+		err = r.storeSerialConsoleLog(trimSerialConsoleLog(output, serialConsoleMaxBytes(serialConsoleLog)))
+	}
+	if err != nil {
+		// The capture is not retried for the VMI, reading the serial console takes a while
+		r.log().Error(err, "failed to capture serial console")
+		r.providerStatus.SerialConsoleLog = &kubevirtproviderv1.SerialConsoleLogStatus{VMIUID: string(vmi.UID)}
+		r.machineScope.setProviderStatus(kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.SerialConsoleCaptured,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.SerialConsoleCaptureFailed,
+			Message: fmt.Sprintf("Node did not join within %v, the serial console could not be captured: %v", timeout, err),
+		})
+		return
+	}
+
+	name := serialConsoleConfigMapName(r.machine.Name)
+	r.log().Info("captured serial console of machine whose node did not join", "configMap", name)
+	captureTime := metav1.NewTime(now)
+	r.providerStatus.SerialConsoleLog = &kubevirtproviderv1.SerialConsoleLogStatus{
+		ConfigMap:   name,
+		VMIUID:      string(vmi.UID),
+		CaptureTime: &captureTime,
+	}
+	r.machineScope.setProviderStatus(kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.SerialConsoleCaptured,
+		Status:  corev1.ConditionTrue,
+		Reason:  kubevirtproviderv1.BootTimeoutExceeded,
+		Message: fmt.Sprintf("Node did not join within %v, the serial console was captured into ConfigMap %s", timeout, name),
+	})
+}
+
+// captureSerialConsole returns the log of the serial console log sidecar of the VMI if set,
+// or else what the serial console prints over a few seconds.
+func (r *Reconciler) captureSerialConsole(vmi *kubevirtapis.VirtualMachineInstance, serialConsoleLog *kubevirtproviderv1.SerialConsoleLog) ([]byte, error) {
+	if serialConsoleLog.SidecarImage == "" {
+		output, err := r.kubevirtClient.ReadSerialConsole(r.Context, vmi.Namespace, vmi.Name, serialConsoleReadDuration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read serial console: %w", err)
+		}
+		return output, nil
+	}
+
+	pod, err := r.getLauncherPod(vmi)
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil {
+		return nil, fmt.Errorf("virt-launcher pod of VirtualMachineInstance not found")
+	}
+	container := serialConsoleSidecarContainer(pod, serialConsoleLog.SidecarImage)
+	if container == "" {
+		return nil, fmt.Errorf("virt-launcher pod %s runs no sidecar of image %s", pod.Name, serialConsoleLog.SidecarImage)
+	}
+	tailLines := int64(serialConsoleTailLines)
+	output, err := r.kubevirtClient.GetPodLogs(r.Context, pod.Namespace, pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs of sidecar %s: %w", container, err)
+	}
+	return output, nil
+}
+
+// storeSerialConsoleLog writes the capture into the serial console ConfigMap of the machine,
+// creating it if needed.
+func (r *Reconciler) storeSerialConsoleLog(output []byte) error {
+	name := serialConsoleConfigMapName(r.machine.Name)
+	configMap := &corev1.ConfigMap{}
+	err := r.client.Get(r.Context, types.NamespacedName{Namespace: r.machine.Namespace, Name: name}, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       r.machine.Namespace,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(r.machine, machinev1.SchemeGroupVersion.WithKind("Machine"))},
+			},
+			Data: map[string]string{serialConsoleLogKey: strings.ToValidUTF8(string(output), "")},
+		}
+		if err := r.client.Create(r.Context, configMap); err != nil {
+			return fmt.Errorf("failed to create ConfigMap %s: %w", name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ConfigMap %s: %w", name, err)
+	}
+
+	configMap.Data = map[string]string{serialConsoleLogKey: strings.ToValidUTF8(string(output), "")}
+	if err := r.client.Update(r.Context, configMap); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestValidateSerialConsoleLog(t *testing.T) {
+	testCases := []struct {
+		testcase         string
+		serialConsoleLog kubevirtproviderv1.SerialConsoleLog
+		expectError      bool
+	}{
+		{
+			testcase: "defaults",
+		},
+		{
+			testcase:         "sidecar",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{SidecarImage: "quay.io/example/console-logger:v1", BootTimeout: &metav1.Duration{Duration: 5 * time.Minute}, MaxBytes: 4096},
+		},
+		{
+			testcase:         "non positive boot timeout",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{BootTimeout: &metav1.Duration{}},
+			expectError:      true,
+		},
+		{
+			testcase:         "too large",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{MaxBytes: 1024 * 1024},
+			expectError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateSerialConsoleLog(&tc.serialConsoleLog)
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMSerialConsoleSidecar(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		AdvancedTuning: &kubevirtproviderv1.AdvancedTuning{
+			Annotations: map[string]string{hookSidecarsAnnotation: `[{"image":"quay.io/example/smbios:v1"}]`},
+		},
+		SerialConsoleLog: &kubevirtproviderv1.SerialConsoleLog{SidecarImage: "quay.io/example/console-logger:v1"},
+	}
+	vm, _, err := buildVM(machine, providerSpec, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `[{"image":"quay.io/example/smbios:v1"},{"image":"quay.io/example/console-logger:v1"}]`
+	if sidecars := vm.Spec.Template.ObjectMeta.Annotations[hookSidecarsAnnotation]; sidecars != expected {
+		t.Errorf("Expected hook sidecars %s, got %s", expected, sidecars)
+	}
+}
+
+func TestReconcileSerialConsoleLog(t *testing.T) {
+	now := time.Now()
+	bootTime := metav1.NewTime(now.Add(-20 * time.Minute))
+	launcherPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "virt-launcher-worker-abcde-xyz", Namespace: "tenant-a"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "compute", Image: "quay.io/kubevirt/virt-launcher:v0.30.0"},
+			{Name: "hook-sidecar-0", Image: "quay.io/example/console-logger:v1"},
+		}},
+	}
+
+	testCases := []struct {
+		testcase         string
+		serialConsoleLog kubevirtproviderv1.SerialConsoleLog
+		nodeRef          *corev1.ObjectReference
+		creation         metav1.Time
+		captured         bool
+		captureErr       error
+		expectCapture    bool
+		expectedLog      string
+		expectedStatus   corev1.ConditionStatus
+	}{
+		{
+			testcase:         "within boot timeout",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{},
+			creation:         metav1.NewTime(now.Add(-time.Minute)),
+		},
+		{
+			testcase:         "node joined",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{},
+			nodeRef:          &corev1.ObjectReference{Name: "worker-abcde"},
+			creation:         bootTime,
+		},
+		{
+			testcase:         "already captured",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{},
+			creation:         bootTime,
+			captured:         true,
+		},
+		{
+			testcase:         "serial console",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{MaxBytes: 8},
+			creation:         bootTime,
+			expectCapture:    true,
+			expectedLog:      "failed\r\n",
+			expectedStatus:   corev1.ConditionTrue,
+		},
+		{
+			testcase:         "sidecar",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{SidecarImage: "quay.io/example/console-logger:v1"},
+			creation:         bootTime,
+			expectCapture:    true,
+			expectedLog:      "Ignition: fetch failed\r\n",
+			expectedStatus:   corev1.ConditionTrue,
+		},
+		{
+			testcase:         "failed capture",
+			serialConsoleLog: kubevirtproviderv1.SerialConsoleLog{},
+			creation:         bootTime,
+			captureErr:       fmt.Errorf("connection refused"),
+			expectCapture:    true,
+			expectedStatus:   corev1.ConditionFalse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			kubevirtClient := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.expectCapture && tc.serialConsoleLog.SidecarImage == "" {
+				kubevirtClient.EXPECT().ReadSerialConsole(gomock.Any(), "tenant-a", "worker-abcde", serialConsoleReadDuration).Return([]byte("Ignition: fetch failed\r\n"), tc.captureErr)
+			}
+			if tc.expectCapture && tc.serialConsoleLog.SidecarImage != "" {
+				kubevirtClient.EXPECT().ListPods(gomock.Any(), "tenant-a", gomock.Any()).Return(&corev1.PodList{Items: []corev1.Pod{launcherPod}}, nil)
+				kubevirtClient.EXPECT().GetPodLogs(gomock.Any(), "tenant-a", launcherPod.Name, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, namespace, name string, options *corev1.PodLogOptions) ([]byte, error) {
+						if options.Container != "hook-sidecar-0" {
+							t.Errorf("Expected the logs of the sidecar, got those of %s", options.Container)
+						}
+						return []byte("Ignition: fetch failed\r\n"), nil
+					})
+			}
+
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			client := fake.NewFakeClientWithScheme(scheme)
+			machine := &machinev1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api", UID: "5678"},
+				Status:     machinev1.MachineStatus{NodeRef: tc.nodeRef},
+			}
+			providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{}
+			if tc.captured {
+				providerStatus.SerialConsoleLog = &kubevirtproviderv1.SerialConsoleLogStatus{ConfigMap: "worker-abcde-serial-console", VMIUID: "1234"}
+			}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				client:         client,
+				kubevirtClient: kubevirtClient,
+				machine:        machine,
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{SerialConsoleLog: &tc.serialConsoleLog},
+				providerStatus: providerStatus,
+			})
+			vmi := &kubevirtapis.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", UID: "1234", CreationTimestamp: tc.creation},
+				Status:     kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running},
+			}
+
+			r.reconcileSerialConsoleLog(vmi, now)
+
+			condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.SerialConsoleCaptured)
+			if !tc.expectCapture {
+				if condition != nil {
+					t.Errorf("Expected no capture, got %v", condition)
+				}
+				return
+			}
+			if condition == nil || condition.Status != tc.expectedStatus {
+				t.Fatalf("Expected the SerialConsoleCaptured condition to be %s, got %v", tc.expectedStatus, condition)
+			}
+			if providerStatus.SerialConsoleLog == nil || providerStatus.SerialConsoleLog.VMIUID != "1234" {
+				t.Errorf("Expected the capture of VMI 1234 to be recorded, got %v", providerStatus.SerialConsoleLog)
+			}
+			if tc.expectedStatus != corev1.ConditionTrue {
+				return
+			}
+			configMap := &corev1.ConfigMap{}
+			if err := client.Get(context.Background(), types.NamespacedName{Namespace: "openshift-machine-api", Name: "worker-abcde-serial-console"}, configMap); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if log := configMap.Data[serialConsoleLogKey]; log != tc.expectedLog {
+				t.Errorf("Expected serial console log %q, got %q", tc.expectedLog, log)
+			}
+			if len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].UID != machine.UID {
+				t.Errorf("Expected the ConfigMap to be owned by the machine, got %v", configMap.OwnerReferences)
+			}
+		})
+	}
+}
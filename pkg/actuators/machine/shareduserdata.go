@@ -0,0 +1,108 @@
+package machine
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+)
+
+// validateSharedUserData returns an error if the provider spec renders UserData specific to
+// each machine, which cannot be shared.
+func validateSharedUserData(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	if providerSpec.UserDataFormat == kubevirtproviderv1.UserDataFormatIgnition {
+		return fmt.Errorf("shareUserDataSecret requires %s UserData", kubevirtproviderv1.UserDataFormatCloudInit)
+	}
+	if len(providerSpec.StaticIPAddresses) > 0 {
+		return fmt.Errorf("shareUserDataSecret cannot be combined with staticIPAddresses")
+	}
+	if len(addressPools("", providerSpec.NetworkData)) > 0 {
+		return fmt.Errorf("shareUserDataSecret cannot be combined with addressesFromPools")
+	}
+	if providerSpec.DomainSuffix != "" {
+		return fmt.Errorf("shareUserDataSecret cannot be combined with domainSuffix")
+	}
+	return nil
+}
+
+// shareUserDataSecret turns the UserData secret of a machine into the secret shared by the
+// machines of its MachineSet. The secret is named after a hash of its data, so that machines
+// created before a change of the UserData keep their secret.
+func shareUserDataSecret(secret *corev1.Secret, machine *machinev1.Machine) error {
+	machineSet, ok := machineSetKey(machine)
+	if !ok {
+		return fmt.Errorf("shareUserDataSecret requires the machine to belong to a MachineSet")
+	}
+
+	secret.Name = fmt.Sprintf("%s%s-%s", machineSet.Name, userDataSecretSuffix, secretDataHash(secret.Data))
+	secret.Labels = map[string]string{
+		machineSetLabel: machineSet.Name,
+	}
+	return nil
+}
+
+// secretDataHash returns a short hash of the secret data.
+func secretDataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%d:", key, len(data[key]))
+		hash.Write(data[key])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))[:10]
+}
+
+// ensureSharedUserDataSecret creates the shared UserData secret unless it exists. Its name
+// is derived from its data, so an existing secret holds the same data.
+func ensureSharedUserDataSecret(ctx context.Context, client kubevirtclient.Client, secret *corev1.Secret) error {
+	if _, err := client.CreateSecret(ctx, secret.Namespace, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// addSharedUserDataSecretOwner adds the VM to the owners of the shared UserData secret, so that
+// the infra cluster garbage collects the secret once the last VM using it is deleted. The
+// secret is recreated if it was collected while the VM was created.
+func addSharedUserDataSecretOwner(ctx context.Context, client kubevirtclient.Client, secret *corev1.Secret, vm *kubevirtapis.VirtualMachine) error {
+	owner := metav1.OwnerReference{
+		APIVersion: kubevirtapis.VirtualMachineGroupVersionKind.GroupVersion().String(),
+		Kind:       kubevirtapis.VirtualMachineGroupVersionKind.Kind,
+		Name:       vm.Name,
+		UID:        vm.UID,
+	}
+
+	existing, err := client.GetSecret(ctx, secret.Namespace, secret.Name, &metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret = secret.DeepCopy()
+		secret.OwnerReferences = []metav1.OwnerReference{owner}
+		_, err = client.CreateSecret(ctx, secret.Namespace, secret)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range existing.OwnerReferences {
+		if ref.UID == vm.UID {
+			return nil
+		}
+	}
+	existing.OwnerReferences = append(existing.OwnerReferences, owner)
+	_, err = client.UpdateSecret(ctx, existing.Namespace, existing)
+	return err
+}
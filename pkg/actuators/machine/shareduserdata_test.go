@@ -0,0 +1,110 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestBuildVMSharedUserData(t *testing.T) {
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:       "rhcos",
+		ShareUserDataSecret: true,
+	}
+	newMachine := func(name string) *machinev1.Machine {
+		return &machinev1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "kubevirt-test",
+				Labels:    map[string]string{machineSetLabel: "worker"},
+			},
+		}
+	}
+
+	vm, secret, err := buildVM(newMachine("worker-abcde"), providerSpec, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, otherSecret, err := buildVM(newMachine("worker-fghij"), providerSpec, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if secret.Name != otherSecret.Name {
+		t.Errorf("Expected the machines of a MachineSet to share their UserData secret, got %q and %q", secret.Name, otherSecret.Name)
+	}
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.Name == cloudInitVolumeName && volume.CloudInitNoCloud.UserDataSecretRef.Name != secret.Name {
+			t.Errorf("Expected cloud-init volume to refer to %q, got %q", secret.Name, volume.CloudInitNoCloud.UserDataSecretRef.Name)
+		}
+	}
+	if vm.Spec.Template.Spec.Hostname != "worker-abcde" {
+		t.Errorf("Expected hostname worker-abcde in the instance metadata, got %q", vm.Spec.Template.Spec.Hostname)
+	}
+
+	_, changedSecret, err := buildVM(newMachine("worker-klmno"), providerSpec, []byte("#cloud-config\npackages: [tmux]\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changedSecret.Name == secret.Name {
+		t.Errorf("Expected changed UserData to be kept in another secret")
+	}
+
+	if _, _, err := buildVM(&machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "standalone"}}, providerSpec, []byte("#cloud-config\n")); err == nil {
+		t.Errorf("Expected error for a machine without MachineSet, got nil")
+	}
+
+	withStaticIPs := providerSpec.DeepCopy()
+	withStaticIPs.StaticIPAddresses = []string{"10.0.0.10"}
+	if _, _, err := buildVM(newMachine("worker-abcde"), withStaticIPs, []byte("#cloud-config\n")); err == nil {
+		t.Errorf("Expected error for shared UserData with static IP addresses, got nil")
+	}
+}
+
+func TestAddSharedUserDataSecretOwner(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-userdata-0123456789", Namespace: "kubevirt-test"},
+	}
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", UID: "1234"},
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	client := mockkubevirt.NewMockClient(mockCtrl)
+
+	existing := secret.DeepCopy()
+	existing.OwnerReferences = []metav1.OwnerReference{{Kind: "VirtualMachine", Name: "worker-fghij", UID: "5678"}}
+	client.EXPECT().GetSecret(gomock.Any(), "kubevirt-test", secret.Name, gomock.Any()).Return(existing, nil)
+	client.EXPECT().UpdateSecret(gomock.Any(), "kubevirt-test", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, updated *corev1.Secret) (*corev1.Secret, error) {
+		if len(updated.OwnerReferences) != 2 || updated.OwnerReferences[1].UID != "1234" {
+			t.Errorf("Expected the VM to be added to the owners, got %v", updated.OwnerReferences)
+		}
+		return updated, nil
+	})
+	if err := addSharedUserDataSecretOwner(context.Background(), client, secret, vm); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// A secret collected in the meantime is recreated
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, secret.Name)
+	client.EXPECT().GetSecret(gomock.Any(), "kubevirt-test", secret.Name, gomock.Any()).Return(nil, notFound)
+	client.EXPECT().CreateSecret(gomock.Any(), "kubevirt-test", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, created *corev1.Secret) (*corev1.Secret, error) {
+		if len(created.OwnerReferences) != 1 || created.OwnerReferences[0].UID != "1234" {
+			t.Errorf("Expected the VM to own the recreated secret, got %v", created.OwnerReferences)
+		}
+		return created, nil
+	})
+	if err := addSharedUserDataSecretOwner(context.Background(), client, secret, vm); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package machine
+
+import (
+	"fmt"
+	"regexp"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+var (
+	// smbiosUUIDPattern matches UUIDs in their canonical textual form.
+	smbiosUUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// smbiosSerialPattern matches the printable ASCII strings SMBIOS serial numbers are made of.
+	smbiosSerialPattern = regexp.MustCompile(`^[\x20-\x7e]+$`)
+)
+
+// validateSMBIOS returns an error if the SMBIOS serial number is not printable ASCII or the UUID
+// is not a canonical UUID.
+func validateSMBIOS(smbios *kubevirtproviderv1.SMBIOS) error {
+	if smbios.Serial != "" && !smbiosSerialPattern.MatchString(smbios.Serial) {
+		return fmt.Errorf("smbios serial %q must be printable ASCII", smbios.Serial)
+	}
+	if smbios.UUID != "" && !smbiosUUIDPattern.MatchString(smbios.UUID) {
+		return fmt.Errorf("smbios uuid %q is not a UUID", smbios.UUID)
+	}
+	return nil
+}
+
+// applySMBIOS sets the SMBIOS serial number and UUID of the VM, defaulting to the name and UID
+// of the machine, which neither restarts nor live migrations of the VM change.
+func applySMBIOS(spec *kubevirtapis.VirtualMachineInstanceSpec, machine *machinev1.Machine, smbios *kubevirtproviderv1.SMBIOS) {
+	if spec.Domain.Firmware == nil {
+		spec.Domain.Firmware = &kubevirtapis.Firmware{}
+	}
+	spec.Domain.Firmware.Serial = smbios.Serial
+	if spec.Domain.Firmware.Serial == "" {
+		spec.Domain.Firmware.Serial = machine.Name
+	}
+	spec.Domain.Firmware.UUID = types.UID(smbios.UUID)
+	if spec.Domain.Firmware.UUID == "" {
+		spec.Domain.Firmware.UUID = machine.UID
+	}
+}
@@ -0,0 +1,81 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateSMBIOS(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		smbios      kubevirtproviderv1.SMBIOS
+		expectError bool
+	}{
+		{
+			testcase: "defaults",
+		},
+		{
+			testcase: "serial and uuid",
+			smbios:   kubevirtproviderv1.SMBIOS{Serial: "ASSET-0042", UUID: "4C4A0E5E-0E2F-4F7A-9B0B-2F3C1D5E6A7B"},
+		},
+		{
+			testcase:    "non printable serial",
+			smbios:      kubevirtproviderv1.SMBIOS{Serial: "ASSET\n0042"},
+			expectError: true,
+		},
+		{
+			testcase:    "invalid uuid",
+			smbios:      kubevirtproviderv1.SMBIOS{UUID: "4c4a0e5e0e2f4f7a9b0b2f3c1d5e6a7b"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateSMBIOS(&tc.smbios)
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMSMBIOS(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", UID: "0b5e3c8e-6f0d-4a59-8d3c-3f2a7e1b9c40"}}
+
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if firmware := vm.Spec.Template.Spec.Domain.Firmware; firmware != nil {
+		t.Errorf("Expected the firmware to be left to the KubeVirt defaults, got %v", firmware)
+	}
+
+	vm, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", SMBIOS: &kubevirtproviderv1.SMBIOS{}}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if firmware := vm.Spec.Template.Spec.Domain.Firmware; firmware.Serial != "worker-abcde" || firmware.UUID != machine.UID {
+		t.Errorf("Expected the machine name and UID as SMBIOS serial and UUID, got %q and %q", firmware.Serial, firmware.UUID)
+	}
+
+	vm, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos-aarch64",
+		Architecture:  kubevirtproviderv1.ArchitectureARM64,
+		SMBIOS:        &kubevirtproviderv1.SMBIOS{Serial: "ASSET-0042", UUID: "4c4a0e5e-0e2f-4f7a-9b0b-2f3c1d5e6a7b"},
+	}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	firmware := vm.Spec.Template.Spec.Domain.Firmware
+	if firmware.Serial != "ASSET-0042" || firmware.UUID != "4c4a0e5e-0e2f-4f7a-9b0b-2f3c1d5e6a7b" {
+		t.Errorf("Expected the SMBIOS serial and UUID of the provider spec, got %q and %q", firmware.Serial, firmware.UUID)
+	}
+	if firmware.Bootloader == nil || firmware.Bootloader.EFI == nil {
+		t.Errorf("Expected the UEFI bootloader of arm64 guests to be kept, got %v", firmware.Bootloader)
+	}
+}
@@ -0,0 +1,224 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
+)
+
+const (
+	// defaultSnapshotTTL is how long snapshots are retained when the provider spec does not say.
+	defaultSnapshotTTL = 72 * time.Hour
+	// defaultSnapshotTimeout bounds the snapshot of a VM when the provider spec does not.
+	defaultSnapshotTimeout = 30 * time.Minute
+	// snapshotPollInterval is the delay between the checks of the snapshot being taken.
+	snapshotPollInterval = 10 * time.Second
+
+	// snapshottedMachineLabel is set on the snapshots taken before the deletion of a VM to the
+	// name of its machine.
+	snapshottedMachineLabel = "kubevirtproviderconfig.openshift.io/snapshotted-machine"
+	// snapshotExpiryAnnotation is set on the snapshots taken before the deletion of a VM to the
+	// time they are deleted at, in RFC 3339 format.
+	snapshotExpiryAnnotation = "kubevirtproviderconfig.openshift.io/snapshot-expiry"
+)
+
+// validateSnapshotOnDelete returns an error if the TTL or the timeout of the snapshot is not positive.
+func validateSnapshotOnDelete(snapshotOnDelete *kubevirtproviderv1.SnapshotOnDelete) error {
+	if snapshotOnDelete.TTL != nil && snapshotOnDelete.TTL.Duration <= 0 {
+		return fmt.Errorf("snapshotOnDelete ttl must be positive, got %v", snapshotOnDelete.TTL.Duration)
+	}
+	if snapshotOnDelete.Timeout != nil && snapshotOnDelete.Timeout.Duration <= 0 {
+		return fmt.Errorf("snapshotOnDelete timeout must be positive, got %v", snapshotOnDelete.Timeout.Duration)
+	}
+	return nil
+}
+
+func snapshotTTL(snapshotOnDelete *kubevirtproviderv1.SnapshotOnDelete) time.Duration {
+	if snapshotOnDelete.TTL == nil {
+		return defaultSnapshotTTL
+	}
+	return snapshotOnDelete.TTL.Duration
+}
+
+func snapshotTimeout(snapshotOnDelete *kubevirtproviderv1.SnapshotOnDelete) time.Duration {
+	if snapshotOnDelete.Timeout == nil {
+		return defaultSnapshotTimeout
+	}
+	return snapshotOnDelete.Timeout.Duration
+}
+
+// deleteSnapshotName returns the name of the snapshot taken before the deletion of the VM,
+// unique to the VM for a machine recreated with the same name not to reuse it.
+func deleteSnapshotName(vm *kubevirtapis.VirtualMachine) string {
+	uid := string(vm.UID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	return fmt.Sprintf("%s-deleted-%s", vm.Name, uid)
+}
+
+// buildDeleteSnapshot renders the VirtualMachineSnapshot of the VM, expiring once the TTL elapsed.
+func buildDeleteSnapshot(machineName string, vm *kubevirtapis.VirtualMachine, expiry time.Time) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{
+				"apiGroup": kubevirtapis.GroupName,
+				"kind":     "VirtualMachine",
+				"name":     vm.Name,
+			},
+		},
+	}}
+	snapshot.SetAPIVersion(kubevirtclient.VirtualMachineSnapshotResource.GroupVersion().String())
+	snapshot.SetKind("VirtualMachineSnapshot")
+	snapshot.SetName(deleteSnapshotName(vm))
+	snapshot.SetNamespace(vm.Namespace)
+	snapshot.SetLabels(map[string]string{snapshottedMachineLabel: machineName})
+	snapshot.SetAnnotations(map[string]string{snapshotExpiryAnnotation: expiry.UTC().Format(time.RFC3339)})
+	return snapshot
+}
+
+// snapshotReady returns whether the snapshot is ready to use, along with the error it reports, if any.
+func snapshotReady(snapshot *unstructured.Unstructured) (bool, string) {
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	message, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+	return ready, message
+}
+
+// snapshotDone returns true if the snapshot succeeded or timed out, the VM being deleted then.
+func snapshotDone(condition *kubevirtproviderv1.KubevirtMachineProviderCondition) bool {
+	return condition != nil && (condition.Status == corev1.ConditionTrue || condition.Reason == kubevirtproviderv1.SnapshotTimedOut)
+}
+
+// snapshotTakenCondition reports the snapshot of the VM taken before its deletion.
+func snapshotTakenCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.SnapshotTaken,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// snapshotBeforeDelete stops the VM and takes a VirtualMachineSnapshot of it, returning a
+// RequeueAfterError until the snapshot is ready to use or the snapshot timeout elapsed.
+func (r *Reconciler) snapshotBeforeDelete(vm *kubevirtapis.VirtualMachine) error {
+	snapshotOnDelete := r.providerSpec.SnapshotOnDelete
+	if snapshotOnDelete == nil {
+		return nil
+	}
+	if err := validateSnapshotOnDelete(snapshotOnDelete); err != nil {
+		return machinecontroller.InvalidMachineConfiguration("%v: %v", r.machine.GetName(), err)
+	}
+	if snapshotDone(findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.SnapshotTaken)) {
+		return nil
+	}
+
+	now := time.Now()
+	timeout := snapshotTimeout(snapshotOnDelete)
+	name := deleteSnapshotName(vm)
+	if r.providerStatus.SnapshotStartTime == nil {
+		r.log().Info("snapshotting VirtualMachine before deletion", "snapshot", name, "timeout", timeout.String())
+		startTime := metav1.NewTime(now)
+		r.providerStatus.SnapshotStartTime = &startTime
+	}
+
+	ready, message, err := r.takeSnapshot(vm, snapshotOnDelete, now)
+	if err != nil {
+		return err
+	}
+	if ready {
+		r.log().Info("snapshotted VirtualMachine before deletion", "snapshot", name)
+		r.machineScope.setProviderStatus(snapshotTakenCondition(corev1.ConditionTrue, kubevirtproviderv1.SnapshotSucceeded,
+			fmt.Sprintf("VirtualMachineSnapshot %s is retained for %v", name, snapshotTTL(snapshotOnDelete))))
+		return nil
+	}
+
+	if r.providerStatus.SnapshotStartTime.Add(timeout).Before(now) {
+		r.log().Info("VirtualMachine not snapshotted within the snapshot timeout, deleting VM", "snapshot", name, "timeout", timeout.String())
+		message = fmt.Sprintf("VirtualMachineSnapshot %s not ready within %v", name, timeout)
+		r.machineScope.setProviderStatus(snapshotTakenCondition(corev1.ConditionFalse, kubevirtproviderv1.SnapshotTimedOut, message))
+		return nil
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Taking VirtualMachineSnapshot %s", name)
+	}
+	r.machineScope.setProviderStatus(snapshotTakenCondition(corev1.ConditionFalse, kubevirtproviderv1.SnapshotInProgress, message))
+	return &machinecontroller.RequeueAfterError{RequeueAfter: snapshotPollInterval}
+}
+
+// takeSnapshot stops the VM, creates its snapshot once its guest powered off and returns whether
+// the snapshot is ready to use, along with the error it reports, if any.
+func (r *Reconciler) takeSnapshot(vm *kubevirtapis.VirtualMachine, snapshotOnDelete *kubevirtproviderv1.SnapshotOnDelete, now time.Time) (bool, string, error) {
+	if !vmHalted(vm) {
+		r.log().Info("stopping VirtualMachine to snapshot it")
+		haltVM(vm)
+		if _, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, vm.Namespace, vm); err != nil {
+			return false, "", fmt.Errorf("failed to stop VirtualMachine: %w", err)
+		}
+		return false, "", nil
+	}
+
+	name := deleteSnapshotName(vm)
+	snapshot, err := r.kubevirtClient.GetVirtualMachineSnapshot(r.Context, vm.Namespace, name, &metav1.GetOptions{})
+	if err == nil {
+		ready, message := snapshotReady(snapshot)
+		return ready, message, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, "", fmt.Errorf("failed to get VirtualMachineSnapshot %s: %w", name, err)
+	}
+
+	vmi, err := r.getMachineVMI()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get VirtualMachineInstance: %w", err)
+	}
+	if !guestPoweredOff(vmi) {
+		// KubeVirt only snapshots VMs which are not running
+		return false, "", nil
+	}
+
+	snapshot = buildDeleteSnapshot(r.machine.Name, vm, now.Add(snapshotTTL(snapshotOnDelete)))
+	if err := tracing.Trace(r.Context, "CreateVirtualMachineSnapshot", func() error {
+		_, err := r.kubevirtClient.CreateVirtualMachineSnapshot(r.Context, vm.Namespace, snapshot)
+		return err
+	}, tracing.String("snapshot.name", name)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, "", fmt.Errorf("failed to create VirtualMachineSnapshot %s: %w", name, err)
+	}
+	return false, "", nil
+}
+
+// pruneExpiredSnapshots deletes the snapshots taken before the deletion of the VMs of the
+// infra namespace of the machine whose TTL elapsed. Failures are only logged, not to block the machine.
+func (r *Reconciler) pruneExpiredSnapshots(now time.Time) {
+	if r.providerSpec.SnapshotOnDelete == nil {
+		return
+	}
+	namespace := infraNamespace(r.machine, r.providerSpec)
+	snapshots, err := r.kubevirtClient.ListVirtualMachineSnapshots(r.Context, namespace, &metav1.ListOptions{LabelSelector: snapshottedMachineLabel})
+	if err != nil {
+		r.log().Error(err, "failed to list VirtualMachineSnapshots to prune")
+		return
+	}
+	for i := range snapshots.Items {
+		snapshot := &snapshots.Items[i]
+		expiry, err := time.Parse(time.RFC3339, snapshot.GetAnnotations()[snapshotExpiryAnnotation])
+		if err != nil || now.Before(expiry) {
+			continue
+		}
+		r.log().Info("deleting expired VirtualMachineSnapshot", "snapshot", snapshot.GetName(), "machine", snapshot.GetLabels()[snapshottedMachineLabel])
+		if err := r.kubevirtClient.DeleteVirtualMachineSnapshot(r.Context, namespace, snapshot.GetName(), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			r.log().Error(err, "failed to delete expired VirtualMachineSnapshot", "snapshot", snapshot.GetName())
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestValidateSnapshotOnDelete(t *testing.T) {
+	testCases := []struct {
+		testcase         string
+		snapshotOnDelete kubevirtproviderv1.SnapshotOnDelete
+		expectError      bool
+	}{
+		{
+			testcase: "defaults",
+		},
+		{
+			testcase:         "negative ttl",
+			snapshotOnDelete: kubevirtproviderv1.SnapshotOnDelete{TTL: &metav1.Duration{Duration: -time.Hour}},
+			expectError:      true,
+		},
+		{
+			testcase:         "zero timeout",
+			snapshotOnDelete: kubevirtproviderv1.SnapshotOnDelete{Timeout: &metav1.Duration{}},
+			expectError:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			if err := validateSnapshotOnDelete(&tc.snapshotOnDelete); (err != nil) != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestDeleteWithSnapshotOnDelete(t *testing.T) {
+	vmiNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "virtualmachineinstances"}, "worker-abcde")
+	snapshotNotFound := apierrors.NewNotFound(schema.GroupResource{Resource: "virtualmachinesnapshots"}, "worker-abcde-deleted-0a1b2c3d")
+	snapshot := func(ready bool) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"readyToUse": ready},
+		}}
+	}
+	labeledSnapshot := func(name string, expiry time.Time) unstructured.Unstructured {
+		snapshot := unstructured.Unstructured{}
+		snapshot.SetName(name)
+		snapshot.SetLabels(map[string]string{snapshottedMachineLabel: "worker-old"})
+		snapshot.SetAnnotations(map[string]string{snapshotExpiryAnnotation: expiry.UTC().Format(time.RFC3339)})
+		return snapshot
+	}
+	expectDeletion := func(client *mockkubevirt.MockClient) {
+		client.EXPECT().DeleteVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil)
+		client.EXPECT().DeleteSecret(gomock.Any(), "tenant-a", "worker-abcde"+userDataSecretSuffix, gomock.Any()).Return(nil)
+		client.EXPECT().ListVirtualMachineSnapshots(gomock.Any(), "tenant-a", gomock.Any()).Return(&unstructured.UnstructuredList{
+			Items: []unstructured.Unstructured{
+				labeledSnapshot("worker-old-deleted-expired", time.Now().Add(-time.Minute)),
+				labeledSnapshot("worker-old-deleted-retained", time.Now().Add(time.Hour)),
+			},
+		}, nil)
+		client.EXPECT().DeleteVirtualMachineSnapshot(gomock.Any(), "tenant-a", "worker-old-deleted-expired", gomock.Any()).Return(nil)
+	}
+
+	testCases := []struct {
+		testcase      string
+		startTime     *metav1.Time
+		halted        bool
+		expectClient  func(client *mockkubevirt.MockClient)
+		expectRequeue bool
+		expectReason  kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase: "stops the VM",
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+					if !vmHalted(vm) {
+						t.Errorf("Expected the VM to be halted, got run strategy %v", vm.Spec.RunStrategy)
+					}
+					return vm, nil
+				})
+			},
+			expectRequeue: true,
+			expectReason:  kubevirtproviderv1.SnapshotInProgress,
+		},
+		{
+			testcase: "snapshots the VM once the guest powered off",
+			halted:   true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineSnapshot(gomock.Any(), "tenant-a", "worker-abcde-deleted-0a1b2c3d", gomock.Any()).Return(nil, snapshotNotFound)
+				client.EXPECT().GetVirtualMachineInstance(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(nil, vmiNotFound)
+				client.EXPECT().CreateVirtualMachineSnapshot(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, snapshot *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+					if source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "name"); source != "worker-abcde" {
+						t.Errorf("Expected a snapshot of worker-abcde, got %q", source)
+					}
+					if snapshot.GetLabels()[snapshottedMachineLabel] != "worker-abcde" {
+						t.Errorf("Expected the snapshotted machine label, got %v", snapshot.GetLabels())
+					}
+					expiry, err := time.Parse(time.RFC3339, snapshot.GetAnnotations()[snapshotExpiryAnnotation])
+					if err != nil || expiry.Before(time.Now().Add(47*time.Hour)) {
+						t.Errorf("Expected the snapshot to expire after the TTL, got %v", snapshot.GetAnnotations())
+					}
+					return snapshot, nil
+				})
+			},
+			expectRequeue: true,
+			expectReason:  kubevirtproviderv1.SnapshotInProgress,
+		},
+		{
+			testcase:  "deletes once the snapshot is ready",
+			startTime: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			halted:    true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineSnapshot(gomock.Any(), "tenant-a", "worker-abcde-deleted-0a1b2c3d", gomock.Any()).Return(snapshot(true), nil)
+				expectDeletion(client)
+			},
+			expectReason: kubevirtproviderv1.SnapshotSucceeded,
+		},
+		{
+			testcase:  "deletes after the timeout",
+			startTime: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			halted:    true,
+			expectClient: func(client *mockkubevirt.MockClient) {
+				client.EXPECT().GetVirtualMachineSnapshot(gomock.Any(), "tenant-a", "worker-abcde-deleted-0a1b2c3d", gomock.Any()).Return(snapshot(false), nil)
+				expectDeletion(client)
+			},
+			expectReason: kubevirtproviderv1.SnapshotTimedOut,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			always := kubevirtapis.RunStrategyAlways
+			vm := &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", UID: "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"},
+				Spec:       kubevirtapis.VirtualMachineSpec{RunStrategy: &always},
+			}
+			if tc.halted {
+				haltVM(vm)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			client.EXPECT().GetVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde", gomock.Any()).Return(vm, nil)
+			tc.expectClient(client)
+
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}},
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					SnapshotOnDelete: &kubevirtproviderv1.SnapshotOnDelete{
+						TTL:     &metav1.Duration{Duration: 48 * time.Hour},
+						Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+					},
+				},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{SnapshotStartTime: tc.startTime},
+			})
+
+			err := r.delete()
+			_, requeue := err.(*machinecontroller.RequeueAfterError)
+			if tc.expectRequeue != requeue {
+				t.Fatalf("Expected requeue %v, got error %v", tc.expectRequeue, err)
+			}
+			if !tc.expectRequeue && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if r.providerStatus.SnapshotStartTime == nil {
+				t.Error("Expected the snapshot start time to be set")
+			}
+			condition := findProviderCondition(r.providerStatus.Conditions, kubevirtproviderv1.SnapshotTaken)
+			if condition == nil || condition.Reason != tc.expectReason {
+				t.Errorf("Expected reason %v, got condition %v", tc.expectReason, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package machine
+
+import (
+	"context"
+	"time"
+)
+
+// OperationTimeouts bound the machine operations, the infra requests of an operation being
+// cancelled once its timeout elapsed. A zero timeout leaves the operation unbounded, only the
+// deadline of the context it is invoked with applies then.
+type OperationTimeouts struct {
+	Create time.Duration
+	Exists time.Duration
+	Update time.Duration
+	Delete time.Duration
+}
+
+// timeout returns the timeout of the operation.
+func (t OperationTimeouts) timeout(operation string) time.Duration {
+	switch operation {
+	case "Create":
+		return t.Create
+	case "Exists":
+		return t.Exists
+	case "Update":
+		return t.Update
+	case "Delete":
+		return t.Delete
+	}
+	return 0
+}
+
+// withOperationTimeout returns the context of the operation, cancelled once its timeout
+// elapsed. The cancel function must be called when the operation returns.
+func (a *Actuator) withOperationTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	timeout := a.operationTimeouts.timeout(operation)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
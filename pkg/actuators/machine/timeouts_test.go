@@ -0,0 +1,27 @@
+package machine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithOperationTimeout(t *testing.T) {
+	a := &Actuator{operationTimeouts: OperationTimeouts{Create: time.Minute}}
+
+	ctx, cancel := a.withOperationTimeout(context.Background(), "Create")
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("Expected a deadline within a minute, got %v (set %v)", deadline, ok)
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("Expected the context to be cancelled, got %v", ctx.Err())
+	}
+
+	ctx, cancel = a.withOperationTimeout(context.Background(), "Delete")
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline without a Delete timeout")
+	}
+}
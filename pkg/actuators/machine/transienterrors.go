@@ -0,0 +1,123 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// conflictRequeue is the delay before an operation failed on a stale infra object is
+	// retried against its latest version.
+	conflictRequeue = 5 * time.Second
+	// unavailableRequeue is the delay before an operation throttled by the infra API server, or
+	// failed on its unavailability, is retried when the server did not suggest one.
+	unavailableRequeue = 30 * time.Second
+	// quotaRequeue is the delay before an operation refused by an infra resource quota is
+	// retried, for other VMs of the namespace to release their share in the meantime.
+	quotaRequeue = 2 * time.Minute
+	// storageNotBoundRequeue is the delay before an operation waiting for a
+	// PersistentVolumeClaim to be bound is retried.
+	storageNotBoundRequeue = 15 * time.Second
+)
+
+// storageNotBoundError is returned while a PersistentVolumeClaim an operation depends on is
+// not bound to a volume yet.
+type storageNotBoundError struct {
+	claim string
+	phase corev1.PersistentVolumeClaimPhase
+}
+
+func (e *storageNotBoundError) Error() string {
+	return fmt.Sprintf("PersistentVolumeClaim %s is %s, not bound yet", e.claim, e.phase)
+}
+
+// transientRequeue returns the delay after which an operation failed with err is retried, and
+// false if err is not known to be transient.
+func transientRequeue(err error) (time.Duration, bool) {
+	var notBound *storageNotBoundError
+	if errors.As(err, &notBound) {
+		return storageNotBoundRequeue, true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// The operation timed out, e.g. on an unresponsive infra API server
+		return unavailableRequeue, true
+	}
+
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) {
+		return 0, false
+	}
+	if seconds, ok := apierrors.SuggestsClientDelay(statusErr); ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	switch {
+	case apierrors.IsConflict(statusErr):
+		return conflictRequeue, true
+	case apierrors.IsTooManyRequests(statusErr), apierrors.IsServerTimeout(statusErr), apierrors.IsTimeout(statusErr), apierrors.IsServiceUnavailable(statusErr):
+		return unavailableRequeue, true
+	case apierrors.IsForbidden(statusErr) && strings.Contains(statusErr.ErrStatus.Message, "exceeded quota"):
+		return quotaRequeue, true
+	}
+	return 0, false
+}
+
+// transientError is an error the operation is retried after, which the machine controller
+// finds a RequeueAfterError in to back off on while its message remains the original one.
+type transientError struct {
+	err          error
+	requeueAfter time.Duration
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// As lets errors.As find the RequeueAfterError of the transient error.
+func (e *transientError) As(target interface{}) bool {
+	if requeue, ok := target.(**machinecontroller.RequeueAfterError); ok {
+		*requeue = &machinecontroller.RequeueAfterError{RequeueAfter: e.requeueAfter}
+		return true
+	}
+	return false
+}
+
+// requeueIfTransient marks transient infra errors to be retried after the delay suiting them,
+// instead of right away and then with the exponential backoff of the controller. Other errors
+// are returned as is.
+func requeueIfTransient(err error) error {
+	var requeue *machinecontroller.RequeueAfterError
+	if errors.As(err, &requeue) {
+		return err
+	}
+	if delay, ok := transientRequeue(err); ok {
+		return &transientError{err: err, requeueAfter: delay}
+	}
+	return err
+}
+
+// isTransient returns true if err was marked transient.
+func isTransient(err error) bool {
+	var transient *transientError
+	return errors.As(err, &transient)
+}
+
+// createMachineError returns the CreateMachine error formatted from the infra error, marked
+// transient if the infra error is, as the CreateMachine error does not wrap it.
+func createMachineError(err error, format string, args ...interface{}) error {
+	createErr := machinecontroller.CreateMachine(format, args...)
+	if delay, ok := transientRequeue(err); ok {
+		return &transientError{err: createErr, requeueAfter: delay}
+	}
+	return createErr
+}
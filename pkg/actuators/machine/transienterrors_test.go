@@ -0,0 +1,124 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTransientRequeue(t *testing.T) {
+	virtualMachines := schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachines"}
+
+	testCases := []struct {
+		testcase      string
+		err           error
+		expectRequeue time.Duration
+	}{
+		{
+			testcase:      "conflict",
+			err:           fmt.Errorf("failed to update VirtualMachine: %w", apierrors.NewConflict(virtualMachines, "worker-abcde", errors.New("the object has been modified"))),
+			expectRequeue: conflictRequeue,
+		},
+		{
+			testcase:      "throttled with a suggested delay",
+			err:           apierrors.NewTooManyRequests("too many requests", 7),
+			expectRequeue: 7 * time.Second,
+		},
+		{
+			testcase:      "unavailable",
+			err:           apierrors.NewServiceUnavailable("etcd leader changed"),
+			expectRequeue: unavailableRequeue,
+		},
+		{
+			testcase:      "quota exceeded",
+			err:           apierrors.NewForbidden(virtualMachines, "worker-abcde", errors.New("exceeded quota: compute, requested: requests.memory=8Gi")),
+			expectRequeue: quotaRequeue,
+		},
+		{
+			testcase:      "storage not bound",
+			err:           fmt.Errorf("cannot export: %w", &storageNotBoundError{claim: "worker-abcde-boot", phase: corev1.ClaimPending}),
+			expectRequeue: storageNotBoundRequeue,
+		},
+		{
+			testcase:      "operation timed out",
+			err:           fmt.Errorf("failed to get VirtualMachine: %w", context.DeadlineExceeded),
+			expectRequeue: unavailableRequeue,
+		},
+		{
+			testcase: "forbidden",
+			err:      apierrors.NewForbidden(virtualMachines, "worker-abcde", errors.New("not allowed")),
+		},
+		{
+			testcase: "invalid",
+			err:      apierrors.NewBadRequest("spec.template.spec.domain.resources: invalid memory"),
+		},
+		{
+			testcase: "not an API error",
+			err:      errors.New("failed to render user data"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			requeue, transient := transientRequeue(tc.err)
+			if transient != (tc.expectRequeue != 0) || requeue != tc.expectRequeue {
+				t.Errorf("Expected requeue after %v, got %v (transient %v)", tc.expectRequeue, requeue, transient)
+			}
+		})
+	}
+}
+
+func TestRequeueIfTransient(t *testing.T) {
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "virtualmachines"}, "worker-abcde", errors.New("the object has been modified"))
+
+	err := requeueIfTransient(fmt.Errorf("failed to update VirtualMachine: %w", conflict))
+	var requeue *machinecontroller.RequeueAfterError
+	if !errors.As(err, &requeue) || requeue.RequeueAfter != conflictRequeue {
+		t.Fatalf("Expected a requeue after %v, got %v", conflictRequeue, err)
+	}
+	if !apierrors.IsConflict(errors.Unwrap(errors.Unwrap(err))) {
+		t.Errorf("Expected the conflict to remain wrapped, got %v", err)
+	}
+	if expected := "failed to update VirtualMachine: " + conflict.Error(); err.Error() != expected {
+		t.Errorf("Expected message %q, got %q", expected, err.Error())
+	}
+
+	original := &machinecontroller.RequeueAfterError{RequeueAfter: time.Minute}
+	if err := requeueIfTransient(original); err != original {
+		t.Errorf("Expected the RequeueAfterError as is, got %v", err)
+	}
+	fatal := errors.New("failed to render user data")
+	if err := requeueIfTransient(fatal); err != fatal {
+		t.Errorf("Expected the error as is, got %v", err)
+	}
+}
+
+func TestCreateMachineError(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("etcd leader changed")
+	err := createMachineError(unavailable, "error creating VirtualMachine: %v", unavailable)
+	var requeue *machinecontroller.RequeueAfterError
+	if !errors.As(err, &requeue) || requeue.RequeueAfter != unavailableRequeue {
+		t.Errorf("Expected a requeue after %v, got %v", unavailableRequeue, err)
+	}
+	if !isTransient(fmt.Errorf("failed to create VirtualMachine: %w", err)) {
+		t.Error("Expected the error to be transient")
+	}
+
+	invalid := apierrors.NewBadRequest("invalid memory")
+	err = createMachineError(invalid, "error creating VirtualMachine: %v", invalid)
+	var machineErr *machinecontroller.MachineError
+	if !errors.As(err, &machineErr) || machineErr.Reason != machinev1.CreateMachineError {
+		t.Errorf("Expected a CreateMachine error, got %v", err)
+	}
+	if isTransient(err) {
+		t.Error("Expected the error not to be transient")
+	}
+}
@@ -0,0 +1,215 @@
+package machine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// templateField is a field of the VMI template the update policy compares between the VM
+// rendered out of the provider spec and the live VM. Fields other reconcilers change on the
+// live VM, like the volumes and the node selector of cold migrations, are left out.
+type templateField struct {
+	name string
+	// restartable fields apply to the VM from its next restart, the others require the
+	// machine to be replaced.
+	restartable bool
+	value       func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{}
+	apply       func(live, desired *kubevirtapis.VirtualMachineInstanceSpec)
+}
+
+var templateFields = []templateField{
+	{
+		name:        "cpu",
+		restartable: true,
+		value:       func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} { return spec.Domain.CPU },
+		apply: func(live, desired *kubevirtapis.VirtualMachineInstanceSpec) {
+			live.Domain.CPU = desired.Domain.CPU
+		},
+	},
+	{
+		name:        "memory",
+		restartable: true,
+		value:       func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} { return spec.Domain.Memory },
+		apply: func(live, desired *kubevirtapis.VirtualMachineInstanceSpec) {
+			live.Domain.Memory = desired.Domain.Memory
+		},
+	},
+	{
+		name:        "resources",
+		restartable: true,
+		value:       func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} { return spec.Domain.Resources },
+		apply: func(live, desired *kubevirtapis.VirtualMachineInstanceSpec) {
+			live.Domain.Resources = desired.Domain.Resources
+		},
+	},
+	{
+		name:        "tolerations",
+		restartable: true,
+		value:       func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} { return spec.Tolerations },
+		apply: func(live, desired *kubevirtapis.VirtualMachineInstanceSpec) {
+			live.Tolerations = desired.Tolerations
+		},
+	},
+	{
+		name:        "priorityClassName",
+		restartable: true,
+		value:       func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} { return spec.PriorityClassName },
+		apply: func(live, desired *kubevirtapis.VirtualMachineInstanceSpec) {
+			live.PriorityClassName = desired.PriorityClassName
+		},
+	},
+	{
+		name:        "evictionStrategy",
+		restartable: true,
+		value:       func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} { return spec.EvictionStrategy },
+		apply: func(live, desired *kubevirtapis.VirtualMachineInstanceSpec) {
+			live.EvictionStrategy = desired.EvictionStrategy
+		},
+	},
+	{
+		name:        "terminationGracePeriodSeconds",
+		restartable: true,
+		value: func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} {
+			return spec.TerminationGracePeriodSeconds
+		},
+		apply: func(live, desired *kubevirtapis.VirtualMachineInstanceSpec) {
+			live.TerminationGracePeriodSeconds = desired.TerminationGracePeriodSeconds
+		},
+	},
+	{
+		// The guest sees its root disk on another bus or queue layout, which it may not boot from
+		name:  "rootDisk",
+		value: func(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} { return rootDiskLayout(spec) },
+	},
+}
+
+// rootDiskLayout returns the device of the root disk along with the block multi-queue setting.
+func rootDiskLayout(spec *kubevirtapis.VirtualMachineInstanceSpec) interface{} {
+	layout := struct {
+		Disk            *kubevirtapis.Disk
+		BlockMultiQueue *bool
+	}{BlockMultiQueue: spec.Domain.Devices.BlockMultiQueue}
+	for i := range spec.Domain.Devices.Disks {
+		if spec.Domain.Devices.Disks[i].Name == mainDiskName {
+			layout.Disk = &spec.Domain.Devices.Disks[i]
+		}
+	}
+	return layout
+}
+
+// driftedTemplateFields returns the fields of the live VMI template which differ from the
+// desired one. The fields are compared serialized, the live VM lost the empty values the
+// rendered one may hold on its way through the API.
+func driftedTemplateFields(live, desired *kubevirtapis.VirtualMachineInstanceSpec) ([]templateField, error) {
+	var drifted []templateField
+	for _, field := range templateFields {
+		liveValue, err := json.Marshal(field.value(live))
+		if err != nil {
+			return nil, err
+		}
+		desiredValue, err := json.Marshal(field.value(desired))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(liveValue, desiredValue) {
+			drifted = append(drifted, field)
+		}
+	}
+	return drifted, nil
+}
+
+// templateFieldNames returns the comma separated names of the fields.
+func templateFieldNames(fields []templateField) string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// validateUpdatePolicy returns an error if the update policy is unsupported.
+func validateUpdatePolicy(policy kubevirtproviderv1.UpdatePolicy) error {
+	switch policy {
+	case "", kubevirtproviderv1.UpdatePolicyInPlace, kubevirtproviderv1.UpdatePolicyRestart, kubevirtproviderv1.UpdatePolicyRecreate:
+		return nil
+	}
+	return fmt.Errorf("unsupported updatePolicy %q, must be one of %q, %q or %q", policy,
+		kubevirtproviderv1.UpdatePolicyInPlace, kubevirtproviderv1.UpdatePolicyRestart, kubevirtproviderv1.UpdatePolicyRecreate)
+}
+
+// vmUpToDateCondition reports whether the VM runs the current provider spec.
+func vmUpToDateCondition(status corev1.ConditionStatus, reason kubevirtproviderv1.KubevirtMachineProviderConditionReason, message string) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.VMUpToDate,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// reconcileUpdatePolicy applies the update policy to the fields of the VMI template drifted
+// from the desired VM. An updated VM is reported pending until a VMI created after the update
+// runs.
+func (r *Reconciler) reconcileUpdatePolicy(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance, desired *kubevirtapis.VirtualMachine, drifted []templateField, now time.Time) (*kubevirtapis.VirtualMachine, error) {
+	if len(drifted) == 0 {
+		updateTime := r.providerStatus.SpecUpdateTime
+		// The provider status only keeps the update time to the second
+		if updateTime != nil && (vmi == nil || vmi.Status.Phase != kubevirtapis.Running || vmi.CreationTimestamp.Time.Before(updateTime.Time.Truncate(time.Second))) {
+			return vm, nil
+		}
+		r.providerStatus.SpecUpdateTime = nil
+		r.machineScope.setProviderStatus(vmUpToDateCondition(corev1.ConditionTrue, kubevirtproviderv1.VMMatchesSpec, "VirtualMachine runs the provider spec"))
+		return vm, nil
+	}
+
+	policy := r.providerSpec.UpdatePolicy
+	if policy == "" {
+		policy = kubevirtproviderv1.UpdatePolicyRecreate
+	}
+	var replaceOnly []templateField
+	for _, field := range drifted {
+		if !field.restartable {
+			replaceOnly = append(replaceOnly, field)
+		}
+	}
+	if policy == kubevirtproviderv1.UpdatePolicyRecreate || len(replaceOnly) > 0 {
+		message := fmt.Sprintf("Provider spec drifted from VirtualMachine in %s, the machine has to be replaced", templateFieldNames(drifted))
+		if policy != kubevirtproviderv1.UpdatePolicyRecreate {
+			message = fmt.Sprintf("Provider spec drifted from VirtualMachine in %s, which cannot be updated, the machine has to be replaced", templateFieldNames(replaceOnly))
+		}
+		r.machineScope.setProviderStatus(vmUpToDateCondition(corev1.ConditionFalse, kubevirtproviderv1.ReplacementRequired, message))
+		return vm, nil
+	}
+
+	r.log().Info("updating VirtualMachine to the drifted provider spec", "fields", templateFieldNames(drifted), "updatePolicy", policy)
+	updated := vm.DeepCopy()
+	for _, field := range drifted {
+		field.apply(&updated.Spec.Template.Spec, &desired.Spec.Template.Spec)
+	}
+	updatedVM, err := r.kubevirtClient.UpdateVirtualMachine(r.Context, updated.Namespace, updated)
+	if err != nil {
+		return vm, fmt.Errorf("failed to update VirtualMachine to the provider spec: %w", err)
+	}
+	r.providerStatus.SpecUpdateTime = &metav1.Time{Time: now}
+
+	if policy == kubevirtproviderv1.UpdatePolicyRestart && vmi != nil {
+		if err := r.kubevirtClient.RestartVirtualMachine(r.Context, vm.Namespace, vm.Name); err != nil {
+			return updatedVM, fmt.Errorf("failed to restart VirtualMachine: %w", err)
+		}
+		r.machineScope.setProviderStatus(vmUpToDateCondition(corev1.ConditionFalse, kubevirtproviderv1.UpdateRestartedVM,
+			fmt.Sprintf("VirtualMachine updated in %s and restarted", templateFieldNames(drifted))))
+		return updatedVM, nil
+	}
+	r.machineScope.setProviderStatus(vmUpToDateCondition(corev1.ConditionFalse, kubevirtproviderv1.UpdatePendingRestart,
+		fmt.Sprintf("VirtualMachine updated in %s, which applies from its next restart", templateFieldNames(drifted))))
+	return updatedVM, nil
+}
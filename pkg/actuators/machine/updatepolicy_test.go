@@ -0,0 +1,171 @@
+package machine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+	mockkubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/mock"
+)
+
+func TestReconcileUpdatePolicy(t *testing.T) {
+	now := time.Now()
+	updateTime := metav1.NewTime(now.Add(-time.Minute).Truncate(time.Second))
+
+	testCases := []struct {
+		testcase         string
+		updatePolicy     kubevirtproviderv1.UpdatePolicy
+		liveCPU          uint32
+		liveDiskBus      kubevirtproviderv1.DiskBus
+		updateTime       *metav1.Time
+		vmiCreated       time.Time
+		expectUpdate     bool
+		expectRestart    bool
+		expectUpdateTime bool
+		expectReason     kubevirtproviderv1.KubevirtMachineProviderConditionReason
+	}{
+		{
+			testcase:     "up to date",
+			liveCPU:      4,
+			vmiCreated:   now.Add(-time.Hour),
+			expectReason: kubevirtproviderv1.VMMatchesSpec,
+		},
+		{
+			testcase:     "drifted with the Recreate policy",
+			liveCPU:      2,
+			vmiCreated:   now.Add(-time.Hour),
+			expectReason: kubevirtproviderv1.ReplacementRequired,
+		},
+		{
+			testcase:         "updated in place",
+			updatePolicy:     kubevirtproviderv1.UpdatePolicyInPlace,
+			liveCPU:          2,
+			vmiCreated:       now.Add(-time.Hour),
+			expectUpdate:     true,
+			expectUpdateTime: true,
+			expectReason:     kubevirtproviderv1.UpdatePendingRestart,
+		},
+		{
+			testcase:         "updated and restarted",
+			updatePolicy:     kubevirtproviderv1.UpdatePolicyRestart,
+			liveCPU:          2,
+			vmiCreated:       now.Add(-time.Hour),
+			expectUpdate:     true,
+			expectRestart:    true,
+			expectUpdateTime: true,
+			expectReason:     kubevirtproviderv1.UpdateRestartedVM,
+		},
+		{
+			testcase:     "root disk drifted",
+			updatePolicy: kubevirtproviderv1.UpdatePolicyRestart,
+			liveCPU:      4,
+			liveDiskBus:  kubevirtproviderv1.DiskBusSCSI,
+			vmiCreated:   now.Add(-time.Hour),
+			expectReason: kubevirtproviderv1.ReplacementRequired,
+		},
+		{
+			testcase:         "waits for the restart",
+			updatePolicy:     kubevirtproviderv1.UpdatePolicyInPlace,
+			liveCPU:          4,
+			updateTime:       &updateTime,
+			vmiCreated:       now.Add(-time.Hour),
+			expectUpdateTime: true,
+			expectReason:     kubevirtproviderv1.UpdatePendingRestart,
+		},
+		{
+			testcase:     "restarted since the update",
+			updatePolicy: kubevirtproviderv1.UpdatePolicyInPlace,
+			liveCPU:      4,
+			updateTime:   &updateTime,
+			vmiCreated:   updateTime.Time,
+			expectReason: kubevirtproviderv1.VMMatchesSpec,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+			liveSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: tc.liveCPU, DiskBus: tc.liveDiskBus}
+			vm, _, err := buildVM(machine, liveSpec, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			client := mockkubevirt.NewMockClient(mockCtrl)
+			if tc.expectUpdate {
+				client.EXPECT().UpdateVirtualMachine(gomock.Any(), "tenant-a", gomock.Any()).DoAndReturn(func(_ context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+					if cores := vm.Spec.Template.Spec.Domain.CPU.Cores; cores != 4 {
+						t.Errorf("Expected the VM to be updated to 4 cores, got %d", cores)
+					}
+					return vm, nil
+				})
+			}
+			if tc.expectRestart {
+				client.EXPECT().RestartVirtualMachine(gomock.Any(), "tenant-a", "worker-abcde").Return(nil)
+			}
+
+			providerStatus := &kubevirtproviderv1.KubevirtMachineProviderStatus{SpecUpdateTime: tc.updateTime}
+			if tc.updateTime != nil {
+				providerStatus.Conditions = []kubevirtproviderv1.KubevirtMachineProviderCondition{vmUpToDateCondition(corev1.ConditionFalse, kubevirtproviderv1.UpdatePendingRestart, "")}
+			}
+			r := newReconciler(&machineScope{
+				Context:        context.Background(),
+				kubevirtClient: client,
+				machine:        machine,
+				providerSpec:   &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: 4, UpdatePolicy: tc.updatePolicy},
+				providerStatus: providerStatus,
+			})
+			vmi := &kubevirtapis.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", CreationTimestamp: metav1.NewTime(tc.vmiCreated)},
+				Status:     kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running},
+			}
+
+			if _, err := r.reconcileSpecDrift(vm, vmi, now); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if (providerStatus.SpecUpdateTime != nil) != tc.expectUpdateTime {
+				t.Errorf("Expected a spec update time %v, got %v", tc.expectUpdateTime, providerStatus.SpecUpdateTime)
+			}
+			condition := findProviderCondition(providerStatus.Conditions, kubevirtproviderv1.VMUpToDate)
+			if condition == nil || condition.Reason != tc.expectReason {
+				t.Errorf("Expected reason %v, got condition %v", tc.expectReason, condition)
+			}
+		})
+	}
+}
+
+func TestRecordUpdateDecision(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	status := func(reason kubevirtproviderv1.KubevirtMachineProviderConditionReason) *kubevirtproviderv1.KubevirtMachineProviderStatus {
+		return &kubevirtproviderv1.KubevirtMachineProviderStatus{
+			Conditions: []kubevirtproviderv1.KubevirtMachineProviderCondition{vmUpToDateCondition(corev1.ConditionFalse, reason, "drifted")},
+		}
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	a := NewActuator(ActuatorParams{EventRecorder: recorder})
+	a.recordUpdateDecision(machine, "", status(kubevirtproviderv1.VMMatchesSpec))
+	a.recordUpdateDecision(machine, kubevirtproviderv1.ReplacementRequired, status(kubevirtproviderv1.ReplacementRequired))
+	if events := drainEvents(recorder); len(events) != 0 {
+		t.Errorf("Expected no event without a new decision, got %v", events)
+	}
+
+	a.recordUpdateDecision(machine, kubevirtproviderv1.VMMatchesSpec, status(kubevirtproviderv1.ReplacementRequired))
+	a.recordUpdateDecision(machine, kubevirtproviderv1.UpdatePendingRestart, status(kubevirtproviderv1.VMMatchesSpec))
+	events := drainEvents(recorder)
+	if len(events) != 2 || !strings.HasPrefix(events[0], "Warning ReplacementRequired") || !strings.HasPrefix(events[1], "Normal VMMatchesSpec") {
+		t.Errorf("Expected the update decisions to be recorded, got %v", events)
+	}
+}
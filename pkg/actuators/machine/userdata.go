@@ -0,0 +1,144 @@
+package machine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	cloudConfigHeader = "#cloud-config"
+	// ignitionSSHUser is the user RHCOS and FCOS guests grant SSH access to.
+	ignitionSSHUser = "core"
+)
+
+// editCloudConfig applies edit to the #cloud-config user data and renders it back.
+// Empty user data is handled as an empty #cloud-config document.
+func editCloudConfig(userData []byte, edit func(cloudConfig map[string]interface{})) ([]byte, error) {
+	if len(bytes.TrimSpace(userData)) > 0 && !bytes.HasPrefix(userData, []byte(cloudConfigHeader)) {
+		return nil, fmt.Errorf("only %s or Ignition user data can be extended", cloudConfigHeader)
+	}
+
+	cloudConfig := map[string]interface{}{}
+	if err := yaml.Unmarshal(userData, &cloudConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-config user data: %v", err)
+	}
+
+	edit(cloudConfig)
+
+	rendered, err := yaml.Marshal(cloudConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render cloud-config user data: %v", err)
+	}
+
+	return append([]byte(cloudConfigHeader+"\n"), rendered...), nil
+}
+
+// editIgnitionConfig applies edit to the Ignition user data and renders it back.
+// The Ignition spec version is handed to edit, the schema differs between 2.x and 3.x.
+func editIgnitionConfig(userData []byte, edit func(ignitionConfig map[string]interface{}, version string)) ([]byte, error) {
+	ignitionConfig := map[string]interface{}{}
+	if err := json.Unmarshal(userData, &ignitionConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse Ignition user data: %v", err)
+	}
+
+	ignition, _ := ignitionConfig["ignition"].(map[string]interface{})
+	version, _ := ignition["version"].(string)
+
+	edit(ignitionConfig, version)
+
+	rendered, err := json.Marshal(ignitionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render Ignition user data: %v", err)
+	}
+
+	return rendered, nil
+}
+
+// ignitionSection returns the named object of the Ignition config, creating it if needed.
+func ignitionSection(ignitionConfig map[string]interface{}, name string) map[string]interface{} {
+	section, _ := ignitionConfig[name].(map[string]interface{})
+	if section == nil {
+		section = map[string]interface{}{}
+		ignitionConfig[name] = section
+	}
+	return section
+}
+
+// appendIgnitionFile adds a file with mode 0644 to the storage section of the Ignition config.
+func appendIgnitionFile(ignitionConfig map[string]interface{}, version, path, contents string) {
+	file := map[string]interface{}{
+		"path": path,
+		"mode": 420,
+		"contents": map[string]interface{}{
+			"source": "data:," + url.PathEscape(contents),
+		},
+	}
+	if strings.HasPrefix(version, "2.") {
+		file["filesystem"] = "root"
+	} else {
+		file["overwrite"] = true
+	}
+
+	storage := ignitionSection(ignitionConfig, "storage")
+	files, _ := storage["files"].([]interface{})
+	storage["files"] = append(files, file)
+}
+
+// injectSSHKeys authorizes the SSH public keys on the guest, through ssh_authorized_keys for
+// cloud-init or the core user for Ignition.
+func injectSSHKeys(userData []byte, format kubevirtproviderv1.UserDataFormat, keys []string) ([]byte, error) {
+	if len(keys) == 0 {
+		return userData, nil
+	}
+
+	if format == kubevirtproviderv1.UserDataFormatIgnition {
+		return editIgnitionConfig(userData, func(ignitionConfig map[string]interface{}, _ string) {
+			passwd := ignitionSection(ignitionConfig, "passwd")
+			users, _ := passwd["users"].([]interface{})
+			for _, u := range users {
+				if user, ok := u.(map[string]interface{}); ok && user["name"] == ignitionSSHUser {
+					authorizedKeys, _ := user["sshAuthorizedKeys"].([]interface{})
+					user["sshAuthorizedKeys"] = appendStrings(authorizedKeys, keys)
+					return
+				}
+			}
+			passwd["users"] = append(users, map[string]interface{}{
+				"name":              ignitionSSHUser,
+				"sshAuthorizedKeys": appendStrings(nil, keys),
+			})
+		})
+	}
+
+	return editCloudConfig(userData, func(cloudConfig map[string]interface{}) {
+		authorizedKeys, _ := cloudConfig["ssh_authorized_keys"].([]interface{})
+		cloudConfig["ssh_authorized_keys"] = appendStrings(authorizedKeys, keys)
+	})
+}
+
+func appendStrings(list []interface{}, values []string) []interface{} {
+	for _, value := range values {
+		list = append(list, value)
+	}
+	return list
+}
+
+// parseSSHKeys splits authorized_keys formatted content into individual keys,
+// skipping blank lines and comments.
+func parseSSHKeys(content string) []string {
+	keys := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	return keys
+}
@@ -0,0 +1,100 @@
+package machine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestInjectSSHKeys(t *testing.T) {
+	keys := []string{"ssh-ed25519 AAAA operator@example.com"}
+
+	testCases := []struct {
+		testcase     string
+		format       kubevirtproviderv1.UserDataFormat
+		userData     string
+		expectedKeys []interface{}
+		expectError  bool
+	}{
+		{
+			testcase:     "empty cloud-init",
+			format:       kubevirtproviderv1.UserDataFormatCloudInit,
+			expectedKeys: []interface{}{"ssh-ed25519 AAAA operator@example.com"},
+		},
+		{
+			testcase:     "cloud-config with keys",
+			format:       kubevirtproviderv1.UserDataFormatCloudInit,
+			userData:     "#cloud-config\nssh_authorized_keys:\n- ssh-rsa BBBB baked@image\n",
+			expectedKeys: []interface{}{"ssh-rsa BBBB baked@image", "ssh-ed25519 AAAA operator@example.com"},
+		},
+		{
+			testcase:     "ignition without users",
+			format:       kubevirtproviderv1.UserDataFormatIgnition,
+			userData:     `{"ignition":{"version":"3.1.0"}}`,
+			expectedKeys: []interface{}{"ssh-ed25519 AAAA operator@example.com"},
+		},
+		{
+			testcase:     "ignition with core user",
+			format:       kubevirtproviderv1.UserDataFormatIgnition,
+			userData:     `{"ignition":{"version":"3.1.0"},"passwd":{"users":[{"name":"core","sshAuthorizedKeys":["ssh-rsa BBBB baked@image"]}]}}`,
+			expectedKeys: []interface{}{"ssh-rsa BBBB baked@image", "ssh-ed25519 AAAA operator@example.com"},
+		},
+		{
+			testcase:    "script",
+			format:      kubevirtproviderv1.UserDataFormatCloudInit,
+			userData:    "#!/bin/bash\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			userData, err := injectSSHKeys([]byte(tc.userData), tc.format, keys)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var authorizedKeys interface{}
+			switch tc.format {
+			case kubevirtproviderv1.UserDataFormatIgnition:
+				ignitionConfig := map[string]interface{}{}
+				if err := json.Unmarshal(userData, &ignitionConfig); err != nil {
+					t.Fatalf("Failed to parse rendered Ignition config: %v", err)
+				}
+				users := ignitionConfig["passwd"].(map[string]interface{})["users"].([]interface{})
+				if len(users) != 1 {
+					t.Fatalf("Expected 1 user, got %d", len(users))
+				}
+				authorizedKeys = users[0].(map[string]interface{})["sshAuthorizedKeys"]
+			default:
+				cloudConfig := map[string]interface{}{}
+				if err := yaml.Unmarshal(userData, &cloudConfig); err != nil {
+					t.Fatalf("Failed to parse rendered cloud-config: %v", err)
+				}
+				authorizedKeys = cloudConfig["ssh_authorized_keys"]
+			}
+
+			if !reflect.DeepEqual(authorizedKeys, tc.expectedKeys) {
+				t.Errorf("Expected authorized keys %v, got %v", tc.expectedKeys, authorizedKeys)
+			}
+		})
+	}
+}
+
+func TestParseSSHKeys(t *testing.T) {
+	keys := parseSSHKeys("# operators\nssh-ed25519 AAAA one@example.com\n\n  ssh-rsa BBBB two@example.com  \n")
+	expected := []string{"ssh-ed25519 AAAA one@example.com", "ssh-rsa BBBB two@example.com"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+}
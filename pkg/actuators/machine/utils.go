@@ -17,20 +17,12 @@ limitations under the License.
 package machine
 
 import (
-	"fmt"
-	"net"
-	"strings"
-
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/ec2"
 	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
-	"k8s.io/klog"
-	awsproviderv1 "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
-	awsclient "sigs.k8s.io/cluster-api-provider-aws/pkg/client"
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
 )
 
 // upstreamMachineClusterIDLabel is the label that a machine must have to identify the cluster to which it belongs
@@ -38,176 +30,21 @@ const upstreamMachineClusterIDLabel = "sigs.k8s.io/cluster-api-cluster"
 
 // existingInstanceStates returns the list of states an EC2 instance can be in
 // while being considered "existing", i.e. mostly anything but "Terminated".
-func existingInstanceStates() []*string {
-	return []*string{
-		aws.String(ec2.InstanceStateNameRunning),
-		aws.String(ec2.InstanceStateNamePending),
-		aws.String(ec2.InstanceStateNameStopped),
-		aws.String(ec2.InstanceStateNameStopping),
-		aws.String(ec2.InstanceStateNameShuttingDown),
-	}
-}
-
 // getRunningFromInstances returns all running instances from a list of instances.
-func getRunningFromInstances(instances []*ec2.Instance) []*ec2.Instance {
-	var runningInstances []*ec2.Instance
-	for _, instance := range instances {
-		if *instance.State.Name == ec2.InstanceStateNameRunning {
-			runningInstances = append(runningInstances, instance)
-		}
-	}
-	return runningInstances
-}
-
 // getStoppedInstances returns all stopped instances that have a tag matching our machine name,
 // and cluster ID.
-func getStoppedInstances(machine *machinev1.Machine, client awsclient.Client) ([]*ec2.Instance, error) {
-	stoppedInstanceStateFilter := []*string{aws.String(ec2.InstanceStateNameStopped), aws.String(ec2.InstanceStateNameStopping)}
-	return getInstances(machine, client, stoppedInstanceStateFilter)
-}
-
 // getExistingInstances returns all instances not terminated
-func getExistingInstances(machine *machinev1.Machine, client awsclient.Client) ([]*ec2.Instance, error) {
-	return getInstances(machine, client, existingInstanceStates())
-}
-
-func getExistingInstanceByID(id string, client awsclient.Client) (*ec2.Instance, error) {
-	return getInstanceByID(id, client, existingInstanceStates())
-}
-
-func instanceHasAllowedState(instance *ec2.Instance, instanceStateFilter []*string) error {
-	if instance.InstanceId == nil {
-		return fmt.Errorf("instance has nil ID")
-	}
-
-	if instance.State == nil {
-		return fmt.Errorf("instance %s has nil state", *instance.InstanceId)
-	}
-
-	if len(instanceStateFilter) == 0 {
-		return nil
-	}
-
-	actualState := aws.StringValue(instance.State.Name)
-	for _, allowedState := range instanceStateFilter {
-		if aws.StringValue(allowedState) == actualState {
-			return nil
-		}
-	}
-
-	allowedStates := make([]string, 0, len(instanceStateFilter))
-	for _, allowedState := range instanceStateFilter {
-		allowedStates = append(allowedStates, aws.StringValue(allowedState))
-	}
-	return fmt.Errorf("instance %s state %q is not in %s", *instance.InstanceId, actualState, strings.Join(allowedStates, ", "))
-}
-
 // getInstanceByID returns the instance with the given ID if it exists.
-func getInstanceByID(id string, client awsclient.Client, instanceStateFilter []*string) (*ec2.Instance, error) {
-	if id == "" {
-		return nil, fmt.Errorf("instance-id not specified")
-	}
-
-	request := &ec2.DescribeInstancesInput{
-		InstanceIds: aws.StringSlice([]string{id}),
-	}
-
-	result, err := client.DescribeInstances(request)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(result.Reservations) != 1 {
-		return nil, fmt.Errorf("found %d reservations for instance-id %s", len(result.Reservations), id)
-	}
-
-	reservation := result.Reservations[0]
-
-	if len(reservation.Instances) != 1 {
-		return nil, fmt.Errorf("found %d instances for instance-id %s", len(reservation.Instances), id)
-	}
-
-	instance := reservation.Instances[0]
-
-	return instance, instanceHasAllowedState(instance, instanceStateFilter)
-}
-
 // getInstances returns all instances that have a tag matching our machine name,
 // and cluster ID.
-func getInstances(machine *machinev1.Machine, client awsclient.Client, instanceStateFilter []*string) ([]*ec2.Instance, error) {
-	clusterID, ok := getClusterID(machine)
-	if !ok {
-		return []*ec2.Instance{}, fmt.Errorf("unable to get cluster ID for machine: %q", machine.Name)
-	}
-
-	requestFilters := []*ec2.Filter{
-		{
-			Name:   awsTagFilter("Name"),
-			Values: aws.StringSlice([]string{machine.Name}),
-		},
-		clusterFilter(clusterID),
-	}
-
-	request := &ec2.DescribeInstancesInput{
-		Filters: requestFilters,
-	}
-
-	result, err := client.DescribeInstances(request)
-	if err != nil {
-		return []*ec2.Instance{}, err
-	}
-
-	instances := make([]*ec2.Instance, 0, len(result.Reservations))
-
-	for _, reservation := range result.Reservations {
-		for _, instance := range reservation.Instances {
-			err := instanceHasAllowedState(instance, instanceStateFilter)
-			if err != nil {
-				klog.Errorf("Excluding instance matching %s: %v", machine.Name, err)
-			} else {
-				instances = append(instances, instance)
-			}
-		}
-	}
-
-	return instances, nil
-}
-
 // terminateInstances terminates all provided instances with a single EC2 request.
-func terminateInstances(client awsclient.Client, instances []*ec2.Instance) ([]*ec2.InstanceStateChange, error) {
-	instanceIDs := []*string{}
-	// Cleanup all older instances:
-	for _, instance := range instances {
-		klog.Infof("Cleaning up extraneous instance for machine: %v, state: %v, launchTime: %v", *instance.InstanceId, *instance.State.Name, *instance.LaunchTime)
-		instanceIDs = append(instanceIDs, instance.InstanceId)
-	}
-	for _, instanceID := range instanceIDs {
-		klog.Infof("Terminating %v instance", *instanceID)
-	}
-
-	terminateInstancesRequest := &ec2.TerminateInstancesInput{
-		InstanceIds: instanceIDs,
-	}
-	output, err := client.TerminateInstances(terminateInstancesRequest)
-	if err != nil {
-		klog.Errorf("Error terminating instances: %v", err)
-		return nil, fmt.Errorf("error terminating instances: %v", err)
-	}
-
-	if output == nil {
-		return nil, nil
-	}
-
-	return output.TerminatingInstances, nil
-}
-
-// setAWSMachineProviderCondition sets the condition for the machine and
+// setKubevirtMachineProviderCondition sets the condition for the machine and
 // returns the new slice of conditions.
 // If the machine does not already have a condition with the specified type,
 // a condition will be added to the slice
 // If the machine does already have a condition with the specified type,
 // the condition will be updated if either of the following are true.
-func setAWSMachineProviderCondition(condition awsproviderv1.AWSMachineProviderCondition, conditions []awsproviderv1.AWSMachineProviderCondition) []awsproviderv1.AWSMachineProviderCondition {
+func setKubevirtMachineProviderCondition(condition kubevirtproviderv1.KubevirtMachineProviderCondition, conditions []kubevirtproviderv1.KubevirtMachineProviderCondition) []kubevirtproviderv1.KubevirtMachineProviderCondition {
 	now := metav1.Now()
 
 	if existingCondition := findProviderCondition(conditions, condition.Type); existingCondition == nil {
@@ -221,7 +58,7 @@ func setAWSMachineProviderCondition(condition awsproviderv1.AWSMachineProviderCo
 	return conditions
 }
 
-func findProviderCondition(conditions []awsproviderv1.AWSMachineProviderCondition, conditionType awsproviderv1.AWSMachineProviderConditionType) *awsproviderv1.AWSMachineProviderCondition {
+func findProviderCondition(conditions []kubevirtproviderv1.KubevirtMachineProviderCondition, conditionType kubevirtproviderv1.KubevirtMachineProviderConditionType) *kubevirtproviderv1.KubevirtMachineProviderCondition {
 	for i := range conditions {
 		if conditions[i].Type == conditionType {
 			return &conditions[i]
@@ -230,7 +67,7 @@ func findProviderCondition(conditions []awsproviderv1.AWSMachineProviderConditio
 	return nil
 }
 
-func updateExistingCondition(newCondition, existingCondition *awsproviderv1.AWSMachineProviderCondition) {
+func updateExistingCondition(newCondition, existingCondition *kubevirtproviderv1.KubevirtMachineProviderCondition) {
 	if !shouldUpdateCondition(newCondition, existingCondition) {
 		return
 	}
@@ -244,90 +81,25 @@ func updateExistingCondition(newCondition, existingCondition *awsproviderv1.AWSM
 	existingCondition.LastProbeTime = newCondition.LastProbeTime
 }
 
-func shouldUpdateCondition(newCondition, existingCondition *awsproviderv1.AWSMachineProviderCondition) bool {
+func shouldUpdateCondition(newCondition, existingCondition *kubevirtproviderv1.KubevirtMachineProviderCondition) bool {
 	return newCondition.Reason != existingCondition.Reason || newCondition.Message != existingCondition.Message
 }
 
 // extractNodeAddresses maps the instance information from EC2 to an array of NodeAddresses
-func extractNodeAddresses(instance *ec2.Instance) ([]corev1.NodeAddress, error) {
-	// Not clear if the order matters here, but we might as well indicate a sensible preference order
-
-	if instance == nil {
-		return nil, fmt.Errorf("nil instance passed to extractNodeAddresses")
-	}
-
-	addresses := []corev1.NodeAddress{}
-
-	// handle internal network interfaces
-	for _, networkInterface := range instance.NetworkInterfaces {
-		// skip network interfaces that are not currently in use
-		if aws.StringValue(networkInterface.Status) != ec2.NetworkInterfaceStatusInUse {
-			continue
-		}
-
-		// Treating IPv6 addresses as type NodeInternalIP to match what the KNI
-		// patch to the AWS cloud-provider code is doing:
-		//
-		// https://github.com/openshift-kni/origin/commit/7db21c1e26a344e25ae1b825d4f21e7bef5c3650
-		for _, ipv6Address := range networkInterface.Ipv6Addresses {
-			if addr := aws.StringValue(ipv6Address.Ipv6Address); addr != "" {
-				ip := net.ParseIP(addr)
-				if ip == nil {
-					return nil, fmt.Errorf("EC2 instance had invalid IPv6 address: %s (%q)", aws.StringValue(instance.InstanceId), addr)
-				}
-				addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip.String()})
-			}
-		}
-
-		for _, internalIP := range networkInterface.PrivateIpAddresses {
-			if ipAddress := aws.StringValue(internalIP.PrivateIpAddress); ipAddress != "" {
-				ip := net.ParseIP(ipAddress)
-				if ip == nil {
-					return nil, fmt.Errorf("EC2 instance had invalid private address: %s (%q)", aws.StringValue(instance.InstanceId), ipAddress)
-				}
-				addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip.String()})
-			}
-		}
-	}
-
-	// TODO: Other IP addresses (multiple ips)?
-	publicIPAddress := aws.StringValue(instance.PublicIpAddress)
-	if publicIPAddress != "" {
-		ip := net.ParseIP(publicIPAddress)
-		if ip == nil {
-			return nil, fmt.Errorf("EC2 instance had invalid public address: %s (%s)", aws.StringValue(instance.InstanceId), publicIPAddress)
-		}
-		addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: ip.String()})
-	}
-
-	privateDNSName := aws.StringValue(instance.PrivateDnsName)
-	if privateDNSName != "" {
-		addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeInternalDNS, Address: privateDNSName})
-		addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeHostName, Address: privateDNSName})
-	}
-
-	publicDNSName := aws.StringValue(instance.PublicDnsName)
-	if publicDNSName != "" {
-		addresses = append(addresses, corev1.NodeAddress{Type: corev1.NodeExternalDNS, Address: publicDNSName})
-	}
-
-	return addresses, nil
-}
-
-func conditionSuccess() awsproviderv1.AWSMachineProviderCondition {
-	return awsproviderv1.AWSMachineProviderCondition{
-		Type:    awsproviderv1.MachineCreation,
+func conditionSuccess() kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.MachineCreation,
 		Status:  corev1.ConditionTrue,
-		Reason:  awsproviderv1.MachineCreationSucceeded,
+		Reason:  kubevirtproviderv1.MachineCreationSucceeded,
 		Message: "Machine successfully created",
 	}
 }
 
-func conditionFailed() awsproviderv1.AWSMachineProviderCondition {
-	return awsproviderv1.AWSMachineProviderCondition{
-		Type:   awsproviderv1.MachineCreation,
+func conditionFailed() kubevirtproviderv1.KubevirtMachineProviderCondition {
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:   kubevirtproviderv1.MachineCreation,
 		Status: corev1.ConditionFalse,
-		Reason: awsproviderv1.MachineCreationFailed,
+		Reason: kubevirtproviderv1.MachineCreationFailed,
 	}
 }
 
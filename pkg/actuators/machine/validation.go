@@ -0,0 +1,325 @@
+package machine
+
+import (
+	"fmt"
+
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// DefaultProviderSpec sets the defaults the actuator applies to unset fields of the provider spec.
+func DefaultProviderSpec(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) {
+	if providerSpec.RequestedMemory == "" {
+		providerSpec.RequestedMemory = defaultRequestedMemory
+	}
+	if providerSpec.RequestedCPU == 0 {
+		providerSpec.RequestedCPU = defaultRequestedCPU
+	}
+	if providerSpec.RequestedStorage == "" {
+		providerSpec.RequestedStorage = defaultRequestedStorage
+	}
+	if providerSpec.DiskBus == "" {
+		providerSpec.DiskBus = defaultBus
+	}
+	if providerSpec.CloudInitSource == "" {
+		providerSpec.CloudInitSource = kubevirtproviderv1.CloudInitNoCloud
+	}
+}
+
+// ValidateProviderSpec validates the provider spec the way the actuator does when creating the VM,
+// without access to the infra cluster. fldPath is the path of the provider spec value.
+func ValidateProviderSpec(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if providerSpec.SourcePvcName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("sourcePvcName"), "sourcePvcName must be specified"))
+	}
+
+	if providerSpec.RequestedMemory != "" {
+		if _, err := resource.ParseQuantity(providerSpec.RequestedMemory); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requestedMemory"), providerSpec.RequestedMemory, err.Error()))
+		}
+	}
+	if providerSpec.GuestMemory != "" {
+		if err := validateGuestMemory(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("guestMemory"), providerSpec.GuestMemory, err.Error()))
+		}
+	}
+	if providerSpec.RequestedStorage != "" {
+		if _, err := resource.ParseQuantity(providerSpec.RequestedStorage); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("requestedStorage"), providerSpec.RequestedStorage, err.Error()))
+		}
+	}
+
+	requestedCPU := providerSpec.RequestedCPU
+	if requestedCPU == 0 {
+		requestedCPU = defaultRequestedCPU
+	}
+	if _, err := resolveDiskBus(providerSpec, requestedCPU); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("diskBus"), providerSpec.DiskBus, err.Error()))
+	}
+
+	switch providerSpec.CloudInitSource {
+	case "", kubevirtproviderv1.CloudInitNoCloud, kubevirtproviderv1.CloudInitConfigDrive:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("cloudInitSource"), providerSpec.CloudInitSource,
+			[]string{string(kubevirtproviderv1.CloudInitNoCloud), string(kubevirtproviderv1.CloudInitConfigDrive)}))
+	}
+
+	switch providerSpec.UserDataFormat {
+	case "", kubevirtproviderv1.UserDataFormatCloudInit, kubevirtproviderv1.UserDataFormatIgnition:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("userDataFormat"), providerSpec.UserDataFormat,
+			[]string{string(kubevirtproviderv1.UserDataFormatCloudInit), string(kubevirtproviderv1.UserDataFormatIgnition)}))
+	}
+
+	if providerSpec.UserDataSecret != nil && providerSpec.UserDataSecret.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("userDataSecret", "name"), "userDataSecret name must be specified"))
+	}
+
+	for i, address := range providerSpec.StaticIPAddresses {
+		if _, err := parseStaticIPAddresses([]string{address}); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("staticIPAddresses").Index(i), address, err.Error()))
+		}
+	}
+
+	if providerSpec.AddressFamilyPolicy != "" {
+		if err := validateAddressFamilyPolicy(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("addressFamilyPolicy"), providerSpec.AddressFamilyPolicy, err.Error()))
+		}
+	}
+
+	if len(providerSpec.Interfaces) > 0 {
+		if err := validateNetworkInterfaces(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("interfaces"), "", err.Error()))
+		}
+	}
+
+	if providerSpec.NetworkData != nil {
+		if err := validateNetworkData(providerSpec.NetworkData); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("networkData"), "", err.Error()))
+		}
+		if err := validateAddressPools(providerSpec.NetworkData); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("networkData"), "", err.Error()))
+		}
+		if providerSpec.UserDataFormat == kubevirtproviderv1.UserDataFormatIgnition {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("networkData"), "", fmt.Sprintf("networkData requires %s UserData", kubevirtproviderv1.UserDataFormatCloudInit)))
+		}
+		if providerSpec.CloudInitSource == kubevirtproviderv1.CloudInitConfigDrive {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("networkData"), "", fmt.Sprintf("networkData requires the %s cloudInitSource", kubevirtproviderv1.CloudInitNoCloud)))
+		}
+	}
+
+	if providerSpec.ShareUserDataSecret {
+		if err := validateSharedUserData(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("shareUserDataSecret"), providerSpec.ShareUserDataSecret, err.Error()))
+		}
+	}
+
+	if providerSpec.DomainSuffix != "" {
+		if _, err := validateDomainSuffix(providerSpec.DomainSuffix); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("domainSuffix"), providerSpec.DomainSuffix, err.Error()))
+		}
+	}
+
+	if err := validateInfraNamespace(providerSpec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("infraNamespace"), providerSpec.InfraNamespace, err.Error()))
+	}
+
+	if _, err := resolveRunStrategy(providerSpec); err != nil {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("runStrategy"), providerSpec.RunStrategy,
+			[]string{string(kubevirtproviderv1.RunStrategyAlways), string(kubevirtproviderv1.RunStrategyRerunOnFailure), string(kubevirtproviderv1.RunStrategyManual)}))
+	}
+
+	if err := validateNodeLabels(providerSpec.NodeLabels); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("nodeLabels"), providerSpec.NodeLabels, err.Error()))
+	}
+
+	if err := validateNodeTaints(providerSpec.NodeTaints); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("nodeTaints"), providerSpec.NodeTaints, err.Error()))
+	}
+
+	if providerSpec.ControlPlaneLoadBalancer != nil {
+		if err := validateControlPlaneLoadBalancer(providerSpec.ControlPlaneLoadBalancer); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("controlPlaneLoadBalancer"), providerSpec.ControlPlaneLoadBalancer, err.Error()))
+		}
+	}
+
+	if err := validateUpdatePolicy(providerSpec.UpdatePolicy); err != nil {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("updatePolicy"), providerSpec.UpdatePolicy,
+			[]string{string(kubevirtproviderv1.UpdatePolicyInPlace), string(kubevirtproviderv1.UpdatePolicyRestart), string(kubevirtproviderv1.UpdatePolicyRecreate)}))
+	}
+
+	if providerSpec.GuestShutdownPolicy != "" {
+		if err := validateGuestShutdownPolicy(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("guestShutdownPolicy"), providerSpec.GuestShutdownPolicy, err.Error()))
+		}
+	}
+
+	if _, err := resolveEvictionStrategy(providerSpec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("evictionStrategy"), providerSpec.EvictionStrategy, err.Error()))
+	} else if _, err := resolveRootVolumeAccessMode(providerSpec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rootVolumeAccessMode"), providerSpec.RootVolumeAccessMode, err.Error()))
+	}
+
+	if _, err := resolveRootVolumeMode(providerSpec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rootVolumeMode"), providerSpec.RootVolumeMode, err.Error()))
+	}
+
+	if len(providerSpec.ScratchDisks) > 0 {
+		if err := validateScratchDisks(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("scratchDisks"), providerSpec.ScratchDisks, err.Error()))
+		}
+	}
+
+	if providerSpec.Watchdog != nil {
+		if err := validateWatchdog(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("watchdog"), *providerSpec.Watchdog, err.Error()))
+		}
+	}
+
+	if providerSpec.SMBIOS != nil {
+		if err := validateSMBIOS(providerSpec.SMBIOS); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("smbios"), *providerSpec.SMBIOS, err.Error()))
+		}
+	}
+
+	if providerSpec.Clock != nil {
+		if err := validateClock(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("clock"), *providerSpec.Clock, err.Error()))
+		}
+	}
+
+	if providerSpec.Preemptible != nil {
+		if err := validatePreemptible(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("preemptible"), *providerSpec.Preemptible, err.Error()))
+		}
+	}
+
+	if providerSpec.FailureDomain != nil {
+		if err := validateFailureDomain(providerSpec.FailureDomain); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("failureDomain"), *providerSpec.FailureDomain, err.Error()))
+		}
+	}
+
+	if providerSpec.Hugepages != nil {
+		if err := validateHugepages(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hugepages"), *providerSpec.Hugepages, err.Error()))
+		}
+	}
+	if providerSpec.NUMAResourceCheck {
+		if err := validateNUMAResourceCheck(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("numaResourceCheck"), providerSpec.NUMAResourceCheck, err.Error()))
+		}
+	}
+
+	if providerSpec.LauncherResources != nil {
+		if err := validateLauncherResources(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("launcherResources"), *providerSpec.LauncherResources, err.Error()))
+		}
+	}
+
+	if providerSpec.LauncherOverhead != nil {
+		if err := validateLauncherOverhead(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("launcherOverhead"), *providerSpec.LauncherOverhead, err.Error()))
+		}
+	}
+
+	if providerSpec.LauncherSecurity != nil {
+		if err := validateLauncherSecurity(providerSpec.LauncherSecurity); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("launcherSecurity"), *providerSpec.LauncherSecurity, err.Error()))
+		}
+	}
+
+	if providerSpec.BootImageImport != nil {
+		if err := validateBootImageImport(providerSpec.BootImageImport); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("bootImageImport"), *providerSpec.BootImageImport, err.Error()))
+		}
+	}
+
+	if _, err := resolveArchitecture(providerSpec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("architecture"), providerSpec.Architecture, err.Error()))
+	}
+
+	if providerSpec.Windows != nil {
+		if err := validateWindows(providerSpec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("windows"), *providerSpec.Windows, err.Error()))
+		}
+	}
+
+	if providerSpec.SerialConsoleLog != nil {
+		if err := validateSerialConsoleLog(providerSpec.SerialConsoleLog); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("serialConsoleLog"), *providerSpec.SerialConsoleLog, err.Error()))
+		}
+	}
+
+	if providerSpec.RebootPolicy != nil {
+		if err := validateRebootPolicy(providerSpec.RebootPolicy); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rebootPolicy"), *providerSpec.RebootPolicy, err.Error()))
+		}
+	}
+
+	if providerSpec.GracefulShutdownTimeout != nil {
+		if err := validateGracefulShutdownTimeout(providerSpec.GracefulShutdownTimeout); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("gracefulShutdownTimeout"), providerSpec.GracefulShutdownTimeout.Duration.String(), err.Error()))
+		}
+	}
+
+	if providerSpec.NodeDrainTimeout != nil {
+		if err := validateNodeDrainTimeout(providerSpec.NodeDrainTimeout); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nodeDrainTimeout"), providerSpec.NodeDrainTimeout.Duration.String(), err.Error()))
+		}
+	}
+
+	if providerSpec.MaxLifetime != nil {
+		if err := validateMaxLifetime(providerSpec.MaxLifetime); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxLifetime"), providerSpec.MaxLifetime.Duration.String(), err.Error()))
+		}
+	}
+	if _, err := resolveExpiredMachineAction(providerSpec); err != nil {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("expiredMachineAction"), providerSpec.ExpiredMachineAction,
+			[]string{string(kubevirtproviderv1.ExpiredMachineReport), string(kubevirtproviderv1.ExpiredMachineMarkForDeletion), string(kubevirtproviderv1.ExpiredMachineDelete)}))
+	}
+
+	if providerSpec.BootVolumeRetryPolicy != nil {
+		if err := validateBootVolumeRetryPolicy(providerSpec.BootVolumeRetryPolicy); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("bootVolumeRetryPolicy"), *providerSpec.BootVolumeRetryPolicy, err.Error()))
+		}
+	}
+
+	if providerSpec.DataExport != nil {
+		if err := validateDataExport(providerSpec.DataExport); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dataExport"), *providerSpec.DataExport, err.Error()))
+		}
+	}
+
+	if providerSpec.SnapshotOnDelete != nil {
+		if err := validateSnapshotOnDelete(providerSpec.SnapshotOnDelete); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("snapshotOnDelete"), *providerSpec.SnapshotOnDelete, err.Error()))
+		}
+	}
+
+	if providerSpec.AdvancedTuning != nil {
+		if err := validateAdvancedTuning(providerSpec.AdvancedTuning); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("advancedTuning", "annotations"), advancedTuningAnnotationKeys(providerSpec.AdvancedTuning), err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// providerSpecPath is the path of the provider spec value in a Machine.
+var providerSpecPath = field.NewPath("spec", "providerSpec", "value")
+
+// validateProviderSpec returns an InvalidMachineConfiguration error listing what is malformed
+// in the provider spec, for the machine to fail right away rather than its creation to be
+// retried until the spec is fixed.
+func (r *Reconciler) validateProviderSpec() error {
+	if allErrs := ValidateProviderSpec(r.providerSpec, providerSpecPath); len(allErrs) > 0 {
+		return machinecontroller.InvalidMachineConfiguration("%v: invalid provider spec: %v", r.machine.GetName(), allErrs.ToAggregate())
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestDefaultProviderSpec(t *testing.T) {
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		RequestedCPU:  4,
+	}
+	DefaultProviderSpec(providerSpec)
+
+	expected := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:    "rhcos",
+		RequestedCPU:     4,
+		RequestedMemory:  defaultRequestedMemory,
+		RequestedStorage: defaultRequestedStorage,
+		DiskBus:          kubevirtproviderv1.DiskBusVirtio,
+		CloudInitSource:  kubevirtproviderv1.CloudInitNoCloud,
+	}
+	if !equality.Semantic.DeepEqual(providerSpec, expected) {
+		t.Errorf("Expected provider spec %+v, got %+v", expected, providerSpec)
+	}
+}
+
+func TestValidateProviderSpec(t *testing.T) {
+	testCases := []struct {
+		testcase       string
+		providerSpec   kubevirtproviderv1.KubevirtMachineProviderSpec
+		expectedFields []string
+	}{
+		{
+			testcase: "valid",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:     "rhcos",
+				RequestedMemory:   "4Gi",
+				RequestedCPU:      4,
+				BlockMultiQueue:   true,
+				StaticIPAddresses: []string{"192.168.1.10"},
+				DomainSuffix:      "cluster.example.com",
+			},
+		},
+		{
+			testcase:       "missing source PVC",
+			expectedFields: []string{"providerSpec.sourcePvcName"},
+		},
+		{
+			testcase: "invalid fields",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:     "rhcos",
+				RequestedStorage:  "big",
+				CloudInitSource:   "Ignition",
+				UserDataFormat:    "Script",
+				StaticIPAddresses: []string{"192.168.1.10", "not-an-ip"},
+				DomainSuffix:      "Cluster_Example",
+			},
+			expectedFields: []string{
+				"providerSpec.requestedStorage",
+				"providerSpec.cloudInitSource",
+				"providerSpec.userDataFormat",
+				"providerSpec.staticIPAddresses[1]",
+				"providerSpec.domainSuffix",
+			},
+		},
+		{
+			testcase: "multi-queue with a single vCPU",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:   "rhcos",
+				BlockMultiQueue: true,
+			},
+			expectedFields: []string{"providerSpec.diskBus"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			errs := ValidateProviderSpec(&tc.providerSpec, field.NewPath("providerSpec"))
+			if len(errs) != len(tc.expectedFields) {
+				t.Fatalf("Expected %d errors, got %v", len(tc.expectedFields), errs)
+			}
+			for i, err := range errs {
+				if err.Field != tc.expectedFields[i] {
+					t.Errorf("Expected error on %q, got %v", tc.expectedFields[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateWithInvalidProviderSpec(t *testing.T) {
+	r := newReconciler(&machineScope{
+		Context: context.Background(),
+		machine: &machinev1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker-abcde",
+				Namespace: "tenant-a",
+				Labels:    map[string]string{machinev1.MachineClusterIDLabel: "tenant-a"},
+			},
+		},
+		providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+			SourcePvcName:   "rhcos",
+			RequestedMemory: "8 gigs",
+			NetworkData: &kubevirtproviderv1.NetworkData{
+				VLANs: []kubevirtproviderv1.VLANInterface{{Name: "eth0.100", ID: 100, Link: "eth0"}},
+			},
+		},
+		providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+	})
+
+	err := r.create()
+	var machineErr *machinecontroller.MachineError
+	if !errors.As(err, &machineErr) || machineErr.Reason != machinev1.InvalidConfigurationMachineError {
+		t.Fatalf("Expected an invalid configuration error, got %v", err)
+	}
+	for _, fieldPath := range []string{"spec.providerSpec.value.requestedMemory", "spec.providerSpec.value.networkData"} {
+		if !strings.Contains(err.Error(), fieldPath) {
+			t.Errorf("Expected the error to report %s, got %v", fieldPath, err)
+		}
+	}
+}
+
+func TestValidateSecretReferences(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sshKeysSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ssh-keys", Namespace: "tenant-a"},
+	}
+
+	testCases := []struct {
+		testcase    string
+		secretName  string
+		expectedErr bool
+	}{
+		{
+			testcase:   "existing SSH keys secret",
+			secretName: "ssh-keys",
+		},
+		{
+			testcase:    "SSH keys secret not created yet",
+			secretName:  "missing",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			r := newReconciler(&machineScope{
+				Context: context.Background(),
+				client:  fake.NewFakeClientWithScheme(scheme, sshKeysSecret),
+				machine: &machinev1.Machine{
+					ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"},
+				},
+				providerSpec: &kubevirtproviderv1.KubevirtMachineProviderSpec{
+					SSHKeys: &kubevirtproviderv1.SSHKeys{
+						SecretRef: &corev1.LocalObjectReference{Name: tc.secretName},
+					},
+				},
+			})
+
+			err := r.validateSecretReferences()
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+			// A missing secret is retried, the machine is not failed
+			var machineErr *machinecontroller.MachineError
+			if errors.As(err, &machineErr) {
+				t.Errorf("Expected a transient error, got %v", err)
+			}
+		})
+	}
+}
@@ -1,408 +1,659 @@
 package machine
 
 import (
-	"encoding/base64"
+	"context"
+	"encoding/json"
 	"fmt"
-	"sort"
-	"strconv"
 	"strings"
-	"time"
 
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
 	mapierrors "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
-	awsproviderv1 "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
-	awsclient "sigs.k8s.io/cluster-api-provider-aws/pkg/client"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/ec2"
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
 	kubevirtclient "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tracing"
 )
 
-// Scan machine tags, and return a deduped tags list
-func removeDuplicatedTags(tags []*ec2.Tag) []*ec2.Tag {
-	m := make(map[string]bool)
-	result := []*ec2.Tag{}
+const (
+	defaultRequestedMemory  = "2048M"
+	defaultRequestedStorage = "35Gi"
+	defaultRequestedCPU     = 1
+	defaultBus              = kubevirtproviderv1.DiskBusVirtio
+
+	mainDiskName         = "rootdisk"
+	cloudInitVolumeName  = "cloudinitdisk"
+	bootVolumeSuffix     = "-bootvolume"
+	userDataSecretSuffix = "-userdata"
+	// cloudInitUserDataKey is the key KubeVirt reads the UserData from in cloud-init secrets.
+	cloudInitUserDataKey = "userdata"
+)
 
-	// look for duplicates
-	for _, entry := range tags {
-		if _, value := m[*entry.Key]; !value {
-			m[*entry.Key] = true
-			result = append(result, entry)
+// createVM creates the VirtualMachine backing the machine on the infra cluster, with the
+// resources of the overcommit profile if it is not nil.
+func createVM(ctx context.Context, machine *machinev1.Machine, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, overcommitProfile *OvercommitProfile, userData []byte, client kubevirtclient.Client) (*kubevirtapis.VirtualMachine, error) {
+	virtualMachine, userDataSecret, err := renderVM(ctx, machine, providerSpec, overcommitProfile, userData, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if userDataSecret != nil && providerSpec.ShareUserDataSecret {
+		if err := tracing.Trace(ctx, "EnsureSharedUserDataSecret", func() error {
+			return ensureSharedUserDataSecret(ctx, client, userDataSecret)
+		}, tracing.String("secret.name", userDataSecret.Name)); err != nil {
+			return nil, createMachineError(err, "error creating shared UserData secret %s: %v", userDataSecret.Name, err)
+		}
+	} else if userDataSecret != nil {
+		if err := tracing.Trace(ctx, "ApplyUserDataSecret", func() error {
+			return applyUserDataSecret(ctx, client, userDataSecret)
+		}, tracing.String("secret.name", userDataSecret.Name)); err != nil {
+			return nil, createMachineError(err, "error creating UserData secret %s: %v", userDataSecret.Name, err)
 		}
 	}
-	return result
-}
 
-// removeStoppedMachine removes all instances of a specific machine that are in a stopped state.
-func removeStoppedMachine(machine *machinev1.Machine, client awsclient.Client) error {
-	instances, err := getStoppedInstances(machine, client)
+	// KubeVirt creates the DataVolumes out of the DataVolume templates of the VM
+	var createdVM *kubevirtapis.VirtualMachine
+	err = tracing.Trace(ctx, "CreateVirtualMachine", func() (err error) {
+		createdVM, err = client.CreateVirtualMachine(ctx, virtualMachine.Namespace, virtualMachine)
+		return err
+	}, tracing.String("vm.name", virtualMachine.Name), tracing.String("vm.dataVolumeTemplates", dataVolumeTemplateNames(virtualMachine)))
 	if err != nil {
-		klog.Errorf("Error getting stopped instances: %v", err)
-		return fmt.Errorf("error getting stopped instances: %v", err)
+		klog.Errorf("Error creating VirtualMachine: %v", err)
+		return nil, createMachineError(err, "error creating VirtualMachine: %v", err)
 	}
 
-	if len(instances) == 0 {
-		klog.Infof("No stopped instances found for machine %v", machine.Name)
-		return nil
+	if userDataSecret != nil && providerSpec.ShareUserDataSecret {
+		if err := addSharedUserDataSecretOwner(ctx, client, userDataSecret, createdVM); err != nil {
+			return nil, createMachineError(err, "error adding VirtualMachine to the owners of shared UserData secret %s: %v", userDataSecret.Name, err)
+		}
 	}
 
-	_, err = terminateInstances(client, instances)
-	return err
+	if err := tracing.Trace(ctx, "StartVirtualMachine", func() error {
+		return startManualVM(ctx, client, createdVM, createdVM.Spec.RunStrategy)
+	}, tracing.String("vm.name", createdVM.Name)); err != nil {
+		return nil, createMachineError(err, "%v", err)
+	}
+
+	return createdVM, nil
 }
 
-func buildEC2Filters(inputFilters []awsproviderv1.Filter) []*ec2.Filter {
-	filters := make([]*ec2.Filter, len(inputFilters))
-	for i, f := range inputFilters {
-		values := make([]*string, len(f.Values))
-		for j, v := range f.Values {
-			values[j] = aws.String(v)
+// renderVM renders the VirtualMachine and the UserData secret createVM creates, reading the
+// source PVC of the boot image if it is tracked or must match the architecture of the guest.
+func renderVM(ctx context.Context, machine *machinev1.Machine, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, overcommitProfile *OvercommitProfile, userData []byte, client kubevirtclient.Client) (*kubevirtapis.VirtualMachine, *corev1.Secret, error) {
+	if providerSpec.BootImageImport != nil {
+		sourcePvcName, err := resolveBootImageSource(ctx, client, infraNamespace(machine, providerSpec), providerSpec)
+		if err != nil {
+			return nil, nil, createMachineError(err, "error resolving boot image %s: %v", providerSpec.SourcePvcName, err)
 		}
-		filters[i] = &ec2.Filter{
-			Name:   aws.String(f.Name),
-			Values: values,
+		providerSpec = providerSpec.DeepCopy()
+		providerSpec.SourcePvcName = sourcePvcName
+	}
+
+	virtualMachine, userDataSecret, err := buildVM(machine, providerSpec, userData)
+	if err != nil {
+		return nil, nil, mapierrors.InvalidMachineConfiguration("error building VirtualMachine: %v", err)
+	}
+	if overcommitProfile != nil {
+		applyOvercommitProfile(&virtualMachine.Spec.Template.Spec, overcommitProfile)
+	}
+	if providerSpec.LauncherOverhead != nil {
+		if err := applyLauncherOverhead(&virtualMachine.Spec.Template.Spec, providerSpec.LauncherOverhead); err != nil {
+			return nil, nil, mapierrors.InvalidMachineConfiguration("error building VirtualMachine: invalid launcherOverhead: %v", err)
 		}
 	}
-	return filters
-}
 
-func getSecurityGroupsIDs(securityGroups []awsproviderv1.AWSResourceReference, client awsclient.Client) ([]*string, error) {
-	var securityGroupIDs []*string
-	for _, g := range securityGroups {
-		// ID has priority
-		if g.ID != nil {
-			securityGroupIDs = append(securityGroupIDs, g.ID)
-		} else if g.Filters != nil {
-			klog.Info("Describing security groups based on filters")
-			// Get groups based on filters
-			describeSecurityGroupsRequest := ec2.DescribeSecurityGroupsInput{
-				Filters: buildEC2Filters(g.Filters),
-			}
-			describeSecurityGroupsResult, err := client.DescribeSecurityGroups(&describeSecurityGroupsRequest)
-			if err != nil {
-				klog.Errorf("error describing security groups: %v", err)
-				return nil, fmt.Errorf("error describing security groups: %v", err)
-			}
-			for _, g := range describeSecurityGroupsResult.SecurityGroups {
-				groupID := *g.GroupId
-				securityGroupIDs = append(securityGroupIDs, &groupID)
+	if providerSpec.TrackBootImage || providerSpec.Architecture != "" {
+		var sourcePvc *corev1.PersistentVolumeClaim
+		err := tracing.Trace(ctx, "GetSourcePVC", func() (err error) {
+			sourcePvc, err = client.GetPersistentVolumeClaim(ctx, virtualMachine.Namespace, providerSpec.SourcePvcName, &metav1.GetOptions{})
+			return err
+		}, tracing.String("pvc.name", providerSpec.SourcePvcName))
+		if err != nil {
+			return nil, nil, createMachineError(err, "error getting source PVC %s: %v", providerSpec.SourcePvcName, err)
+		}
+		if providerSpec.Architecture != "" {
+			if err := validateSourceArchitecture(sourcePvc, providerSpec.Architecture); err != nil {
+				return nil, nil, mapierrors.InvalidMachineConfiguration("error building VirtualMachine: %v", err)
 			}
 		}
+		if providerSpec.TrackBootImage {
+			setBootImageSource(virtualMachine, sourcePvc)
+		}
 	}
+	return virtualMachine, userDataSecret, nil
+}
 
-	if len(securityGroups) == 0 {
-		klog.Info("No security group found")
+// dataVolumeTemplateNames returns the comma separated names of the DataVolume templates of the VM.
+func dataVolumeTemplateNames(vm *kubevirtapis.VirtualMachine) string {
+	names := make([]string, 0, len(vm.Spec.DataVolumeTemplates))
+	for _, template := range vm.Spec.DataVolumeTemplates {
+		names = append(names, template.Name)
 	}
+	return strings.Join(names, ",")
+}
 
-	return securityGroupIDs, nil
+// applyUserDataSecret creates the UserData secret, or updates it if left over by a previous attempt.
+func applyUserDataSecret(ctx context.Context, client kubevirtclient.Client, secret *corev1.Secret) error {
+	_, err := client.CreateSecret(ctx, secret.Namespace, secret)
+	if apierrors.IsAlreadyExists(err) {
+		_, err = client.UpdateSecret(ctx, secret.Namespace, secret)
+	}
+	return err
 }
 
-func getSubnetIDs(subnet awsproviderv1.AWSResourceReference, availabilityZone string, client awsclient.Client) ([]*string, error) {
-	var subnetIDs []*string
-	// ID has priority
-	if subnet.ID != nil {
-		subnetIDs = append(subnetIDs, subnet.ID)
+// buildVM renders the VirtualMachine for the given machine out of its provider spec, along
+// with the secret its cloud-init volume mounts, or the secret of the rendered answer file of
+// Windows guests. No secret is returned for UserData handed to the guest through the KubeVirt
+// Ignition mechanism.
+func buildVM(machine *machinev1.Machine, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, userData []byte) (*kubevirtapis.VirtualMachine, *corev1.Secret, error) {
+	if providerSpec.SourcePvcName == "" {
+		return nil, nil, fmt.Errorf("sourcePvcName must be specified")
+	}
+
+	requestedMemory := providerSpec.RequestedMemory
+	if requestedMemory == "" {
+		requestedMemory = defaultRequestedMemory
+	}
+	memory, err := resource.ParseQuantity(requestedMemory)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid requestedMemory %q: %v", requestedMemory, err)
+	}
+
+	requestedCPU := providerSpec.RequestedCPU
+	if requestedCPU == 0 {
+		requestedCPU = defaultRequestedCPU
+	}
+
+	diskBus, err := resolveDiskBus(providerSpec, requestedCPU)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rootVolumeMode, err := resolveRootVolumeMode(providerSpec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	disks := []kubevirtapis.Disk{
+		buildDisk(mainDiskName, diskBus),
+	}
+	var volumes []kubevirtapis.Volume
+	var dataVolumeTemplates []cdiv1.DataVolume
+	if rootVolumeMode == kubevirtproviderv1.RootVolumeModeEphemeral {
+		volumes = append(volumes, buildEphemeralRootVolume(providerSpec))
 	} else {
-		var filters []awsproviderv1.Filter
-		if availabilityZone != "" {
-			// Improve error logging for better user experience.
-			// Otherwise, during the process of minimizing API calls, this is a good
-			// candidate for removal.
-			_, err := client.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
-				ZoneNames: []*string{aws.String(availabilityZone)},
-			})
-			if err != nil {
-				klog.Errorf("error describing availability zones: %v", err)
-				return nil, fmt.Errorf("error describing availability zones: %v", err)
-			}
-			filters = append(filters, awsproviderv1.Filter{Name: "availabilityZone", Values: []string{availabilityZone}})
-		}
-		filters = append(filters, subnet.Filters...)
-		klog.Info("Describing subnets based on filters")
-		describeSubnetRequest := ec2.DescribeSubnetsInput{
-			Filters: buildEC2Filters(filters),
-		}
-		describeSubnetResult, err := client.DescribeSubnets(&describeSubnetRequest)
+		bootVolume, err := buildBootVolumeTemplate(machine, providerSpec)
 		if err != nil {
-			klog.Errorf("error describing subnetes: %v", err)
-			return nil, fmt.Errorf("error describing subnets: %v", err)
+			return nil, nil, err
 		}
-		for _, n := range describeSubnetResult.Subnets {
-			subnetID := *n.SubnetId
-			subnetIDs = append(subnetIDs, &subnetID)
+		volumes = append(volumes, kubevirtapis.Volume{
+			Name: mainDiskName,
+			VolumeSource: kubevirtapis.VolumeSource{
+				DataVolume: &kubevirtapis.DataVolumeSource{
+					Name: bootVolume.Name,
+				},
+			},
+		})
+		dataVolumeTemplates = append(dataVolumeTemplates, *bootVolume)
+	}
+	var templateAnnotations map[string]string
+	var userDataSecret *corev1.Secret
+
+	if providerSpec.Windows != nil {
+		if err := validateWindows(providerSpec); err != nil {
+			return nil, nil, err
 		}
 	}
-	if len(subnetIDs) == 0 {
-		return nil, fmt.Errorf("no subnet IDs were found")
-	}
-	return subnetIDs, nil
-}
-
-func getAMI(AMI awsproviderv1.AWSResourceReference, client awsclient.Client) (*string, error) {
-	kubevirtclient.Client()
-	if AMI.ID != nil {
-		amiID := AMI.ID
-		klog.Infof("Using AMI %s", *amiID)
-		return amiID, nil
+	format, err := resolveUserDataFormat(providerSpec.UserDataFormat, userData)
+	if err != nil {
+		return nil, nil, err
 	}
-	if len(AMI.Filters) > 0 {
-		klog.Info("Describing AMI based on filters")
-		describeImagesRequest := ec2.DescribeImagesInput{
-			Filters: buildEC2Filters(AMI.Filters),
+	if providerSpec.NetworkData != nil {
+		if format != kubevirtproviderv1.UserDataFormatCloudInit {
+			return nil, nil, fmt.Errorf("networkData requires %s UserData", kubevirtproviderv1.UserDataFormatCloudInit)
 		}
-		describeAMIResult, err := client.DescribeImages(&describeImagesRequest)
-		if err != nil {
-			klog.Errorf("error describing AMI: %v", err)
-			return nil, fmt.Errorf("error describing AMI: %v", err)
-		}
-		if len(describeAMIResult.Images) < 1 {
-			klog.Errorf("no image for given filters not found")
-			return nil, fmt.Errorf("no image for given filters not found")
+		if providerSpec.CloudInitSource == kubevirtproviderv1.CloudInitConfigDrive {
+			return nil, nil, fmt.Errorf("networkData requires the %s cloudInitSource", kubevirtproviderv1.CloudInitNoCloud)
 		}
-		latestImage := describeAMIResult.Images[0]
-		latestTime, err := time.Parse(time.RFC3339, *latestImage.CreationDate)
+	}
+
+	if windows := providerSpec.Windows; windows != nil && windows.Sysprep == nil {
+		// The UserData is run by the rendered answer file, both on the sysprep CD-ROM
+		userDataSecret, err = buildAnswerFileSecret(machine, infraNamespace(machine, providerSpec), windows, userData)
 		if err != nil {
-			klog.Errorf("unable to parse time for %q AMI: %v", *latestImage.ImageId, err)
-			return nil, fmt.Errorf("unable to parse time for %q AMI: %v", *latestImage.ImageId, err)
+			return nil, nil, err
 		}
-		for _, image := range describeAMIResult.Images[1:] {
-			imageTime, err := time.Parse(time.RFC3339, *image.CreationDate)
+		disks = append(disks, buildCDRom(sysprepVolumeName, windowsDiskBus))
+		volumes = append(volumes, buildSysprepVolume(nil, userDataSecret.Name))
+	} else if format == kubevirtproviderv1.UserDataFormatIgnition && providerSpec.CloudInitSource != kubevirtproviderv1.CloudInitConfigDrive {
+		// Ignition configs are handed to the guest through the KubeVirt Ignition
+		// mechanism. Ignition reads config-drive as well, so that one is kept as is.
+		templateAnnotations = map[string]string{
+			kubevirtapis.IgnitionAnnotation: string(userData),
+		}
+	} else {
+		userDataSecret = buildUserDataSecret(machine, infraNamespace(machine, providerSpec), userData)
+		if providerSpec.NetworkData != nil {
+			networkData, err := renderNetworkData(providerSpec.NetworkData, providerSpec.AddressFamilyPolicy)
 			if err != nil {
-				klog.Errorf("unable to parse time for %q AMI: %v", *image.ImageId, err)
-				return nil, fmt.Errorf("unable to parse time for %q AMI: %v", *image.ImageId, err)
+				return nil, nil, err
 			}
-			if latestTime.Before(imageTime) {
-				latestImage = image
-				latestTime = imageTime
+			userDataSecret.Data[cloudInitNetworkDataKey] = networkData
+		}
+		if providerSpec.ShareUserDataSecret {
+			if err := validateSharedUserData(providerSpec); err != nil {
+				return nil, nil, err
 			}
+			if err := shareUserDataSecret(userDataSecret, machine); err != nil {
+				return nil, nil, err
+			}
+		}
+		cloudInitVolume, err := buildCloudInitVolume(providerSpec.CloudInitSource, userDataSecret.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if providerSpec.NetworkData != nil {
+			cloudInitVolume.CloudInitNoCloud.NetworkDataSecretRef = &corev1.LocalObjectReference{
+				Name: userDataSecret.Name,
+			}
+		}
+		if providerSpec.Windows != nil {
+			disks = append(disks, buildDisk(cloudInitVolumeName, windowsDiskBus), buildCDRom(sysprepVolumeName, windowsDiskBus))
+			volumes = append(volumes, *cloudInitVolume, buildSysprepVolume(providerSpec.Windows.Sysprep, ""))
+		} else {
+			disks = append(disks, buildDisk(cloudInitVolumeName, defaultBus))
+			volumes = append(volumes, *cloudInitVolume)
 		}
-		return latestImage.ImageId, nil
 	}
-	return nil, fmt.Errorf("AMI ID or AMI filters need to be specified")
-}
 
-func getBlockDeviceMappings(blockDeviceMappings []awsproviderv1.BlockDeviceMappingSpec, AMI string, client awsclient.Client) ([]*ec2.BlockDeviceMapping, error) {
-	if len(blockDeviceMappings) == 0 || blockDeviceMappings[0].EBS == nil {
-		return []*ec2.BlockDeviceMapping{}, nil
+	if len(providerSpec.ScratchDisks) > 0 {
+		if err := validateScratchDisks(providerSpec); err != nil {
+			return nil, nil, err
+		}
+		scratchDisks, scratchVolumes, err := buildScratchDisks(providerSpec.ScratchDisks, diskBus)
+		if err != nil {
+			return nil, nil, err
+		}
+		disks = append(disks, scratchDisks...)
+		volumes = append(volumes, scratchVolumes...)
 	}
 
-	// Get image object to get the RootDeviceName
-	describeImagesRequest := ec2.DescribeImagesInput{
-		ImageIds: []*string{&AMI},
+	var blockMultiQueue *bool
+	if providerSpec.BlockMultiQueue {
+		blockMultiQueue = &providerSpec.BlockMultiQueue
 	}
-	describeAMIResult, err := client.DescribeImages(&describeImagesRequest)
-	if err != nil {
-		klog.Errorf("Error describing AMI: %v", err)
-		return nil, fmt.Errorf("error describing AMI: %v", err)
-	}
-	if len(describeAMIResult.Images) < 1 {
-		klog.Errorf("No image for given AMI was found")
-		return nil, fmt.Errorf("no image for given AMI not found")
-	}
-	deviceName := describeAMIResult.Images[0].RootDeviceName
-
-	// Only support one blockDeviceMapping
-	volumeSize := blockDeviceMappings[0].EBS.VolumeSize
-	volumeType := blockDeviceMappings[0].EBS.VolumeType
-	blockDeviceMapping := ec2.BlockDeviceMapping{
-		DeviceName: deviceName,
-		Ebs: &ec2.EbsBlockDevice{
-			VolumeSize: volumeSize,
-			VolumeType: volumeType,
-			Encrypted:  blockDeviceMappings[0].EBS.Encrypted,
-		},
+
+	var watchdog *kubevirtapis.Watchdog
+	if providerSpec.Watchdog != nil {
+		if err := validateWatchdog(providerSpec); err != nil {
+			return nil, nil, err
+		}
+		watchdog = buildWatchdog(providerSpec.Watchdog)
 	}
 
-	if aws.StringValue(blockDeviceMappings[0].EBS.KMSKey.ID) != "" {
-		klog.V(3).Infof("Using KMS key ID %q for encrypting EBS volume", *blockDeviceMappings[0].EBS.KMSKey.ID)
-		blockDeviceMapping.Ebs.KmsKeyId = blockDeviceMappings[0].EBS.KMSKey.ID
-	} else if aws.StringValue(blockDeviceMappings[0].EBS.KMSKey.ARN) != "" {
-		klog.V(3).Info("Using KMS key ARN for encrypting EBS volume") // ARN usually have account ids, therefore are sensitive data so shouldn't log the value
-		blockDeviceMapping.Ebs.KmsKeyId = blockDeviceMappings[0].EBS.KMSKey.ARN
+	// The virtio RNG device keeps guests from starving for entropy early in boot
+	var rng *kubevirtapis.Rng
+	if !providerSpec.DisableRNG {
+		rng = &kubevirtapis.Rng{}
 	}
 
-	if *volumeType == "io1" {
-		blockDeviceMapping.Ebs.Iops = blockDeviceMappings[0].EBS.Iops
+	if providerSpec.AdvancedTuning != nil {
+		if err := validateAdvancedTuning(providerSpec.AdvancedTuning); err != nil {
+			return nil, nil, err
+		}
+		if templateAnnotations == nil {
+			templateAnnotations = map[string]string{}
+		}
+		for key, value := range providerSpec.AdvancedTuning.Annotations {
+			templateAnnotations[key] = value
+		}
 	}
 
-	return []*ec2.BlockDeviceMapping{&blockDeviceMapping}, nil
-}
+	if serialConsoleLog := providerSpec.SerialConsoleLog; serialConsoleLog != nil && serialConsoleLog.SidecarImage != "" {
+		if templateAnnotations == nil {
+			templateAnnotations = map[string]string{}
+		}
+		if err := addSerialConsoleSidecar(templateAnnotations, serialConsoleLog.SidecarImage); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if providerSpec.LauncherSecurity != nil {
+		if err := validateLauncherSecurity(providerSpec.LauncherSecurity); err != nil {
+			return nil, nil, err
+		}
+		if templateAnnotations == nil {
+			templateAnnotations = map[string]string{}
+		}
+		setLauncherSecurityAnnotations(templateAnnotations, providerSpec.LauncherSecurity)
+	}
 
-func createVm(machine *machinev1.Machine, machineProviderConfig *awsproviderv1.AWSMachineProviderConfig, userData []byte, client awsclient.Client) (*ec2.Instance, error) {
-	amiID, err := getAMI(machineProviderConfig.AMI, client)
+	if providerSpec.FailureDomain != nil {
+		if err := validateFailureDomain(providerSpec.FailureDomain); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	evictionStrategy, err := resolveEvictionStrategy(providerSpec)
 	if err != nil {
-		return nil, mapierrors.InvalidMachineConfiguration("error getting AMI: %v", err)
+		return nil, nil, err
 	}
 
-	securityGroupsIDs, err := getSecurityGroupsIDs(machineProviderConfig.SecurityGroups, client)
+	runStrategy, err := resolveRunStrategy(providerSpec)
 	if err != nil {
-		return nil, mapierrors.InvalidMachineConfiguration("error getting security groups IDs: %v", err)
+		return nil, nil, err
 	}
-	subnetIDs, err := getSubnetIDs(machineProviderConfig.Subnet, machineProviderConfig.Placement.AvailabilityZone, client)
+
+	architecture, err := resolveArchitecture(providerSpec)
 	if err != nil {
-		return nil, mapierrors.InvalidMachineConfiguration("error getting subnet IDs: %v", err)
+		return nil, nil, err
 	}
-	if len(subnetIDs) > 1 {
-		klog.Warningf("More than one subnet id returned, only first one will be used")
+
+	vmLabels := map[string]string{
+		kubevirtapis.VirtualMachineLabel: machine.Name,
+	}
+	if providerSpec.ControlPlaneLoadBalancer != nil {
+		vmLabels[controlPlaneServiceLabel] = providerSpec.ControlPlaneLoadBalancer.ServiceName
 	}
 
-	// build list of networkInterfaces (just 1 for now)
-	var networkInterfaces = []*ec2.InstanceNetworkInterfaceSpecification{
-		{
-			DeviceIndex:              aws.Int64(machineProviderConfig.DeviceIndex),
-			AssociatePublicIpAddress: machineProviderConfig.PublicIP,
-			SubnetId:                 subnetIDs[0],
-			Groups:                   securityGroupsIDs,
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      machine.Name,
+			Namespace: infraNamespace(machine, providerSpec),
+			Labels:    vmLabels,
+		},
+		Spec: kubevirtapis.VirtualMachineSpec{
+			DataVolumeTemplates: dataVolumeTemplates,
+			Template: &kubevirtapis.VirtualMachineInstanceTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      copyStringMap(vmLabels),
+					Annotations: templateAnnotations,
+				},
+				Spec: kubevirtapis.VirtualMachineInstanceSpec{
+					Domain: kubevirtapis.DomainSpec{
+						CPU: &kubevirtapis.CPU{
+							Cores:                 requestedCPU,
+							DedicatedCPUPlacement: providerSpec.DedicatedCPUPlacement,
+						},
+						Resources: kubevirtapis.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceMemory: memory,
+							},
+						},
+						Devices: kubevirtapis.Devices{
+							Disks:           disks,
+							BlockMultiQueue: blockMultiQueue,
+							Rng:             rng,
+							Watchdog:        watchdog,
+						},
+					},
+					// KubeVirt renders the hostname as local-hostname into the instance
+					// metadata, the instance-id is derived from the VMI name.
+					Hostname:         guestHostname(machine.Name),
+					Affinity:         providerSpec.Affinity.DeepCopy(),
+					NodeSelector:     copyStringMap(providerSpec.NodeSelector),
+					Tolerations:      copyTolerations(providerSpec.Tolerations),
+					EvictionStrategy: evictionStrategy,
+					Volumes:          volumes,
+				},
+			},
 		},
 	}
 
-	blockDeviceMappings, err := getBlockDeviceMappings(machineProviderConfig.BlockDevices, *amiID, client)
-	if err != nil {
-		return nil, mapierrors.InvalidMachineConfiguration("error getting blockDeviceMappings: %v", err)
+	applyRunStrategy(vm, runStrategy)
+
+	if len(providerSpec.Interfaces) > 0 {
+		if err := validateNetworkInterfaces(providerSpec); err != nil {
+			return nil, nil, err
+		}
+		applyNetworkInterfaces(&vm.Spec.Template.Spec, providerSpec.Interfaces)
 	}
 
-	clusterID, ok := getClusterID(machine)
-	if !ok {
-		klog.Errorf("Unable to get cluster ID for machine: %q", machine.Name)
-		return nil, mapierrors.InvalidMachineConfiguration("Unable to get cluster ID for machine: %q", machine.Name)
+	if providerSpec.Windows != nil {
+		applyWindows(&vm.Spec.Template.Spec, providerSpec.Windows)
 	}
-	// Add tags to the created machine
-	rawTagList := []*ec2.Tag{}
-	for _, tag := range machineProviderConfig.Tags {
-		rawTagList = append(rawTagList, &ec2.Tag{Key: aws.String(tag.Name), Value: aws.String(tag.Value)})
+
+	if providerSpec.Clock != nil {
+		if err := validateClock(providerSpec); err != nil {
+			return nil, nil, err
+		}
+		applyClock(&vm.Spec.Template.Spec, providerSpec.Clock)
 	}
-	rawTagList = append(rawTagList, []*ec2.Tag{
-		{Key: aws.String("kubernetes.io/cluster/" + clusterID), Value: aws.String("owned")},
-		{Key: aws.String("Name"), Value: aws.String(machine.Name)},
-	}...)
-	tagList := removeDuplicatedTags(rawTagList)
-	tagInstance := &ec2.TagSpecification{
-		ResourceType: aws.String("instance"),
-		Tags:         tagList,
+
+	if providerSpec.Architecture != "" {
+		applyArchitecture(&vm.Spec.Template.Spec, architecture)
 	}
-	tagVolume := &ec2.TagSpecification{
-		ResourceType: aws.String("volume"),
-		Tags:         tagList,
+
+	if providerSpec.SMBIOS != nil {
+		if err := validateSMBIOS(providerSpec.SMBIOS); err != nil {
+			return nil, nil, err
+		}
+		applySMBIOS(&vm.Spec.Template.Spec, machine, providerSpec.SMBIOS)
 	}
 
-	userDataEnc := base64.StdEncoding.EncodeToString(userData)
+	if providerSpec.FailureDomain != nil {
+		applyFailureDomain(vm.Spec.Template, machine, providerSpec.FailureDomain)
+	}
 
-	var iamInstanceProfile *ec2.IamInstanceProfileSpecification
-	if machineProviderConfig.IAMInstanceProfile != nil && machineProviderConfig.IAMInstanceProfile.ID != nil {
-		iamInstanceProfile = &ec2.IamInstanceProfileSpecification{
-			Name: aws.String(*machineProviderConfig.IAMInstanceProfile.ID),
+	if providerSpec.Preemptible != nil {
+		if err := validatePreemptible(providerSpec); err != nil {
+			return nil, nil, err
 		}
+		applyPreemptible(vm.Spec.Template, providerSpec.Preemptible)
 	}
 
-	var placement *ec2.Placement
-	if machineProviderConfig.Placement.AvailabilityZone != "" && machineProviderConfig.Subnet.ID == nil {
-		placement = &ec2.Placement{
-			AvailabilityZone: aws.String(machineProviderConfig.Placement.AvailabilityZone),
+	if providerSpec.Hugepages != nil {
+		if err := validateHugepages(providerSpec); err != nil {
+			return nil, nil, err
 		}
+		setHugepages(&vm.Spec.Template.Spec, providerSpec.Hugepages)
 	}
 
-	inputConfig := ec2.RunInstancesInput{
-		ImageId:      amiID,
-		InstanceType: aws.String(machineProviderConfig.InstanceType),
-		// Only a single instance of the AWS instance allowed
-		MinCount:              aws.Int64(1),
-		MaxCount:              aws.Int64(1),
-		KeyName:               machineProviderConfig.KeyName,
-		IamInstanceProfile:    iamInstanceProfile,
-		TagSpecifications:     []*ec2.TagSpecification{tagInstance, tagVolume},
-		NetworkInterfaces:     networkInterfaces,
-		UserData:              &userDataEnc,
-		Placement:             placement,
-		InstanceMarketOptions: getInstanceMarketOptionsRequest(machineProviderConfig),
+	if providerSpec.GuestMemory != "" {
+		if err := validateGuestMemory(providerSpec); err != nil {
+			return nil, nil, err
+		}
+		if err := applyGuestMemory(&vm.Spec.Template.Spec, providerSpec.GuestMemory); err != nil {
+			return nil, nil, err
+		}
 	}
+	vm.Spec.Template.Spec.Domain.Resources.OvercommitGuestOverhead = providerSpec.OvercommitGuestOverhead
 
-	if len(blockDeviceMappings) > 0 {
-		inputConfig.BlockDeviceMappings = blockDeviceMappings
+	if providerSpec.GracefulShutdownTimeout != nil {
+		if err := validateGracefulShutdownTimeout(providerSpec.GracefulShutdownTimeout); err != nil {
+			return nil, nil, err
+		}
+		setTerminationGracePeriod(&vm.Spec.Template.Spec, providerSpec.GracefulShutdownTimeout.Duration)
 	}
-	runResult, err := client.RunInstances(&inputConfig)
+
+	limits, err := launcherLimits(providerSpec)
 	if err != nil {
-		// we return InvalidMachineConfiguration for 4xx errors which by convention signal client misconfiguration
-		// https://tools.ietf.org/html/rfc2616#section-6.1.1
-		// https: //docs.aws.amazon.com/AWSEC2/latest/APIReference/errors-overview.html
-		// https://docs.aws.amazon.com/sdk-for-go/api/aws/awserr/
-		if _, ok := err.(awserr.Error); ok {
-			if reqErr, ok := err.(awserr.RequestFailure); ok {
-				if strings.HasPrefix(strconv.Itoa(reqErr.StatusCode()), "4") {
-					klog.Infof("Error launching instance: %v", reqErr)
-					return nil, mapierrors.InvalidMachineConfiguration("error launching instance: %v", reqErr.Message())
-				}
-			}
+		return nil, nil, err
+	}
+	if limits != nil {
+		setLauncherLimits(&vm.Spec.Template.Spec, limits)
+	}
+
+	return vm, userDataSecret, nil
+}
+
+// resolveDiskBus returns the bus of the root disk, validating the multi-queue setting against
+// it and the vCPU count. KubeVirt only applies multi-queue to virtio-blk, with one queue per vCPU.
+func resolveDiskBus(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, requestedCPU uint32) (kubevirtproviderv1.DiskBus, error) {
+	bus := providerSpec.DiskBus
+	switch bus {
+	case "":
+		bus = defaultBus
+		if providerSpec.Windows != nil {
+			bus = windowsDiskBus
 		}
-		klog.Errorf("Error creating EC2 instance: %v", err)
-		return nil, mapierrors.CreateMachine("error creating EC2 instance: %v", err)
+	case kubevirtproviderv1.DiskBusVirtio, kubevirtproviderv1.DiskBusSCSI, kubevirtproviderv1.DiskBusSATA:
+	default:
+		return "", fmt.Errorf("unsupported diskBus %q, must be one of %q, %q or %q", bus, kubevirtproviderv1.DiskBusVirtio, kubevirtproviderv1.DiskBusSCSI, kubevirtproviderv1.DiskBusSATA)
 	}
 
-	if runResult == nil || len(runResult.Instances) != 1 {
-		klog.Errorf("Unexpected reservation creating instances: %v", runResult)
-		return nil, mapierrors.CreateMachine("unexpected reservation creating instance")
+	if providerSpec.BlockMultiQueue {
+		if bus != kubevirtproviderv1.DiskBusVirtio {
+			return "", fmt.Errorf("blockMultiQueue requires diskBus %q, got %q", kubevirtproviderv1.DiskBusVirtio, bus)
+		}
+		if requestedCPU < 2 {
+			return "", fmt.Errorf("blockMultiQueue requires at least 2 vCPUs, got %d", requestedCPU)
+		}
 	}
 
-	return runResult.Instances[0], nil
+	return bus, nil
 }
 
-type instanceList []*ec2.Instance
+// buildBootVolumeTemplate renders the DataVolume cloning the source PVC into the root disk of the VM.
+func buildBootVolumeTemplate(machine *machinev1.Machine, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) (*cdiv1.DataVolume, error) {
+	requestedStorage := providerSpec.RequestedStorage
+	if requestedStorage == "" {
+		requestedStorage = defaultRequestedStorage
+	}
+	storage, err := resource.ParseQuantity(requestedStorage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid requestedStorage %q: %v", requestedStorage, err)
+	}
+
+	accessMode, err := resolveRootVolumeAccessMode(providerSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	pvcSpec := &corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{
+			accessMode,
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: storage,
+			},
+		},
+	}
+	if providerSpec.StorageClassName != "" {
+		storageClassName := providerSpec.StorageClassName
+		pvcSpec.StorageClassName = &storageClassName
+	}
 
-func (il instanceList) Len() int {
-	return len(il)
+	return &cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      machine.Name + bootVolumeSuffix,
+			Namespace: infraNamespace(machine, providerSpec),
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Source: cdiv1.DataVolumeSource{
+				PVC: &cdiv1.DataVolumeSourcePVC{
+					Name:      providerSpec.SourcePvcName,
+					Namespace: infraNamespace(machine, providerSpec),
+				},
+			},
+			PVC: pvcSpec,
+		},
+	}, nil
 }
 
-func (il instanceList) Swap(i, j int) {
-	il[i], il[j] = il[j], il[i]
+// buildUserDataSecret renders the secret holding the user data next to the VM, in namespace.
+func buildUserDataSecret(machine *machinev1.Machine, namespace string, userData []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      machine.Name + userDataSecretSuffix,
+			Namespace: namespace,
+			Labels: map[string]string{
+				kubevirtapis.VirtualMachineLabel: machine.Name,
+			},
+		},
+		Data: map[string][]byte{
+			cloudInitUserDataKey: userData,
+		},
+	}
 }
 
-func (il instanceList) Less(i, j int) bool {
-	if il[i].LaunchTime == nil && il[j].LaunchTime == nil {
-		return false
+// buildCloudInitVolume renders the volume exposing the user data secret to the guest
+// through the cloud-init datasource selected in the provider spec.
+func buildCloudInitVolume(source kubevirtproviderv1.CloudInitSource, userDataSecretName string) (*kubevirtapis.Volume, error) {
+	userDataSecretRef := &corev1.LocalObjectReference{
+		Name: userDataSecretName,
 	}
-	if il[i].LaunchTime != nil && il[j].LaunchTime == nil {
-		return false
+
+	volume := &kubevirtapis.Volume{
+		Name: cloudInitVolumeName,
 	}
-	if il[i].LaunchTime == nil && il[j].LaunchTime != nil {
-		return true
+
+	switch source {
+	case "", kubevirtproviderv1.CloudInitNoCloud:
+		volume.CloudInitNoCloud = &kubevirtapis.CloudInitNoCloudSource{
+			UserDataSecretRef: userDataSecretRef,
+		}
+	case kubevirtproviderv1.CloudInitConfigDrive:
+		volume.CloudInitConfigDrive = &kubevirtapis.CloudInitConfigDriveSource{
+			UserDataSecretRef: userDataSecretRef,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cloudInitSource %q, must be one of %q or %q", source, kubevirtproviderv1.CloudInitNoCloud, kubevirtproviderv1.CloudInitConfigDrive)
 	}
-	return (*il[i].LaunchTime).After(*il[j].LaunchTime)
-}
 
-// sortInstances will sort a list of instance based on an instace launch time
-// from the newest to the oldest.
-// This function should only be called with running instances, not those which are stopped or
-// terminated.
-func sortInstances(instances []*ec2.Instance) {
-	sort.Sort(instanceList(instances))
+	return volume, nil
 }
 
-func getInstanceMarketOptionsRequest(providerConfig *awsproviderv1.AWSMachineProviderConfig) *ec2.InstanceMarketOptionsRequest {
-	if providerConfig.SpotMarketOptions == nil {
-		// Instance is not a Spot instance
-		return nil
+// resolveUserDataFormat returns the declared format of the user data, or detects it
+// from the content when none is declared. Ignition configs are JSON documents
+// carrying an ignition.version field.
+func resolveUserDataFormat(declared kubevirtproviderv1.UserDataFormat, userData []byte) (kubevirtproviderv1.UserDataFormat, error) {
+	switch declared {
+	case kubevirtproviderv1.UserDataFormatCloudInit, kubevirtproviderv1.UserDataFormatIgnition:
+		return declared, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported userDataFormat %q, must be one of %q or %q", declared, kubevirtproviderv1.UserDataFormatCloudInit, kubevirtproviderv1.UserDataFormatIgnition)
+	}
+
+	ignitionConfig := struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+	}{}
+	if err := json.Unmarshal(userData, &ignitionConfig); err == nil && ignitionConfig.Ignition.Version != "" {
+		return kubevirtproviderv1.UserDataFormatIgnition, nil
 	}
 
-	// Set required values for Spot instances
-	spotOptions := &ec2.SpotMarketOptions{}
-	// The following two options ensure that:
-	// - If an instance is interrupted, it is terminated rather than hibernating or stopping
-	// - No replacement instance will be created if the instance is interrupted
-	// - If the spot request cannot immediately be fulfilled, it will not be created
-	// This behaviour should satisfy the 1:1 mapping of Machines to Instances as
-	// assumed by the machine API.
-	spotOptions.SetInstanceInterruptionBehavior(ec2.InstanceInterruptionBehaviorTerminate)
-	spotOptions.SetSpotInstanceType(ec2.SpotInstanceTypeOneTime)
+	return kubevirtproviderv1.UserDataFormatCloudInit, nil
+}
 
-	// Set the MaxPrice if specified by the providerConfig
-	maxPrice := providerConfig.SpotMarketOptions.MaxPrice
-	if maxPrice != nil && *maxPrice != "" {
-		spotOptions.SetMaxPrice(*maxPrice)
+func copyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for key, value := range in {
+		out[key] = value
 	}
+	return out
+}
 
-	instanceMarketOptionsRequest := &ec2.InstanceMarketOptionsRequest{}
-	instanceMarketOptionsRequest.SetMarketType(ec2.MarketTypeSpot)
-	instanceMarketOptionsRequest.SetSpotOptions(spotOptions)
+func copyTolerations(in []corev1.Toleration) []corev1.Toleration {
+	if in == nil {
+		return nil
+	}
+	out := make([]corev1.Toleration, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
 
-	return instanceMarketOptionsRequest
+func buildDisk(name string, bus kubevirtproviderv1.DiskBus) kubevirtapis.Disk {
+	return kubevirtapis.Disk{
+		Name: name,
+		DiskDevice: kubevirtapis.DiskDevice{
+			Disk: &kubevirtapis.DiskTarget{
+				Bus: string(bus),
+			},
+		},
+	}
 }
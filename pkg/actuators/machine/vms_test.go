@@ -0,0 +1,370 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestBuildCloudInitVolume(t *testing.T) {
+	userDataSecretRef := &corev1.LocalObjectReference{
+		Name: "kubevirt-test-userdata",
+	}
+
+	testCases := []struct {
+		testcase       string
+		source         kubevirtproviderv1.CloudInitSource
+		expectedVolume *kubevirtapis.Volume
+		expectError    bool
+	}{
+		{
+			testcase: "default to NoCloud",
+			source:   "",
+			expectedVolume: &kubevirtapis.Volume{
+				Name: cloudInitVolumeName,
+				VolumeSource: kubevirtapis.VolumeSource{
+					CloudInitNoCloud: &kubevirtapis.CloudInitNoCloudSource{
+						UserDataSecretRef: userDataSecretRef,
+					},
+				},
+			},
+		},
+		{
+			testcase: "NoCloud",
+			source:   kubevirtproviderv1.CloudInitNoCloud,
+			expectedVolume: &kubevirtapis.Volume{
+				Name: cloudInitVolumeName,
+				VolumeSource: kubevirtapis.VolumeSource{
+					CloudInitNoCloud: &kubevirtapis.CloudInitNoCloudSource{
+						UserDataSecretRef: userDataSecretRef,
+					},
+				},
+			},
+		},
+		{
+			testcase: "ConfigDrive",
+			source:   kubevirtproviderv1.CloudInitConfigDrive,
+			expectedVolume: &kubevirtapis.Volume{
+				Name: cloudInitVolumeName,
+				VolumeSource: kubevirtapis.VolumeSource{
+					CloudInitConfigDrive: &kubevirtapis.CloudInitConfigDriveSource{
+						UserDataSecretRef: userDataSecretRef,
+					},
+				},
+			},
+		},
+		{
+			testcase:    "unsupported source",
+			source:      "Ignition",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			volume, err := buildCloudInitVolume(tc.source, userDataSecretRef.Name)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for source %q, got nil", tc.source)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(volume, tc.expectedVolume) {
+				t.Errorf("Expected volume %+v, got %+v", tc.expectedVolume, volume)
+			}
+		})
+	}
+}
+
+func TestResolveUserDataFormat(t *testing.T) {
+	testCases := []struct {
+		testcase       string
+		declared       kubevirtproviderv1.UserDataFormat
+		userData       string
+		expectedFormat kubevirtproviderv1.UserDataFormat
+		expectError    bool
+	}{
+		{
+			testcase:       "detect cloud-config",
+			userData:       "#cloud-config\npassword: secret\n",
+			expectedFormat: kubevirtproviderv1.UserDataFormatCloudInit,
+		},
+		{
+			testcase:       "detect ignition",
+			userData:       `{"ignition":{"version":"3.1.0"}}`,
+			expectedFormat: kubevirtproviderv1.UserDataFormatIgnition,
+		},
+		{
+			testcase:       "detect json without ignition version",
+			userData:       `{"foo":"bar"}`,
+			expectedFormat: kubevirtproviderv1.UserDataFormatCloudInit,
+		},
+		{
+			testcase:       "declared overrides content",
+			declared:       kubevirtproviderv1.UserDataFormatCloudInit,
+			userData:       `{"ignition":{"version":"3.1.0"}}`,
+			expectedFormat: kubevirtproviderv1.UserDataFormatCloudInit,
+		},
+		{
+			testcase:    "unsupported format",
+			declared:    "Butane",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			format, err := resolveUserDataFormat(tc.declared, []byte(tc.userData))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for format %q, got nil", tc.declared)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if format != tc.expectedFormat {
+				t.Errorf("Expected format %q, got %q", tc.expectedFormat, format)
+			}
+		})
+	}
+}
+
+func TestBuildVMUserData(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubevirt-test",
+			Namespace: "kubevirt-test",
+		},
+	}
+	ignition := []byte(`{"ignition":{"version":"3.1.0"}}`)
+
+	testCases := []struct {
+		testcase            string
+		cloudInitSource     kubevirtproviderv1.CloudInitSource
+		userData            []byte
+		expectIgnition      bool
+		expectCloudInitDisk bool
+	}{
+		{
+			testcase:            "cloud-init",
+			userData:            []byte("#cloud-config\n"),
+			expectCloudInitDisk: true,
+		},
+		{
+			testcase:       "ignition",
+			userData:       ignition,
+			expectIgnition: true,
+		},
+		{
+			testcase:            "ignition over config-drive",
+			cloudInitSource:     kubevirtproviderv1.CloudInitConfigDrive,
+			userData:            ignition,
+			expectCloudInitDisk: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			vm, userDataSecret, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:   "rhcos",
+				CloudInitSource: tc.cloudInitSource,
+			}, tc.userData)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, hasIgnition := vm.Spec.Template.ObjectMeta.Annotations[kubevirtapis.IgnitionAnnotation]
+			if hasIgnition != tc.expectIgnition {
+				t.Errorf("Expected ignition annotation: %v, got: %v", tc.expectIgnition, hasIgnition)
+			}
+
+			hasCloudInitDisk := false
+			for _, volume := range vm.Spec.Template.Spec.Volumes {
+				if volume.Name == cloudInitVolumeName {
+					hasCloudInitDisk = true
+				}
+			}
+			if hasCloudInitDisk != tc.expectCloudInitDisk {
+				t.Errorf("Expected cloud-init volume: %v, got: %v", tc.expectCloudInitDisk, hasCloudInitDisk)
+			}
+
+			if hasUserDataSecret := userDataSecret != nil; hasUserDataSecret != tc.expectCloudInitDisk {
+				t.Fatalf("Expected UserData secret: %v, got: %v", tc.expectCloudInitDisk, hasUserDataSecret)
+			}
+			if userDataSecret != nil && string(userDataSecret.Data[cloudInitUserDataKey]) != string(tc.userData) {
+				t.Errorf("Expected UserData secret to hold %q, got %q", tc.userData, userDataSecret.Data[cloudInitUserDataKey])
+			}
+		})
+	}
+}
+
+func TestBuildVMDiskBus(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubevirt-test",
+			Namespace: "kubevirt-test",
+		},
+	}
+
+	testCases := []struct {
+		testcase         string
+		diskBus          kubevirtproviderv1.DiskBus
+		blockMultiQueue  bool
+		requestedCPU     uint32
+		expectedBus      string
+		expectMultiQueue bool
+		expectError      bool
+	}{
+		{
+			testcase:    "default",
+			expectedBus: "virtio",
+		},
+		{
+			testcase:    "scsi",
+			diskBus:     kubevirtproviderv1.DiskBusSCSI,
+			expectedBus: "scsi",
+		},
+		{
+			testcase:         "multi-queue",
+			blockMultiQueue:  true,
+			requestedCPU:     4,
+			expectedBus:      "virtio",
+			expectMultiQueue: true,
+		},
+		{
+			testcase:        "multi-queue with a single vCPU",
+			blockMultiQueue: true,
+			expectError:     true,
+		},
+		{
+			testcase:        "multi-queue over scsi",
+			diskBus:         kubevirtproviderv1.DiskBusSCSI,
+			blockMultiQueue: true,
+			requestedCPU:    4,
+			expectError:     true,
+		},
+		{
+			testcase:    "unsupported bus",
+			diskBus:     "ide",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+				SourcePvcName:   "rhcos",
+				RequestedCPU:    tc.requestedCPU,
+				DiskBus:         tc.diskBus,
+				BlockMultiQueue: tc.blockMultiQueue,
+			}, []byte("#cloud-config\n"))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			devices := vm.Spec.Template.Spec.Domain.Devices
+			if bus := devices.Disks[0].Disk.Bus; bus != tc.expectedBus {
+				t.Errorf("Expected root disk bus %q, got %q", tc.expectedBus, bus)
+			}
+			if multiQueue := devices.BlockMultiQueue != nil && *devices.BlockMultiQueue; multiQueue != tc.expectMultiQueue {
+				t.Errorf("Expected blockMultiQueue: %v, got: %v", tc.expectMultiQueue, multiQueue)
+			}
+		})
+	}
+}
+
+func TestBuildVMScheduling(t *testing.T) {
+	machine := &machinev1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubevirt-test",
+			Namespace: "kubevirt-test",
+		},
+	}
+	affinity := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+				{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"machine.openshift.io/cluster-api-machine-role": "master"},
+					},
+					TopologyKey: "kubernetes.io/hostname",
+				},
+			},
+		},
+	}
+
+	nodeSelector := map[string]string{"node-role.kubernetes.io/gpu": ""}
+	tolerations := []corev1.Toleration{
+		{
+			Key:      "nvidia.com/gpu",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+		Affinity:      affinity,
+		NodeSelector:  nodeSelector,
+		Tolerations:   tolerations,
+	}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(vm.Spec.Template.Spec.Affinity, affinity) {
+		t.Errorf("Expected affinity %+v, got %+v", affinity, vm.Spec.Template.Spec.Affinity)
+	}
+	if !equality.Semantic.DeepEqual(vm.Spec.Template.Spec.NodeSelector, nodeSelector) {
+		t.Errorf("Expected node selector %v, got %v", nodeSelector, vm.Spec.Template.Spec.NodeSelector)
+	}
+	if !equality.Semantic.DeepEqual(vm.Spec.Template.Spec.Tolerations, tolerations) {
+		t.Errorf("Expected tolerations %+v, got %+v", tolerations, vm.Spec.Template.Spec.Tolerations)
+	}
+
+	vm, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "rhcos",
+	}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vm.Spec.Template.Spec.Affinity != nil || vm.Spec.Template.Spec.NodeSelector != nil || vm.Spec.Template.Spec.Tolerations != nil {
+		t.Errorf("Expected no scheduling constraints, got %+v", vm.Spec.Template.Spec)
+	}
+}
+
+func TestBuildVMRng(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vm.Spec.Template.Spec.Domain.Devices.Rng == nil {
+		t.Errorf("Expected a virtio RNG device by default")
+	}
+
+	vm, _, err = buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", DisableRNG: true}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rng := vm.Spec.Template.Spec.Domain.Devices.Rng; rng != nil {
+		t.Errorf("Expected no RNG device with disableRNG, got %v", rng)
+	}
+}
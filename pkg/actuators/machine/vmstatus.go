@@ -0,0 +1,125 @@
+package machine
+
+import (
+	"fmt"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	machinecontroller "github.com/openshift/machine-api-operator/pkg/controller/machine"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// vmRunningCondition returns whether the VirtualMachineInstance of the VM is running.
+func vmRunningCondition(vmi *kubevirtapis.VirtualMachineInstance) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	if vmi == nil {
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.VMRunning,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.VMINotRunning,
+			Message: "VirtualMachine is stopped",
+		}
+	}
+	if vmi.Status.Phase != kubevirtapis.Running {
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.VMRunning,
+			Status:  corev1.ConditionFalse,
+			Reason:  kubevirtproviderv1.VMINotRunning,
+			Message: fmt.Sprintf("VirtualMachineInstance is in phase %s", vmi.Status.Phase),
+		}
+	}
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.VMRunning,
+		Status:  corev1.ConditionTrue,
+		Reason:  kubevirtproviderv1.VMIRunning,
+		Message: fmt.Sprintf("VirtualMachineInstance is running on infra node %s", vmi.Status.NodeName),
+	}
+}
+
+// vmErroredCondition returns whether KubeVirt reports a failure of the VM or of its
+// VirtualMachineInstance.
+func vmErroredCondition(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) kubevirtproviderv1.KubevirtMachineProviderCondition {
+	for _, condition := range vm.Status.Conditions {
+		if condition.Type == kubevirtapis.VirtualMachineFailure && condition.Status == corev1.ConditionTrue {
+			return kubevirtproviderv1.KubevirtMachineProviderCondition{
+				Type:    kubevirtproviderv1.VMErrored,
+				Status:  corev1.ConditionTrue,
+				Reason:  kubevirtproviderv1.VMFailure,
+				Message: fmt.Sprintf("VirtualMachine failed: %s: %s", condition.Reason, condition.Message),
+			}
+		}
+	}
+	if vmi != nil && vmi.Status.Phase == kubevirtapis.Failed {
+		return kubevirtproviderv1.KubevirtMachineProviderCondition{
+			Type:    kubevirtproviderv1.VMErrored,
+			Status:  corev1.ConditionTrue,
+			Reason:  kubevirtproviderv1.VMFailure,
+			Message: "VirtualMachineInstance failed",
+		}
+	}
+	return kubevirtproviderv1.KubevirtMachineProviderCondition{
+		Type:    kubevirtproviderv1.VMErrored,
+		Status:  corev1.ConditionFalse,
+		Reason:  kubevirtproviderv1.NoVMFailure,
+		Message: "VirtualMachine reports no failure",
+	}
+}
+
+// vmState returns the printable state of the VM.
+func vmState(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) kubevirtproviderv1.VMState {
+	if vm.DeletionTimestamp != nil {
+		return kubevirtproviderv1.VMStateTerminating
+	}
+	if vmi == nil {
+		if vmHalted(vm) || (vm.Spec.Running != nil && !*vm.Spec.Running) {
+			return kubevirtproviderv1.VMStateStopped
+		}
+		return kubevirtproviderv1.VMStateStarting
+	}
+	if vmi.DeletionTimestamp != nil {
+		return kubevirtproviderv1.VMStateStopping
+	}
+
+	switch vmi.Status.Phase {
+	case kubevirtapis.Running:
+		for _, condition := range vmi.Status.Conditions {
+			if condition.Type == kubevirtapis.VirtualMachineInstancePaused && condition.Status == corev1.ConditionTrue {
+				return kubevirtproviderv1.VMStatePaused
+			}
+		}
+		if migration := vmi.Status.MigrationState; migration != nil && !migration.Completed && !migration.Failed {
+			return kubevirtproviderv1.VMStateMigrating
+		}
+		return kubevirtproviderv1.VMStateRunning
+	case kubevirtapis.Failed:
+		return kubevirtproviderv1.VMStateCrashLoopBackOff
+	case kubevirtapis.Succeeded:
+		return kubevirtproviderv1.VMStateStopped
+	default:
+		return kubevirtproviderv1.VMStateStarting
+	}
+}
+
+// setInstanceState sets the printable state of the VM as instance-state annotation of the
+// machine, which the machine API prints as its State.
+func setInstanceState(machine *machinev1.Machine, state kubevirtproviderv1.VMState) {
+	if machine.Annotations == nil {
+		machine.Annotations = map[string]string{}
+	}
+	machine.Annotations[machinecontroller.MachineInstanceStateAnnotationName] = string(state)
+}
+
+// setVMStatus records the UID of the VM, the phase of its VirtualMachineInstance, the printable
+// state and the conditions derived from them in the provider status.
+func (s *machineScope) setVMStatus(vm *kubevirtapis.VirtualMachine, vmi *kubevirtapis.VirtualMachineInstance) {
+	s.providerStatus.VMID = string(vm.UID)
+	s.providerStatus.Phase = ""
+	if vmi != nil {
+		s.providerStatus.Phase = string(vmi.Status.Phase)
+	}
+	s.providerStatus.VMState = vmState(vm, vmi)
+	setInstanceState(s.machine, s.providerStatus.VMState)
+	s.setProviderStatus(vmRunningCondition(vmi))
+	s.setProviderStatus(vmErroredCondition(vm, vmi))
+}
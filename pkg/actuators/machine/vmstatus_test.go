@@ -0,0 +1,176 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestSetVMStatus(t *testing.T) {
+	vm := &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", UID: "1234"},
+	}
+	failedVM := vm.DeepCopy()
+	failedVM.Status.Conditions = []kubevirtapis.VirtualMachineCondition{
+		{Type: kubevirtapis.VirtualMachineFailure, Status: corev1.ConditionTrue, Reason: "FailedCreate", Message: "quota exceeded"},
+	}
+
+	testCases := []struct {
+		testcase        string
+		vm              *kubevirtapis.VirtualMachine
+		vmi             *kubevirtapis.VirtualMachineInstance
+		expectedPhase   string
+		expectedRunning corev1.ConditionStatus
+		expectedErrored corev1.ConditionStatus
+	}{
+		{
+			testcase:        "stopped VM",
+			vm:              vm,
+			expectedRunning: corev1.ConditionFalse,
+			expectedErrored: corev1.ConditionFalse,
+		},
+		{
+			testcase: "running VM",
+			vm:       vm,
+			vmi: &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running, NodeName: "infra-1"},
+			},
+			expectedPhase:   "Running",
+			expectedRunning: corev1.ConditionTrue,
+			expectedErrored: corev1.ConditionFalse,
+		},
+		{
+			testcase: "failed VMI",
+			vm:       vm,
+			vmi: &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Failed},
+			},
+			expectedPhase:   "Failed",
+			expectedRunning: corev1.ConditionFalse,
+			expectedErrored: corev1.ConditionTrue,
+		},
+		{
+			testcase:        "failed VM",
+			vm:              failedVM,
+			expectedRunning: corev1.ConditionFalse,
+			expectedErrored: corev1.ConditionTrue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			scope := &machineScope{
+				machine:        &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}},
+				providerStatus: &kubevirtproviderv1.KubevirtMachineProviderStatus{},
+			}
+			scope.setVMStatus(tc.vm, tc.vmi)
+
+			if scope.providerStatus.VMID != "1234" {
+				t.Errorf("Expected VM ID 1234, got %q", scope.providerStatus.VMID)
+			}
+			if scope.providerStatus.Phase != tc.expectedPhase {
+				t.Errorf("Expected phase %q, got %q", tc.expectedPhase, scope.providerStatus.Phase)
+			}
+			running := findProviderCondition(scope.providerStatus.Conditions, kubevirtproviderv1.VMRunning)
+			if running == nil || running.Status != tc.expectedRunning {
+				t.Errorf("Expected VMRunning condition %s, got %v", tc.expectedRunning, running)
+			}
+			errored := findProviderCondition(scope.providerStatus.Conditions, kubevirtproviderv1.VMErrored)
+			if errored == nil || errored.Status != tc.expectedErrored {
+				t.Errorf("Expected VMErrored condition %s, got %v", tc.expectedErrored, errored)
+			}
+		})
+	}
+}
+
+func TestVMState(t *testing.T) {
+	running := true
+	stopped := false
+	now := metav1.Now()
+
+	testCases := []struct {
+		testcase      string
+		vm            *kubevirtapis.VirtualMachine
+		vmi           *kubevirtapis.VirtualMachineInstance
+		expectedState kubevirtproviderv1.VMState
+	}{
+		{
+			testcase:      "stopped",
+			vm:            &kubevirtapis.VirtualMachine{Spec: kubevirtapis.VirtualMachineSpec{Running: &stopped}},
+			expectedState: kubevirtproviderv1.VMStateStopped,
+		},
+		{
+			testcase:      "starting without VMI",
+			vm:            &kubevirtapis.VirtualMachine{Spec: kubevirtapis.VirtualMachineSpec{Running: &running}},
+			expectedState: kubevirtproviderv1.VMStateStarting,
+		},
+		{
+			testcase: "scheduling",
+			vm:       &kubevirtapis.VirtualMachine{Spec: kubevirtapis.VirtualMachineSpec{Running: &running}},
+			vmi: &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Scheduling},
+			},
+			expectedState: kubevirtproviderv1.VMStateStarting,
+		},
+		{
+			testcase: "running",
+			vm:       &kubevirtapis.VirtualMachine{Spec: kubevirtapis.VirtualMachineSpec{Running: &running}},
+			vmi: &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Running},
+			},
+			expectedState: kubevirtproviderv1.VMStateRunning,
+		},
+		{
+			testcase: "migrating",
+			vm:       &kubevirtapis.VirtualMachine{Spec: kubevirtapis.VirtualMachineSpec{Running: &running}},
+			vmi: &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{
+					Phase:          kubevirtapis.Running,
+					MigrationState: &kubevirtapis.VirtualMachineInstanceMigrationState{SourceNode: "infra-1"},
+				},
+			},
+			expectedState: kubevirtproviderv1.VMStateMigrating,
+		},
+		{
+			testcase: "paused",
+			vm:       &kubevirtapis.VirtualMachine{Spec: kubevirtapis.VirtualMachineSpec{Running: &running}},
+			vmi: &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{
+					Phase: kubevirtapis.Running,
+					Conditions: []kubevirtapis.VirtualMachineInstanceCondition{
+						{Type: kubevirtapis.VirtualMachineInstancePaused, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+			expectedState: kubevirtproviderv1.VMStatePaused,
+		},
+		{
+			testcase: "failed",
+			vm:       &kubevirtapis.VirtualMachine{Spec: kubevirtapis.VirtualMachineSpec{Running: &running}},
+			vmi: &kubevirtapis.VirtualMachineInstance{
+				Status: kubevirtapis.VirtualMachineInstanceStatus{Phase: kubevirtapis.Failed},
+			},
+			expectedState: kubevirtproviderv1.VMStateCrashLoopBackOff,
+		},
+		{
+			testcase: "terminating",
+			vm: &kubevirtapis.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+			},
+			expectedState: kubevirtproviderv1.VMStateTerminating,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			if state := vmState(tc.vm, tc.vmi); state != tc.expectedState {
+				t.Errorf("Expected state %s, got %s", tc.expectedState, state)
+			}
+		})
+	}
+}
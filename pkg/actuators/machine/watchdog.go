@@ -0,0 +1,42 @@
+package machine
+
+import (
+	"fmt"
+
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// watchdogName is the name of the watchdog device of VMs.
+const watchdogName = "watchdog"
+
+// validateWatchdog returns an error if the watchdog action is unsupported, or the architecture
+// of the VM has no i6300esb device. The shutdown action of KubeVirt is not supported, it asks
+// the guest which hung for a clean shutdown.
+func validateWatchdog(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	switch providerSpec.Watchdog.Action {
+	case "", kubevirtproviderv1.WatchdogActionReset, kubevirtproviderv1.WatchdogActionPoweroff:
+	default:
+		return fmt.Errorf("unsupported watchdog action %q, must be one of %q or %q", providerSpec.Watchdog.Action,
+			kubevirtproviderv1.WatchdogActionReset, kubevirtproviderv1.WatchdogActionPoweroff)
+	}
+	if providerSpec.Architecture == kubevirtproviderv1.ArchitectureARM64 {
+		return fmt.Errorf("watchdog is not supported on architecture %q", providerSpec.Architecture)
+	}
+	return nil
+}
+
+// buildWatchdog renders the i6300esb watchdog device of the VM.
+func buildWatchdog(watchdog *kubevirtproviderv1.Watchdog) *kubevirtapis.Watchdog {
+	action := kubevirtapis.WatchdogActionReset
+	if watchdog.Action == kubevirtproviderv1.WatchdogActionPoweroff {
+		action = kubevirtapis.WatchdogActionPoweroff
+	}
+	return &kubevirtapis.Watchdog{
+		Name: watchdogName,
+		WatchdogDevice: kubevirtapis.WatchdogDevice{
+			I6300ESB: &kubevirtapis.I6300ESBWatchdog{Action: action},
+		},
+	}
+}
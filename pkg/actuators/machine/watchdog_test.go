@@ -0,0 +1,79 @@
+package machine
+
+import (
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestValidateWatchdog(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		action       kubevirtproviderv1.WatchdogAction
+		architecture kubevirtproviderv1.Architecture
+		expectError  bool
+	}{
+		{
+			testcase: "default",
+		},
+		{
+			testcase: "poweroff",
+			action:   kubevirtproviderv1.WatchdogActionPoweroff,
+		},
+		{
+			testcase:    "shutdown",
+			action:      "shutdown",
+			expectError: true,
+		},
+		{
+			testcase:     "arm64",
+			architecture: kubevirtproviderv1.ArchitectureARM64,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateWatchdog(&kubevirtproviderv1.KubevirtMachineProviderSpec{
+				Watchdog:     &kubevirtproviderv1.Watchdog{Action: tc.action},
+				Architecture: tc.architecture,
+			})
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMWatchdog(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a"}}
+	testCases := map[kubevirtproviderv1.WatchdogAction]kubevirtapis.WatchdogAction{
+		"":                                     kubevirtapis.WatchdogActionReset,
+		kubevirtproviderv1.WatchdogActionReset: kubevirtapis.WatchdogActionReset,
+		kubevirtproviderv1.WatchdogActionPoweroff: kubevirtapis.WatchdogActionPoweroff,
+	}
+
+	for action, expected := range testCases {
+		providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", Watchdog: &kubevirtproviderv1.Watchdog{Action: action}}
+		vm, _, err := buildVM(machine, providerSpec, []byte("#cloud-config\n"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		watchdog := vm.Spec.Template.Spec.Domain.Devices.Watchdog
+		if watchdog == nil || watchdog.I6300ESB == nil || watchdog.I6300ESB.Action != expected {
+			t.Errorf("Expected an i6300esb watchdog with action %s for %q, got %v", expected, action, watchdog)
+		}
+	}
+
+	vm, _, err := buildVM(machine, &kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"}, []byte("#cloud-config\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if watchdog := vm.Spec.Template.Spec.Domain.Devices.Watchdog; watchdog != nil {
+		t.Errorf("Expected no watchdog by default, got %v", watchdog)
+	}
+}
@@ -0,0 +1,259 @@
+package machine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"text/template"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+const (
+	sysprepVolumeName = "sysprepdisk"
+	// sysprepVolumeLabel is the label of the CD-ROM of the rendered answer file, which the
+	// answer file locates the bootstrap script with
+	sysprepVolumeLabel = "SYSPREP"
+	// sysprepUnattendKey is the name Windows looks up the answer file under at the root of removable media
+	sysprepUnattendKey = "unattend.xml"
+	// windowsBootstrapScriptKey is the key of the UserData in the secret of the rendered answer file
+	windowsBootstrapScriptKey = "bootstrap.ps1"
+
+	defaultWindowsTimeZone       = "UTC"
+	defaultWindowsInterfaceModel = "e1000e"
+	// podNetworkName is the name KubeVirt gives the pod network it attaches VMIs to by default
+	podNetworkName = "default"
+	windowsDiskBus = kubevirtproviderv1.DiskBusSATA
+	// windowsComputerNameMaxLength is the NetBIOS limit of Windows computer names
+	windowsComputerNameMaxLength = 15
+	// hypervSpinlockRetries is the spinlock retry count recommended for Windows guests
+	hypervSpinlockRetries = 8191
+)
+
+var answerFileTemplate = template.Must(template.New("unattend").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version="1.0" encoding="utf-8"?>
+<unattend xmlns="urn:schemas-microsoft-com:unattend" xmlns:wcm="http://schemas.microsoft.com/WMIConfig/2002/State">
+  <settings pass="specialize">
+    <component name="Microsoft-Windows-Shell-Setup" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+      <ComputerName>{{ xml .ComputerName }}</ComputerName>
+      <TimeZone>{{ xml .TimeZone }}</TimeZone>
+    </component>
+    <component name="Microsoft-Windows-Deployment" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+      <RunSynchronous>
+        <RunSynchronousCommand wcm:action="add">
+          <Order>1</Order>
+          <Description>Bootstrap the machine</Description>
+          <Path>{{ xml .BootstrapCommand }}</Path>
+        </RunSynchronousCommand>
+      </RunSynchronous>
+    </component>
+  </settings>
+  <settings pass="oobeSystem">
+    <component name="Microsoft-Windows-Shell-Setup" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+      <OOBE>
+        <HideEULAPage>true</HideEULAPage>
+        <HideOEMRegistrationScreen>true</HideOEMRegistrationScreen>
+        <HideOnlineAccountScreens>true</HideOnlineAccountScreens>
+        <HideWirelessSetupInOOBE>true</HideWirelessSetupInOOBE>
+        <ProtectYourPC>3</ProtectYourPC>
+        <SkipMachineOOBE>true</SkipMachineOOBE>
+        <SkipUserOOBE>true</SkipUserOOBE>
+      </OOBE>
+    </component>
+  </settings>
+</unattend>
+`))
+
+func xmlEscape(value string) (string, error) {
+	var escaped bytes.Buffer
+	if err := xml.EscapeText(&escaped, []byte(value)); err != nil {
+		return "", err
+	}
+	return escaped.String(), nil
+}
+
+// windowsComputerName returns the computer name of the Windows guest of the machine, the
+// machine name within the NetBIOS limit of 15 characters. Longer names keep their first
+// characters and the random suffix of their MachineSet.
+func windowsComputerName(machineName string) string {
+	name := strings.ToUpper(guestHostname(machineName))
+	if len(name) <= windowsComputerNameMaxLength {
+		return name
+	}
+	suffix := name[strings.LastIndex(name, "-")+1:]
+	if len(suffix) > windowsComputerNameMaxLength/2 {
+		suffix = suffix[len(suffix)-windowsComputerNameMaxLength/2:]
+	}
+	prefix := strings.TrimRight(name[:windowsComputerNameMaxLength-len(suffix)-1], "-")
+	return prefix + "-" + suffix
+}
+
+// windowsBootstrapScript returns the PowerShell script of the UserData, without the
+// <powershell> tags of the EC2 convention.
+func windowsBootstrapScript(userData []byte) []byte {
+	script := bytes.TrimSpace(userData)
+	if bytes.HasPrefix(script, []byte("<powershell>")) && bytes.HasSuffix(script, []byte("</powershell>")) {
+		script = bytes.TrimSpace(script[len("<powershell>") : len(script)-len("</powershell>")])
+	}
+	return script
+}
+
+// renderAnswerFile renders the sysprep answer file of the Windows guest of the machine.
+func renderAnswerFile(machine *machinev1.Machine, windows *kubevirtproviderv1.Windows) ([]byte, error) {
+	timeZone := windows.TimeZone
+	if timeZone == "" {
+		timeZone = defaultWindowsTimeZone
+	}
+	bootstrapCommand := fmt.Sprintf(`powershell.exe -NoProfile -ExecutionPolicy Bypass -Command "& ((Get-Volume -FileSystemLabel %s).DriveLetter + ':\%s')"`, sysprepVolumeLabel, windowsBootstrapScriptKey)
+
+	var answerFile bytes.Buffer
+	if err := answerFileTemplate.Execute(&answerFile, struct {
+		ComputerName     string
+		TimeZone         string
+		BootstrapCommand string
+	}{
+		ComputerName:     windowsComputerName(machine.Name),
+		TimeZone:         timeZone,
+		BootstrapCommand: bootstrapCommand,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to render answer file: %v", err)
+	}
+	return answerFile.Bytes(), nil
+}
+
+// validateWindows returns an error if the Windows settings are invalid, or if the rendered
+// answer file is combined with UserData settings it does not support.
+func validateWindows(providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec) error {
+	windows := providerSpec.Windows
+	switch windows.InterfaceModel {
+	case "", "e1000e", "virtio":
+	default:
+		return fmt.Errorf("unsupported interfaceModel %q, must be one of %q or %q", windows.InterfaceModel, "e1000e", "virtio")
+	}
+	if providerSpec.UserDataFormat == kubevirtproviderv1.UserDataFormatIgnition {
+		return fmt.Errorf("windows does not support %s UserData", kubevirtproviderv1.UserDataFormatIgnition)
+	}
+
+	if sysprep := windows.Sysprep; sysprep != nil {
+		if (sysprep.ConfigMap == nil) == (sysprep.Secret == nil) {
+			return fmt.Errorf("sysprep must reference exactly one of a configMap or a secret")
+		}
+		if (sysprep.ConfigMap != nil && sysprep.ConfigMap.Name == "") || (sysprep.Secret != nil && sysprep.Secret.Name == "") {
+			return fmt.Errorf("sysprep must reference the answer file by name")
+		}
+		if windows.TimeZone != "" {
+			return fmt.Errorf("timeZone only applies to the rendered answer file, set it in the sysprep answer file")
+		}
+		return nil
+	}
+
+	if providerSpec.NetworkData != nil {
+		return fmt.Errorf("the rendered answer file does not support networkData")
+	}
+	if providerSpec.CloudInitSource != "" {
+		return fmt.Errorf("the rendered answer file replaces the cloud-init volume, cloudInitSource cannot be set")
+	}
+	if providerSpec.ShareUserDataSecret {
+		return fmt.Errorf("the rendered answer file names the guest after its machine, it cannot be shared with shareUserDataSecret")
+	}
+	return nil
+}
+
+// buildAnswerFileSecret renders the secret holding the answer file of the Windows guest of
+// the machine and its bootstrap script, in namespace.
+func buildAnswerFileSecret(machine *machinev1.Machine, namespace string, windows *kubevirtproviderv1.Windows, userData []byte) (*corev1.Secret, error) {
+	answerFile, err := renderAnswerFile(machine, windows)
+	if err != nil {
+		return nil, err
+	}
+	secret := buildUserDataSecret(machine, namespace, nil)
+	secret.Data = map[string][]byte{
+		sysprepUnattendKey:        answerFile,
+		windowsBootstrapScriptKey: windowsBootstrapScript(userData),
+	}
+	return secret, nil
+}
+
+// buildSysprepVolume renders the volume handing the answer file to Windows out of the
+// referenced ConfigMap or Secret, or out of the secret of the rendered answer file.
+func buildSysprepVolume(sysprep *kubevirtproviderv1.SysprepSource, answerFileSecretName string) kubevirtapis.Volume {
+	volume := kubevirtapis.Volume{Name: sysprepVolumeName}
+	switch {
+	case sysprep == nil:
+		volume.Secret = &kubevirtapis.SecretVolumeSource{SecretName: answerFileSecretName, VolumeLabel: sysprepVolumeLabel}
+	case sysprep.ConfigMap != nil:
+		volume.ConfigMap = &kubevirtapis.ConfigMapVolumeSource{LocalObjectReference: *sysprep.ConfigMap}
+	default:
+		volume.Secret = &kubevirtapis.SecretVolumeSource{SecretName: sysprep.Secret.Name}
+	}
+	return volume
+}
+
+// buildCDRom renders a CD-ROM attached through the bus.
+func buildCDRom(name string, bus kubevirtproviderv1.DiskBus) kubevirtapis.Disk {
+	return kubevirtapis.Disk{
+		Name: name,
+		DiskDevice: kubevirtapis.DiskDevice{
+			CDRom: &kubevirtapis.CDRomTarget{
+				Bus: string(bus),
+			},
+		},
+	}
+}
+
+// applyWindows sets the Hyper-V enlightenments and the clock recommended for Windows guests,
+// and the interface model of the network interfaces, attaching the VMI to the pod network
+// explicitly for that if no interfaces are set.
+func applyWindows(spec *kubevirtapis.VirtualMachineInstanceSpec, windows *kubevirtproviderv1.Windows) {
+	enabled := true
+	spinlockRetries := uint32(hypervSpinlockRetries)
+	spec.Domain.Features = &kubevirtapis.Features{
+		ACPI: kubevirtapis.FeatureState{},
+		APIC: &kubevirtapis.FeatureAPIC{},
+		Hyperv: &kubevirtapis.FeatureHyperv{
+			Relaxed:    &kubevirtapis.FeatureState{},
+			VAPIC:      &kubevirtapis.FeatureState{},
+			Spinlocks:  &kubevirtapis.FeatureSpinlocks{Retries: &spinlockRetries},
+			VPIndex:    &kubevirtapis.FeatureState{},
+			SyNIC:      &kubevirtapis.FeatureState{},
+			SyNICTimer: &kubevirtapis.FeatureState{},
+			Reset:      &kubevirtapis.FeatureState{},
+		},
+	}
+	disabled := false
+	spec.Domain.Clock = &kubevirtapis.Clock{
+		ClockOffset: kubevirtapis.ClockOffset{UTC: &kubevirtapis.ClockOffsetUTC{}},
+		Timer: &kubevirtapis.Timer{
+			HPET:   &kubevirtapis.HPETTimer{Enabled: &disabled},
+			PIT:    &kubevirtapis.PITTimer{TickPolicy: kubevirtapis.PITTickPolicyDelay},
+			RTC:    &kubevirtapis.RTCTimer{TickPolicy: kubevirtapis.RTCTickPolicyCatchup},
+			Hyperv: &kubevirtapis.HypervTimer{Enabled: &enabled},
+		},
+	}
+
+	model := windows.InterfaceModel
+	if model == "" {
+		model = defaultWindowsInterfaceModel
+	}
+	if len(spec.Domain.Devices.Interfaces) == 0 {
+		// The interface KubeVirt would otherwise add on the pod network
+		spec.Domain.Devices.Interfaces = []kubevirtapis.Interface{{
+			Name:                   podNetworkName,
+			InterfaceBindingMethod: kubevirtapis.InterfaceBindingMethod{Bridge: &kubevirtapis.InterfaceBridge{}},
+		}}
+		spec.Networks = []kubevirtapis.Network{{
+			Name:          podNetworkName,
+			NetworkSource: kubevirtapis.NetworkSource{Pod: &kubevirtapis.PodNetwork{}},
+		}}
+	}
+	for i := range spec.Domain.Devices.Interfaces {
+		// SR-IOV interfaces are passed through as is
+		if spec.Domain.Devices.Interfaces[i].SRIOV == nil {
+			spec.Domain.Devices.Interfaces[i].Model = model
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package machine
+
+import (
+	"strings"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestWindowsComputerName(t *testing.T) {
+	testCases := map[string]string{
+		"win-abcde":                   "WIN-ABCDE",
+		"tenant-windows-worker-abcde": "TENANT-WI-ABCDE",
+		"tenant---worker-abcde":       "TENANT-ABCDE",
+		"windowsworkerwithoutsuffix":  "WINDOWS-TSUFFIX",
+	}
+	for machineName, expected := range testCases {
+		if name := windowsComputerName(machineName); name != expected || len(name) > windowsComputerNameMaxLength {
+			t.Errorf("Expected computer name %q for machine %s, got %q", expected, machineName, name)
+		}
+	}
+}
+
+func TestWindowsBootstrapScript(t *testing.T) {
+	testCases := map[string]string{
+		"<powershell>\nStart-Service sshd\n</powershell>\n": "Start-Service sshd",
+		"Start-Service sshd\n":                              "Start-Service sshd",
+	}
+	for userData, expected := range testCases {
+		if script := string(windowsBootstrapScript([]byte(userData))); script != expected {
+			t.Errorf("Expected script %q, got %q", expected, script)
+		}
+	}
+}
+
+func TestValidateWindows(t *testing.T) {
+	testCases := []struct {
+		testcase     string
+		providerSpec kubevirtproviderv1.KubevirtMachineProviderSpec
+		expectError  bool
+	}{
+		{
+			testcase:     "rendered answer file",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Windows: &kubevirtproviderv1.Windows{TimeZone: "Pacific Standard Time", InterfaceModel: "virtio"}},
+		},
+		{
+			testcase: "sysprep secret",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{
+				Windows:         &kubevirtproviderv1.Windows{Sysprep: &kubevirtproviderv1.SysprepSource{Secret: &corev1.LocalObjectReference{Name: "unattend"}}},
+				CloudInitSource: kubevirtproviderv1.CloudInitConfigDrive,
+			},
+		},
+		{
+			testcase:     "unsupported interface model",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Windows: &kubevirtproviderv1.Windows{InterfaceModel: "rtl8139"}},
+			expectError:  true,
+		},
+		{
+			testcase: "sysprep configMap and secret",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Windows: &kubevirtproviderv1.Windows{Sysprep: &kubevirtproviderv1.SysprepSource{
+				ConfigMap: &corev1.LocalObjectReference{Name: "unattend"},
+				Secret:    &corev1.LocalObjectReference{Name: "unattend"},
+			}}},
+			expectError: true,
+		},
+		{
+			testcase:     "ignition",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Windows: &kubevirtproviderv1.Windows{}, UserDataFormat: kubevirtproviderv1.UserDataFormatIgnition},
+			expectError:  true,
+		},
+		{
+			testcase:     "rendered answer file shared",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{Windows: &kubevirtproviderv1.Windows{}, ShareUserDataSecret: true},
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			err := validateWindows(&tc.providerSpec)
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestBuildVMWindows(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "tenant-windows-worker-abcde", Namespace: "tenant-a"}}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "windows-2019",
+		Windows:       &kubevirtproviderv1.Windows{TimeZone: "W. Europe Standard Time"},
+	}
+	vm, userDataSecret, err := buildVM(machine, providerSpec, []byte("<powershell>\nStart-Service sshd\n</powershell>"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spec := vm.Spec.Template.Spec
+	disks := spec.Domain.Devices.Disks
+	if len(disks) != 2 || disks[0].Disk == nil || disks[0].Disk.Bus != "sata" || disks[1].CDRom == nil || disks[1].Name != sysprepVolumeName {
+		t.Errorf("Expected a SATA root disk and the sysprep CD-ROM, got %v", disks)
+	}
+	if volume := spec.Volumes[1]; volume.Secret == nil || volume.Secret.SecretName != userDataSecret.Name || volume.Secret.VolumeLabel != sysprepVolumeLabel {
+		t.Errorf("Expected the sysprep volume out of the UserData secret, got %v", volume)
+	}
+	answerFile := string(userDataSecret.Data[sysprepUnattendKey])
+	for _, expected := range []string{"<ComputerName>TENANT-WI-ABCDE</ComputerName>", "<TimeZone>W. Europe Standard Time</TimeZone>", `-FileSystemLabel SYSPREP).DriveLetter + &#39;:\bootstrap.ps1&#39;)`} {
+		if !strings.Contains(answerFile, expected) {
+			t.Errorf("Expected the answer file to contain %s, got %s", expected, answerFile)
+		}
+	}
+	if script := string(userDataSecret.Data[windowsBootstrapScriptKey]); script != "Start-Service sshd" {
+		t.Errorf("Expected the bootstrap script out of the UserData, got %q", script)
+	}
+	if _, ok := userDataSecret.Data[cloudInitUserDataKey]; ok {
+		t.Errorf("Expected no cloud-init UserData, got %v", userDataSecret.Data)
+	}
+
+	if features := spec.Domain.Features; features == nil || features.Hyperv == nil || features.Hyperv.Spinlocks == nil || *features.Hyperv.Spinlocks.Retries != hypervSpinlockRetries {
+		t.Errorf("Expected the Hyper-V enlightenments, got %v", features)
+	}
+	if clock := spec.Domain.Clock; clock == nil || clock.UTC == nil || clock.Timer == nil || clock.Timer.Hyperv == nil {
+		t.Errorf("Expected the UTC clock with the Hyper-V timer, got %v", clock)
+	}
+	if interfaces := spec.Domain.Devices.Interfaces; len(interfaces) != 1 || interfaces[0].Model != "e1000e" || len(spec.Networks) != 1 || spec.Networks[0].Pod == nil {
+		t.Errorf("Expected an e1000e interface on the pod network, got %v and %v", interfaces, spec.Networks)
+	}
+}
+
+func TestBuildVMWindowsSysprep(t *testing.T) {
+	machine := &machinev1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "win-abcde", Namespace: "tenant-a"}}
+	providerSpec := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName: "windows-2019",
+		DiskBus:       kubevirtproviderv1.DiskBusVirtio,
+		Interfaces:    []kubevirtproviderv1.NetworkInterface{{Name: "default"}},
+		Windows: &kubevirtproviderv1.Windows{
+			Sysprep:        &kubevirtproviderv1.SysprepSource{ConfigMap: &corev1.LocalObjectReference{Name: "unattend"}},
+			InterfaceModel: "virtio",
+		},
+	}
+	vm, userDataSecret, err := buildVM(machine, providerSpec, []byte("#ps1_sysnative\nStart-Service sshd\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spec := vm.Spec.Template.Spec
+	disks := spec.Domain.Devices.Disks
+	if len(disks) != 3 || disks[0].Disk.Bus != "virtio" || disks[1].Name != cloudInitVolumeName || disks[2].CDRom == nil {
+		t.Errorf("Expected the virtio root disk, the cloud-init disk and the sysprep CD-ROM, got %v", disks)
+	}
+	if volume := spec.Volumes[2]; volume.ConfigMap == nil || volume.ConfigMap.Name != "unattend" {
+		t.Errorf("Expected the sysprep volume out of the ConfigMap, got %v", volume)
+	}
+	if _, ok := userDataSecret.Data[cloudInitUserDataKey]; !ok {
+		t.Errorf("Expected the cloud-init UserData, got %v", userDataSecret.Data)
+	}
+	if interfaces := spec.Domain.Devices.Interfaces; len(interfaces) != 1 || interfaces[0].Model != "virtio" {
+		t.Errorf("Expected a virtio interface, got %v", interfaces)
+	}
+}
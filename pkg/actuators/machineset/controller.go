@@ -10,12 +10,15 @@ import (
 	mapierrors "github.com/openshift/machine-api-operator/pkg/controller/machine"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
-	awsproviderv1 "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	machineactuator "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/actuators/machine"
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
 )
 
 const (
@@ -25,6 +28,11 @@ const (
 	cpuKey    = "machine.openshift.io/vCPU"
 	memoryKey = "machine.openshift.io/memoryMb"
 	gpuKey    = "machine.openshift.io/GPU"
+
+	// The infra footprint of a machine of provider specs accounting for the virt-launcher
+	// overhead, the resources of the guest plus the overhead, for infra capacity planning.
+	infraCPUKey    = "kubevirtproviderconfig.openshift.io/infra-cpu"
+	infraMemoryKey = "kubevirtproviderconfig.openshift.io/infra-memory-mb"
 )
 
 // Reconciler reconciles machineSets.
@@ -106,13 +114,14 @@ func isInvalidConfigurationError(err error) bool {
 }
 
 func reconcile(machineSet *machinev1.MachineSet) (ctrl.Result, error) {
-	providerConfig, err := awsproviderv1.ProviderSpecFromRawExtension(machineSet.Spec.Template.Spec.ProviderSpec.Value)
+	providerSpec, err := kubevirtproviderv1.ProviderSpecFromRawExtension(machineSet.Spec.Template.Spec.ProviderSpec.Value)
 	if err != nil {
 		return ctrl.Result{}, mapierrors.InvalidMachineConfiguration("failed to get providerConfig: %v", err)
 	}
-	instanceType, ok := InstanceTypes[providerConfig.InstanceType]
-	if !ok {
-		return ctrl.Result{}, mapierrors.InvalidMachineConfiguration("unknown instance type: %s", providerConfig.InstanceType)
+	machineactuator.DefaultProviderSpec(providerSpec)
+	memory, err := resource.ParseQuantity(providerSpec.RequestedMemory)
+	if err != nil {
+		return ctrl.Result{}, mapierrors.InvalidMachineConfiguration("invalid requestedMemory %q: %v", providerSpec.RequestedMemory, err)
 	}
 
 	if machineSet.Annotations == nil {
@@ -120,9 +129,22 @@ func reconcile(machineSet *machinev1.MachineSet) (ctrl.Result, error) {
 	}
 
 	// TODO: get annotations keys from machine API
-	machineSet.Annotations[cpuKey] = strconv.FormatInt(instanceType.VCPU, 10)
-	machineSet.Annotations[memoryKey] = strconv.FormatInt(instanceType.MemoryMb, 10)
-	machineSet.Annotations[gpuKey] = strconv.FormatInt(instanceType.GPU, 10)
+	machineSet.Annotations[cpuKey] = strconv.FormatInt(int64(providerSpec.RequestedCPU), 10)
+	machineSet.Annotations[memoryKey] = strconv.FormatInt(memory.Value()/(1024*1024), 10)
+	// The provider spec passes no GPU through to the VMs
+	machineSet.Annotations[gpuKey] = "0"
+
+	if providerSpec.LauncherOverhead == nil {
+		delete(machineSet.Annotations, infraCPUKey)
+		delete(machineSet.Annotations, infraMemoryKey)
+		return ctrl.Result{}, nil
+	}
+	infraCPU, infraMemory, err := machineactuator.InfraFootprint(providerSpec)
+	if err != nil {
+		return ctrl.Result{}, mapierrors.InvalidMachineConfiguration("invalid launcherOverhead: %v", err)
+	}
+	machineSet.Annotations[infraCPUKey] = infraCPU.String()
+	machineSet.Annotations[infraMemoryKey] = strconv.FormatInt(infraMemory.Value()/(1024*1024), 10)
 
 	return ctrl.Result{}, nil
 }
@@ -27,11 +27,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
-	awsproviderv1 "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsprovider/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
 )
 
 var _ = Describe("MachineSetReconciler", func() {
@@ -66,14 +67,14 @@ var _ = Describe("MachineSetReconciler", func() {
 	})
 
 	type reconcileTestCase = struct {
-		instanceType        string
+		providerSpec        kubevirtproviderv1.KubevirtMachineProviderSpec
 		existingAnnotations map[string]string
 		expectedAnnotations map[string]string
 		expectedEvents      []string
 	}
 
 	DescribeTable("when reconciling MachineSets", func(rtc reconcileTestCase) {
-		machineSet, err := newTestMachineSet(namespace.Name, rtc.instanceType, rtc.existingAnnotations)
+		machineSet, err := newTestMachineSet(namespace.Name, rtc.providerSpec, rtc.existingAnnotations)
 		Expect(err).ToNot(HaveOccurred())
 
 		Expect(c.Create(ctx, machineSet)).To(Succeed())
@@ -103,34 +104,28 @@ var _ = Describe("MachineSetReconciler", func() {
 		}
 		Expect(receivedEvents).To(ConsistOf(eventMatchers))
 	},
-		Entry("with no instanceType set", reconcileTestCase{
-			instanceType:        "",
-			existingAnnotations: make(map[string]string),
-			expectedAnnotations: make(map[string]string),
-			expectedEvents:      []string{"ReconcileError"},
-		}),
-		Entry("with a a1.2xlarge", reconcileTestCase{
-			instanceType:        "a1.2xlarge",
+		Entry("with the default resources", reconcileTestCase{
+			providerSpec:        kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"},
 			existingAnnotations: make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "8",
-				memoryKey: "16384",
+				cpuKey:    "1",
+				memoryKey: "1953",
 				gpuKey:    "0",
 			},
 			expectedEvents: []string{},
 		}),
-		Entry("with a p2.16xlarge", reconcileTestCase{
-			instanceType:        "p2.16xlarge",
+		Entry("with requested resources", reconcileTestCase{
+			providerSpec:        kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: 8, RequestedMemory: "16Gi"},
 			existingAnnotations: make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "64",
-				memoryKey: "786432",
-				gpuKey:    "16",
+				cpuKey:    "8",
+				memoryKey: "16384",
+				gpuKey:    "0",
 			},
 			expectedEvents: []string{},
 		}),
 		Entry("with existing annotations", reconcileTestCase{
-			instanceType: "a1.2xlarge",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: 8, RequestedMemory: "16Gi"},
 			existingAnnotations: map[string]string{
 				"existing": "annotation",
 				"annother": "existingAnnotation",
@@ -144,8 +139,8 @@ var _ = Describe("MachineSetReconciler", func() {
 			},
 			expectedEvents: []string{},
 		}),
-		Entry("with an invalid instanceType", reconcileTestCase{
-			instanceType: "invalid",
+		Entry("with an invalid requestedMemory", reconcileTestCase{
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedMemory: "invalid"},
 			existingAnnotations: map[string]string{
 				"existing": "annotation",
 				"annother": "existingAnnotation",
@@ -191,43 +186,36 @@ func deleteMachineSets(c client.Client, namespaceName string) error {
 func TestReconcile(t *testing.T) {
 	testCases := []struct {
 		name                string
-		instanceType        string
+		providerSpec        kubevirtproviderv1.KubevirtMachineProviderSpec
 		existingAnnotations map[string]string
 		expectedAnnotations map[string]string
 		expectErr           bool
 	}{
 		{
-			name:                "with no instanceType set",
-			instanceType:        "",
-			existingAnnotations: make(map[string]string),
-			expectedAnnotations: make(map[string]string),
-			expectErr:           true,
-		},
-		{
-			name:                "with a a1.2xlarge",
-			instanceType:        "a1.2xlarge",
+			name:                "with the default resources",
+			providerSpec:        kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos"},
 			existingAnnotations: make(map[string]string),
 			expectedAnnotations: map[string]string{
-				cpuKey:    "8",
-				memoryKey: "16384",
+				cpuKey:    "1",
+				memoryKey: "1953",
 				gpuKey:    "0",
 			},
 			expectErr: false,
 		},
 		{
-			name:                "with a p2.16xlarge",
-			instanceType:        "p2.16xlarge",
+			name:                "with requested resources",
+			providerSpec:        kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: 64, RequestedMemory: "768Gi"},
 			existingAnnotations: make(map[string]string),
 			expectedAnnotations: map[string]string{
 				cpuKey:    "64",
 				memoryKey: "786432",
-				gpuKey:    "16",
+				gpuKey:    "0",
 			},
 			expectErr: false,
 		},
 		{
 			name:         "with existing annotations",
-			instanceType: "a1.2xlarge",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: 8, RequestedMemory: "16Gi"},
 			existingAnnotations: map[string]string{
 				"existing": "annotation",
 				"annother": "existingAnnotation",
@@ -242,8 +230,22 @@ func TestReconcile(t *testing.T) {
 			expectErr: false,
 		},
 		{
-			name:         "with an invalid instanceType",
-			instanceType: "invalid",
+			name: "with a launcher overhead",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedCPU: 2, RequestedMemory: "4Gi",
+				LauncherOverhead: &kubevirtproviderv1.LauncherOverhead{CPU: "200m", Memory: "256Mi"}},
+			existingAnnotations: make(map[string]string),
+			expectedAnnotations: map[string]string{
+				cpuKey:         "2",
+				memoryKey:      "4096",
+				gpuKey:         "0",
+				infraCPUKey:    "2200m",
+				infraMemoryKey: "4352",
+			},
+			expectErr: false,
+		},
+		{
+			name:         "with an invalid requestedMemory",
+			providerSpec: kubevirtproviderv1.KubevirtMachineProviderSpec{SourcePvcName: "rhcos", RequestedMemory: "invalid"},
 			existingAnnotations: map[string]string{
 				"existing": "annotation",
 				"annother": "existingAnnotation",
@@ -260,7 +262,7 @@ func TestReconcile(t *testing.T) {
 		t.Run(tc.name, func(tt *testing.T) {
 			g := NewWithT(tt)
 
-			machineSet, err := newTestMachineSet("default", tc.instanceType, tc.existingAnnotations)
+			machineSet, err := newTestMachineSet("default", tc.providerSpec, tc.existingAnnotations)
 			g.Expect(err).ToNot(HaveOccurred())
 
 			_, err = reconcile(machineSet)
@@ -270,17 +272,14 @@ func TestReconcile(t *testing.T) {
 	}
 }
 
-func newTestMachineSet(namespace string, instanceType string, existingAnnotations map[string]string) (*machinev1.MachineSet, error) {
+func newTestMachineSet(namespace string, machineProviderSpec kubevirtproviderv1.KubevirtMachineProviderSpec, existingAnnotations map[string]string) (*machinev1.MachineSet, error) {
 	// Copy anntotations map so we don't modify the input
 	annotations := make(map[string]string)
 	for k, v := range existingAnnotations {
 		annotations[k] = v
 	}
 
-	machineProviderSpec := &awsproviderv1.AWSMachineProviderConfig{
-		InstanceType: instanceType,
-	}
-	providerSpec, err := providerSpecFromMachine(machineProviderSpec)
+	providerSpec, err := providerSpecFromMachine(&machineProviderSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -301,7 +300,7 @@ func newTestMachineSet(namespace string, instanceType string, existingAnnotation
 	}, nil
 }
 
-func providerSpecFromMachine(in *awsproviderv1.AWSMachineProviderConfig) (machinev1.ProviderSpec, error) {
+func providerSpecFromMachine(in *kubevirtproviderv1.KubevirtMachineProviderSpec) (machinev1.ProviderSpec, error) {
 	bytes, err := json.Marshal(in)
 	if err != nil {
 		return machinev1.ProviderSpec{}, err
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package codec decodes the provider specs of every supported kubevirtproviderconfig version
+// into the hub version the actuator works with.
+package codec
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// DecodeProviderSpec unmarshals a raw extension into the v1beta1 provider spec, converting
+// the provider specs of older versions. Provider specs without apiVersion are decoded as
+// v1beta1, the versions share the serialization of the fields they have in common.
+func DecodeProviderSpec(rawExtension *runtime.RawExtension) (*v1beta1.KubevirtMachineProviderSpec, error) {
+	if rawExtension == nil {
+		return &v1beta1.KubevirtMachineProviderSpec{}, nil
+	}
+
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(rawExtension.Raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("error unmarshalling providerSpec: %v", err)
+	}
+	gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing providerSpec apiVersion: %v", err)
+	}
+	if gv.Group != v1beta1.SchemeGroupVersion.Group {
+		// Left to the kind checks of the callers
+		return v1beta1.ProviderSpecFromRawExtension(rawExtension)
+	}
+
+	switch gv.Version {
+	case v1alpha1.SchemeGroupVersion.Version:
+		spec, err := v1alpha1.ProviderSpecFromRawExtension(rawExtension)
+		if err != nil {
+			return nil, err
+		}
+		hub := &v1beta1.KubevirtMachineProviderSpec{}
+		if err := spec.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+		return hub, nil
+	case v1beta1.SchemeGroupVersion.Version:
+		return v1beta1.ProviderSpecFromRawExtension(rawExtension)
+	default:
+		return nil, fmt.Errorf("unsupported providerSpec apiVersion %q", typeMeta.APIVersion)
+	}
+}
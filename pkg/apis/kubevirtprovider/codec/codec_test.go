@@ -0,0 +1,93 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func TestDecodeProviderSpec(t *testing.T) {
+	testCases := []struct {
+		testcase           string
+		raw                string
+		expectedAPIVersion string
+		expectError        bool
+	}{
+		{
+			testcase:           "v1alpha1",
+			raw:                `{"apiVersion":"kubevirtproviderconfig.openshift.io/v1alpha1","kind":"KubevirtMachineProviderSpec","sourcePvcName":"rhcos","requestedCPU":2}`,
+			expectedAPIVersion: "kubevirtproviderconfig.openshift.io/v1beta1",
+		},
+		{
+			testcase:           "v1beta1",
+			raw:                `{"apiVersion":"kubevirtproviderconfig.openshift.io/v1beta1","kind":"KubevirtMachineProviderSpec","sourcePvcName":"rhcos","requestedCPU":2}`,
+			expectedAPIVersion: "kubevirtproviderconfig.openshift.io/v1beta1",
+		},
+		{
+			testcase: "no apiVersion",
+			raw:      `{"sourcePvcName":"rhcos","requestedCPU":2}`,
+		},
+		{
+			testcase:    "unsupported version",
+			raw:         `{"apiVersion":"kubevirtproviderconfig.openshift.io/v2","sourcePvcName":"rhcos"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			spec, err := DecodeProviderSpec(&runtime.RawExtension{Raw: []byte(tc.raw)})
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if spec.APIVersion != tc.expectedAPIVersion {
+				t.Errorf("Expected apiVersion %q, got %q", tc.expectedAPIVersion, spec.APIVersion)
+			}
+			if spec.SourcePvcName != "rhcos" || spec.RequestedCPU != 2 {
+				t.Errorf("Expected the provider spec fields to be decoded, got %+v", spec)
+			}
+		})
+	}
+}
+
+func TestProviderSpecRoundTrip(t *testing.T) {
+	original := &v1alpha1.KubevirtMachineProviderSpec{
+		TypeMeta:        metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "KubevirtMachineProviderSpec"},
+		SourcePvcName:   "rhcos",
+		RequestedMemory: "4G",
+		RequestedCPU:    4,
+		RunStrategy:     v1alpha1.RunStrategyManual,
+		RebootPolicy: &v1alpha1.RebootPolicy{
+			Interval:          metav1.Duration{Duration: 1000},
+			MaintenanceWindow: &v1alpha1.MaintenanceWindow{Start: "02:00"},
+		},
+		LauncherResources: &v1alpha1.LauncherResources{CPULimit: "4", MemoryLimit: "8Gi"},
+		NodeSelector:      map[string]string{"tier": "worker"},
+	}
+
+	hub := &v1beta1.KubevirtMachineProviderSpec{}
+	if err := original.ConvertTo(hub); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if hub.APIVersion != v1beta1.SchemeGroupVersion.String() {
+		t.Errorf("Expected apiVersion %q, got %q", v1beta1.SchemeGroupVersion.String(), hub.APIVersion)
+	}
+	converted := &v1alpha1.KubevirtMachineProviderSpec{}
+	if err := converted.ConvertFrom(hub); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(original, converted) {
+		t.Errorf("Expected the round trip to keep %+v, got %+v", original, converted)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// The v1alpha1 provider spec is kept for the Machines and MachineSets written before v1beta1,
+// new fields are only added to v1beta1. Fields keeping their serialization are converted
+// through it, fields whose serialization changes between the versions have to be converted
+// explicitly by ConvertTo and ConvertFrom.
+
+// ConvertTo converts the provider spec to the v1beta1 hub version.
+func (src *KubevirtMachineProviderSpec) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.KubevirtMachineProviderSpec)
+	if !ok {
+		return fmt.Errorf("unsupported conversion of providerSpec to %T", dstRaw)
+	}
+	if err := convertFields(src, dst); err != nil {
+		return err
+	}
+	if src.APIVersion != "" {
+		dst.APIVersion = v1beta1.SchemeGroupVersion.String()
+	}
+	return nil
+}
+
+// ConvertFrom converts the provider spec from the v1beta1 hub version. Fields added in
+// v1beta1 are dropped.
+func (dst *KubevirtMachineProviderSpec) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.KubevirtMachineProviderSpec)
+	if !ok {
+		return fmt.Errorf("unsupported conversion of providerSpec from %T", srcRaw)
+	}
+	if err := convertFields(src, dst); err != nil {
+		return err
+	}
+	if src.APIVersion != "" {
+		dst.APIVersion = SchemeGroupVersion.String()
+	}
+	return nil
+}
+
+// convertFields sets the fields of dst out of the serialization of the fields of src.
+func convertFields(src, dst interface{}) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("error marshalling providerSpec: %v", err)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("error converting providerSpec: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,461 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KubevirtMachineProviderSpec is the Schema for the kubevirtmachineproviderspecs API
+// +k8s:openapi-gen=true
+type KubevirtMachineProviderSpec struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// SourcePvcName is the name of the PVC on the infra cluster holding the boot image.
+	// It is cloned into the root disk of every VM created from this spec.
+	SourcePvcName string `json:"sourcePvcName"`
+
+	// RequestedMemory is the amount of memory requested for the VM, e.g. 2048M.
+	// Defaults to 2048M.
+	RequestedMemory string `json:"requestedMemory,omitempty"`
+
+	// RequestedCPU is the number of vCPU cores requested for the VM.
+	// Defaults to 1.
+	RequestedCPU uint32 `json:"requestedCPU,omitempty"`
+
+	// RequestedStorage is the size of the root disk of the VM, e.g. 35Gi.
+	// Defaults to 35Gi.
+	RequestedStorage string `json:"requestedStorage,omitempty"`
+
+	// StorageClassName is the storage class used for the root disk. If not set,
+	// the default storage class of the infra cluster is used.
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// DiskBus is the bus the root disk is attached with, either virtio for a virtio-blk
+	// device or scsi for a virtio-scsi controller. Defaults to virtio.
+	// +optional
+	DiskBus DiskBus `json:"diskBus,omitempty"`
+
+	// BlockMultiQueue enables one queue per vCPU for the virtio-blk root disk, improving
+	// throughput of IO-heavy guests. Requires the virtio disk bus and at least 2 vCPUs.
+	// +optional
+	BlockMultiQueue bool `json:"blockMultiQueue,omitempty"`
+
+	// UserDataSecret references the secret that contains the UserData to apply to the VM.
+	// The rendered UserData is stored in a secret next to the VM on the infra cluster,
+	// which the VM mounts as its cloud-init secret, so that bootstrap tokens are kept out
+	// of the Machine and VM objects.
+	UserDataSecret *UserDataSecretReference `json:"userDataSecret,omitempty"`
+
+	// SSHKeys are SSH public keys authorized on the guest, merged into the UserData.
+	// +optional
+	SSHKeys *SSHKeys `json:"sshKeys,omitempty"`
+
+	// CredentialsSecret is a reference to the secret holding the kubeconfig of the
+	// infra cluster. Otherwise, defaults to the cluster the actuator is running in.
+	CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+
+	// CloudInitSource specifies how the UserData is delivered to the guest.
+	// Some images (e.g. certain RHCOS or Windows builds) only read config-drive metadata.
+	// Valid values are NoCloud and ConfigDrive. Defaults to NoCloud.
+	// +optional
+	CloudInitSource CloudInitSource `json:"cloudInitSource,omitempty"`
+
+	// UserDataFormat declares the format of the UserData. Ignition UserData is passed to
+	// the guest through the KubeVirt Ignition mechanism, or through config-drive when
+	// CloudInitSource is ConfigDrive. Valid values are CloudInit and Ignition. If not set,
+	// the format is detected from the UserData content.
+	// +optional
+	UserDataFormat UserDataFormat `json:"userDataFormat,omitempty"`
+
+	// StaticIPAddresses are the static IP addresses assigned to the guest. They are passed to
+	// the kubelet as node IPs through the UserData, so that the kubelet serving certificate
+	// carries them as SANs. Once the node joined, the SANs of the certificate are verified
+	// and reported with the KubeletCertificateSANs condition.
+	// +optional
+	StaticIPAddresses []string `json:"staticIPAddresses,omitempty"`
+
+	// NetworkData declares the network configuration of the guest, rendered as cloud-init
+	// network config version 2 and handed to the guest along with the UserData. It
+	// requires CloudInit UserData delivered through the NoCloud CloudInitSource.
+	// +optional
+	NetworkData *NetworkData `json:"networkData,omitempty"`
+
+	// ShareUserDataSecret keeps the CloudInit UserData and NetworkData of the machines of a
+	// MachineSet in one secret on the infra cluster instead of one secret per machine. The
+	// per-machine hostname and instance-id reach the guest through the instance metadata.
+	// It cannot be combined with staticIPAddresses or domainSuffix, which are rendered into
+	// the UserData of each machine.
+	// +optional
+	ShareUserDataSecret bool `json:"shareUserDataSecret,omitempty"`
+
+	// TrackBootImage enables tracking of the source PVC the root disk was cloned from.
+	// When the source PVC is replaced on the infra cluster (e.g. by a DataImportCron
+	// importing a newer image), machines cloned from the previous revision are reported
+	// with the OutdatedBootImage condition so that they can be rolled out.
+	// +optional
+	TrackBootImage bool `json:"trackBootImage,omitempty"`
+
+	// DomainSuffix is the DNS domain appended to the machine name to form the FQDN of the
+	// guest. The guest hostname is always set to the machine name through the instance
+	// metadata, when DomainSuffix is set the FQDN is written through the UserData as well.
+	// +optional
+	DomainSuffix string `json:"domainSuffix,omitempty"`
+
+	// Affinity is copied into the VM template and applies to the virt-launcher pods of the
+	// VM on the infra cluster, e.g. to keep control plane VMs apart with pod anti-affinity.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// NodeSelector is copied into the VM template so that the VM is only scheduled to
+	// infra nodes carrying the given labels, e.g. GPU or SSD hosts.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are copied into the VM template so that the VM can be scheduled to
+	// tainted infra nodes.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// FailureDomain places the VM in a failure domain of the infra cluster. MachineSets
+	// use one failure domain each, or spread their VMs across the failure domains.
+	// +optional
+	FailureDomain *FailureDomain `json:"failureDomain,omitempty"`
+
+	// RunStrategy is the run strategy of the VM. Always and RerunOnFailure leave it to
+	// KubeVirt to keep the VM running, Manual has the controller start the VM once it is
+	// created and leaves it stopped after a shutdown. Defaults to running the VM, as Always.
+	// +optional
+	RunStrategy RunStrategy `json:"runStrategy,omitempty"`
+
+	// GuestShutdownPolicy is applied when the guest powers itself off. Restart starts the VM
+	// again, Fail marks the machine failed and Halt keeps the VM stopped as intended. It
+	// requires the RerunOnFailure or Manual run strategy, with which KubeVirt leaves the VM
+	// stopped after a guest shutdown. Defaults to leaving it to the run strategy.
+	// +optional
+	GuestShutdownPolicy GuestShutdownPolicy `json:"guestShutdownPolicy,omitempty"`
+
+	// EvictionStrategy is the strategy KubeVirt applies to the VM when its infra node is
+	// drained. LiveMigrate migrates the VM to another infra node, None shuts it down.
+	// Defaults to the eviction strategy configured for the infra cluster.
+	// +optional
+	EvictionStrategy EvictionStrategy `json:"evictionStrategy,omitempty"`
+
+	// RootVolumeAccessMode is the access mode of the root disk volume. Live migration needs
+	// volumes shared by the source and target infra nodes, so it defaults to ReadWriteMany
+	// with the LiveMigrate eviction strategy and to ReadWriteOnce otherwise.
+	// +optional
+	RootVolumeAccessMode corev1.PersistentVolumeAccessMode `json:"rootVolumeAccessMode,omitempty"`
+
+	// AdvancedTuning holds settings not modeled by the provider spec, for expert users.
+	// It is only honored by controllers started with --enable-advanced-tuning, machines
+	// using it are reported with the AdvancedTuningActive condition.
+	// +optional
+	AdvancedTuning *AdvancedTuning `json:"advancedTuning,omitempty"`
+
+	// RebootPolicy schedules periodic reboots of the guest, e.g. to pick up kernel updates
+	// staged in an immutable image.
+	// +optional
+	RebootPolicy *RebootPolicy `json:"rebootPolicy,omitempty"`
+
+	// LauncherResources are limits of the virt-launcher pod of the VM, giving it headroom
+	// above the memory and CPU seen by the guest. Changes are applied to the running pod
+	// where the infra cluster supports in-place pod resize, and at the next restart of the
+	// VM otherwise.
+	// +optional
+	LauncherResources *LauncherResources `json:"launcherResources,omitempty"`
+}
+
+// SSHKeys holds SSH public keys, either inline or from a secret.
+type SSHKeys struct {
+	// Keys are SSH public keys in authorized_keys format.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+
+	// SecretRef references a secret in the machine namespace. Every value of the secret
+	// holds one or more SSH public keys in authorized_keys format.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// UserDataSecretReference references a key of a secret holding UserData.
+type UserDataSecretReference struct {
+	// Name of the secret.
+	Name string `json:"name"`
+
+	// Namespace of the secret. Defaults to the namespace of the machine.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the UserData in the secret. Defaults to userData.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// CloudInitSource is the cloud-init datasource through which UserData is
+// exposed to the guest.
+type CloudInitSource string
+
+// Possible values for CloudInitSource.
+const (
+	// CloudInitNoCloud delivers UserData through the cloud-init NoCloud datasource.
+	CloudInitNoCloud CloudInitSource = "NoCloud"
+	// CloudInitConfigDrive delivers UserData through the OpenStack config-drive datasource.
+	CloudInitConfigDrive CloudInitSource = "ConfigDrive"
+)
+
+// FailureDomain is a failure domain of the infra cluster, identified by the value of a
+// topology label of the infra nodes.
+type FailureDomain struct {
+	// Zone pins the VM to the infra nodes with the given value of the topology key.
+	// It is also set as the machine.openshift.io/zone label of the Machine.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// TopologyKey is the infra node label the failure domains are identified by.
+	// Defaults to topology.kubernetes.io/zone.
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// Spread makes the VMs of the MachineSet of the machine prefer infra nodes in distinct
+	// failure domains, through pod anti-affinity of their virt-launcher pods.
+	// +optional
+	Spread bool `json:"spread,omitempty"`
+}
+
+// LauncherResources are resource limits of the virt-launcher pod of a VM.
+type LauncherResources struct {
+	// CPULimit is the CPU limit of the virt-launcher pod, e.g. 2500m.
+	// +optional
+	CPULimit string `json:"cpuLimit,omitempty"`
+
+	// MemoryLimit is the memory limit of the virt-launcher pod, e.g. 3Gi. It must not be
+	// below the requested memory, which remains the memory seen by the guest.
+	// +optional
+	MemoryLimit string `json:"memoryLimit,omitempty"`
+}
+
+// RebootPolicy schedules periodic reboots of the guest.
+type RebootPolicy struct {
+	// Interval is the time between two reboots of the guest, counted from the creation of
+	// the machine for the first reboot.
+	Interval metav1.Duration `json:"interval"`
+
+	// MaintenanceWindow restricts the reboots to a daily window. Reboots falling due outside
+	// of the window are postponed to its next opening.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// MaxUnavailable is the number of machines of the MachineSet of the machine that may be
+	// rebooting or otherwise not running at the same time. A reboot falling due while the
+	// budget is exhausted waits for the other machines. Defaults to 1.
+	// +optional
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+}
+
+// MaintenanceWindow is a daily time window.
+type MaintenanceWindow struct {
+	// Start is the time of day the window opens at, in HH:MM format, in UTC.
+	Start string `json:"start"`
+
+	// Duration is how long the window stays open, at most 24h.
+	Duration metav1.Duration `json:"duration"`
+}
+
+// AdvancedTuning holds allowlisted KubeVirt settings applied to the VM as is.
+type AdvancedTuning struct {
+	// Annotations are set on the VM template. Only hook sidecars rewriting the libvirt
+	// domain XML (hooks.kubevirt.io/hookSidecars) and SMBIOS settings (smbios.vm.kubevirt.io/*)
+	// are allowed.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NetworkData is the network configuration of the guest.
+type NetworkData struct {
+	// Ethernets configure the ethernet interfaces of the guest.
+	// +optional
+	Ethernets []EthernetInterface `json:"ethernets,omitempty"`
+
+	// VLANs configure VLAN sub-interfaces on top of the ethernet interfaces.
+	// +optional
+	VLANs []VLANInterface `json:"vlans,omitempty"`
+}
+
+// EthernetInterface configures an ethernet interface of the guest.
+type EthernetInterface struct {
+	// Name is the name of the interface in the guest, e.g. eth0.
+	Name string `json:"name"`
+
+	// MACAddress matches the interface by MAC address instead of by name, which is then
+	// set as the name of the matched interface.
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+
+	InterfaceConfig `json:",inline"`
+}
+
+// VLANInterface configures a VLAN sub-interface of the guest.
+type VLANInterface struct {
+	// Name is the name of the sub-interface in the guest, e.g. eth0.100.
+	Name string `json:"name"`
+
+	// ID is the VLAN ID, between 1 and 4094.
+	ID int32 `json:"id"`
+
+	// Link is the name of the ethernet interface the VLAN is on.
+	Link string `json:"link"`
+
+	InterfaceConfig `json:",inline"`
+}
+
+// InterfaceConfig is the addressing and routing configuration of a guest interface.
+type InterfaceConfig struct {
+	// DHCP4 enables DHCP for IPv4 on the interface.
+	// +optional
+	DHCP4 bool `json:"dhcp4,omitempty"`
+
+	// Addresses are the static addresses of the interface in CIDR notation.
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+
+	// MTU is the MTU of the interface.
+	// +optional
+	MTU int32 `json:"mtu,omitempty"`
+
+	// Routes are the static routes through the interface.
+	// +optional
+	Routes []Route `json:"routes,omitempty"`
+
+	// RoutingPolicy are the policy routing rules of the interface, selecting the routing
+	// table of the traffic.
+	// +optional
+	RoutingPolicy []RoutingPolicyRule `json:"routingPolicy,omitempty"`
+}
+
+// Route is a static route of the guest.
+type Route struct {
+	// To is the destination of the route in CIDR notation.
+	To string `json:"to"`
+
+	// Via is the gateway of the route.
+	// +optional
+	Via string `json:"via,omitempty"`
+
+	// Metric is the metric of the route.
+	// +optional
+	Metric int32 `json:"metric,omitempty"`
+
+	// Table is the routing table of the route, the main table if not set.
+	// +optional
+	Table int32 `json:"table,omitempty"`
+}
+
+// RoutingPolicyRule is a policy routing rule of the guest.
+type RoutingPolicyRule struct {
+	// From matches the source of the traffic in CIDR notation.
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// To matches the destination of the traffic in CIDR notation.
+	// +optional
+	To string `json:"to,omitempty"`
+
+	// Table is the routing table of the matched traffic.
+	Table int32 `json:"table"`
+
+	// Priority is the priority of the rule, lower values are evaluated first.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// RunStrategy is the run strategy of the VM.
+type RunStrategy string
+
+// Possible values for RunStrategy.
+const (
+	// RunStrategyAlways keeps the VM running, restarting it after any shutdown.
+	RunStrategyAlways RunStrategy = "Always"
+	// RunStrategyRerunOnFailure restarts the VM after failures, but not after a guest shutdown.
+	RunStrategyRerunOnFailure RunStrategy = "RerunOnFailure"
+	// RunStrategyManual starts the VM once created, it is stopped and started on demand afterwards.
+	RunStrategyManual RunStrategy = "Manual"
+)
+
+// GuestShutdownPolicy is the handling of a VM whose guest powered itself off.
+type GuestShutdownPolicy string
+
+// Possible values for GuestShutdownPolicy.
+const (
+	// GuestShutdownRestart restarts the VM.
+	GuestShutdownRestart GuestShutdownPolicy = "Restart"
+	// GuestShutdownFail marks the machine failed, e.g. for machine health checks to replace it.
+	GuestShutdownFail GuestShutdownPolicy = "Fail"
+	// GuestShutdownHalt keeps the VM stopped until it is started again on demand.
+	GuestShutdownHalt GuestShutdownPolicy = "Halt"
+)
+
+// EvictionStrategy is the strategy applied to the VM when its infra node is drained.
+type EvictionStrategy string
+
+// Possible values for EvictionStrategy.
+const (
+	// EvictionStrategyLiveMigrate live migrates the VM off the drained infra node.
+	EvictionStrategyLiveMigrate EvictionStrategy = "LiveMigrate"
+	// EvictionStrategyNone shuts the VM down with the drained infra node.
+	EvictionStrategyNone EvictionStrategy = "None"
+)
+
+// DiskBus is the bus a disk is attached to the VM with.
+type DiskBus string
+
+// Possible values for DiskBus.
+const (
+	// DiskBusVirtio attaches the disk as a virtio-blk device.
+	DiskBusVirtio DiskBus = "virtio"
+	// DiskBusSCSI attaches the disk to a virtio-scsi controller.
+	DiskBusSCSI DiskBus = "scsi"
+)
+
+// UserDataFormat is the format of the UserData handed to the guest.
+type UserDataFormat string
+
+// Possible values for UserDataFormat.
+const (
+	// UserDataFormatCloudInit is cloud-init UserData, e.g. a #cloud-config document or a script.
+	UserDataFormatCloudInit UserDataFormat = "CloudInit"
+	// UserDataFormatIgnition is an Ignition config, as consumed by RHCOS and FCOS guests.
+	UserDataFormatIgnition UserDataFormat = "Ignition"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KubevirtMachineProviderSpecList contains a list of KubevirtMachineProviderSpec
+type KubevirtMachineProviderSpecList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubevirtMachineProviderSpec `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubevirtMachineProviderSpec{}, &KubevirtMachineProviderSpecList{}, &KubevirtMachineProviderStatus{})
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KubevirtMachineProviderStatus is the type that will be embedded in a Machine.Status.ProviderStatus field.
+// It contains KubeVirt-specific status information.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type KubevirtMachineProviderStatus struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// VMID is the UID of the VirtualMachine of the machine on the infra cluster
+	// +optional
+	VMID string `json:"vmId,omitempty"`
+
+	// Phase is the phase of the VirtualMachineInstance of the VM, empty while the VM is stopped
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// VMState is the printable state of the VM, also reported in the instance-state
+	// annotation of the machine
+	// +optional
+	VMState VMState `json:"vmState,omitempty"`
+
+	// ColdMigration tracks the last cold migration of the VM
+	// +optional
+	ColdMigration *ColdMigrationStatus `json:"coldMigration,omitempty"`
+
+	// DiagnosticsURLs holds deep links into the infra cluster consoles for the VM, such as
+	// its VM page, the logs of its virt-launcher pod or the dashboard of its infra node,
+	// keyed by the names of the URL templates configured for the provider
+	// +optional
+	DiagnosticsURLs map[string]string `json:"diagnosticsURLs,omitempty"`
+
+	// Reboot tracks the reboots scheduled by the reboot policy of the machine
+	// +optional
+	Reboot *RebootStatus `json:"reboot,omitempty"`
+
+	// LauncherResources tracks the last change of the virt-launcher limits of the VM
+	// +optional
+	LauncherResources *LauncherResourcesStatus `json:"launcherResources,omitempty"`
+
+	// Conditions is a set of conditions associated with the Machine to indicate
+	// errors or other status
+	Conditions []KubevirtMachineProviderCondition `json:"conditions,omitempty"`
+}
+
+// LauncherResizePath is how a change of the virt-launcher limits of a VM was applied.
+type LauncherResizePath string
+
+// Possible values for LauncherResizePath.
+const (
+	// LauncherResizeInPlace is set when the limits were changed on the running virt-launcher pod.
+	LauncherResizeInPlace LauncherResizePath = "InPlace"
+	// LauncherResizeOnRestart is set when the infra cluster refused to resize the running
+	// virt-launcher pod, the limits apply from the next restart of the VM.
+	LauncherResizeOnRestart LauncherResizePath = "OnRestart"
+)
+
+// LauncherResourcesStatus tracks the last change of the virt-launcher limits of a VM.
+type LauncherResourcesStatus struct {
+	// Path is how the limits were applied.
+	Path LauncherResizePath `json:"path"`
+	// PodName is the virt-launcher pod the limits were applied to.
+	PodName string `json:"podName"`
+	// CPULimit is the CPU limit applied.
+	// +optional
+	CPULimit string `json:"cpuLimit,omitempty"`
+	// MemoryLimit is the memory limit applied.
+	// +optional
+	MemoryLimit string `json:"memoryLimit,omitempty"`
+	// Message is a human-readable message about the change.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RebootStatus tracks the reboots scheduled by the reboot policy of a machine.
+type RebootStatus struct {
+	// LastRebootTime is the time the guest was last rebooted by the reboot policy.
+	// +optional
+	LastRebootTime *metav1.Time `json:"lastRebootTime,omitempty"`
+	// NextRebootTime is the time the next reboot of the guest is due.
+	// +optional
+	NextRebootTime *metav1.Time `json:"nextRebootTime,omitempty"`
+	// Message is a human-readable message about the next reboot.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VMState is the printable state of a VM, derived from the VM and its VirtualMachineInstance.
+type VMState string
+
+// Possible values for VMState.
+const (
+	// VMStateStopped is set while the VM has no VirtualMachineInstance.
+	VMStateStopped VMState = "Stopped"
+	// VMStateStarting is set while the VirtualMachineInstance is scheduled and started.
+	VMStateStarting VMState = "Starting"
+	// VMStateRunning is set while the VirtualMachineInstance is running.
+	VMStateRunning VMState = "Running"
+	// VMStateMigrating is set while the VirtualMachineInstance is live migrated.
+	VMStateMigrating VMState = "Migrating"
+	// VMStatePaused is set while the VirtualMachineInstance is paused.
+	VMStatePaused VMState = "Paused"
+	// VMStateStopping is set while the VirtualMachineInstance of a VM requested to stop shuts down.
+	VMStateStopping VMState = "Stopping"
+	// VMStateTerminating is set while the VM is deleted.
+	VMStateTerminating VMState = "Terminating"
+	// VMStateCrashLoopBackOff is set while the VirtualMachineInstance of a VM meant to run failed.
+	VMStateCrashLoopBackOff VMState = "CrashLoopBackOff"
+)
+
+// ColdMigrationPhase is the step a cold migration is at.
+type ColdMigrationPhase string
+
+// Possible values for ColdMigrationPhase.
+const (
+	// ColdMigrationStopping is set while the VM is being stopped.
+	ColdMigrationStopping ColdMigrationPhase = "Stopping"
+	// ColdMigrationCloning is set while the root disk is cloned to the target volume.
+	ColdMigrationCloning ColdMigrationPhase = "Cloning"
+	// ColdMigrationStarting is set while the VM is started from the target volume.
+	ColdMigrationStarting ColdMigrationPhase = "Starting"
+	// ColdMigrationSucceeded is set once the VM runs from the target volume.
+	ColdMigrationSucceeded ColdMigrationPhase = "Succeeded"
+	// ColdMigrationFailed is set when the target volume could not be cloned,
+	// the VM is started again from the source volume.
+	ColdMigrationFailed ColdMigrationPhase = "Failed"
+)
+
+// ColdMigrationStatus describes an offline move of the VM to another storage class or zone.
+type ColdMigrationStatus struct {
+	// Phase is the step the cold migration is at.
+	Phase ColdMigrationPhase `json:"phase"`
+	// TargetStorageClass is the storage class the root disk is moved to.
+	// +optional
+	TargetStorageClass string `json:"targetStorageClass,omitempty"`
+	// TargetZone is the infra zone the VM is moved to.
+	// +optional
+	TargetZone string `json:"targetZone,omitempty"`
+	// SourceVolume is the DataVolume the root disk is moved from.
+	SourceVolume string `json:"sourceVolume,omitempty"`
+	// TargetVolume is the DataVolume the root disk is moved to.
+	TargetVolume string `json:"targetVolume,omitempty"`
+	// StartTime is the time the cold migration was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the cold migration succeeded or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message is a human-readable message about the current step.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// KubevirtMachineProviderConditionType is a valid value for KubevirtMachineProviderCondition.Type
+type KubevirtMachineProviderConditionType string
+
+// Valid conditions for a KubeVirt machine.
+const (
+	// MachineCreation indicates whether the machine has been created or not. If not,
+	// it should include a reason and message for the failure.
+	MachineCreation KubevirtMachineProviderConditionType = "MachineCreation"
+	// OutdatedBootImage indicates whether the machine was cloned from an older revision
+	// of its boot image source than the one currently published on the infra cluster.
+	OutdatedBootImage KubevirtMachineProviderConditionType = "OutdatedBootImage"
+	// KubeletCertificateSANs indicates whether the kubelet serving certificate of the node
+	// covers the static IP addresses of the machine.
+	KubeletCertificateSANs KubevirtMachineProviderConditionType = "KubeletCertificateSANs"
+	// AdvancedTuningActive indicates the VM is tuned through settings not modeled by the provider
+	// spec, which are outside of the supported configuration.
+	AdvancedTuningActive KubevirtMachineProviderConditionType = "AdvancedTuningActive"
+	// LiveMigratable indicates whether the VM can be live migrated, which the LiveMigrate
+	// eviction strategy relies on to survive drains of its infra node.
+	LiveMigratable KubevirtMachineProviderConditionType = "LiveMigratable"
+	// GuestProblems indicates node-problem-detector reports problems of the virtualized node,
+	// e.g. virtio errors or clock jumps, correlated with the state of the VM.
+	GuestProblems KubevirtMachineProviderConditionType = "GuestProblems"
+	// CircuitOpen indicates the creation of the VM is stopped, as the machines of its
+	// MachineSet exhausted their provisioning failure budget.
+	CircuitOpen KubevirtMachineProviderConditionType = "CircuitOpen"
+	// VMRunning indicates whether the VirtualMachineInstance of the VM is running.
+	VMRunning KubevirtMachineProviderConditionType = "VMRunning"
+	// VMErrored indicates whether KubeVirt reports a failure of the VM or its
+	// VirtualMachineInstance.
+	VMErrored KubevirtMachineProviderConditionType = "VMErrored"
+	// GuestShutdown indicates how the last shutdown of the guest from within was handled.
+	GuestShutdown KubevirtMachineProviderConditionType = "GuestShutdown"
+)
+
+// KubevirtMachineProviderConditionReason is reason for the condition's last transition.
+type KubevirtMachineProviderConditionReason string
+
+const (
+	// MachineCreationSucceeded indicates machine creation success.
+	MachineCreationSucceeded KubevirtMachineProviderConditionReason = "MachineCreationSucceeded"
+	// MachineCreationFailed indicates machine creation failure.
+	MachineCreationFailed KubevirtMachineProviderConditionReason = "MachineCreationFailed"
+	// BootImageUpToDate indicates the machine runs the current revision of its boot image.
+	BootImageUpToDate KubevirtMachineProviderConditionReason = "BootImageUpToDate"
+	// BootImageUpdated indicates the boot image source was replaced after the machine was created.
+	BootImageUpdated KubevirtMachineProviderConditionReason = "BootImageUpdated"
+	// BootImageUnknown indicates the revision the machine was created from could not be determined.
+	BootImageUnknown KubevirtMachineProviderConditionReason = "BootImageUnknown"
+	// CertificateSANsMatch indicates the kubelet serving certificate covers all static IP addresses.
+	CertificateSANsMatch KubevirtMachineProviderConditionReason = "CertificateSANsMatch"
+	// CertificateSANsMismatch indicates the kubelet serving certificate misses some static IP addresses.
+	CertificateSANsMismatch KubevirtMachineProviderConditionReason = "CertificateSANsMismatch"
+	// CertificateNotFound indicates no kubelet serving certificate request was found for the node.
+	CertificateNotFound KubevirtMachineProviderConditionReason = "CertificateNotFound"
+	// AdvancedTuningApplied indicates advanced tuning annotations are set on the VM.
+	AdvancedTuningApplied KubevirtMachineProviderConditionReason = "AdvancedTuningApplied"
+	// VMIMigratable indicates KubeVirt reports the VM as live migratable.
+	VMIMigratable KubevirtMachineProviderConditionReason = "VMIMigratable"
+	// VMINotMigratable indicates KubeVirt reports the VM as not live migratable.
+	VMINotMigratable KubevirtMachineProviderConditionReason = "VMINotMigratable"
+	// GuestProblemsDetected indicates the node reports problems of the virtualized node.
+	GuestProblemsDetected KubevirtMachineProviderConditionReason = "GuestProblemsDetected"
+	// NoGuestProblems indicates the node reports no problems of the virtualized node.
+	NoGuestProblems KubevirtMachineProviderConditionReason = "NoGuestProblems"
+	// FailureBudgetExhausted indicates the MachineSet reached its consecutive provisioning failures threshold.
+	FailureBudgetExhausted KubevirtMachineProviderConditionReason = "FailureBudgetExhausted"
+	// FailureBudgetAvailable indicates the MachineSet is below its consecutive provisioning failures threshold.
+	FailureBudgetAvailable KubevirtMachineProviderConditionReason = "FailureBudgetAvailable"
+	// VMIRunning indicates the VirtualMachineInstance is running.
+	VMIRunning KubevirtMachineProviderConditionReason = "VMIRunning"
+	// VMINotRunning indicates the VM is stopped or its VirtualMachineInstance is not running yet.
+	VMINotRunning KubevirtMachineProviderConditionReason = "VMINotRunning"
+	// VMFailure indicates KubeVirt reports a failure of the VM or its VirtualMachineInstance.
+	VMFailure KubevirtMachineProviderConditionReason = "VMFailure"
+	// NoVMFailure indicates KubeVirt reports no failure of the VM.
+	NoVMFailure KubevirtMachineProviderConditionReason = "NoVMFailure"
+	// GuestShutdownRestarted indicates the VM was restarted after the guest shut down.
+	GuestShutdownRestarted KubevirtMachineProviderConditionReason = "GuestShutdownRestarted"
+	// GuestShutdownFailed indicates the machine was marked failed after the guest shut down.
+	GuestShutdownFailed KubevirtMachineProviderConditionReason = "GuestShutdownFailed"
+	// GuestShutdownHalted indicates the VM was kept stopped after the guest shut down.
+	GuestShutdownHalted KubevirtMachineProviderConditionReason = "GuestShutdownHalted"
+)
+
+// KubevirtMachineProviderCondition is a condition in a KubevirtMachineProviderStatus.
+type KubevirtMachineProviderCondition struct {
+	// Type is the type of the condition.
+	Type KubevirtMachineProviderConditionType `json:"type"`
+	// Status is the status of the condition.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastProbeTime is the last time we probed the condition.
+	// +optional
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a unique, one-word, CamelCase reason for the condition's last transition.
+	// +optional
+	Reason KubevirtMachineProviderConditionReason `json:"reason,omitempty"`
+	// Message is a human-readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -1,4 +1,5 @@
-Copyright 2015 James Saryerwinnie
+/*
+Copyright 2020 The Kubernetes Authors.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -11,3 +12,10 @@ distributed under the License is distributed on an "AS IS" BASIS,
 WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
+*/
+
+package v1beta1
+
+// Hub marks the provider spec as the conversion hub, the version the actuator works with.
+// The provider specs of other versions are converted to it when decoded.
+func (*KubevirtMachineProviderSpec) Hub() {}
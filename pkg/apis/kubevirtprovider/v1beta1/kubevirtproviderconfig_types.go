@@ -0,0 +1,1109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KubevirtMachineProviderSpec is the Schema for the kubevirtmachineproviderspecs API
+// +k8s:openapi-gen=true
+type KubevirtMachineProviderSpec struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// SourcePvcName is the name of the PVC on the infra cluster holding the boot image.
+	// It is cloned into the root disk of every VM created from this spec.
+	SourcePvcName string `json:"sourcePvcName"`
+
+	// RequestedMemory is the amount of memory requested for the VM, e.g. 2048M.
+	// Defaults to 2048M.
+	RequestedMemory string `json:"requestedMemory,omitempty"`
+
+	// GuestMemory is the amount of memory seen by the guest, e.g. 4096M, for the VM to only
+	// request requestedMemory on the infra cluster, deliberately overcommitting its memory. It
+	// must not be below requestedMemory. The memory balloon KubeVirt attaches to every VMI
+	// reports the free guest memory for the infra node to reclaim. Defaults to requestedMemory.
+	// +optional
+	GuestMemory string `json:"guestMemory,omitempty"`
+
+	// OvercommitGuestOverhead leaves the memory overhead of the virt-launcher pod out of the
+	// memory it requests, only adding it to its memory limit, for more VMs to be scheduled on
+	// an infra node at the risk of running it out of memory.
+	// +optional
+	OvercommitGuestOverhead bool `json:"overcommitGuestOverhead,omitempty"`
+
+	// RequestedCPU is the number of vCPU cores requested for the VM.
+	// Defaults to 1.
+	RequestedCPU uint32 `json:"requestedCPU,omitempty"`
+
+	// DedicatedCPUPlacement requests the VM to be placed on an infra node with enough
+	// dedicated pCPUs and to have its vCPUs pinned to them.
+	// +optional
+	DedicatedCPUPlacement bool `json:"dedicatedCpuPlacement,omitempty"`
+
+	// Hugepages backs the memory of the VM with hugepages, requestedMemory has to be a
+	// multiple of their page size.
+	// +optional
+	Hugepages *Hugepages `json:"hugepages,omitempty"`
+
+	// NUMAResourceCheck holds back the creation of the VM until an infra node matching the
+	// node selector has the hugepages and the dedicated CPUs of the VM free on a single NUMA
+	// node, as reported by the NodeResourceTopology objects of the infra cluster. It suits
+	// infra nodes running the single-numa-node topology manager policy, which would leave
+	// the VM pending otherwise.
+	// +optional
+	NUMAResourceCheck bool `json:"numaResourceCheck,omitempty"`
+
+	// RequestedStorage is the size of the root disk of the VM, e.g. 35Gi.
+	// Defaults to 35Gi.
+	RequestedStorage string `json:"requestedStorage,omitempty"`
+
+	// StorageClassName is the storage class used for the root disk. If not set,
+	// the default storage class of the infra cluster is used.
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// DiskBus is the bus the root disk is attached with, either virtio for a virtio-blk
+	// device, scsi for a virtio-scsi controller or sata. Defaults to virtio, or to sata for
+	// Windows guests.
+	// +optional
+	DiskBus DiskBus `json:"diskBus,omitempty"`
+
+	// BlockMultiQueue enables one queue per vCPU for the virtio-blk root disk, improving
+	// throughput of IO-heavy guests. Requires the virtio disk bus and at least 2 vCPUs.
+	// +optional
+	BlockMultiQueue bool `json:"blockMultiQueue,omitempty"`
+
+	// ScratchDisks are sparse disks attached to the VM next to the root disk, backed by the
+	// ephemeral storage of the virt-launcher pod rather than PVCs. They are blank whenever
+	// the VM starts, e.g. for the build scratch space of CI runners.
+	// +optional
+	ScratchDisks []ScratchDisk `json:"scratchDisks,omitempty"`
+
+	// DisableRNG leaves out the virtio RNG device attached to VMs by default. The device feeds
+	// the guest entropy from the infra node, without which the guest may stall generating the
+	// keys of its TLS bootstrap.
+	// +optional
+	DisableRNG bool `json:"disableRNG,omitempty"`
+
+	// Watchdog attaches an i6300esb watchdog device to the VM, which the infra node acts on
+	// when the guest stops petting it, recovering hung guests ahead of a MachineHealthCheck.
+	// The guest arms the watchdog itself, e.g. with the RuntimeWatchdogSec setting of systemd.
+	// +optional
+	Watchdog *Watchdog `json:"watchdog,omitempty"`
+
+	// SMBIOS sets the system identity the VM reports through SMBIOS, for guest tooling keying
+	// off the hardware identity, e.g. licensing or asset tracking, to see the same values
+	// across restarts and live migrations of the VM. The manufacturer, product and version
+	// are set for all VMs by the KubeVirt configuration of the infra cluster.
+	// +optional
+	SMBIOS *SMBIOS `json:"smbios,omitempty"`
+
+	// Clock sets the clock offset and the paravirtualized timers of the VM, on top of the
+	// clock set for Windows guests.
+	// +optional
+	Clock *Clock `json:"clock,omitempty"`
+
+	// UserDataSecret references the secret that contains the UserData to apply to the VM.
+	// The rendered UserData is stored in a secret next to the VM on the infra cluster,
+	// which the VM mounts as its cloud-init secret, so that bootstrap tokens are kept out
+	// of the Machine and VM objects.
+	UserDataSecret *UserDataSecretReference `json:"userDataSecret,omitempty"`
+
+	// SSHKeys are SSH public keys authorized on the guest, merged into the UserData.
+	// +optional
+	SSHKeys *SSHKeys `json:"sshKeys,omitempty"`
+
+	// CredentialsSecret is a reference to the secret holding the kubeconfig of the
+	// infra cluster. Otherwise, defaults to the cluster the actuator is running in.
+	CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+
+	// InfraNamespace is the namespace of the infra cluster the VM, its DataVolumes and its
+	// UserData secret are created in, and the source PVC is read from, e.g. a namespace per
+	// tenant cluster. Defaults to the namespace of the machine. Changing it does not move
+	// the VMs already created.
+	// +optional
+	InfraNamespace string `json:"infraNamespace,omitempty"`
+
+	// CreateInfraNamespace creates the InfraNamespace on the infra cluster if it does not
+	// exist yet. The namespace is not deleted along with the machines.
+	// +optional
+	CreateInfraNamespace bool `json:"createInfraNamespace,omitempty"`
+
+	// CloudInitSource specifies how the UserData is delivered to the guest.
+	// Some images (e.g. certain RHCOS or Windows builds) only read config-drive metadata.
+	// Valid values are NoCloud and ConfigDrive. Defaults to NoCloud.
+	// +optional
+	CloudInitSource CloudInitSource `json:"cloudInitSource,omitempty"`
+
+	// UserDataFormat declares the format of the UserData. Ignition UserData is passed to
+	// the guest through the KubeVirt Ignition mechanism, or through config-drive when
+	// CloudInitSource is ConfigDrive. Valid values are CloudInit and Ignition. If not set,
+	// the format is detected from the UserData content.
+	// +optional
+	UserDataFormat UserDataFormat `json:"userDataFormat,omitempty"`
+
+	// StaticIPAddresses are the static IP addresses assigned to the guest. They are passed to
+	// the kubelet as node IPs through the UserData, so that the kubelet serving certificate
+	// carries them as SANs. Once the node joined, the SANs of the certificate are verified
+	// and reported with the KubeletCertificateSANs condition.
+	// +optional
+	StaticIPAddresses []string `json:"staticIPAddresses,omitempty"`
+
+	// AddressFamilyPolicy selects the IP families of the machine, IPv4, IPv6 or Dual. The
+	// interfaces of the networkData enabling dhcp4 get DHCP for the families of the policy,
+	// static addresses must belong to them, and the kubelet is passed the staticIPAddresses
+	// as node IPs in the order of the families, IPv4 first for Dual, or the unspecified
+	// address of the family without them. The machine addresses are restricted to the
+	// families. If not set, the families are not restricted.
+	// +optional
+	AddressFamilyPolicy AddressFamilyPolicy `json:"addressFamilyPolicy,omitempty"`
+
+	// RequireGuestAgent holds the machine from being reported ready until the qemu-guest-agent
+	// of the guest connected, the addresses of the machine being those the agent reports.
+	// Guests without the agent never become ready with it set.
+	// +optional
+	RequireGuestAgent bool `json:"requireGuestAgent,omitempty"`
+
+	// Windows provisions a Windows guest, with the Hyper-V enlightenments and the clock
+	// Windows expects, devices it supports without the virtio drivers and a sysprep answer
+	// file specializing the generalized image of the source PVC.
+	// +optional
+	Windows *Windows `json:"windows,omitempty"`
+
+	// Architecture is the CPU architecture of the guest, amd64 by default. arm64 guests run
+	// the virt machine type with UEFI firmware, are scheduled onto arm64 infra nodes and
+	// require a source PVC that is not labeled for another architecture.
+	// +optional
+	Architecture Architecture `json:"architecture,omitempty"`
+
+	// AllowEmulation lets arm64 guests run under software virtualization, on infra nodes of
+	// their architecture that lack hardware virtualization. It requires the emulation of the
+	// infra KubeVirt to be enabled, a setting of the whole infra cluster, and is not supported
+	// for amd64 guests. It is meant for development environments, emulated guests are much
+	// slower.
+	// +optional
+	AllowEmulation bool `json:"allowEmulation,omitempty"`
+
+	// NetworkData declares the network configuration of the guest, rendered as cloud-init
+	// network config version 2 and handed to the guest along with the UserData. It
+	// requires CloudInit UserData delivered through the NoCloud CloudInitSource.
+	// +optional
+	NetworkData *NetworkData `json:"networkData,omitempty"`
+
+	// Interfaces attach the VM to the networks of the infra cluster, the pod network and
+	// secondary Multus networks, in the order of the interfaces of the guest. If not set, the
+	// VM is attached to the pod network only, as KubeVirt defaults it. The pod network of
+	// IPv6 and Dual machines must use the Masquerade binding.
+	// +optional
+	Interfaces []NetworkInterface `json:"interfaces,omitempty"`
+
+	// ShareUserDataSecret keeps the CloudInit UserData and NetworkData of the machines of a
+	// MachineSet in one secret on the infra cluster instead of one secret per machine. The
+	// per-machine hostname and instance-id reach the guest through the instance metadata.
+	// It cannot be combined with staticIPAddresses or domainSuffix, which are rendered into
+	// the UserData of each machine.
+	// +optional
+	ShareUserDataSecret bool `json:"shareUserDataSecret,omitempty"`
+
+	// TrackBootImage enables tracking of the source PVC the root disk was cloned from.
+	// When the source PVC is replaced on the infra cluster (e.g. by a DataImportCron
+	// importing a newer image), machines cloned from the previous revision are reported
+	// with the OutdatedBootImage condition so that they can be rolled out.
+	// +optional
+	TrackBootImage bool `json:"trackBootImage,omitempty"`
+
+	// BootImageImport keeps the boot image imported from a registry on the infra cluster
+	// with a CDI DataImportCron, managed by the controller when started with
+	// --boot-image-cache. SourcePvcName then names the DataSource the DataImportCron
+	// publishes the last imported image to, and root disks are cloned from the PVC the
+	// DataSource points to.
+	// +optional
+	BootImageImport *BootImageImport `json:"bootImageImport,omitempty"`
+
+	// DomainSuffix is the DNS domain appended to the machine name to form the FQDN of the
+	// guest. The guest hostname is always set to the machine name through the instance
+	// metadata, when DomainSuffix is set the FQDN is written through the UserData as well.
+	// +optional
+	DomainSuffix string `json:"domainSuffix,omitempty"`
+
+	// Affinity is copied into the VM template and applies to the virt-launcher pods of the
+	// VM on the infra cluster, e.g. to keep control plane VMs apart with pod anti-affinity.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// NodeSelector is copied into the VM template so that the VM is only scheduled to
+	// infra nodes carrying the given labels, e.g. GPU or SSD hosts.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// NodeLabels are set onto the tenant Node of the machine once it joins, along with the
+	// node-role label of the machine role and the labels of the machine spec, so that the
+	// nodes of a worker pool are labeled without a separate day-2 step. Labels are only
+	// added or updated, never removed from the Node.
+	// +optional
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+	// NodeTaints are applied to the tenant Node of the machine as soon as it registers, so
+	// that dedicated pools, e.g. for GPU or infra workloads, are tainted before workloads
+	// land on them. Taints are matched by key and effect, they are only added or updated,
+	// never removed from the Node.
+	// +optional
+	NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
+
+	// Tolerations are copied into the VM template so that the VM can be scheduled to
+	// tainted infra nodes.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// FailureDomain places the VM in a failure domain of the infra cluster. MachineSets
+	// use one failure domain each, or spread their VMs across the failure domains.
+	// +optional
+	FailureDomain *FailureDomain `json:"failureDomain,omitempty"`
+
+	// RunStrategy is the run strategy of the VM. Always and RerunOnFailure leave it to
+	// KubeVirt to keep the VM running, Manual has the controller start the VM once it is
+	// created and leaves it stopped after a shutdown. Defaults to running the VM, as Always.
+	// +optional
+	RunStrategy RunStrategy `json:"runStrategy,omitempty"`
+
+	// GuestShutdownPolicy is applied when the guest powers itself off. Restart starts the VM
+	// again, Fail marks the machine failed and Halt keeps the VM stopped as intended. It
+	// requires the RerunOnFailure or Manual run strategy, with which KubeVirt leaves the VM
+	// stopped after a guest shutdown. Defaults to leaving it to the run strategy.
+	// +optional
+	GuestShutdownPolicy GuestShutdownPolicy `json:"guestShutdownPolicy,omitempty"`
+
+	// UpdatePolicy is applied when the provider spec drifts from the live VM, e.g. once the
+	// requested CPU or memory of the machine changed. InPlace updates the VM, the changes
+	// applying from its next restart, Restart updates and restarts the VM, and Recreate leaves
+	// the VM as is for the machine to be replaced. Changes to the root disk always require
+	// the machine to be replaced. The VMUpToDate condition and the events of the machine
+	// report the decision. Defaults to Recreate.
+	// +optional
+	UpdatePolicy UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// EvictionStrategy is the strategy KubeVirt applies to the VM when its infra node is
+	// drained. LiveMigrate migrates the VM to another infra node, None shuts it down.
+	// Defaults to the eviction strategy configured for the infra cluster.
+	// +optional
+	EvictionStrategy EvictionStrategy `json:"evictionStrategy,omitempty"`
+
+	// RootVolumeAccessMode is the access mode of the root disk volume. Live migration needs
+	// volumes shared by the source and target infra nodes, so it defaults to ReadWriteMany
+	// with the LiveMigrate eviction strategy and to ReadWriteOnce otherwise.
+	// +optional
+	RootVolumeAccessMode corev1.PersistentVolumeAccessMode `json:"rootVolumeAccessMode,omitempty"`
+
+	// RootVolumeMode is the mode of the root disk. Persistent, the default, clones the source
+	// PVC into a DataVolume of the machine. Ephemeral attaches the source PVC read-only with a
+	// copy-on-write overlay local to the virt-launcher pod, discarded whenever the VM stops,
+	// for stateless workers that consume no storage of their own and start without waiting
+	// for a clone. The source PVC must then be accessible from all infra nodes running VMs
+	// out of it, and the VMs cannot be live migrated.
+	// +optional
+	RootVolumeMode RootVolumeMode `json:"rootVolumeMode,omitempty"`
+
+	// Preemptible runs the machine on spare infra capacity. The virt-launcher pod of the VM
+	// gets a low priority for the infra cluster to preempt it in favor of other workloads, the
+	// VM is shut down rather than live migrated on drains of its infra node, and its tenant
+	// Node is labeled interruptible and tainted for workloads to opt in. While the VM is being
+	// evicted the tenant Node is cordoned. It excludes the LiveMigrate eviction strategy.
+	// +optional
+	Preemptible *Preemptible `json:"preemptible,omitempty"`
+
+	// AdvancedTuning holds settings not modeled by the provider spec, for expert users.
+	// It is only honored by controllers started with --enable-advanced-tuning, machines
+	// using it are reported with the AdvancedTuningActive condition.
+	// +optional
+	AdvancedTuning *AdvancedTuning `json:"advancedTuning,omitempty"`
+
+	// RebootPolicy schedules periodic reboots of the guest, e.g. to pick up kernel updates
+	// staged in an immutable image.
+	// +optional
+	RebootPolicy *RebootPolicy `json:"rebootPolicy,omitempty"`
+
+	// LauncherResources are limits of the virt-launcher pod of the VM, giving it headroom
+	// above the memory and CPU seen by the guest. Changes are applied to the running pod
+	// where the infra cluster supports in-place pod resize, and at the next restart of the
+	// VM otherwise. They cannot be combined with dedicatedCpuPlacement.
+	// +optional
+	LauncherResources *LauncherResources `json:"launcherResources,omitempty"`
+
+	// LauncherOverhead accounts for the resources the virt-launcher pod of the VM consumes on
+	// top of its guest. The VM requests the CPUs and memory of its guest plus the overhead, in
+	// place of the memory overhead KubeVirt adds, and its CPU limit includes the overhead. The
+	// MachineSets of the spec are annotated with the resulting infra footprint of a machine.
+	// It cannot be combined with dedicatedCpuPlacement or overcommitGuestOverhead.
+	// +optional
+	LauncherOverhead *LauncherOverhead `json:"launcherOverhead,omitempty"`
+
+	// LauncherSecurity sets the security profile of the virt-launcher pod of the VM, for infra
+	// clusters whose policies only admit pods with one.
+	// +optional
+	LauncherSecurity *LauncherSecurity `json:"launcherSecurity,omitempty"`
+
+	// BootVolumeRetryPolicy deletes the boot DataVolume of the VM when its import is stuck, so
+	// that KubeVirt recreates it and the import starts over. Without it a stuck import leaves
+	// the machine provisioning until it is deleted.
+	// +optional
+	BootVolumeRetryPolicy *BootVolumeRetryPolicy `json:"bootVolumeRetryPolicy,omitempty"`
+
+	// GracefulShutdownTimeout shuts the guest down cleanly before its VM is deleted. The VM is
+	// stopped first, for KubeVirt to request the shutdown through the guest agent or an ACPI
+	// power button event, and deleted once the guest powered off or the timeout elapsed, the
+	// VM being forcibly stopped then. It is also the termination grace period of the VMI.
+	// Without it the VM is deleted right away.
+	// +optional
+	GracefulShutdownTimeout *metav1.Duration `json:"gracefulShutdownTimeout,omitempty"`
+
+	// OvercommitProfile is the name of an overcommit profile of the controller, which sets the
+	// ratios of the CPUs and memory seen by the guest to the resources requested on the infra
+	// cluster, and whether the limits are set to the requests. Without it the VM requests the
+	// memory of its guest and leaves the CPU request to KubeVirt.
+	// +optional
+	OvercommitProfile string `json:"overcommitProfile,omitempty"`
+
+	// NodeDrainTimeout bounds the cordon and drain of the tenant Node of a deleted machine,
+	// which come before the deletion of its VM. Once it elapsed since the deletion of the
+	// machine, the drain is skipped and the VM deleted with the pods left on the Node. The drain
+	// can be skipped right away with the machine.openshift.io/exclude-node-draining annotation.
+	// Without it the drain is retried until all pods are evicted.
+	// +optional
+	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// MaxLifetime is the age after which the machine is expired, counted from its creation. The
+	// LifetimeExceeded condition reports expired machines, which are otherwise handled according
+	// to the expiredMachineAction.
+	// +optional
+	MaxLifetime *metav1.Duration `json:"maxLifetime,omitempty"`
+
+	// ExpiredMachineAction is applied once the machine exceeded its maxLifetime. MarkForDeletion
+	// sets the delete-machine annotation, for its MachineSet to delete it first on the next scale
+	// down. Delete deletes the machine for its MachineSet to replace it, one machine of the
+	// MachineSet at a time. Defaults to Report, which only sets the condition.
+	// +optional
+	ExpiredMachineAction ExpiredMachineAction `json:"expiredMachineAction,omitempty"`
+
+	// DataExport clones volumes of the VM into an archive namespace of the infra cluster before
+	// the VM is deleted, for their data to be retained past the machine. The DataExported
+	// condition tracks the export. Without it the volumes are deleted along with the VM.
+	// +optional
+	DataExport *DataExport `json:"dataExport,omitempty"`
+
+	// SnapshotOnDelete takes a VirtualMachineSnapshot of the VM before the VM is deleted, for an
+	// accidentally deleted machine to be restored out of it. The SnapshotTaken condition tracks
+	// the snapshot. Without it nothing is retained past the VM, unless exported by DataExport.
+	// +optional
+	SnapshotOnDelete *SnapshotOnDelete `json:"snapshotOnDelete,omitempty"`
+
+	// ControlPlaneLoadBalancer is set on the control plane machines of the tenant cluster for an
+	// infra cluster Service to front the API server of their VMs. The endpoints of the Service
+	// follow the control plane VMs as the machines come and go. The ControlPlaneLoadBalancerReady
+	// condition and the controlPlaneEndpoint of the provider status report the endpoint.
+	// +optional
+	ControlPlaneLoadBalancer *ControlPlaneLoadBalancer `json:"controlPlaneLoadBalancer,omitempty"`
+
+	// SerialConsoleLog captures the serial console of the guest into a ConfigMap next to the
+	// machine when its node did not join the cluster within a boot timeout, to debug machines
+	// which never join without virtctl access to the infra cluster. The SerialConsoleCaptured
+	// condition and the provider status report the capture.
+	// +optional
+	SerialConsoleLog *SerialConsoleLog `json:"serialConsoleLog,omitempty"`
+}
+
+// SSHKeys holds SSH public keys, either inline or from a secret.
+type SSHKeys struct {
+	// Keys are SSH public keys in authorized_keys format.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+
+	// SecretRef references a secret in the machine namespace. Every value of the secret
+	// holds one or more SSH public keys in authorized_keys format.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// UserDataSecretReference references a key of a secret holding UserData.
+type UserDataSecretReference struct {
+	// Name of the secret.
+	Name string `json:"name"`
+
+	// Namespace of the secret. Defaults to the namespace of the machine.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the UserData in the secret. Defaults to userData.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// CloudInitSource is the cloud-init datasource through which UserData is
+// exposed to the guest.
+type CloudInitSource string
+
+// Possible values for CloudInitSource.
+const (
+	// CloudInitNoCloud delivers UserData through the cloud-init NoCloud datasource.
+	CloudInitNoCloud CloudInitSource = "NoCloud"
+	// CloudInitConfigDrive delivers UserData through the OpenStack config-drive datasource.
+	CloudInitConfigDrive CloudInitSource = "ConfigDrive"
+)
+
+// FailureDomain is a failure domain of the infra cluster, identified by the value of a
+// topology label of the infra nodes.
+type FailureDomain struct {
+	// Zone pins the VM to the infra nodes with the given value of the topology key.
+	// It is also set as the machine.openshift.io/zone label of the Machine.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// TopologyKey is the infra node label the failure domains are identified by.
+	// Defaults to topology.kubernetes.io/zone.
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// Spread makes the VMs of the MachineSet of the machine prefer infra nodes in distinct
+	// failure domains, through pod anti-affinity of their virt-launcher pods.
+	// +optional
+	Spread bool `json:"spread,omitempty"`
+}
+
+// LauncherResources are resource limits of the virt-launcher pod of a VM.
+type LauncherResources struct {
+	// CPULimit is the CPU limit of the virt-launcher pod, e.g. 2500m.
+	// +optional
+	CPULimit string `json:"cpuLimit,omitempty"`
+
+	// MemoryLimit is the memory limit of the virt-launcher pod, e.g. 3Gi. It must not be
+	// below the requested memory, which remains the memory seen by the guest.
+	// +optional
+	MemoryLimit string `json:"memoryLimit,omitempty"`
+}
+
+// LauncherOverhead is the resource overhead of the virt-launcher pod of a VM.
+type LauncherOverhead struct {
+	// CPU is the CPU consumed by virt-launcher, libvirt and QEMU besides the vCPUs of the
+	// guest, e.g. 200m. Defaults to 100m.
+	// +optional
+	CPU string `json:"cpu,omitempty"`
+
+	// Memory is the memory consumed by the virt-launcher pod besides the memory of the guest,
+	// e.g. 300Mi. Defaults to the estimate of KubeVirt out of the guest memory and vCPUs.
+	// +optional
+	Memory string `json:"memory,omitempty"`
+}
+
+// RebootPolicy schedules periodic reboots of the guest.
+type RebootPolicy struct {
+	// Interval is the time between two reboots of the guest, counted from the creation of
+	// the machine for the first reboot.
+	Interval metav1.Duration `json:"interval"`
+
+	// MaintenanceWindow restricts the reboots to a daily window. Reboots falling due outside
+	// of the window are postponed to its next opening.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// MaxUnavailable is the number of machines of the MachineSet of the machine that may be
+	// rebooting or otherwise not running at the same time. A reboot falling due while the
+	// budget is exhausted waits for the other machines. Defaults to 1.
+	// +optional
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+}
+
+// BootVolumeRetryPolicy configures when and how often a stuck import of the boot DataVolume
+// is retried.
+type BootVolumeRetryPolicy struct {
+	// StuckTimeout is the time without import progress after which the import is considered
+	// stuck. Imports whose importer pod is crashlooping or whose DataVolume failed are retried
+	// right away. Defaults to 15m.
+	// +optional
+	StuckTimeout *metav1.Duration `json:"stuckTimeout,omitempty"`
+
+	// MaxRetries is the number of times the import is retried before the machine is marked
+	// failed. Defaults to 3.
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// Backoff is the minimum time between two retries, doubled after each retry.
+	// Defaults to 1m.
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
+// DataExport configures the volumes exported before the deletion of a VM.
+type DataExport struct {
+	// Volumes are the names of the volumes of the VM exported, which are backed by DataVolumes
+	// or PersistentVolumeClaims, e.g. rootdisk for the root disk.
+	Volumes []string `json:"volumes"`
+
+	// ArchiveNamespace is the infra namespace the volumes are cloned to, as DataVolumes named
+	// after the machine and the volume. The identity of the provider on the infra cluster
+	// needs to be allowed to clone from the namespace of the VM into it.
+	ArchiveNamespace string `json:"archiveNamespace"`
+
+	// StorageClass is the storage class of the archived volumes. Defaults to the storage class
+	// of the exported volumes.
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// Timeout bounds the export, counted from its start, which stops the VM for its volumes to
+	// be cloned. Once it elapsed the VM is deleted with the volumes not exported yet. Defaults to 1h.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// SnapshotOnDelete configures the VirtualMachineSnapshot taken before the deletion of a VM. The
+// snapshot is taken in the namespace of the VM, labeled with the name of the machine, and needs
+// a VolumeSnapshotClass for the storage class of the root disk. A deleted machine is restored by
+// recreating its VM from the snapshot content and restoring the snapshot with a
+// VirtualMachineRestore.
+type SnapshotOnDelete struct {
+	// TTL is how long the snapshot is retained, counted from its creation. Expired snapshots are
+	// deleted by the provider whenever it creates or deletes a machine in their namespace.
+	// Defaults to 72h.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// Timeout bounds the snapshot, counted from its start, which stops the VM. Once it elapsed
+	// the VM is deleted without the snapshot being ready. Defaults to 30m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Windows configures a Windows guest.
+type Windows struct {
+	// Sysprep references the answer file of the guest, handed to Windows on a CD-ROM along
+	// with the UserData on the cloud-init volume, e.g. for cloudbase-init to consume. If not
+	// set, an answer file is rendered for the machine, naming the guest after the machine,
+	// skipping the out-of-box experience and running the UserData as a PowerShell script
+	// during the specialize pass. The UserData may be wrapped in <powershell> tags.
+	// +optional
+	Sysprep *SysprepSource `json:"sysprep,omitempty"`
+
+	// TimeZone is the Windows time zone set by the rendered answer file, such as
+	// Pacific Standard Time. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// InterfaceModel is the model of the network interfaces of the guest, e1000e, which
+	// Windows supports without the virtio drivers, or virtio for images carrying the
+	// virtio-win drivers. Defaults to e1000e.
+	// +optional
+	InterfaceModel string `json:"interfaceModel,omitempty"`
+}
+
+// SysprepSource references the ConfigMap or the Secret holding a sysprep answer file,
+// under the unattend.xml or the autounattend.xml key, in the namespace of the VM.
+// Exactly one of them is set.
+type SysprepSource struct {
+	// ConfigMap is the ConfigMap holding the answer file.
+	// +optional
+	ConfigMap *corev1.LocalObjectReference `json:"configMap,omitempty"`
+
+	// Secret is the Secret holding the answer file.
+	// +optional
+	Secret *corev1.LocalObjectReference `json:"secret,omitempty"`
+}
+
+// SerialConsoleLog configures the capture of the serial console of a guest. The console is
+// captured once per VirtualMachineInstance, into the ConfigMap <machine>-serial-console owned
+// by the machine.
+type SerialConsoleLog struct {
+	// SidecarImage is the image of a KubeVirt hook sidecar added to the virt-launcher pod
+	// which logs the serial console of the guest from its boot onwards to its standard output,
+	// the log of the sidecar being captured. Without it the serial console is read for a few
+	// seconds, capturing only what the guest prints then.
+	// +optional
+	SidecarImage string `json:"sidecarImage,omitempty"`
+
+	// BootTimeout is how long the node of the machine has to join the cluster, counted from
+	// the creation of the VirtualMachineInstance, before the serial console is captured.
+	// Defaults to 15m.
+	// +optional
+	BootTimeout *metav1.Duration `json:"bootTimeout,omitempty"`
+
+	// MaxBytes is the size the capture is trimmed to, keeping the end of the output. Defaults
+	// to 64Ki, at most 512Ki.
+	// +optional
+	MaxBytes int32 `json:"maxBytes,omitempty"`
+}
+
+// ControlPlaneLoadBalancer configures the infra cluster Service fronting the API server of the
+// control plane VMs. The Service is created in the namespace of the VMs, selecting the
+// virt-launcher pods of the VMs whose provider spec sets the same service name. It outlives the
+// machines, for the API endpoint to be kept while all of them are replaced at once, and is
+// deleted along with the namespace or by hand.
+type ControlPlaneLoadBalancer struct {
+	// ServiceName is the name of the Service, shared by the control plane machines of the
+	// tenant cluster.
+	ServiceName string `json:"serviceName"`
+
+	// Type is the type of the Service, LoadBalancer or NodePort. NodePort Services expose the
+	// API server on their node port of every infra node. Defaults to LoadBalancer.
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Port is the port the API server listens on in the VMs, exposed at the same port by the
+	// Service. Defaults to 6443.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// MaintenanceWindow is a daily time window.
+type MaintenanceWindow struct {
+	// Start is the time of day the window opens at, in HH:MM format, in UTC.
+	Start string `json:"start"`
+
+	// Duration is how long the window stays open, at most 24h.
+	Duration metav1.Duration `json:"duration"`
+}
+
+// LauncherSecurity is the security profile of the virt-launcher pod of a VM. KubeVirt copies
+// the annotations of the VM template onto the pod, which is how the profile reaches it. The
+// SELinux type of the pod is a setting of the infra KubeVirt, it cannot be set per VM.
+type LauncherSecurity struct {
+	// SeccompProfile is the seccomp profile of the containers of the virt-launcher pod.
+	// +optional
+	SeccompProfile *SeccompProfile `json:"seccompProfile,omitempty"`
+}
+
+// SeccompProfile selects a seccomp profile.
+type SeccompProfile struct {
+	// Type is the kind of profile applied.
+	Type SeccompProfileType `json:"type"`
+
+	// LocalhostProfile is the path of the profile relative to the seccomp profile root of
+	// the infra nodes, only with the Localhost type.
+	// +optional
+	LocalhostProfile string `json:"localhostProfile,omitempty"`
+}
+
+// SeccompProfileType is the kind of a seccomp profile.
+type SeccompProfileType string
+
+// Possible values for SeccompProfileType.
+const (
+	// SeccompProfileTypeRuntimeDefault applies the default profile of the container runtime.
+	SeccompProfileTypeRuntimeDefault SeccompProfileType = "RuntimeDefault"
+	// SeccompProfileTypeLocalhost applies a profile file of the infra nodes.
+	SeccompProfileTypeLocalhost SeccompProfileType = "Localhost"
+	// SeccompProfileTypeUnconfined applies no profile.
+	SeccompProfileTypeUnconfined SeccompProfileType = "Unconfined"
+)
+
+// AdvancedTuning holds allowlisted KubeVirt settings applied to the VM as is.
+type AdvancedTuning struct {
+	// Annotations are set on the VM template. Only hook sidecars rewriting the libvirt
+	// domain XML (hooks.kubevirt.io/hookSidecars) and SMBIOS settings (smbios.vm.kubevirt.io/*)
+	// are allowed.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NetworkData is the network configuration of the guest.
+type NetworkData struct {
+	// Ethernets configure the ethernet interfaces of the guest.
+	// +optional
+	Ethernets []EthernetInterface `json:"ethernets,omitempty"`
+
+	// VLANs configure VLAN sub-interfaces on top of the ethernet interfaces.
+	// +optional
+	VLANs []VLANInterface `json:"vlans,omitempty"`
+}
+
+// EthernetInterface configures an ethernet interface of the guest.
+type EthernetInterface struct {
+	// Name is the name of the interface in the guest, e.g. eth0.
+	Name string `json:"name"`
+
+	// MACAddress matches the interface by MAC address instead of by name, which is then
+	// set as the name of the matched interface.
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+
+	// PodNetwork marks the interface attached to the pod network through the Masquerade
+	// binding. Under the IPv6 and Dual addressFamilyPolicy it is given the static IPv6 address
+	// and default route of the masquerade network, on which KubeVirt serves no DHCPv6.
+	// +optional
+	PodNetwork bool `json:"podNetwork,omitempty"`
+
+	InterfaceConfig `json:",inline"`
+}
+
+// VLANInterface configures a VLAN sub-interface of the guest.
+type VLANInterface struct {
+	// Name is the name of the sub-interface in the guest, e.g. eth0.100.
+	Name string `json:"name"`
+
+	// ID is the VLAN ID, between 1 and 4094.
+	ID int32 `json:"id"`
+
+	// Link is the name of the ethernet interface the VLAN is on.
+	Link string `json:"link"`
+
+	InterfaceConfig `json:",inline"`
+}
+
+// InterfaceConfig is the addressing and routing configuration of a guest interface.
+type InterfaceConfig struct {
+	// DHCP4 enables DHCP for IPv4 on the interface.
+	// +optional
+	DHCP4 bool `json:"dhcp4,omitempty"`
+
+	// Addresses are the static addresses of the interface in CIDR notation.
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+
+	// AddressesFromPools are the IP pools of an IPAM provider, e.g. the InClusterIPPools of the
+	// cluster-api in-cluster IPAM provider, an address of each is allocated to the interface
+	// through an IPAddressClaim of the tenant cluster. The VM is created once all addresses are
+	// allocated, with them as static addresses, gateways and node IPs. The claims are owned by
+	// the machine, the addresses are released along with it.
+	// +optional
+	AddressesFromPools []corev1.TypedLocalObjectReference `json:"addressesFromPools,omitempty"`
+
+	// MTU is the MTU of the interface.
+	// +optional
+	MTU int32 `json:"mtu,omitempty"`
+
+	// Routes are the static routes through the interface.
+	// +optional
+	Routes []Route `json:"routes,omitempty"`
+
+	// RoutingPolicy are the policy routing rules of the interface, selecting the routing
+	// table of the traffic.
+	// +optional
+	RoutingPolicy []RoutingPolicyRule `json:"routingPolicy,omitempty"`
+}
+
+// Route is a static route of the guest.
+type Route struct {
+	// To is the destination of the route in CIDR notation.
+	To string `json:"to"`
+
+	// Via is the gateway of the route.
+	// +optional
+	Via string `json:"via,omitempty"`
+
+	// Metric is the metric of the route.
+	// +optional
+	Metric int32 `json:"metric,omitempty"`
+
+	// Table is the routing table of the route, the main table if not set.
+	// +optional
+	Table int32 `json:"table,omitempty"`
+}
+
+// RoutingPolicyRule is a policy routing rule of the guest.
+type RoutingPolicyRule struct {
+	// From matches the source of the traffic in CIDR notation.
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// To matches the destination of the traffic in CIDR notation.
+	// +optional
+	To string `json:"to,omitempty"`
+
+	// Table is the routing table of the matched traffic.
+	Table int32 `json:"table"`
+
+	// Priority is the priority of the rule, lower values are evaluated first.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// NetworkInterface attaches the VM to a network of the infra cluster.
+type NetworkInterface struct {
+	// Name is the name of the interface of the VM, a DNS label unique within the VM.
+	Name string `json:"name"`
+
+	// NetworkName is the Multus NetworkAttachmentDefinition of the secondary network, as
+	// <name> or <namespace>/<name>. If not set, the interface is attached to the pod network.
+	// +optional
+	NetworkName string `json:"networkName,omitempty"`
+
+	// Binding connects the interface to the guest, Bridge, Masquerade or SRIOV. Masquerade is
+	// only supported on the pod network. Defaults to Masquerade on the pod network and Bridge
+	// on secondary networks.
+	// +optional
+	Binding InterfaceBinding `json:"binding,omitempty"`
+
+	// MACAddress is the MAC address of the interface.
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+}
+
+// Hugepages configures the hugepages backing the memory of the VM.
+type Hugepages struct {
+	// PageSize is the size of the hugepages, 2Mi or 1Gi.
+	PageSize string `json:"pageSize"`
+}
+
+// RunStrategy is the run strategy of the VM.
+type RunStrategy string
+
+// Possible values for RunStrategy.
+const (
+	// RunStrategyAlways keeps the VM running, restarting it after any shutdown.
+	RunStrategyAlways RunStrategy = "Always"
+	// RunStrategyRerunOnFailure restarts the VM after failures, but not after a guest shutdown.
+	RunStrategyRerunOnFailure RunStrategy = "RerunOnFailure"
+	// RunStrategyManual starts the VM once created, it is stopped and started on demand afterwards.
+	RunStrategyManual RunStrategy = "Manual"
+)
+
+// GuestShutdownPolicy is the handling of a VM whose guest powered itself off.
+type GuestShutdownPolicy string
+
+// Possible values for GuestShutdownPolicy.
+const (
+	// GuestShutdownRestart restarts the VM.
+	GuestShutdownRestart GuestShutdownPolicy = "Restart"
+	// GuestShutdownFail marks the machine failed, e.g. for machine health checks to replace it.
+	GuestShutdownFail GuestShutdownPolicy = "Fail"
+	// GuestShutdownHalt keeps the VM stopped until it is started again on demand.
+	GuestShutdownHalt GuestShutdownPolicy = "Halt"
+)
+
+// UpdatePolicy is the handling of a VM the provider spec drifted from.
+type UpdatePolicy string
+
+// Possible values for UpdatePolicy.
+const (
+	// UpdatePolicyInPlace updates the VM, the changes applying from its next restart.
+	UpdatePolicyInPlace UpdatePolicy = "InPlace"
+	// UpdatePolicyRestart updates the VM and restarts it for the changes to apply.
+	UpdatePolicyRestart UpdatePolicy = "Restart"
+	// UpdatePolicyRecreate leaves the VM as is, the machine has to be replaced.
+	UpdatePolicyRecreate UpdatePolicy = "Recreate"
+)
+
+// ExpiredMachineAction is the handling of a machine which exceeded its maximum lifetime.
+type ExpiredMachineAction string
+
+// Possible values for ExpiredMachineAction.
+const (
+	// ExpiredMachineReport only reports the machine as expired.
+	ExpiredMachineReport ExpiredMachineAction = "Report"
+	// ExpiredMachineMarkForDeletion prioritizes the machine for deletion by its MachineSet.
+	ExpiredMachineMarkForDeletion ExpiredMachineAction = "MarkForDeletion"
+	// ExpiredMachineDelete deletes the machine for its MachineSet to replace it.
+	ExpiredMachineDelete ExpiredMachineAction = "Delete"
+)
+
+// Preemptible configures a preemptible machine.
+type Preemptible struct {
+	// PriorityClassName is the priority class of the virt-launcher pod of the VM, which must
+	// exist in the infra cluster. Defaults to kubevirt-machine-preemptible.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// EvictionStrategy is the strategy applied to the VM when its infra node is drained.
+type EvictionStrategy string
+
+// Possible values for EvictionStrategy.
+const (
+	// EvictionStrategyLiveMigrate live migrates the VM off the drained infra node.
+	EvictionStrategyLiveMigrate EvictionStrategy = "LiveMigrate"
+	// EvictionStrategyNone shuts the VM down with the drained infra node.
+	EvictionStrategyNone EvictionStrategy = "None"
+)
+
+// RootVolumeMode is the mode of the root disk of a VM.
+type RootVolumeMode string
+
+// Possible values for RootVolumeMode.
+const (
+	// RootVolumeModePersistent clones the source PVC into the root disk of the VM.
+	RootVolumeModePersistent RootVolumeMode = "Persistent"
+	// RootVolumeModeEphemeral boots the VM off a copy-on-write overlay of the source PVC.
+	RootVolumeModeEphemeral RootVolumeMode = "Ephemeral"
+)
+
+// ScratchDisk is a blank disk of the VM which does not outlive its VMI.
+type ScratchDisk struct {
+	// Name is the name of the disk, its serial number in the guest.
+	Name string `json:"name"`
+
+	// Size is the capacity of the disk, e.g. 50Gi.
+	Size string `json:"size"`
+
+	// Bus is the bus the disk is attached with, the bus of the root disk by default.
+	// +optional
+	Bus DiskBus `json:"bus,omitempty"`
+}
+
+// Watchdog is the watchdog device of a VM.
+type Watchdog struct {
+	// Action is the action taken when the watchdog expires, reset by default.
+	// +optional
+	Action WatchdogAction `json:"action,omitempty"`
+}
+
+// WatchdogAction is the action taken on a VM whose watchdog expired.
+type WatchdogAction string
+
+// Possible values for WatchdogAction.
+const (
+	// WatchdogActionReset resets the VM, which reboots within its VMI.
+	WatchdogActionReset WatchdogAction = "reset"
+	// WatchdogActionPoweroff powers the VM off, its VMI stops and the run strategy of the VM
+	// decides whether it is started again.
+	WatchdogActionPoweroff WatchdogAction = "poweroff"
+)
+
+// SMBIOS is the system identity of a VM.
+type SMBIOS struct {
+	// Serial is the system serial number, the machine name by default.
+	// +optional
+	Serial string `json:"serial,omitempty"`
+
+	// UUID is the system UUID, the UID of the machine by default. The machines of a
+	// MachineSet keep the default, each VM needing a UUID of its own.
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+}
+
+// Clock is the clock of a VM.
+type Clock struct {
+	// Timezone keeps the real time clock of the guest in the local time of the zone, e.g.
+	// America/New_York, for guests expecting localtime. The clock is kept in UTC by default.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// HypervTimer enables or disables the Hyper-V reference time counter guests read the time
+	// of the infra node from. It is enabled for Windows guests by default.
+	// +optional
+	HypervTimer TimerState `json:"hypervTimer,omitempty"`
+
+	// KVMTimer enables or disables the kvmclock Linux guests read the time of the infra node
+	// from. It is left to the machine type by default.
+	// +optional
+	KVMTimer TimerState `json:"kvmTimer,omitempty"`
+}
+
+// TimerState is whether a timer is attached to a VM.
+type TimerState string
+
+// Possible values for TimerState.
+const (
+	// TimerStateEnabled attaches the timer to the VM.
+	TimerStateEnabled TimerState = "Enabled"
+	// TimerStateDisabled keeps the machine type from attaching the timer to the VM.
+	TimerStateDisabled TimerState = "Disabled"
+)
+
+// DiskBus is the bus a disk is attached to the VM with.
+type DiskBus string
+
+// Possible values for DiskBus.
+const (
+	// DiskBusVirtio attaches the disk as a virtio-blk device.
+	DiskBusVirtio DiskBus = "virtio"
+	// DiskBusSCSI attaches the disk to a virtio-scsi controller.
+	DiskBusSCSI DiskBus = "scsi"
+	// DiskBusSATA attaches the disk to a SATA controller, which guests support without virtio drivers.
+	DiskBusSATA DiskBus = "sata"
+)
+
+// BootImageImport is a registry image CDI imports on a schedule as the boot image of machines.
+type BootImageImport struct {
+	// URL is the registry image to import, e.g. docker://quay.io/containerdisks/fedora:latest
+	URL string `json:"url"`
+
+	// Schedule is the cron schedule the registry is polled for a new image on, every
+	// 12 hours by default.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// ImportsToKeep is the number of imported images kept on the infra cluster, 3 by default.
+	// +optional
+	ImportsToKeep *int32 `json:"importsToKeep,omitempty"`
+
+	// Storage is the size of the PVCs of the imported images, requestedStorage by default.
+	// The imports use storageClassName.
+	// +optional
+	Storage string `json:"storage,omitempty"`
+}
+
+// Architecture is the CPU architecture of a guest.
+type Architecture string
+
+// Possible values for Architecture.
+const (
+	// ArchitectureAMD64 runs an x86_64 guest.
+	ArchitectureAMD64 Architecture = "amd64"
+	// ArchitectureARM64 runs an aarch64 guest.
+	ArchitectureARM64 Architecture = "arm64"
+)
+
+// AddressFamilyPolicy selects the IP families of a machine.
+type AddressFamilyPolicy string
+
+// Possible values for AddressFamilyPolicy.
+const (
+	// AddressFamilyIPv4 restricts the machine to IPv4.
+	AddressFamilyIPv4 AddressFamilyPolicy = "IPv4"
+	// AddressFamilyIPv6 restricts the machine to IPv6.
+	AddressFamilyIPv6 AddressFamilyPolicy = "IPv6"
+	// AddressFamilyDual gives the machine IPv4 and IPv6 addresses, IPv4 being the primary family.
+	AddressFamilyDual AddressFamilyPolicy = "Dual"
+)
+
+// InterfaceBinding connects a VM interface to the guest.
+type InterfaceBinding string
+
+// Possible values for InterfaceBinding.
+const (
+	// InterfaceBindingBridge bridges the network into the guest.
+	InterfaceBindingBridge InterfaceBinding = "Bridge"
+	// InterfaceBindingMasquerade NATs the guest behind the addresses of the virt-launcher pod.
+	InterfaceBindingMasquerade InterfaceBinding = "Masquerade"
+	// InterfaceBindingSRIOV passes an SR-IOV virtual function through to the guest.
+	InterfaceBindingSRIOV InterfaceBinding = "SRIOV"
+)
+
+// UserDataFormat is the format of the UserData handed to the guest.
+type UserDataFormat string
+
+// Possible values for UserDataFormat.
+const (
+	// UserDataFormatCloudInit is cloud-init UserData, e.g. a #cloud-config document or a script.
+	UserDataFormatCloudInit UserDataFormat = "CloudInit"
+	// UserDataFormatIgnition is an Ignition config, as consumed by RHCOS and FCOS guests.
+	UserDataFormatIgnition UserDataFormat = "Ignition"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KubevirtMachineProviderSpecList contains a list of KubevirtMachineProviderSpec
+type KubevirtMachineProviderSpecList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubevirtMachineProviderSpec `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubevirtMachineProviderSpec{}, &KubevirtMachineProviderSpecList{}, &KubevirtMachineProviderStatus{})
+}
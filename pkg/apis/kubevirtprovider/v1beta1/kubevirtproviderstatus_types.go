@@ -0,0 +1,553 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KubevirtMachineProviderStatus is the type that will be embedded in a Machine.Status.ProviderStatus field.
+// It contains KubeVirt-specific status information.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type KubevirtMachineProviderStatus struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// VMID is the UID of the VirtualMachine of the machine on the infra cluster
+	// +optional
+	VMID string `json:"vmId,omitempty"`
+
+	// Phase is the phase of the VirtualMachineInstance of the VM, empty while the VM is stopped
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// VMState is the printable state of the VM, also reported in the instance-state
+	// annotation of the machine
+	// +optional
+	VMState VMState `json:"vmState,omitempty"`
+
+	// ColdMigration tracks the last cold migration of the VM
+	// +optional
+	ColdMigration *ColdMigrationStatus `json:"coldMigration,omitempty"`
+
+	// DiagnosticsURLs holds deep links into the infra cluster consoles for the VM, such as
+	// its VM page, the logs of its virt-launcher pod or the dashboard of its infra node,
+	// keyed by the names of the URL templates configured for the provider
+	// +optional
+	DiagnosticsURLs map[string]string `json:"diagnosticsURLs,omitempty"`
+
+	// Reboot tracks the reboots scheduled by the reboot policy of the machine
+	// +optional
+	Reboot *RebootStatus `json:"reboot,omitempty"`
+
+	// LauncherResources tracks the last change of the virt-launcher limits of the VM
+	// +optional
+	LauncherResources *LauncherResourcesStatus `json:"launcherResources,omitempty"`
+
+	// BootVolumeImport tracks the progress and the retries of the import of the boot DataVolume
+	// +optional
+	// BootVolume is the phase and progress of the import or clone populating the boot
+	// DataVolume of the VM
+	// +optional
+	BootVolume *BootVolumeStatus `json:"bootVolume,omitempty"`
+
+	BootVolumeImport *BootVolumeImportStatus `json:"bootVolumeImport,omitempty"`
+
+	// ShutdownStartTime is when the VM was stopped for the guest to shut down before the
+	// deletion of the VM
+	// +optional
+	ShutdownStartTime *metav1.Time `json:"shutdownStartTime,omitempty"`
+
+	// DataExportStartTime is when the export of the volumes of the VM started before the
+	// deletion of the VM
+	// +optional
+	DataExportStartTime *metav1.Time `json:"dataExportStartTime,omitempty"`
+
+	// SnapshotStartTime is when the VM was stopped to be snapshotted before its deletion
+	// +optional
+	SnapshotStartTime *metav1.Time `json:"snapshotStartTime,omitempty"`
+
+	// SpecUpdateTime is when the VM was updated to the drifted provider spec, which applies
+	// from the first VMI created after it
+	// +optional
+	SpecUpdateTime *metav1.Time `json:"specUpdateTime,omitempty"`
+
+	// DriftedFields are the fields of the VM which differ from the VM rendered out of the
+	// current provider spec, whether or not the update policy acts on them
+	// +optional
+	DriftedFields []string `json:"driftedFields,omitempty"`
+
+	// ControlPlaneEndpoint is the host:port of the load balancer fronting the API server of the
+	// control plane VMs, once the infra cluster assigned it
+	// +optional
+	ControlPlaneEndpoint string `json:"controlPlaneEndpoint,omitempty"`
+
+	// DNSName is the name the internal IPs of the machine are registered under in the DNS
+	// backend of the machine controller
+	// +optional
+	DNSName string `json:"dnsName,omitempty"`
+
+	// AllocatedAddresses are the addresses allocated to the interfaces of the guest out of the
+	// IP pools of their addressesFromPools
+	// +optional
+	AllocatedAddresses []AllocatedAddress `json:"allocatedAddresses,omitempty"`
+
+	// GuestOSInfo is the operating system of the guest, as reported by its guest agent
+	// +optional
+	GuestOSInfo *GuestOSInfo `json:"guestOSInfo,omitempty"`
+
+	// GuestInterfaces are the interfaces of the guest with all their addresses, as reported by
+	// its guest agent
+	// +optional
+	GuestInterfaces []GuestInterface `json:"guestInterfaces,omitempty"`
+
+	// RemediationRestartTime is when the VM was restarted to remediate the unhealthy machine
+	// +optional
+	RemediationRestartTime *metav1.Time `json:"remediationRestartTime,omitempty"`
+
+	// SerialConsoleLog records the last capture of the serial console of the guest
+	// +optional
+	SerialConsoleLog *SerialConsoleLogStatus `json:"serialConsoleLog,omitempty"`
+
+	// Conditions is a set of conditions associated with the Machine to indicate
+	// errors or other status
+	Conditions []KubevirtMachineProviderCondition `json:"conditions,omitempty"`
+}
+
+// LauncherResizePath is how a change of the virt-launcher limits of a VM was applied.
+type LauncherResizePath string
+
+// Possible values for LauncherResizePath.
+const (
+	// LauncherResizeInPlace is set when the limits were changed on the running virt-launcher pod.
+	LauncherResizeInPlace LauncherResizePath = "InPlace"
+	// LauncherResizeOnRestart is set when the infra cluster refused to resize the running
+	// virt-launcher pod, the limits apply from the next restart of the VM.
+	LauncherResizeOnRestart LauncherResizePath = "OnRestart"
+)
+
+// LauncherResourcesStatus tracks the last change of the virt-launcher limits of a VM.
+type LauncherResourcesStatus struct {
+	// Path is how the limits were applied.
+	Path LauncherResizePath `json:"path"`
+	// PodName is the virt-launcher pod the limits were applied to.
+	PodName string `json:"podName"`
+	// CPULimit is the CPU limit applied.
+	// +optional
+	CPULimit string `json:"cpuLimit,omitempty"`
+	// MemoryLimit is the memory limit applied.
+	// +optional
+	MemoryLimit string `json:"memoryLimit,omitempty"`
+	// Message is a human-readable message about the change.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// BootVolumeStatus is the population of the boot DataVolume of a VM as reported by CDI.
+type BootVolumeStatus struct {
+	// DataVolume is the name of the boot DataVolume.
+	DataVolume string `json:"dataVolume"`
+	// Phase is the phase of the DataVolume, e.g. ImportInProgress or CloneInProgress.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// Progress is the progress of the import or clone, e.g. 45.20%.
+	// +optional
+	Progress string `json:"progress,omitempty"`
+}
+
+// BootVolumeImportStatus tracks the progress and the retries of the import of the boot
+// DataVolume of a VM.
+type BootVolumeImportStatus struct {
+	// Progress is the last import progress reported for the DataVolume.
+	// +optional
+	Progress string `json:"progress,omitempty"`
+	// LastProgressTime is the time the import progress was last seen changing.
+	// +optional
+	LastProgressTime *metav1.Time `json:"lastProgressTime,omitempty"`
+	// Retries is the number of times the import was retried.
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+	// NextRetryTime is the earliest time the import may be retried again.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// RebootStatus tracks the reboots scheduled by the reboot policy of a machine.
+type RebootStatus struct {
+	// LastRebootTime is the time the guest was last rebooted by the reboot policy.
+	// +optional
+	LastRebootTime *metav1.Time `json:"lastRebootTime,omitempty"`
+	// NextRebootTime is the time the next reboot of the guest is due.
+	// +optional
+	NextRebootTime *metav1.Time `json:"nextRebootTime,omitempty"`
+	// Message is a human-readable message about the next reboot.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VMState is the printable state of a VM, derived from the VM and its VirtualMachineInstance.
+type VMState string
+
+// Possible values for VMState.
+const (
+	// VMStateStopped is set while the VM has no VirtualMachineInstance.
+	VMStateStopped VMState = "Stopped"
+	// VMStateStarting is set while the VirtualMachineInstance is scheduled and started.
+	VMStateStarting VMState = "Starting"
+	// VMStateRunning is set while the VirtualMachineInstance is running.
+	VMStateRunning VMState = "Running"
+	// VMStateMigrating is set while the VirtualMachineInstance is live migrated.
+	VMStateMigrating VMState = "Migrating"
+	// VMStatePaused is set while the VirtualMachineInstance is paused.
+	VMStatePaused VMState = "Paused"
+	// VMStateStopping is set while the VirtualMachineInstance of a VM requested to stop shuts down.
+	VMStateStopping VMState = "Stopping"
+	// VMStateTerminating is set while the VM is deleted.
+	VMStateTerminating VMState = "Terminating"
+	// VMStateCrashLoopBackOff is set while the VirtualMachineInstance of a VM meant to run failed.
+	VMStateCrashLoopBackOff VMState = "CrashLoopBackOff"
+)
+
+// ColdMigrationPhase is the step a cold migration is at.
+type ColdMigrationPhase string
+
+// Possible values for ColdMigrationPhase.
+const (
+	// ColdMigrationStopping is set while the VM is being stopped.
+	ColdMigrationStopping ColdMigrationPhase = "Stopping"
+	// ColdMigrationCloning is set while the root disk is cloned to the target volume.
+	ColdMigrationCloning ColdMigrationPhase = "Cloning"
+	// ColdMigrationStarting is set while the VM is started from the target volume.
+	ColdMigrationStarting ColdMigrationPhase = "Starting"
+	// ColdMigrationSucceeded is set once the VM runs from the target volume.
+	ColdMigrationSucceeded ColdMigrationPhase = "Succeeded"
+	// ColdMigrationFailed is set when the target volume could not be cloned,
+	// the VM is started again from the source volume.
+	ColdMigrationFailed ColdMigrationPhase = "Failed"
+)
+
+// AllocatedAddress is an address allocated to an interface of the guest out of an IP pool.
+type AllocatedAddress struct {
+	// Interface is the name of the interface in the guest.
+	Interface string `json:"interface"`
+	// Claim is the name of the IPAddressClaim the address is allocated for.
+	Claim string `json:"claim"`
+	// Address is the address in CIDR notation.
+	Address string `json:"address"`
+	// Gateway is the gateway of the pool of the address.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+}
+
+// GuestOSInfo is the operating system of a guest, as reported by its guest agent.
+type GuestOSInfo struct {
+	// ID is the identifier of the OS, such as rhcos.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Name is the name of the OS.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// PrettyName is the human-readable name of the OS with its version.
+	// +optional
+	PrettyName string `json:"prettyName,omitempty"`
+	// Version is the version of the OS.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// KernelRelease is the release of the kernel of the guest.
+	// +optional
+	KernelRelease string `json:"kernelRelease,omitempty"`
+	// Machine is the machine hardware name of the guest, such as x86_64.
+	// +optional
+	Machine string `json:"machine,omitempty"`
+}
+
+// GuestInterface is an interface of a guest, as reported by its guest agent.
+type GuestInterface struct {
+	// Name is the name of the network of the VM the interface is attached to.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// InterfaceName is the name of the interface in the guest.
+	// +optional
+	InterfaceName string `json:"interfaceName,omitempty"`
+	// MAC is the hardware address of the interface.
+	// +optional
+	MAC string `json:"mac,omitempty"`
+	// IPs are all the addresses of the interface in CIDR notation, link-local ones included.
+	// +optional
+	IPs []string `json:"ips,omitempty"`
+}
+
+// SerialConsoleLogStatus records a capture of the serial console of a guest.
+type SerialConsoleLogStatus struct {
+	// ConfigMap is the name of the ConfigMap holding the capture, empty if the capture failed.
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+	// VMIUID is the UID of the VirtualMachineInstance whose serial console was captured.
+	VMIUID string `json:"vmiUID"`
+	// CaptureTime is the time the serial console was captured.
+	// +optional
+	CaptureTime *metav1.Time `json:"captureTime,omitempty"`
+}
+
+// ColdMigrationStatus describes an offline move of the VM to another storage class or zone.
+type ColdMigrationStatus struct {
+	// Phase is the step the cold migration is at.
+	Phase ColdMigrationPhase `json:"phase"`
+	// TargetStorageClass is the storage class the root disk is moved to.
+	// +optional
+	TargetStorageClass string `json:"targetStorageClass,omitempty"`
+	// TargetZone is the infra zone the VM is moved to.
+	// +optional
+	TargetZone string `json:"targetZone,omitempty"`
+	// SourceVolume is the DataVolume the root disk is moved from.
+	SourceVolume string `json:"sourceVolume,omitempty"`
+	// TargetVolume is the DataVolume the root disk is moved to.
+	TargetVolume string `json:"targetVolume,omitempty"`
+	// StartTime is the time the cold migration was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the cold migration succeeded or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message is a human-readable message about the current step.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// KubevirtMachineProviderConditionType is a valid value for KubevirtMachineProviderCondition.Type
+type KubevirtMachineProviderConditionType string
+
+// Valid conditions for a KubeVirt machine.
+const (
+	// MachineCreation indicates whether the machine has been created or not. If not,
+	// it should include a reason and message for the failure.
+	MachineCreation KubevirtMachineProviderConditionType = "MachineCreation"
+	// OutdatedBootImage indicates whether the machine was cloned from an older revision
+	// of its boot image source than the one currently published on the infra cluster.
+	OutdatedBootImage KubevirtMachineProviderConditionType = "OutdatedBootImage"
+	// KubeletCertificateSANs indicates whether the kubelet serving certificate of the node
+	// covers the static IP addresses of the machine.
+	KubeletCertificateSANs KubevirtMachineProviderConditionType = "KubeletCertificateSANs"
+	// AdvancedTuningActive indicates the VM is tuned through settings not modeled by the provider
+	// spec, which are outside of the supported configuration.
+	AdvancedTuningActive KubevirtMachineProviderConditionType = "AdvancedTuningActive"
+	// LiveMigratable indicates whether the VM can be live migrated, which the LiveMigrate
+	// eviction strategy relies on to survive drains of its infra node.
+	LiveMigratable KubevirtMachineProviderConditionType = "LiveMigratable"
+	// GuestProblems indicates node-problem-detector reports problems of the virtualized node,
+	// e.g. virtio errors or clock jumps, correlated with the state of the VM.
+	GuestProblems KubevirtMachineProviderConditionType = "GuestProblems"
+	// CircuitOpen indicates the creation of the VM is stopped, as the machines of its
+	// MachineSet exhausted their provisioning failure budget.
+	CircuitOpen KubevirtMachineProviderConditionType = "CircuitOpen"
+	// VMRunning indicates whether the VirtualMachineInstance of the VM is running.
+	VMRunning KubevirtMachineProviderConditionType = "VMRunning"
+	// VMErrored indicates whether KubeVirt reports a failure of the VM or its
+	// VirtualMachineInstance.
+	VMErrored KubevirtMachineProviderConditionType = "VMErrored"
+	// GuestShutdown indicates how the last shutdown of the guest from within was handled.
+	GuestShutdown KubevirtMachineProviderConditionType = "GuestShutdown"
+	// BootVolumeImport indicates whether the import of the boot DataVolume got stuck and was retried.
+	BootVolumeImport KubevirtMachineProviderConditionType = "BootVolumeImport"
+	// LifetimeExceeded indicates whether the machine is older than its maximum lifetime.
+	LifetimeExceeded KubevirtMachineProviderConditionType = "LifetimeExceeded"
+	// WaitingForBootstrapData indicates whether the creation of the VM waits for the user data
+	// secret to be created, e.g. by a bootstrap provider.
+	WaitingForBootstrapData KubevirtMachineProviderConditionType = "WaitingForBootstrapData"
+	// DataExported indicates whether the volumes of the VM were exported to the archive
+	// namespace before the deletion of the VM.
+	DataExported KubevirtMachineProviderConditionType = "DataExported"
+	// WaitingForCloneSlot indicates whether the creation of the VM waits for other machines to
+	// finish cloning their boot volume out of the same source PVC.
+	WaitingForCloneSlot KubevirtMachineProviderConditionType = "WaitingForCloneSlot"
+	// Preempted indicates whether the VM of a preemptible machine is being evicted from its
+	// infra node, its tenant Node being cordoned meanwhile.
+	Preempted KubevirtMachineProviderConditionType = "Preempted"
+	// Remediated indicates whether the restart of the VM remediated the unhealthy machine.
+	Remediated KubevirtMachineProviderConditionType = "Remediated"
+	// Hibernated indicates whether the VM is stopped on request, keeping its volumes, until the
+	// machine is resumed.
+	Hibernated KubevirtMachineProviderConditionType = "Hibernated"
+	// SnapshotTaken indicates whether the VM was snapshotted before its deletion
+	SnapshotTaken KubevirtMachineProviderConditionType = "SnapshotTaken"
+	// VMUpToDate indicates whether the VM runs the current provider spec of the machine
+	VMUpToDate KubevirtMachineProviderConditionType = "VMUpToDate"
+	// SpecDrifted indicates whether the VM differs from the VM rendered out of the current provider spec
+	SpecDrifted KubevirtMachineProviderConditionType = "SpecDrifted"
+	// ControlPlaneLoadBalancerReady indicates whether the Service fronting the API server of the control plane VMs is reachable
+	ControlPlaneLoadBalancerReady KubevirtMachineProviderConditionType = "ControlPlaneLoadBalancerReady"
+	// AddressesAllocated indicates whether the addresses of the IP pools of the network data are allocated
+	AddressesAllocated KubevirtMachineProviderConditionType = "AddressesAllocated"
+	// AgentConnected indicates whether the qemu-guest-agent of the guest is connected
+	AgentConnected KubevirtMachineProviderConditionType = "AgentConnected"
+	// SerialConsoleCaptured indicates the serial console of the guest was captured as its node did not join in time
+	SerialConsoleCaptured KubevirtMachineProviderConditionType = "SerialConsoleCaptured"
+	// BootVolumeReady indicates whether the boot DataVolume of the VM is populated, the VM only starting once it is
+	BootVolumeReady KubevirtMachineProviderConditionType = "BootVolumeReady"
+)
+
+// KubevirtMachineProviderConditionReason is reason for the condition's last transition.
+type KubevirtMachineProviderConditionReason string
+
+const (
+	// MachineCreationSucceeded indicates machine creation success.
+	MachineCreationSucceeded KubevirtMachineProviderConditionReason = "MachineCreationSucceeded"
+	// MachineCreationFailed indicates machine creation failure.
+	MachineCreationFailed KubevirtMachineProviderConditionReason = "MachineCreationFailed"
+	// BootImageUpToDate indicates the machine runs the current revision of its boot image.
+	BootImageUpToDate KubevirtMachineProviderConditionReason = "BootImageUpToDate"
+	// BootImageUpdated indicates the boot image source was replaced after the machine was created.
+	BootImageUpdated KubevirtMachineProviderConditionReason = "BootImageUpdated"
+	// BootImageUnknown indicates the revision the machine was created from could not be determined.
+	BootImageUnknown KubevirtMachineProviderConditionReason = "BootImageUnknown"
+	// CertificateSANsMatch indicates the kubelet serving certificate covers all static IP addresses.
+	CertificateSANsMatch KubevirtMachineProviderConditionReason = "CertificateSANsMatch"
+	// CertificateSANsMismatch indicates the kubelet serving certificate misses some static IP addresses.
+	CertificateSANsMismatch KubevirtMachineProviderConditionReason = "CertificateSANsMismatch"
+	// CertificateNotFound indicates no kubelet serving certificate request was found for the node.
+	CertificateNotFound KubevirtMachineProviderConditionReason = "CertificateNotFound"
+	// AdvancedTuningApplied indicates advanced tuning annotations are set on the VM.
+	AdvancedTuningApplied KubevirtMachineProviderConditionReason = "AdvancedTuningApplied"
+	// VMIMigratable indicates KubeVirt reports the VM as live migratable.
+	VMIMigratable KubevirtMachineProviderConditionReason = "VMIMigratable"
+	// VMINotMigratable indicates KubeVirt reports the VM as not live migratable.
+	VMINotMigratable KubevirtMachineProviderConditionReason = "VMINotMigratable"
+	// GuestProblemsDetected indicates the node reports problems of the virtualized node.
+	GuestProblemsDetected KubevirtMachineProviderConditionReason = "GuestProblemsDetected"
+	// NoGuestProblems indicates the node reports no problems of the virtualized node.
+	NoGuestProblems KubevirtMachineProviderConditionReason = "NoGuestProblems"
+	// FailureBudgetExhausted indicates the MachineSet reached its consecutive provisioning failures threshold.
+	FailureBudgetExhausted KubevirtMachineProviderConditionReason = "FailureBudgetExhausted"
+	// FailureBudgetAvailable indicates the MachineSet is below its consecutive provisioning failures threshold.
+	FailureBudgetAvailable KubevirtMachineProviderConditionReason = "FailureBudgetAvailable"
+	// VMIRunning indicates the VirtualMachineInstance is running.
+	VMIRunning KubevirtMachineProviderConditionReason = "VMIRunning"
+	// VMINotRunning indicates the VM is stopped or its VirtualMachineInstance is not running yet.
+	VMINotRunning KubevirtMachineProviderConditionReason = "VMINotRunning"
+	// VMFailure indicates KubeVirt reports a failure of the VM or its VirtualMachineInstance.
+	VMFailure KubevirtMachineProviderConditionReason = "VMFailure"
+	// NoVMFailure indicates KubeVirt reports no failure of the VM.
+	NoVMFailure KubevirtMachineProviderConditionReason = "NoVMFailure"
+	// GuestShutdownRestarted indicates the VM was restarted after the guest shut down.
+	GuestShutdownRestarted KubevirtMachineProviderConditionReason = "GuestShutdownRestarted"
+	// GuestShutdownFailed indicates the machine was marked failed after the guest shut down.
+	GuestShutdownFailed KubevirtMachineProviderConditionReason = "GuestShutdownFailed"
+	// GuestShutdownHalted indicates the VM was kept stopped after the guest shut down.
+	GuestShutdownHalted KubevirtMachineProviderConditionReason = "GuestShutdownHalted"
+	// InsufficientResources indicates no infra node of the pool has the resources of the VM free on a single NUMA node.
+	InsufficientResources KubevirtMachineProviderConditionReason = "InsufficientResources"
+	// ImportStuck indicates the import of the boot DataVolume got stuck and is retried.
+	ImportStuck KubevirtMachineProviderConditionReason = "ImportStuck"
+	// ImportRetriesExhausted indicates the import of the boot DataVolume got stuck after its last retry.
+	ImportRetriesExhausted KubevirtMachineProviderConditionReason = "ImportRetriesExhausted"
+	// ImportSucceeded indicates the import of the boot DataVolume completed.
+	ImportSucceeded KubevirtMachineProviderConditionReason = "ImportSucceeded"
+	// CloneSlotsExhausted indicates the maximum of concurrent clones of the source PVC is reached.
+	CloneSlotsExhausted KubevirtMachineProviderConditionReason = "CloneSlotsExhausted"
+	// CloneSlotAcquired indicates the boot volume is being cloned out of the source PVC.
+	CloneSlotAcquired KubevirtMachineProviderConditionReason = "CloneSlotAcquired"
+	// CloneCompleted indicates the clone of the boot volume finished, releasing its slot.
+	CloneCompleted KubevirtMachineProviderConditionReason = "CloneCompleted"
+	// LauncherEvicted indicates the virt-launcher pod of the VM is being evicted or preempted.
+	LauncherEvicted KubevirtMachineProviderConditionReason = "LauncherEvicted"
+	// LauncherRunning indicates the virt-launcher pod of the VM runs again after an eviction.
+	LauncherRunning KubevirtMachineProviderConditionReason = "LauncherRunning"
+	// RemediationInProgress indicates the VM was restarted to remediate the machine.
+	RemediationInProgress KubevirtMachineProviderConditionReason = "RemediationInProgress"
+	// RemediationSucceeded indicates the VM runs again after its restart.
+	RemediationSucceeded KubevirtMachineProviderConditionReason = "RemediationSucceeded"
+	// RemediationFailed indicates the VM did not run again in time after its restart.
+	RemediationFailed KubevirtMachineProviderConditionReason = "RemediationFailed"
+	// HibernationRequested indicates the VM is stopped as the machine is requested to hibernate.
+	HibernationRequested KubevirtMachineProviderConditionReason = "HibernationRequested"
+	// MachineResumed indicates the VM was started again after the hibernation of the machine.
+	MachineResumed KubevirtMachineProviderConditionReason = "MachineResumed"
+	// SnapshotInProgress indicates the VM is being snapshotted before its deletion.
+	SnapshotInProgress KubevirtMachineProviderConditionReason = "SnapshotInProgress"
+	// SnapshotSucceeded indicates the snapshot of the VM is ready to use.
+	SnapshotSucceeded KubevirtMachineProviderConditionReason = "SnapshotSucceeded"
+	// SnapshotTimedOut indicates the snapshot was not ready within its timeout.
+	SnapshotTimedOut KubevirtMachineProviderConditionReason = "SnapshotTimedOut"
+	// VMMatchesSpec indicates the VM runs the current provider spec.
+	VMMatchesSpec KubevirtMachineProviderConditionReason = "VMMatchesSpec"
+	// UpdatePendingRestart indicates the VM was updated to the provider spec, which applies from its next restart.
+	UpdatePendingRestart KubevirtMachineProviderConditionReason = "UpdatePendingRestart"
+	// UpdateRestartedVM indicates the VM was updated to the provider spec and restarted for it to apply.
+	UpdateRestartedVM KubevirtMachineProviderConditionReason = "UpdateRestartedVM"
+	// ReplacementRequired indicates the provider spec drifted from the VM, which is not updated.
+	ReplacementRequired KubevirtMachineProviderConditionReason = "ReplacementRequired"
+	// FieldsDrifted indicates fields of the VM differ from the provider spec.
+	FieldsDrifted KubevirtMachineProviderConditionReason = "FieldsDrifted"
+	// NoDrift indicates the VM matches the provider spec.
+	NoDrift KubevirtMachineProviderConditionReason = "NoDrift"
+	// LoadBalancerPending indicates the infra cluster did not assign the address of the load balancer yet.
+	LoadBalancerPending KubevirtMachineProviderConditionReason = "LoadBalancerPending"
+	// LoadBalancerReady indicates the load balancer exposes the API server.
+	LoadBalancerReady KubevirtMachineProviderConditionReason = "LoadBalancerReady"
+	// AddressClaimPending indicates an IPAddressClaim of the machine is not bound to an address yet.
+	AddressClaimPending KubevirtMachineProviderConditionReason = "AddressClaimPending"
+	// AddressClaimsBound indicates all IPAddressClaims of the machine are bound to addresses.
+	AddressClaimsBound KubevirtMachineProviderConditionReason = "AddressClaimsBound"
+	// MaxLifetimeExceeded indicates the machine is older than its maximum lifetime.
+	MaxLifetimeExceeded KubevirtMachineProviderConditionReason = "MaxLifetimeExceeded"
+	// WithinMaxLifetime indicates the machine is younger than its maximum lifetime.
+	WithinMaxLifetime KubevirtMachineProviderConditionReason = "WithinMaxLifetime"
+	// UserDataSecretNotFound indicates the user data secret does not exist yet.
+	UserDataSecretNotFound KubevirtMachineProviderConditionReason = "UserDataSecretNotFound"
+	// BootstrapDataAvailable indicates the user data secret was found.
+	BootstrapDataAvailable KubevirtMachineProviderConditionReason = "BootstrapDataAvailable"
+	// DataExportInProgress indicates the volumes of the VM are being cloned to the archive namespace.
+	DataExportInProgress KubevirtMachineProviderConditionReason = "DataExportInProgress"
+	// DataExportSucceeded indicates all volumes of the VM were cloned to the archive namespace.
+	DataExportSucceeded KubevirtMachineProviderConditionReason = "DataExportSucceeded"
+	// DataExportTimedOut indicates the export did not complete within its timeout.
+	DataExportTimedOut KubevirtMachineProviderConditionReason = "DataExportTimedOut"
+	// GuestAgentConnected indicates the guest agent reports the guest to the VirtualMachineInstance.
+	GuestAgentConnected KubevirtMachineProviderConditionReason = "GuestAgentConnected"
+	// GuestAgentNotConnected indicates the guest agent is not connected, not running in the guest or not installed.
+	GuestAgentNotConnected KubevirtMachineProviderConditionReason = "GuestAgentNotConnected"
+	// BootTimeoutExceeded indicates the node of the machine did not join within the boot timeout.
+	BootTimeoutExceeded KubevirtMachineProviderConditionReason = "BootTimeoutExceeded"
+	// SerialConsoleCaptureFailed indicates the serial console of the guest could not be captured.
+	SerialConsoleCaptureFailed KubevirtMachineProviderConditionReason = "SerialConsoleCaptureFailed"
+	// BootVolumePopulating indicates CDI is importing or cloning the boot image into the boot DataVolume.
+	BootVolumePopulating KubevirtMachineProviderConditionReason = "BootVolumePopulating"
+	// BootVolumePopulated indicates the boot DataVolume holds the boot image.
+	BootVolumePopulated KubevirtMachineProviderConditionReason = "BootVolumePopulated"
+	// BootVolumeFailed indicates CDI failed to populate the boot DataVolume.
+	BootVolumeFailed KubevirtMachineProviderConditionReason = "BootVolumeFailed"
+)
+
+// KubevirtMachineProviderCondition is a condition in a KubevirtMachineProviderStatus.
+type KubevirtMachineProviderCondition struct {
+	// Type is the type of the condition.
+	Type KubevirtMachineProviderConditionType `json:"type"`
+	// Status is the status of the condition.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastProbeTime is the last time we probed the condition.
+	// +optional
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a unique, one-word, CamelCase reason for the condition's last transition.
+	// +optional
+	Reason KubevirtMachineProviderConditionReason `json:"reason,omitempty"`
+	// Message is a human-readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,445 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/kubecli"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The resources of the infra cluster read from the cache.
+var (
+	virtualMachinesResource         = schema.GroupResource{Group: kubevirtapis.GroupName, Resource: "virtualmachines"}
+	virtualMachineInstancesResource = schema.GroupResource{Group: kubevirtapis.GroupName, Resource: "virtualmachineinstances"}
+	dataVolumesResource             = schema.GroupResource{Group: cdiv1.SchemeGroupVersion.Group, Resource: "datavolumes"}
+)
+
+// InfraCache serves the VirtualMachines, VirtualMachineInstances and DataVolumes read by the
+// clients it builds from informers, instead of a request to the infra cluster per read. The
+// informers are shared by the clients of the same credentials and started per namespace on
+// its first read, until then and while they sync the reads reach the infra cluster.
+type InfraCache struct {
+	resync time.Duration
+
+	lock     sync.Mutex
+	stop     <-chan struct{}
+	clusters map[string]*clusterCache
+}
+
+// NewInfraCache returns a cache whose informers resync with the given period. It serves no
+// read until it is started.
+func NewInfraCache(resync time.Duration) *InfraCache {
+	return &InfraCache{
+		resync:   resync,
+		clusters: map[string]*clusterCache{},
+	}
+}
+
+// Start runs the informers of the cache until stop is closed. It implements manager.Runnable.
+func (c *InfraCache) Start(stop <-chan struct{}) error {
+	c.lock.Lock()
+	c.stop = stop
+	c.lock.Unlock()
+
+	<-stop
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.stop = nil
+	for key, cluster := range c.clusters {
+		close(cluster.stop)
+		delete(c.clusters, key)
+	}
+	return nil
+}
+
+// ClientBuilder builds the clients like NewClient, with their reads served from the cache. It
+// is a KubevirtClientBuilderFuncType.
+func (c *InfraCache) ClientBuilder(ctrlRuntimeClient client.Client, secretName, namespace string) (Client, error) {
+	restConfig, err := getRestConfig(ctrlRuntimeClient, secretName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	infraClient, err := NewClientFromRESTConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := c.cluster(namespace+"/"+secretName, restConfig)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		return infraClient, nil
+	}
+	return &cachingClient{Client: infraClient, cache: cluster}, nil
+}
+
+// cluster returns the cache of the infra cluster the credentials secret points to, nil if the
+// cache is not started. The informers are restarted once the credentials changed.
+func (c *InfraCache) cluster(key string, restConfig *rest.Config) (*clusterCache, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.stop == nil {
+		return nil, nil
+	}
+
+	fingerprint := credentialsFingerprint(restConfig)
+	cluster, ok := c.clusters[key]
+	if ok && cluster.fingerprint == fingerprint {
+		return cluster, nil
+	}
+	if ok {
+		close(cluster.stop)
+		delete(c.clusters, key)
+	}
+
+	// Watches outlive the request timeout of the other clients
+	informerConfig := rest.CopyConfig(restConfig)
+	informerConfig.Timeout = 0
+	kubevirtClientset, err := kubecli.GetKubevirtClientFromRESTConfig(informerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubevirt client: %v", err)
+	}
+	cluster = newClusterCache(kubevirtListWatches(kubevirtClientset), c.resync)
+	cluster.fingerprint = fingerprint
+	c.clusters[key] = cluster
+	return cluster, nil
+}
+
+// WatchCredentials restarts the informers of the infra clusters as soon as their credentials
+// secret changes, so that rotated short-lived credentials replace the expiring ones of the
+// watches without waiting for the next client built, and stops them once the secret is
+// deleted. The credentials of the manager itself are reloaded by client-go from their files.
+func (c *InfraCache) WatchCredentials(ctx context.Context, informers ctrlcache.Informers) error {
+	informer, err := informers.GetInformer(ctx, &corev1.Secret{})
+	if err != nil {
+		return fmt.Errorf("failed to get the informer of credentials secrets: %v", err)
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if secret, ok := newObj.(*corev1.Secret); ok {
+				c.credentialsChanged(secret)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*corev1.Secret); ok {
+				c.credentialsDeleted(secret)
+			}
+		},
+	})
+	return nil
+}
+
+// credentialsChanged restarts the informers of the infra cluster of the secret if its
+// credentials changed.
+func (c *InfraCache) credentialsChanged(secret *corev1.Secret) {
+	key := secret.Namespace + "/" + secret.Name
+	c.lock.Lock()
+	_, ok := c.clusters[key]
+	c.lock.Unlock()
+	if !ok {
+		return
+	}
+
+	kubeconfig, ok := secret.Data[KubeconfigSecretKey]
+	if !ok {
+		c.credentialsDeleted(secret)
+		return
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		klog.Errorf("Failed to parse kubeconfig from credentials secret %s: %v", key, err)
+		c.credentialsDeleted(secret)
+		return
+	}
+	if _, err := c.cluster(key, restConfig); err != nil {
+		klog.Errorf("Failed to restart the infra cache of credentials secret %s: %v", key, err)
+	}
+}
+
+// credentialsDeleted stops the informers of the infra cluster of the secret.
+func (c *InfraCache) credentialsDeleted(secret *corev1.Secret) {
+	key := secret.Namespace + "/" + secret.Name
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if cluster, ok := c.clusters[key]; ok {
+		close(cluster.stop)
+		delete(c.clusters, key)
+	}
+}
+
+// credentialsFingerprint returns a digest of the server and credentials of the configuration.
+func credentialsFingerprint(restConfig *rest.Config) string {
+	digest := sha256.New()
+	for _, data := range [][]byte{
+		[]byte(restConfig.Host),
+		[]byte(restConfig.Username),
+		[]byte(restConfig.Password),
+		[]byte(restConfig.BearerToken),
+		[]byte(restConfig.BearerTokenFile),
+		[]byte(restConfig.CertFile),
+		restConfig.CertData,
+		restConfig.KeyData,
+		restConfig.CAData,
+	} {
+		digest.Write(data)
+		digest.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", digest.Sum(nil))
+}
+
+// listWatchFunc returns the list watch of a resource in a namespace, along with the type of
+// its objects.
+type listWatchFunc func(resource schema.GroupResource, namespace string) (cache.ListerWatcher, runtime.Object)
+
+// kubevirtListWatches returns the list watches of the cached resources of the infra cluster.
+func kubevirtListWatches(kubevirtClientset kubecli.KubevirtClient) listWatchFunc {
+	return func(resource schema.GroupResource, namespace string) (cache.ListerWatcher, runtime.Object) {
+		switch resource {
+		case virtualMachinesResource:
+			return cache.NewListWatchFromClient(kubevirtClientset.RestClient(), resource.Resource, namespace, fields.Everything()), &kubevirtapis.VirtualMachine{}
+		case virtualMachineInstancesResource:
+			return cache.NewListWatchFromClient(kubevirtClientset.RestClient(), resource.Resource, namespace, fields.Everything()), &kubevirtapis.VirtualMachineInstance{}
+		default:
+			dataVolumes := kubevirtClientset.CdiClient().CdiV1alpha1().DataVolumes(namespace)
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return dataVolumes.List(context.TODO(), options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return dataVolumes.Watch(context.TODO(), options)
+				},
+			}, &cdiv1.DataVolume{}
+		}
+	}
+}
+
+// objectKey identifies an object of a cached resource.
+type objectKey struct {
+	resource schema.GroupResource
+	key      string
+}
+
+// informerKey identifies the informer of a resource in a namespace.
+type informerKey struct {
+	resource  schema.GroupResource
+	namespace string
+}
+
+// clusterCache holds the informers of an infra cluster.
+type clusterCache struct {
+	fingerprint string
+	listWatch   listWatchFunc
+	resync      time.Duration
+	stop        chan struct{}
+
+	lock      sync.Mutex
+	informers map[informerKey]cache.SharedIndexInformer
+	// written holds the objects written through the cache since their informer last observed
+	// them, with the resource version they had then.
+	written map[objectKey]string
+}
+
+func newClusterCache(listWatch listWatchFunc, resync time.Duration) *clusterCache {
+	return &clusterCache{
+		listWatch: listWatch,
+		resync:    resync,
+		stop:      make(chan struct{}),
+		informers: map[informerKey]cache.SharedIndexInformer{},
+		written:   map[objectKey]string{},
+	}
+}
+
+// informer returns the informer of the resource in the namespace, started if create is true
+// and it does not exist yet.
+func (c *clusterCache) informer(resource schema.GroupResource, namespace string, create bool) cache.SharedIndexInformer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := informerKey{resource: resource, namespace: namespace}
+	if informer, ok := c.informers[key]; ok || !create {
+		return informer
+	}
+
+	listWatch, objectType := c.listWatch(resource, namespace)
+	informer := cache.NewSharedIndexInformer(listWatch, objectType, c.resync, cache.Indexers{})
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.observed(resource, obj, false)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.observed(resource, obj, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.observed(resource, obj, true)
+		},
+	})
+	go informer.Run(c.stop)
+	c.informers[key] = informer
+	return informer
+}
+
+// observed serves a written object from the cache again once its informer observed it changed
+// or deleted.
+func (c *clusterCache) observed(resource schema.GroupResource, obj interface{}, deleted bool) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	resourceVersion := ""
+	if accessor, err := meta.Accessor(obj); err == nil {
+		resourceVersion = accessor.GetResourceVersion()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	writtenKey := objectKey{resource: resource, key: key}
+	if staleVersion, ok := c.written[writtenKey]; ok && (deleted || resourceVersion != staleVersion) {
+		delete(c.written, writtenKey)
+	}
+}
+
+// markWritten has the object read from the infra cluster until its informer observed the
+// write.
+func (c *clusterCache) markWritten(resource schema.GroupResource, namespace, name string) {
+	key := namespace + "/" + name
+	staleVersion := ""
+	if informer := c.informer(resource, namespace, false); informer != nil {
+		if obj, exists, err := informer.GetIndexer().GetByKey(key); err == nil && exists {
+			if accessor, err := meta.Accessor(obj); err == nil {
+				staleVersion = accessor.GetResourceVersion()
+			}
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.written[objectKey{resource: resource, key: key}] = staleVersion
+}
+
+// get returns the object from its informer, and whether it exists. It returns false for
+// cached if the object is to be read from the infra cluster, while the informer syncs or until
+// it observed a write of the object.
+func (c *clusterCache) get(resource schema.GroupResource, namespace, name string) (obj interface{}, exists bool, cached bool) {
+	key := namespace + "/" + name
+	c.lock.Lock()
+	_, written := c.written[objectKey{resource: resource, key: key}]
+	c.lock.Unlock()
+	if written {
+		return nil, false, false
+	}
+
+	informer := c.informer(resource, namespace, true)
+	if !informer.HasSynced() {
+		return nil, false, false
+	}
+	obj, exists, err := informer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return nil, false, false
+	}
+	return obj, exists, true
+}
+
+// cachingClient reads VirtualMachines, VirtualMachineInstances and DataVolumes from the cache
+// of the infra cluster, its other requests reach the infra cluster.
+type cachingClient struct {
+	Client
+	cache *clusterCache
+}
+
+func (c *cachingClient) GetVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachine, error) {
+	obj, exists, cached := c.cache.get(virtualMachinesResource, namespace, name)
+	if !cached {
+		return c.Client.GetVirtualMachine(ctx, namespace, name, options)
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(virtualMachinesResource, name)
+	}
+	return obj.(*kubevirtapis.VirtualMachine).DeepCopy(), nil
+}
+
+func (c *cachingClient) GetVirtualMachineInstance(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachineInstance, error) {
+	obj, exists, cached := c.cache.get(virtualMachineInstancesResource, namespace, name)
+	if !cached {
+		return c.Client.GetVirtualMachineInstance(ctx, namespace, name, options)
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(virtualMachineInstancesResource, name)
+	}
+	return obj.(*kubevirtapis.VirtualMachineInstance).DeepCopy(), nil
+}
+
+func (c *cachingClient) GetDataVolume(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*cdiv1.DataVolume, error) {
+	obj, exists, cached := c.cache.get(dataVolumesResource, namespace, name)
+	if !cached {
+		return c.Client.GetDataVolume(ctx, namespace, name, options)
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(dataVolumesResource, name)
+	}
+	return obj.(*cdiv1.DataVolume).DeepCopy(), nil
+}
+
+// markVMWritten marks the VM written, along with its VMI which KubeVirt starts, stops or
+// restarts after the VM changed.
+func (c *cachingClient) markVMWritten(namespace, name string) {
+	c.cache.markWritten(virtualMachinesResource, namespace, name)
+	c.cache.markWritten(virtualMachineInstancesResource, namespace, name)
+}
+
+func (c *cachingClient) CreateVirtualMachine(ctx context.Context, namespace string, newVM *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	c.markVMWritten(namespace, newVM.Name)
+	return c.Client.CreateVirtualMachine(ctx, namespace, newVM)
+}
+
+func (c *cachingClient) DeleteVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	c.markVMWritten(namespace, name)
+	return c.Client.DeleteVirtualMachine(ctx, namespace, name, options)
+}
+
+func (c *cachingClient) UpdateVirtualMachine(ctx context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	c.markVMWritten(namespace, vm.Name)
+	return c.Client.UpdateVirtualMachine(ctx, namespace, vm)
+}
+
+func (c *cachingClient) StartVirtualMachine(ctx context.Context, namespace string, name string) error {
+	c.markVMWritten(namespace, name)
+	return c.Client.StartVirtualMachine(ctx, namespace, name)
+}
+
+func (c *cachingClient) RestartVirtualMachine(ctx context.Context, namespace string, name string) error {
+	c.markVMWritten(namespace, name)
+	return c.Client.RestartVirtualMachine(ctx, namespace, name)
+}
+
+func (c *cachingClient) CreateDataVolume(ctx context.Context, namespace string, dataVolume *cdiv1.DataVolume) (*cdiv1.DataVolume, error) {
+	c.cache.markWritten(dataVolumesResource, namespace, dataVolume.Name)
+	return c.Client.CreateDataVolume(ctx, namespace, dataVolume)
+}
+
+func (c *cachingClient) DeleteDataVolume(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	c.cache.markWritten(dataVolumesResource, namespace, name)
+	return c.Client.DeleteDataVolume(ctx, namespace, name, options)
+}
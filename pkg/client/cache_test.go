@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+// liveClient counts the VirtualMachines read from the infra cluster.
+type liveClient struct {
+	Client
+	reads int
+}
+
+func (c *liveClient) GetVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachine, error) {
+	c.reads++
+	return &kubevirtapis.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}, nil
+}
+
+func (c *liveClient) UpdateVirtualMachine(ctx context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	return vm, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	vm := func(resourceVersion string) *kubevirtapis.VirtualMachine {
+		return &kubevirtapis.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "tenant-a", ResourceVersion: resourceVersion}}
+	}
+	vmWatch := watch.NewFake()
+	listWatch := func(resource schema.GroupResource, namespace string) (cache.ListerWatcher, runtime.Object) {
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return &kubevirtapis.VirtualMachineList{
+					ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+					Items:    []kubevirtapis.VirtualMachine{*vm("1")},
+				}, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return vmWatch, nil
+			},
+		}, &kubevirtapis.VirtualMachine{}
+	}
+	clusterCache := newClusterCache(listWatch, 0)
+	defer close(clusterCache.stop)
+	live := &liveClient{}
+	client := &cachingClient{Client: live, cache: clusterCache}
+
+	// The first read starts the informer and reaches the infra cluster
+	if _, err := client.GetVirtualMachine(context.Background(), "tenant-a", "worker-abcde", &metav1.GetOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	informer := clusterCache.informer(virtualMachinesResource, "tenant-a", false)
+	if !cache.WaitForCacheSync(clusterCache.stop, informer.HasSynced) {
+		t.Fatal("Expected the informer to sync")
+	}
+
+	reads := live.reads
+	cached, err := client.GetVirtualMachine(context.Background(), "tenant-a", "worker-abcde", &metav1.GetOptions{})
+	if err != nil || cached.ResourceVersion != "1" || live.reads != reads {
+		t.Errorf("Expected the VM to be read from the cache, got %v (error %v)", cached, err)
+	}
+	if _, err := client.GetVirtualMachine(context.Background(), "tenant-a", "worker-fghij", &metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	if _, err := client.UpdateVirtualMachine(context.Background(), "tenant-a", vm("1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.GetVirtualMachine(context.Background(), "tenant-a", "worker-abcde", &metav1.GetOptions{}); err != nil || live.reads != reads+1 {
+		t.Errorf("Expected the updated VM to be read from the infra cluster, got %d reads (error %v)", live.reads-reads, err)
+	}
+
+	vmWatch.Modify(vm("2"))
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		_, _, cached := clusterCache.get(virtualMachinesResource, "tenant-a", "worker-abcde")
+		return cached, nil
+	}); err != nil {
+		t.Fatal("Expected the VM to be read from the cache once the informer observed the update")
+	}
+	if cached, err := client.GetVirtualMachine(context.Background(), "tenant-a", "worker-abcde", &metav1.GetOptions{}); err != nil || cached.ResourceVersion != "2" {
+		t.Errorf("Expected the updated VM from the cache, got %v (error %v)", cached, err)
+	}
+}
+
+func TestCredentialsChanged(t *testing.T) {
+	kubeconfig := func(token string) []byte {
+		return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: infra
+  cluster:
+    server: https://infra.example.com:6443
+contexts:
+- name: infra
+  context:
+    cluster: infra
+    user: manager
+current-context: infra
+users:
+- name: manager
+  user:
+    token: ` + token + `
+`)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "infra-credentials", Namespace: "tenant-a"},
+		Data:       map[string][]byte{KubeconfigSecretKey: kubeconfig("first")},
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	infraCache := NewInfraCache(0)
+	infraCache.stop = stop
+
+	// Secrets without a cache are ignored
+	infraCache.credentialsChanged(secret)
+	if len(infraCache.clusters) != 0 {
+		t.Fatalf("Expected no cache to be created, got %v", infraCache.clusters)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(secret.Data[KubeconfigSecretKey])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	first, err := infraCache.cluster("tenant-a/infra-credentials", restConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Unchanged credentials keep the informers
+	infraCache.credentialsChanged(secret)
+	if infraCache.clusters["tenant-a/infra-credentials"] != first {
+		t.Fatal("Expected the informers to be kept")
+	}
+
+	// Rotated credentials restart them
+	secret.Data[KubeconfigSecretKey] = kubeconfig("second")
+	infraCache.credentialsChanged(secret)
+	second := infraCache.clusters["tenant-a/infra-credentials"]
+	if second == nil || second == first {
+		t.Fatal("Expected the informers to be restarted")
+	}
+	select {
+	case <-first.stop:
+	default:
+		t.Error("Expected the informers of the previous credentials to be stopped")
+	}
+
+	infraCache.credentialsDeleted(secret)
+	if _, ok := infraCache.clusters["tenant-a/infra-credentials"]; ok {
+		t.Error("Expected the informers to be stopped once the secret is deleted")
+	}
+	select {
+	case <-second.stop:
+	default:
+		t.Error("Expected the informers of the deleted credentials to be stopped")
+	}
+}
@@ -17,56 +17,420 @@ limitations under the License.
 package client
 
 import (
-	networkclient "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/clientset/versioned"
-	"kubevirt.io/kubevirt/pkg/kubecli"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client/kubecli"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
 //go:generate go run ../../vendor/github.com/golang/mock/mockgen -source=./client.go -destination=./mock/client_generated.go -package=mock
 
 const (
-	kubeconfig = "kubeconfig"
+	// KubeconfigSecretKey is the key in the credentials secret holding the kubeconfig of the infra cluster
+	KubeconfigSecretKey = "kubeconfig"
 )
 
+// RequestTimeout bounds the requests to the infra cluster whose configuration sets no timeout,
+// including the requests of the KubeVirt and CDI clients abandoned once their context is done.
+const RequestTimeout = time.Minute
+
+// NodeResourceTopologyResource is the resource of the per NUMA node resources of infra nodes
+// exported by the resource topology exporter.
+var NodeResourceTopologyResource = schema.GroupVersionResource{Group: "topology.node.k8s.io", Version: "v1alpha1", Resource: "noderesourcetopologies"}
+
+// VirtualMachineSnapshotResource is the resource of the snapshots of KubeVirt VMs.
+var VirtualMachineSnapshotResource = schema.GroupVersionResource{Group: "snapshot.kubevirt.io", Version: "v1alpha1", Resource: "virtualmachinesnapshots"}
+
+// DataImportCronResource is the resource of the CDI crons importing a registry image on a
+// schedule into a DataSource.
+var DataImportCronResource = schema.GroupVersionResource{Group: "cdi.kubevirt.io", Version: "v1beta1", Resource: "dataimportcrons"}
+
+// DataSourceResource is the resource of the CDI references to the last image imported by a
+// DataImportCron.
+var DataSourceResource = schema.GroupVersionResource{Group: "cdi.kubevirt.io", Version: "v1beta1", Resource: "datasources"}
+
 // KubevirtClientBuilderFuncType is function type for building kubevirt client
-type KubevirtClientBuilderFuncType func(client kubecli.KubevirtClient, namespace string) (Client, error){
-	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(
-	secretName, metav1.GetOptions{})
-	kubeconfig = secret.value???
-	client = newkubevirtClient(kubeconfig)
-	return client
-}
+type KubevirtClientBuilderFuncType func(client client.Client, secretName, namespace string) (Client, error)
+
+// ImpersonatingClientBuilderFuncType is function type for building kubevirt client sending
+// its requests as the impersonated identity
+type ImpersonatingClientBuilderFuncType func(client client.Client, secretName, namespace string, impersonate rest.ImpersonationConfig) (Client, error)
 
-// Client is a wrapper object for actual AWS SDK clients to allow for easier testing.
+// Client is a wrapper object for actual KubeVirt clients to allow for easier testing.
 type Client interface {
-	VirtualMachine(namespace string) kubecli.kubeVirtualMachineInterface
-	NetworkClient() networkclient.Interface
+	CreateVirtualMachine(ctx context.Context, namespace string, newVM *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error)
+	DeleteVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error
+	GetVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachine, error)
+	UpdateVirtualMachine(ctx context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error)
+	StartVirtualMachine(ctx context.Context, namespace string, name string) error
+	RestartVirtualMachine(ctx context.Context, namespace string, name string) error
+	GetVirtualMachineInstance(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachineInstance, error)
+	GetPersistentVolumeClaim(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.PersistentVolumeClaim, error)
+	ListPods(ctx context.Context, namespace string, options *metav1.ListOptions) (*corev1.PodList, error)
+	PatchPod(ctx context.Context, namespace string, name string, patchType types.PatchType, data []byte) (*corev1.Pod, error)
+	ListNodes(ctx context.Context, options *metav1.ListOptions) (*corev1.NodeList, error)
+	ListEvents(ctx context.Context, namespace string, options *metav1.ListOptions) (*corev1.EventList, error)
+	ListNodeResourceTopologies(ctx context.Context, options *metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	CreateVirtualMachineSnapshot(ctx context.Context, namespace string, snapshot *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	GetVirtualMachineSnapshot(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*unstructured.Unstructured, error)
+	ListVirtualMachineSnapshots(ctx context.Context, namespace string, options *metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	DeleteVirtualMachineSnapshot(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error
+	GetService(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.Service, error)
+	CreateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error)
+	UpdateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error)
+	GetSecret(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.Secret, error)
+	CreateSecret(ctx context.Context, namespace string, secret *corev1.Secret) (*corev1.Secret, error)
+	UpdateSecret(ctx context.Context, namespace string, secret *corev1.Secret) (*corev1.Secret, error)
+	DeleteSecret(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error
+	CreateDataVolume(ctx context.Context, namespace string, dataVolume *cdiv1.DataVolume) (*cdiv1.DataVolume, error)
+	GetDataVolume(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*cdiv1.DataVolume, error)
+	DeleteDataVolume(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error
+	GetDataSource(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*unstructured.Unstructured, error)
+	CreateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	UpdateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	ListDataImportCrons(ctx context.Context, namespace string, options *metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	DeleteDataImportCron(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error
+	GetNamespace(ctx context.Context, name string, options *metav1.GetOptions) (*corev1.Namespace, error)
+	CreateNamespace(ctx context.Context, namespace *corev1.Namespace) (*corev1.Namespace, error)
+	CreateSelfSubjectAccessReview(ctx context.Context, review *authorizationv1.SelfSubjectAccessReview) (*authorizationv1.SelfSubjectAccessReview, error)
+	GetPodLogs(ctx context.Context, namespace string, name string, options *corev1.PodLogOptions) ([]byte, error)
+	ReadSerialConsole(ctx context.Context, namespace string, name string, duration time.Duration) ([]byte, error)
 }
 
-type kubevirtclient struct {
-	namespace string
+type kubevirtClient struct {
+	kubevirtClient kubecli.KubevirtClient
 }
 
-func NewClientBuilder(kubeconfig string) (*ClientBuilder, error) {
-	config, err := getRestConfig(kubeconfig)
+// NewClient creates our client wrapper object for the actual KubeVirt clients we use.
+// For authentication the underlying clients will use either the infra cluster kubeconfig
+// stored in the credentials secret if defined, otherwise the configuration of the cluster
+// where the actuator is running.
+func NewClient(ctrlRuntimeClient client.Client, secretName, namespace string) (Client, error) {
+	restConfig, err := getRestConfig(ctrlRuntimeClient, secretName, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ClientBuilder{
-		config: config,
+	return NewClientFromRESTConfig(restConfig)
+}
+
+// NewImpersonatingClient creates our client wrapper object like NewClient, with every request
+// to the infra cluster impersonating the given identity. The identity authenticating with the
+// infra cluster needs to be allowed to impersonate it.
+func NewImpersonatingClient(ctrlRuntimeClient client.Client, secretName, namespace string, impersonate rest.ImpersonationConfig) (Client, error) {
+	restConfig, err := getRestConfig(ctrlRuntimeClient, secretName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	restConfig = rest.CopyConfig(restConfig)
+	restConfig.Impersonate = impersonate
+
+	return NewClientFromRESTConfig(restConfig)
+}
+
+// NewClientFromRESTConfig creates our client wrapper object for the infra cluster the given
+// configuration points to, its requests sharing the rate limiter of the cluster.
+func NewClientFromRESTConfig(restConfig *rest.Config) (Client, error) {
+	if restConfig.Timeout == 0 || restConfig.RateLimiter == nil {
+		restConfig = rest.CopyConfig(restConfig)
+	}
+	if restConfig.Timeout == 0 {
+		restConfig.Timeout = RequestTimeout
+	}
+	if restConfig.RateLimiter == nil {
+		restConfig.RateLimiter = rateLimiter(restConfig.Host)
+	}
+	kubevirtClientset, err := kubecli.GetKubevirtClientFromRESTConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubevirt client: %v", err)
+	}
+
+	return &kubevirtClient{
+		kubevirtClient: kubevirtClientset,
 	}, nil
 }
 
-func (c *kubevirtclient) VirtualMachine(namespace string) kubecli.VirtualMachineInterface {
-	return c.VirtualMachine(namespace)
+func getRestConfig(ctrlRuntimeClient client.Client, secretName, namespace string) (*rest.Config, error) {
+	if secretName == "" {
+		return config.GetConfig()
+	}
+
+	var secret corev1.Secret
+	if err := ctrlRuntimeClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: secretName}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get credentials secret %s/%s: %v", namespace, secretName, err)
+	}
+
+	kubeconfig, ok := secret.Data[KubeconfigSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("credentials secret %s/%s missing %s key", namespace, secretName, KubeconfigSecretKey)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig from credentials secret %s/%s: %v", namespace, secretName, err)
+	}
+	return restConfig, nil
+}
+
+func (c *kubevirtClient) CreateVirtualMachine(ctx context.Context, namespace string, newVM *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	return c.kubevirtClient.VirtualMachine(namespace).Create(ctx, newVM)
+}
+
+func (c *kubevirtClient) DeleteVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return c.kubevirtClient.VirtualMachine(namespace).Delete(ctx, name, options)
+}
+
+func (c *kubevirtClient) GetVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachine, error) {
+	return c.kubevirtClient.VirtualMachine(namespace).Get(ctx, name, options)
+}
+
+func (c *kubevirtClient) UpdateVirtualMachine(ctx context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	return c.kubevirtClient.VirtualMachine(namespace).Update(ctx, vm)
+}
+
+func (c *kubevirtClient) StartVirtualMachine(ctx context.Context, namespace string, name string) error {
+	return c.kubevirtClient.VirtualMachine(namespace).Start(ctx, name)
+}
+
+func (c *kubevirtClient) RestartVirtualMachine(ctx context.Context, namespace string, name string) error {
+	return c.kubevirtClient.VirtualMachine(namespace).Restart(ctx, name)
+}
+
+func (c *kubevirtClient) GetVirtualMachineInstance(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachineInstance, error) {
+	return c.kubevirtClient.VirtualMachineInstance(namespace).Get(ctx, name, options)
+}
+
+func (c *kubevirtClient) GetPersistentVolumeClaim(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.PersistentVolumeClaim, error) {
+	return c.kubevirtClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, *options)
+}
+
+func (c *kubevirtClient) ListPods(ctx context.Context, namespace string, options *metav1.ListOptions) (*corev1.PodList, error) {
+	return c.kubevirtClient.CoreV1().Pods(namespace).List(ctx, *options)
+}
+
+func (c *kubevirtClient) PatchPod(ctx context.Context, namespace string, name string, patchType types.PatchType, data []byte) (*corev1.Pod, error) {
+	return c.kubevirtClient.CoreV1().Pods(namespace).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+}
+
+func (c *kubevirtClient) ListNodes(ctx context.Context, options *metav1.ListOptions) (*corev1.NodeList, error) {
+	return c.kubevirtClient.CoreV1().Nodes().List(ctx, *options)
+}
+
+func (c *kubevirtClient) ListEvents(ctx context.Context, namespace string, options *metav1.ListOptions) (*corev1.EventList, error) {
+	return c.kubevirtClient.CoreV1().Events(namespace).List(ctx, *options)
+}
+
+func (c *kubevirtClient) ListNodeResourceTopologies(ctx context.Context, options *metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	dynamicClient, err := dynamic.NewForConfig(c.kubevirtClient.Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+	return dynamicClient.Resource(NodeResourceTopologyResource).List(ctx, *options)
+}
+
+func (c *kubevirtClient) virtualMachineSnapshots(namespace string) (dynamic.ResourceInterface, error) {
+	dynamicClient, err := dynamic.NewForConfig(c.kubevirtClient.Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+	return dynamicClient.Resource(VirtualMachineSnapshotResource).Namespace(namespace), nil
+}
+
+func (c *kubevirtClient) CreateVirtualMachineSnapshot(ctx context.Context, namespace string, snapshot *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	snapshots, err := c.virtualMachineSnapshots(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots.Create(ctx, snapshot, metav1.CreateOptions{})
+}
+
+func (c *kubevirtClient) GetVirtualMachineSnapshot(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*unstructured.Unstructured, error) {
+	snapshots, err := c.virtualMachineSnapshots(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots.Get(ctx, name, *options)
+}
+
+func (c *kubevirtClient) ListVirtualMachineSnapshots(ctx context.Context, namespace string, options *metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	snapshots, err := c.virtualMachineSnapshots(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots.List(ctx, *options)
+}
+
+func (c *kubevirtClient) DeleteVirtualMachineSnapshot(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	snapshots, err := c.virtualMachineSnapshots(namespace)
+	if err != nil {
+		return err
+	}
+	return snapshots.Delete(ctx, name, *options)
+}
+
+func (c *kubevirtClient) GetService(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.Service, error) {
+	return c.kubevirtClient.CoreV1().Services(namespace).Get(ctx, name, *options)
+}
+
+func (c *kubevirtClient) CreateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
+	return c.kubevirtClient.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+}
+
+func (c *kubevirtClient) UpdateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
+	return c.kubevirtClient.CoreV1().Services(namespace).Update(ctx, service, metav1.UpdateOptions{})
+}
+
+func (c *kubevirtClient) GetSecret(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.Secret, error) {
+	return c.kubevirtClient.CoreV1().Secrets(namespace).Get(ctx, name, *options)
+}
+
+func (c *kubevirtClient) CreateSecret(ctx context.Context, namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
+	return c.kubevirtClient.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+}
+
+func (c *kubevirtClient) UpdateSecret(ctx context.Context, namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
+	return c.kubevirtClient.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+}
+
+func (c *kubevirtClient) DeleteSecret(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return c.kubevirtClient.CoreV1().Secrets(namespace).Delete(ctx, name, *options)
+}
+
+func (c *kubevirtClient) CreateDataVolume(ctx context.Context, namespace string, dataVolume *cdiv1.DataVolume) (*cdiv1.DataVolume, error) {
+	return c.kubevirtClient.CdiClient().CdiV1alpha1().DataVolumes(namespace).Create(ctx, dataVolume)
+}
+
+func (c *kubevirtClient) GetDataVolume(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*cdiv1.DataVolume, error) {
+	return c.kubevirtClient.CdiClient().CdiV1alpha1().DataVolumes(namespace).Get(ctx, name, *options)
+}
+
+func (c *kubevirtClient) DeleteDataVolume(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return c.kubevirtClient.CdiClient().CdiV1alpha1().DataVolumes(namespace).Delete(ctx, name, options)
+}
+
+func (c *kubevirtClient) GetDataSource(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*unstructured.Unstructured, error) {
+	dynamicClient, err := dynamic.NewForConfig(c.kubevirtClient.Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+	return dynamicClient.Resource(DataSourceResource).Namespace(namespace).Get(ctx, name, *options)
+}
+
+func (c *kubevirtClient) dataImportCrons(namespace string) (dynamic.ResourceInterface, error) {
+	dynamicClient, err := dynamic.NewForConfig(c.kubevirtClient.Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+	return dynamicClient.Resource(DataImportCronResource).Namespace(namespace), nil
+}
+
+func (c *kubevirtClient) CreateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	crons, err := c.dataImportCrons(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return crons.Create(ctx, cron, metav1.CreateOptions{})
+}
+
+func (c *kubevirtClient) UpdateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	crons, err := c.dataImportCrons(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return crons.Update(ctx, cron, metav1.UpdateOptions{})
+}
+
+func (c *kubevirtClient) ListDataImportCrons(ctx context.Context, namespace string, options *metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	crons, err := c.dataImportCrons(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return crons.List(ctx, *options)
+}
+
+func (c *kubevirtClient) DeleteDataImportCron(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	crons, err := c.dataImportCrons(namespace)
+	if err != nil {
+		return err
+	}
+	return crons.Delete(ctx, name, *options)
+}
+
+func (c *kubevirtClient) GetNamespace(ctx context.Context, name string, options *metav1.GetOptions) (*corev1.Namespace, error) {
+	return c.kubevirtClient.CoreV1().Namespaces().Get(ctx, name, *options)
+}
+
+func (c *kubevirtClient) CreateNamespace(ctx context.Context, namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	return c.kubevirtClient.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+}
+
+func (c *kubevirtClient) CreateSelfSubjectAccessReview(ctx context.Context, review *authorizationv1.SelfSubjectAccessReview) (*authorizationv1.SelfSubjectAccessReview, error) {
+	return c.kubevirtClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+}
+
+func (c *kubevirtClient) GetPodLogs(ctx context.Context, namespace string, name string, options *corev1.PodLogOptions) ([]byte, error) {
+	return c.kubevirtClient.CoreV1().Pods(namespace).GetLogs(name, options).DoRaw(ctx)
+}
+
+// ReadSerialConsole returns the output of the serial console of the VMI over the duration.
+// The console does not replay the output printed before it was connected.
+func (c *kubevirtClient) ReadSerialConsole(ctx context.Context, namespace string, name string, duration time.Duration) ([]byte, error) {
+	readCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	output := &consoleBuffer{}
+	stream, err := c.kubevirtClient.VirtualMachineInstance(namespace).SerialConsole(readCtx, name, &kubecli.SerialConsoleOptions{})
+	if err == nil {
+		// The console is read only, its input ends once the duration elapsed
+		err = stream.Stream(kubecli.StreamOptions{In: &contextReader{ctx: readCtx}, Out: output})
+	}
+	if err != nil && (ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded)) {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// consoleBuffer is a buffer safe for the concurrent writes of a console stream.
+type consoleBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *consoleBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *consoleBuffer) Bytes() []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]byte(nil), b.buffer.Bytes()...)
 }
 
-func (c *kubevirtclient) NetworkClient() networkclient.Interface {
-	return c.NetworkClient()
+// contextReader is an empty input which ends once its context is done.
+type contextReader struct {
+	ctx context.Context
 }
 
-func NewClient(ctrlRuntimeClient client.Client, namespace string) Client {
-	return &kubevirtclient{namespace}
+func (r *contextReader) Read(p []byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, io.EOF
 }
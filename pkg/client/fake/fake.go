@@ -1,131 +1,241 @@
 package fake
 
 import (
+	"context"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/ec2"
-	"github.com/aws/aws-sdk-go/service/elb"
-	"github.com/aws/aws-sdk-go/service/elbv2"
-	"k8s.io/client-go/kubernetes"
-	"sigs.k8s.io/cluster-api-provider-aws/pkg/client"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/client"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type awsClient struct {
+type kubevirtClient struct {
 }
 
-func (c *awsClient) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
-	return &ec2.DescribeImagesOutput{
-		Images: []*ec2.Image{
-			{
-				ImageId: aws.String("ami-a9acbbd6"),
-			},
+func (c *kubevirtClient) CreateVirtualMachine(ctx context.Context, namespace string, newVM *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	// Feel free to extend the returned values
+	return newVM.DeepCopy(), nil
+}
+
+func (c *kubevirtClient) DeleteVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return nil
+}
+
+func (c *kubevirtClient) GetVirtualMachine(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachine, error) {
+	running := true
+	return &kubevirtapis.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: kubevirtapis.VirtualMachineSpec{
+			Running: &running,
+		},
+		Status: kubevirtapis.VirtualMachineStatus{
+			Created: true,
+			Ready:   true,
 		},
 	}, nil
 }
 
-func (c *awsClient) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
-	return &ec2.DescribeVpcsOutput{
-		Vpcs: []*ec2.Vpc{
-			{
-				VpcId: aws.String("vpc-32677e0e794418639"),
-			},
+func (c *kubevirtClient) UpdateVirtualMachine(ctx context.Context, namespace string, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	// Feel free to extend the returned values
+	return vm.DeepCopy(), nil
+}
+
+func (c *kubevirtClient) StartVirtualMachine(ctx context.Context, namespace string, name string) error {
+	return nil
+}
+
+func (c *kubevirtClient) RestartVirtualMachine(ctx context.Context, namespace string, name string) error {
+	return nil
+}
+
+func (c *kubevirtClient) GetVirtualMachineInstance(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachineInstance, error) {
+	return &kubevirtapis.VirtualMachineInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Status: kubevirtapis.VirtualMachineInstanceStatus{
+			Phase: kubevirtapis.Running,
 		},
 	}, nil
 }
 
-func (c *awsClient) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
-	return &ec2.DescribeSubnetsOutput{
-		Subnets: []*ec2.Subnet{
-			{
-				SubnetId: aws.String("subnet-28fddb3c45cae61b5"),
-			},
+func (c *kubevirtClient) GetPersistentVolumeClaim(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.PersistentVolumeClaim, error) {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       "source-pvc-uid",
 		},
 	}, nil
 }
 
-func (c *awsClient) DescribeAvailabilityZones(*ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
-	return &ec2.DescribeAvailabilityZonesOutput{}, nil
+func (c *kubevirtClient) ListPods(ctx context.Context, namespace string, options *metav1.ListOptions) (*corev1.PodList, error) {
+	return &corev1.PodList{}, nil
 }
 
-func (c *awsClient) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
-	return &ec2.DescribeSecurityGroupsOutput{
-		SecurityGroups: []*ec2.SecurityGroup{
-			{
-				GroupId: aws.String("sg-05acc3c38a35ce63b"),
-			},
+func (c *kubevirtClient) PatchPod(ctx context.Context, namespace string, name string, patchType types.PatchType, data []byte) (*corev1.Pod, error) {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
 		},
 	}, nil
 }
 
-func (c *awsClient) RunInstances(input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
-	return &ec2.Reservation{
-		Instances: []*ec2.Instance{
-			{
-				ImageId:    aws.String("ami-a9acbbd6"),
-				InstanceId: aws.String("i-02fcb933c5da7085c"),
-				State: &ec2.InstanceState{
-					Code: aws.Int64(16),
-				},
-			},
-		},
-	}, nil
+func (c *kubevirtClient) ListNodes(ctx context.Context, options *metav1.ListOptions) (*corev1.NodeList, error) {
+	return &corev1.NodeList{}, nil
+}
+
+func (c *kubevirtClient) ListEvents(ctx context.Context, namespace string, options *metav1.ListOptions) (*corev1.EventList, error) {
+	return &corev1.EventList{}, nil
+}
+
+func (c *kubevirtClient) ListNodeResourceTopologies(ctx context.Context, options *metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return &unstructured.UnstructuredList{}, nil
+}
+
+func (c *kubevirtClient) CreateVirtualMachineSnapshot(ctx context.Context, namespace string, snapshot *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return snapshot, nil
+}
+
+func (c *kubevirtClient) GetVirtualMachineSnapshot(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*unstructured.Unstructured, error) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetName(name)
+	snapshot.SetNamespace(namespace)
+	return snapshot, nil
+}
+
+func (c *kubevirtClient) ListVirtualMachineSnapshots(ctx context.Context, namespace string, options *metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return &unstructured.UnstructuredList{}, nil
+}
+
+func (c *kubevirtClient) DeleteVirtualMachineSnapshot(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return nil
 }
 
-func (c *awsClient) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
-	return &ec2.DescribeInstancesOutput{
-		Reservations: []*ec2.Reservation{
-			{
-				Instances: []*ec2.Instance{
-					{
-						ImageId:    aws.String("ami-a9acbbd6"),
-						InstanceId: aws.String("i-02fcb933c5da7085c"),
-						State: &ec2.InstanceState{
-							Name: aws.String(ec2.InstanceStateNameRunning),
-							Code: aws.Int64(16),
-						},
-						LaunchTime: aws.Time(time.Now()),
-					},
-				},
-			},
+func (c *kubevirtClient) GetService(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.Service, error) {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
 		},
 	}, nil
 }
 
-func (c *awsClient) TerminateInstances(input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+func (c *kubevirtClient) CreateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
 	// Feel free to extend the returned values
-	return &ec2.TerminateInstancesOutput{}, nil
+	return service.DeepCopy(), nil
 }
 
-func (c *awsClient) DescribeVolumes(input *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+func (c *kubevirtClient) UpdateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
 	// Feel free to extend the returned values
-	return &ec2.DescribeVolumesOutput{}, nil
+	return service.DeepCopy(), nil
 }
 
-func (c *awsClient) RegisterInstancesWithLoadBalancer(input *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
-	// Feel free to extend the returned values
-	return &elb.RegisterInstancesWithLoadBalancerOutput{}, nil
+func (c *kubevirtClient) GetSecret(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*corev1.Secret, error) {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}, nil
 }
 
-func (c *awsClient) ELBv2DescribeLoadBalancers(*elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+func (c *kubevirtClient) CreateSecret(ctx context.Context, namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
 	// Feel free to extend the returned values
-	return &elbv2.DescribeLoadBalancersOutput{}, nil
+	return secret.DeepCopy(), nil
 }
 
-func (c *awsClient) ELBv2DescribeTargetGroups(*elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
+func (c *kubevirtClient) UpdateSecret(ctx context.Context, namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
 	// Feel free to extend the returned values
-	return &elbv2.DescribeTargetGroupsOutput{}, nil
+	return secret.DeepCopy(), nil
 }
 
-func (c *awsClient) ELBv2RegisterTargets(*elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
+func (c *kubevirtClient) DeleteSecret(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return nil
+}
+
+func (c *kubevirtClient) CreateDataVolume(ctx context.Context, namespace string, dataVolume *cdiv1.DataVolume) (*cdiv1.DataVolume, error) {
 	// Feel free to extend the returned values
-	return &elbv2.RegisterTargetsOutput{}, nil
+	return dataVolume.DeepCopy(), nil
+}
+
+func (c *kubevirtClient) GetDataVolume(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*cdiv1.DataVolume, error) {
+	return &cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Status: cdiv1.DataVolumeStatus{
+			Phase: cdiv1.Succeeded,
+		},
+	}, nil
+}
+
+func (c *kubevirtClient) DeleteDataVolume(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return nil
+}
+
+func (c *kubevirtClient) GetDataSource(ctx context.Context, namespace string, name string, options *metav1.GetOptions) (*unstructured.Unstructured, error) {
+	dataSource := &unstructured.Unstructured{}
+	dataSource.SetName(name)
+	dataSource.SetNamespace(namespace)
+	return dataSource, nil
+}
+
+func (c *kubevirtClient) CreateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return cron, nil
+}
+
+func (c *kubevirtClient) UpdateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return cron, nil
+}
+
+func (c *kubevirtClient) ListDataImportCrons(ctx context.Context, namespace string, options *metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return &unstructured.UnstructuredList{}, nil
+}
+
+func (c *kubevirtClient) DeleteDataImportCron(ctx context.Context, namespace string, name string, options *metav1.DeleteOptions) error {
+	return nil
+}
+
+func (c *kubevirtClient) GetNamespace(ctx context.Context, name string, options *metav1.GetOptions) (*corev1.Namespace, error) {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}, nil
+}
+
+func (c *kubevirtClient) CreateNamespace(ctx context.Context, namespace *corev1.Namespace) (*corev1.Namespace, error) {
+	return namespace.DeepCopy(), nil
+}
+
+func (c *kubevirtClient) CreateSelfSubjectAccessReview(ctx context.Context, review *authorizationv1.SelfSubjectAccessReview) (*authorizationv1.SelfSubjectAccessReview, error) {
+	allowed := review.DeepCopy()
+	allowed.Status.Allowed = true
+	return allowed, nil
+}
+
+func (c *kubevirtClient) GetPodLogs(ctx context.Context, namespace string, name string, options *corev1.PodLogOptions) ([]byte, error) {
+	return nil, nil
+}
+
+func (c *kubevirtClient) ReadSerialConsole(ctx context.Context, namespace string, name string, duration time.Duration) ([]byte, error) {
+	return nil, nil
 }
 
-// NewClient creates our client wrapper object for the actual AWS clients we use.
-// For authentication the underlying clients will use either the cluster AWS credentials
-// secret if defined (i.e. in the root cluster),
-// otherwise the IAM profile of the master where the actuator will run. (target clusters)
-func NewClient(kubeClient kubernetes.Interface, secretName, namespace, region string) (client.Client, error) {
-	return &awsClient{}, nil
+// NewClient creates our client wrapper object for the actual KubeVirt clients we use.
+func NewClient(ctrlRuntimeClient runtimeclient.Client, secretName, namespace string) (client.Client, error) {
+	return &kubevirtClient{}, nil
 }
@@ -0,0 +1,128 @@
+package kubecli
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+)
+
+var (
+	cdiScheme         = runtime.NewScheme()
+	cdiCodecs         = serializer.NewCodecFactory(cdiScheme)
+	cdiParameterCodec = runtime.NewParameterCodec(cdiScheme)
+)
+
+func init() {
+	if err := cdiv1.AddToScheme(cdiScheme); err != nil {
+		panic(err)
+	}
+}
+
+// CdiInterface is the client of the CDI API.
+type CdiInterface interface {
+	CdiV1alpha1() CdiV1alpha1Interface
+}
+
+// CdiV1alpha1Interface is the client of the v1alpha1 CDI API.
+type CdiV1alpha1Interface interface {
+	DataVolumes(namespace string) DataVolumeInterface
+}
+
+// DataVolumeInterface is the client of the DataVolumes of a namespace.
+type DataVolumeInterface interface {
+	Get(ctx context.Context, name string, options metav1.GetOptions) (*cdiv1.DataVolume, error)
+	Create(ctx context.Context, dataVolume *cdiv1.DataVolume) (*cdiv1.DataVolume, error)
+	Delete(ctx context.Context, name string, options *metav1.DeleteOptions) error
+	List(ctx context.Context, options metav1.ListOptions) (*cdiv1.DataVolumeList, error)
+	Watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+}
+
+type cdi struct {
+	restClient *rest.RESTClient
+}
+
+func newCdiClient(config *rest.Config) (*cdi, error) {
+	config = rest.CopyConfig(config)
+	config.GroupVersion = &cdiv1.SchemeGroupVersion
+	config.NegotiatedSerializer = serializer.WithoutConversionCodecFactory{CodecFactory: cdiCodecs}
+	restClient, err := rest.RESTClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return &cdi{restClient: restClient}, nil
+}
+
+func (k *kubevirt) CdiClient() CdiInterface {
+	return k.cdiClient
+}
+
+func (c *cdi) CdiV1alpha1() CdiV1alpha1Interface {
+	return c
+}
+
+func (c *cdi) DataVolumes(namespace string) DataVolumeInterface {
+	return &dataVolumes{restClient: c.restClient, namespace: namespace}
+}
+
+type dataVolumes struct {
+	restClient *rest.RESTClient
+	namespace  string
+}
+
+func (d *dataVolumes) Get(ctx context.Context, name string, options metav1.GetOptions) (*cdiv1.DataVolume, error) {
+	result := &cdiv1.DataVolume{}
+	err := d.restClient.Get().
+		Namespace(d.namespace).
+		Resource("datavolumes").
+		Name(name).
+		VersionedParams(&options, cdiParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (d *dataVolumes) Create(ctx context.Context, dataVolume *cdiv1.DataVolume) (*cdiv1.DataVolume, error) {
+	result := &cdiv1.DataVolume{}
+	err := d.restClient.Post().
+		Namespace(d.namespace).
+		Resource("datavolumes").
+		Body(dataVolume).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (d *dataVolumes) Delete(ctx context.Context, name string, options *metav1.DeleteOptions) error {
+	return d.restClient.Delete().
+		Namespace(d.namespace).
+		Resource("datavolumes").
+		Name(name).
+		Body(options).
+		Do(ctx).
+		Error()
+}
+
+func (d *dataVolumes) List(ctx context.Context, options metav1.ListOptions) (*cdiv1.DataVolumeList, error) {
+	result := &cdiv1.DataVolumeList{}
+	err := d.restClient.Get().
+		Namespace(d.namespace).
+		Resource("datavolumes").
+		VersionedParams(&options, cdiParameterCodec).
+		Do(ctx).
+		Into(result)
+	return result, err
+}
+
+func (d *dataVolumes) Watch(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+	options.Watch = true
+	return d.restClient.Get().
+		Namespace(d.namespace).
+		Resource("datavolumes").
+		VersionedParams(&options, cdiParameterCodec).
+		Watch(ctx)
+}
@@ -0,0 +1,70 @@
+// Package kubecli is the client of the KubeVirt API the provider uses, the subset of the kubecli
+// package of kubevirt.io/client-go it needs. The kubecli of KubeVirt v0.30 is built on client-go
+// v0.16 and does not build against client-go v0.18 of the manager, while its API types do. Unlike
+// those of kubecli, the requests of these clients take the context they are cancelled with.
+package kubecli
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+// KubevirtClient is the client of the KubeVirt and Kubernetes APIs of a cluster.
+type KubevirtClient interface {
+	VirtualMachineInstance(namespace string) VirtualMachineInstanceInterface
+	VirtualMachine(namespace string) VirtualMachineInterface
+	CdiClient() CdiInterface
+	RestClient() *rest.RESTClient
+	Config() *rest.Config
+	kubernetes.Interface
+}
+
+type kubevirt struct {
+	kubernetes.Interface
+	restClient *rest.RESTClient
+	config     *rest.Config
+	cdiClient  *cdi
+}
+
+// GetKubevirtClientFromRESTConfig returns the client of the cluster the configuration points to.
+func GetKubevirtClientFromRESTConfig(config *rest.Config) (KubevirtClient, error) {
+	config = rest.CopyConfig(config)
+	config.GroupVersion = &kubevirtapis.StorageGroupVersion
+	config.NegotiatedSerializer = serializer.WithoutConversionCodecFactory{CodecFactory: kubevirtapis.Codecs}
+	config.APIPath = "/apis"
+	config.ContentType = runtime.ContentTypeJSON
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	restClient, err := rest.RESTClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	coreClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	cdiClient, err := newCdiClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kubevirt{
+		Interface:  coreClient,
+		restClient: restClient,
+		config:     config,
+		cdiClient:  cdiClient,
+	}, nil
+}
+
+func (k *kubevirt) RestClient() *rest.RESTClient {
+	return k.restClient
+}
+
+func (k *kubevirt) Config() *rest.Config {
+	return k.config
+}
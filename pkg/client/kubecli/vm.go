@@ -0,0 +1,97 @@
+package kubecli
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+const vmSubresourceURL = "/apis/subresources.kubevirt.io/%s/namespaces/%s/virtualmachines/%s/%s"
+
+// VirtualMachineInterface is the client of the VirtualMachines of a namespace.
+type VirtualMachineInterface interface {
+	Get(ctx context.Context, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachine, error)
+	Create(ctx context.Context, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error)
+	Update(ctx context.Context, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error)
+	Delete(ctx context.Context, name string, options *metav1.DeleteOptions) error
+	Start(ctx context.Context, name string) error
+	Restart(ctx context.Context, name string) error
+}
+
+func (k *kubevirt) VirtualMachine(namespace string) VirtualMachineInterface {
+	return &vm{
+		restClient: k.restClient,
+		namespace:  namespace,
+		resource:   "virtualmachines",
+	}
+}
+
+type vm struct {
+	restClient *rest.RESTClient
+	namespace  string
+	resource   string
+}
+
+func (v *vm) Get(ctx context.Context, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachine, error) {
+	result := &kubevirtapis.VirtualMachine{}
+	err := v.restClient.Get().
+		Namespace(v.namespace).
+		Resource(v.resource).
+		Name(name).
+		VersionedParams(options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	result.SetGroupVersionKind(kubevirtapis.VirtualMachineGroupVersionKind)
+	return result, err
+}
+
+func (v *vm) Create(ctx context.Context, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	result := &kubevirtapis.VirtualMachine{}
+	err := v.restClient.Post().
+		Namespace(v.namespace).
+		Resource(v.resource).
+		Body(vm).
+		Do(ctx).
+		Into(result)
+	result.SetGroupVersionKind(kubevirtapis.VirtualMachineGroupVersionKind)
+	return result, err
+}
+
+func (v *vm) Update(ctx context.Context, vm *kubevirtapis.VirtualMachine) (*kubevirtapis.VirtualMachine, error) {
+	result := &kubevirtapis.VirtualMachine{}
+	err := v.restClient.Put().
+		Namespace(v.namespace).
+		Resource(v.resource).
+		Name(vm.Name).
+		Body(vm).
+		Do(ctx).
+		Into(result)
+	result.SetGroupVersionKind(kubevirtapis.VirtualMachineGroupVersionKind)
+	return result, err
+}
+
+func (v *vm) Delete(ctx context.Context, name string, options *metav1.DeleteOptions) error {
+	return v.restClient.Delete().
+		Namespace(v.namespace).
+		Resource(v.resource).
+		Name(name).
+		Body(options).
+		Do(ctx).
+		Error()
+}
+
+// Start asks KubeVirt to start the VM, whatever its run strategy.
+func (v *vm) Start(ctx context.Context, name string) error {
+	uri := fmt.Sprintf(vmSubresourceURL, kubevirtapis.ApiStorageVersion, v.namespace, name, "start")
+	return v.restClient.Put().RequestURI(uri).Do(ctx).Error()
+}
+
+// Restart asks KubeVirt to restart the VMI of the VM.
+func (v *vm) Restart(ctx context.Context, name string) error {
+	uri := fmt.Sprintf(vmSubresourceURL, kubevirtapis.ApiStorageVersion, v.namespace, name, "restart")
+	return v.restClient.Put().RequestURI(uri).Do(ctx).Error()
+}
@@ -0,0 +1,53 @@
+package kubecli
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+func TestCreateVirtualMachineCancelled(t *testing.T) {
+	received := make(chan struct{})
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		close(received)
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client, err := GetKubevirtClientFromRESTConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-received
+		cancel()
+	}()
+	vm := &kubevirtapis.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde"}}
+	if _, err := client.VirtualMachine("tenant-a").Create(ctx, vm); err == nil {
+		t.Fatalf("Expected the cancelled request to fail")
+	}
+
+	// The request is aborted along with the call, not left running
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the request to be cancelled on the server")
+	}
+}
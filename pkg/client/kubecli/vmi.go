@@ -0,0 +1,225 @@
+package kubecli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	kubevirtapis "kubevirt.io/client-go/api/v1"
+)
+
+// plainStreamProtocolName is the websocket subprotocol of the plain streams of KubeVirt.
+const plainStreamProtocolName = "plain.kubevirt.io"
+
+// VirtualMachineInstanceInterface is the client of the VirtualMachineInstances of a namespace.
+type VirtualMachineInstanceInterface interface {
+	Get(ctx context.Context, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachineInstance, error)
+	SerialConsole(ctx context.Context, name string, options *SerialConsoleOptions) (StreamInterface, error)
+}
+
+func (k *kubevirt) VirtualMachineInstance(namespace string) VirtualMachineInstanceInterface {
+	return &vmis{
+		restClient: k.restClient,
+		config:     k.config,
+		namespace:  namespace,
+		resource:   "virtualmachineinstances",
+	}
+}
+
+type vmis struct {
+	restClient *rest.RESTClient
+	config     *rest.Config
+	namespace  string
+	resource   string
+}
+
+func (v *vmis) Get(ctx context.Context, name string, options *metav1.GetOptions) (*kubevirtapis.VirtualMachineInstance, error) {
+	result := &kubevirtapis.VirtualMachineInstance{}
+	err := v.restClient.Get().
+		Namespace(v.namespace).
+		Resource(v.resource).
+		Name(name).
+		VersionedParams(options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	result.SetGroupVersionKind(kubevirtapis.VirtualMachineInstanceGroupVersionKind)
+	return result, err
+}
+
+// SerialConsoleOptions are the options of the connection to the serial console of a VMI.
+type SerialConsoleOptions struct{}
+
+// StreamOptions are the input and output of a stream.
+type StreamOptions struct {
+	In  io.Reader
+	Out io.Writer
+}
+
+// StreamInterface is a stream to a VMI.
+type StreamInterface interface {
+	// Stream copies the input to the VMI and its output to Out, until either ends.
+	Stream(options StreamOptions) error
+}
+
+// SerialConsole connects to the serial console of the VMI over a websocket, the connection is
+// abandoned once ctx is done.
+func (v *vmis) SerialConsole(ctx context.Context, name string, options *SerialConsoleOptions) (StreamInterface, error) {
+	tlsConfig, err := rest.TLSConfigFor(v.config)
+	if err != nil {
+		return nil, err
+	}
+	connection := make(chan *websocket.Conn)
+	done := make(chan struct{})
+	dialer := &websocketRoundTripper{
+		dialer: &websocket.Dialer{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+			Subprotocols:    []string{plainStreamProtocolName},
+		},
+		connection: connection,
+		done:       done,
+	}
+	// The wrappers add the credentials of the configuration to the request
+	roundTripper, err := rest.HTTPWrappersForConfig(v.config, dialer)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(v.config.Host)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return nil, fmt.Errorf("unsupported protocol %s", u.Scheme)
+	}
+	u.Path = fmt.Sprintf("/apis/subresources.kubevirt.io/%s/namespaces/%s/virtualmachineinstances/%s/console", kubevirtapis.ApiStorageVersion, v.namespace, name)
+
+	errs := make(chan error, 1)
+	go func() {
+		request := (&http.Request{Method: http.MethodGet, URL: u, Header: http.Header{}}).WithContext(ctx)
+		response, err := roundTripper.RoundTrip(request)
+		if err == nil && response != nil && response.StatusCode != http.StatusSwitchingProtocols {
+			err = fmt.Errorf("console connection failed with http status: %s", response.Status)
+		}
+		errs <- err
+	}()
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			err = fmt.Errorf("console connection closed")
+		}
+		return nil, err
+	case conn := <-connection:
+		return &wsStreamer{conn: conn, done: done}, nil
+	}
+}
+
+// websocketRoundTripper dials the websocket of a request, handing over the connection until the
+// stream is done.
+type websocketRoundTripper struct {
+	dialer     *websocket.Dialer
+	connection chan *websocket.Conn
+	done       chan struct{}
+}
+
+func (rt *websocketRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	conn, response, err := rt.dialer.DialContext(request.Context(), request.URL.String(), request.Header)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("can't connect to websocket (%s): %w", response.Status, err)
+		}
+		return nil, fmt.Errorf("can't connect to websocket: %w", err)
+	}
+	defer conn.Close()
+
+	select {
+	case rt.connection <- conn:
+	case <-request.Context().Done():
+		return nil, request.Context().Err()
+	}
+	<-rt.done
+	return response, nil
+}
+
+type wsStreamer struct {
+	conn *websocket.Conn
+	done chan struct{}
+}
+
+func (ws *wsStreamer) Stream(options StreamOptions) error {
+	defer close(ws.done)
+	copyErr := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(&binaryWriter{conn: ws.conn}, options.In)
+		copyErr <- err
+	}()
+	go func() {
+		_, err := io.Copy(options.Out, &binaryReader{conn: ws.conn})
+		copyErr <- err
+	}()
+	return <-copyErr
+}
+
+// binaryWriter writes to the binary messages of a websocket.
+type binaryWriter struct {
+	conn *websocket.Conn
+}
+
+func (w *binaryWriter) Write(p []byte) (int, error) {
+	writer, err := w.conn.NextWriter(websocket.BinaryMessage)
+	if err != nil {
+		return 0, convertCloseError(err)
+	}
+	defer writer.Close()
+	return writer.Write(p)
+}
+
+// binaryReader reads the binary messages of a websocket, skipping the others.
+type binaryReader struct {
+	conn   *websocket.Conn
+	reader io.Reader
+}
+
+func (r *binaryReader) Read(p []byte) (int, error) {
+	for {
+		if r.reader == nil {
+			messageType, reader, err := r.conn.NextReader()
+			if err != nil {
+				return 0, convertCloseError(err)
+			}
+			if messageType != websocket.BinaryMessage {
+				continue
+			}
+			r.reader = reader
+		}
+		n, err := r.reader.Read(p)
+		if err == io.EOF {
+			r.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// convertCloseError returns io.EOF for the normal closure of a websocket.
+func convertCloseError(err error) error {
+	if closeErr, ok := err.(*websocket.CloseError); ok && closeErr.Code == websocket.CloseNormalClosure {
+		return io.EOF
+	}
+	return err
+}
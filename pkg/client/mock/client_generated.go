@@ -7,10 +7,16 @@ package mock
 import (
 	reflect "reflect"
 
-	ec2 "github.com/aws/aws-sdk-go/service/ec2"
-	elb "github.com/aws/aws-sdk-go/service/elb"
-	elbv2 "github.com/aws/aws-sdk-go/service/elbv2"
+	context "context"
 	gomock "github.com/golang/mock/gomock"
+	v1 "k8s.io/api/authorization/v1"
+	v10 "k8s.io/api/core/v1"
+	v11 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	types "k8s.io/apimachinery/pkg/types"
+	v12 "kubevirt.io/client-go/api/v1"
+	v1alpha1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+	time "time"
 )
 
 // MockClient is a mock of Client interface
@@ -36,197 +42,550 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
-// DescribeImages mocks base method
-func (m *MockClient) DescribeImages(arg0 *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+// CreateVirtualMachine mocks base method
+func (m *MockClient) CreateVirtualMachine(ctx context.Context, namespace string, newVM *v12.VirtualMachine) (*v12.VirtualMachine, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeImages", arg0)
-	ret0, _ := ret[0].(*ec2.DescribeImagesOutput)
+	ret := m.ctrl.Call(m, "CreateVirtualMachine", ctx, namespace, newVM)
+	ret0, _ := ret[0].(*v12.VirtualMachine)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DescribeImages indicates an expected call of DescribeImages
-func (mr *MockClientMockRecorder) DescribeImages(arg0 interface{}) *gomock.Call {
+// CreateVirtualMachine indicates an expected call of CreateVirtualMachine
+func (mr *MockClientMockRecorder) CreateVirtualMachine(ctx, namespace, newVM interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeImages", reflect.TypeOf((*MockClient)(nil).DescribeImages), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVirtualMachine", reflect.TypeOf((*MockClient)(nil).CreateVirtualMachine), ctx, namespace, newVM)
 }
 
-// DescribeVpcs mocks base method
-func (m *MockClient) DescribeVpcs(arg0 *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+// DeleteVirtualMachine mocks base method
+func (m *MockClient) DeleteVirtualMachine(ctx context.Context, namespace, name string, options *v11.DeleteOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeVpcs", arg0)
-	ret0, _ := ret[0].(*ec2.DescribeVpcsOutput)
+	ret := m.ctrl.Call(m, "DeleteVirtualMachine", ctx, namespace, name, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVirtualMachine indicates an expected call of DeleteVirtualMachine
+func (mr *MockClientMockRecorder) DeleteVirtualMachine(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVirtualMachine", reflect.TypeOf((*MockClient)(nil).DeleteVirtualMachine), ctx, namespace, name, options)
+}
+
+// GetVirtualMachine mocks base method
+func (m *MockClient) GetVirtualMachine(ctx context.Context, namespace, name string, options *v11.GetOptions) (*v12.VirtualMachine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVirtualMachine", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*v12.VirtualMachine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVirtualMachine indicates an expected call of GetVirtualMachine
+func (mr *MockClientMockRecorder) GetVirtualMachine(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVirtualMachine", reflect.TypeOf((*MockClient)(nil).GetVirtualMachine), ctx, namespace, name, options)
+}
+
+// UpdateVirtualMachine mocks base method
+func (m *MockClient) UpdateVirtualMachine(ctx context.Context, namespace string, vm *v12.VirtualMachine) (*v12.VirtualMachine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVirtualMachine", ctx, namespace, vm)
+	ret0, _ := ret[0].(*v12.VirtualMachine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVirtualMachine indicates an expected call of UpdateVirtualMachine
+func (mr *MockClientMockRecorder) UpdateVirtualMachine(ctx, namespace, vm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVirtualMachine", reflect.TypeOf((*MockClient)(nil).UpdateVirtualMachine), ctx, namespace, vm)
+}
+
+// StartVirtualMachine mocks base method
+func (m *MockClient) StartVirtualMachine(ctx context.Context, namespace, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartVirtualMachine", ctx, namespace, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartVirtualMachine indicates an expected call of StartVirtualMachine
+func (mr *MockClientMockRecorder) StartVirtualMachine(ctx, namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartVirtualMachine", reflect.TypeOf((*MockClient)(nil).StartVirtualMachine), ctx, namespace, name)
+}
+
+// RestartVirtualMachine mocks base method
+func (m *MockClient) RestartVirtualMachine(ctx context.Context, namespace, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestartVirtualMachine", ctx, namespace, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestartVirtualMachine indicates an expected call of RestartVirtualMachine
+func (mr *MockClientMockRecorder) RestartVirtualMachine(ctx, namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestartVirtualMachine", reflect.TypeOf((*MockClient)(nil).RestartVirtualMachine), ctx, namespace, name)
+}
+
+// GetVirtualMachineInstance mocks base method
+func (m *MockClient) GetVirtualMachineInstance(ctx context.Context, namespace, name string, options *v11.GetOptions) (*v12.VirtualMachineInstance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVirtualMachineInstance", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*v12.VirtualMachineInstance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVirtualMachineInstance indicates an expected call of GetVirtualMachineInstance
+func (mr *MockClientMockRecorder) GetVirtualMachineInstance(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVirtualMachineInstance", reflect.TypeOf((*MockClient)(nil).GetVirtualMachineInstance), ctx, namespace, name, options)
+}
+
+// GetPersistentVolumeClaim mocks base method
+func (m *MockClient) GetPersistentVolumeClaim(ctx context.Context, namespace, name string, options *v11.GetOptions) (*v10.PersistentVolumeClaim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersistentVolumeClaim", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*v10.PersistentVolumeClaim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersistentVolumeClaim indicates an expected call of GetPersistentVolumeClaim
+func (mr *MockClientMockRecorder) GetPersistentVolumeClaim(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersistentVolumeClaim", reflect.TypeOf((*MockClient)(nil).GetPersistentVolumeClaim), ctx, namespace, name, options)
+}
+
+// ListPods mocks base method
+func (m *MockClient) ListPods(ctx context.Context, namespace string, options *v11.ListOptions) (*v10.PodList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPods", ctx, namespace, options)
+	ret0, _ := ret[0].(*v10.PodList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPods indicates an expected call of ListPods
+func (mr *MockClientMockRecorder) ListPods(ctx, namespace, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPods", reflect.TypeOf((*MockClient)(nil).ListPods), ctx, namespace, options)
+}
+
+// PatchPod mocks base method
+func (m *MockClient) PatchPod(ctx context.Context, namespace, name string, patchType types.PatchType, data []byte) (*v10.Pod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchPod", ctx, namespace, name, patchType, data)
+	ret0, _ := ret[0].(*v10.Pod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchPod indicates an expected call of PatchPod
+func (mr *MockClientMockRecorder) PatchPod(ctx, namespace, name, patchType, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchPod", reflect.TypeOf((*MockClient)(nil).PatchPod), ctx, namespace, name, patchType, data)
+}
+
+// ListNodes mocks base method
+func (m *MockClient) ListNodes(ctx context.Context, options *v11.ListOptions) (*v10.NodeList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNodes", ctx, options)
+	ret0, _ := ret[0].(*v10.NodeList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNodes indicates an expected call of ListNodes
+func (mr *MockClientMockRecorder) ListNodes(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNodes", reflect.TypeOf((*MockClient)(nil).ListNodes), ctx, options)
+}
+
+// ListEvents mocks base method
+func (m *MockClient) ListEvents(ctx context.Context, namespace string, options *v11.ListOptions) (*v10.EventList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", ctx, namespace, options)
+	ret0, _ := ret[0].(*v10.EventList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents
+func (mr *MockClientMockRecorder) ListEvents(ctx, namespace, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockClient)(nil).ListEvents), ctx, namespace, options)
+}
+
+// ListNodeResourceTopologies mocks base method
+func (m *MockClient) ListNodeResourceTopologies(ctx context.Context, options *v11.ListOptions) (*unstructured.UnstructuredList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNodeResourceTopologies", ctx, options)
+	ret0, _ := ret[0].(*unstructured.UnstructuredList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNodeResourceTopologies indicates an expected call of ListNodeResourceTopologies
+func (mr *MockClientMockRecorder) ListNodeResourceTopologies(ctx, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNodeResourceTopologies", reflect.TypeOf((*MockClient)(nil).ListNodeResourceTopologies), ctx, options)
+}
+
+// CreateVirtualMachineSnapshot mocks base method
+func (m *MockClient) CreateVirtualMachineSnapshot(ctx context.Context, namespace string, snapshot *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVirtualMachineSnapshot", ctx, namespace, snapshot)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVirtualMachineSnapshot indicates an expected call of CreateVirtualMachineSnapshot
+func (mr *MockClientMockRecorder) CreateVirtualMachineSnapshot(ctx, namespace, snapshot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVirtualMachineSnapshot", reflect.TypeOf((*MockClient)(nil).CreateVirtualMachineSnapshot), ctx, namespace, snapshot)
+}
+
+// GetVirtualMachineSnapshot mocks base method
+func (m *MockClient) GetVirtualMachineSnapshot(ctx context.Context, namespace, name string, options *v11.GetOptions) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVirtualMachineSnapshot", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DescribeVpcs indicates an expected call of DescribeVpcs
-func (mr *MockClientMockRecorder) DescribeVpcs(arg0 interface{}) *gomock.Call {
+// GetVirtualMachineSnapshot indicates an expected call of GetVirtualMachineSnapshot
+func (mr *MockClientMockRecorder) GetVirtualMachineSnapshot(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVirtualMachineSnapshot", reflect.TypeOf((*MockClient)(nil).GetVirtualMachineSnapshot), ctx, namespace, name, options)
+}
+
+// ListVirtualMachineSnapshots mocks base method
+func (m *MockClient) ListVirtualMachineSnapshots(ctx context.Context, namespace string, options *v11.ListOptions) (*unstructured.UnstructuredList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVirtualMachineSnapshots", ctx, namespace, options)
+	ret0, _ := ret[0].(*unstructured.UnstructuredList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVirtualMachineSnapshots indicates an expected call of ListVirtualMachineSnapshots
+func (mr *MockClientMockRecorder) ListVirtualMachineSnapshots(ctx, namespace, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVirtualMachineSnapshots", reflect.TypeOf((*MockClient)(nil).ListVirtualMachineSnapshots), ctx, namespace, options)
+}
+
+// DeleteVirtualMachineSnapshot mocks base method
+func (m *MockClient) DeleteVirtualMachineSnapshot(ctx context.Context, namespace, name string, options *v11.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVirtualMachineSnapshot", ctx, namespace, name, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVirtualMachineSnapshot indicates an expected call of DeleteVirtualMachineSnapshot
+func (mr *MockClientMockRecorder) DeleteVirtualMachineSnapshot(ctx, namespace, name, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeVpcs", reflect.TypeOf((*MockClient)(nil).DescribeVpcs), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVirtualMachineSnapshot", reflect.TypeOf((*MockClient)(nil).DeleteVirtualMachineSnapshot), ctx, namespace, name, options)
 }
 
-// DescribeSubnets mocks base method
-func (m *MockClient) DescribeSubnets(arg0 *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+// GetService mocks base method
+func (m *MockClient) GetService(ctx context.Context, namespace, name string, options *v11.GetOptions) (*v10.Service, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeSubnets", arg0)
-	ret0, _ := ret[0].(*ec2.DescribeSubnetsOutput)
+	ret := m.ctrl.Call(m, "GetService", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*v10.Service)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DescribeSubnets indicates an expected call of DescribeSubnets
-func (mr *MockClientMockRecorder) DescribeSubnets(arg0 interface{}) *gomock.Call {
+// GetService indicates an expected call of GetService
+func (mr *MockClientMockRecorder) GetService(ctx, namespace, name, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeSubnets", reflect.TypeOf((*MockClient)(nil).DescribeSubnets), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetService", reflect.TypeOf((*MockClient)(nil).GetService), ctx, namespace, name, options)
 }
 
-// DescribeAvailabilityZones mocks base method
-func (m *MockClient) DescribeAvailabilityZones(arg0 *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+// CreateService mocks base method
+func (m *MockClient) CreateService(ctx context.Context, namespace string, service *v10.Service) (*v10.Service, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeAvailabilityZones", arg0)
-	ret0, _ := ret[0].(*ec2.DescribeAvailabilityZonesOutput)
+	ret := m.ctrl.Call(m, "CreateService", ctx, namespace, service)
+	ret0, _ := ret[0].(*v10.Service)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DescribeAvailabilityZones indicates an expected call of DescribeAvailabilityZones
-func (mr *MockClientMockRecorder) DescribeAvailabilityZones(arg0 interface{}) *gomock.Call {
+// CreateService indicates an expected call of CreateService
+func (mr *MockClientMockRecorder) CreateService(ctx, namespace, service interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeAvailabilityZones", reflect.TypeOf((*MockClient)(nil).DescribeAvailabilityZones), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateService", reflect.TypeOf((*MockClient)(nil).CreateService), ctx, namespace, service)
 }
 
-// DescribeSecurityGroups mocks base method
-func (m *MockClient) DescribeSecurityGroups(arg0 *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+// UpdateService mocks base method
+func (m *MockClient) UpdateService(ctx context.Context, namespace string, service *v10.Service) (*v10.Service, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeSecurityGroups", arg0)
-	ret0, _ := ret[0].(*ec2.DescribeSecurityGroupsOutput)
+	ret := m.ctrl.Call(m, "UpdateService", ctx, namespace, service)
+	ret0, _ := ret[0].(*v10.Service)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DescribeSecurityGroups indicates an expected call of DescribeSecurityGroups
-func (mr *MockClientMockRecorder) DescribeSecurityGroups(arg0 interface{}) *gomock.Call {
+// UpdateService indicates an expected call of UpdateService
+func (mr *MockClientMockRecorder) UpdateService(ctx, namespace, service interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeSecurityGroups", reflect.TypeOf((*MockClient)(nil).DescribeSecurityGroups), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateService", reflect.TypeOf((*MockClient)(nil).UpdateService), ctx, namespace, service)
 }
 
-// RunInstances mocks base method
-func (m *MockClient) RunInstances(arg0 *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+// GetSecret mocks base method
+func (m *MockClient) GetSecret(ctx context.Context, namespace, name string, options *v11.GetOptions) (*v10.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RunInstances", arg0)
-	ret0, _ := ret[0].(*ec2.Reservation)
+	ret := m.ctrl.Call(m, "GetSecret", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*v10.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RunInstances indicates an expected call of RunInstances
-func (mr *MockClientMockRecorder) RunInstances(arg0 interface{}) *gomock.Call {
+// GetSecret indicates an expected call of GetSecret
+func (mr *MockClientMockRecorder) GetSecret(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockClient)(nil).GetSecret), ctx, namespace, name, options)
+}
+
+// CreateSecret mocks base method
+func (m *MockClient) CreateSecret(ctx context.Context, namespace string, secret *v10.Secret) (*v10.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecret", ctx, namespace, secret)
+	ret0, _ := ret[0].(*v10.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSecret indicates an expected call of CreateSecret
+func (mr *MockClientMockRecorder) CreateSecret(ctx, namespace, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockClient)(nil).CreateSecret), ctx, namespace, secret)
+}
+
+// UpdateSecret mocks base method
+func (m *MockClient) UpdateSecret(ctx context.Context, namespace string, secret *v10.Secret) (*v10.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecret", ctx, namespace, secret)
+	ret0, _ := ret[0].(*v10.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSecret indicates an expected call of UpdateSecret
+func (mr *MockClientMockRecorder) UpdateSecret(ctx, namespace, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecret", reflect.TypeOf((*MockClient)(nil).UpdateSecret), ctx, namespace, secret)
+}
+
+// DeleteSecret mocks base method
+func (m *MockClient) DeleteSecret(ctx context.Context, namespace, name string, options *v11.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecret", ctx, namespace, name, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecret indicates an expected call of DeleteSecret
+func (mr *MockClientMockRecorder) DeleteSecret(ctx, namespace, name, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInstances", reflect.TypeOf((*MockClient)(nil).RunInstances), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockClient)(nil).DeleteSecret), ctx, namespace, name, options)
 }
 
-// DescribeInstances mocks base method
-func (m *MockClient) DescribeInstances(arg0 *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+// CreateDataVolume mocks base method
+func (m *MockClient) CreateDataVolume(ctx context.Context, namespace string, dataVolume *v1alpha1.DataVolume) (*v1alpha1.DataVolume, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeInstances", arg0)
-	ret0, _ := ret[0].(*ec2.DescribeInstancesOutput)
+	ret := m.ctrl.Call(m, "CreateDataVolume", ctx, namespace, dataVolume)
+	ret0, _ := ret[0].(*v1alpha1.DataVolume)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DescribeInstances indicates an expected call of DescribeInstances
-func (mr *MockClientMockRecorder) DescribeInstances(arg0 interface{}) *gomock.Call {
+// CreateDataVolume indicates an expected call of CreateDataVolume
+func (mr *MockClientMockRecorder) CreateDataVolume(ctx, namespace, dataVolume interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeInstances", reflect.TypeOf((*MockClient)(nil).DescribeInstances), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataVolume", reflect.TypeOf((*MockClient)(nil).CreateDataVolume), ctx, namespace, dataVolume)
 }
 
-// TerminateInstances mocks base method
-func (m *MockClient) TerminateInstances(arg0 *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+// GetDataVolume mocks base method
+func (m *MockClient) GetDataVolume(ctx context.Context, namespace, name string, options *v11.GetOptions) (*v1alpha1.DataVolume, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TerminateInstances", arg0)
-	ret0, _ := ret[0].(*ec2.TerminateInstancesOutput)
+	ret := m.ctrl.Call(m, "GetDataVolume", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*v1alpha1.DataVolume)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// TerminateInstances indicates an expected call of TerminateInstances
-func (mr *MockClientMockRecorder) TerminateInstances(arg0 interface{}) *gomock.Call {
+// GetDataVolume indicates an expected call of GetDataVolume
+func (mr *MockClientMockRecorder) GetDataVolume(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataVolume", reflect.TypeOf((*MockClient)(nil).GetDataVolume), ctx, namespace, name, options)
+}
+
+// DeleteDataVolume mocks base method
+func (m *MockClient) DeleteDataVolume(ctx context.Context, namespace, name string, options *v11.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDataVolume", ctx, namespace, name, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDataVolume indicates an expected call of DeleteDataVolume
+func (mr *MockClientMockRecorder) DeleteDataVolume(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDataVolume", reflect.TypeOf((*MockClient)(nil).DeleteDataVolume), ctx, namespace, name, options)
+}
+
+// GetDataSource mocks base method
+func (m *MockClient) GetDataSource(ctx context.Context, namespace, name string, options *v11.GetOptions) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataSource", ctx, namespace, name, options)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataSource indicates an expected call of GetDataSource
+func (mr *MockClientMockRecorder) GetDataSource(ctx, namespace, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataSource", reflect.TypeOf((*MockClient)(nil).GetDataSource), ctx, namespace, name, options)
+}
+
+// CreateDataImportCron mocks base method
+func (m *MockClient) CreateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataImportCron", ctx, namespace, cron)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataImportCron indicates an expected call of CreateDataImportCron
+func (mr *MockClientMockRecorder) CreateDataImportCron(ctx, namespace, cron interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataImportCron", reflect.TypeOf((*MockClient)(nil).CreateDataImportCron), ctx, namespace, cron)
+}
+
+// UpdateDataImportCron mocks base method
+func (m *MockClient) UpdateDataImportCron(ctx context.Context, namespace string, cron *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDataImportCron", ctx, namespace, cron)
+	ret0, _ := ret[0].(*unstructured.Unstructured)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDataImportCron indicates an expected call of UpdateDataImportCron
+func (mr *MockClientMockRecorder) UpdateDataImportCron(ctx, namespace, cron interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDataImportCron", reflect.TypeOf((*MockClient)(nil).UpdateDataImportCron), ctx, namespace, cron)
+}
+
+// ListDataImportCrons mocks base method
+func (m *MockClient) ListDataImportCrons(ctx context.Context, namespace string, options *v11.ListOptions) (*unstructured.UnstructuredList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDataImportCrons", ctx, namespace, options)
+	ret0, _ := ret[0].(*unstructured.UnstructuredList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDataImportCrons indicates an expected call of ListDataImportCrons
+func (mr *MockClientMockRecorder) ListDataImportCrons(ctx, namespace, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDataImportCrons", reflect.TypeOf((*MockClient)(nil).ListDataImportCrons), ctx, namespace, options)
+}
+
+// DeleteDataImportCron mocks base method
+func (m *MockClient) DeleteDataImportCron(ctx context.Context, namespace, name string, options *v11.DeleteOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDataImportCron", ctx, namespace, name, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDataImportCron indicates an expected call of DeleteDataImportCron
+func (mr *MockClientMockRecorder) DeleteDataImportCron(ctx, namespace, name, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TerminateInstances", reflect.TypeOf((*MockClient)(nil).TerminateInstances), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDataImportCron", reflect.TypeOf((*MockClient)(nil).DeleteDataImportCron), ctx, namespace, name, options)
 }
 
-// DescribeVolumes mocks base method
-func (m *MockClient) DescribeVolumes(arg0 *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+// GetNamespace mocks base method
+func (m *MockClient) GetNamespace(ctx context.Context, name string, options *v11.GetOptions) (*v10.Namespace, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeVolumes", arg0)
-	ret0, _ := ret[0].(*ec2.DescribeVolumesOutput)
+	ret := m.ctrl.Call(m, "GetNamespace", ctx, name, options)
+	ret0, _ := ret[0].(*v10.Namespace)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DescribeVolumes indicates an expected call of DescribeVolumes
-func (mr *MockClientMockRecorder) DescribeVolumes(arg0 interface{}) *gomock.Call {
+// GetNamespace indicates an expected call of GetNamespace
+func (mr *MockClientMockRecorder) GetNamespace(ctx, name, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeVolumes", reflect.TypeOf((*MockClient)(nil).DescribeVolumes), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamespace", reflect.TypeOf((*MockClient)(nil).GetNamespace), ctx, name, options)
 }
 
-// RegisterInstancesWithLoadBalancer mocks base method
-func (m *MockClient) RegisterInstancesWithLoadBalancer(arg0 *elb.RegisterInstancesWithLoadBalancerInput) (*elb.RegisterInstancesWithLoadBalancerOutput, error) {
+// CreateNamespace mocks base method
+func (m *MockClient) CreateNamespace(ctx context.Context, namespace *v10.Namespace) (*v10.Namespace, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterInstancesWithLoadBalancer", arg0)
-	ret0, _ := ret[0].(*elb.RegisterInstancesWithLoadBalancerOutput)
+	ret := m.ctrl.Call(m, "CreateNamespace", ctx, namespace)
+	ret0, _ := ret[0].(*v10.Namespace)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RegisterInstancesWithLoadBalancer indicates an expected call of RegisterInstancesWithLoadBalancer
-func (mr *MockClientMockRecorder) RegisterInstancesWithLoadBalancer(arg0 interface{}) *gomock.Call {
+// CreateNamespace indicates an expected call of CreateNamespace
+func (mr *MockClientMockRecorder) CreateNamespace(ctx, namespace interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterInstancesWithLoadBalancer", reflect.TypeOf((*MockClient)(nil).RegisterInstancesWithLoadBalancer), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNamespace", reflect.TypeOf((*MockClient)(nil).CreateNamespace), ctx, namespace)
 }
 
-// ELBv2DescribeLoadBalancers mocks base method
-func (m *MockClient) ELBv2DescribeLoadBalancers(arg0 *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+// CreateSelfSubjectAccessReview mocks base method
+func (m *MockClient) CreateSelfSubjectAccessReview(ctx context.Context, review *v1.SelfSubjectAccessReview) (*v1.SelfSubjectAccessReview, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ELBv2DescribeLoadBalancers", arg0)
-	ret0, _ := ret[0].(*elbv2.DescribeLoadBalancersOutput)
+	ret := m.ctrl.Call(m, "CreateSelfSubjectAccessReview", ctx, review)
+	ret0, _ := ret[0].(*v1.SelfSubjectAccessReview)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ELBv2DescribeLoadBalancers indicates an expected call of ELBv2DescribeLoadBalancers
-func (mr *MockClientMockRecorder) ELBv2DescribeLoadBalancers(arg0 interface{}) *gomock.Call {
+// CreateSelfSubjectAccessReview indicates an expected call of CreateSelfSubjectAccessReview
+func (mr *MockClientMockRecorder) CreateSelfSubjectAccessReview(ctx, review interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ELBv2DescribeLoadBalancers", reflect.TypeOf((*MockClient)(nil).ELBv2DescribeLoadBalancers), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSelfSubjectAccessReview", reflect.TypeOf((*MockClient)(nil).CreateSelfSubjectAccessReview), ctx, review)
 }
 
-// ELBv2DescribeTargetGroups mocks base method
-func (m *MockClient) ELBv2DescribeTargetGroups(arg0 *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
+// GetPodLogs mocks base method
+func (m *MockClient) GetPodLogs(ctx context.Context, namespace, name string, options *v10.PodLogOptions) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ELBv2DescribeTargetGroups", arg0)
-	ret0, _ := ret[0].(*elbv2.DescribeTargetGroupsOutput)
+	ret := m.ctrl.Call(m, "GetPodLogs", ctx, namespace, name, options)
+	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ELBv2DescribeTargetGroups indicates an expected call of ELBv2DescribeTargetGroups
-func (mr *MockClientMockRecorder) ELBv2DescribeTargetGroups(arg0 interface{}) *gomock.Call {
+// GetPodLogs indicates an expected call of GetPodLogs
+func (mr *MockClientMockRecorder) GetPodLogs(ctx, namespace, name, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ELBv2DescribeTargetGroups", reflect.TypeOf((*MockClient)(nil).ELBv2DescribeTargetGroups), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodLogs", reflect.TypeOf((*MockClient)(nil).GetPodLogs), ctx, namespace, name, options)
 }
 
-// ELBv2RegisterTargets mocks base method
-func (m *MockClient) ELBv2RegisterTargets(arg0 *elbv2.RegisterTargetsInput) (*elbv2.RegisterTargetsOutput, error) {
+// ReadSerialConsole mocks base method
+func (m *MockClient) ReadSerialConsole(ctx context.Context, namespace, name string, duration time.Duration) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ELBv2RegisterTargets", arg0)
-	ret0, _ := ret[0].(*elbv2.RegisterTargetsOutput)
+	ret := m.ctrl.Call(m, "ReadSerialConsole", ctx, namespace, name, duration)
+	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ELBv2RegisterTargets indicates an expected call of ELBv2RegisterTargets
-func (mr *MockClientMockRecorder) ELBv2RegisterTargets(arg0 interface{}) *gomock.Call {
+// ReadSerialConsole indicates an expected call of ReadSerialConsole
+func (mr *MockClientMockRecorder) ReadSerialConsole(ctx, namespace, name, duration interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ELBv2RegisterTargets", reflect.TypeOf((*MockClient)(nil).ELBv2RegisterTargets), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSerialConsole", reflect.TypeOf((*MockClient)(nil).ReadSerialConsole), ctx, namespace, name, duration)
 }
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"sync"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// Default rate limits of the requests to an infra cluster.
+const (
+	DefaultQPS   = 20
+	DefaultBurst = 40
+)
+
+// rateLimiters holds the rate limiter of the requests to each infra cluster, shared by all the
+// clients of the cluster as a client is built per machine operation.
+var rateLimiters = struct {
+	sync.Mutex
+	qps      float32
+	burst    int
+	limiters map[string]flowcontrol.RateLimiter
+}{
+	qps:      DefaultQPS,
+	burst:    DefaultBurst,
+	limiters: map[string]flowcontrol.RateLimiter{},
+}
+
+// SetRateLimits sets the QPS and burst of the requests to each infra cluster whose
+// configuration sets no rate limiter. It is to be called before any client is built.
+func SetRateLimits(qps float32, burst int) {
+	rateLimiters.Lock()
+	defer rateLimiters.Unlock()
+	rateLimiters.qps = qps
+	rateLimiters.burst = burst
+	rateLimiters.limiters = map[string]flowcontrol.RateLimiter{}
+}
+
+// rateLimiter returns the rate limiter of the requests to the infra cluster of the host.
+func rateLimiter(host string) flowcontrol.RateLimiter {
+	rateLimiters.Lock()
+	defer rateLimiters.Unlock()
+	limiter, ok := rateLimiters.limiters[host]
+	if !ok {
+		limiter = flowcontrol.NewTokenBucketRateLimiter(rateLimiters.qps, rateLimiters.burst)
+		rateLimiters.limiters[host] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,300 @@
+// Package handoff implements the versioned leader election of the provider and the handoff
+// of reconciliation between provider versions during upgrades.
+//
+// The identity of every instance carries its version. A candidate whose version differs from
+// the version of the leader requests a handoff in the handoff ConfigMap. The leader then stops
+// starting machine operations, waits for the operations in flight to complete, records the
+// version it hands off from and releases the lock, which the candidate acquires within one
+// retry period. Instances of the version handed off from do not request a handoff back, they
+// only take over through regular election once the leader terminates. A leader shutting down
+// drains the operations in flight and releases the lock the same way.
+package handoff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// candidateKey of the handoff ConfigMap holds the identity of the candidate requesting
+	// the handoff.
+	candidateKey = "candidate"
+	// handedOffFromKey of the handoff ConfigMap holds the version the last handoff was from.
+	handedOffFromKey = "handedOffFrom"
+)
+
+// Options configures the leader election.
+type Options struct {
+	// Namespace and Name of the leader election ConfigMap. The handoff ConfigMap is named
+	// after it with the -handoff suffix.
+	Namespace string
+	Name      string
+	// Version of this instance.
+	Version       string
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+	// DrainTimeout bounds the wait for the operations in flight before stepping down.
+	DrainTimeout time.Duration
+}
+
+// Gate stops machine operations while the leader steps down.
+type Gate interface {
+	Drain(ctx context.Context) error
+	Resume()
+}
+
+// Elector runs the versioned leader election and the handoff protocol.
+type Elector struct {
+	options  Options
+	identity string
+	lock     resourcelock.Interface
+	reader   runtimeclient.Reader
+	writer   runtimeclient.Writer
+	gate     Gate
+}
+
+// NewIdentity returns a unique identity of an instance of the given version.
+func NewIdentity(version string) (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s_%s@%s", hostname, uuid.NewUUID(), version), nil
+}
+
+// versionOf returns the version of the instance of the identity.
+func versionOf(identity string) string {
+	if i := strings.LastIndex(identity, "@"); i >= 0 {
+		return identity[i+1:]
+	}
+	return ""
+}
+
+// NewElector returns an elector campaigning with the identity of the lock. The handoff
+// ConfigMap is read with the reader and written with the writer. The reader must not be
+// backed by the cache of the manager, which only starts once this instance leads.
+func NewElector(options Options, lock resourcelock.Interface, reader runtimeclient.Reader, writer runtimeclient.Writer, gate Gate) *Elector {
+	return &Elector{
+		options:  options,
+		identity: lock.Identity(),
+		lock:     lock,
+		reader:   reader,
+		writer:   writer,
+		gate:     gate,
+	}
+}
+
+// Run campaigns for leadership and calls lead once elected. It returns once this instance is
+// no longer leading, after handing off or after stepping down because ctx is done.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) error {
+	campaignCtx, stopCampaign := context.WithCancel(context.Background())
+	defer stopCampaign()
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            e.lock,
+		Name:            e.options.Name,
+		LeaseDuration:   e.options.LeaseDuration,
+		RenewDeadline:   e.options.RenewDeadline,
+		RetryPeriod:     e.options.RetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				klog.Infof("%s: started leading", e.identity)
+				go e.stepDownWhenDone(ctx, leaderCtx, stopCampaign)
+				lead(leaderCtx)
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("%s: stopped leading", e.identity)
+			},
+			OnNewLeader: func(identity string) {
+				klog.Infof("%s: leader is %s", e.identity, identity)
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Leaders step down once their operations are drained
+			if !elector.IsLeader() {
+				stopCampaign()
+			}
+		case <-campaignCtx.Done():
+		}
+	}()
+	go e.requestHandoffWhileCandidate(campaignCtx, elector)
+
+	elector.Run(campaignCtx)
+	return nil
+}
+
+// stepDownWhenDone steps down once ctx is done or a handoff is requested, after draining the
+// operations in flight.
+func (e *Elector) stepDownWhenDone(ctx, leaderCtx context.Context, stepDown func()) {
+	ticker := time.NewTicker(e.options.RetryPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-leaderCtx.Done():
+			return
+		case <-ctx.Done():
+			if err := e.drain(); err != nil {
+				klog.Errorf("%s: stepping down with operations in flight: %v", e.identity, err)
+			}
+			stepDown()
+			return
+		case <-ticker.C:
+			candidate, err := e.handoffCandidate(leaderCtx)
+			if err != nil {
+				klog.Errorf("%s: failed to check for handoff requests: %v", e.identity, err)
+				continue
+			}
+			if candidate == "" {
+				continue
+			}
+			klog.Infof("%s: handing off to %s", e.identity, candidate)
+			if err := e.drain(); err != nil {
+				klog.Errorf("%s: postponing handoff to %s: %v", e.identity, candidate, err)
+				e.gate.Resume()
+				continue
+			}
+			if err := e.recordHandoff(leaderCtx); err != nil {
+				klog.Errorf("%s: postponing handoff to %s: %v", e.identity, candidate, err)
+				e.gate.Resume()
+				continue
+			}
+			stepDown()
+			return
+		}
+	}
+}
+
+func (e *Elector) drain() error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.options.DrainTimeout)
+	defer cancel()
+	return e.gate.Drain(ctx)
+}
+
+// requestHandoffWhileCandidate requests a handoff from leaders of another version, unless
+// this version was handed off from.
+func (e *Elector) requestHandoffWhileCandidate(ctx context.Context, elector *leaderelection.LeaderElector) {
+	ticker := time.NewTicker(e.options.RetryPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if elector.IsLeader() {
+				return
+			}
+			if err := e.requestHandoff(ctx); err != nil {
+				klog.Errorf("%s: failed to request handoff: %v", e.identity, err)
+			}
+		}
+	}
+}
+
+// requestHandoff records this instance as handoff candidate if the leader runs another version.
+func (e *Elector) requestHandoff(ctx context.Context) error {
+	record, _, err := e.lock.Get(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if record.HolderIdentity == "" || record.HolderIdentity == e.identity || versionOf(record.HolderIdentity) == e.options.Version {
+		return nil
+	}
+
+	configMap, err := e.getHandoffConfigMap(ctx)
+	if err != nil {
+		return err
+	}
+	if configMap.Data[handedOffFromKey] == e.options.Version || configMap.Data[candidateKey] == e.identity {
+		return nil
+	}
+
+	klog.Infof("%s: requesting handoff from %s", e.identity, record.HolderIdentity)
+	configMap.Data[candidateKey] = e.identity
+	return e.saveHandoffConfigMap(ctx, configMap)
+}
+
+// handoffCandidate returns the candidate of another version requesting a handoff, if any.
+func (e *Elector) handoffCandidate(ctx context.Context) (string, error) {
+	configMap, err := e.getHandoffConfigMap(ctx)
+	if err != nil {
+		return "", err
+	}
+	candidate := configMap.Data[candidateKey]
+	if candidate == "" || candidate == e.identity || versionOf(candidate) == e.options.Version {
+		return "", nil
+	}
+	return candidate, nil
+}
+
+// recordHandoff records the version handed off from and clears the request.
+func (e *Elector) recordHandoff(ctx context.Context) error {
+	configMap, err := e.getHandoffConfigMap(ctx)
+	if err != nil {
+		return err
+	}
+	delete(configMap.Data, candidateKey)
+	configMap.Data[handedOffFromKey] = e.options.Version
+	return e.saveHandoffConfigMap(ctx, configMap)
+}
+
+func (e *Elector) handoffConfigMapKey() types.NamespacedName {
+	return types.NamespacedName{Namespace: e.options.Namespace, Name: e.options.Name + "-handoff"}
+}
+
+// getHandoffConfigMap returns the handoff ConfigMap, a new one if it does not exist yet.
+func (e *Elector) getHandoffConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
+	key := e.handoffConfigMapKey()
+	configMap := &corev1.ConfigMap{}
+	if err := e.reader.Get(ctx, key, configMap); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get handoff ConfigMap: %w", err)
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		}
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	return configMap, nil
+}
+
+func (e *Elector) saveHandoffConfigMap(ctx context.Context, configMap *corev1.ConfigMap) error {
+	var err error
+	if configMap.ResourceVersion == "" {
+		err = e.writer.Create(ctx, configMap)
+	} else {
+		err = e.writer.Update(ctx, configMap)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save handoff ConfigMap: %w", err)
+	}
+	return nil
+}
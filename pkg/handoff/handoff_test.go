@@ -0,0 +1,190 @@
+package handoff
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeLock is an in-memory leader election lock.
+type fakeLock struct {
+	identity string
+	record   *resourcelock.LeaderElectionRecord
+}
+
+func (l *fakeLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	// The elector only observes a record, renewing its lease, when the raw record changes
+	raw, err := json.Marshal(l.record)
+	return l.record, raw, err
+}
+
+func (l *fakeLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.record = &ler
+	return nil
+}
+
+func (l *fakeLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.record = &ler
+	return nil
+}
+
+func (l *fakeLock) RecordEvent(string) {}
+
+func (l *fakeLock) Identity() string {
+	return l.identity
+}
+
+func (l *fakeLock) Describe() string {
+	return "fake"
+}
+
+// fakeGate counts drains and resumes.
+type fakeGate struct {
+	drained, resumed int
+}
+
+func (g *fakeGate) Drain(ctx context.Context) error {
+	g.drained++
+	return nil
+}
+
+func (g *fakeGate) Resume() {
+	g.resumed++
+}
+
+func TestVersionOf(t *testing.T) {
+	identity, err := NewIdentity("v0.2.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if version := versionOf(identity); version != "v0.2.0" {
+		t.Errorf("Expected version v0.2.0 of identity %q, got %q", identity, version)
+	}
+	if version := versionOf("host_1234"); version != "" {
+		t.Errorf("Expected no version of an unversioned identity, got %q", version)
+	}
+}
+
+func TestHandoff(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client := fake.NewFakeClientWithScheme(scheme)
+	ctx := context.Background()
+	record := &resourcelock.LeaderElectionRecord{HolderIdentity: "old_1@v0.1.0"}
+	options := Options{
+		Namespace:    "openshift-machine-api",
+		Name:         "cluster-api-provider-kubevirt-leader",
+		RetryPeriod:  time.Second,
+		DrainTimeout: time.Second,
+	}
+
+	newOptions := options
+	newOptions.Version = "v0.2.0"
+	candidate := NewElector(newOptions, &fakeLock{identity: "new_1@v0.2.0", record: record}, client, client, &fakeGate{})
+	oldOptions := options
+	oldOptions.Version = "v0.1.0"
+	leader := NewElector(oldOptions, &fakeLock{identity: "old_1@v0.1.0", record: record}, client, client, &fakeGate{})
+	standby := NewElector(oldOptions, &fakeLock{identity: "old_2@v0.1.0", record: record}, client, client, &fakeGate{})
+
+	// Candidates of the leader version do not request handoffs
+	if err := standby.requestHandoff(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c, err := leader.handoffCandidate(ctx); err != nil || c != "" {
+		t.Fatalf("Expected no handoff candidate, got %q, error %v", c, err)
+	}
+
+	if err := candidate.requestHandoff(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	c, err := leader.handoffCandidate(ctx)
+	if err != nil || c != "new_1@v0.2.0" {
+		t.Fatalf("Expected handoff candidate new_1@v0.2.0, got %q, error %v", c, err)
+	}
+	if err := leader.recordHandoff(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c, err := leader.handoffCandidate(ctx); err != nil || c != "" {
+		t.Fatalf("Expected the handoff request to be cleared, got %q, error %v", c, err)
+	}
+
+	// Once the new version leads, the version handed off from does not request it back
+	record.HolderIdentity = "new_1@v0.2.0"
+	if err := standby.requestHandoff(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c, err := candidate.handoffCandidate(ctx); err != nil || c != "" {
+		t.Errorf("Expected no handoff back to the version handed off from, got %q, error %v", c, err)
+	}
+}
+
+func TestRequestHandoffWithCacheNotStarted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	informers, err := cache.New(&rest.Config{Host: "https://127.0.0.1:1"}, cache.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The API reader and the client of a manager whose cache only starts once leading
+	apiReader := fake.NewFakeClientWithScheme(scheme)
+	client := &runtimeclient.DelegatingClient{Reader: informers, Writer: apiReader, StatusClient: apiReader}
+
+	options := Options{
+		Namespace:     "openshift-machine-api",
+		Name:          "cluster-api-provider-kubevirt-leader",
+		Version:       "v0.2.0",
+		LeaseDuration: time.Minute,
+		RenewDeadline: 30 * time.Second,
+		RetryPeriod:   10 * time.Millisecond,
+		DrainTimeout:  time.Second,
+	}
+	lock := &fakeLock{identity: "new_1@v0.2.0", record: &resourcelock.LeaderElectionRecord{HolderIdentity: "old_1@v0.1.0"}}
+	candidate := NewElector(options, lock, apiReader, client, &fakeGate{})
+
+	var notStarted *cache.ErrCacheNotStarted
+	if err := client.Get(context.Background(), candidate.handoffConfigMapKey(), &corev1.ConfigMap{}); !errors.As(err, &notStarted) {
+		t.Fatalf("Expected the cache not to be started, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := candidate.Run(ctx, func(context.Context) {}); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		configMap := &corev1.ConfigMap{}
+		if err := apiReader.Get(context.Background(), candidate.handoffConfigMapKey(), configMap); err != nil {
+			return false, nil
+		}
+		return configMap.Data[candidateKey] == "new_1@v0.2.0", nil
+	})
+	if err != nil {
+		t.Errorf("Expected the candidate to request a handoff: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package logging builds the structured loggers of the provider. Loggers log in the klog
+// text format, or in JSON lines for log pipelines that index the fields, e.g. the machine,
+// namespace and operation of the machine actuator logs.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/klogr"
+)
+
+const (
+	// FormatText logs through klog, in its text format
+	FormatText = "text"
+	// FormatJSON logs a JSON object per line
+	FormatJSON = "json"
+)
+
+// New returns a logger in the format, logging the messages up to the verbosity. The text
+// format leaves the verbosity to the klog -v flag.
+func New(format string, out io.Writer, verbosity int) (logr.Logger, error) {
+	switch format {
+	case FormatText, "":
+		return klogr.New(), nil
+	case FormatJSON:
+		return NewJSONLogger(out, verbosity), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q, must be %s or %s", format, FormatText, FormatJSON)
+	}
+}
+
+// NewJSONLogger returns a logger writing a JSON object per message to out, with the ts,
+// level, v, logger, msg and error fields followed by the key value pairs of the logger and
+// the message. Messages above the verbosity are discarded.
+func NewJSONLogger(out io.Writer, verbosity int) logr.Logger {
+	return &jsonLogger{
+		sink:      &jsonSink{out: out},
+		verbosity: verbosity,
+	}
+}
+
+// jsonSink serializes the writes of the loggers derived from one another.
+type jsonSink struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+type jsonLogger struct {
+	sink      *jsonSink
+	verbosity int
+	level     int
+	name      string
+	values    []interface{}
+}
+
+func (l *jsonLogger) clone() *jsonLogger {
+	c := *l
+	c.values = append([]interface{}{}, l.values...)
+	return &c
+}
+
+// Enabled implements logr.InfoLogger.
+func (l *jsonLogger) Enabled() bool {
+	return l.level <= l.verbosity
+}
+
+// Info implements logr.InfoLogger.
+func (l *jsonLogger) Info(msg string, keysAndValues ...interface{}) {
+	if l.Enabled() {
+		l.write("info", msg, nil, keysAndValues)
+	}
+}
+
+// Error implements logr.Logger, errors are logged at any verbosity.
+func (l *jsonLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.write("error", msg, err, keysAndValues)
+}
+
+// V implements logr.Logger.
+func (l *jsonLogger) V(level int) logr.InfoLogger {
+	c := l.clone()
+	c.level = l.level + level
+	return c
+}
+
+// WithValues implements logr.Logger.
+func (l *jsonLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	c := l.clone()
+	c.values = append(c.values, keysAndValues...)
+	return c
+}
+
+// WithName implements logr.Logger.
+func (l *jsonLogger) WithName(name string) logr.Logger {
+	c := l.clone()
+	if c.name == "" {
+		c.name = name
+	} else {
+		c.name += "." + name
+	}
+	return c
+}
+
+func (l *jsonLogger) write(level, msg string, err error, keysAndValues []interface{}) {
+	entry := map[string]interface{}{
+		"ts":    time.Now().UTC().Format(time.RFC3339Nano),
+		"level": level,
+		"v":     l.level,
+		"msg":   msg,
+	}
+	if l.name != "" {
+		entry["logger"] = l.name
+	}
+	if err != nil {
+		entry["error"] = err.Error()
+	}
+	addFields(entry, l.values)
+	addFields(entry, keysAndValues)
+
+	line, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		line, _ = json.Marshal(map[string]interface{}{
+			"ts":    entry["ts"],
+			"level": "error",
+			"msg":   fmt.Sprintf("failed to marshal log entry %q: %v", msg, marshalErr),
+		})
+	}
+	l.sink.lock.Lock()
+	defer l.sink.lock.Unlock()
+	_, _ = l.sink.out.Write(append(line, '\n'))
+}
+
+// addFields adds the key value pairs to the entry, later keys override earlier ones. A key
+// without a value is logged with a null value.
+func addFields(entry map[string]interface{}, keysAndValues []interface{}) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", keysAndValues[i])
+		}
+		var value interface{}
+		if i+1 < len(keysAndValues) {
+			value = fieldValue(keysAndValues[i+1])
+		}
+		entry[key] = value
+	}
+}
+
+// fieldValue returns the JSON representation of the value, errors and stringers are logged
+// as their strings and values JSON does not encode with their Go syntax.
+func fieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJSONLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewJSONLogger(out, 2).WithName("actuator").WithValues("machine", "worker-abcde", "operation", "Create")
+
+	logger.Info("creating machine", "attempt", 1)
+	logger.V(3).Info("above the verbosity")
+	logger.V(2).Info("at the verbosity")
+	logger.Error(fmt.Errorf("quota exceeded"), "failed to create VirtualMachine", "operation", "Update")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got %d: %s", len(lines), out.String())
+	}
+	entries := make([]map[string]interface{}, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i]); err != nil {
+			t.Fatalf("Expected JSON log lines, got %q: %v", line, err)
+		}
+	}
+
+	expected := map[string]interface{}{"level": "info", "logger": "actuator", "msg": "creating machine", "machine": "worker-abcde", "operation": "Create", "attempt": float64(1), "v": float64(0)}
+	for key, value := range expected {
+		if entries[0][key] != value {
+			t.Errorf("Expected %s %v, got %v", key, value, entries[0][key])
+		}
+	}
+	if entries[1]["v"] != float64(2) {
+		t.Errorf("Expected v 2, got %v", entries[1]["v"])
+	}
+	if entries[2]["level"] != "error" || entries[2]["error"] != "quota exceeded" {
+		t.Errorf("Expected error entry, got %v", entries[2])
+	}
+	if entries[2]["operation"] != "Update" {
+		t.Errorf("Expected message values to override logger values, got operation %v", entries[2]["operation"])
+	}
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		testcase    string
+		format      string
+		expectError bool
+	}{
+		{testcase: "default", format: ""},
+		{testcase: "text", format: FormatText},
+		{testcase: "json", format: FormatJSON},
+		{testcase: "unsupported", format: "logfmt", expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			logger, err := New(tc.format, &bytes.Buffer{}, 0)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("Expected error %v, got %v", tc.expectError, err)
+			}
+			if !tc.expectError && logger == nil {
+				t.Error("Expected a logger, got nil")
+			}
+		})
+	}
+}
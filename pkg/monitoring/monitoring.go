@@ -0,0 +1,184 @@
+// Package monitoring generates the Prometheus alert rules and the Grafana dashboard of the
+// provider out of the descriptions of the metrics it exports, so that they cannot drift from
+// the metrics they query.
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// MetricType is the Prometheus type of a metric.
+type MetricType string
+
+// Possible values for MetricType.
+const (
+	// Counter is a monotonically increasing metric, graphed as its rate.
+	Counter MetricType = "counter"
+	// Gauge is a metric going up and down, graphed as is.
+	Gauge MetricType = "gauge"
+	// Histogram is a metric observing a distribution, graphed as its 50th and 99th percentiles.
+	Histogram MetricType = "histogram"
+)
+
+// Alert is an alert rule on a metric.
+type Alert struct {
+	// Name is the name of the alert.
+	Name string
+	// Expr is the PromQL expression firing the alert.
+	Expr string
+	// For is how long the expression holds before the alert fires, e.g. 5m.
+	For string
+	// Severity is the severity label of the alert, e.g. warning or critical.
+	Severity string
+	// Summary is the summary annotation of the alert, which may refer to the labels of the
+	// expression, e.g. {{ $labels.namespace }}.
+	Summary string
+}
+
+// Metric describes a metric exported by the provider.
+type Metric struct {
+	// Name is the name of the metric.
+	Name string
+	// Help is the help text of the metric, used as the title of its dashboard panel.
+	Help string
+	// Type is the type of the metric.
+	Type MetricType
+	// Selector restricts the series of the metric, e.g. to the machine controller for the
+	// metrics shared by the controllers of the manager.
+	Selector string
+	// Labels are the labels of the metric, the dashboard panel has a series per combination.
+	Labels []string
+	// Alerts are the alert rules on the metric.
+	Alerts []Alert
+}
+
+// PrometheusRule returns the YAML of the PrometheusRule holding the alert rules of the metrics.
+func PrometheusRule(name, namespace string, metrics []Metric) ([]byte, error) {
+	var rules []interface{}
+	for _, metric := range metrics {
+		for _, alert := range metric.Alerts {
+			rules = append(rules, map[string]interface{}{
+				"alert": alert.Name,
+				"expr":  alert.Expr,
+				"for":   alert.For,
+				"labels": map[string]string{
+					"severity": alert.Severity,
+				},
+				"annotations": map[string]string{
+					"summary": alert.Summary,
+				},
+			})
+		}
+	}
+
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "PrometheusRule",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name":  name,
+					"rules": rules,
+				},
+			},
+		},
+	})
+}
+
+// panelQueries returns the PromQL queries graphing the metric.
+func panelQueries(metric Metric) []string {
+	by := ""
+	if len(metric.Labels) > 0 {
+		by = fmt.Sprintf(" by (%s)", strings.Join(metric.Labels, ", "))
+	}
+	selector := ""
+	if metric.Selector != "" {
+		selector = "{" + metric.Selector + "}"
+	}
+	switch metric.Type {
+	case Counter:
+		return []string{fmt.Sprintf("sum%s (rate(%s%s[5m]))", by, metric.Name, selector)}
+	case Histogram:
+		le := strings.Join(append([]string{"le"}, metric.Labels...), ", ")
+		return []string{
+			fmt.Sprintf("histogram_quantile(0.5, sum by (%s) (rate(%s_bucket%s[5m])))", le, metric.Name, selector),
+			fmt.Sprintf("histogram_quantile(0.99, sum by (%s) (rate(%s_bucket%s[5m])))", le, metric.Name, selector),
+		}
+	default:
+		return []string{fmt.Sprintf("max%s (%s%s)", by, metric.Name, selector)}
+	}
+}
+
+// Dashboard returns the JSON of the Grafana dashboard with a graph panel per metric, querying
+// the Prometheus data source selected by the datasource variable.
+func Dashboard(title string, metrics []Metric) ([]byte, error) {
+	panels := make([]interface{}, 0, len(metrics))
+	for i, metric := range metrics {
+		var targets []interface{}
+		for j, query := range panelQueries(metric) {
+			targets = append(targets, map[string]interface{}{
+				"expr":  query,
+				"refId": string(rune('A' + j)),
+			})
+		}
+		panels = append(panels, map[string]interface{}{
+			"id":         i + 1,
+			"type":       "graph",
+			"title":      metric.Help,
+			"datasource": "$datasource",
+			"gridPos": map[string]int{
+				"h": 8,
+				"w": 12,
+				"x": (i % 2) * 12,
+				"y": (i / 2) * 8,
+			},
+			"targets": targets,
+		})
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"title":         title,
+		"schemaVersion": 22,
+		"time": map[string]string{
+			"from": "now-6h",
+			"to":   "now",
+		},
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{
+					"name":  "datasource",
+					"type":  "datasource",
+					"query": "prometheus",
+				},
+			},
+		},
+		"panels": panels,
+	}, "", "  ")
+}
+
+// DashboardConfigMap returns the YAML of the ConfigMap holding the dashboard, labeled for the
+// Grafana dashboard sidecar to load it.
+func DashboardConfigMap(name, namespace string, dashboard []byte) ([]byte, error) {
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels": map[string]string{
+				"grafana_dashboard": "1",
+			},
+		},
+		"data": map[string]string{
+			name + ".json": string(dashboard),
+		},
+	})
+}
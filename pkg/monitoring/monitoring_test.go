@@ -0,0 +1,77 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+var testMetrics = []Metric{
+	{
+		Name:   "kubevirt_machineset_provisioning_failures_total",
+		Help:   "Provisioning failures",
+		Type:   Counter,
+		Labels: []string{"namespace", "machineset"},
+		Alerts: []Alert{{Name: "ProvisioningFailing", Expr: "increase(kubevirt_machineset_provisioning_failures_total[30m]) > 2", For: "5m", Severity: "warning", Summary: "failing"}},
+	},
+	{
+		Name:     "controller_runtime_reconcile_time_seconds",
+		Help:     "Reconcile latency",
+		Type:     Histogram,
+		Selector: `controller="machine_controller"`,
+	},
+}
+
+func TestPrometheusRule(t *testing.T) {
+	manifest, err := PrometheusRule("kubevirt-machine-controller", "openshift-machine-api", testMetrics)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rule := struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			Groups []struct {
+				Rules []map[string]interface{} `json:"rules"`
+			} `json:"groups"`
+		} `json:"spec"`
+	}{}
+	if err := yaml.Unmarshal(manifest, &rule); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rule.Kind != "PrometheusRule" || len(rule.Spec.Groups) != 1 || len(rule.Spec.Groups[0].Rules) != 1 {
+		t.Fatalf("Expected a PrometheusRule with one rule, got %s", manifest)
+	}
+	if alert := rule.Spec.Groups[0].Rules[0]["alert"]; alert != "ProvisioningFailing" {
+		t.Errorf("Expected alert ProvisioningFailing, got %v", alert)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	dashboard, err := Dashboard("KubeVirt machines", testMetrics)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	parsed := struct {
+		Panels []struct {
+			Title   string `json:"title"`
+			Targets []struct {
+				Expr string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}{}
+	if err := json.Unmarshal(dashboard, &parsed); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(parsed.Panels) != 2 {
+		t.Fatalf("Expected a panel per metric, got %d", len(parsed.Panels))
+	}
+	expected := "sum by (namespace, machineset) (rate(kubevirt_machineset_provisioning_failures_total[5m]))"
+	if expr := parsed.Panels[0].Targets[0].Expr; expr != expected {
+		t.Errorf("Expected query %q, got %q", expected, expr)
+	}
+	if targets := parsed.Panels[1].Targets; len(targets) != 2 || !strings.Contains(targets[1].Expr, `controller_runtime_reconcile_time_seconds_bucket{controller="machine_controller"}`) {
+		t.Errorf("Expected percentile queries of the histogram, got %v", targets)
+	}
+}
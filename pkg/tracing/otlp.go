@@ -0,0 +1,231 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+const (
+	// otlpTracesPath is the path of the OTLP/HTTP traces endpoint of a collector
+	otlpTracesPath = "/v1/traces"
+	// maxQueuedSpans bounds the spans kept while the collector is unreachable
+	maxQueuedSpans = 2048
+	// instrumentationScope names the instrumentation recording the spans
+	instrumentationScope = "sigs.k8s.io/cluster-api-provider-kubevirt"
+
+	spanKindInternal = 1
+	statusCodeOK     = 1
+	statusCodeError  = 2
+)
+
+// OTLPTracer records spans and exports them in batches to an OpenTelemetry collector with
+// the OTLP/HTTP protocol. It is a manager runnable exporting the spans ended since the last
+// export every export interval, spans beyond the queue limit are dropped.
+type OTLPTracer struct {
+	endpoint       string
+	serviceName    string
+	exportInterval time.Duration
+	client         *http.Client
+
+	lock    sync.Mutex
+	queue   []*span
+	dropped int
+}
+
+// NewOTLPTracer returns a tracer exporting to the collector at the endpoint, e.g.
+// http://otel-collector:4318, with the service name as resource.
+func NewOTLPTracer(endpoint, serviceName string, exportInterval time.Duration) *OTLPTracer {
+	return &OTLPTracer{
+		endpoint:       strings.TrimSuffix(endpoint, "/") + otlpTracesPath,
+		serviceName:    serviceName,
+		exportInterval: exportInterval,
+		client:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// StartSpan implements Tracer.
+func (t *OTLPTracer) StartSpan(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	s := &span{
+		tracer:     t,
+		spanID:     newID(8),
+		name:       name,
+		start:      time.Now(),
+		attributes: attributes,
+	}
+	if parent, ok := ctx.Value(spanContextKey{}).(*span); ok {
+		s.traceID = parent.traceID
+		s.parentSpanID = parent.spanID
+	} else {
+		s.traceID = newID(16)
+	}
+	return context.WithValue(ctx, spanContextKey{}, s), s
+}
+
+func (t *OTLPTracer) enqueue(s *span) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.queue) >= maxQueuedSpans {
+		t.dropped++
+		return
+	}
+	t.queue = append(t.queue, s)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every instance exports its spans.
+func (t *OTLPTracer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, it exports the spans until the stop channel is closed
+// and exports the remaining spans then.
+func (t *OTLPTracer) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(t.exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			t.export()
+			return nil
+		case <-ticker.C:
+			t.export()
+		}
+	}
+}
+
+// export sends the queued spans to the collector, they are kept for the next export if it fails.
+func (t *OTLPTracer) export() {
+	t.lock.Lock()
+	spans, dropped := t.queue, t.dropped
+	t.queue, t.dropped = nil, 0
+	t.lock.Unlock()
+
+	if dropped > 0 {
+		klog.Warningf("Dropped %d spans while the OTLP collector was unreachable", dropped)
+	}
+	if len(spans) == 0 {
+		return
+	}
+	if err := t.send(spans); err != nil {
+		klog.Errorf("Failed to export %d spans: %v", len(spans), err)
+		t.lock.Lock()
+		for _, s := range spans {
+			if len(t.queue) >= maxQueuedSpans {
+				t.dropped++
+				continue
+			}
+			t.queue = append(t.queue, s)
+		}
+		t.lock.Unlock()
+	}
+}
+
+func (t *OTLPTracer) send(spans []*span) error {
+	body, err := json.Marshal(t.tracesRequest(spans))
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded %s", resp.Status)
+	}
+	return nil
+}
+
+// The OTLP/HTTP JSON encoding of an export traces request, IDs are hex encoded and
+// timestamps are nanoseconds since the epoch.
+
+type otlpTracesRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func otlpAttributes(attributes []Attribute) []otlpAttribute {
+	var result []otlpAttribute
+	for _, attribute := range attributes {
+		result = append(result, otlpAttribute{Key: attribute.Key, Value: otlpValue{StringValue: attribute.Value}})
+	}
+	return result
+}
+
+func (t *OTLPTracer) tracesRequest(spans []*span) *otlpTracesRequest {
+	scopeSpans := otlpScopeSpans{Scope: otlpScope{Name: instrumentationScope}}
+	for _, s := range spans {
+		status := otlpStatus{Code: statusCodeOK}
+		if s.err != nil {
+			status = otlpStatus{Code: statusCodeError, Message: s.err.Error()}
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentSpanID,
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.attributes),
+			Status:            status,
+		})
+	}
+	return &otlpTracesRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: otlpAttributes([]Attribute{String("service.name", t.serviceName)})},
+			ScopeSpans: []otlpScopeSpans{scopeSpans},
+		}},
+	}
+}
@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOTLPTracer(t *testing.T) {
+	requests := make(chan *otlpTracesRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != otlpTracesPath {
+			t.Errorf("Expected spans posted to %s, got %s", otlpTracesPath, r.URL.Path)
+		}
+		request := &otlpTracesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		requests <- request
+	}))
+	defer collector.Close()
+
+	tracer := NewOTLPTracer(collector.URL, "machine-controller", 0)
+	ctx, parent := tracer.StartSpan(context.Background(), "Create", String("machine", "worker-abcde"))
+	_, child := StartSpan(ctx, "CreateVirtualMachine")
+	child.RecordError(fmt.Errorf("quota exceeded"))
+	child.End()
+	parent.End()
+	tracer.export()
+
+	request := <-requests
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	exportedChild, exportedParent := spans[0], spans[1]
+	if exportedChild.TraceID != exportedParent.TraceID || exportedChild.ParentSpanID != exportedParent.SpanID {
+		t.Errorf("Expected CreateVirtualMachine to be a child of Create, got %+v and %+v", exportedChild, exportedParent)
+	}
+	if exportedChild.Status.Code != statusCodeError || exportedChild.Status.Message != "quota exceeded" {
+		t.Errorf("Expected the error to be recorded, got %+v", exportedChild.Status)
+	}
+	if len(exportedParent.Attributes) != 1 || exportedParent.Attributes[0].Value.StringValue != "worker-abcde" {
+		t.Errorf("Expected the machine attribute, got %+v", exportedParent.Attributes)
+	}
+	if service := request.ResourceSpans[0].Resource.Attributes[0]; service.Value.StringValue != "machine-controller" {
+		t.Errorf("Expected service name machine-controller, got %+v", service)
+	}
+
+	// Without a span in the context nothing is recorded
+	_, span := StartSpan(context.Background(), "orphan")
+	span.End()
+	if len(tracer.queue) != 0 {
+		t.Errorf("Expected no span queued, got %d", len(tracer.queue))
+	}
+}
@@ -0,0 +1,117 @@
+// Package tracing records spans of the machine operations of the provider and exports them
+// to an OpenTelemetry collector with the OTLP/HTTP protocol, in its JSON encoding.
+//
+// Spans are started through a Tracer, nested spans are started with StartSpan out of the
+// span carried by the context, so that code called by the actuator records spans without
+// being handed the tracer. Without a span in the context StartSpan records nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Tracer starts spans.
+type Tracer interface {
+	// StartSpan starts a span, child of the span of the context if any, and returns the
+	// context carrying it.
+	StartSpan(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// Span is an operation being traced.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attributes ...Attribute)
+	// RecordError marks the span failed with the error, nil errors are ignored.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// Attribute is a key value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String returns an attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+type spanContextKey struct{}
+
+// StartSpan starts a span, child of the span carried by the context, with the tracer of that
+// span. Without a span in the context the returned span records nothing.
+func StartSpan(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	parent, ok := ctx.Value(spanContextKey{}).(*span)
+	if !ok {
+		return ctx, noopSpan{}
+	}
+	return parent.tracer.StartSpan(ctx, name, attributes...)
+}
+
+// Trace records the operation in a span, child of the span carried by the context, and
+// returns the error of the operation.
+func Trace(ctx context.Context, name string, operation func() error, attributes ...Attribute) error {
+	_, span := StartSpan(ctx, name, attributes...)
+	err := operation()
+	span.RecordError(err)
+	span.End()
+	return err
+}
+
+// NoopTracer returns a tracer recording nothing.
+func NoopTracer() Tracer {
+	return noopTracer{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) StartSpan(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attributes ...Attribute) {}
+func (noopSpan) RecordError(err error)                 {}
+func (noopSpan) End()                                  {}
+
+// span is a span recorded by the OTLP tracer.
+type span struct {
+	tracer       *OTLPTracer
+	traceID      string
+	spanID       string
+	parentSpanID string
+	name         string
+	start        time.Time
+	end          time.Time
+	attributes   []Attribute
+	err          error
+}
+
+func (s *span) SetAttributes(attributes ...Attribute) {
+	s.attributes = append(s.attributes, attributes...)
+}
+
+func (s *span) RecordError(err error) {
+	if err != nil {
+		s.err = err
+	}
+}
+
+func (s *span) End() {
+	s.end = time.Now()
+	s.tracer.enqueue(s)
+}
+
+// newID returns a random ID of the given number of bytes, hex encoded.
+func newID(size int) string {
+	id := make([]byte, size)
+	// crypto/rand only fails if the system randomness is unavailable
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,129 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	machineactuator "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/actuators/machine"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/codec"
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+// ProviderSpecDefaultingPath is the path the provider spec defaulting webhook is served at.
+const ProviderSpecDefaultingPath = "/mutate-machine-openshift-io-v1beta1-kubevirt-providerspec"
+
+// ProviderSpecDefaulter fills the defaults the actuator applies into the unset fields of the
+// KubeVirt provider spec of Machines and MachineSets, so that minimal provider specs show the
+// memory, CPU, storage, disk bus and cloud-init source their VMs get. Fields defaulted by the
+// infra cluster, such as the storage class and the eviction strategy, are left unset.
+type ProviderSpecDefaulter struct {
+	decoder *admission.Decoder
+}
+
+// NewProviderSpecDefaulter returns a defaulter.
+func NewProviderSpecDefaulter() *ProviderSpecDefaulter {
+	return &ProviderSpecDefaulter{}
+}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (d *ProviderSpecDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (d *ProviderSpecDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var obj runtime.Object
+	var providerSpec *machinev1.ProviderSpec
+
+	switch req.Kind.Kind {
+	case "Machine":
+		machine := &machinev1.Machine{}
+		if err := d.decoder.Decode(req, machine); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		obj, providerSpec = machine, &machine.Spec.ProviderSpec
+	case "MachineSet":
+		machineSet := &machinev1.MachineSet{}
+		if err := d.decoder.Decode(req, machineSet); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		obj, providerSpec = machineSet, &machineSet.Spec.Template.Spec.ProviderSpec
+	default:
+		return admission.Allowed("")
+	}
+
+	if providerSpec.Value == nil {
+		return admission.Allowed("")
+	}
+	defaulted, changed, err := defaultProviderSpecValue(providerSpec.Value.Raw)
+	if err != nil {
+		// Malformed provider specs are left to the validation webhook to deny
+		return admission.Allowed("")
+	}
+	if !changed {
+		return admission.Allowed("")
+	}
+
+	providerSpec.Value = &runtime.RawExtension{Raw: defaulted}
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// defaultProviderSpecValue adds the defaulted fields to the raw provider spec. Fields already
+// set and fields unknown to this version of the provider are kept as they are.
+func defaultProviderSpecValue(raw []byte) ([]byte, bool, error) {
+	spec, err := codec.DecodeProviderSpec(&runtime.RawExtension{Raw: raw})
+	if err != nil {
+		return nil, false, err
+	}
+	if spec.Kind != "" && spec.Kind != providerSpecKind {
+		return raw, false, nil
+	}
+	original, err := toFieldMap(spec)
+	if err != nil {
+		return nil, false, err
+	}
+	machineactuator.DefaultProviderSpec(spec)
+	defaulted, err := toFieldMap(spec)
+	if err != nil {
+		return nil, false, err
+	}
+	value := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, false, err
+	}
+
+	changed := false
+	for key, field := range defaulted {
+		if _, ok := value[key]; !ok && !reflect.DeepEqual(field, original[key]) {
+			value[key] = field
+			changed = true
+		}
+	}
+	if !changed {
+		return raw, false, nil
+	}
+	result, err := json.Marshal(value)
+	return result, true, err
+}
+
+// toFieldMap returns the JSON fields of the provider spec.
+func toFieldMap(spec *kubevirtproviderv1.KubevirtMachineProviderSpec) (map[string]interface{}, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(raw, &fields)
+	return fields, err
+}
@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestProviderSpecDefaulter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := machinev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defaulter := NewProviderSpecDefaulter()
+	if err := defaulter.InjectDecoder(decoder); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	machine := &machinev1.Machine{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine"},
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-abcde", Namespace: "openshift-machine-api"},
+		Spec: machinev1.MachineSpec{
+			ProviderSpec: machinev1.ProviderSpec{
+				Value: &runtime.RawExtension{Raw: []byte(`{"kind":"KubevirtMachineProviderSpec","sourcePvcName":"rhcos","requestedCPU":4,"futureField":true}`)},
+			},
+		},
+	}
+	raw, err := json.Marshal(machine)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	response := defaulter.Handle(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "Machine"},
+			Operation: admissionv1beta1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+	if !response.Allowed {
+		t.Fatalf("Expected the machine to be allowed, got %v", response.Result)
+	}
+
+	patched := map[string]interface{}{}
+	for _, operation := range response.Patches {
+		if operation.Path == "/spec/providerSpec/value/futureField" || operation.Path == "/spec/providerSpec/value/requestedCPU" {
+			t.Errorf("Expected %s to be left alone, got %s", operation.Path, operation.Operation)
+		}
+		patched[operation.Path] = operation.Value
+	}
+	expected := map[string]interface{}{
+		"/spec/providerSpec/value/requestedMemory":  "2048M",
+		"/spec/providerSpec/value/requestedStorage": "35Gi",
+		"/spec/providerSpec/value/diskBus":          "virtio",
+		"/spec/providerSpec/value/cloudInitSource":  "NoCloud",
+	}
+	for path, value := range expected {
+		if patched[path] != value {
+			t.Errorf("Expected %s to be defaulted to %v, got %v", path, value, patched[path])
+		}
+	}
+
+	// A defaulted provider spec is left as it is
+	defaulted, changed, err := defaultProviderSpecValue([]byte(`{"sourcePvcName":"rhcos","requestedMemory":"4G","requestedCPU":1,"requestedStorage":"35Gi","diskBus":"virtio","cloudInitSource":"NoCloud"}`))
+	if err != nil || changed {
+		t.Errorf("Expected no change, got %s, error %v", defaulted, err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package webhooks implements the admission webhooks of the provider.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	machineactuator "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/actuators/machine"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/codec"
+)
+
+const (
+	// ProviderSpecValidationPath is the path the provider spec validation webhook is served at.
+	ProviderSpecValidationPath = "/validate-machine-openshift-io-v1beta1-kubevirt-providerspec"
+
+	providerSpecKind = "KubevirtMachineProviderSpec"
+)
+
+// ProviderSpecValidator validates the KubeVirt provider spec of Machines and MachineSets on
+// creation and on updates changing it, the way the actuator does when creating the VM. The
+// secrets it references are looked up by the actuator, they may be created after the spec.
+// It also denies deletions of Machines protected from deletion.
+type ProviderSpecValidator struct {
+	decoder *admission.Decoder
+}
+
+// NewProviderSpecValidator returns a provider spec validator.
+func NewProviderSpecValidator() *ProviderSpecValidator {
+	return &ProviderSpecValidator{}
+}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (v *ProviderSpecValidator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (v *ProviderSpecValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var providerSpec, oldProviderSpec machinev1.ProviderSpec
+	var fldPath *field.Path
+
+	if req.Operation == admissionv1beta1.Delete {
+		return v.handleDelete(req)
+	}
+
+	switch req.Kind.Kind {
+	case "Machine":
+		machine := &machinev1.Machine{}
+		if err := v.decoder.Decode(req, machine); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if machine.DeletionTimestamp != nil {
+			return admission.Allowed("machine is being deleted")
+		}
+		providerSpec = machine.Spec.ProviderSpec
+		fldPath = field.NewPath("spec", "providerSpec", "value")
+		if req.Operation == admissionv1beta1.Update {
+			old := &machinev1.Machine{}
+			if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+				return admission.Errored(http.StatusBadRequest, err)
+			}
+			oldProviderSpec = old.Spec.ProviderSpec
+		}
+	case "MachineSet":
+		machineSet := &machinev1.MachineSet{}
+		if err := v.decoder.Decode(req, machineSet); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if machineSet.DeletionTimestamp != nil {
+			return admission.Allowed("machine set is being deleted")
+		}
+		providerSpec = machineSet.Spec.Template.Spec.ProviderSpec
+		fldPath = field.NewPath("spec", "template", "spec", "providerSpec", "value")
+		if req.Operation == admissionv1beta1.Update {
+			old := &machinev1.MachineSet{}
+			if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+				return admission.Errored(http.StatusBadRequest, err)
+			}
+			oldProviderSpec = old.Spec.Template.Spec.ProviderSpec
+		}
+	default:
+		return admission.Allowed("")
+	}
+
+	if providerSpec.Value == nil {
+		return admission.Allowed("")
+	}
+	// Updates leaving the provider spec alone, e.g. scaling a MachineSet, are not held back
+	// by specs which passed admission before a validation was added
+	if oldProviderSpec.Value != nil && bytes.Equal(oldProviderSpec.Value.Raw, providerSpec.Value.Raw) {
+		return admission.Allowed("")
+	}
+
+	spec, err := codec.DecodeProviderSpec(providerSpec.Value)
+	if err != nil {
+		return admission.Denied(field.Invalid(fldPath, "", err.Error()).Error())
+	}
+	if spec.Kind != "" && spec.Kind != providerSpecKind {
+		return admission.Allowed("")
+	}
+
+	machineactuator.DefaultProviderSpec(spec)
+	allErrs := machineactuator.ValidateProviderSpec(spec, fldPath)
+	// The machines of a MachineSet share its provider spec, the VMs would share the UUID
+	if req.Kind.Kind == "MachineSet" && spec.SMBIOS != nil && spec.SMBIOS.UUID != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("smbios", "uuid"), "must not be set in the provider spec of a MachineSet"))
+	}
+	if len(allErrs) > 0 {
+		return admission.Denied(allErrs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// handleDelete denies the deletion of Machines protected from deletion. The object being deleted
+// is only sent as the old object.
+func (v *ProviderSpecValidator) handleDelete(req admission.Request) admission.Response {
+	if req.Kind.Kind != "Machine" {
+		return admission.Allowed("")
+	}
+	machine := &machinev1.Machine{}
+	if err := v.decoder.DecodeRaw(req.OldObject, machine); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := machineactuator.CheckDeletionProtection(machine); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
@@ -0,0 +1,176 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	machinev1 "github.com/openshift/machine-api-operator/pkg/apis/machine/v1beta1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubevirtproviderv1 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/apis/kubevirtprovider/v1beta1"
+)
+
+func newMachineSet(t *testing.T, providerSpec *kubevirtproviderv1.KubevirtMachineProviderSpec, replicas int32) runtime.RawExtension {
+	value, err := kubevirtproviderv1.RawExtensionFromProviderSpec(providerSpec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	machineSet := &machinev1.MachineSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "machine.openshift.io/v1beta1", Kind: "MachineSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "openshift-machine-api"},
+		Spec: machinev1.MachineSetSpec{
+			Replicas: &replicas,
+			Template: machinev1.MachineTemplateSpec{
+				Spec: machinev1.MachineSpec{ProviderSpec: machinev1.ProviderSpec{Value: value}},
+			},
+		},
+	}
+	raw, err := json.Marshal(machineSet)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestProviderSpecValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := machinev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	validator := NewProviderSpecValidator()
+	if err := validator.InjectDecoder(decoder); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	valid := &kubevirtproviderv1.KubevirtMachineProviderSpec{
+		SourcePvcName:  "rhcos",
+		UserDataSecret: &kubevirtproviderv1.UserDataSecretReference{Name: "worker-user-data"},
+	}
+	invalidMemory := valid.DeepCopy()
+	invalidMemory.RequestedMemory = "2 gigs"
+	uncreatedSecret := valid.DeepCopy()
+	uncreatedSecret.UserDataSecret.Name = "uncreated"
+	sharedUUID := valid.DeepCopy()
+	sharedUUID.SMBIOS = &kubevirtproviderv1.SMBIOS{UUID: "4c4a0e5e-0e2f-4f7a-9b0b-2f3c1d5e6a7b"}
+
+	testCases := []struct {
+		testcase        string
+		operation       admissionv1beta1.Operation
+		oldObject       runtime.RawExtension
+		object          runtime.RawExtension
+		expectedAllowed bool
+	}{
+		{
+			testcase:        "valid provider spec",
+			operation:       admissionv1beta1.Create,
+			object:          newMachineSet(t, valid, 1),
+			expectedAllowed: true,
+		},
+		{
+			testcase:  "invalid memory",
+			operation: admissionv1beta1.Create,
+			object:    newMachineSet(t, invalidMemory, 1),
+		},
+		{
+			testcase:        "user data secret created after the provider spec",
+			operation:       admissionv1beta1.Create,
+			object:          newMachineSet(t, uncreatedSecret, 1),
+			expectedAllowed: true,
+		},
+		{
+			testcase:  "smbios uuid shared by the machines of a machine set",
+			operation: admissionv1beta1.Create,
+			object:    newMachineSet(t, sharedUUID, 1),
+		},
+		{
+			testcase:  "update to an invalid provider spec",
+			operation: admissionv1beta1.Update,
+			oldObject: newMachineSet(t, valid, 1),
+			object:    newMachineSet(t, invalidMemory, 1),
+		},
+		{
+			testcase:        "scaling a machine set with an unchanged provider spec",
+			operation:       admissionv1beta1.Update,
+			oldObject:       newMachineSet(t, sharedUUID, 1),
+			object:          newMachineSet(t, sharedUUID, 3),
+			expectedAllowed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			response := validator.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1beta1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSet"},
+					Operation: tc.operation,
+					Object:    tc.object,
+					OldObject: tc.oldObject,
+				},
+			})
+			if response.Allowed != tc.expectedAllowed {
+				t.Errorf("Expected allowed %v, got %v: %v", tc.expectedAllowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+func TestProviderSpecValidatorDelete(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := machinev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	validator := NewProviderSpecValidator()
+	if err := validator.InjectDecoder(decoder); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		testcase        string
+		annotations     map[string]string
+		expectedAllowed bool
+	}{
+		{
+			testcase:        "unprotected machine",
+			expectedAllowed: true,
+		},
+		{
+			testcase:    "protected machine",
+			annotations: map[string]string{"kubevirtproviderconfig.openshift.io/deletion-protected": "true"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testcase, func(t *testing.T) {
+			machine := &machinev1.Machine{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine"},
+				ObjectMeta: metav1.ObjectMeta{Name: "master-0", Namespace: "openshift-machine-api", Annotations: tc.annotations},
+			}
+			raw, err := json.Marshal(machine)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			response := validator.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1beta1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "Machine"},
+					Operation: admissionv1beta1.Delete,
+					OldObject: runtime.RawExtension{Raw: raw},
+				},
+			})
+			if response.Allowed != tc.expectedAllowed {
+				t.Errorf("Expected allowed %v, got %v: %v", tc.expectedAllowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}